@@ -19,7 +19,9 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/apache/apisix-ingress-controller/cmd/export"
 	"github.com/apache/apisix-ingress-controller/cmd/ingress"
+	"github.com/apache/apisix-ingress-controller/cmd/validate"
 	"github.com/apache/apisix-ingress-controller/pkg/version"
 )
 
@@ -50,6 +52,8 @@ func NewAPISIXIngressControllerCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(ingress.NewIngressCommand())
+	cmd.AddCommand(validate.NewValidateCommand())
+	cmd.AddCommand(export.NewExportCommand())
 	cmd.AddCommand(newVersionCommand())
 	return cmd
 }
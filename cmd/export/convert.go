@@ -0,0 +1,423 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// object is an exported manifest, along with the notes about the parts of
+// the APISIX resource which can't be converted cleanly.
+type object struct {
+	obj   runtime.Object
+	notes []string
+}
+
+func (o *object) notef(format string, args ...interface{}) {
+	o.notes = append(o.notes, fmt.Sprintf(format, args...))
+}
+
+// exporter converts the APISIX resources to the CRD manifests on a best
+// effort basis.
+type exporter struct {
+	namespace string
+	// services are the names of the Services created for the upstreams,
+	// keyed by the upstream ID.
+	services map[string]string
+	// ports are the ports of the Services created for the upstreams,
+	// keyed by the upstream ID.
+	ports map[string][]int32
+	// names are the names in use, keyed by the kind.
+	names   map[string]map[string]struct{}
+	objects []*object
+}
+
+func newExporter(namespace string) *exporter {
+	return &exporter{
+		namespace: namespace,
+		services:  make(map[string]string),
+		ports:     make(map[string][]int32),
+		names:     make(map[string]map[string]struct{}),
+	}
+}
+
+// export converts the upstreams, routes and consumers, the upstreams should go
+// first, as they are referenced by the routes.
+func (e *exporter) export(upstreams []*apisixv1.Upstream, routes []*apisixv1.Route, consumers []*apisixv1.Consumer) []*object {
+	for _, ups := range upstreams {
+		e.exportUpstream(ups)
+	}
+	for _, r := range routes {
+		e.exportRoute(r)
+	}
+	for _, c := range consumers {
+		e.exportConsumer(c)
+	}
+	return e.objects
+}
+
+// exportUpstream converts the upstream to a Service without selector and
+// the Endpoints of the upstream nodes, so that it can be referenced by the
+// ApisixRoutes, and an ApisixUpstream carrying the upstream settings.
+func (e *exporter) exportUpstream(ups *apisixv1.Upstream) {
+	name := e.allocateName("Service", ups.Name, "upstream-"+ups.ID)
+	e.services[ups.ID] = name
+
+	svc := &object{
+		obj: &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: e.objectMeta(name),
+		},
+	}
+	ep := &object{
+		obj: &corev1.Endpoints{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Endpoints"},
+			ObjectMeta: e.objectMeta(name),
+		},
+	}
+	svc.notef("converted from the nodes of APISIX upstream %s", ups.ID)
+
+	addresses := make(map[int32][]corev1.EndpointAddress)
+	weights := make(map[int]struct{})
+	for _, node := range ups.Nodes {
+		if net.ParseIP(node.Host) == nil {
+			svc.notef("node %s:%d is skipped since only IP addresses can be used in Endpoints", node.Host, node.Port)
+			continue
+		}
+		port := int32(node.Port)
+		addresses[port] = append(addresses[port], corev1.EndpointAddress{IP: node.Host})
+		weights[node.Weight] = struct{}{}
+	}
+	if len(weights) > 1 {
+		svc.notef("the node weights are dropped since they can't be expressed in Endpoints")
+	}
+
+	var ports []int32
+	for port := range addresses {
+		ports = append(ports, port)
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	e.ports[ups.ID] = ports
+
+	service := svc.obj.(*corev1.Service)
+	endpoints := ep.obj.(*corev1.Endpoints)
+	for _, port := range ports {
+		portName := fmt.Sprintf("port-%d", port)
+		service.Spec.Ports = append(service.Spec.Ports, corev1.ServicePort{
+			Name:       portName,
+			Port:       port,
+			TargetPort: intstr.FromInt(int(port)),
+		})
+		endpoints.Subsets = append(endpoints.Subsets, corev1.EndpointSubset{
+			Addresses: addresses[port],
+			Ports:     []corev1.EndpointPort{{Name: portName, Port: port}},
+		})
+	}
+	if len(ports) == 0 {
+		svc.notef("no node can be converted, please fill the ports and the Endpoints manually")
+	}
+	e.objects = append(e.objects, svc, ep)
+
+	au := &object{}
+	cfg := e.convertUpstreamConfig(ups, au)
+	if cfg == nil && len(au.notes) == 0 {
+		return
+	}
+	spec := &configv2beta3.ApisixUpstreamSpec{}
+	if cfg != nil {
+		spec.ApisixUpstreamConfig = *cfg
+	}
+	au.obj = &configv2beta3.ApisixUpstream{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apisix.apache.org/v2beta3", Kind: "ApisixUpstream"},
+		ObjectMeta: e.objectMeta(name),
+		Spec:       spec,
+	}
+	e.objects = append(e.objects, au)
+}
+
+// convertUpstreamConfig converts the upstream settings, nil is returned if
+// all of them are the defaults.
+func (e *exporter) convertUpstreamConfig(ups *apisixv1.Upstream, o *object) *configv2beta3.ApisixUpstreamConfig {
+	var (
+		cfg     configv2beta3.ApisixUpstreamConfig
+		changed bool
+	)
+	if ups.Type != "" && ups.Type != apisixv1.LbRoundRobin {
+		cfg.LoadBalancer = &configv2beta3.LoadBalancer{
+			Type:   ups.Type,
+			HashOn: ups.HashOn,
+			Key:    ups.Key,
+		}
+		changed = true
+	}
+	if ups.Scheme != "" && ups.Scheme != apisixv1.SchemeHTTP {
+		cfg.Scheme = ups.Scheme
+		changed = true
+	}
+	if ups.PassHost != "" && ups.PassHost != apisixv1.PassHostPass {
+		cfg.PassHost = ups.PassHost
+		cfg.UpstreamHost = ups.UpstreamHost
+		changed = true
+	}
+	if ups.Retries != nil {
+		cfg.Retries = ups.Retries
+		changed = true
+	}
+	if ups.RetryTimeout != nil {
+		cfg.RetryTimeout = &metav1.Duration{Duration: seconds(*ups.RetryTimeout)}
+		changed = true
+	}
+	if ups.Timeout != nil {
+		cfg.Timeout = &configv2beta3.UpstreamTimeout{
+			Connect: metav1.Duration{Duration: seconds(ups.Timeout.Connect)},
+			Send:    metav1.Duration{Duration: seconds(ups.Timeout.Send)},
+			Read:    metav1.Duration{Duration: seconds(ups.Timeout.Read)},
+		}
+		changed = true
+	}
+	if ups.KeepalivePool != nil {
+		cfg.KeepalivePool = &configv2beta3.UpstreamKeepalivePool{
+			Size:        ups.KeepalivePool.Size,
+			IdleTimeout: metav1.Duration{Duration: seconds(ups.KeepalivePool.IdleTimeout)},
+			Requests:    ups.KeepalivePool.Requests,
+		}
+		changed = true
+	}
+	if ups.Checks != nil {
+		o.notef("health checks are not exported: %s", mustJSON(ups.Checks))
+	}
+	if ups.TLS != nil {
+		o.notef("the client certificate is not exported, put it into a Secret and reference it in tlsSecret")
+	}
+	if !changed {
+		return nil
+	}
+	return &cfg
+}
+
+// exportRoute converts the route to an ApisixRoute with a single rule.
+func (e *exporter) exportRoute(r *apisixv1.Route) {
+	name := e.allocateName("ApisixRoute", r.Name, "route-"+r.ID)
+	o := &object{}
+
+	rule := configv2.ApisixRouteHTTP{
+		Name:      "rule1",
+		Priority:  r.Priority,
+		Websocket: r.EnableWebsocket,
+		Match: configv2.ApisixRouteHTTPMatch{
+			Paths:       r.Uris,
+			Hosts:       r.Hosts,
+			Methods:     r.Methods,
+			RemoteAddrs: r.RemoteAddrs,
+		},
+	}
+	if r.Uri != "" {
+		rule.Match.Paths = append(rule.Match.Paths, r.Uri)
+	}
+	if r.Host != "" {
+		rule.Match.Hosts = append(rule.Match.Hosts, r.Host)
+	}
+	if r.Timeout != nil {
+		rule.Timeout = &configv2.UpstreamTimeout{
+			Connect: metav1.Duration{Duration: seconds(r.Timeout.Connect)},
+			Send:    metav1.Duration{Duration: seconds(r.Timeout.Send)},
+			Read:    metav1.Duration{Duration: seconds(r.Timeout.Read)},
+		}
+	}
+	if len(r.Vars) > 0 {
+		o.notef("vars are not exported, convert them to match.exprs manually: %s", mustJSON(r.Vars))
+	}
+	if r.PluginConfigId != "" {
+		o.notef("plugin config %s is not exported, set plugin_config_name to the corresponding ApisixPluginConfig", r.PluginConfigId)
+	}
+
+	var plugins []string
+	for plugin := range r.Plugins {
+		plugins = append(plugins, plugin)
+	}
+	sort.Strings(plugins)
+	for _, plugin := range plugins {
+		cfg, _ := r.Plugins[plugin].(map[string]interface{})
+		rule.Plugins = append(rule.Plugins, configv2.ApisixRouteHTTPPlugin{
+			Name:   plugin,
+			Enable: true,
+			Config: cfg,
+		})
+	}
+	if len(plugins) > 0 {
+		o.notef("plugins %s are copied as is, review their configurations (e.g. the credentials) before applying", strings.Join(plugins, ", "))
+	}
+
+	if svc, ok := e.services[r.UpstreamId]; ok {
+		ports := e.ports[r.UpstreamId]
+		weight := 100
+		backend := configv2.ApisixRouteHTTPBackend{
+			ServiceName: svc,
+			Weight:      &weight,
+		}
+		if len(ports) > 0 {
+			backend.ServicePort = intstr.FromInt(int(ports[0]))
+		}
+		if len(ports) > 1 {
+			o.notef("the upstream listens on multiple ports, port %d of Service %s is used", ports[0], svc)
+		}
+		rule.Backends = []configv2.ApisixRouteHTTPBackend{backend}
+	} else if r.UpstreamId != "" {
+		o.notef("upstream %s is not found, please fill the backends manually", r.UpstreamId)
+	} else {
+		o.notef("the route has no upstream_id (e.g. it uses an inline upstream or a service), please fill the backends manually")
+	}
+
+	o.obj = &configv2.ApisixRoute{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apisix.apache.org/v2", Kind: "ApisixRoute"},
+		ObjectMeta: e.objectMeta(name),
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{rule},
+		},
+	}
+	e.objects = append(e.objects, o)
+}
+
+// exportConsumer converts the consumer to an ApisixConsumer, only the
+// key-auth and basic-auth credentials are converted.
+func (e *exporter) exportConsumer(c *apisixv1.Consumer) {
+	name := e.allocateName("ApisixConsumer", c.Username, "consumer")
+	o := &object{}
+
+	var (
+		auth    configv2.ApisixConsumerAuthParameter
+		skipped []string
+	)
+	for plugin, value := range c.Plugins {
+		cfg, _ := value.(map[string]interface{})
+		switch plugin {
+		case "key-auth":
+			auth.KeyAuth = &configv2.ApisixConsumerKeyAuth{
+				Value: &configv2.ApisixConsumerKeyAuthValue{Key: stringValue(cfg, "key")},
+			}
+		case "basic-auth":
+			auth.BasicAuth = &configv2.ApisixConsumerBasicAuth{
+				Value: &configv2.ApisixConsumerBasicAuthValue{
+					Username: stringValue(cfg, "username"),
+					Password: stringValue(cfg, "password"),
+				},
+			}
+		default:
+			skipped = append(skipped, plugin)
+		}
+	}
+	if len(skipped) > 0 {
+		sort.Strings(skipped)
+		o.notef("plugins %s are not exported", strings.Join(skipped, ", "))
+	}
+	if auth.KeyAuth != nil || auth.BasicAuth != nil {
+		o.notef("the credentials are exported in place, consider moving them to Secrets")
+	}
+
+	o.obj = &configv2.ApisixConsumer{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apisix.apache.org/v2", Kind: "ApisixConsumer"},
+		ObjectMeta: e.objectMeta(name),
+		Spec: configv2.ApisixConsumerSpec{
+			AuthParameter: auth,
+		},
+	}
+	e.objects = append(e.objects, o)
+}
+
+func (e *exporter) objectMeta(name string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      name,
+		Namespace: e.namespace,
+	}
+}
+
+// allocateName returns a valid and unused object name of the kind, which is
+// derived from the given name, or the fallback if nothing is left.
+func (e *exporter) allocateName(kind, name, fallback string) string {
+	used, ok := e.names[kind]
+	if !ok {
+		used = make(map[string]struct{})
+		e.names[kind] = used
+	}
+	base := sanitizeName(name)
+	if base == "" {
+		base = sanitizeName(fallback)
+	}
+	candidate := base
+	for i := 2; ; i++ {
+		if _, ok := used[candidate]; !ok {
+			break
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	used[candidate] = struct{}{}
+	return candidate
+}
+
+// sanitizeName converts the name to a DNS-1035 label, which is valid for all
+// the exported kinds.
+func sanitizeName(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if b.Len() == 0 && r >= '0' && r <= '9' {
+				b.WriteByte('x')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else if b.Len() > 0 && !dash {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	s := b.String()
+	if len(s) > 55 {
+		// Leave room for the suffix of duplicated names.
+		s = s[:55]
+	}
+	return strings.TrimRight(s, "-")
+}
+
+func seconds(n int) time.Duration {
+	return time.Duration(n) * time.Second
+}
+
+func stringValue(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
+
+func mustJSON(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
@@ -0,0 +1,160 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+)
+
+// NewExportCommand creates the export sub command for apisix-ingress-controller.
+func NewExportCommand() *cobra.Command {
+	var (
+		logLevel  string
+		baseURL   string
+		adminKey  string
+		namespace string
+		timeout   time.Duration
+	)
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "export the APISIX resources as CRD manifests",
+		Long: `export the APISIX resources as CRD manifests
+
+The routes, upstreams and consumers are listed from the APISIX admin API and converted to
+ApisixRoutes, ApisixUpstreams and ApisixConsumers on a best effort basis, so that a manually
+configured APISIX can be migrated to the ingress controller:
+
+    apisix-ingress-controller export --apisix-base-url http://127.0.0.1:9180/apisix/admin \
+        --apisix-admin-key <key> --namespace apps > manifests.yaml
+
+Since ApisixRoutes route to Services, each upstream is converted to a Service without selector and
+the Endpoints of its nodes, along with an ApisixUpstream for the upstream settings. The parts which
+can't be converted cleanly (e.g. route vars, health checks, or plugins copied as is) are reported
+in the comments of the manifests, review them before applying.
+
+APISIX is only read, the manifests are written to stdout.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			logger, err := log.NewLogger(log.WithLogLevel(logLevel))
+			if err != nil {
+				return fmt.Errorf("failed to initialize logging: %s", err)
+			}
+			log.DefaultLogger = logger
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			objects, err := exportCluster(ctx, baseURL, adminKey, namespace)
+			if err != nil {
+				return err
+			}
+			return writeObjects(cmd.OutOrStdout(), objects)
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", "fatal", "log level of the APISIX client")
+	cmd.PersistentFlags().StringVar(&baseURL, "apisix-base-url", "http://127.0.0.1:9180/apisix/admin", "the base URL of the APISIX admin api")
+	cmd.PersistentFlags().StringVar(&adminKey, "apisix-admin-key", "", "admin key used for the authorization of the APISIX admin api")
+	cmd.PersistentFlags().StringVar(&namespace, "namespace", "default", "namespace of the exported manifests")
+	cmd.PersistentFlags().DurationVar(&timeout, "timeout", 30*time.Second, "the timeout of listing the APISIX resources")
+	return cmd
+}
+
+// exportCluster lists the resources of the APISIX cluster and converts them.
+func exportCluster(ctx context.Context, baseURL, adminKey, namespace string) ([]*object, error) {
+	client, err := apisix.NewClient()
+	if err != nil {
+		return nil, err
+	}
+	err = client.AddCluster(ctx, &apisix.ClusterOptions{
+		Name:             "export",
+		BaseURL:          baseURL,
+		AdminKey:         adminKey,
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	cluster := client.Cluster("export")
+
+	upstreams, err := cluster.Upstream().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upstreams: %s", err)
+	}
+	routes, err := cluster.Route().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list routes: %s", err)
+	}
+	consumers, err := cluster.Consumer().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list consumers: %s", err)
+	}
+	return newExporter(namespace).export(upstreams, routes, consumers), nil
+}
+
+// writeObjects writes the objects as a multi-document YAML, the notes of each
+// object are written as the comments ahead of it.
+func writeObjects(w io.Writer, objects []*object) error {
+	for _, o := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o.obj)
+		if err != nil {
+			return err
+		}
+		// Drop the fields which are only meaningful for existing objects.
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(content, "status")
+		// The authentication of the ApisixRoute rules is never set, drop it
+		// since the empty type is rejected by the CRD validation.
+		if rules, ok, _ := unstructured.NestedSlice(content, "spec", "http"); ok {
+			for _, rule := range rules {
+				if m, ok := rule.(map[string]interface{}); ok {
+					delete(m, "authentication")
+				}
+			}
+			if err := unstructured.SetNestedSlice(content, rules, "spec", "http"); err != nil {
+				return err
+			}
+		}
+		data, err := yaml.Marshal(content)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(w, "---"); err != nil {
+			return err
+		}
+		for _, note := range o.notes {
+			if _, err := fmt.Fprintf(w, "# NOTE: %s\n", note); err != nil {
+				return err
+			}
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package export
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestExport(t *testing.T) {
+	retries := 2
+	upstreams := []*apisixv1.Upstream{
+		{
+			Metadata: apisixv1.Metadata{ID: "1", Name: "httpbin_upstream"},
+			Type:     apisixv1.LbEwma,
+			Nodes: apisixv1.UpstreamNodes{
+				{Host: "10.0.0.1", Port: 80, Weight: 100},
+				{Host: "10.0.0.2", Port: 80, Weight: 100},
+				{Host: "httpbin.org", Port: 80, Weight: 100},
+			},
+			Retries: &retries,
+		},
+	}
+	routes := []*apisixv1.Route{
+		{
+			Metadata:   apisixv1.Metadata{ID: "100", Name: "httpbin route"},
+			Uri:        "/ip",
+			Hosts:      []string{"httpbin.org"},
+			Methods:    []string{"GET"},
+			UpstreamId: "1",
+			Plugins: apisixv1.Plugins{
+				"cors": map[string]interface{}{},
+			},
+			Vars: apisixv1.Vars{
+				{{StrVal: "arg_id"}, {StrVal: "=="}, {StrVal: "1"}},
+			},
+		},
+		{
+			Metadata: apisixv1.Metadata{ID: "101"},
+			Uris:     []string{"/status/*"},
+		},
+	}
+	consumers := []*apisixv1.Consumer{
+		{
+			Username: "jack",
+			Plugins: apisixv1.Plugins{
+				"key-auth":    map[string]interface{}{"key": "jack-key"},
+				"limit-count": map[string]interface{}{"count": 10},
+			},
+		},
+	}
+
+	objects := newExporter("apps").export(upstreams, routes, consumers)
+	buf := bytes.NewBuffer(nil)
+	assert.Nil(t, writeObjects(buf, objects))
+	assert.Equal(t, `---
+# NOTE: converted from the nodes of APISIX upstream 1
+# NOTE: node httpbin.org:80 is skipped since only IP addresses can be used in Endpoints
+apiVersion: v1
+kind: Service
+metadata:
+  name: httpbin-upstream
+  namespace: apps
+spec:
+  ports:
+  - name: port-80
+    port: 80
+    targetPort: 80
+---
+apiVersion: v1
+kind: Endpoints
+metadata:
+  name: httpbin-upstream
+  namespace: apps
+subsets:
+- addresses:
+  - ip: 10.0.0.1
+  - ip: 10.0.0.2
+  ports:
+  - name: port-80
+    port: 80
+---
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixUpstream
+metadata:
+  name: httpbin-upstream
+  namespace: apps
+spec:
+  loadbalancer:
+    type: ewma
+  retries: 2
+---
+# NOTE: vars are not exported, convert them to match.exprs manually: [["arg_id","==","1"]]
+# NOTE: plugins cors are copied as is, review their configurations (e.g. the credentials) before applying
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: httpbin-route
+  namespace: apps
+spec:
+  http:
+  - backends:
+    - serviceName: httpbin-upstream
+      servicePort: 80
+      weight: 100
+    match:
+      hosts:
+      - httpbin.org
+      methods:
+      - GET
+      paths:
+      - /ip
+    name: rule1
+    plugins:
+    - config: {}
+      enable: true
+      name: cors
+    websocket: false
+---
+# NOTE: the route has no upstream_id (e.g. it uses an inline upstream or a service), please fill the backends manually
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: route-101
+  namespace: apps
+spec:
+  http:
+  - match:
+      paths:
+      - /status/*
+    name: rule1
+    websocket: false
+---
+# NOTE: plugins limit-count are not exported
+# NOTE: the credentials are exported in place, consider moving them to Secrets
+apiVersion: apisix.apache.org/v2
+kind: ApisixConsumer
+metadata:
+  name: jack
+  namespace: apps
+spec:
+  authParameter:
+    keyAuth:
+      value:
+        key: jack-key
+`, buf.String())
+}
+
+func TestSanitizeName(t *testing.T) {
+	assert.Equal(t, "httpbin-route", sanitizeName("HTTPBin_Route"))
+	assert.Equal(t, "x1-route", sanitizeName("1 route"))
+	assert.Equal(t, "route", sanitizeName("--route--"))
+	assert.Equal(t, "", sanitizeName("_"))
+
+	e := newExporter("default")
+	assert.Equal(t, "httpbin", e.allocateName("ApisixRoute", "httpbin", "route-1"))
+	assert.Equal(t, "httpbin-2", e.allocateName("ApisixRoute", "HTTPBin", "route-2"))
+	assert.Equal(t, "route-3", e.allocateName("ApisixRoute", "", "route-3"))
+	assert.Equal(t, "httpbin", e.allocateName("ApisixConsumer", "httpbin", "consumer"))
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
 	controller "github.com/apache/apisix-ingress-controller/pkg/ingress"
@@ -40,13 +41,117 @@ func dief(template string, args ...interface{}) {
 	os.Exit(1)
 }
 
-func waitForSignal(stopCh chan struct{}) {
+func waitForSignal(stopCh chan struct{}, reload func()) {
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
 
-	sig := <-sigCh
-	log.Infof("signal %d (%s) received", sig, sig.String())
-	close(stopCh)
+	for {
+		sig := <-sigCh
+		log.Infof("signal %d (%s) received", sig, sig.String())
+		if sig == syscall.SIGHUP {
+			reload()
+			continue
+		}
+		close(stopCh)
+		return
+	}
+}
+
+// flagOverrides records the command line flags of the configuration items
+// which are set explicitly, they take precedence over the configuration file
+// and the environment variables.
+type flagOverrides map[string][]string
+
+func newFlagOverrides(fs *pflag.FlagSet) flagOverrides {
+	overrides := make(flagOverrides)
+	fs.Visit(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			overrides[f.Name] = sv.GetSlice()
+		} else {
+			overrides[f.Name] = []string{f.Value.String()}
+		}
+	})
+	return overrides
+}
+
+// apply sets the overridden configuration items to cfg.
+func (fo flagOverrides) apply(cfg *config.Config) error {
+	// Binding the flags resets the fields to the flag defaults, so the
+	// original values are restored before applying the overrides.
+	saved := *cfg
+	fs := pflag.NewFlagSet("overrides", pflag.ContinueOnError)
+	addConfigFlags(fs, cfg)
+	*cfg = saved
+
+	for name, values := range fo {
+		f := fs.Lookup(name)
+		if f == nil {
+			// Not a configuration item, e.g. --config-path.
+			continue
+		}
+		var err error
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			err = sv.Replace(values)
+		} else if len(values) > 0 {
+			err = f.Value.Set(values[0])
+		}
+		if err != nil {
+			return fmt.Errorf("invalid flag --%s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+// loadConfig layers the configuration from, in the increasing order of
+// precedence, the configuration file (or the flag defaults if no file is
+// specified), the environment variables and the explicitly set flags.
+func loadConfig(configPath string, flagCfg *config.Config, overrides flagOverrides) (*config.Config, error) {
+	var cfg *config.Config
+	if configPath != "" {
+		c, err := config.NewConfigFromFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+		cfg = c
+	} else {
+		c := *flagCfg
+		cfg = &c
+	}
+	if err := cfg.ApplyOSEnv(); err != nil {
+		return nil, err
+	}
+	if err := overrides.apply(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// reloadConfig reloads the configuration file and applies the config
+// items which can be changed at runtime, changes of the other items are
+// reported and ignored. The environment variables and the flags still take
+// precedence over the reloaded file.
+func reloadConfig(configPath string, cfg *config.Config, overrides flagOverrides, ingress *controller.Controller) {
+	if configPath == "" {
+		log.Warn("configuration reload ignored since no configuration file is specified")
+		return
+	}
+	newCfg, err := loadConfig(configPath, cfg, overrides)
+	if err != nil {
+		log.Errorf("failed to reload configuration: %s", err)
+		return
+	}
+	if err := newCfg.Validate(); err != nil {
+		log.Errorf("bad configuration, reload aborted: %s", err)
+		return
+	}
+	for _, item := range cfg.RestartRequiredChanges(newCfg) {
+		log.Warnf("config item %s is changed, it takes effect after restarting", item)
+	}
+	if err := log.DefaultLogger.SetLevel(newCfg.LogLevel); err != nil {
+		log.Errorf("failed to reload log level: %s", err)
+	}
+	ingress.Reload(newCfg)
+	log.Info("configuration reloaded")
 }
 
 // NewIngressCommand creates the ingress sub command for apisix-ingress-controller.
@@ -58,8 +163,9 @@ func NewIngressCommand() *cobra.Command {
 		Use: "ingress [flags]",
 		Long: `launch the ingress controller
 
-You can run apisix-ingress-controller from configuration file or command line options,
-if you run it from configuration file, other command line options will be ignored.
+You can run apisix-ingress-controller from configuration file, environment variables
+or command line options. The command line options take precedence over the environment
+variables, which take precedence over the configuration file.
 
 Run from configuration file:
 
@@ -67,6 +173,13 @@ Run from configuration file:
 
 Both json and yaml are supported as the configuration file format.
 
+Every configuration item can be overridden by an environment variable, the name of which is
+the APISIX_INGRESS_ prefix followed by the upper-cased item path joined with "_", e.g.
+
+    APISIX_INGRESS_LOG_LEVEL=debug
+    APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL=12h
+    APISIX_INGRESS_KUBERNETES_APP_NAMESPACES=default,apisix
+
 Run from command line options:
 
     apisix-ingress-controller ingress --default-apisix-cluster-base-url http://apisix-service:9180/apisix/admin --kubeconfig /path/to/kubeconfig
@@ -91,13 +204,12 @@ or if you run it inside cluster, leave it alone and in-cluster configuration wil
 Before you run apisix-ingress-controller, be sure all related resources, like CRDs (ApisixRoute, ApisixUpstream and etc),
 the apisix cluster and others are created`,
 		Run: func(cmd *cobra.Command, args []string) {
-			if configPath != "" {
-				c, err := config.NewConfigFromFile(configPath)
-				if err != nil {
-					dief("failed to initialize configuration: %s", err)
-				}
-				cfg = c
+			overrides := newFlagOverrides(cmd.Flags())
+			c, err := loadConfig(configPath, cfg, overrides)
+			if err != nil {
+				dief("failed to initialize configuration: %s", err)
 			}
+			cfg = c
 			if err := cfg.Validate(); err != nil {
 				dief("bad configuration: %s", err)
 			}
@@ -105,6 +217,7 @@ the apisix cluster and others are created`,
 			logger, err := log.NewLogger(
 				log.WithLogLevel(cfg.LogLevel),
 				log.WithOutputFile(cfg.LogOutput),
+				log.WithSampling(cfg.LogSampling.Initial, cfg.LogSampling.Thereafter),
 			)
 			if err != nil {
 				dief("failed to initialize logging: %s", err)
@@ -134,47 +247,103 @@ the apisix cluster and others are created`,
 				}
 			}()
 
-			waitForSignal(stop)
+			waitForSignal(stop, func() {
+				reloadConfig(configPath, cfg, overrides, ingress)
+			})
 			wg.Wait()
 			log.Info("apisix ingress controller exited")
 		},
 	}
 
 	cmd.PersistentFlags().StringVar(&configPath, "config-path", "", "configuration file path for apisix-ingress-controller")
-	cmd.PersistentFlags().StringVar(&cfg.LogLevel, "log-level", "info", "error log level")
-	cmd.PersistentFlags().StringVar(&cfg.LogOutput, "log-output", "stderr", "error log output file")
-	cmd.PersistentFlags().StringVar(&cfg.HTTPListen, "http-listen", ":8080", "the HTTP Server listen address")
-	cmd.PersistentFlags().StringVar(&cfg.HTTPSListen, "https-listen", ":8443", "the HTTPS Server listen address")
-	cmd.PersistentFlags().StringVar(&cfg.IngressPublishService, "ingress-publish-service", "",
+	addConfigFlags(cmd.PersistentFlags(), cfg)
+
+	if err := cmd.PersistentFlags().MarkDeprecated("app-namespace", "use namespace-selector instead"); err != nil {
+		dief("failed to mark `app-namespace` as deprecated: %s", err)
+	}
+	return cmd
+}
+
+// addConfigFlags adds the command line flags of the configuration items to
+// fs, the flags are bound to the fields of cfg.
+func addConfigFlags(fs *pflag.FlagSet, cfg *config.Config) {
+	fs.StringVar(&cfg.LogLevel, "log-level", "info", "error log level")
+	fs.StringVar(&cfg.LogOutput, "log-output", "stderr", "error log output file")
+	fs.IntVar(&cfg.LogSampling.Initial, "log-sampling-initial", 0, "the number of debug and info logs with the same message logged in each second before sampling, 0 means disabled")
+	fs.IntVar(&cfg.LogSampling.Thereafter, "log-sampling-thereafter", 100, "after the initial logs, every N-th debug or info log with the same message in each second is logged")
+	fs.StringVar(&cfg.AuditLogOutput, "audit-log-output", "", "the output of the audit log which records every write to APISIX as a JSON line, can be \"stdout\", \"stderr\" or a file path, empty means disabled")
+	fs.StringVar(&cfg.HTTPListen, "http-listen", ":8080", "the HTTP Server listen address")
+	fs.StringVar(&cfg.HTTPSListen, "https-listen", ":8443", "the HTTPS Server listen address")
+	fs.StringVar(&cfg.IngressPublishService, "ingress-publish-service", "",
 		`the controller will use the Endpoint of this Service to update the status information of the Ingress resource. 
 The format is "namespace/svc-name" to solve the situation that the data plane and the controller are not deployed in the same namespace.`)
-	cmd.PersistentFlags().StringSliceVar(&cfg.IngressStatusAddress, "ingress-status-address", []string{},
+	fs.StringSliceVar(&cfg.IngressStatusAddress, "ingress-status-address", []string{},
 		`when there is no available information on the Service used for publishing on the data plane,
 the static address provided here will be used to update the status information of Ingress.
 When ingress-publish-service is specified at the same time, ingress-status-address is preferred.
 For example, no available LB exists in the bare metal environment.`)
-	cmd.PersistentFlags().BoolVar(&cfg.EnableProfiling, "enable-profiling", true, "enable profiling via web interface host:port/debug/pprof")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.Kubeconfig, "kubeconfig", "", "Kubernetes configuration file (by default in-cluster configuration will be used)")
-	cmd.PersistentFlags().DurationVar(&cfg.Kubernetes.ResyncInterval.Duration, "resync-interval", time.Minute, "the controller resync (with Kubernetes) interval, the minimum resync interval is 30s")
-	cmd.PersistentFlags().StringSliceVar(&cfg.Kubernetes.AppNamespaces, "app-namespace", []string{config.NamespaceAll}, "namespaces that controller will watch for resources.")
-	cmd.PersistentFlags().StringSliceVar(&cfg.Kubernetes.NamespaceSelector, "namespace-selector", []string{""}, "labels that controller used to select namespaces which will watch for resources")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.IngressClass, "ingress-class", config.IngressClass, "the class of an Ingress object is set using the field IngressClassName in Kubernetes clusters version v1.18.0 or higher or the annotation \"kubernetes.io/ingress.class\" (deprecated)")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ElectionID, "election-id", config.IngressAPISIXLeader, "election id used for campaign the controller leader")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.IngressVersion, "ingress-version", config.IngressNetworkingV1, "the supported ingress api group version, can be \"networking/v1beta1\", \"networking/v1\" (for Kubernetes version v1.19.0 or higher) and \"extensions/v1beta1\"")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixRouteVersion, "apisix-route-version", config.ApisixRouteV2beta3, "the supported apisixroute api group version, can be \"apisix.apache.org/v2beta2\" or \"apisix.apache.org/v2beta3\"")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixPluginConfigVersion, "apisix-plugin-config-version", config.ApisixV2beta3, "the supported ApisixPluginConfig api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixTlsVersion, "apisix-tls-version", config.ApisixV2beta3, "the supported apisixtls api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixClusterConfigVersion, "apisix-cluster-config-version", config.ApisixV2beta3, "the supported ApisixClusterConfig api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
-	cmd.PersistentFlags().StringVar(&cfg.Kubernetes.ApisixConsumerVersion, "apisix-consumer-version", config.ApisixV2beta3, "the supported ApisixConsumer api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
-	cmd.PersistentFlags().BoolVar(&cfg.Kubernetes.WatchEndpointSlices, "watch-endpointslices", false, "whether to watch endpointslices rather than endpoints")
-	cmd.PersistentFlags().BoolVar(&cfg.Kubernetes.EnableGatewayAPI, "enable-gateway-api", false, "whether to enable support for Gateway API")
-	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterBaseURL, "default-apisix-cluster-base-url", "", "the base URL of admin api / manager api for the default APISIX cluster")
-	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterAdminKey, "default-apisix-cluster-admin-key", "", "admin key used for the authorization of admin api / manager api for the default APISIX cluster")
-	cmd.PersistentFlags().StringVar(&cfg.APISIX.DefaultClusterName, "default-apisix-cluster-name", "default", "name of the default apisix cluster")
-	cmd.PersistentFlags().DurationVar(&cfg.ApisixResourceSyncInterval.Duration, "apisix-resource-sync-interval", 300*time.Second, "interval between syncs in seconds. Default value is 300s.")
-
-	if err := cmd.PersistentFlags().MarkDeprecated("app-namespace", "use namespace-selector instead"); err != nil {
-		dief("failed to mark `app-namespace` as deprecated: %s", err)
-	}
-	return cmd
+	fs.BoolVar(&cfg.EnableProfiling, "enable-profiling", false, "enable profiling via web interface host:port/debug/pprof")
+	fs.IntVar(&cfg.ProfilingMutexFraction, "profiling-mutex-fraction", 0, "the fraction of mutex contention events reported in the mutex profile, 0 disables the mutex profiling")
+	fs.IntVar(&cfg.ProfilingBlockRate, "profiling-block-rate", 0, "the rate (in nanoseconds) of blocking events reported in the block profile, 0 disables the block profiling")
+	fs.StringVar(&cfg.Kubernetes.Kubeconfig, "kubeconfig", "", "Kubernetes configuration file (by default in-cluster configuration will be used)")
+	fs.DurationVar(&cfg.Kubernetes.ResyncInterval.Duration, "resync-interval", time.Minute, "the controller resync (with Kubernetes) interval, the minimum resync interval is 30s")
+	fs.StringSliceVar(&cfg.Kubernetes.AppNamespaces, "app-namespace", []string{config.NamespaceAll}, "namespaces that controller will watch for resources.")
+	fs.StringSliceVar(&cfg.Kubernetes.NamespaceSelector, "namespace-selector", []string{""}, "label selectors that controller used to select namespaces which will watch for resources, e.g. apisix.ingress=watching, set-based selectors containing commas should be put in the configuration file")
+	fs.StringVar(&cfg.Kubernetes.IngressClass, "ingress-class", config.IngressClass, "the class of an Ingress object is set using the field IngressClassName in Kubernetes clusters version v1.18.0 or higher or the annotation \"kubernetes.io/ingress.class\" (deprecated)")
+	fs.StringVar(&cfg.Kubernetes.ElectionID, "election-id", config.IngressAPISIXLeader, "election id used for campaign the controller leader")
+	fs.DurationVar(&cfg.Kubernetes.ElectionLeaseDuration.Duration, "election-lease-duration", 15*time.Second, "the duration that the candidates wait to force acquire the leadership since it was last renewed")
+	fs.DurationVar(&cfg.Kubernetes.ElectionRenewDeadline.Duration, "election-renew-deadline", 5*time.Second, "the duration that the leader retries refreshing the leadership before giving up, should be less than the lease duration")
+	fs.DurationVar(&cfg.Kubernetes.ElectionRetryPeriod.Duration, "election-retry-period", 2*time.Second, "the duration the candidates and the leader wait between the tries of the leader election actions")
+	fs.DurationVar(&cfg.Kubernetes.ElectionDrainTimeout.Duration, "election-drain-timeout", 5*time.Second, "the maximum duration to wait for the workers to exit after their in-flight syncs are canceled since the leadership is lost")
+	fs.StringVar(&cfg.Kubernetes.IngressVersion, "ingress-version", config.IngressNetworkingV1, "the supported ingress api group version, can be \"networking/v1beta1\", \"networking/v1\" (for Kubernetes version v1.19.0 or higher) and \"extensions/v1beta1\"")
+	fs.StringVar(&cfg.Kubernetes.ApisixRouteVersion, "apisix-route-version", config.ApisixRouteV2beta3, "the supported apisixroute api group version, can be \"apisix.apache.org/v2beta2\" or \"apisix.apache.org/v2beta3\"")
+	fs.StringVar(&cfg.Kubernetes.ApisixPluginConfigVersion, "apisix-plugin-config-version", config.ApisixV2beta3, "the supported ApisixPluginConfig api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
+	fs.StringVar(&cfg.Kubernetes.ApisixTlsVersion, "apisix-tls-version", config.ApisixV2beta3, "the supported apisixtls api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
+	fs.StringVar(&cfg.Kubernetes.ApisixClusterConfigVersion, "apisix-cluster-config-version", config.ApisixV2beta3, "the supported ApisixClusterConfig api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
+	fs.StringVar(&cfg.Kubernetes.ApisixConsumerVersion, "apisix-consumer-version", config.ApisixV2beta3, "the supported ApisixConsumer api group version, can be \"apisix.apache.org/v2beta3\" or \"apisix.apache.org/v2\"")
+	fs.BoolVar(&cfg.Kubernetes.WatchEndpointSlices, "watch-endpointslices", false, "whether to watch endpointslices rather than endpoints")
+	fs.BoolVar(&cfg.Kubernetes.EnableGatewayAPI, "enable-gateway-api", false, "whether to enable support for Gateway API")
+	fs.BoolVar(&cfg.Kubernetes.AllowCrossNamespacePluginConfig, "allow-cross-namespace-plugin-config", false, "whether to allow referencing ApisixPluginConfigs in other namespaces in the form of \"namespace/name\"")
+	fs.BoolVar(&cfg.Kubernetes.AllowServerlessFunctions, "allow-serverless-functions", false, "whether to allow running inline Lua functions through the serverless field of ApisixRoute and the serverless plugins, the functions are not sandboxed")
+	fs.StringSliceVar(&cfg.Kubernetes.RouteLabelKeys, "route-label-keys", nil, "keys of the ApisixRoute labels copied to the labels of the APISIX routes")
+	fs.StringVar(&cfg.Kubernetes.RouteLabelPrefix, "route-label-prefix", "", "copy the ApisixRoute labels whose keys have the prefix to the labels of the APISIX routes")
+	fs.BoolVar(&cfg.Kubernetes.EnableFinalizers, "enable-finalizers", false, "whether to add a finalizer to ApisixRoute, ApisixTls, ApisixConsumer and ApisixPluginConfig objects, which blocks their deletion until the APISIX resources are deleted")
+	fs.StringSliceVar(&cfg.Kubernetes.EnabledControllers, "enabled-controllers", nil, "the resource controllers to run (ingress, apisix_route, apisix_upstream, apisix_tls, apisix_cluster_config, apisix_consumer, apisix_plugin_config), all controllers are enabled if it's empty")
+	fs.StringSliceVar(&cfg.Kubernetes.ServiceUpstreamAnnotations, "service-upstream-annotations", nil, "the Service annotations honored to configure the upstreams of the Service without ApisixUpstream, like k8s.apisix.apache.org/upstream-scheme")
+	fs.DurationVar(&cfg.Kubernetes.FinalizerTimeout.Duration, "finalizer-timeout", 5*time.Minute, "the maximum duration to retry deleting the APISIX resources of an object with finalizer, after which the finalizer is removed anyway")
+	fs.StringVar(&cfg.Kubernetes.ClusterDomain, "cluster-domain", "cluster.local", "the DNS domain of the cluster, the backends with the dns resolve granularity are resolved by the Service DNS names in it")
+	fs.DurationVar(&cfg.Kubernetes.LeaderResyncRamp.Duration, "leader-resync-ramp", 0, "the duration to spread the reconciliation of all resources over after the controller becomes the leader, zero means they are reconciled at once")
+	fs.BoolVar(&cfg.Kubernetes.WarnDeprecatedVersions, "warn-deprecated-versions", false, "whether to emit warnings when reconciling resources of deprecated api versions like apisix.apache.org/v2beta3")
+	fs.StringVar(&cfg.APISIX.DefaultClusterBaseURL, "default-apisix-cluster-base-url", "", "the base URL of admin api / manager api for the default APISIX cluster")
+	fs.StringVar(&cfg.APISIX.DefaultClusterAdminKey, "default-apisix-cluster-admin-key", "", "admin key used for the authorization of admin api / manager api for the default APISIX cluster")
+	fs.StringVar(&cfg.APISIX.DefaultClusterName, "default-apisix-cluster-name", "default", "name of the default apisix cluster")
+	fs.BoolVar(&cfg.APISIX.VerifySSLSNIs, "verify-ssl-snis", false, "whether to read back SSL objects from APISIX to verify all SNIs were registered")
+	fs.DurationVar(&cfg.APISIX.PluginSchemaCacheTTL.Duration, "plugin-schema-cache-ttl", 10*time.Minute, "the maximum duration to use a cached plugin schema (e.g. in the admission webhooks) before fetching it from APISIX again")
+	fs.IntVar(&cfg.APISIX.MaxRouteCount, "max-route-count", 0, "the maximum number of routes in the default APISIX cluster, routes beyond it will be rejected, 0 means no limit")
+	fs.DurationVar(&cfg.APISIX.AdminAPIHealthCheckTTL.Duration, "admin-api-health-check-ttl", 5*time.Second, "the duration to cache the result of the APISIX Admin API reachability check used by the readiness probe")
+	fs.IntVar(&cfg.APISIX.AdminAPIHealthCheckFailureThreshold, "admin-api-health-check-failure-threshold", 3, "the number of consecutive failed APISIX Admin API checks before reporting not ready")
+	fs.IntVar(&cfg.APISIX.AdminAPIMaxRetries, "admin-api-max-retries", 3, "the maximum number of retries when the APISIX Admin API responds with 429 or 503, zero means no retry")
+	fs.DurationVar(&cfg.APISIX.AdminAPIMaxRetryBackoff.Duration, "admin-api-max-retry-backoff", 5*time.Second, "the maximum delay between the retries of the APISIX Admin API requests")
+	fs.StringVar(&cfg.APISIX.IDPrefix, "id-prefix", "", "the prefix of the IDs and the consumer usernames of the APISIX objects, controllers with different prefixes ignore the objects of each other")
+	fs.StringSliceVar(&cfg.APISIX.DiscoveryTypes, "discovery-types", nil, "the service discovery types enabled in APISIX (dns, consul, consul_kv, nacos, eureka, kubernetes), which can be used by ApisixUpstreams")
+	fs.IntVar(&cfg.APISIX.AdminAPIBatchConcurrency, "admin-api-batch-concurrency", 8, "the maximum number of in-flight APISIX Admin API writes of a cluster when resources are written in batches")
+	fs.DurationVar(&cfg.APISIX.AdminAPITimeout.Duration, "admin-api-timeout", 5*time.Second, "the timeout of an APISIX Admin API request, timed out requests fail and are retried later")
+	fs.DurationVar(&cfg.APISIX.AdminAPIDialTimeout.Duration, "admin-api-dial-timeout", 3*time.Second, "the timeout to connect to the APISIX Admin API")
+	fs.IntVar(&cfg.APISIX.AdminAPIMaxIdleConnsPerHost, "admin-api-max-idle-conns-per-host", 8, "the maximum number of idle connections kept to the APISIX Admin API of a cluster")
+	fs.BoolVar(&cfg.APISIX.RollbackOnFailure, "rollback-on-failure", false, "whether to roll back the objects already applied in a sync when a later one fails")
+	fs.DurationVar(&cfg.ApisixResourceSyncInterval.Duration, "apisix-resource-sync-interval", 300*time.Second, "interval between syncs in seconds. Default value is 300s.")
+	fs.Float64Var(&cfg.ApisixResourceSyncJitter, "apisix-resource-sync-jitter", 0.1, "the fraction of apisix-resource-sync-interval which is randomly added to each sync interval, should be in the range [0, 1]")
+	fs.IntVar(&cfg.Workers, "workers", 1, "the number of workers of each resource controller, i.e. how many objects of a kind are synced concurrently")
+	fs.StringVar(&cfg.ResyncToken, "resync-token", "", "the bearer token of the requests to trigger a full resync through host:port/debug/resync, empty means the endpoint is disabled")
+	fs.DurationVar(&cfg.ResyncTimeout.Duration, "resync-timeout", 5*time.Minute, "the maximum duration to wait for a resync triggered through host:port/debug/resync to complete, 0 means no limit")
+	fs.DurationVar(&cfg.ReadinessTimeout.Duration, "readiness-timeout", 5*time.Minute, "the maximum duration to wait for the initial sync before reporting ready, 0 means waiting forever")
+	fs.DurationVar(&cfg.ConsumerRevalidateInterval.Duration, "consumer-revalidate-interval", 0, "the interval to re-validate ApisixConsumers against the latest plugin schemas from APISIX, 0 means disabled")
+	fs.DurationVar(&cfg.EndpointBatchWindow.Duration, "endpoint-batch-window", 0, "the duration to collect upstream nodes changes caused by endpoints before pushing them to APISIX, 0 means pushing immediately")
+	fs.IntVar(&cfg.EndpointBatchMax, "endpoint-batch-max", 0, "the maximum number of upstreams in an endpoint batch, a full batch is pushed without waiting for the window, 0 means no limit")
+	fs.DurationVar(&cfg.EndpointDebounceWindow.Duration, "endpoint-debounce-window", 0, "the quiet duration after the last endpoints change of a service before syncing it, the changes in between are coalesced, 0 means syncing every change")
+	fs.DurationVar(&cfg.EndpointDebounceMaxWait.Duration, "endpoint-debounce-max-wait", 0, "the maximum delay of the endpoints changes of a service by the debounce window, 0 means ten times the window")
+	fs.StringVar(&cfg.ApisixUpstreamDefaults.LoadBalancer, "apisix-upstream-default-loadbalancer", "roundrobin", "the load balancer type filled in ApisixUpstreams without one by the mutating webhook, can be \"roundrobin\", \"ewma\" or \"least_conn\", empty means no default")
+	fs.StringVar(&cfg.ApisixUpstreamDefaults.Scheme, "apisix-upstream-default-scheme", "http", "the scheme filled in ApisixUpstreams without one by the mutating webhook, empty means no default")
+	fs.DurationVar(&cfg.ApisixUpstreamDefaults.HealthCheckInterval.Duration, "apisix-upstream-default-health-check-interval", time.Second, "the interval filled in the active health check healthy and unhealthy probes of ApisixUpstreams without one by the mutating webhook, 0 means no default")
+	fs.StringVar(&cfg.OrphanGC, "orphan-gc", config.OrphanGCDisabled, "the garbage collection mode of the orphan APISIX resources which are managed by the controller, can be \"disabled\", \"dry-run\" or \"enabled\"")
 }
@@ -96,6 +96,61 @@ func TestSignalHandler(t *testing.T) {
 	assert.Contains(t, msg, "apisix ingress controller exited")
 }
 
+func TestSignalHandlerReload(t *testing.T) {
+	listen := getRandomListen()
+	configTemplate := `{
+  "log_level": "%s",
+  "http_listen": "%s",
+  "kubernetes": {
+    "kubeconfig": "/foo/bar/baz",
+    "resync_interval": "%s"
+  },
+  "apisix": {
+    "default_cluster_base_url": "http://apisixgw.default.cluster.local/apisix"
+  },
+  "apisix-resource-sync-interval": "%s"
+}`
+	configFile, err := os.CreateTemp("", "apisix-ingress-controller-*.json")
+	assert.Nil(t, err)
+	defer os.Remove(configFile.Name())
+	_, err = fmt.Fprintf(configFile, configTemplate, "info", listen, "24h", "300s")
+	assert.Nil(t, err)
+	assert.Nil(t, configFile.Close())
+
+	cmd := NewIngressCommand()
+	cmd.SetArgs([]string{"--config-path", configFile.Name()})
+	waitCh := make(chan struct{})
+	go func() {
+		if err := cmd.Execute(); err != nil {
+			log.Errorf("failed to execute command: %s", err)
+		}
+		close(waitCh)
+	}()
+
+	time.Sleep(5 * time.Second)
+	fws := &fakeWriteSyncer{}
+	logger, err := log.NewLogger(log.WithLogLevel("info"), log.WithWriteSyncer(fws))
+	assert.Nil(t, err)
+	defer logger.Close()
+	log.DefaultLogger = logger
+
+	data := fmt.Sprintf(configTemplate, "debug", listen, "12h", "600s")
+	assert.Nil(t, os.WriteFile(configFile.Name(), []byte(data), 0644))
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	time.Sleep(time.Second)
+	assert.Nil(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	<-waitCh
+	logger.Debug("log level reloaded")
+
+	msg := fws.buf.String()
+	assert.Contains(t, msg, fmt.Sprintf("signal %d (%s) received", syscall.SIGHUP, syscall.SIGHUP.String()))
+	assert.Contains(t, msg, "config item kubernetes.resync_interval is changed, it takes effect after restarting")
+	assert.NotContains(t, msg, "config item apisix-resource-sync-interval")
+	assert.Contains(t, msg, "configuration reloaded")
+	assert.Contains(t, msg, "log level reloaded")
+	assert.Contains(t, msg, "apisix ingress controller exited")
+}
+
 func TestNewIngressCommandEffectiveLog(t *testing.T) {
 	listen := getRandomListen()
 	cmd := NewIngressCommand()
@@ -162,3 +217,58 @@ func parseLog(t *testing.T, r *bufio.Reader) *fields {
 	assert.Nil(t, err)
 	return &f
 }
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	configFile, err := os.CreateTemp("", "apisix-ingress-controller-*.yaml")
+	assert.Nil(t, err)
+	defer os.Remove(configFile.Name())
+	_, err = configFile.WriteString(`
+log_level: warn
+http_listen: ":9090"
+kubernetes:
+  resync_interval: 1h
+  app_namespaces: ["file"]
+apisix:
+  default_cluster_base_url: http://file:9180/apisix/admin
+`)
+	assert.Nil(t, err)
+	assert.Nil(t, configFile.Close())
+
+	t.Setenv("APISIX_INGRESS_LOG_LEVEL", "error")
+	t.Setenv("APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL", "2h")
+	t.Setenv("APISIX_INGRESS_KUBERNETES_APP_NAMESPACES", "env1,env2")
+
+	cmd := NewIngressCommand()
+	fs := cmd.PersistentFlags()
+	assert.Nil(t, fs.Parse([]string{
+		"--config-path", configFile.Name(),
+		"--log-level", "debug",
+		"--app-namespace", "flag",
+	}))
+
+	flagCfg := config.NewDefaultConfig()
+	cfg, err := loadConfig(configFile.Name(), flagCfg, newFlagOverrides(fs))
+	assert.Nil(t, err)
+	// Flags take precedence over the environment variables and the file.
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, []string{"flag"}, cfg.Kubernetes.AppNamespaces)
+	// Environment variables take precedence over the file.
+	assert.Equal(t, 2*time.Hour, cfg.Kubernetes.ResyncInterval.Duration)
+	// Items only in the file are kept.
+	assert.Equal(t, ":9090", cfg.HTTPListen)
+	assert.Equal(t, "http://file:9180/apisix/admin", cfg.APISIX.DefaultClusterBaseURL)
+
+	// Without a configuration file, the flag defaults are the lowest layer.
+	cmd = NewIngressCommand()
+	fs = cmd.PersistentFlags()
+	assert.Nil(t, fs.Parse([]string{"--http-listen", ":9091"}))
+	cfg, err = loadConfig("", config.NewDefaultConfig(), newFlagOverrides(fs))
+	assert.Nil(t, err)
+	assert.Equal(t, "error", cfg.LogLevel)
+	assert.Equal(t, ":9091", cfg.HTTPListen)
+	assert.Equal(t, []string{"env1", "env2"}, cfg.Kubernetes.AppNamespaces)
+
+	t.Setenv("APISIX_INGRESS_LOG_SAMPLING_INITIAL", "many")
+	_, err = loadConfig("", config.NewDefaultConfig(), newFlagOverrides(fs))
+	assert.NotNil(t, err)
+}
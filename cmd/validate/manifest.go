@@ -0,0 +1,421 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package validate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	apisixfake "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixscheme "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/scheme"
+	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// _stubServiceClusterIP is the cluster IP of the Services which are
+// referenced but not found in the manifests.
+const _stubServiceClusterIP = "127.0.0.1"
+
+var _decoder runtime.Decoder
+
+func init() {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	if err := apisixscheme.AddToScheme(scheme); err != nil {
+		panic(err)
+	}
+	// Unknown fields are rejected, so that typos in the manifests are
+	// caught rather than silently ignored by the API server.
+	_decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDeserializer()
+}
+
+// manifest is a decoded Kubernetes object.
+type manifest struct {
+	// source is the file and the index of the YAML document, e.g. route.yaml#2.
+	source string
+	obj    runtime.Object
+}
+
+func (m *manifest) String() string {
+	kind := m.obj.GetObjectKind().GroupVersionKind().Kind
+	accessor, err := meta.Accessor(m.obj)
+	if err != nil {
+		return fmt.Sprintf("%s %s", m.source, kind)
+	}
+	return fmt.Sprintf("%s %s %s/%s", m.source, kind, accessor.GetNamespace(), accessor.GetName())
+}
+
+// result is the validation result of a manifest.
+type result struct {
+	source string
+	err    error
+}
+
+func (r *result) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("%s: invalid: %s", r.source, r.err)
+	}
+	return fmt.Sprintf("%s: valid", r.source)
+}
+
+func namespaceOf(obj metav1.Object) string {
+	if obj.GetNamespace() == "" {
+		return metav1.NamespaceDefault
+	}
+	return obj.GetNamespace()
+}
+
+// readManifests decodes the YAML (or JSON) documents, the ones which
+// cannot be decoded are returned as invalid results, and the ones of
+// unknown kinds are skipped.
+func readManifests(source string, r io.Reader) ([]*manifest, []*result, error) {
+	var (
+		manifests []*manifest
+		results   []*result
+	)
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for i := 1; ; i++ {
+		doc, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %s", source, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		docSource := fmt.Sprintf("%s#%d", source, i)
+		obj, _, err := _decoder.Decode(doc, nil, nil)
+		if err != nil {
+			if runtime.IsNotRegisteredError(err) {
+				continue
+			}
+			results = append(results, &result{source: docSource, err: err})
+			continue
+		}
+		manifests = append(manifests, &manifest{source: docSource, obj: defaultNamespace(obj)})
+	}
+	return manifests, results, nil
+}
+
+// validateManifests translates the APISIX resources in the manifests,
+// the other manifests are only used as the translation context.
+func validateManifests(manifests []*manifest) ([]*result, error) {
+	tr, stop, err := newTranslator(manifests)
+	if err != nil {
+		return nil, err
+	}
+	defer close(stop)
+
+	var results []*result
+	for _, m := range manifests {
+		ok, err := translate(tr, m.obj)
+		if !ok {
+			continue
+		}
+		results = append(results, &result{source: m.String(), err: err})
+	}
+	return results, nil
+}
+
+// translate translates the object, ok is false if the object is not
+// an APISIX resource to validate.
+func translate(tr translation.Translator, obj runtime.Object) (ok bool, err error) {
+	switch o := obj.(type) {
+	case *configv2beta2.ApisixRoute:
+		_, err = tr.TranslateRouteV2beta2(o)
+	case *configv2beta3.ApisixRoute:
+		_, err = tr.TranslateRouteV2beta3(o)
+	case *configv2.ApisixRoute:
+		_, err = tr.TranslateRouteV2(o)
+	case *configv2beta3.ApisixUpstream:
+		err = translateApisixUpstream(tr, o)
+	case *configv2beta3.ApisixPluginConfig:
+		_, err = tr.TranslatePluginConfigV2beta3(o)
+	case *configv2.ApisixPluginConfig:
+		_, err = tr.TranslatePluginConfigV2(o)
+	case *configv2beta3.ApisixConsumer:
+		_, err = tr.TranslateApisixConsumerV2beta3(o)
+	case *configv2.ApisixConsumer:
+		_, err = tr.TranslateApisixConsumerV2(o)
+	case *configv2beta3.ApisixClusterConfig:
+		_, err = tr.TranslateClusterConfigV2beta3(o)
+	case *configv2.ApisixClusterConfig:
+		if _, err = tr.TranslateClusterConfigV2(o); err == nil {
+			_, err = tr.TranslatePluginMetadataV2(o)
+		}
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+func translateApisixUpstream(tr translation.Translator, au *configv2beta3.ApisixUpstream) error {
+	if au.Spec == nil {
+		return nil
+	}
+	if _, err := tr.TranslateUpstreamConfig(&au.Spec.ApisixUpstreamConfig); err != nil {
+		return err
+	}
+	for _, pls := range au.Spec.PortLevelSettings {
+		if _, err := tr.TranslateUpstreamConfig(&pls.ApisixUpstreamConfig); err != nil {
+			return fmt.Errorf("portLevelSettings[%d]: %s", pls.Port, err)
+		}
+	}
+	return nil
+}
+
+// newTranslator creates a Translator whose listers are backed by fake
+// clients filled with the manifests, the returned channel should be
+// closed once the Translator is no longer used.
+func newTranslator(manifests []*manifest) (translation.Translator, chan struct{}, error) {
+	kubeClient := fake.NewSimpleClientset()
+	apisixClient := apisixfake.NewSimpleClientset()
+	for _, m := range manifests {
+		var err error
+		switch m.obj.(type) {
+		case *corev1.Service, *corev1.Endpoints, *corev1.Secret, *corev1.ConfigMap, *corev1.Pod:
+			err = kubeClient.Tracker().Add(m.obj)
+		case *configv2beta3.ApisixUpstream, *configv2.ApisixRateLimitPolicy:
+			err = apisixClient.Tracker().Add(m.obj)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %s", m, err)
+		}
+	}
+	if err := addStubServices(kubeClient, manifests); err != nil {
+		return nil, nil, err
+	}
+
+	kubeFactory := informers.NewSharedInformerFactory(kubeClient, 0)
+	apisixFactory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	epLister, _ := kube.NewEndpointListerAndInformer(kubeFactory, false)
+	tr := translation.NewTranslator(&translation.TranslatorOptions{
+		PodCache:             types.NewPodCache(),
+		PodLister:            kubeFactory.Core().V1().Pods().Lister(),
+		EndpointLister:       epLister,
+		ServiceLister:        kubeFactory.Core().V1().Services().Lister(),
+		ApisixUpstreamLister: apisixFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+		SecretLister:         kubeFactory.Core().V1().Secrets().Lister(),
+		ConfigMapLister:      kubeFactory.Core().V1().ConfigMaps().Lister(),
+
+		ApisixRateLimitPolicyLister: apisixFactory.Apisix().V2().ApisixRateLimitPolicies().Lister(),
+	})
+
+	stop := make(chan struct{})
+	kubeFactory.Start(stop)
+	apisixFactory.Start(stop)
+	kubeFactory.WaitForCacheSync(stop)
+	apisixFactory.WaitForCacheSync(stop)
+	return tr, stop, nil
+}
+
+// defaultNamespace sets the namespace of the object to "default" if it's
+// empty, as kubectl does.
+func defaultNamespace(obj runtime.Object) runtime.Object {
+	accessor, err := meta.Accessor(obj)
+	if err == nil {
+		accessor.SetNamespace(namespaceOf(accessor))
+	}
+	return obj
+}
+
+// addStubServices adds the Services referenced by the ApisixRoutes but
+// not found in the manifests, and empty Endpoints for the Services which
+// don't have one, so that the routes can be translated offline.
+func addStubServices(client *fake.Clientset, manifests []*manifest) error {
+	type serviceKey struct {
+		namespace string
+		name      string
+	}
+	services := make(map[serviceKey]*corev1.Service)
+	endpoints := make(map[serviceKey]struct{})
+	var stubs []*corev1.Service
+	for _, m := range manifests {
+		switch o := m.obj.(type) {
+		case *corev1.Service:
+			services[serviceKey{o.Namespace, o.Name}] = o
+		case *corev1.Endpoints:
+			endpoints[serviceKey{o.Namespace, o.Name}] = struct{}{}
+		}
+	}
+	for _, m := range manifests {
+		switch m.obj.(type) {
+		case *configv2beta2.ApisixRoute, *configv2beta3.ApisixRoute, *configv2.ApisixRoute:
+		default:
+			continue
+		}
+		accessor, err := meta.Accessor(m.obj)
+		if err != nil {
+			continue
+		}
+		ns := accessor.GetNamespace()
+		backends, err := collectBackends(m.obj)
+		if err != nil {
+			return fmt.Errorf("%s: %s", m, err)
+		}
+		for _, backend := range backends {
+			key := serviceKey{ns, backend.ServiceName}
+			svc, ok := services[key]
+			if !ok {
+				svc = &corev1.Service{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      backend.ServiceName,
+						Namespace: ns,
+					},
+					Spec: corev1.ServiceSpec{
+						ClusterIP: _stubServiceClusterIP,
+					},
+				}
+				services[key] = svc
+				stubs = append(stubs, svc)
+			} else if !isStub(svc, stubs) {
+				continue
+			}
+			addStubServicePort(svc, backend.ServicePort)
+		}
+	}
+	for _, svc := range stubs {
+		if err := client.Tracker().Add(svc); err != nil {
+			return err
+		}
+	}
+	for key := range services {
+		if _, ok := endpoints[key]; ok {
+			continue
+		}
+		ep := &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      key.name,
+				Namespace: key.namespace,
+			},
+		}
+		if err := client.Tracker().Add(ep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isStub(svc *corev1.Service, stubs []*corev1.Service) bool {
+	for _, stub := range stubs {
+		if stub == svc {
+			return true
+		}
+	}
+	return false
+}
+
+func addStubServicePort(svc *corev1.Service, port intstr.IntOrString) {
+	sp := corev1.ServicePort{
+		Name:       fmt.Sprintf("port-%d", port.IntVal),
+		Port:       port.IntVal,
+		TargetPort: port,
+	}
+	if port.Type == intstr.String {
+		// Named ports get distinct numbers as they may be referenced
+		// together.
+		sp.Name = port.StrVal
+		sp.Port = int32(80 + len(svc.Spec.Ports))
+		sp.TargetPort = intstr.FromInt(int(sp.Port))
+	}
+	for _, p := range svc.Spec.Ports {
+		if p.Name == sp.Name {
+			return
+		}
+	}
+	svc.Spec.Ports = append(svc.Spec.Ports, sp)
+}
+
+// backendRef is a reference to a Service port, it matches the backends
+// of all the ApisixRoute versions.
+type backendRef struct {
+	ServiceName string             `json:"serviceName"`
+	ServicePort intstr.IntOrString `json:"servicePort"`
+}
+
+// collectBackends finds all the backends in the ApisixRoute spec, it
+// walks the JSON representation so that the backends of all versions and
+// places (http, stream, canary, etc.) are found alike.
+func collectBackends(obj runtime.Object) ([]backendRef, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	var (
+		backends []backendRef
+		walk     func(interface{}) error
+	)
+	walk = func(v interface{}) error {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			if name, ok := vv["serviceName"].(string); ok && name != "" {
+				if _, ok := vv["servicePort"]; ok {
+					raw, err := json.Marshal(vv)
+					if err != nil {
+						return err
+					}
+					var ref backendRef
+					if err := json.Unmarshal(raw, &ref); err != nil {
+						return err
+					}
+					backends = append(backends, ref)
+				}
+			}
+			for _, child := range vv {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			for _, child := range vv {
+				if err := walk(child); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk(root); err != nil {
+		return nil, err
+	}
+	return backends, nil
+}
@@ -0,0 +1,116 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package validate
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// NewValidateCommand creates the validate sub command for apisix-ingress-controller.
+func NewValidateCommand() *cobra.Command {
+	var logLevel string
+	cmd := &cobra.Command{
+		Use:   "validate [file...]",
+		Short: "validate APISIX CRD manifests offline",
+		Long: `validate APISIX CRD manifests offline
+
+The manifests are read from the given files, or from stdin if no file is given (or the file is "-"),
+and translated by the same translator as the ingress controller, so the errors which would be
+reported in the resource status are caught without a Kubernetes cluster or APISIX:
+
+    apisix-ingress-controller validate route.yaml upstream.yaml
+    cat route.yaml | apisix-ingress-controller validate
+
+ApisixRoutes, ApisixUpstreams, ApisixPluginConfigs, ApisixConsumers and ApisixClusterConfigs are
+validated. Services, Endpoints, Secrets, ConfigMaps, ApisixUpstreams and ApisixRateLimitPolicies in
+the manifests are used when translating the others, Services referenced by ApisixRoutes but not
+found in the manifests are assumed to exist and expose the referenced ports.
+
+The command exits with a non-zero status if any manifest is invalid.`,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			logger, err := log.NewLogger(log.WithLogLevel(logLevel))
+			if err != nil {
+				return fmt.Errorf("failed to initialize logging: %s", err)
+			}
+			log.DefaultLogger = logger
+
+			if len(args) == 0 {
+				args = []string{"-"}
+			}
+			var (
+				manifests []*manifest
+				results   []*result
+			)
+			for _, file := range args {
+				ms, rs, err := readFile(file)
+				if err != nil {
+					return err
+				}
+				manifests = append(manifests, ms...)
+				results = append(results, rs...)
+			}
+			vr, err := validateManifests(manifests)
+			if err != nil {
+				return err
+			}
+			results = append(results, vr...)
+
+			invalid := 0
+			out := cmd.OutOrStdout()
+			for _, r := range results {
+				if r.err != nil {
+					invalid++
+				}
+				fmt.Fprintln(out, r)
+			}
+			if invalid > 0 {
+				return fmt.Errorf("%d of %d manifests are invalid", invalid, len(results))
+			}
+			return nil
+		},
+	}
+
+	cmd.PersistentFlags().StringVar(&logLevel, "log-level", "fatal", "log level of the translator, the translation errors are always reported")
+	return cmd
+}
+
+// readFile reads the manifests from the file, "-" means stdin. The
+// documents which cannot be decoded are returned as invalid results.
+func readFile(file string) ([]*manifest, []*result, error) {
+	var (
+		r      io.Reader
+		source = file
+	)
+	if file == "-" {
+		r = os.Stdin
+		source = "stdin"
+	} else {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	return readManifests(source, r)
+}
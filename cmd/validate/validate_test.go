@@ -0,0 +1,155 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package validate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const _manifests = `
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: httpbin
+spec:
+  http:
+    - name: rule1
+      match:
+        paths:
+          - /*
+      backends:
+        - serviceName: httpbin
+          servicePort: 80
+      plugins:
+        - name: cors
+          enable: true
+---
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: bad-rewrite
+  namespace: test
+spec:
+  http:
+    - name: rule1
+      match:
+        paths:
+          - /*
+      backends:
+        - serviceName: httpbin
+          servicePort: http
+      rewrite:
+        regex: "^/(["
+        replacement: /
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: plugins
+data:
+  plugins.json: '{"cors": {}}'
+---
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: plugins-from
+spec:
+  http:
+    - name: rule1
+      match:
+        paths:
+          - /*
+      backends:
+        - serviceName: httpbin
+          servicePort: 80
+      pluginsFrom:
+        configMapName: plugins
+---
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixUpstream
+metadata:
+  name: other
+spec:
+  loadbalancer:
+    type: bad
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: foo
+`
+
+func TestValidateManifests(t *testing.T) {
+	manifests, results, err := readManifests("test.yaml", strings.NewReader(_manifests))
+	assert.Nil(t, err)
+	assert.Len(t, manifests, 5)
+	assert.Len(t, results, 0)
+
+	results, err = validateManifests(manifests)
+	assert.Nil(t, err)
+	assert.Len(t, results, 4)
+	assert.Equal(t, "test.yaml#1 ApisixRoute default/httpbin: valid", results[0].String())
+	assert.Contains(t, results[1].String(), "test.yaml#2 ApisixRoute test/bad-rewrite: invalid: rewrite.regex: ")
+	assert.Equal(t, "test.yaml#4 ApisixRoute default/plugins-from: valid", results[2].String())
+	assert.Equal(t, "test.yaml#5 ApisixUpstream default/other: invalid: loadbalancer.type: invalid value", results[3].String())
+}
+
+func TestReadManifestsUnknownField(t *testing.T) {
+	_, results, err := readManifests("test.yaml", strings.NewReader(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: httpbin
+spec:
+  http:
+    - name: rule1
+      backend:
+        serviceName: httpbin
+        servicePort: 80
+`))
+	assert.Nil(t, err)
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].String(), "test.yaml#1: invalid: ")
+	assert.Contains(t, results[0].String(), "unknown field")
+}
+
+func TestValidateCommand(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yaml")
+	assert.Nil(t, os.WriteFile(valid, []byte(_manifests[:strings.Index(_manifests, "---")]), 0644))
+	invalid := filepath.Join(dir, "invalid.yaml")
+	assert.Nil(t, os.WriteFile(invalid, []byte(_manifests), 0644))
+
+	var out bytes.Buffer
+	cmd := NewValidateCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{valid})
+	assert.Nil(t, cmd.Execute())
+	assert.Contains(t, out.String(), "valid.yaml#1 ApisixRoute default/httpbin: valid")
+
+	out.Reset()
+	cmd = NewValidateCommand()
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{valid, invalid})
+	err := cmd.Execute()
+	assert.NotNil(t, err)
+	assert.Equal(t, "2 of 5 manifests are invalid", err.Error())
+}
@@ -0,0 +1,96 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mutation
+
+import (
+	"context"
+	"errors"
+
+	kwhmodel "github.com/slok/kubewebhook/v2/pkg/model"
+	kwhmutating "github.com/slok/kubewebhook/v2/pkg/webhook/mutating"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// errNotApisixUpstream will be used when the mutating object is not ApisixUpstream.
+var errNotApisixUpstream = errors.New("object is not ApisixUpstream")
+
+// NewApisixUpstreamMutator returns a Mutator which fills the unset load
+// balancer, scheme and active health check intervals of ApisixUpstream with
+// the defaults, port level settings are left alone since they inherit the
+// outer ones.
+func NewApisixUpstreamMutator(defaults *config.ApisixUpstreamDefaultsConfig) kwhmutating.Mutator {
+	return kwhmutating.MutatorFunc(
+		func(ctx context.Context, review *kwhmodel.AdmissionReview, object metav1.Object) (*kwhmutating.MutatorResult, error) {
+			log.Debug("arrive ApisixUpstream mutator webhook")
+
+			switch au := object.(type) {
+			case *v2beta3.ApisixUpstream:
+				if au.Spec != nil {
+					defaultApisixUpstreamConfigV2beta3(&au.Spec.ApisixUpstreamConfig, defaults)
+				}
+			case *v2.ApisixUpstream:
+				if au.Spec != nil {
+					defaultApisixUpstreamConfigV2(&au.Spec.ApisixUpstreamConfig, defaults)
+				}
+			default:
+				return nil, errNotApisixUpstream
+			}
+			return &kwhmutating.MutatorResult{MutatedObject: object}, nil
+		},
+	)
+}
+
+func defaultApisixUpstreamConfigV2beta3(au *v2beta3.ApisixUpstreamConfig, defaults *config.ApisixUpstreamDefaultsConfig) {
+	if au.LoadBalancer == nil && defaults.LoadBalancer != "" {
+		au.LoadBalancer = &v2beta3.LoadBalancer{Type: defaults.LoadBalancer}
+	}
+	if au.Scheme == "" {
+		au.Scheme = defaults.Scheme
+	}
+	if au.HealthCheck == nil || au.HealthCheck.Active == nil {
+		return
+	}
+	interval := metav1.Duration{Duration: defaults.HealthCheckInterval.Duration}
+	if au.HealthCheck.Active.Healthy != nil && au.HealthCheck.Active.Healthy.Interval.Duration == 0 {
+		au.HealthCheck.Active.Healthy.Interval = interval
+	}
+	if au.HealthCheck.Active.Unhealthy != nil && au.HealthCheck.Active.Unhealthy.Interval.Duration == 0 {
+		au.HealthCheck.Active.Unhealthy.Interval = interval
+	}
+}
+
+func defaultApisixUpstreamConfigV2(au *v2.ApisixUpstreamConfig, defaults *config.ApisixUpstreamDefaultsConfig) {
+	if au.LoadBalancer == nil && defaults.LoadBalancer != "" {
+		au.LoadBalancer = &v2.LoadBalancer{Type: defaults.LoadBalancer}
+	}
+	if au.Scheme == "" {
+		au.Scheme = defaults.Scheme
+	}
+	if au.HealthCheck == nil || au.HealthCheck.Active == nil {
+		return
+	}
+	interval := metav1.Duration{Duration: defaults.HealthCheckInterval.Duration}
+	if au.HealthCheck.Active.Healthy != nil && au.HealthCheck.Active.Healthy.Interval.Duration == 0 {
+		au.HealthCheck.Active.Healthy.Interval = interval
+	}
+	if au.HealthCheck.Active.Unhealthy != nil && au.HealthCheck.Active.Unhealthy.Interval.Duration == 0 {
+		au.HealthCheck.Active.Unhealthy.Interval = interval
+	}
+}
@@ -0,0 +1,77 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mutation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestApisixUpstreamMutator(t *testing.T) {
+	mutator := NewApisixUpstreamMutator(&config.ApisixUpstreamDefaultsConfig{
+		LoadBalancer:        "roundrobin",
+		Scheme:              "http",
+		HealthCheckInterval: types.TimeDuration{Duration: 2 * time.Second},
+	})
+
+	au := &v2beta3.ApisixUpstream{
+		Spec: &v2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: v2beta3.ApisixUpstreamConfig{
+				HealthCheck: &v2beta3.HealthCheck{
+					Active: &v2beta3.ActiveHealthCheck{
+						Healthy: &v2beta3.ActiveHealthCheckHealthy{
+							Interval: metav1.Duration{Duration: 5 * time.Second},
+						},
+						Unhealthy: &v2beta3.ActiveHealthCheckUnhealthy{},
+					},
+				},
+			},
+		},
+	}
+	_, err := mutator.Mutate(context.Background(), nil, au)
+	assert.Nil(t, err)
+	assert.Equal(t, &v2beta3.LoadBalancer{Type: "roundrobin"}, au.Spec.LoadBalancer)
+	assert.Equal(t, "http", au.Spec.Scheme)
+	assert.Equal(t, 5*time.Second, au.Spec.HealthCheck.Active.Healthy.Interval.Duration)
+	assert.Equal(t, 2*time.Second, au.Spec.HealthCheck.Active.Unhealthy.Interval.Duration)
+
+	// The set fields are kept.
+	au2 := &v2.ApisixUpstream{
+		Spec: &v2.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: v2.ApisixUpstreamConfig{
+				LoadBalancer: &v2.LoadBalancer{Type: "ewma"},
+				Scheme:       "grpc",
+			},
+		},
+	}
+	_, err = mutator.Mutate(context.Background(), nil, au2)
+	assert.Nil(t, err)
+	assert.Equal(t, &v2.LoadBalancer{Type: "ewma"}, au2.Spec.LoadBalancer)
+	assert.Equal(t, "grpc", au2.Spec.Scheme)
+	assert.Nil(t, au2.Spec.HealthCheck)
+
+	_, err = mutator.Mutate(context.Background(), nil, &corev1.Service{})
+	assert.Equal(t, errNotApisixUpstream, err)
+}
@@ -0,0 +1,42 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package mutation
+
+import (
+	"github.com/gin-gonic/gin"
+	kwhhttp "github.com/slok/kubewebhook/v2/pkg/http"
+	kwhmutating "github.com/slok/kubewebhook/v2/pkg/webhook/mutating"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// NewHandlerFunc returns a HandlerFunc to handle admission reviews using the given mutator.
+func NewHandlerFunc(ID string, mutator kwhmutating.Mutator) gin.HandlerFunc {
+	// Create a mutating webhook.
+	wh, err := kwhmutating.NewWebhook(kwhmutating.WebhookConfig{
+		ID:      ID,
+		Mutator: mutator,
+	})
+	if err != nil {
+		log.Errorf("failed to create webhook: %s", err)
+	}
+
+	h, err := kwhhttp.HandlerFor(kwhhttp.HandlerConfig{Webhook: wh})
+	if err != nil {
+		log.Errorf("failed to create webhook handle: %s", err)
+	}
+
+	return gin.WrapH(h)
+}
@@ -0,0 +1,65 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+type watchedNamespace struct {
+	Name       string `json:"name"`
+	SelectedBy string `json:"selected_by"`
+}
+
+type namespacesResponse struct {
+	Namespaces []watchedNamespace `json:"namespaces"`
+}
+
+// MountNamespaces mounts the route which lists the watched namespaces, the
+// requests should carry the token in the "Authorization: Bearer <token>"
+// header.
+func MountNamespaces(r *gin.Engine, state *NamespacesState, token string) {
+	r.GET("/debug/namespaces", namespaces(state, token))
+}
+
+func namespaces(state *NamespacesState, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorized(c, token) {
+			return
+		}
+
+		state.RLock()
+		source := state.Namespaces
+		state.RUnlock()
+
+		if source == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				healthzResponse{Status: "namespaces are not watched since the controller is not leading"})
+			return
+		}
+		resp := namespacesResponse{Namespaces: []watchedNamespace{}}
+		for name, selectedBy := range source() {
+			resp.Namespaces = append(resp.Namespaces, watchedNamespace{Name: name, SelectedBy: selectedBy})
+		}
+		sort.Slice(resp.Namespaces, func(i, j int) bool {
+			return resp.Namespaces[i].Name < resp.Namespaces[j].Name
+		})
+		c.AbortWithStatusJSON(http.StatusOK, resp)
+	}
+}
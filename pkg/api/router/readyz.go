@@ -0,0 +1,48 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MountReadyz mounts the readiness route, the controller is not ready if the
+// APISIX Admin API is unreachable.
+func MountReadyz(r *gin.Engine, state *ReadinessState, adminAPIState *AdminAPIHealthState) {
+	r.GET("/readyz", readyz(state, adminAPIState))
+}
+
+func readyz(state *ReadinessState, adminAPIState *AdminAPIHealthState) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state.RLock()
+		ready := state.Ready
+		state.RUnlock()
+
+		if !ready {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				healthzResponse{Status: "initial sync is in progress"})
+			return
+		}
+		if err := adminAPIState.Err(c.Request.Context()); err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				healthzResponse{Status: "APISIX Admin API is unreachable: " + err.Error()})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusOK, healthzResponse{Status: "ok"})
+	}
+}
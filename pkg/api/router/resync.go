@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package router
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MountResync mounts the route which triggers a full resync, the requests
+// should carry the token in the "Authorization: Bearer <token>" header.
+func MountResync(r *gin.Engine, state *ResyncState, token string) {
+	r.POST("/debug/resync", resync(state, token))
+}
+
+// resyncTimeoutResponse is responded when a resync doesn't complete in time.
+type resyncTimeoutResponse struct {
+	Status   string         `json:"status"`
+	Progress ResyncProgress `json:"progress"`
+}
+
+// authorized checks the bearer token of the request, it responds 401 and
+// returns false if the token is missing or wrong.
+func authorized(c *gin.Context, token string) bool {
+	auth := c.GetHeader("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, healthzResponse{Status: "unauthorized"})
+		return false
+	}
+	return true
+}
+
+func resync(state *ResyncState, token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !authorized(c, token) {
+			return
+		}
+
+		state.RLock()
+		trigger := state.Resync
+		timeout := state.Timeout
+		state.RUnlock()
+
+		if trigger == nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable,
+				healthzResponse{Status: "resources are not synced since the controller is not leading"})
+			return
+		}
+		done, progress := trigger()
+		if c.Query("wait") == "false" {
+			c.AbortWithStatusJSON(http.StatusAccepted, healthzResponse{Status: "resync triggered"})
+			return
+		}
+		// The queued resources might never be observed, e.g. they are
+		// deduplicated by the workqueue, so the wait is bounded.
+		var timeoutCh <-chan time.Time
+		if timeout > 0 {
+			timer := time.NewTimer(timeout)
+			defer timer.Stop()
+			timeoutCh = timer.C
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, healthzResponse{Status: err.Error()})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusOK, healthzResponse{Status: "resync completed"})
+		case <-timeoutCh:
+			// The resync still goes on.
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, resyncTimeoutResponse{
+				Status:   "resync is not completed in time",
+				Progress: progress(),
+			})
+		case <-c.Request.Context().Done():
+			// The resync still goes on.
+			c.Abort()
+		}
+	}
+}
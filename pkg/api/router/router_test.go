@@ -15,15 +15,19 @@
 package router
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
 )
 
 func TestHealthz(t *testing.T) {
@@ -57,6 +61,151 @@ func TestApisixHealthz(t *testing.T) {
 	assert.Equal(t, resp, healthzResponse{Status: "ok"})
 }
 
+func TestWorkersHealthz(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	var state WorkersHealthState
+	MountWorkersHealthz(r, &state)
+	workersHealthz(&state)(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp healthzResponse
+	dec := json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, healthzResponse{Status: "ok"}, resp)
+
+	state.StaleWorkers = []string{"ApisixRoute-0"}
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	workersHealthz(&state)(c)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	dec = json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, healthzResponse{Status: "stale workers: ApisixRoute-0"}, resp)
+}
+
+func TestReadyz(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, r := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	var state ReadinessState
+	var adminAPIState AdminAPIHealthState
+	MountReadyz(r, &state, &adminAPIState)
+	readyz(&state, &adminAPIState)(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var resp healthzResponse
+	dec := json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, healthzResponse{Status: "initial sync is in progress"}, resp)
+
+	state.Ready = true
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	readyz(&state, &adminAPIState)(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	dec = json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, healthzResponse{Status: "ok"}, resp)
+
+	adminAPIState.FailureThreshold = 1
+	adminAPIState.SetCheck(func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	readyz(&state, &adminAPIState)(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	dec = json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, healthzResponse{Status: "APISIX Admin API is unreachable: connection refused"}, resp)
+}
+
+func TestAdminAPIHealthState(t *testing.T) {
+	var (
+		calls int
+		err   error
+	)
+	state := &AdminAPIHealthState{
+		TTL:              time.Hour,
+		FailureThreshold: 2,
+	}
+	assert.Nil(t, state.Err(context.Background()), "no check when not leading")
+
+	state.SetCheck(func(context.Context) error {
+		calls++
+		return err
+	})
+	err = errors.New("connection refused")
+	assert.Nil(t, state.Err(context.Background()), "a single failure is tolerated")
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, state.Err(context.Background()), "the result is cached")
+	assert.Equal(t, 1, calls)
+
+	state.TTL = 0
+	assert.Equal(t, err, state.Err(context.Background()))
+	assert.Equal(t, 2, calls)
+
+	err = nil
+	assert.Nil(t, state.Err(context.Background()))
+	err = errors.New("connection refused")
+	assert.Nil(t, state.Err(context.Background()), "failures are counted after a success")
+	assert.Equal(t, 4, calls)
+
+	state.SetCheck(nil)
+	assert.Nil(t, state.Err(context.Background()))
+}
+
+func TestNamespaces(t *testing.T) {
+	newContext := func(auth string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req, _ := http.NewRequest(http.MethodGet, "/debug/namespaces", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		c.Request = req
+		return c, w
+	}
+
+	var state NamespacesState
+	handler := namespaces(&state, "s3cret")
+
+	c, w := newContext("")
+	handler(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newContext("Bearer wrong")
+	handler(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newContext("Bearer s3cret")
+	handler(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	state.Namespaces = func() map[string]string {
+		return map[string]string{"foo": "label", "bar": "explicit"}
+	}
+	c, w = newContext("Bearer s3cret")
+	handler(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp namespacesResponse
+	dec := json.NewDecoder(w.Body)
+	assert.Nil(t, dec.Decode(&resp))
+	assert.Equal(t, namespacesResponse{Namespaces: []watchedNamespace{
+		{Name: "bar", SelectedBy: "explicit"},
+		{Name: "foo", SelectedBy: "label"},
+	}}, resp)
+}
+
 func TestMetrics(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, r := gin.CreateTestContext(w)
@@ -75,7 +224,76 @@ func TestWebhooks(t *testing.T) {
 	req, err := http.NewRequest("POST", "/validation", nil)
 	assert.Nil(t, err, nil)
 	c.Request = req
-	MountWebhooks(r, &apisix.ClusterOptions{})
+	MountWebhooks(r, &apisix.ClusterOptions{}, &config.ApisixUpstreamDefaultsConfig{})
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestResync(t *testing.T) {
+	newContext := func(auth string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req, _ := http.NewRequest(http.MethodPost, "/debug/resync", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		c.Request = req
+		return c, w
+	}
 
+	var state ResyncState
+	handler := resync(&state, "s3cret")
+
+	c, w := newContext("")
+	handler(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newContext("Bearer wrong")
+	handler(c)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	c, w = newContext("Bearer s3cret")
+	handler(c)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	triggered := 0
+	var result error
+	state.Resync = func() (<-chan error, func() ResyncProgress) {
+		triggered++
+		done := make(chan error, 1)
+		done <- result
+		close(done)
+		return done, nil
+	}
+	c, w = newContext("Bearer s3cret")
+	handler(c)
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, triggered)
+
+	result = errors.New("1 resources failed to sync")
+	c, w = newContext("Bearer s3cret")
+	handler(c)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Body.String(), "1 resources failed to sync")
+	assert.Equal(t, 2, triggered)
+
+	state.Resync = func() (<-chan error, func() ResyncProgress) {
+		triggered++
+		return make(chan error), func() ResyncProgress {
+			return ResyncProgress{Queued: 3, Synced: 1}
+		}
+	}
+	c, w = newContext("Bearer s3cret")
+	c.Request.URL.RawQuery = "wait=false"
+	handler(c)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Equal(t, 3, triggered)
+
+	// The wait is bounded, the progress so far is responded.
+	state.Timeout = 10 * time.Millisecond
+	c, w = newContext("Bearer s3cret")
+	handler(c)
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.JSONEq(t, `{"status":"resync is not completed in time","progress":{"queued":3,"synced":1,"failed":0}}`, w.Body.String())
+	assert.Equal(t, 4, triggered)
 }
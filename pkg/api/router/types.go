@@ -15,7 +15,11 @@
 
 package router
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"time"
+)
 
 // HealthState stores healthcheck err of APISIX
 type HealthState struct {
@@ -23,3 +27,104 @@ type HealthState struct {
 
 	Err error
 }
+
+// WorkersHealthState stores the controller workers which are stuck
+type WorkersHealthState struct {
+	sync.RWMutex
+
+	StaleWorkers []string
+}
+
+// ReadinessState stores whether the controller finishes the initial sync
+type ReadinessState struct {
+	sync.RWMutex
+
+	Ready bool
+}
+
+// NamespacesState provides the namespaces being watched
+type NamespacesState struct {
+	sync.RWMutex
+
+	// Namespaces returns the watched namespaces and how each one is
+	// selected, it's nil if the controller is not leading.
+	Namespaces func() map[string]string
+}
+
+// ResyncState triggers a full resync of the resources manually
+type ResyncState struct {
+	sync.RWMutex
+
+	// Resync triggers a full resync, the returned channel receives nil once
+	// all the resources queued by it are synced, or the error if it fails,
+	// and progress reports how far it is. The concurrent triggers are
+	// coalesced. It's nil if the controller is not leading.
+	Resync func() (done <-chan error, progress func() ResyncProgress)
+	// Timeout is the maximum duration to wait for a resync to complete,
+	// zero means no limit.
+	Timeout time.Duration
+}
+
+// ResyncProgress is the progress of a manual resync.
+type ResyncProgress struct {
+	// Queued is the number of the resources queued by the resync, it's zero
+	// before they are all queued.
+	Queued int `json:"queued"`
+	// Synced is the number of the queued resources which are synced.
+	Synced int `json:"synced"`
+	// Failed is the number of the synced resources which failed.
+	Failed int `json:"failed"`
+}
+
+// AdminAPIHealthState checks whether the APISIX Admin API is reachable. The
+// result is cached for TTL, and the Admin API is reported unreachable only
+// after FailureThreshold consecutive failures, so brief blips are tolerated.
+type AdminAPIHealthState struct {
+	sync.Mutex
+
+	// Check sends a request to the Admin API, it's nil if the controller
+	// doesn't talk to APISIX (e.g. it's not leading).
+	Check            func(context.Context) error
+	TTL              time.Duration
+	FailureThreshold int
+
+	checkedAt time.Time
+	failures  int
+	lastErr   error
+}
+
+// Err returns the last error of the Admin API check if it failed
+// consistently, the Admin API is checked again if the cached result expires.
+func (s *AdminAPIHealthState) Err(ctx context.Context) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.Check == nil {
+		return nil
+	}
+	if s.checkedAt.IsZero() || time.Since(s.checkedAt) >= s.TTL {
+		s.checkedAt = time.Now()
+		if err := s.Check(ctx); err != nil {
+			s.failures++
+			s.lastErr = err
+		} else {
+			s.failures = 0
+			s.lastErr = nil
+		}
+	}
+	if s.failures >= s.FailureThreshold && s.lastErr != nil {
+		return s.lastErr
+	}
+	return nil
+}
+
+// SetCheck replaces the Admin API check and resets the cached result.
+func (s *AdminAPIHealthState) SetCheck(check func(context.Context) error) {
+	s.Lock()
+	defer s.Unlock()
+
+	s.Check = check
+	s.checkedAt = time.Time{}
+	s.failures = 0
+	s.lastErr = nil
+}
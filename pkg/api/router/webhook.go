@@ -18,12 +18,14 @@ package router
 import (
 	"github.com/gin-gonic/gin"
 
+	"github.com/apache/apisix-ingress-controller/pkg/api/mutation"
 	"github.com/apache/apisix-ingress-controller/pkg/api/validation"
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
 )
 
 // MountWebhooks mounts webhook related routes.
-func MountWebhooks(r *gin.Engine, co *apisix.ClusterOptions) {
+func MountWebhooks(r *gin.Engine, co *apisix.ClusterOptions, upstreamDefaults *config.ApisixUpstreamDefaultsConfig) {
 	// init the schema client, it will be used to query schema of objects.
 	_, _ = validation.GetSchemaClient(co)
 
@@ -31,8 +33,16 @@ func MountWebhooks(r *gin.Engine, co *apisix.ClusterOptions) {
 	validationGroup := r.Group("/validation")
 	{
 		validationGroup.POST("/apisixroutes", validation.NewHandlerFunc("ApisixRoute", validation.ApisixRouteValidator))
+		validationGroup.POST("/apisixroutes/references", validation.NewHandlerFunc("ApisixRouteReference", validation.ApisixRouteReferenceValidator))
 		validationGroup.POST("/apisixupstreams", validation.NewHandlerFunc("ApisixUpstream", validation.ApisixUpstreamValidator))
 		validationGroup.POST("/apisixconsumers", validation.NewHandlerFunc("ApisixConsumer", validation.ApisixConsumerValidator))
 		validationGroup.POST("/apisixtlses", validation.NewHandlerFunc("ApisixTls", validation.ApisixTlsValidator))
+		validationGroup.POST("/apisixpluginconfigs", validation.NewHandlerFunc("ApisixPluginConfig", validation.ApisixPluginConfigValidator))
+	}
+
+	// grouping mutation routes
+	mutationGroup := r.Group("/mutation")
+	{
+		mutationGroup.POST("/apisixupstreams", mutation.NewHandlerFunc("ApisixUpstream", mutation.NewApisixUpstreamMutator(upstreamDefaults)))
 	}
 }
@@ -21,6 +21,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"runtime"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -35,11 +36,16 @@ import (
 
 // Server represents the API Server in ingress-apisix-controller.
 type Server struct {
-	HealthState     *apirouter.HealthState
-	httpServer      *gin.Engine
-	admissionServer *http.Server
-	httpListener    net.Listener
-	pprofMu         *http.ServeMux
+	HealthState        *apirouter.HealthState
+	WorkersHealthState *apirouter.WorkersHealthState
+	ReadinessState     *apirouter.ReadinessState
+	AdminAPIState      *apirouter.AdminAPIHealthState
+	NamespacesState    *apirouter.NamespacesState
+	ResyncState        *apirouter.ResyncState
+	httpServer         *gin.Engine
+	admissionServer    *http.Server
+	httpListener       net.Listener
+	pprofMu            *http.ServeMux
 }
 
 // NewServer initializes the API Server.
@@ -54,13 +60,32 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	apirouter.Mount(httpServer)
 
 	srv := &Server{
-		HealthState:  new(apirouter.HealthState),
-		httpServer:   httpServer,
-		httpListener: httpListener,
+		HealthState:        new(apirouter.HealthState),
+		WorkersHealthState: new(apirouter.WorkersHealthState),
+		ReadinessState:     new(apirouter.ReadinessState),
+		AdminAPIState: &apirouter.AdminAPIHealthState{
+			TTL:              cfg.APISIX.AdminAPIHealthCheckTTL.Duration,
+			FailureThreshold: cfg.APISIX.AdminAPIHealthCheckFailureThreshold,
+		},
+		NamespacesState: new(apirouter.NamespacesState),
+		ResyncState:     &apirouter.ResyncState{Timeout: cfg.ResyncTimeout.Duration},
+		httpServer:      httpServer,
+		httpListener:    httpListener,
 	}
 	apirouter.MountApisixHealthz(httpServer, srv.HealthState)
+	apirouter.MountWorkersHealthz(httpServer, srv.WorkersHealthState)
+	apirouter.MountReadyz(httpServer, srv.ReadinessState, srv.AdminAPIState)
+	// The manual resync and the namespaces listing are disabled unless a
+	// token is configured.
+	if cfg.ResyncToken != "" {
+		apirouter.MountNamespaces(httpServer, srv.NamespacesState, cfg.ResyncToken)
+		apirouter.MountResync(httpServer, srv.ResyncState, cfg.ResyncToken)
+	}
 
 	if cfg.EnableProfiling {
+		runtime.SetMutexProfileFraction(cfg.ProfilingMutexFraction)
+		runtime.SetBlockProfileRate(cfg.ProfilingBlockRate)
+
 		srv.pprofMu = new(http.ServeMux)
 		srv.pprofMu.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
 		srv.pprofMu.HandleFunc("/debug/pprof/profile", pprof.Profile)
@@ -81,10 +106,11 @@ func NewServer(cfg *config.Config) (*Server, error) {
 		admission := gin.New()
 		admission.Use(gin.Recovery(), gin.Logger())
 		apirouter.MountWebhooks(admission, &apisix.ClusterOptions{
-			Name:     cfg.APISIX.DefaultClusterName,
-			AdminKey: cfg.APISIX.DefaultClusterAdminKey,
-			BaseURL:  cfg.APISIX.DefaultClusterBaseURL,
-		})
+			Name:           cfg.APISIX.DefaultClusterName,
+			AdminKey:       cfg.APISIX.DefaultClusterAdminKey,
+			BaseURL:        cfg.APISIX.DefaultClusterBaseURL,
+			SchemaCacheTTL: cfg.APISIX.PluginSchemaCacheTTL.Duration,
+		}, &cfg.ApisixUpstreamDefaults)
 
 		srv.admissionServer = &http.Server{
 			Addr:    cfg.HTTPSListen,
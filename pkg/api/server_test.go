@@ -19,6 +19,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"runtime"
 	"testing"
 	"time"
 
@@ -148,23 +149,36 @@ func TestProfileNotMount(t *testing.T) {
 }
 
 func TestProfile(t *testing.T) {
-	cfg := &config.Config{HTTPListen: "127.0.0.1:0", EnableProfiling: true}
+	cfg := &config.Config{
+		HTTPListen:             "127.0.0.1:0",
+		EnableProfiling:        true,
+		ProfilingMutexFraction: 5,
+		ProfilingBlockRate:     1,
+	}
 	srv, err := NewServer(cfg)
 	assert.Nil(t, err, "see non-nil error: ", err)
+	defer func() {
+		runtime.SetMutexProfileFraction(0)
+		runtime.SetBlockProfileRate(0)
+	}()
+	assert.Equal(t, 5, runtime.SetMutexProfileFraction(-1))
+
 	stopCh := make(chan struct{})
 	go func() {
 		err := srv.Run(stopCh)
 		assert.Nil(t, err, "see non-nil error: ", err)
 	}()
 
-	u := (&url.URL{
-		Scheme: "http",
-		Host:   srv.httpListener.Addr().String(),
-		Path:   "/debug/pprof/cmdline",
-	}).String()
+	for _, path := range []string{"/debug/pprof/cmdline", "/debug/pprof/heap", "/debug/pprof/goroutine", "/debug/pprof/mutex", "/debug/pprof/block"} {
+		u := (&url.URL{
+			Scheme: "http",
+			Host:   srv.httpListener.Addr().String(),
+			Path:   path,
+		}).String()
 
-	resp, err := http.Get(u)
-	assert.Nil(t, err, nil)
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp, err := http.Get(u)
+		assert.Nil(t, err, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+	}
 	close(stopCh)
 }
@@ -0,0 +1,87 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	kwhmodel "github.com/slok/kubewebhook/v2/pkg/model"
+	kwhvalidating "github.com/slok/kubewebhook/v2/pkg/webhook/validating"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// errNotApisixPluginConfig will be used when the validating object is not ApisixPluginConfig.
+var errNotApisixPluginConfig = errors.New("object is not ApisixPluginConfig")
+
+// ApisixPluginConfigValidator validates the plugins of ApisixPluginConfig.
+var ApisixPluginConfigValidator = kwhvalidating.ValidatorFunc(
+	func(ctx context.Context, review *kwhmodel.AdmissionReview, object metav1.Object) (result *kwhvalidating.ValidatorResult, err error) {
+		log.Debug("arrive ApisixPluginConfig validator webhook")
+
+		var plugins []apisixRoutePlugin
+		switch apc := object.(type) {
+		case *v2beta3.ApisixPluginConfig:
+			for i, p := range apc.Spec.Plugins {
+				if p.Enable {
+					plugins = append(plugins, apisixRoutePlugin{
+						fmt.Sprintf("plugins[%d]", i), p.Name, p.Config,
+					})
+				}
+			}
+		case *v2.ApisixPluginConfig:
+			for i, p := range apc.Spec.Plugins {
+				if p.Enable {
+					plugins = append(plugins, apisixRoutePlugin{
+						fmt.Sprintf("plugins[%d]", i), p.Name, p.Config,
+					})
+				}
+			}
+		default:
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: errNotApisixPluginConfig.Error()}, errNotApisixPluginConfig
+		}
+
+		if msgs := serverlessPluginViolations(plugins); len(msgs) > 0 {
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: strings.Join(msgs, "\n")}, nil
+		}
+
+		client, err := GetSchemaClient(&apisix.ClusterOptions{})
+		if err != nil {
+			msg := "failed to get the schema client"
+			log.Errorf("%s: %s", msg, err)
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: msg}, err
+		}
+
+		valid := true
+		var msgs []string
+		for _, p := range plugins {
+			if v, err := validatePlugin(client, p.Name, p.Config); !v {
+				valid = false
+				msgs = append(msgs, fmt.Sprintf("%s: %s", p.Field, err))
+				log.Warnf("failed to validate plugin %s: %s", p.Name, err)
+			}
+		}
+
+		return &kwhvalidating.ValidatorResult{Valid: valid, Message: strings.Join(msgs, "\n")}, nil
+	},
+)
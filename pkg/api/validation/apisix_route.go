@@ -31,6 +31,7 @@ import (
 	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 )
 
@@ -38,6 +39,8 @@ import (
 var errNotApisixRoute = errors.New("object is not ApisixRoute")
 
 type apisixRoutePlugin struct {
+	// Field is the path of the plugin in ApisixRoute, e.g. "http[0].plugins[1]".
+	Field  string
 	Name   string
 	Config interface{}
 }
@@ -51,18 +54,21 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 		valid := true
 		var plugins []apisixRoutePlugin
 		var spec interface{}
+		var msgs []string
+		// violations are the inline Lua functions disabled by the controller.
+		var violations []string
 
 		switch ar := object.(type) {
 		case *v2beta2.ApisixRoute:
 			spec = ar.Spec
 
 			// validate plugins
-			for _, h := range ar.Spec.HTTP {
-				for _, p := range h.Plugins {
+			for i, h := range ar.Spec.HTTP {
+				for j, p := range h.Plugins {
 					// only check plugins that are enabled.
 					if p.Enable {
 						plugins = append(plugins, apisixRoutePlugin{
-							p.Name, p.Config,
+							fmt.Sprintf("http[%d].plugins[%d]", i, j), p.Name, p.Config,
 						})
 					}
 				}
@@ -70,11 +76,11 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 		case *v2beta3.ApisixRoute:
 			spec = ar.Spec
 
-			for _, h := range ar.Spec.HTTP {
-				for _, p := range h.Plugins {
+			for i, h := range ar.Spec.HTTP {
+				for j, p := range h.Plugins {
 					if p.Enable {
 						plugins = append(plugins, apisixRoutePlugin{
-							p.Name, p.Config,
+							fmt.Sprintf("http[%d].plugins[%d]", i, j), p.Name, p.Config,
 						})
 					}
 				}
@@ -82,11 +88,55 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 		case *v2.ApisixRoute:
 			spec = ar.Spec
 
-			for _, h := range ar.Spec.HTTP {
-				for _, p := range h.Plugins {
+			for i, h := range ar.Spec.HTTP {
+				if h.IPRestriction != nil {
+					if err := translation.ValidateIPRestriction(h.IPRestriction); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.ForwardAuth != nil {
+					if err := translation.ValidateForwardAuth(h.ForwardAuth); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.Cors != nil {
+					if err := translation.ValidateCors(h.Cors); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.ResponseRewrite != nil {
+					if err := translation.ValidateResponseRewrite(h.ResponseRewrite); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.RequestID != nil {
+					if err := translation.ValidateRequestID(h.RequestID); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.Serverless != nil {
+					if !getAllowServerlessFunctions() {
+						violations = append(violations, fmt.Sprintf("http[%d].serverless: inline Lua functions are disabled by the controller", i))
+					} else if err := translation.ValidateServerless(h.Serverless); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				if h.Match.GRPC != nil {
+					if err := translation.ValidateGRPCMatch(&h.Match); err != nil {
+						valid = false
+						msgs = append(msgs, fmt.Sprintf("http[%d].%s", i, err))
+					}
+				}
+				for j, p := range h.Plugins {
 					if p.Enable {
 						plugins = append(plugins, apisixRoutePlugin{
-							p.Name, p.Config,
+							fmt.Sprintf("http[%d].plugins[%d]", i, j), p.Name, p.Config,
 						})
 					}
 				}
@@ -95,6 +145,13 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 			return &kwhvalidating.ValidatorResult{Valid: false, Message: errNotApisixRoute.Error()}, errNotApisixRoute
 		}
 
+		// they are rejected without asking APISIX for the schemas.
+		violations = append(violations, serverlessPluginViolations(plugins)...)
+		if len(violations) > 0 {
+			msgs = append(msgs, violations...)
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: strings.Join(msgs, "\n")}, nil
+		}
+
 		client, err := GetSchemaClient(&apisix.ClusterOptions{})
 		if err != nil {
 			msg := "failed to get the schema client"
@@ -110,7 +167,6 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 		}
 		arSchemaLoader := gojsonschema.NewStringLoader(rs.Content)
 
-		var msgs []string
 		if _, err := validateSchema(&arSchemaLoader, spec); err != nil {
 			valid = false
 			msgs = append(msgs, err.Error())
@@ -120,7 +176,7 @@ var ApisixRouteValidator = kwhvalidating.ValidatorFunc(
 		for _, p := range plugins {
 			if v, err := validatePlugin(client, p.Name, p.Config); !v {
 				valid = false
-				msgs = append(msgs, err.Error())
+				msgs = append(msgs, fmt.Sprintf("%s: %s", p.Field, err))
 				log.Warnf("failed to validate plugin %s: %s", p.Name, err)
 			}
 		}
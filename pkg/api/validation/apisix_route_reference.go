@@ -0,0 +1,272 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	kwhmodel "github.com/slok/kubewebhook/v2/pkg/model"
+	kwhvalidating "github.com/slok/kubewebhook/v2/pkg/webhook/validating"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+var (
+	referenceClientMu sync.RWMutex
+	referenceClient   *kube.KubeClient
+)
+
+// SetReferenceClient sets the Kubernetes client which is used to check
+// whether the resources referenced by the validating objects exist.
+func SetReferenceClient(client *kube.KubeClient) {
+	referenceClientMu.Lock()
+	defer referenceClientMu.Unlock()
+	referenceClient = client
+}
+
+func getReferenceClient() *kube.KubeClient {
+	referenceClientMu.RLock()
+	defer referenceClientMu.RUnlock()
+	return referenceClient
+}
+
+// backendReference is a Service referenced by an ApisixRoute.
+type backendReference struct {
+	field       string
+	serviceName string
+	servicePort intstr.IntOrString
+	subset      string
+}
+
+// pluginConfigReference is an ApisixPluginConfig referenced by an ApisixRoute.
+type pluginConfigReference struct {
+	field string
+	name  string
+}
+
+// ApisixRouteReferenceValidator validates that the Services (and their ports),
+// ApisixPluginConfigs and ApisixUpstreams (for subsets) referenced by the
+// ApisixRoute exist. All the missing references are reported.
+var ApisixRouteReferenceValidator = kwhvalidating.ValidatorFunc(
+	func(ctx context.Context, review *kwhmodel.AdmissionReview, object metav1.Object) (result *kwhvalidating.ValidatorResult, err error) {
+		log.Debug("arrive ApisixRoute reference validator webhook")
+
+		var (
+			backends      []backendReference
+			pluginConfigs []pluginConfigReference
+		)
+		switch ar := object.(type) {
+		case *v2beta3.ApisixRoute:
+			backends, pluginConfigs = collectApisixRouteV2beta3References(ar)
+		case *v2.ApisixRoute:
+			backends, pluginConfigs = collectApisixRouteV2References(ar)
+		default:
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: errNotApisixRoute.Error()}, errNotApisixRoute
+		}
+
+		client := getReferenceClient()
+		if client == nil {
+			log.Debug("reference client is not set, skip checking the references of ApisixRoute")
+			return &kwhvalidating.ValidatorResult{Valid: true}, nil
+		}
+
+		msgs, err := checkApisixRouteReferences(ctx, client, object.GetNamespace(), backends, pluginConfigs)
+		if err != nil {
+			msg := "failed to check the references of ApisixRoute"
+			log.Errorf("%s: %s", msg, err)
+			return &kwhvalidating.ValidatorResult{Valid: false, Message: msg}, err
+		}
+		if len(msgs) > 0 {
+			log.Warnf("ApisixRoute %s/%s has invalid references: %s", object.GetNamespace(), object.GetName(), strings.Join(msgs, "; "))
+		}
+		return &kwhvalidating.ValidatorResult{Valid: len(msgs) == 0, Message: strings.Join(msgs, "\n")}, nil
+	},
+)
+
+func collectApisixRouteV2beta3References(ar *v2beta3.ApisixRoute) ([]backendReference, []pluginConfigReference) {
+	var (
+		backends      []backendReference
+		pluginConfigs []pluginConfigReference
+	)
+	for i, h := range ar.Spec.HTTP {
+		for j, b := range h.Backends {
+			backends = append(backends, backendReference{
+				field:       fmt.Sprintf("http[%d].backends[%d]", i, j),
+				serviceName: b.ServiceName,
+				servicePort: b.ServicePort,
+				subset:      b.Subset,
+			})
+		}
+		if h.PluginConfigName != "" {
+			pluginConfigs = append(pluginConfigs, pluginConfigReference{
+				field: fmt.Sprintf("http[%d].plugin_config_name", i),
+				name:  h.PluginConfigName,
+			})
+		}
+	}
+	for i, s := range ar.Spec.Stream {
+		backends = append(backends, backendReference{
+			field:       fmt.Sprintf("stream[%d].backend", i),
+			serviceName: s.Backend.ServiceName,
+			servicePort: s.Backend.ServicePort,
+			subset:      s.Backend.Subset,
+		})
+	}
+	return backends, pluginConfigs
+}
+
+func collectApisixRouteV2References(ar *v2.ApisixRoute) ([]backendReference, []pluginConfigReference) {
+	var (
+		backends      []backendReference
+		pluginConfigs []pluginConfigReference
+	)
+	for i, h := range ar.Spec.HTTP {
+		for j, b := range h.Backends {
+			backends = append(backends, backendReference{
+				field:       fmt.Sprintf("http[%d].backends[%d]", i, j),
+				serviceName: b.ServiceName,
+				servicePort: b.ServicePort,
+				subset:      b.Subset,
+			})
+		}
+		for j, mb := range h.MethodBackends {
+			backends = append(backends, backendReference{
+				field:       fmt.Sprintf("http[%d].methodBackends[%d].backend", i, j),
+				serviceName: mb.Backend.ServiceName,
+				servicePort: mb.Backend.ServicePort,
+				subset:      mb.Backend.Subset,
+			})
+		}
+		if h.PluginConfigName != "" {
+			pluginConfigs = append(pluginConfigs, pluginConfigReference{
+				field: fmt.Sprintf("http[%d].plugin_config_name", i),
+				name:  h.PluginConfigName,
+			})
+		}
+	}
+	for i, s := range ar.Spec.Stream {
+		backends = append(backends, backendReference{
+			field:       fmt.Sprintf("stream[%d].backend", i),
+			serviceName: s.Backend.ServiceName,
+			servicePort: s.Backend.ServicePort,
+			subset:      s.Backend.Subset,
+		})
+	}
+	return backends, pluginConfigs
+}
+
+// hasExternalNodes reports whether the ApisixUpstream with the name exists and
+// has external nodes.
+func hasExternalNodes(ctx context.Context, client *kube.KubeClient, namespace, name string) (bool, error) {
+	au, err := client.APISIXClient.ApisixV2beta3().ApisixUpstreams(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return au.Spec != nil && len(au.Spec.ExternalNodes) > 0, nil
+}
+
+// checkApisixRouteReferences returns the messages of the missing references,
+// the error is returned only if the references cannot be checked.
+func checkApisixRouteReferences(ctx context.Context, client *kube.KubeClient, namespace string,
+	backends []backendReference, pluginConfigs []pluginConfigReference) ([]string, error) {
+	var msgs []string
+	for _, ref := range backends {
+		svc, err := client.Client.CoreV1().Services(namespace).Get(ctx, ref.serviceName, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+			// The backends using the external nodes of ApisixUpstream don't
+			// require the Service.
+			external, err := hasExternalNodes(ctx, client, namespace, ref.serviceName)
+			if err != nil {
+				return nil, err
+			}
+			if external {
+				if ref.servicePort.Type != intstr.Int {
+					msgs = append(msgs, fmt.Sprintf("%s: port %s should be a number to use the external nodes of ApisixUpstream %s/%s",
+						ref.field, ref.servicePort.String(), namespace, ref.serviceName))
+				}
+				continue
+			}
+			msgs = append(msgs, fmt.Sprintf("%s: service %s/%s not found", ref.field, namespace, ref.serviceName))
+			continue
+		}
+		portDefined := false
+		for _, port := range svc.Spec.Ports {
+			if (ref.servicePort.Type == intstr.Int && port.Port == ref.servicePort.IntVal) ||
+				(ref.servicePort.Type == intstr.String && port.Name == ref.servicePort.StrVal) {
+				portDefined = true
+				break
+			}
+		}
+		if !portDefined {
+			msgs = append(msgs, fmt.Sprintf("%s: port %s is not defined in service %s/%s",
+				ref.field, ref.servicePort.String(), namespace, ref.serviceName))
+		}
+
+		if ref.subset == "" {
+			continue
+		}
+		au, err := client.APISIXClient.ApisixV2beta3().ApisixUpstreams(namespace).Get(ctx, ref.serviceName, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+			msgs = append(msgs, fmt.Sprintf("%s: ApisixUpstream %s/%s (for subset %s) not found",
+				ref.field, namespace, ref.serviceName, ref.subset))
+			continue
+		}
+		subsetDefined := false
+		if au.Spec != nil {
+			for _, subset := range au.Spec.Subsets {
+				if subset.Name == ref.subset {
+					subsetDefined = true
+					break
+				}
+			}
+		}
+		if !subsetDefined {
+			msgs = append(msgs, fmt.Sprintf("%s: subset %s is not defined in ApisixUpstream %s/%s",
+				ref.field, ref.subset, namespace, ref.serviceName))
+		}
+	}
+
+	for _, ref := range pluginConfigs {
+		pcNamespace, pcName := translation.ParsePluginConfigReference(namespace, ref.name)
+		_, err := client.APISIXClient.ApisixV2beta3().ApisixPluginConfigs(pcNamespace).Get(ctx, pcName, metav1.GetOptions{})
+		if err != nil {
+			if !k8serrors.IsNotFound(err) {
+				return nil, err
+			}
+			msgs = append(msgs, fmt.Sprintf("%s: ApisixPluginConfig %s/%s not found", ref.field, pcNamespace, pcName))
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,155 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+)
+
+func TestApisixRouteReferenceValidator(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "httpbin",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "http", Port: 80},
+			},
+		},
+	}
+	au := &v2beta3.ApisixUpstream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "httpbin",
+			Namespace: "default",
+		},
+		Spec: &v2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: v2beta3.ApisixUpstreamConfig{
+				Subsets: []v2beta3.ApisixUpstreamSubset{
+					{Name: "v1"},
+				},
+			},
+		},
+	}
+	externalAu := &v2beta3.ApisixUpstream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-proxy",
+			Namespace: "default",
+		},
+		Spec: &v2beta3.ApisixUpstreamSpec{
+			ExternalNodes: []v2beta3.ApisixUpstreamExternalNode{
+				{Host: "10.0.0.10", Port: 3306},
+			},
+		},
+	}
+	pc := &v2beta3.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "echo",
+			Namespace: "default",
+		},
+	}
+	SetReferenceClient(&kube.KubeClient{
+		Client:       fake.NewSimpleClientset(svc),
+		APISIXClient: fakeapisix.NewSimpleClientset(au, externalAu, pc),
+	})
+	defer SetReferenceClient(nil)
+
+	newRoute := func(backend v2.ApisixRouteHTTPBackend, pluginConfigName string) *v2.ApisixRoute {
+		return &v2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "httpbin-route",
+				Namespace: "default",
+			},
+			Spec: v2.ApisixRouteSpec{
+				HTTP: []v2.ApisixRouteHTTP{
+					{
+						Name:             "rule1",
+						Backends:         []v2.ApisixRouteHTTPBackend{backend},
+						PluginConfigName: pluginConfigName,
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		route     *v2.ApisixRoute
+		wantValid bool
+		wantMsg   string
+	}{
+		{
+			name:      "all references exist",
+			route:     newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "httpbin", ServicePort: intstr.FromInt(80), Subset: "v1"}, "echo"),
+			wantValid: true,
+		},
+		{
+			name:      "port referenced by name",
+			route:     newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "httpbin", ServicePort: intstr.FromString("http")}, ""),
+			wantValid: true,
+		},
+		{
+			name:    "service not found",
+			route:   newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "foo", ServicePort: intstr.FromInt(80)}, ""),
+			wantMsg: "http[0].backends[0]: service default/foo not found",
+		},
+		{
+			name:      "external nodes without service",
+			route:     newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "db-proxy", ServicePort: intstr.FromInt(3306)}, ""),
+			wantValid: true,
+		},
+		{
+			name:    "external nodes referenced by port name",
+			route:   newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "db-proxy", ServicePort: intstr.FromString("mysql")}, ""),
+			wantMsg: "http[0].backends[0]: port mysql should be a number to use the external nodes of ApisixUpstream default/db-proxy",
+		},
+		{
+			name:    "port not defined",
+			route:   newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "httpbin", ServicePort: intstr.FromInt(8080)}, ""),
+			wantMsg: "http[0].backends[0]: port 8080 is not defined in service default/httpbin",
+		},
+		{
+			name:    "subset not defined",
+			route:   newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "httpbin", ServicePort: intstr.FromInt(80), Subset: "v2"}, ""),
+			wantMsg: "http[0].backends[0]: subset v2 is not defined in ApisixUpstream default/httpbin",
+		},
+		{
+			name:    "plugin config not found",
+			route:   newRoute(v2.ApisixRouteHTTPBackend{ServiceName: "httpbin", ServicePort: intstr.FromInt(80)}, "other/echo"),
+			wantMsg: "http[0].plugin_config_name: ApisixPluginConfig other/echo not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ApisixRouteReferenceValidator.Validate(context.Background(), nil, tt.route)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantValid, result.Valid)
+			assert.Equal(t, tt.wantMsg, result.Message)
+		})
+	}
+}
@@ -18,6 +18,7 @@ package validation
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
@@ -141,3 +142,17 @@ func Test_validatePlugin(t *testing.T) {
 		})
 	}
 }
+
+func Test_validatePluginErrorPath(t *testing.T) {
+	fakeClient := newFakeSchemaClient()
+	valid, err := validatePlugin(fakeClient, "api-breaker", v2.ApisixRouteHTTPPluginConfig{
+		"break_response_code": 100,
+	})
+	if valid {
+		t.Fatal("validatePlugin() gotValid = true, want false")
+	}
+	// The invalid field should be named in the error.
+	if !strings.Contains(err.Error(), "break_response_code: Must be greater than or equal to 200") {
+		t.Errorf("validatePlugin() error = %v, want the path of the invalid field", err)
+	}
+}
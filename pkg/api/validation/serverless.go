@@ -0,0 +1,57 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+)
+
+var (
+	allowServerlessMu        sync.RWMutex
+	allowServerlessFunctions bool
+)
+
+// SetAllowServerlessFunctions sets whether the inline Lua functions, i.e. the
+// serverless field and the serverless plugins, are accepted.
+func SetAllowServerlessFunctions(allow bool) {
+	allowServerlessMu.Lock()
+	defer allowServerlessMu.Unlock()
+	allowServerlessFunctions = allow
+}
+
+func getAllowServerlessFunctions() bool {
+	allowServerlessMu.RLock()
+	defer allowServerlessMu.RUnlock()
+	return allowServerlessFunctions
+}
+
+// serverlessPluginViolations reports the serverless plugins among the given
+// ones, unless inline Lua functions are allowed.
+func serverlessPluginViolations(plugins []apisixRoutePlugin) []string {
+	if getAllowServerlessFunctions() {
+		return nil
+	}
+	var msgs []string
+	for _, p := range plugins {
+		if translation.IsServerlessPlugin(p.Name) {
+			msgs = append(msgs, fmt.Sprintf("%s: plugin %s is not allowed since inline Lua functions are disabled by the controller", p.Field, p.Name))
+		}
+	}
+	return msgs
+}
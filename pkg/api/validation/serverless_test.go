@@ -0,0 +1,152 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+)
+
+func TestServerlessRejectedByValidators(t *testing.T) {
+	SetAllowServerlessFunctions(false)
+
+	fn := map[string]interface{}{"functions": []interface{}{"return function() end"}}
+	cases := []struct {
+		name   string
+		object metav1.Object
+		msg    string
+	}{
+		{
+			name: "v2 ApisixRoute plugin",
+			object: &v2.ApisixRoute{
+				Spec: v2.ApisixRouteSpec{
+					HTTP: []v2.ApisixRouteHTTP{
+						{
+							Plugins: []v2.ApisixRouteHTTPPlugin{
+								{Name: "echo", Enable: true},
+								{Name: "serverless-pre-function", Enable: true, Config: fn},
+							},
+						},
+					},
+				},
+			},
+			msg: "http[0].plugins[1]: plugin serverless-pre-function is not allowed since inline Lua functions are disabled by the controller",
+		},
+		{
+			name: "v2 ApisixRoute serverless",
+			object: &v2.ApisixRoute{
+				Spec: v2.ApisixRouteSpec{
+					HTTP: []v2.ApisixRouteHTTP{
+						{
+							Serverless: &v2.ApisixRouteHTTPServerless{
+								Pre: &v2.ApisixRouteHTTPServerlessFunctions{Functions: []string{"return function() end"}},
+							},
+						},
+					},
+				},
+			},
+			msg: "http[0].serverless: inline Lua functions are disabled by the controller",
+		},
+		{
+			name: "v2beta3 ApisixRoute plugin",
+			object: &v2beta3.ApisixRoute{
+				Spec: v2beta3.ApisixRouteSpec{
+					HTTP: []v2beta3.ApisixRouteHTTP{
+						{
+							Plugins: []v2beta3.ApisixRouteHTTPPlugin{
+								{Name: "serverless-post-function", Enable: true, Config: fn},
+							},
+						},
+					},
+				},
+			},
+			msg: "http[0].plugins[0]: plugin serverless-post-function is not allowed since inline Lua functions are disabled by the controller",
+		},
+		{
+			name: "v2beta2 ApisixRoute plugin",
+			object: &v2beta2.ApisixRoute{
+				Spec: v2beta2.ApisixRouteSpec{
+					HTTP: []v2beta2.ApisixRouteHTTP{
+						{
+							Plugins: []v2beta2.ApisixRouteHTTPPlugin{
+								{Name: "serverless-pre-function", Enable: true, Config: fn},
+							},
+						},
+					},
+				},
+			},
+			msg: "http[0].plugins[0]: plugin serverless-pre-function is not allowed since inline Lua functions are disabled by the controller",
+		},
+		{
+			name: "v2 ApisixPluginConfig plugin",
+			object: &v2.ApisixPluginConfig{
+				Spec: v2.ApisixPluginConfigSpec{
+					Plugins: []v2.ApisixRouteHTTPPlugin{
+						{Name: "serverless-post-function", Enable: true, Config: fn},
+					},
+				},
+			},
+			msg: "plugins[0]: plugin serverless-post-function is not allowed since inline Lua functions are disabled by the controller",
+		},
+		{
+			name: "v2beta3 ApisixPluginConfig plugin",
+			object: &v2beta3.ApisixPluginConfig{
+				Spec: v2beta3.ApisixPluginConfigSpec{
+					Plugins: []v2beta3.ApisixRouteHTTPPlugin{
+						{Name: "serverless-pre-function", Enable: true, Config: fn},
+					},
+				},
+			},
+			msg: "plugins[0]: plugin serverless-pre-function is not allowed since inline Lua functions are disabled by the controller",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			validator := ApisixRouteValidator
+			switch c.object.(type) {
+			case *v2.ApisixPluginConfig, *v2beta3.ApisixPluginConfig:
+				validator = ApisixPluginConfigValidator
+			}
+			res, err := validator.Validate(context.Background(), nil, c.object)
+			assert.Nil(t, err)
+			assert.False(t, res.Valid)
+			assert.Equal(t, c.msg, res.Message)
+		})
+	}
+}
+
+func TestServerlessPluginViolations(t *testing.T) {
+	plugins := []apisixRoutePlugin{
+		{Field: "plugins[0]", Name: "echo"},
+		{Field: "plugins[1]", Name: "serverless-post-function"},
+	}
+
+	SetAllowServerlessFunctions(false)
+	assert.Equal(t, []string{
+		"plugins[1]: plugin serverless-post-function is not allowed since inline Lua functions are disabled by the controller",
+	}, serverlessPluginViolations(plugins))
+
+	SetAllowServerlessFunctions(true)
+	defer SetAllowServerlessFunctions(false)
+	assert.Nil(t, serverlessPluginViolations(plugins))
+}
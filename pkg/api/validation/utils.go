@@ -93,7 +93,8 @@ func validateSchema(schemaLoader *gojsonschema.JSONLoader, obj interface{}) (boo
 	var resultErr error
 	resultErr = multierror.Append(resultErr, fmt.Errorf("the given document is not valid"))
 	for _, desc := range result.Errors() {
-		resultErr = multierror.Append(resultErr, fmt.Errorf("%s", desc.Description()))
+		// The field path helps to locate the invalid field, e.g. "break_response_code".
+		resultErr = multierror.Append(resultErr, fmt.Errorf("%s: %s", desc.Field(), desc.Description()))
 		log.Warnf("- %s", desc)
 	}
 
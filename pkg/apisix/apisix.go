@@ -19,6 +19,7 @@ import (
 	"context"
 	"sync"
 
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
@@ -49,6 +50,8 @@ type Cluster interface {
 	StreamRoute() StreamRoute
 	// GlobalRule returns a GlobalRule interface that can operate GlobalRule resources.
 	GlobalRule() GlobalRule
+	// PluginMetadata returns a PluginMetadata interface that can operate PluginMetadata resources.
+	PluginMetadata() PluginMetadata
 	// String exposes the client information in human readable format.
 	String() string
 	// HasSynced checks whether all resources in APISIX cluster is synced to cache.
@@ -57,6 +60,27 @@ type Cluster interface {
 	Consumer() Consumer
 	// HealthCheck checks apisix cluster health in realtime.
 	HealthCheck(context.Context) error
+	// AdminAPIHealthCheck sends a lightweight request to the Admin API to
+	// check whether it's reachable.
+	AdminAPIHealthCheck(context.Context) error
+	// ResetWriteCache forgets the payloads written to APISIX, so that the
+	// next writes are sent even if the payloads are unchanged.
+	ResetWriteCache()
+	// BatchCreate creates the resources in the batch with bounded
+	// concurrency, the ones might be referenced by routes are created first.
+	BatchCreate(context.Context, *Batch) error
+	// BatchUpdate updates the resources in the batch like BatchCreate.
+	BatchUpdate(context.Context, *Batch) error
+	// RouteIDs returns the IDs of the routes in the cache, i.e. the routes
+	// in APISIX as far as the controller knows.
+	RouteIDs() (map[string]struct{}, error)
+	// Cache returns the local cache of the cluster, objects got from it
+	// are copies and reflect the latest writes made by the controller.
+	Cache() cache.Cache
+	// ManagedResources returns the number of resources with the managed-by
+	// label in the cache, the resource type (route, upstream, ssl, etc) is
+	// the key. The managed_resources gauge is kept with every write.
+	ManagedResources() (map[string]int, error)
 	// Plugin returns a Plugin interface that can operate Plugin resources.
 	Plugin() Plugin
 	// PluginConfig returns a PluginConfig interface that can operate PluginConfig resources.
@@ -85,12 +109,17 @@ type SSL interface {
 	Create(context.Context, *v1.Ssl) (*v1.Ssl, error)
 	Delete(context.Context, *v1.Ssl) error
 	Update(context.Context, *v1.Ssl) (*v1.Ssl, error)
+	// VerifySNIs reads the SSL object back from APISIX (bypassing the cache)
+	// and returns the declared SNIs which were not registered.
+	VerifySNIs(context.Context, *v1.Ssl) ([]string, error)
 }
 
 // Upstream is the specific client interface to take over the create, update,
 // list and delete for APISIX Upstream resource.
 type Upstream interface {
 	Get(context.Context, string) (*v1.Upstream, error)
+	// ListByName lists the upstreams with the name.
+	ListByName(context.Context, string) ([]*v1.Upstream, error)
 	List(context.Context) ([]*v1.Upstream, error)
 	Create(context.Context, *v1.Upstream) (*v1.Upstream, error)
 	Delete(context.Context, *v1.Upstream) error
@@ -127,6 +156,17 @@ type Consumer interface {
 	Update(context.Context, *v1.Consumer) (*v1.Consumer, error)
 }
 
+// PluginMetadata is the specific client interface to take over the update and
+// delete for APISIX plugin_metadata resource, the objects are named by the
+// plugins.
+type PluginMetadata interface {
+	// Get reads the plugin_metadata of the plugin from APISIX, it returns
+	// cache.ErrNotFound if the plugin doesn't have one.
+	Get(context.Context, string) (*v1.PluginMetadata, error)
+	Update(context.Context, *v1.PluginMetadata) (*v1.PluginMetadata, error)
+	Delete(context.Context, *v1.PluginMetadata) error
+}
+
 // Plugin is the specific client interface to fetch APISIX Plugin resource.
 type Plugin interface {
 	List(context.Context) ([]string, error)
@@ -215,7 +255,8 @@ func (c *apisix) AddCluster(ctx context.Context, co *ClusterOptions) error {
 func (c *apisix) UpdateCluster(ctx context.Context, co *ClusterOptions) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if _, ok := c.clusters[co.Name]; !ok {
+	old, ok := c.clusters[co.Name]
+	if !ok {
 		return ErrClusterNotExist
 	}
 
@@ -225,6 +266,10 @@ func (c *apisix) UpdateCluster(ctx context.Context, co *ClusterOptions) error {
 	}
 
 	c.clusters[co.Name] = cluster
+	// The counts of the old cache are dropped, the ones of the resource
+	// types missing in the new cache shouldn't be left behind.
+	closeManagedCache(old)
+	publishManagedCache(cluster)
 	return nil
 }
 
@@ -233,6 +278,25 @@ func (c *apisix) DeleteCluster(name string) {
 	defer c.mu.Unlock()
 
 	// Don't have to close or free some resources in that cluster, so
-	// just delete its index.
+	// just delete its index, except for its managed_resources gauge.
+	if cluster, ok := c.clusters[name]; ok {
+		closeManagedCache(cluster)
+	}
 	delete(c.clusters, name)
 }
+
+// closeManagedCache stops the cluster from reporting the managed_resources
+// gauge and deletes its series.
+func closeManagedCache(c Cluster) {
+	if cl, ok := c.(*cluster); ok && cl.managed != nil {
+		cl.managed.close()
+	}
+}
+
+// publishManagedCache reports the managed_resources gauge of the cluster
+// again, after the series are deleted by closing the cache it replaces.
+func publishManagedCache(c Cluster) {
+	if cl, ok := c.(*cluster); ok && cl.managed != nil {
+		cl.managed.publish()
+	}
+}
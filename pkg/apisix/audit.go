@@ -0,0 +1,135 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+const (
+	// AuditActionCreate is the action of creating an APISIX resource.
+	AuditActionCreate = "create"
+	// AuditActionUpdate is the action of updating an APISIX resource.
+	AuditActionUpdate = "update"
+	// AuditActionDelete is the action of deleting an APISIX resource.
+	AuditActionDelete = "delete"
+
+	// AuditResultSuccess means the write was accepted by APISIX.
+	AuditResultSuccess = "success"
+	// AuditResultFailure means the write failed.
+	AuditResultFailure = "failure"
+)
+
+// AuditRecord records a write to the APISIX Admin API.
+type AuditRecord struct {
+	Time     time.Time `json:"time"`
+	Cluster  string    `json:"cluster"`
+	Resource string    `json:"resource"`
+	ID       string    `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	Action   string    `json:"action"`
+	Result   string    `json:"result"`
+	Code     int       `json:"code,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// AuditLogger records the writes to the APISIX Admin API.
+type AuditLogger interface {
+	// Record records a write.
+	Record(*AuditRecord)
+	// Close closes the audit log.
+	Close() error
+}
+
+type auditLogger struct {
+	sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+// NewAuditLogger creates an AuditLogger which writes each record as a JSON
+// line to the output, which can be "stdout", "stderr" or a file path.
+func NewAuditLogger(output string) (AuditLogger, error) {
+	var w io.Writer
+	switch output {
+	case "stdout":
+		w = os.Stdout
+	case "stderr":
+		w = os.Stderr
+	default:
+		file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+		w = file
+	}
+	return newAuditLoggerWithWriter(w), nil
+}
+
+func newAuditLoggerWithWriter(w io.Writer) *auditLogger {
+	return &auditLogger{
+		w:   w,
+		enc: json.NewEncoder(w),
+	}
+}
+
+func (a *auditLogger) Record(r *AuditRecord) {
+	a.Lock()
+	defer a.Unlock()
+	// The audit log shall not break the writes.
+	_ = a.enc.Encode(r)
+}
+
+func (a *auditLogger) Close() error {
+	if file, ok := a.w.(*os.File); ok && file != os.Stdout && file != os.Stderr {
+		return file.Close()
+	}
+	return nil
+}
+
+// audit records a write to url, data is the payload of the write, code is
+// the status code responded by APISIX, zero if no response.
+func (c *cluster) audit(action, resource, url string, data []byte, code int, err error) {
+	if c.auditLogger == nil {
+		return
+	}
+	r := &AuditRecord{
+		Time:     time.Now(),
+		Cluster:  c.name,
+		Resource: resource,
+		ID:       path.Base(url),
+		Action:   action,
+		Result:   AuditResultSuccess,
+		Code:     code,
+	}
+	if len(data) > 0 {
+		var obj struct {
+			Name string `json:"name"`
+		}
+		if json.Unmarshal(data, &obj) == nil {
+			r.Name = obj.Name
+		}
+	}
+	if err != nil {
+		r.Result = AuditResultFailure
+		r.Error = err.Error()
+	}
+	c.auditLogger.Record(r)
+}
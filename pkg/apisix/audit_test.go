@@ -0,0 +1,90 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestAuditLogger(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"node":{"key":"/apisix/routes/1","value":{"id":"1","name":"test","uri":"/bar"}}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`{"error_msg":"failed to delete"}`))
+		}
+	}))
+	defer srv.Close()
+
+	buf := &bytes.Buffer{}
+	closedCh := make(chan struct{})
+	close(closedCh)
+	c := &cluster{
+		name:             "default",
+		baseURL:          srv.URL + "/apisix/admin",
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+		auditLogger:      newAuditLoggerWithWriter(buf),
+	}
+	cli := newRouteClient(c)
+	route := &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "test",
+		},
+		Uri: "/bar",
+	}
+	ctx := context.Background()
+
+	_, err := cli.Create(ctx, route)
+	assert.Nil(t, err)
+	assert.NotNil(t, cli.Delete(ctx, route))
+
+	dec := json.NewDecoder(buf)
+	var r AuditRecord
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, "default", r.Cluster)
+	assert.Equal(t, "route", r.Resource)
+	assert.Equal(t, "1", r.ID)
+	assert.Equal(t, "test", r.Name)
+	assert.Equal(t, AuditActionCreate, r.Action)
+	assert.Equal(t, AuditResultSuccess, r.Result)
+	assert.Equal(t, http.StatusCreated, r.Code)
+	assert.False(t, r.Time.IsZero())
+
+	r = AuditRecord{}
+	assert.Nil(t, dec.Decode(&r))
+	assert.Equal(t, "1", r.ID)
+	assert.Equal(t, AuditActionDelete, r.Action)
+	assert.Equal(t, AuditResultFailure, r.Result)
+	assert.Equal(t, http.StatusInternalServerError, r.Code)
+	assert.NotEmpty(t, r.Error)
+	assert.False(t, dec.More())
+}
@@ -0,0 +1,181 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apisix
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const (
+	_defaultBatchConcurrency = 8
+)
+
+// Batch is a set of APISIX resources written together.
+type Batch struct {
+	SSLs          []*v1.Ssl
+	Upstreams     []*v1.Upstream
+	PluginConfigs []*v1.PluginConfig
+	Routes        []*v1.Route
+	StreamRoutes  []*v1.StreamRoute
+}
+
+// batchTask is a single write in a batch.
+type batchTask func(context.Context) error
+
+func (c *cluster) BatchCreate(ctx context.Context, b *Batch) error {
+	return c.batchWrite(ctx, b, false)
+}
+
+func (c *cluster) BatchUpdate(ctx context.Context, b *Batch) error {
+	return c.batchWrite(ctx, b, true)
+}
+
+// batchWrite writes the resources in two phases, SSLs, upstreams and plugin
+// configs go first since the routes and stream routes might reference them.
+// The Admin API has no batch endpoint, so the writes in the same phase are
+// sent concurrently instead, bounded by the batch concurrency of the cluster.
+func (c *cluster) batchWrite(ctx context.Context, b *Batch, update bool) error {
+	if b == nil {
+		return nil
+	}
+	verb := "create"
+	if update {
+		verb = "update"
+	}
+
+	var deps []batchTask
+	for _, ssl := range b.SSLs {
+		ssl := ssl
+		deps = append(deps, func(ctx context.Context) (err error) {
+			if update {
+				_, err = c.ssl.Update(ctx, ssl)
+			} else {
+				_, err = c.ssl.Create(ctx, ssl)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s ssl %s: %w", verb, ssl.ID, err)
+			}
+			return nil
+		})
+	}
+	for _, ups := range b.Upstreams {
+		ups := ups
+		deps = append(deps, func(ctx context.Context) (err error) {
+			if update {
+				_, err = c.upstream.Update(ctx, ups)
+			} else {
+				_, err = c.upstream.Create(ctx, ups)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s upstream %s: %w", verb, ups.Name, err)
+			}
+			return nil
+		})
+	}
+	for _, pc := range b.PluginConfigs {
+		pc := pc
+		deps = append(deps, func(ctx context.Context) (err error) {
+			if update {
+				_, err = c.pluginConfig.Update(ctx, pc)
+			} else {
+				_, err = c.pluginConfig.Create(ctx, pc)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s plugin_config %s: %w", verb, pc.Name, err)
+			}
+			return nil
+		})
+	}
+
+	var routes []batchTask
+	for _, r := range b.Routes {
+		r := r
+		routes = append(routes, func(ctx context.Context) (err error) {
+			if update {
+				_, err = c.route.Update(ctx, r)
+			} else {
+				_, err = c.route.Create(ctx, r)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s route %s: %w", verb, r.Name, err)
+			}
+			return nil
+		})
+	}
+	for _, sr := range b.StreamRoutes {
+		sr := sr
+		routes = append(routes, func(ctx context.Context) (err error) {
+			if update {
+				_, err = c.streamRoute.Update(ctx, sr)
+			} else {
+				_, err = c.streamRoute.Create(ctx, sr)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to %s stream_route %s: %w", verb, sr.ID, err)
+			}
+			return nil
+		})
+	}
+
+	var merr *multierror.Error
+	if err := c.runBatchTasks(ctx, deps); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	if err := c.runBatchTasks(ctx, routes); err != nil {
+		merr = multierror.Append(merr, err)
+	}
+	return merr.ErrorOrNil()
+}
+
+// runBatchTasks runs the tasks concurrently and waits for all of them, the
+// number of in-flight tasks of the cluster never exceeds the capacity of
+// batchSem, which is shared by all the batches.
+func (c *cluster) runBatchTasks(ctx context.Context, tasks []batchTask) error {
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+		merr *multierror.Error
+	)
+	for _, task := range tasks {
+		select {
+		case c.batchSem <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			merr = multierror.Append(merr, ctx.Err())
+			return merr.ErrorOrNil()
+		}
+		wg.Add(1)
+		go func(task batchTask) {
+			defer func() {
+				<-c.batchSem
+				wg.Done()
+			}()
+			if err := task(ctx); err != nil {
+				lock.Lock()
+				merr = multierror.Append(merr, err)
+				lock.Unlock()
+			}
+		}(task)
+	}
+	wg.Wait()
+	return merr.ErrorOrNil()
+}
@@ -0,0 +1,128 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apisix
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestBatchCreate(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		inflight int
+		peak     int
+		paths    []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		inflight++
+		if inflight > peak {
+			peak = inflight
+		}
+		lock.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+		data, _ := ioutil.ReadAll(r.Body)
+		key := strings.TrimPrefix(r.URL.Path, "/apisix/admin")
+
+		lock.Lock()
+		inflight--
+		paths = append(paths, key)
+		lock.Unlock()
+
+		if strings.HasSuffix(key, "/bad") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_msg":"invalid configuration"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		resp, _ := json.Marshal(fakeCreateResp{
+			Action: "create",
+			Node: fakeItem{
+				Key:   "/apisix" + key,
+				Value: data,
+			},
+		})
+		_, _ = w.Write(resp)
+	}))
+	defer srv.Close()
+
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	closedCh := make(chan struct{})
+	close(closedCh)
+	c := &cluster{
+		baseURL:          srv.URL + "/apisix/admin",
+		cli:              http.DefaultClient,
+		cache:            db,
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+		batchSem:         make(chan struct{}, 2),
+	}
+	c.route = newRouteClient(c)
+	c.upstream = newUpstreamClient(c)
+	c.upstreamServiceRelation = newUpstreamServiceRelation(c)
+
+	b := &Batch{}
+	for _, id := range []string{"1", "2", "3", "4"} {
+		b.Routes = append(b.Routes, &v1.Route{
+			Metadata: v1.Metadata{ID: id, Name: "route" + id},
+			Uri:      "/" + id,
+		})
+		b.Upstreams = append(b.Upstreams, &v1.Upstream{
+			Metadata: v1.Metadata{ID: id, Name: "upstream" + id},
+		})
+	}
+	assert.Nil(t, c.BatchCreate(context.Background(), b))
+	assert.Equal(t, 2, peak, "in-flight writes should be bounded")
+	assert.Len(t, paths, 8)
+	for i, path := range paths {
+		// Upstreams are written before the routes.
+		if i < 4 {
+			assert.True(t, strings.HasPrefix(path, "/upstreams/"), path)
+		} else {
+			assert.True(t, strings.HasPrefix(path, "/routes/"), path)
+		}
+	}
+	routes, err := db.ListRoutes()
+	assert.Nil(t, err)
+	assert.Len(t, routes, 4)
+
+	// Failed writes don't stop the others.
+	paths = nil
+	err = c.BatchCreate(context.Background(), &Batch{
+		Routes: []*v1.Route{
+			{Metadata: v1.Metadata{ID: "bad", Name: "bad"}, Uri: "/bad"},
+			{Metadata: v1.Metadata{ID: "5", Name: "route5"}, Uri: "/5"},
+		},
+	})
+	assert.Contains(t, err.Error(), "failed to create route bad: unexpected status code 400: invalid configuration")
+	assert.Len(t, paths, 2)
+}
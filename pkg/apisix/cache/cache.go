@@ -67,6 +67,8 @@ type Cache interface {
 	ListSSL() ([]*v1.Ssl, error)
 	// ListUpstreams lists all upstreams in cache.
 	ListUpstreams() ([]*v1.Upstream, error)
+	// ListUpstreamsByName lists the upstreams with the name in cache.
+	ListUpstreamsByName(string) ([]*v1.Upstream, error)
 	// ListStreamRoutes lists all stream_route in cache.
 	ListStreamRoutes() ([]*v1.StreamRoute, error)
 	// ListGlobalRules lists all global_rule objects in cache.
@@ -84,6 +86,10 @@ type Cache interface {
 	DeleteRoute(*v1.Route) error
 	// DeleteSSL deletes the specified ssl in cache.
 	DeleteSSL(*v1.Ssl) error
+	// CheckUpstreamReference returns ErrStillInUse if the upstream is still
+	// referenced by any route or stream_route in cache.
+	CheckUpstreamReference(*v1.Upstream) error
+
 	// DeleteUpstream deletes the specified upstream in cache.
 	DeleteUpstream(*v1.Upstream) error
 	// DeleteStreamRoute deletes the specified stream_route in cache.
@@ -216,6 +216,20 @@ func (c *dbCache) ListUpstreams() ([]*v1.Upstream, error) {
 	return upstreams, nil
 }
 
+func (c *dbCache) ListUpstreamsByName(name string) ([]*v1.Upstream, error) {
+	txn := c.db.Txn(false)
+	defer txn.Abort()
+	iter, err := txn.Get("upstream", "name", name)
+	if err != nil {
+		return nil, err
+	}
+	var upstreams []*v1.Upstream
+	for obj := iter.Next(); obj != nil; obj = iter.Next() {
+		upstreams = append(upstreams, obj.(*v1.Upstream).DeepCopy())
+	}
+	return upstreams, nil
+}
+
 func (c *dbCache) ListStreamRoutes() ([]*v1.StreamRoute, error) {
 	raws, err := c.list("stream_route")
 	if err != nil {
@@ -310,6 +324,10 @@ func (c *dbCache) DeleteSSL(ssl *v1.Ssl) error {
 	return c.delete("ssl", ssl)
 }
 
+func (c *dbCache) CheckUpstreamReference(u *v1.Upstream) error {
+	return c.checkUpstreamReference(u)
+}
+
 func (c *dbCache) DeleteUpstream(u *v1.Upstream) error {
 	if err := c.checkUpstreamReference(u); err != nil {
 		return err
@@ -369,6 +387,15 @@ func (c *dbCache) checkUpstreamReference(u *v1.Upstream) error {
 		return ErrStillInUse
 	}
 
+	// Upstream is referenced by the traffic-split plugin of Route.
+	obj, err = txn.First("route", "traffic_split_upstream_id", u.ID)
+	if err != nil && err != memdb.ErrNotFound {
+		return err
+	}
+	if obj != nil {
+		return ErrStillInUse
+	}
+
 	obj, err = txn.First("stream_route", "upstream_id", u.ID)
 	if err != nil && err != memdb.ErrNotFound {
 		return err
@@ -176,6 +176,22 @@ func TestMemDBCacheUpstream(t *testing.T) {
 		},
 	}
 	assert.Error(t, ErrNotFound, c.DeleteUpstream(u4))
+
+	// Upstreams with the same name but different configs.
+	u5 := &v1.Upstream{
+		Metadata: v1.Metadata{
+			Name: "abc",
+			ID:   "5",
+		},
+		Scheme: "https",
+	}
+	assert.Nil(t, c.InsertUpstream(u5), "inserting upstream 5")
+	upstreams, err = c.ListUpstreamsByName("abc")
+	assert.Nil(t, err, "listing upstreams by name")
+	assert.ElementsMatch(t, []*v1.Upstream{u1, u5}, upstreams)
+	upstreams, err = c.ListUpstreamsByName("name4")
+	assert.Nil(t, err, "listing upstreams by name")
+	assert.Len(t, upstreams, 0)
 }
 
 func TestMemDBCacheReference(t *testing.T) {
@@ -235,6 +251,80 @@ func TestMemDBCacheReference(t *testing.T) {
 	assert.Nil(t, db.DeletePluginConfig(pc))
 }
 
+func TestMemDBCacheTrafficSplitReference(t *testing.T) {
+	r := &v1.Route{
+		Metadata: v1.Metadata{
+			Name: "route",
+			ID:   "1",
+		},
+		UpstreamId: "1",
+		Plugins: v1.Plugins{
+			"traffic-split": &v1.TrafficSplitConfig{
+				Rules: []v1.TrafficSplitConfigRule{
+					{
+						WeightedUpstreams: []v1.TrafficSplitConfigRuleWeightedUpstream{
+							{UpstreamID: "2", Weight: 10},
+							{Weight: 90},
+						},
+					},
+				},
+			},
+		},
+	}
+	u := &v1.Upstream{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "upstream1",
+		},
+	}
+	u2 := &v1.Upstream{
+		Metadata: v1.Metadata{
+			ID:   "2",
+			Name: "upstream2",
+		},
+	}
+	u3 := &v1.Upstream{
+		Metadata: v1.Metadata{
+			ID:   "3",
+			Name: "upstream3",
+		},
+	}
+
+	db, err := NewMemDBCache()
+	assert.Nil(t, err, "NewMemDBCache")
+	assert.Nil(t, db.InsertUpstream(u))
+	assert.Nil(t, db.InsertUpstream(u2))
+	assert.Nil(t, db.InsertUpstream(u3))
+	assert.Nil(t, db.InsertRoute(r))
+
+	assert.Equal(t, ErrStillInUse, db.CheckUpstreamReference(u))
+	assert.Equal(t, ErrStillInUse, db.CheckUpstreamReference(u2))
+	assert.Nil(t, db.CheckUpstreamReference(u3))
+	assert.Equal(t, ErrStillInUse, db.DeleteUpstream(u2))
+	assert.Nil(t, db.DeleteUpstream(u3))
+
+	// The plugin config read from APISIX is a generic map.
+	r.Plugins = v1.Plugins{
+		"traffic-split": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"weighted_upstreams": []interface{}{
+						map[string]interface{}{"upstream_id": "1", "weight": 10},
+					},
+				},
+			},
+		},
+	}
+	r.UpstreamId = ""
+	assert.Nil(t, db.InsertRoute(r))
+	assert.Equal(t, ErrStillInUse, db.CheckUpstreamReference(u))
+	assert.Nil(t, db.CheckUpstreamReference(u2))
+
+	assert.Nil(t, db.DeleteRoute(r))
+	assert.Nil(t, db.DeleteUpstream(u))
+	assert.Nil(t, db.DeleteUpstream(u2))
+}
+
 func TestMemDBCacheStreamRoute(t *testing.T) {
 	c, err := NewMemDBCache()
 	assert.Nil(t, err, "NewMemDBCache")
@@ -16,7 +16,11 @@
 package cache
 
 import (
+	"fmt"
+
 	"github.com/hashicorp/go-memdb"
+
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 var (
@@ -48,6 +52,12 @@ var (
 						Indexer:      &memdb.StringFieldIndex{Field: "PluginConfigId"},
 						AllowMissing: true,
 					},
+					"traffic_split_upstream_id": {
+						Name:         "traffic_split_upstream_id",
+						Unique:       false,
+						Indexer:      &trafficSplitUpstreamIndex{},
+						AllowMissing: true,
+					},
 				},
 			},
 			"upstream": {
@@ -58,9 +68,11 @@ var (
 						Unique:  true,
 						Indexer: &memdb.StringFieldIndex{Field: "ID"},
 					},
+					// Upstreams with the same name but different
+					// configs have different IDs.
 					"name": {
 						Name:         "name",
-						Unique:       true,
+						Unique:       false,
 						Indexer:      &memdb.StringFieldIndex{Field: "Name"},
 						AllowMissing: true,
 					},
@@ -151,3 +163,32 @@ var (
 		},
 	}
 )
+
+// trafficSplitUpstreamIndex indexes the routes by the upstreams referenced
+// in their traffic-split plugin, so that an upstream shared by several routes
+// is not deleted while a route still splits the traffic to it.
+type trafficSplitUpstreamIndex struct{}
+
+func (idx *trafficSplitUpstreamIndex) FromObject(obj interface{}) (bool, [][]byte, error) {
+	r, ok := obj.(*v1.Route)
+	if !ok {
+		return false, nil, fmt.Errorf("unexpected object type %T", obj)
+	}
+	var vals [][]byte
+	for _, id := range v1.TrafficSplitUpstreamIDs(r) {
+		// Add the null character as a terminator like memdb.StringFieldIndex.
+		vals = append(vals, []byte(id+"\x00"))
+	}
+	return len(vals) > 0, vals, nil
+}
+
+func (idx *trafficSplitUpstreamIndex) FromArgs(args ...interface{}) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("must provide only a single argument")
+	}
+	arg, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("argument must be a string: %#v", args[0])
+	}
+	return []byte(arg + "\x00"), nil
+}
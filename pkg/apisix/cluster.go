@@ -16,6 +16,7 @@
 package apisix
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -29,7 +30,6 @@ import (
 	"sync/atomic"
 	"time"
 
-	"go.uber.org/multierr"
 	"go.uber.org/zap"
 	"k8s.io/apimachinery/pkg/util/wait"
 
@@ -40,8 +40,10 @@ import (
 )
 
 const (
-	_defaultTimeout      = 5 * time.Second
-	_defaultSyncInterval = 6 * time.Hour
+	_defaultTimeout             = 5 * time.Second
+	_defaultDialTimeout         = 3 * time.Second
+	_defaultMaxIdleConnsPerHost = 8
+	_defaultSyncInterval        = 6 * time.Hour
 
 	_cacheSyncing = iota
 	_cacheSynced
@@ -55,32 +57,84 @@ var (
 	ErrDuplicatedCluster = errors.New("duplicated cluster")
 	// ErrFunctionDisabled means the APISIX function is disabled
 	ErrFunctionDisabled = errors.New("function disabled")
+	// ErrAdminAPITimeout means the Admin API didn't respond in time, the
+	// request can be retried later.
+	ErrAdminAPITimeout = errors.New("apisix admin api timed out")
 
 	_errReadOnClosedResBody = errors.New("http: read on closed response body")
+)
 
-	// Default shared transport for apisix client
-	_defaultTransport = &http.Transport{
+func newTransport(dialTimeout time.Duration, maxIdleConnsPerHost int) *http.Transport {
+	return &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
-		Dial: (&net.Dialer{
-			Timeout: 3 * time.Second,
-		}).Dial,
 		DialContext: (&net.Dialer{
-			Timeout: 3 * time.Second,
+			Timeout: dialTimeout,
 		}).DialContext,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
 		ResponseHeaderTimeout: 30 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
-)
+}
+
+// AdminAPIError is returned when APISIX Admin API rejects the request, the
+// message is taken from the response body so the bad field reported by
+// APISIX is kept.
+type AdminAPIError struct {
+	StatusCode int
+	// Message is the "error_msg" of the response body, or the whole body if
+	// it's not in the APISIX error format.
+	Message string
+}
+
+func (e *AdminAPIError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Message)
+}
+
+func newAdminAPIError(code int, body string) error {
+	var resp struct {
+		ErrorMsg string `json:"error_msg"`
+	}
+	message := strings.TrimSpace(body)
+	if err := json.Unmarshal([]byte(body), &resp); err == nil && resp.ErrorMsg != "" {
+		message = resp.ErrorMsg
+	}
+	return &AdminAPIError{
+		StatusCode: code,
+		Message:    message,
+	}
+}
 
 // ClusterOptions contains parameters to customize APISIX client.
 type ClusterOptions struct {
 	Name     string
 	AdminKey string
 	BaseURL  string
-	Timeout  time.Duration
+	// Timeout is the timeout of a request, including connecting and
+	// reading the response body.
+	Timeout time.Duration
+	// DialTimeout is the timeout to connect to APISIX.
+	DialTimeout time.Duration
+	// MaxIdleConnsPerHost is the maximum number of idle connections kept
+	// to APISIX.
+	MaxIdleConnsPerHost int
 	// SyncInterval is the interval to sync schema.
-	SyncInterval     types.TimeDuration
+	SyncInterval types.TimeDuration
+	// SchemaCacheTTL is the maximum duration to use a cached schema before
+	// fetching it from APISIX again, zero means the cached schemas are used
+	// until the next schema sync.
+	SchemaCacheTTL time.Duration
+	// MaxRetries is the maximum number of retries when APISIX responds
+	// with 429 or 503, zero means no retry.
+	MaxRetries int
+	// MaxRetryBackoff caps the delay between retries, a Retry-After
+	// beyond it fails the request instead of waiting.
+	MaxRetryBackoff time.Duration
+	// BatchConcurrency is the maximum number of in-flight writes of the
+	// batches to the cluster.
+	BatchConcurrency int
 	MetricsCollector metrics.Collector
+	// AuditLogger records the writes to the cluster, nil means disabled.
+	AuditLogger AuditLogger
 }
 
 type cluster struct {
@@ -91,6 +145,7 @@ type cluster struct {
 	cli                     *http.Client
 	cacheState              int32
 	cache                   cache.Cache
+	managed                 *managedCache // wraps cache to keep the managed_resources gauge
 	cacheSynced             chan struct{}
 	cacheSyncErr            error
 	route                   Route
@@ -98,12 +153,20 @@ type cluster struct {
 	ssl                     SSL
 	streamRoute             StreamRoute
 	globalRules             GlobalRule
+	pluginMetadata          PluginMetadata
 	consumer                Consumer
 	plugin                  Plugin
 	schema                  Schema
 	pluginConfig            PluginConfig
 	metricsCollector        metrics.Collector
+	auditLogger             AuditLogger
 	upstreamServiceRelation UpstreamServiceRelation
+	schemaCacheTTL          time.Duration
+	maxRetries              int
+	maxRetryBackoff         time.Duration
+	writeCache              *writeCache
+	batchSem                chan struct{}
+	dialTimeout             time.Duration
 }
 
 func newCluster(ctx context.Context, o *ClusterOptions) (Cluster, error) {
@@ -113,6 +176,18 @@ func newCluster(ctx context.Context, o *ClusterOptions) (Cluster, error) {
 	if o.Timeout == time.Duration(0) {
 		o.Timeout = _defaultTimeout
 	}
+	if o.DialTimeout == time.Duration(0) {
+		o.DialTimeout = _defaultDialTimeout
+	}
+	if o.MaxIdleConnsPerHost <= 0 {
+		o.MaxIdleConnsPerHost = _defaultMaxIdleConnsPerHost
+	}
+	if o.MaxRetryBackoff == time.Duration(0) {
+		o.MaxRetryBackoff = _defaultMaxRetryBackoff
+	}
+	if o.BatchConcurrency <= 0 {
+		o.BatchConcurrency = _defaultBatchConcurrency
+	}
 	if o.SyncInterval.Duration == time.Duration(0) {
 		o.SyncInterval = types.TimeDuration{Duration: _defaultSyncInterval}
 	}
@@ -130,27 +205,37 @@ func newCluster(ctx context.Context, o *ClusterOptions) (Cluster, error) {
 		adminKey:    o.AdminKey,
 		cli: &http.Client{
 			Timeout:   o.Timeout,
-			Transport: _defaultTransport,
+			Transport: newTransport(o.DialTimeout, o.MaxIdleConnsPerHost),
 		},
 		cacheState:       _cacheSyncing, // default state
 		cacheSynced:      make(chan struct{}),
 		metricsCollector: o.MetricsCollector,
+		auditLogger:      o.AuditLogger,
+		schemaCacheTTL:   o.SchemaCacheTTL,
+		maxRetries:       o.MaxRetries,
+		maxRetryBackoff:  o.MaxRetryBackoff,
+		writeCache:       newWriteCache(),
+		batchSem:         make(chan struct{}, o.BatchConcurrency),
+		dialTimeout:      o.DialTimeout,
 	}
 	c.route = newRouteClient(c)
 	c.upstream = newUpstreamClient(c)
 	c.ssl = newSSLClient(c)
 	c.streamRoute = newStreamRouteClient(c)
 	c.globalRules = newGlobalRuleClient(c)
+	c.pluginMetadata = newPluginMetadataClient(c)
 	c.consumer = newConsumerClient(c)
 	c.plugin = newPluginClient(c)
 	c.schema = newSchemaClient(c)
 	c.pluginConfig = newPluginConfigClient(c)
 	c.upstreamServiceRelation = newUpstreamServiceRelation(c)
 
-	c.cache, err = cache.NewMemDBCache()
+	db, err := cache.NewMemDBCache()
 	if err != nil {
 		return nil, err
 	}
+	c.managed = newManagedCache(db, c.name, c.metricsCollector)
+	c.cache = c.managed
 
 	go c.syncCache(ctx)
 	go c.syncSchema(ctx, o.SyncInterval.Duration)
@@ -442,6 +527,11 @@ func (c *cluster) GlobalRule() GlobalRule {
 	return c.globalRules
 }
 
+// PluginMetadata implements Cluster.PluginMetadata method.
+func (c *cluster) PluginMetadata() PluginMetadata {
+	return c.pluginMetadata
+}
+
 // Consumer implements Cluster.Consumer method.
 func (c *cluster) Consumer() Consumer {
 	return c.consumer
@@ -498,9 +588,27 @@ func (c *cluster) HealthCheck(ctx context.Context) (err error) {
 	return err
 }
 
+// AdminAPIHealthCheck implements Cluster.AdminAPIHealthCheck method.
+func (c *cluster) AdminAPIHealthCheck(ctx context.Context) error {
+	url := c.baseURL + "/plugins/list"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer drainBody(resp.Body, url)
+	if resp.StatusCode != http.StatusOK {
+		return newAdminAPIError(resp.StatusCode, readBody(resp.Body, url))
+	}
+	return nil
+}
+
 func (c *cluster) healthCheck(ctx context.Context) (err error) {
 	// tcp socket probe
-	d := net.Dialer{Timeout: 3 * time.Second}
+	d := net.Dialer{Timeout: c.dialTimeout}
 	conn, err := d.DialContext(ctx, "tcp", c.baseURLHost)
 	if err != nil {
 		return err
@@ -514,6 +622,36 @@ func (c *cluster) healthCheck(ctx context.Context) (err error) {
 	return
 }
 
+func (c *cluster) ResetWriteCache() {
+	c.writeCache.reset()
+}
+
+// RouteIDs implements Cluster.RouteIDs method.
+func (c *cluster) RouteIDs() (map[string]struct{}, error) {
+	routes, err := c.cache.ListRoutes()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]struct{}, len(routes))
+	for _, r := range routes {
+		ids[r.ID] = struct{}{}
+	}
+	return ids, nil
+}
+
+// Cache implements Cluster.Cache method.
+func (c *cluster) Cache() cache.Cache {
+	return c.cache
+}
+
+// ManagedResources implements Cluster.ManagedResources method.
+func (c *cluster) ManagedResources() (map[string]int, error) {
+	if c.managed == nil {
+		return map[string]int{}, nil
+	}
+	return c.managed.counts(), nil
+}
+
 func (c *cluster) applyAuth(req *http.Request) {
 	if c.adminKey != "" {
 		req.Header.Set("X-API-Key", c.adminKey)
@@ -522,7 +660,54 @@ func (c *cluster) applyAuth(req *http.Request) {
 
 func (c *cluster) do(req *http.Request) (*http.Response, error) {
 	c.applyAuth(req)
-	return c.cli.Do(req)
+	for attempt := 0; ; attempt++ {
+		resp, err := c.cli.Do(req)
+		if err != nil {
+			// Don't report the cancellation of the caller as a timeout.
+			if isTimeout(err) && req.Context().Err() == nil {
+				err = fmt.Errorf("%w: %s", ErrAdminAPITimeout, err)
+			}
+			return nil, err
+		}
+		if !isRetryableStatus(resp.StatusCode) || attempt >= c.maxRetries {
+			return resp, err
+		}
+		// The body was consumed, only retry if it can be read again.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, nil
+		}
+		delay := retryBackoff(attempt, c.maxRetryBackoff)
+		if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if after > c.maxRetryBackoff {
+				// Don't block the caller for so long, it will be
+				// retried later (e.g. by the workqueue).
+				return resp, nil
+			}
+			delay = after
+		}
+		drainBody(resp.Body, req.URL.String())
+		log.Warnw("apisix is busy, retrying the request",
+			zap.String("cluster", c.name),
+			zap.String("method", req.Method),
+			zap.String("url", req.URL.String()),
+			zap.Int("status_code", resp.StatusCode),
+			zap.Duration("delay", delay),
+		)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
 }
 
 func (c *cluster) isFunctionDisabled(body string) bool {
@@ -549,12 +734,12 @@ func (c *cluster) getResource(ctx context.Context, url, resource string) (*getRe
 			return nil, ErrFunctionDisabled
 		}
 		if resp.StatusCode == http.StatusNotFound {
+			// The object was removed behind us, it must be written
+			// again.
+			c.writeCache.delete(url)
 			return nil, cache.ErrNotFound
-		} else {
-			err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-			err = multierr.Append(err, fmt.Errorf("error message: %s", body))
 		}
-		return nil, err
+		return nil, newAdminAPIError(resp.StatusCode, body)
 	}
 
 	var res getResponse
@@ -585,9 +770,7 @@ func (c *cluster) listResource(ctx context.Context, url, resource string) (*list
 		if c.isFunctionDisabled(body) {
 			return nil, ErrFunctionDisabled
 		}
-		err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-		err = multierr.Append(err, fmt.Errorf("error message: %s", body))
-		return nil, err
+		return nil, newAdminAPIError(resp.StatusCode, body)
 	}
 
 	var list listResponse
@@ -599,8 +782,23 @@ func (c *cluster) listResource(ctx context.Context, url, resource string) (*list
 	return &list, nil
 }
 
-func (c *cluster) createResource(ctx context.Context, url, resource string, body io.Reader) (*createResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+func (c *cluster) createResource(ctx context.Context, url, resource string, body io.Reader) (_ *createResponse, err error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.writeCache.get(url, data); ok {
+		c.metricsCollector.IncrSkippedWrites(resource)
+		return cached, nil
+	}
+	// Forget the last write, the object might be changed even if this
+	// one fails.
+	c.writeCache.delete(url)
+	var code int
+	defer func() {
+		c.audit(AuditActionCreate, resource, url, data, code, err)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -609,6 +807,7 @@ func (c *cluster) createResource(ctx context.Context, url, resource string, body
 	if err != nil {
 		return nil, err
 	}
+	code = resp.StatusCode
 	c.metricsCollector.RecordAPISIXLatency(time.Since(start), "create")
 	c.metricsCollector.RecordAPISIXCode(resp.StatusCode, resource)
 
@@ -619,9 +818,7 @@ func (c *cluster) createResource(ctx context.Context, url, resource string, body
 		if c.isFunctionDisabled(body) {
 			return nil, ErrFunctionDisabled
 		}
-		err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-		err = multierr.Append(err, fmt.Errorf("error message: %s", body))
-		return nil, err
+		return nil, newAdminAPIError(resp.StatusCode, body)
 	}
 
 	var cr createResponse
@@ -629,11 +826,27 @@ func (c *cluster) createResource(ctx context.Context, url, resource string, body
 	if err := dec.Decode(&cr); err != nil {
 		return nil, err
 	}
+	c.writeCache.set(url, data, &cr)
 	return &cr, nil
 }
 
-func (c *cluster) updateResource(ctx context.Context, url, resource string, body io.Reader) (*updateResponse, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
+func (c *cluster) updateResource(ctx context.Context, url, resource string, body io.Reader) (_ *updateResponse, err error) {
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := c.writeCache.get(url, data); ok {
+		c.metricsCollector.IncrSkippedWrites(resource)
+		return cached, nil
+	}
+	// Forget the last write, the object might be changed even if this
+	// one fails.
+	c.writeCache.delete(url)
+	var code int
+	defer func() {
+		c.audit(AuditActionUpdate, resource, url, data, code, err)
+	}()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(data))
 	if err != nil {
 		return nil, err
 	}
@@ -642,6 +855,7 @@ func (c *cluster) updateResource(ctx context.Context, url, resource string, body
 	if err != nil {
 		return nil, err
 	}
+	code = resp.StatusCode
 	c.metricsCollector.RecordAPISIXLatency(time.Since(start), "update")
 	c.metricsCollector.RecordAPISIXCode(resp.StatusCode, resource)
 
@@ -652,19 +866,22 @@ func (c *cluster) updateResource(ctx context.Context, url, resource string, body
 		if c.isFunctionDisabled(body) {
 			return nil, ErrFunctionDisabled
 		}
-		err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-		err = multierr.Append(err, fmt.Errorf("error message: %s", body))
-		return nil, err
+		return nil, newAdminAPIError(resp.StatusCode, body)
 	}
 	var ur updateResponse
 	dec := json.NewDecoder(resp.Body)
 	if err := dec.Decode(&ur); err != nil {
 		return nil, err
 	}
+	c.writeCache.set(url, data, &ur)
 	return &ur, nil
 }
 
-func (c *cluster) deleteResource(ctx context.Context, url, resource string) error {
+func (c *cluster) deleteResource(ctx context.Context, url, resource string) (err error) {
+	var code int
+	defer func() {
+		c.audit(AuditActionDelete, resource, url, nil, code, err)
+	}()
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return err
@@ -674,8 +891,10 @@ func (c *cluster) deleteResource(ctx context.Context, url, resource string) erro
 	if err != nil {
 		return err
 	}
+	code = resp.StatusCode
 	c.metricsCollector.RecordAPISIXLatency(time.Since(start), "delete")
 	c.metricsCollector.RecordAPISIXCode(resp.StatusCode, resource)
+	c.writeCache.delete(url)
 
 	defer drainBody(resp.Body, url)
 
@@ -684,12 +903,10 @@ func (c *cluster) deleteResource(ctx context.Context, url, resource string) erro
 		if c.isFunctionDisabled(message) {
 			return ErrFunctionDisabled
 		}
-		err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-		err = multierr.Append(err, fmt.Errorf("error message: %s", message))
 		if strings.Contains(message, "still using") {
 			return cache.ErrStillInUse
 		}
-		return err
+		return newAdminAPIError(resp.StatusCode, message)
 	}
 	return nil
 }
@@ -746,11 +963,8 @@ func (c *cluster) getSchema(ctx context.Context, url, resource string) (string,
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNotFound {
 			return "", cache.ErrNotFound
-		} else {
-			err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-			err = multierr.Append(err, fmt.Errorf("error message: %s", readBody(resp.Body, url)))
 		}
-		return "", err
+		return "", newAdminAPIError(resp.StatusCode, readBody(resp.Body, url))
 	}
 
 	return readBody(resp.Body, url), nil
@@ -774,11 +988,8 @@ func (c *cluster) getList(ctx context.Context, url, resource string) ([]string,
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNotFound {
 			return nil, cache.ErrNotFound
-		} else {
-			err = multierr.Append(err, fmt.Errorf("unexpected status code %d", resp.StatusCode))
-			err = multierr.Append(err, fmt.Errorf("error message: %s", readBody(resp.Body, url)))
 		}
-		return nil, err
+		return nil, newAdminAPIError(resp.StatusCode, readBody(resp.Body, url))
 	}
 
 	var listResponse map[string]interface{}
@@ -17,10 +17,15 @@ package apisix
 
 import (
 	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
@@ -102,3 +107,92 @@ func TestNonExistentCluster(t *testing.T) {
 	err = apisix.Cluster("non-existent-cluster").PluginConfig().Delete(context.Background(), &v1.PluginConfig{})
 	assert.Equal(t, ErrClusterNotExist, err)
 }
+
+func TestAdminAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		if strings.HasSuffix(r.URL.Path, "/routes/1") {
+			_, _ = w.Write([]byte(`{"error_msg":"failed to check the configuration of plugin limit-count err: property \"count\" is required"}`))
+			return
+		}
+		_, _ = w.Write([]byte("bad request\n"))
+	}))
+	defer srv.Close()
+
+	closedCh := make(chan struct{})
+	close(closedCh)
+	cli := newRouteClient(&cluster{
+		baseURL:          srv.URL + "/apisix/admin",
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+	})
+
+	_, err := cli.Create(context.Background(), &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "test",
+		},
+		Uri: "/bar",
+	})
+	var adminErr *AdminAPIError
+	assert.True(t, errors.As(err, &adminErr))
+	assert.Equal(t, http.StatusBadRequest, adminErr.StatusCode)
+	assert.Equal(t, `failed to check the configuration of plugin limit-count err: property "count" is required`, adminErr.Message)
+	assert.Equal(t, `unexpected status code 400: failed to check the configuration of plugin limit-count err: property "count" is required`, err.Error())
+
+	// The body is kept if it's not in the APISIX error format.
+	_, err = cli.Update(context.Background(), &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "2",
+			Name: "test",
+		},
+		Uri: "/bar",
+	})
+	assert.Equal(t, "unexpected status code 400: bad request", err.Error())
+}
+
+func TestAdminAPIHealthCheck(t *testing.T) {
+	healthy := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/apisix/admin/plugins/list", r.URL.Path)
+		assert.Equal(t, "123456", r.Header.Get("X-API-Key"))
+		if !healthy {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error_msg":"failed to check token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`["limit-count"]`))
+	}))
+	defer srv.Close()
+
+	c := &cluster{
+		baseURL:  srv.URL + "/apisix/admin",
+		adminKey: "123456",
+		cli:      http.DefaultClient,
+	}
+	assert.Nil(t, c.AdminAPIHealthCheck(context.Background()))
+
+	healthy = false
+	assert.Equal(t, "unexpected status code 401: failed to check token", c.AdminAPIHealthCheck(context.Background()).Error())
+}
+
+func TestRouteIDs(t *testing.T) {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	c := &cluster{cache: db}
+
+	ids, err := c.RouteIDs()
+	assert.Nil(t, err)
+	assert.Len(t, ids, 0)
+
+	assert.Nil(t, db.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Name: "r1"}}))
+	assert.Nil(t, db.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "2", Name: "r2"}}))
+	ids, err = c.RouteIDs()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]struct{}{"1": {}, "2": {}}, ids)
+
+	_, err = (&nonExistentCluster{}).RouteIDs()
+	assert.Equal(t, ErrClusterNotExist, err)
+}
@@ -48,7 +48,7 @@ func (r *globalRuleClient) Get(ctx context.Context, name string) (*v1.GlobalRule
 		zap.String("url", r.url),
 		zap.String("cluster", "default"),
 	)
-	rid := id.GenID(name)
+	rid := id.GenID(id.GlobalRule, name)
 	globalRule, err := r.cluster.cache.GetGlobalRule(rid)
 	if err == nil {
 		return globalRule, nil
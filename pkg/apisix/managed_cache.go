@@ -0,0 +1,242 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"sync"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// managedCache wraps the cache to count the objects managed by the controller
+// (i.e. with the managed-by label) per resource type. The counts are updated
+// on every write to the cache, so the gauge follows all the write paths
+// without listing the cache.
+type managedCache struct {
+	cache.Cache
+
+	cluster          string
+	metricsCollector metrics.Collector
+
+	mu  sync.Mutex
+	ids map[string]map[string]struct{}
+	// closed is true once the cache is dropped, its counts are no longer
+	// reported.
+	closed bool
+}
+
+func newManagedCache(c cache.Cache, cluster string, collector metrics.Collector) *managedCache {
+	return &managedCache{
+		Cache:            c,
+		cluster:          cluster,
+		metricsCollector: collector,
+		ids:              make(map[string]map[string]struct{}),
+	}
+}
+
+func isManaged(labels map[string]string) bool {
+	return labels[v1.ManagedByLabel] == v1.ManagedBy()
+}
+
+// track reflects the written object to the count of the resource type, an
+// object which is no longer managed (e.g. loses the managed-by label) is not
+// counted.
+func (c *managedCache) track(resource, key string, managed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	ids, ok := c.ids[resource]
+	if !ok {
+		ids = make(map[string]struct{})
+		c.ids[resource] = ids
+	}
+	if managed {
+		ids[key] = struct{}{}
+	} else {
+		delete(ids, key)
+	}
+	c.metricsCollector.SetManagedResources(resource, c.cluster, len(ids))
+}
+
+func (c *managedCache) forget(resource, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	ids := c.ids[resource]
+	delete(ids, key)
+	c.metricsCollector.SetManagedResources(resource, c.cluster, len(ids))
+}
+
+// close stops reporting the counts and deletes the gauge series of the
+// cluster, it's called once the cache is dropped, i.e. the cluster is
+// deleted or replaced.
+func (c *managedCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.closed = true
+	for resource := range c.ids {
+		c.metricsCollector.DeleteManagedResources(resource, c.cluster)
+	}
+}
+
+// publish reports the counts again, the gauge series of the cluster might be
+// deleted by closing the cache it replaces.
+func (c *managedCache) publish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+	for resource, ids := range c.ids {
+		c.metricsCollector.SetManagedResources(resource, c.cluster, len(ids))
+	}
+}
+
+// counts returns the number of managed objects per resource type.
+func (c *managedCache) counts() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	counts := make(map[string]int, len(c.ids))
+	for resource, ids := range c.ids {
+		counts[resource] = len(ids)
+	}
+	return counts
+}
+
+func (c *managedCache) InsertRoute(r *v1.Route) error {
+	if err := c.Cache.InsertRoute(r); err != nil {
+		return err
+	}
+	c.track("route", r.ID, isManaged(r.Labels))
+	return nil
+}
+
+func (c *managedCache) DeleteRoute(r *v1.Route) error {
+	if err := c.Cache.DeleteRoute(r); err != nil {
+		return err
+	}
+	c.forget("route", r.ID)
+	return nil
+}
+
+func (c *managedCache) InsertUpstream(u *v1.Upstream) error {
+	if err := c.Cache.InsertUpstream(u); err != nil {
+		return err
+	}
+	c.track("upstream", u.ID, isManaged(u.Labels))
+	return nil
+}
+
+func (c *managedCache) DeleteUpstream(u *v1.Upstream) error {
+	if err := c.Cache.DeleteUpstream(u); err != nil {
+		return err
+	}
+	c.forget("upstream", u.ID)
+	return nil
+}
+
+func (c *managedCache) InsertSSL(ssl *v1.Ssl) error {
+	if err := c.Cache.InsertSSL(ssl); err != nil {
+		return err
+	}
+	c.track("ssl", ssl.ID, isManaged(ssl.Labels))
+	return nil
+}
+
+func (c *managedCache) DeleteSSL(ssl *v1.Ssl) error {
+	if err := c.Cache.DeleteSSL(ssl); err != nil {
+		return err
+	}
+	c.forget("ssl", ssl.ID)
+	return nil
+}
+
+func (c *managedCache) InsertStreamRoute(sr *v1.StreamRoute) error {
+	if err := c.Cache.InsertStreamRoute(sr); err != nil {
+		return err
+	}
+	c.track("streamRoute", sr.ID, isManaged(sr.Labels))
+	return nil
+}
+
+func (c *managedCache) DeleteStreamRoute(sr *v1.StreamRoute) error {
+	if err := c.Cache.DeleteStreamRoute(sr); err != nil {
+		return err
+	}
+	c.forget("streamRoute", sr.ID)
+	return nil
+}
+
+func (c *managedCache) InsertConsumer(consumer *v1.Consumer) error {
+	if err := c.Cache.InsertConsumer(consumer); err != nil {
+		return err
+	}
+	c.track("consumer", consumer.Username, isManaged(consumer.Labels))
+	return nil
+}
+
+func (c *managedCache) DeleteConsumer(consumer *v1.Consumer) error {
+	if err := c.Cache.DeleteConsumer(consumer); err != nil {
+		return err
+	}
+	c.forget("consumer", consumer.Username)
+	return nil
+}
+
+// InsertGlobalRule counts the global rule of the cluster only, global rules
+// don't have labels.
+func (c *managedCache) InsertGlobalRule(gr *v1.GlobalRule) error {
+	if err := c.Cache.InsertGlobalRule(gr); err != nil {
+		return err
+	}
+	c.track("globalRule", gr.ID, gr.ID == id.GenID(id.GlobalRule, c.cluster))
+	return nil
+}
+
+func (c *managedCache) DeleteGlobalRule(gr *v1.GlobalRule) error {
+	if err := c.Cache.DeleteGlobalRule(gr); err != nil {
+		return err
+	}
+	c.forget("globalRule", gr.ID)
+	return nil
+}
+
+func (c *managedCache) InsertPluginConfig(pc *v1.PluginConfig) error {
+	if err := c.Cache.InsertPluginConfig(pc); err != nil {
+		return err
+	}
+	c.track("pluginConfig", pc.ID, isManaged(pc.Labels))
+	return nil
+}
+
+func (c *managedCache) DeletePluginConfig(pc *v1.PluginConfig) error {
+	if err := c.Cache.DeletePluginConfig(pc); err != nil {
+		return err
+	}
+	c.forget("pluginConfig", pc.ID)
+	return nil
+}
@@ -0,0 +1,121 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestManagedCache(t *testing.T) {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	c := newManagedCache(db, "default", metrics.NewPrometheusCollector())
+
+	managed := map[string]string{v1.ManagedByLabel: v1.ManagedBy()}
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Labels: managed}}))
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "2", Labels: managed}}))
+	// Created by others.
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "3"}}))
+	assert.Nil(t, c.InsertSSL(&v1.Ssl{ID: "1", Labels: managed}))
+	assert.Nil(t, c.InsertConsumer(&v1.Consumer{Username: "jack", Labels: managed}))
+	assert.Nil(t, c.InsertGlobalRule(&v1.GlobalRule{ID: id.GenID(id.GlobalRule, "default")}))
+	assert.Nil(t, c.InsertGlobalRule(&v1.GlobalRule{ID: "another"}))
+	assert.Equal(t, map[string]int{"route": 2, "ssl": 1, "consumer": 1, "globalRule": 1}, c.counts())
+
+	// An object losing the label is no longer counted.
+	assert.Nil(t, c.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "2"}}))
+	assert.Nil(t, c.DeleteSSL(&v1.Ssl{ID: "1"}))
+	assert.Nil(t, c.DeleteConsumer(&v1.Consumer{Username: "jack"}))
+	assert.Equal(t, map[string]int{"route": 1, "ssl": 0, "consumer": 0, "globalRule": 1}, c.counts())
+
+	// The objects are still written to the cache.
+	routes, err := c.ListRoutes()
+	assert.Nil(t, err)
+	assert.Len(t, routes, 3)
+}
+
+type fakeGaugeCollector struct {
+	metrics.Collector
+
+	mu     sync.Mutex
+	gauges map[string]int
+}
+
+func (f *fakeGaugeCollector) SetManagedResources(resource, cluster string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.gauges[cluster+"/"+resource] = n
+}
+
+func (f *fakeGaugeCollector) DeleteManagedResources(resource, cluster string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.gauges, cluster+"/"+resource)
+}
+
+func (f *fakeGaugeCollector) get() map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	gauges := make(map[string]int, len(f.gauges))
+	for k, v := range f.gauges {
+		gauges[k] = v
+	}
+	return gauges
+}
+
+func TestManagedResourcesOfDroppedCluster(t *testing.T) {
+	collector := &fakeGaugeCollector{
+		Collector: metrics.NewPrometheusCollector(),
+		gauges:    make(map[string]int),
+	}
+	cli, err := NewClient()
+	assert.Nil(t, err)
+	opts := &ClusterOptions{
+		Name:             "edge",
+		BaseURL:          "http://edge:9080/apisix/admin",
+		MetricsCollector: collector,
+	}
+	ctx := context.Background()
+	assert.Nil(t, cli.AddCluster(ctx, opts))
+
+	managed := map[string]string{v1.ManagedByLabel: v1.ManagedBy()}
+	old := cli.Cluster("edge").(*cluster).managed
+	assert.Nil(t, old.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Labels: managed}}))
+	assert.Nil(t, old.InsertSSL(&v1.Ssl{ID: "1", Labels: managed}))
+	assert.Equal(t, map[string]int{"edge/route": 1, "edge/ssl": 1}, collector.get())
+
+	// The new cache has no SSLs, the series of the old cache are dropped.
+	assert.Nil(t, cli.UpdateCluster(ctx, opts))
+	assert.Equal(t, map[string]int{}, collector.get())
+	curr := cli.Cluster("edge").(*cluster).managed
+	assert.Nil(t, curr.InsertRoute(&v1.Route{Metadata: v1.Metadata{ID: "1", Labels: managed}}))
+	assert.Equal(t, map[string]int{"edge/route": 1}, collector.get())
+
+	// The writes to the old cache are no longer reported.
+	assert.Nil(t, old.InsertSSL(&v1.Ssl{ID: "2", Labels: managed}))
+	assert.Equal(t, map[string]int{"edge/route": 1}, collector.get())
+
+	cli.DeleteCluster("edge")
+	assert.Equal(t, map[string]int{}, collector.get())
+}
@@ -34,6 +34,7 @@ func newNonExistentCluster() *nonExistentCluster {
 			upstream:                &dummyUpstream{},
 			streamRoute:             &dummyStreamRoute{},
 			globalRule:              &dummyGlobalRule{},
+			pluginMetadata:          &dummyPluginMetadata{},
 			consumer:                &dummyConsumer{},
 			plugin:                  &dummyPlugin{},
 			schema:                  &dummySchema{},
@@ -49,6 +50,7 @@ type embedDummyResourceImplementer struct {
 	upstream                Upstream
 	streamRoute             StreamRoute
 	globalRule              GlobalRule
+	pluginMetadata          PluginMetadata
 	consumer                Consumer
 	plugin                  Plugin
 	schema                  Schema
@@ -100,12 +102,20 @@ func (f *dummySSL) Update(_ context.Context, _ *v1.Ssl) (*v1.Ssl, error) {
 	return nil, ErrClusterNotExist
 }
 
+func (f *dummySSL) VerifySNIs(_ context.Context, _ *v1.Ssl) ([]string, error) {
+	return nil, ErrClusterNotExist
+}
+
 type dummyUpstream struct{}
 
 func (f *dummyUpstream) Get(_ context.Context, _ string) (*v1.Upstream, error) {
 	return nil, ErrClusterNotExist
 }
 
+func (f *dummyUpstream) ListByName(_ context.Context, _ string) ([]*v1.Upstream, error) {
+	return nil, ErrClusterNotExist
+}
+
 func (f *dummyUpstream) List(_ context.Context) ([]*v1.Upstream, error) {
 	return nil, ErrClusterNotExist
 }
@@ -166,6 +176,20 @@ func (f *dummyGlobalRule) Update(_ context.Context, _ *v1.GlobalRule) (*v1.Globa
 	return nil, ErrClusterNotExist
 }
 
+type dummyPluginMetadata struct{}
+
+func (f *dummyPluginMetadata) Get(_ context.Context, _ string) (*v1.PluginMetadata, error) {
+	return nil, ErrClusterNotExist
+}
+
+func (f *dummyPluginMetadata) Update(_ context.Context, _ *v1.PluginMetadata) (*v1.PluginMetadata, error) {
+	return nil, ErrClusterNotExist
+}
+
+func (f *dummyPluginMetadata) Delete(_ context.Context, _ *v1.PluginMetadata) error {
+	return ErrClusterNotExist
+}
+
 type dummyConsumer struct{}
 
 func (f *dummyConsumer) Get(_ context.Context, _ string) (*v1.Consumer, error) {
@@ -278,6 +302,10 @@ func (nc *nonExistentCluster) GlobalRule() GlobalRule {
 	return nc.globalRule
 }
 
+func (nc *nonExistentCluster) PluginMetadata() PluginMetadata {
+	return nc.pluginMetadata
+}
+
 func (nc *nonExistentCluster) Consumer() Consumer {
 	return nc.consumer
 }
@@ -306,6 +334,32 @@ func (nc *nonExistentCluster) HealthCheck(_ context.Context) error {
 	return nil
 }
 
+func (nc *nonExistentCluster) AdminAPIHealthCheck(_ context.Context) error {
+	return ErrClusterNotExist
+}
+
+func (nc *nonExistentCluster) ResetWriteCache() {}
+
+func (nc *nonExistentCluster) BatchCreate(_ context.Context, _ *Batch) error {
+	return ErrClusterNotExist
+}
+
+func (nc *nonExistentCluster) BatchUpdate(_ context.Context, _ *Batch) error {
+	return ErrClusterNotExist
+}
+
+func (nc *nonExistentCluster) RouteIDs() (map[string]struct{}, error) {
+	return nil, ErrClusterNotExist
+}
+
+func (nc *nonExistentCluster) Cache() cache.Cache {
+	return &dummyCache{}
+}
+
+func (nc *nonExistentCluster) ManagedResources() (map[string]int, error) {
+	return nil, ErrClusterNotExist
+}
+
 func (nc *nonExistentCluster) String() string {
 	return "non-existent cluster"
 }
@@ -336,17 +390,19 @@ func (c *dummyCache) GetPluginConfig(_ string) (*v1.PluginConfig, error) {
 func (c *dummyCache) GetUpstreamServiceRelation(_ string) (*v1.UpstreamServiceRelation, error) {
 	return nil, cache.ErrNotFound
 }
-func (c *dummyCache) ListRoutes() ([]*v1.Route, error)               { return nil, nil }
-func (c *dummyCache) ListSSL() ([]*v1.Ssl, error)                    { return nil, nil }
-func (c *dummyCache) ListUpstreams() ([]*v1.Upstream, error)         { return nil, nil }
-func (c *dummyCache) ListStreamRoutes() ([]*v1.StreamRoute, error)   { return nil, nil }
-func (c *dummyCache) ListGlobalRules() ([]*v1.GlobalRule, error)     { return nil, nil }
-func (c *dummyCache) ListConsumers() ([]*v1.Consumer, error)         { return nil, nil }
-func (c *dummyCache) ListSchema() ([]*v1.Schema, error)              { return nil, nil }
-func (c *dummyCache) ListPluginConfigs() ([]*v1.PluginConfig, error) { return nil, nil }
+func (c *dummyCache) ListRoutes() ([]*v1.Route, error)                     { return nil, nil }
+func (c *dummyCache) ListSSL() ([]*v1.Ssl, error)                          { return nil, nil }
+func (c *dummyCache) ListUpstreams() ([]*v1.Upstream, error)               { return nil, nil }
+func (c *dummyCache) ListUpstreamsByName(_ string) ([]*v1.Upstream, error) { return nil, nil }
+func (c *dummyCache) ListStreamRoutes() ([]*v1.StreamRoute, error)         { return nil, nil }
+func (c *dummyCache) ListGlobalRules() ([]*v1.GlobalRule, error)           { return nil, nil }
+func (c *dummyCache) ListConsumers() ([]*v1.Consumer, error)               { return nil, nil }
+func (c *dummyCache) ListSchema() ([]*v1.Schema, error)                    { return nil, nil }
+func (c *dummyCache) ListPluginConfigs() ([]*v1.PluginConfig, error)       { return nil, nil }
 func (c *dummyCache) ListUpstreamServiceRelation() ([]*v1.UpstreamServiceRelation, error) {
 	return nil, nil
 }
+func (c *dummyCache) CheckUpstreamReference(_ *v1.Upstream) error                       { return nil }
 func (c *dummyCache) DeleteRoute(_ *v1.Route) error                                     { return nil }
 func (c *dummyCache) DeleteSSL(_ *v1.Ssl) error                                         { return nil }
 func (c *dummyCache) DeleteUpstream(_ *v1.Upstream) error                               { return nil }
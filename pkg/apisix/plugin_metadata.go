@@ -0,0 +1,100 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type pluginMetadataClient struct {
+	url     string
+	cluster *cluster
+}
+
+func newPluginMetadataClient(c *cluster) PluginMetadata {
+	return &pluginMetadataClient{
+		url:     c.baseURL + "/plugin_metadata",
+		cluster: c,
+	}
+}
+
+// Get reads the plugin_metadata of the plugin from APISIX since the objects
+// are not cached.
+func (r *pluginMetadataClient) Get(ctx context.Context, name string) (*v1.PluginMetadata, error) {
+	log.Debugw("try to look up plugin_metadata",
+		zap.String("name", name),
+		zap.String("cluster", r.cluster.name),
+		zap.String("url", r.url),
+	)
+	url := r.url + "/" + name
+	resp, err := r.cluster.getResource(ctx, url, "pluginMetadata")
+	r.cluster.metricsCollector.IncrAPISIXRequest("pluginMetadata")
+	if err != nil {
+		return nil, err
+	}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(resp.Item.Value, &metadata); err != nil {
+		return nil, err
+	}
+	return &v1.PluginMetadata{
+		Name:     name,
+		Metadata: metadata,
+	}, nil
+}
+
+// Update creates or updates the plugin_metadata of the plugin. The objects
+// are not cached since they are only written when ApisixClusterConfigs
+// change.
+func (r *pluginMetadataClient) Update(ctx context.Context, obj *v1.PluginMetadata) (*v1.PluginMetadata, error) {
+	log.Debugw("try to update plugin_metadata",
+		zap.String("name", obj.Name),
+		zap.Any("metadata", obj.Metadata),
+		zap.String("cluster", r.cluster.name),
+		zap.String("url", r.url),
+	)
+	body, err := json.Marshal(obj.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	url := r.url + "/" + obj.Name
+	log.Debugw("updating plugin_metadata", zap.ByteString("body", body), zap.String("url", url))
+	_, err = r.cluster.updateResource(ctx, url, "pluginMetadata", bytes.NewReader(body))
+	r.cluster.metricsCollector.IncrAPISIXRequest("pluginMetadata")
+	if err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// Delete deletes the plugin_metadata of the plugin, it's fine if the object
+// doesn't exist.
+func (r *pluginMetadataClient) Delete(ctx context.Context, obj *v1.PluginMetadata) error {
+	log.Debugw("try to delete plugin_metadata",
+		zap.String("name", obj.Name),
+		zap.String("cluster", r.cluster.name),
+		zap.String("url", r.url),
+	)
+	url := r.url + "/" + obj.Name
+	err := r.cluster.deleteResource(ctx, url, "pluginMetadata")
+	r.cluster.metricsCollector.IncrAPISIXRequest("pluginMetadata")
+	return err
+}
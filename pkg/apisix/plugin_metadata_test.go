@@ -0,0 +1,160 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/nettest"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type fakeAPISIXPluginMetadataSrv struct {
+	metadata map[string]json.RawMessage
+}
+
+func (srv *fakeAPISIXPluginMetadataSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	if !strings.HasPrefix(r.URL.Path, "/apisix/admin/plugin_metadata/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/apisix/admin")
+
+	if r.Method == http.MethodDelete {
+		code := http.StatusNotFound
+		if _, ok := srv.metadata[key]; ok {
+			delete(srv.metadata, key)
+			code = http.StatusOK
+		}
+		w.WriteHeader(code)
+		return
+	}
+
+	if r.Method == http.MethodPut {
+		data, _ := ioutil.ReadAll(r.Body)
+		srv.metadata[key] = data
+		w.WriteHeader(http.StatusCreated)
+		resp := fakeCreateResp{
+			Action: "set",
+			Node: fakeItem{
+				Key:   "/apisix" + key,
+				Value: json.RawMessage(data),
+			},
+		}
+		data, _ = json.Marshal(resp)
+		_, _ = w.Write(data)
+		return
+	}
+	if r.Method == http.MethodGet {
+		data, ok := srv.metadata[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resp := fakeCreateResp{
+			Action: "get",
+			Node: fakeItem{
+				Key:   "/apisix" + key,
+				Value: json.RawMessage(data),
+			},
+		}
+		data, _ = json.Marshal(resp)
+		_, _ = w.Write(data)
+		return
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+}
+
+func runFakePluginMetadataSrv(t *testing.T) (*fakeAPISIXPluginMetadataSrv, *http.Server) {
+	srv := &fakeAPISIXPluginMetadataSrv{
+		metadata: make(map[string]json.RawMessage),
+	}
+
+	ln, _ := nettest.NewLocalListener("tcp")
+
+	httpSrv := &http.Server{
+		Addr:    ln.Addr().String(),
+		Handler: srv,
+	}
+
+	go func() {
+		if err := httpSrv.Serve(ln); err != nil && err != http.ErrServerClosed {
+			t.Errorf("failed to run http server: %s", err)
+		}
+	}()
+
+	return srv, httpSrv
+}
+
+func TestPluginMetadataClient(t *testing.T) {
+	fake, srv := runFakePluginMetadataSrv(t)
+	defer func() {
+		assert.Nil(t, srv.Shutdown(context.Background()))
+	}()
+
+	u := url.URL{
+		Scheme: "http",
+		Host:   srv.Addr,
+		Path:   "/apisix/admin",
+	}
+
+	closedCh := make(chan struct{})
+	close(closedCh)
+	cli := newPluginMetadataClient(&cluster{
+		baseURL:          u.String(),
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+	})
+
+	// Update
+	obj, err := cli.Update(context.Background(), &v1.PluginMetadata{
+		Name: "http-logger",
+		Metadata: map[string]interface{}{
+			"log_format": map[string]interface{}{
+				"host": "$host",
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "http-logger", obj.Name)
+	assert.Len(t, fake.metadata, 1)
+	assert.JSONEq(t, `{"log_format":{"host":"$host"}}`, string(fake.metadata["/plugin_metadata/http-logger"]))
+
+	// Get
+	obj, err = cli.Get(context.Background(), "http-logger")
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{"host": "$host"}, obj.Metadata["log_format"])
+
+	// Delete
+	assert.Nil(t, cli.Delete(context.Background(), obj))
+	assert.Len(t, fake.metadata, 0)
+
+	_, err = cli.Get(context.Background(), "http-logger")
+	assert.Equal(t, cache.ErrNotFound, err)
+}
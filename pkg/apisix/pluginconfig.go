@@ -49,7 +49,7 @@ func (pc *pluginConfigClient) Get(ctx context.Context, name string) (*v1.PluginC
 		zap.String("url", pc.url),
 		zap.String("cluster", "default"),
 	)
-	rid := id.GenID(name)
+	rid := id.GenID(id.PluginConfig, name)
 	pluginConfig, err := pc.cluster.cache.GetPluginConfig(rid)
 	if err == nil {
 		return pluginConfig, nil
@@ -0,0 +1,179 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	for attempt := 0; attempt < 64; attempt++ {
+		d := retryBackoff(attempt, time.Second)
+		assert.LessOrEqual(t, d, time.Second)
+		if attempt == 0 {
+			assert.GreaterOrEqual(t, d, _defaultRetryBackoff/2)
+			assert.LessOrEqual(t, d, _defaultRetryBackoff)
+		}
+		if attempt >= 4 {
+			assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("3", now)
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(2*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, d)
+
+	d, ok = parseRetryAfter(now.Add(-time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), d)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("-1", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
+
+func TestClusterRetry(t *testing.T) {
+	var (
+		requests   int
+		failures   int
+		status     int
+		retryAfter string
+		bodies     []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if requests <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(`{"error_msg":"busy"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"node":{"key":"/apisix/routes/1","value":{"id":"1","name":"test","uri":"/bar"}}}`))
+	}))
+	defer srv.Close()
+
+	closedCh := make(chan struct{})
+	close(closedCh)
+	c := &cluster{
+		baseURL:          srv.URL + "/apisix/admin",
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+		maxRetries:       2,
+		maxRetryBackoff:  10 * time.Millisecond,
+	}
+	route := &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "test",
+		},
+		Uri: "/bar",
+	}
+	reset := func(f, code int, after string) {
+		requests, failures, status, retryAfter, bodies = 0, f, code, after, nil
+	}
+
+	// Retried with the same body until it succeeds.
+	reset(2, http.StatusServiceUnavailable, "")
+	_, err := newRouteClient(c).Create(context.Background(), route)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, requests)
+	assert.Equal(t, bodies[0], bodies[2])
+	assert.Contains(t, bodies[0], `"uri":"/bar"`)
+
+	// Gives up once the retries are exhausted.
+	reset(3, http.StatusTooManyRequests, "0")
+	_, err = newRouteClient(c).Create(context.Background(), route)
+	assert.Equal(t, "unexpected status code 429: busy", err.Error())
+	assert.Equal(t, 3, requests)
+
+	// Not retried if the Retry-After is beyond the maximum backoff.
+	reset(1, http.StatusTooManyRequests, "60")
+	_, err = newRouteClient(c).Create(context.Background(), route)
+	assert.Equal(t, "unexpected status code 429: busy", err.Error())
+	assert.Equal(t, 1, requests)
+
+	// Bad requests fail immediately.
+	reset(1, http.StatusBadRequest, "")
+	_, err = newRouteClient(c).Create(context.Background(), route)
+	assert.Equal(t, "unexpected status code 400: busy", err.Error())
+	assert.Equal(t, 1, requests)
+}
+
+func TestClusterTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	closedCh := make(chan struct{})
+	close(closedCh)
+	c := &cluster{
+		baseURL: srv.URL + "/apisix/admin",
+		cli: &http.Client{
+			Timeout:   50 * time.Millisecond,
+			Transport: newTransport(time.Second, 1),
+		},
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+	}
+	route := &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "test",
+		},
+		Uri: "/bar",
+	}
+	_, err := newRouteClient(c).Create(context.Background(), route)
+	assert.True(t, errors.Is(err, ErrAdminAPITimeout))
+
+	// The cancellation of the caller isn't a timeout.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = newRouteClient(c).Create(ctx, route)
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrAdminAPITimeout))
+}
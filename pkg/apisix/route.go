@@ -49,7 +49,7 @@ func (r *routeClient) Get(ctx context.Context, name string) (*v1.Route, error) {
 		zap.String("url", r.url),
 		zap.String("cluster", "default"),
 	)
-	rid := id.GenID(name)
+	rid := id.GenID(id.Route, name)
 	route, err := r.cluster.cache.GetRoute(rid)
 	if err == nil {
 		return route, nil
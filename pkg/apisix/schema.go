@@ -17,11 +17,12 @@ package apisix
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
-	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
@@ -29,29 +30,50 @@ import (
 type schemaClient struct {
 	url     string
 	cluster *cluster
+
+	// fetchedAt records when the schemas are fetched from APISIX, it's
+	// used to expire the cached schemas.
+	fetchedAtLock sync.Mutex
+	fetchedAt     map[string]time.Time
 }
 
 func newSchemaClient(c *cluster) Schema {
 	return &schemaClient{
-		url:     c.baseURL + "/schema/",
-		cluster: c,
+		url:       c.baseURL + "/schema/",
+		cluster:   c,
+		fetchedAt: make(map[string]time.Time),
+	}
+}
+
+// expired reports whether the cached schema should be fetched again.
+func (sc *schemaClient) expired(name string) bool {
+	if sc.cluster.schemaCacheTTL <= 0 {
+		return false
 	}
+	sc.fetchedAtLock.Lock()
+	defer sc.fetchedAtLock.Unlock()
+	fetchedAt, ok := sc.fetchedAt[name]
+	return !ok || time.Since(fetchedAt) > sc.cluster.schemaCacheTTL
 }
 
 // GetSchema returns APISIX object's schema.
-func (sc schemaClient) getSchema(ctx context.Context, name string) (*v1.Schema, error) {
+func (sc *schemaClient) getSchema(ctx context.Context, name string) (*v1.Schema, error) {
 	log.Debugw("try to look up schema",
 		zap.String("name", name),
 		zap.String("url", sc.url),
 		zap.String("cluster", "default"),
 	)
 
-	sid := id.GenID(name)
-	schema, err := sc.cluster.cache.GetSchema(sid)
+	// Schemas are indexed by name in the cache.
+	cached, err := sc.cluster.cache.GetSchema(name)
 	if err == nil {
-		return schema, nil
-	}
-	if err == cache.ErrNotFound {
+		if !sc.expired(name) {
+			return cached, nil
+		}
+		log.Debugw("schema in cache is expired, will try to lookup from APISIX",
+			zap.String("name", name),
+		)
+	} else if err == cache.ErrNotFound {
 		log.Debugw("failed to find schema in cache, will try to lookup from APISIX",
 			zap.String("name", name),
 			zap.Error(err),
@@ -72,10 +94,14 @@ func (sc schemaClient) getSchema(ctx context.Context, name string) (*v1.Schema,
 			zap.String("cluster", "default"),
 			zap.Error(err),
 		)
+		if cached != nil {
+			// Prefer the expired schema to failing.
+			return cached, nil
+		}
 		return nil, err
 	}
 
-	schema = &v1.Schema{
+	schema := &v1.Schema{
 		Name:    name,
 		Content: content,
 	}
@@ -83,35 +109,38 @@ func (sc schemaClient) getSchema(ctx context.Context, name string) (*v1.Schema,
 		log.Errorf("failed to reflect schema create to cache: %s", err)
 		return nil, err
 	}
+	sc.fetchedAtLock.Lock()
+	sc.fetchedAt[name] = time.Now()
+	sc.fetchedAtLock.Unlock()
 	return schema, nil
 }
 
 // GetPluginSchema returns plugin's schema.
-func (sc schemaClient) GetPluginSchema(ctx context.Context, pluginName string) (*v1.Schema, error) {
+func (sc *schemaClient) GetPluginSchema(ctx context.Context, pluginName string) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "plugins/"+pluginName)
 }
 
 // GetRouteSchema returns route's schema.
-func (sc schemaClient) GetRouteSchema(ctx context.Context) (*v1.Schema, error) {
+func (sc *schemaClient) GetRouteSchema(ctx context.Context) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "route")
 }
 
 // GetUpstreamSchema returns upstream's schema.
-func (sc schemaClient) GetUpstreamSchema(ctx context.Context) (*v1.Schema, error) {
+func (sc *schemaClient) GetUpstreamSchema(ctx context.Context) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "upstream")
 }
 
 // GetConsumerSchema returns consumer's schema.
-func (sc schemaClient) GetConsumerSchema(ctx context.Context) (*v1.Schema, error) {
+func (sc *schemaClient) GetConsumerSchema(ctx context.Context) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "consumer")
 }
 
 // GetSslSchema returns SSL's schema.
-func (sc schemaClient) GetSslSchema(ctx context.Context) (*v1.Schema, error) {
+func (sc *schemaClient) GetSslSchema(ctx context.Context) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "ssl")
 }
 
 // GetPluginConfigSchema returns PluginConfig's schema.
-func (sc schemaClient) GetPluginConfigSchema(ctx context.Context) (*v1.Schema, error) {
+func (sc *schemaClient) GetPluginConfigSchema(ctx context.Context) (*v1.Schema, error) {
 	return sc.getSchema(ctx, "pluginConfig")
 }
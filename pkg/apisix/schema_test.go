@@ -19,11 +19,14 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/nettest"
 
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 )
 
@@ -154,3 +157,59 @@ func TestSchemaClient(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, sslSchema.Content, testData["ssl"])
 }
+
+func TestSchemaClientCacheTTL(t *testing.T) {
+	var requests int32
+	schemaSrv := &fakeAPISIXSchemaSrv{
+		schema: map[string]string{
+			"plugins/key-auth": testData["plugins/key-auth"],
+		},
+	}
+	ln, err := nettest.NewLocalListener("tcp")
+	assert.Nil(t, err)
+	srv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			schemaSrv.ServeHTTP(w, r)
+		}),
+	}
+	go func() {
+		_ = srv.Serve(ln)
+	}()
+	defer func() {
+		assert.Nil(t, srv.Shutdown(context.Background()))
+	}()
+
+	u := url.URL{
+		Scheme: "http",
+		Host:   ln.Addr().String(),
+		Path:   "/apisix/admin",
+	}
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	closedCh := make(chan struct{})
+	close(closedCh)
+	cli := newSchemaClient(&cluster{
+		baseURL:          u.String(),
+		cli:              http.DefaultClient,
+		cache:            db,
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+		schemaCacheTTL:   200 * time.Millisecond,
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		schema, err := cli.GetPluginSchema(ctx, "key-auth")
+		assert.Nil(t, err)
+		assert.Equal(t, testData["plugins/key-auth"], schema.Content)
+	}
+	// The schema is fetched once and then served from the cache.
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	time.Sleep(300 * time.Millisecond)
+	_, err = cli.GetPluginSchema(ctx, "key-auth")
+	assert.Nil(t, err)
+	// The expired schema is fetched again.
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
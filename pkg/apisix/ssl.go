@@ -46,7 +46,7 @@ func (s *sslClient) Get(ctx context.Context, name string) (*v1.Ssl, error) {
 		zap.String("url", s.url),
 		zap.String("cluster", "default"),
 	)
-	sid := id.GenID(name)
+	sid := id.GenID(id.SSL, name)
 	ssl, err := s.cluster.cache.GetSSL(sid)
 	if err == nil {
 		return ssl, nil
@@ -220,3 +220,36 @@ func (s *sslClient) Update(ctx context.Context, obj *v1.Ssl) (*v1.Ssl, error) {
 	}
 	return ssl, nil
 }
+
+// VerifySNIs fetches the SSL object from APISIX directly, so the result reflects
+// what APISIX really registered, and returns the SNIs in obj which are absent.
+func (s *sslClient) VerifySNIs(ctx context.Context, obj *v1.Ssl) ([]string, error) {
+	log.Debugw("try to verify ssl snis",
+		zap.String("id", obj.ID),
+		zap.Strings("snis", obj.Snis),
+		zap.String("cluster", "default"),
+		zap.String("url", s.url),
+	)
+	url := s.url + "/" + obj.ID
+	resp, err := s.cluster.getResource(ctx, url, "ssl")
+	s.cluster.metricsCollector.IncrAPISIXRequest("ssl")
+	if err != nil {
+		return nil, err
+	}
+	ssl, err := resp.Item.ssl()
+	if err != nil {
+		return nil, err
+	}
+
+	registered := make(map[string]struct{}, len(ssl.Snis))
+	for _, sni := range ssl.Snis {
+		registered[sni] = struct{}{}
+	}
+	var missing []string
+	for _, sni := range obj.Snis {
+		if _, ok := registered[sni]; !ok {
+			missing = append(missing, sni)
+		}
+	}
+	return missing, nil
+}
@@ -36,6 +36,9 @@ import (
 
 type fakeAPISIXSSLSrv struct {
 	ssl map[string]json.RawMessage
+	// maxSNIs limits the number of SNIs to be stored, it is used to
+	// simulate the partial SNI registration.
+	maxSNIs int
 }
 
 func (srv *fakeAPISIXSSLSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
@@ -46,6 +49,19 @@ func (srv *fakeAPISIXSSLSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/apisix/admin/ssl/") {
+		id := "/apisix/ssl/" + strings.TrimPrefix(r.URL.Path, "/apisix/admin/ssl/")
+		data, ok := srv.ssl[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		output := fmt.Sprintf(`{"action": "get", "node": {"key": "%s", "value": %s}}`, id, string(data))
+		_, _ = w.Write([]byte(output))
+		return
+	}
+
 	if r.Method == http.MethodGet {
 		resp := fakeListResp{
 			Count: strconv.Itoa(len(srv.ssl)),
@@ -85,6 +101,14 @@ func (srv *fakeAPISIXSSLSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		paths := strings.Split(r.URL.Path, "/")
 		key := fmt.Sprintf("/apisix/ssl/%s", paths[len(paths)-1])
 		data, _ := ioutil.ReadAll(r.Body)
+		if srv.maxSNIs > 0 {
+			var ssl v1.Ssl
+			_ = json.Unmarshal(data, &ssl)
+			if len(ssl.Snis) > srv.maxSNIs {
+				ssl.Snis = ssl.Snis[:srv.maxSNIs]
+			}
+			data, _ = json.Marshal(ssl)
+		}
 		srv.ssl[key] = data
 		w.WriteHeader(http.StatusCreated)
 		resp := fakeCreateResp{
@@ -118,8 +142,13 @@ func (srv *fakeAPISIXSSLSrv) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 }
 
 func runFakeSSLSrv(t *testing.T) *http.Server {
+	return runFakeSSLSrvWithMaxSNIs(t, 0)
+}
+
+func runFakeSSLSrvWithMaxSNIs(t *testing.T, maxSNIs int) *http.Server {
 	srv := &fakeAPISIXSSLSrv{
-		ssl: make(map[string]json.RawMessage),
+		ssl:     make(map[string]json.RawMessage),
+		maxSNIs: maxSNIs,
 	}
 
 	ln, _ := nettest.NewLocalListener("tcp")
@@ -200,3 +229,45 @@ func TestSSLClient(t *testing.T) {
 	assert.Equal(t, "2", objs[0].ID)
 	assert.Equal(t, "foo.com", objs[0].Snis[0])
 }
+
+func TestSSLClientVerifySNIs(t *testing.T) {
+	srv := runFakeSSLSrvWithMaxSNIs(t, 1)
+	defer func() {
+		assert.Nil(t, srv.Shutdown(context.Background()))
+	}()
+
+	u := url.URL{
+		Scheme: "http",
+		Host:   srv.Addr,
+		Path:   "/apisix/admin",
+	}
+	closedCh := make(chan struct{})
+	close(closedCh)
+
+	cli := newSSLClient(&cluster{
+		baseURL:          u.String(),
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+	})
+
+	ssl := &v1.Ssl{
+		ID:   "1",
+		Snis: []string{"foo.com", "bar.com", "*.baz.com"},
+	}
+	_, err := cli.Create(context.TODO(), ssl)
+	assert.Nil(t, err)
+
+	missing, err := cli.VerifySNIs(context.TODO(), ssl)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"bar.com", "*.baz.com"}, missing)
+
+	ssl.Snis = []string{"foo.com"}
+	missing, err = cli.VerifySNIs(context.TODO(), ssl)
+	assert.Nil(t, err)
+	assert.Len(t, missing, 0)
+
+	_, err = cli.VerifySNIs(context.TODO(), &v1.Ssl{ID: "2"})
+	assert.NotNil(t, err)
+}
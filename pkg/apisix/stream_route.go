@@ -54,7 +54,7 @@ func (r *streamRouteClient) Get(ctx context.Context, name string) (*v1.StreamRou
 		zap.String("url", r.url),
 		zap.String("cluster", "default"),
 	)
-	rid := id.GenID(name)
+	rid := id.GenID(id.StreamRoute, name)
 	streamRoute, err := r.cluster.cache.GetStreamRoute(rid)
 	if err == nil {
 		return streamRoute, nil
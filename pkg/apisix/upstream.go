@@ -39,31 +39,31 @@ func newUpstreamClient(c *cluster) Upstream {
 	}
 }
 
+// Get finds the upstream by the name, if there are several upstreams with the
+// name (see ListByName), any of them is returned. The upstreams created by the
+// earlier versions are looked up from APISIX by the ID generated from the name.
 func (u *upstreamClient) Get(ctx context.Context, name string) (*v1.Upstream, error) {
 	log.Debugw("try to look up upstream",
 		zap.String("name", name),
 		zap.String("url", u.url),
 		zap.String("cluster", "default"),
 	)
-	uid := id.GenID(name)
-	ups, err := u.cluster.cache.GetUpstream(uid)
-	if err == nil {
-		return ups, nil
-	}
-	if err != cache.ErrNotFound {
+	upstreams, err := u.cluster.cache.ListUpstreamsByName(name)
+	if err != nil {
 		log.Errorw("failed to find upstream in cache, will try to lookup from APISIX",
 			zap.String("name", name),
 			zap.Error(err),
 		)
+	} else if len(upstreams) > 0 {
+		return upstreams[0], nil
 	} else {
 		log.Debugw("failed to find upstream in cache, will try to lookup from APISIX",
 			zap.String("name", name),
-			zap.Error(err),
 		)
 	}
 
 	// TODO Add mutex here to avoid dog-pile effection.
-	url := u.url + "/" + uid
+	url := u.url + "/" + id.GenID(id.Upstream, name)
 	resp, err := u.cluster.getResource(ctx, url, "upstream")
 	if err != nil {
 		if err == cache.ErrNotFound {
@@ -83,7 +83,7 @@ func (u *upstreamClient) Get(ctx context.Context, name string) (*v1.Upstream, er
 		return nil, err
 	}
 
-	ups, err = resp.Item.upstream()
+	ups, err := resp.Item.upstream()
 	if err != nil {
 		log.Errorw("failed to convert upstream item",
 			zap.String("url", u.url),
@@ -100,6 +100,22 @@ func (u *upstreamClient) Get(ctx context.Context, name string) (*v1.Upstream, er
 	return ups, nil
 }
 
+// ListByName lists the upstreams with the name from the cache. The IDs of
+// upstreams are generated from their configs, so the upstreams of the same
+// Service port might be more than one, e.g. the ones translated from the
+// HTTPRoute and the ApisixRoute, or the ones with the config before and
+// after a change.
+func (u *upstreamClient) ListByName(ctx context.Context, name string) ([]*v1.Upstream, error) {
+	log.Debugw("try to list upstreams by name",
+		zap.String("name", name),
+		zap.String("cluster", "default"),
+	)
+	if err := u.cluster.HasSynced(ctx); err != nil {
+		return nil, err
+	}
+	return u.cluster.cache.ListUpstreamsByName(name)
+}
+
 // List is only used in cache warming up. So here just pass through
 // to APISIX.
 func (u *upstreamClient) List(ctx context.Context) ([]*v1.Upstream, error) {
@@ -178,12 +194,49 @@ func (u *upstreamClient) Delete(ctx context.Context, obj *v1.Upstream) error {
 		zap.String("url", u.url),
 	)
 
-	if err := u.cluster.upstreamServiceRelation.Delete(ctx, &v1.UpstreamServiceRelation{UpstreamName: obj.Name}); err != nil {
-		log.Errorf("failed to delete upstreamService in cache: %s", err)
-	}
 	if err := u.cluster.HasSynced(ctx); err != nil {
 		return err
 	}
+	// The upstreams translated for deletion only have the names since the
+	// IDs are generated from the configs, delete the ones with the name.
+	if _, err := u.cluster.cache.GetUpstream(obj.ID); err == cache.ErrNotFound && obj.Name != "" {
+		upstreams, err := u.cluster.cache.ListUpstreamsByName(obj.Name)
+		if err != nil {
+			return err
+		}
+		if len(upstreams) > 0 {
+			return u.deleteAll(ctx, upstreams)
+		}
+	}
+	return u.delete(ctx, obj)
+}
+
+// deleteAll deletes the upstreams, cache.ErrStillInUse is returned if some
+// of them are still in use and the others are deleted.
+func (u *upstreamClient) deleteAll(ctx context.Context, upstreams []*v1.Upstream) error {
+	var inUse bool
+	for _, ups := range upstreams {
+		if err := u.delete(ctx, ups); err != nil {
+			if err != cache.ErrStillInUse {
+				return err
+			}
+			inUse = true
+		}
+	}
+	if inUse {
+		return cache.ErrStillInUse
+	}
+	return nil
+}
+
+func (u *upstreamClient) delete(ctx context.Context, obj *v1.Upstream) error {
+	// Identical upstreams share the ID, so an upstream may still be used by
+	// the routes of other resources, keep it until the last one goes.
+	// APISIX only rejects the deletion if the upstream is bound to a route
+	// directly, not when it's referred by the traffic-split plugin.
+	if err := u.cluster.cache.CheckUpstreamReference(obj); err != nil {
+		return err
+	}
 	url := u.url + "/" + obj.ID
 	if err := u.cluster.deleteResource(ctx, url, "upstream"); err != nil {
 		u.cluster.metricsCollector.IncrAPISIXRequest("upstream")
@@ -196,6 +249,12 @@ func (u *upstreamClient) Delete(ctx context.Context, obj *v1.Upstream) error {
 			return err
 		}
 	}
+	// The relation is kept for the other upstreams with the name.
+	if upstreams, err := u.cluster.cache.ListUpstreamsByName(obj.Name); err == nil && len(upstreams) == 0 {
+		if err := u.cluster.upstreamServiceRelation.Delete(ctx, &v1.UpstreamServiceRelation{UpstreamName: obj.Name}); err != nil {
+			log.Errorf("failed to delete upstreamService in cache: %s", err)
+		}
+	}
 	return nil
 }
 
@@ -29,6 +29,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/nettest"
 
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
@@ -229,3 +231,97 @@ func TestUpstreamClient(t *testing.T) {
 	assert.Equal(t, "2", objs[0].ID)
 	assert.Equal(t, "chash", objs[0].Type)
 }
+
+func TestUpstreamClientSharedUpstream(t *testing.T) {
+	srv := runFakeUpstreamSrv(t)
+	defer func() {
+		assert.Nil(t, srv.Shutdown(context.Background()))
+	}()
+
+	u := url.URL{
+		Scheme: "http",
+		Host:   srv.Addr,
+		Path:   "/apisix/admin",
+	}
+	closedCh := make(chan struct{})
+	close(closedCh)
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	cli := newUpstreamClient(&cluster{
+		baseURL:                 u.String(),
+		cli:                     http.DefaultClient,
+		cache:                   db,
+		cacheSynced:             closedCh,
+		metricsCollector:        metrics.NewPrometheusCollector(),
+		upstreamServiceRelation: &dummyUpstreamServiceRelation{},
+	})
+
+	// Two routes backed by the same Service port translate to the identical
+	// upstreams, which are one APISIX object.
+	name := v1.ComposeUpstreamName("default", "httpbin", "", 80)
+	newUpstream := func(scheme string) *v1.Upstream {
+		ups := v1.NewDefaultUpstream()
+		ups.Name = name
+		ups.Scheme = scheme
+		ups.Nodes = v1.UpstreamNodes{{Host: "10.0.0.1", Port: 80, Weight: 100}}
+		ups.ID = v1.ComposeUpstreamID(ups)
+		return ups
+	}
+	for i := 0; i < 2; i++ {
+		_, err = cli.Create(context.Background(), newUpstream(v1.SchemeHTTP))
+		assert.Nil(t, err)
+	}
+	objs, err := cli.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, objs, 1)
+
+	// The upstream of the same Service port but with a different config is
+	// another object.
+	_, err = cli.Create(context.Background(), newUpstream(v1.SchemeHTTPS))
+	assert.Nil(t, err)
+	objs, err = cli.ListByName(context.Background(), name)
+	assert.Nil(t, err)
+	assert.Len(t, objs, 2)
+	assert.NotEqual(t, objs[0].ID, objs[1].ID)
+
+	// The upstream is kept while a route still splits the traffic to it.
+	referred := newUpstream(v1.SchemeHTTP)
+	assert.Nil(t, db.InsertRoute(&v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "route",
+		},
+		Plugins: v1.Plugins{
+			"traffic-split": &v1.TrafficSplitConfig{
+				Rules: []v1.TrafficSplitConfigRule{
+					{
+						WeightedUpstreams: []v1.TrafficSplitConfigRuleWeightedUpstream{
+							{UpstreamID: referred.ID, Weight: 10},
+						},
+					},
+				},
+			},
+		},
+	}))
+	// The upstreams translated for deletion only have the names, all the
+	// upstreams with the name which are not in use are deleted.
+	notStrictly := &v1.Upstream{
+		Metadata: v1.Metadata{
+			ID:   id.GenID(id.Upstream, name),
+			Name: name,
+		},
+	}
+	assert.Equal(t, cache.ErrStillInUse, cli.Delete(context.Background(), notStrictly))
+	objs, err = cli.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, objs, 1)
+	assert.Equal(t, referred.ID, objs[0].ID)
+
+	r, err := db.GetRoute("1")
+	assert.Nil(t, err)
+	assert.Nil(t, db.DeleteRoute(r))
+	assert.Nil(t, cli.Delete(context.Background(), notStrictly))
+	objs, err = cli.List(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, objs, 0)
+}
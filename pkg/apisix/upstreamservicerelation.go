@@ -74,14 +74,15 @@ func (u *upstreamService) Delete(ctx context.Context, relation *v1.UpstreamServi
 		if err != nil {
 			return err
 		}
-		ups, err := u.cluster.upstream.Get(ctx, usr.UpstreamName)
+		upstreams, err := u.cluster.upstream.ListByName(ctx, usr.UpstreamName)
 		if err != nil {
 			return err
 		}
-		ups.Nodes = make(v1.UpstreamNodes, 0)
-		_, err = u.cluster.upstream.Update(ctx, ups)
-		if err != nil {
-			return err
+		for _, ups := range upstreams {
+			ups.Nodes = make(v1.UpstreamNodes, 0)
+			if _, err := u.cluster.upstream.Update(ctx, ups); err != nil {
+				return err
+			}
 		}
 		err = u.cluster.cache.DeleteUpstreamServiceRelation(usr)
 		if err != nil {
@@ -0,0 +1,85 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"crypto/sha256"
+	"sync"
+)
+
+// writeCache remembers the hash of the payload last written to each
+// resource URL and the response of that write, so that writing an
+// unchanged payload again can be skipped.
+type writeCache struct {
+	sync.Mutex
+	items map[string]writeCacheItem
+}
+
+type writeCacheItem struct {
+	hash [sha256.Size]byte
+	resp createResponse
+}
+
+func newWriteCache() *writeCache {
+	return &writeCache{
+		items: make(map[string]writeCacheItem),
+	}
+}
+
+// get returns the response of the last write to the url if its payload is
+// the same as data.
+func (w *writeCache) get(url string, data []byte) (*createResponse, bool) {
+	if w == nil {
+		return nil, false
+	}
+	w.Lock()
+	defer w.Unlock()
+	item, ok := w.items[url]
+	if !ok || item.hash != sha256.Sum256(data) {
+		return nil, false
+	}
+	resp := item.resp
+	return &resp, true
+}
+
+func (w *writeCache) set(url string, data []byte, resp *createResponse) {
+	if w == nil {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	w.items[url] = writeCacheItem{
+		hash: sha256.Sum256(data),
+		resp: *resp,
+	}
+}
+
+func (w *writeCache) delete(url string) {
+	if w == nil {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	delete(w.items, url)
+}
+
+func (w *writeCache) reset() {
+	if w == nil {
+		return
+	}
+	w.Lock()
+	defer w.Unlock()
+	w.items = make(map[string]writeCacheItem)
+}
@@ -0,0 +1,98 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package apisix
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestWriteCache(t *testing.T) {
+	writes := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			writes++
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"node":{"key":"/apisix/routes/1","value":{"id":"1","name":"test","uri":"/bar"}}}`))
+		case http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message":"Key not found"}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	closedCh := make(chan struct{})
+	close(closedCh)
+	c := &cluster{
+		baseURL:          srv.URL + "/apisix/admin",
+		cli:              http.DefaultClient,
+		cache:            &dummyCache{},
+		cacheSynced:      closedCh,
+		metricsCollector: metrics.NewPrometheusCollector(),
+		writeCache:       newWriteCache(),
+	}
+	cli := newRouteClient(c)
+	route := &v1.Route{
+		Metadata: v1.Metadata{
+			ID:   "1",
+			Name: "test",
+		},
+		Uri: "/bar",
+	}
+	ctx := context.Background()
+
+	_, err := cli.Create(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, writes)
+
+	// Unchanged payloads are not written again.
+	obj, err := cli.Update(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, writes)
+	assert.Equal(t, "/bar", obj.Uri)
+
+	route.Uri = "/baz"
+	_, err = cli.Update(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, writes)
+
+	c.ResetWriteCache()
+	_, err = cli.Update(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, writes)
+
+	// The object is missing in APISIX.
+	_, err = c.getResource(ctx, cli.(*routeClient).url+"/1", "route")
+	assert.Equal(t, cache.ErrNotFound, err)
+	_, err = cli.Update(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 4, writes)
+
+	assert.Nil(t, cli.Delete(ctx, route))
+	_, err = cli.Create(ctx, route)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, writes)
+}
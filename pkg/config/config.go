@@ -21,14 +21,17 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"regexp"
 	"strings"
 	"text/template"
 	"time"
 
 	"gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -63,12 +66,74 @@ const (
 	ApisixV2 = "apisix.apache.org/v2"
 
 	_minimalResyncInterval = 30 * time.Second
+	// _electionJitterFactor is the JitterFactor of the client-go leader
+	// election.
+	_electionJitterFactor = 1.2
+
+	// OrphanGCDisabled disables the garbage collection of orphan APISIX resources.
+	OrphanGCDisabled = "disabled"
+	// OrphanGCDryRun only lists the orphan APISIX resources which would be deleted.
+	OrphanGCDryRun = "dry-run"
+	// OrphanGCEnabled deletes the orphan APISIX resources.
+	OrphanGCEnabled = "enabled"
+
+	// ControllerIngress is the name of the Ingress controller.
+	ControllerIngress = "ingress"
+	// ControllerApisixRoute is the name of the ApisixRoute controller.
+	ControllerApisixRoute = "apisix_route"
+	// ControllerApisixUpstream is the name of the ApisixUpstream controller.
+	ControllerApisixUpstream = "apisix_upstream"
+	// ControllerApisixTls is the name of the ApisixTls controller.
+	ControllerApisixTls = "apisix_tls"
+	// ControllerApisixClusterConfig is the name of the ApisixClusterConfig controller.
+	ControllerApisixClusterConfig = "apisix_cluster_config"
+	// ControllerApisixConsumer is the name of the ApisixConsumer controller.
+	ControllerApisixConsumer = "apisix_consumer"
+	// ControllerApisixPluginConfig is the name of the ApisixPluginConfig controller.
+	ControllerApisixPluginConfig = "apisix_plugin_config"
 
 	// ControllerName is the name of the controller used to identify
 	// the controller of the GatewayClass.
 	ControllerName = "apisix.apache.org/gateway-controller"
 )
 
+// _controllerDependencies are the controllers which must be enabled
+// together with the controller, e.g. the ApisixUpstreams customize the
+// upstreams of ApisixRoutes and Ingresses.
+var _controllerDependencies = map[string][]string{
+	ControllerIngress:             {ControllerApisixUpstream},
+	ControllerApisixRoute:         {ControllerApisixUpstream},
+	ControllerApisixUpstream:      nil,
+	ControllerApisixTls:           nil,
+	ControllerApisixClusterConfig: nil,
+	ControllerApisixConsumer:      nil,
+	ControllerApisixPluginConfig:  nil,
+}
+
+// _discoveryTypes are the service discovery types supported by APISIX.
+var _discoveryTypes = map[string]struct{}{
+	"dns":        {},
+	"consul":     {},
+	"consul_kv":  {},
+	"nacos":      {},
+	"eureka":     {},
+	"kubernetes": {},
+}
+
+// _idPrefixRegex restricts the ID prefix to the characters allowed in both
+// the APISIX object IDs and the consumer usernames.
+var _idPrefixRegex = regexp.MustCompile(`^[a-zA-Z0-9_]{0,32}$`)
+
+// _reloadableConfigItems are the config items which can be changed at
+// runtime by reloading the configuration file, changes of the other
+// items only take effect after restarting.
+var _reloadableConfigItems = map[string]struct{}{
+	"log_level":                     {},
+	"apisix-resource-sync-interval": {},
+	"apisix-resource-sync-jitter":   {},
+	"workers":                       {},
+}
+
 // Config contains all config items which are necessary for
 // apisix-ingress-controller's running.
 type Config struct {
@@ -76,14 +141,88 @@ type Config struct {
 	KeyFilePath                string             `json:"key_file" yaml:"key_file"`
 	LogLevel                   string             `json:"log_level" yaml:"log_level"`
 	LogOutput                  string             `json:"log_output" yaml:"log_output"`
+	LogSampling                LogSamplingConfig  `json:"log_sampling" yaml:"log_sampling"`
 	HTTPListen                 string             `json:"http_listen" yaml:"http_listen"`
 	HTTPSListen                string             `json:"https_listen" yaml:"https_listen"`
 	IngressPublishService      string             `json:"ingress_publish_service" yaml:"ingress_publish_service"`
 	IngressStatusAddress       []string           `json:"ingress_status_address" yaml:"ingress_status_address"`
 	EnableProfiling            bool               `json:"enable_profiling" yaml:"enable_profiling"`
+	ProfilingMutexFraction     int                `json:"profiling_mutex_fraction" yaml:"profiling_mutex_fraction"`
+	ProfilingBlockRate         int                `json:"profiling_block_rate" yaml:"profiling_block_rate"`
 	Kubernetes                 KubernetesConfig   `json:"kubernetes" yaml:"kubernetes"`
 	APISIX                     APISIXConfig       `json:"apisix" yaml:"apisix"`
 	ApisixResourceSyncInterval types.TimeDuration `json:"apisix-resource-sync-interval" yaml:"apisix-resource-sync-interval"`
+	// ApisixResourceSyncJitter is the fraction of ApisixResourceSyncInterval
+	// which is randomly added to each sync interval, so that replicas won't
+	// sync at the same time. It should be in the range [0, 1].
+	ApisixResourceSyncJitter float64 `json:"apisix-resource-sync-jitter" yaml:"apisix-resource-sync-jitter"`
+	// Workers is the number of workers of each resource controller, i.e.
+	// how many objects of a kind can be synced concurrently.
+	Workers int `json:"workers" yaml:"workers"`
+	// ResyncToken authenticates the requests to trigger a full resync
+	// through the "/debug/resync" endpoint and to list the watched
+	// namespaces through "/debug/namespaces", both are disabled if empty.
+	ResyncToken string `json:"resync_token" yaml:"resync_token"`
+	// ResyncTimeout is the maximum duration to wait for a resync triggered
+	// through "/debug/resync" to complete, zero means no limit.
+	ResyncTimeout types.TimeDuration `json:"resync_timeout" yaml:"resync_timeout"`
+	// OrphanGC controls the garbage collection of APISIX resources which
+	// are marked as managed by the controller but not derived from any
+	// Kubernetes resource selecting their APISIX cluster, the value can be
+	// "disabled", "dry-run" or "enabled".
+	OrphanGC string `json:"orphan_gc" yaml:"orphan_gc"`
+	// ReadinessTimeout is the maximum duration to wait for the initial sync
+	// before reporting ready, zero means waiting forever.
+	ReadinessTimeout types.TimeDuration `json:"readiness_timeout" yaml:"readiness_timeout"`
+	// ConsumerRevalidateInterval is the interval to re-validate the
+	// ApisixConsumers against the latest plugin schemas from APISIX,
+	// zero means disabled.
+	ConsumerRevalidateInterval types.TimeDuration `json:"consumer_revalidate_interval" yaml:"consumer_revalidate_interval"`
+	// EndpointBatchWindow is the duration to collect the upstream nodes
+	// changes caused by endpoints before pushing them to APISIX, zero means
+	// pushing immediately.
+	EndpointBatchWindow types.TimeDuration `json:"endpoint_batch_window" yaml:"endpoint_batch_window"`
+	// EndpointBatchMax is the maximum number of upstreams in a batch, a batch
+	// is pushed once it's full even if the window isn't elapsed. Zero means
+	// no limit.
+	EndpointBatchMax int `json:"endpoint_batch_max" yaml:"endpoint_batch_max"`
+	// EndpointDebounceWindow is the quiet duration after the last endpoints
+	// change of a Service before syncing it, the rapid changes in between
+	// are coalesced into one sync. Zero means syncing every change.
+	EndpointDebounceWindow types.TimeDuration `json:"endpoint_debounce_window" yaml:"endpoint_debounce_window"`
+	// EndpointDebounceMaxWait caps how long the changes of a Service can be
+	// delayed by the debounce window, so that a Service which never quiets
+	// down is still synced. Zero means ten times the window.
+	EndpointDebounceMaxWait types.TimeDuration `json:"endpoint_debounce_max_wait" yaml:"endpoint_debounce_max_wait"`
+	// AuditLogOutput is the destination of the audit log, which records
+	// every write to APISIX as a JSON line, it can be "stdout", "stderr"
+	// or a file path. Empty means the audit log is disabled.
+	AuditLogOutput string `json:"audit_log_output" yaml:"audit_log_output"`
+	// ApisixUpstreamDefaults are the defaults filled in ApisixUpstreams by
+	// the mutating admission webhook.
+	ApisixUpstreamDefaults ApisixUpstreamDefaultsConfig `json:"apisix_upstream_defaults" yaml:"apisix_upstream_defaults"`
+}
+
+// LogSamplingConfig contains the sampling thresholds of debug and info
+// logs, the first Initial entries with the same level and message in each
+// second are logged, then every Thereafter-th entry is logged. Warnings and
+// errors are never sampled.
+type LogSamplingConfig struct {
+	// Initial is zero means the sampling is disabled.
+	Initial    int `json:"initial" yaml:"initial"`
+	Thereafter int `json:"thereafter" yaml:"thereafter"`
+}
+
+// ApisixUpstreamDefaultsConfig contains the defaults of the unset fields of
+// ApisixUpstream, empty values mean no default.
+type ApisixUpstreamDefaultsConfig struct {
+	// LoadBalancer is the default load balancer type, "chash" can't be
+	// used since it requires the hash key.
+	LoadBalancer string `json:"loadbalancer" yaml:"loadbalancer"`
+	Scheme       string `json:"scheme" yaml:"scheme"`
+	// HealthCheckInterval is the default interval of the active health
+	// check healthy and unhealthy probes.
+	HealthCheckInterval types.TimeDuration `json:"health_check_interval" yaml:"health_check_interval"`
 }
 
 // KubernetesConfig contains all Kubernetes related config items.
@@ -102,6 +241,71 @@ type KubernetesConfig struct {
 	ApisixTlsVersion           string             `json:"apisix_tls_version" yaml:"apisix_tls_version"`
 	ApisixClusterConfigVersion string             `json:"apisix_cluster_config_version" yaml:"apisix_cluster_config_version"`
 	EnableGatewayAPI           bool               `json:"enable_gateway_api" yaml:"enable_gateway_api"`
+	// AllowCrossNamespacePluginConfig allows ApisixRoutes and Ingresses to
+	// reference ApisixPluginConfigs in other namespaces ("namespace/name").
+	AllowCrossNamespacePluginConfig bool `json:"allow_cross_namespace_plugin_config" yaml:"allow_cross_namespace_plugin_config"`
+	// AllowServerlessFunctions allows ApisixRoutes to run inline Lua
+	// functions through the serverless field or the serverless plugins,
+	// which is disabled by default since the functions are not sandboxed.
+	AllowServerlessFunctions bool `json:"allow_serverless_functions" yaml:"allow_serverless_functions"`
+	// WarnDeprecatedVersions enables warnings (logs and events) when
+	// resources of a deprecated API version (e.g. v2beta3) are reconciled.
+	WarnDeprecatedVersions bool `json:"warn_deprecated_versions" yaml:"warn_deprecated_versions"`
+	// RouteLabelKeys are the keys of the ApisixRoute labels copied to the
+	// labels of the APISIX routes.
+	RouteLabelKeys []string `json:"route_label_keys" yaml:"route_label_keys"`
+	// RouteLabelPrefix copies all the ApisixRoute labels whose keys have
+	// the prefix to the labels of the APISIX routes.
+	RouteLabelPrefix string `json:"route_label_prefix" yaml:"route_label_prefix"`
+	// EnableFinalizers adds a finalizer to the ApisixRoute, ApisixTls,
+	// ApisixConsumer and ApisixPluginConfig objects, so that they are not
+	// removed until the corresponding APISIX resources are deleted.
+	EnableFinalizers bool `json:"enable_finalizers" yaml:"enable_finalizers"`
+	// FinalizerTimeout is the maximum duration to retry the deletion of
+	// APISIX resources for an object being deleted, after that the finalizer
+	// is removed anyway so that the object deletion is not blocked forever.
+	FinalizerTimeout types.TimeDuration `json:"finalizer_timeout" yaml:"finalizer_timeout"`
+	// LeaderResyncRamp is the duration to spread the reconciliation of all
+	// the resources over after the controller becomes the leader, zero
+	// means they are reconciled at once.
+	LeaderResyncRamp types.TimeDuration `json:"leader_resync_ramp" yaml:"leader_resync_ramp"`
+	// ElectionLeaseDuration, ElectionRenewDeadline and ElectionRetryPeriod
+	// are the timings of the leader election, see the LeaderElectionConfig
+	// of client-go for details.
+	ElectionLeaseDuration types.TimeDuration `json:"election_lease_duration" yaml:"election_lease_duration"`
+	ElectionRenewDeadline types.TimeDuration `json:"election_renew_deadline" yaml:"election_renew_deadline"`
+	ElectionRetryPeriod   types.TimeDuration `json:"election_retry_period" yaml:"election_retry_period"`
+	// ElectionDrainTimeout is the maximum duration to wait for the workers
+	// to exit after the in-flight syncs are canceled since the leader role
+	// is lost, the leader states are cleaned up after twice of it anyway.
+	ElectionDrainTimeout types.TimeDuration `json:"election_drain_timeout" yaml:"election_drain_timeout"`
+	// EnabledControllers are the names of the resource controllers to run,
+	// like "apisix_route", the informers of the others are not started and
+	// no event handlers are registered for them. All controllers are
+	// enabled if it's empty.
+	EnabledControllers []string `json:"enabled_controllers" yaml:"enabled_controllers"`
+	// ServiceUpstreamAnnotations are the Service annotations honored to
+	// configure the upstreams of the Service without ApisixUpstream, like
+	// "k8s.apisix.apache.org/upstream-scheme". ApisixUpstream takes
+	// precedence over them.
+	ServiceUpstreamAnnotations []string `json:"service_upstream_annotations" yaml:"service_upstream_annotations"`
+	// ClusterDomain is the DNS domain of the cluster, the backends with
+	// the "dns" resolve granularity are resolved by the Service DNS names
+	// in it.
+	ClusterDomain string `json:"cluster_domain" yaml:"cluster_domain"`
+}
+
+// IsControllerEnabled checks whether the resource controller is enabled.
+func (kc *KubernetesConfig) IsControllerEnabled(name string) bool {
+	if len(kc.EnabledControllers) == 0 {
+		return true
+	}
+	for _, enabled := range kc.EnabledControllers {
+		if enabled == name {
+			return true
+		}
+	}
+	return false
 }
 
 // APISIXConfig contains all APISIX related config items.
@@ -113,6 +317,67 @@ type APISIXConfig struct {
 	// DefaultClusterAdminKey is the admin key for the default cluster.
 	// TODO: Obsolete the plain way to specify admin_key, which is insecure.
 	DefaultClusterAdminKey string `json:"default_cluster_admin_key" yaml:"default_cluster_admin_key"`
+	// VerifySSLSNIs indicates whether to read back the SSL objects after
+	// pushing them to APISIX, so that the partially registered SNIs can
+	// be reported.
+	VerifySSLSNIs bool `json:"verify_ssl_snis" yaml:"verify_ssl_snis"`
+	// MaxRouteCount is the maximum number of routes that can be pushed to
+	// each APISIX cluster, it's enforced per cluster and routes beyond it
+	// will be rejected. Zero means no limit.
+	MaxRouteCount int `json:"max_route_count" yaml:"max_route_count"`
+	// RollbackOnFailure indicates whether to revert the objects already
+	// applied in a sync when a later one fails, so that the dependent
+	// objects are not left half applied.
+	RollbackOnFailure bool `json:"rollback_on_failure" yaml:"rollback_on_failure"`
+	// PluginSchemaCacheTTL is the maximum duration to use a cached plugin
+	// schema (e.g. in the admission webhooks) before fetching it from APISIX
+	// again.
+	PluginSchemaCacheTTL types.TimeDuration `json:"plugin_schema_cache_ttl" yaml:"plugin_schema_cache_ttl"`
+	// AdminAPIHealthCheckTTL is the duration to cache the result of the
+	// Admin API reachability check used by the readiness probe.
+	AdminAPIHealthCheckTTL types.TimeDuration `json:"admin_api_health_check_ttl" yaml:"admin_api_health_check_ttl"`
+	// AdminAPIHealthCheckFailureThreshold is the number of consecutive
+	// failed Admin API checks before reporting not ready.
+	AdminAPIHealthCheckFailureThreshold int `json:"admin_api_health_check_failure_threshold" yaml:"admin_api_health_check_failure_threshold"`
+	// AdminAPIMaxRetries is the maximum number of retries when the Admin
+	// API responds with 429 or 503, zero means no retry.
+	AdminAPIMaxRetries int `json:"admin_api_max_retries" yaml:"admin_api_max_retries"`
+	// AdminAPIMaxRetryBackoff caps the exponential backoff between the
+	// retries, the Retry-After header is respected up to it.
+	AdminAPIMaxRetryBackoff types.TimeDuration `json:"admin_api_max_retry_backoff" yaml:"admin_api_max_retry_backoff"`
+	// AdminAPIBatchConcurrency is the maximum number of in-flight Admin
+	// API writes of a cluster when resources are written in batches.
+	AdminAPIBatchConcurrency int `json:"admin_api_batch_concurrency" yaml:"admin_api_batch_concurrency"`
+	// AdminAPITimeout is the timeout of an Admin API request, including
+	// connecting and reading the response body.
+	AdminAPITimeout types.TimeDuration `json:"admin_api_timeout" yaml:"admin_api_timeout"`
+	// AdminAPIDialTimeout is the timeout to connect to the Admin API.
+	AdminAPIDialTimeout types.TimeDuration `json:"admin_api_dial_timeout" yaml:"admin_api_dial_timeout"`
+	// AdminAPIMaxIdleConnsPerHost is the maximum number of idle connections
+	// kept to the Admin API of a cluster.
+	AdminAPIMaxIdleConnsPerHost int `json:"admin_api_max_idle_conns_per_host" yaml:"admin_api_max_idle_conns_per_host"`
+	// DiscoveryTypes are the service discovery types enabled in APISIX,
+	// like "dns" and "nacos", the ApisixUpstreams can only use them.
+	DiscoveryTypes []string `json:"discovery_types" yaml:"discovery_types"`
+	// IDPrefix is prepended to the IDs (and the consumer usernames) of the
+	// APISIX objects and appended to their managed-by label, so that the
+	// controllers connecting to the same APISIX don't collide with or
+	// collect the objects of each other. Empty means no prefix.
+	IDPrefix string `json:"id_prefix" yaml:"id_prefix"`
+	// Clusters are the APISIX clusters besides the default one, resources
+	// select one of them with the "k8s.apisix.apache.org/apisix-cluster"
+	// annotation, and the ones without it are pushed to the default cluster.
+	Clusters []APISIXClusterConfig `json:"clusters" yaml:"clusters"`
+}
+
+// APISIXClusterConfig contains the config items of an APISIX cluster.
+type APISIXClusterConfig struct {
+	// Name is the name of the cluster, it's referenced by resources.
+	Name string `json:"name" yaml:"name"`
+	// BaseURL is the base url of the Admin API.
+	BaseURL string `json:"base_url" yaml:"base_url"`
+	// AdminKey is the admin key of the cluster.
+	AdminKey string `json:"admin_key" yaml:"admin_key"`
 }
 
 // NewDefaultConfig creates a Config object which fills all config items with
@@ -121,14 +386,25 @@ func NewDefaultConfig() *Config {
 	return &Config{
 		LogLevel:                   "warn",
 		LogOutput:                  "stderr",
+		LogSampling:                LogSamplingConfig{Thereafter: 100},
 		HTTPListen:                 ":8080",
 		HTTPSListen:                ":8443",
 		IngressPublishService:      "",
 		IngressStatusAddress:       []string{},
 		CertFilePath:               "/etc/webhook/certs/cert.pem",
 		KeyFilePath:                "/etc/webhook/certs/key.pem",
-		EnableProfiling:            true,
+		EnableProfiling:            false,
 		ApisixResourceSyncInterval: types.TimeDuration{Duration: 300 * time.Second},
+		ApisixResourceSyncJitter:   0.1,
+		Workers:                    1,
+		ResyncTimeout:              types.TimeDuration{Duration: 5 * time.Minute},
+		OrphanGC:                   OrphanGCDisabled,
+		ReadinessTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+		ApisixUpstreamDefaults: ApisixUpstreamDefaultsConfig{
+			LoadBalancer:        "roundrobin",
+			Scheme:              "http",
+			HealthCheckInterval: types.TimeDuration{Duration: time.Second},
+		},
 		Kubernetes: KubernetesConfig{
 			Kubeconfig:                 "", // Use in-cluster configurations.
 			ResyncInterval:             types.TimeDuration{Duration: 6 * time.Hour},
@@ -143,6 +419,23 @@ func NewDefaultConfig() *Config {
 			ApisixClusterConfigVersion: ApisixV2beta3,
 			WatchEndpointSlices:        false,
 			EnableGatewayAPI:           false,
+			FinalizerTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+			ElectionLeaseDuration:      types.TimeDuration{Duration: 15 * time.Second},
+			ElectionRenewDeadline:      types.TimeDuration{Duration: 5 * time.Second},
+			ElectionRetryPeriod:        types.TimeDuration{Duration: 2 * time.Second},
+			ElectionDrainTimeout:       types.TimeDuration{Duration: 5 * time.Second},
+			ClusterDomain:              "cluster.local",
+		},
+		APISIX: APISIXConfig{
+			PluginSchemaCacheTTL:                types.TimeDuration{Duration: 10 * time.Minute},
+			AdminAPIHealthCheckTTL:              types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIHealthCheckFailureThreshold: 3,
+			AdminAPIMaxRetries:                  3,
+			AdminAPIMaxRetryBackoff:             types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIBatchConcurrency:            8,
+			AdminAPITimeout:                     types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIDialTimeout:                 types.TimeDuration{Duration: 3 * time.Second},
+			AdminAPIMaxIdleConnsPerHost:         8,
 		},
 	}
 }
@@ -191,6 +484,12 @@ func (cfg *Config) Validate() error {
 	if cfg.Kubernetes.ResyncInterval.Duration < _minimalResyncInterval {
 		return errors.New("controller resync interval too small")
 	}
+	if cfg.ProfilingMutexFraction < 0 {
+		return errors.New("profiling mutex fraction should not be negative")
+	}
+	if cfg.ProfilingBlockRate < 0 {
+		return errors.New("profiling block rate should not be negative")
+	}
 	if cfg.APISIX.DefaultClusterName == "" {
 		cfg.APISIX.DefaultClusterName = "default"
 	}
@@ -203,6 +502,123 @@ func (cfg *Config) Validate() error {
 	default:
 		return errors.New("unsupported ingress version")
 	}
+	if cfg.Kubernetes.FinalizerTimeout.Duration <= 0 {
+		return errors.New("finalizer timeout should be positive")
+	}
+	if cfg.Kubernetes.LeaderResyncRamp.Duration < 0 {
+		return errors.New("leader resync ramp should not be negative")
+	}
+	if cfg.Kubernetes.ElectionLeaseDuration.Duration <= 0 {
+		return errors.New("election lease duration should be positive")
+	}
+	if cfg.Kubernetes.ElectionRetryPeriod.Duration <= 0 {
+		return errors.New("election retry period should be positive")
+	}
+	if cfg.Kubernetes.ElectionRenewDeadline.Duration >= cfg.Kubernetes.ElectionLeaseDuration.Duration {
+		return errors.New("election renew deadline should be less than the lease duration")
+	}
+	// The same constraint as client-go, which jitters the retry period.
+	if cfg.Kubernetes.ElectionRenewDeadline.Duration <= time.Duration(_electionJitterFactor*float64(cfg.Kubernetes.ElectionRetryPeriod.Duration)) {
+		return fmt.Errorf("election renew deadline should be greater than %v times the retry period", _electionJitterFactor)
+	}
+	if cfg.Kubernetes.ElectionDrainTimeout.Duration <= 0 {
+		return errors.New("election drain timeout should be positive")
+	}
+	if cfg.Kubernetes.ClusterDomain == "" {
+		return errors.New("cluster domain should not be empty")
+	}
+	if cfg.ApisixResourceSyncJitter < 0 || cfg.ApisixResourceSyncJitter > 1 {
+		return errors.New("apisix resource sync jitter should be in the range [0, 1]")
+	}
+	if cfg.Workers < 1 {
+		return errors.New("workers should be at least 1")
+	}
+	if cfg.LogSampling.Initial < 0 || cfg.LogSampling.Thereafter < 0 {
+		return errors.New("log sampling thresholds should not be negative")
+	}
+	switch cfg.ApisixUpstreamDefaults.LoadBalancer {
+	case "", "roundrobin", "ewma", "least_conn":
+		break
+	default:
+		return errors.New("unsupported default apisix upstream load balancer")
+	}
+	switch cfg.ApisixUpstreamDefaults.Scheme {
+	case "", "http", "https", "grpc", "grpcs":
+		break
+	default:
+		return errors.New("unsupported default apisix upstream scheme")
+	}
+	if d := cfg.ApisixUpstreamDefaults.HealthCheckInterval.Duration; d != 0 && d < time.Second {
+		return errors.New("default apisix upstream health check interval should not be less than 1s")
+	}
+	if cfg.EndpointBatchWindow.Duration < 0 {
+		return errors.New("endpoint batch window should not be negative")
+	}
+	if cfg.EndpointBatchMax < 0 {
+		return errors.New("endpoint batch max should not be negative")
+	}
+	if cfg.EndpointDebounceWindow.Duration < 0 {
+		return errors.New("endpoint debounce window should not be negative")
+	}
+	if cfg.EndpointDebounceMaxWait.Duration < 0 {
+		return errors.New("endpoint debounce max wait should not be negative")
+	}
+	if cfg.APISIX.PluginSchemaCacheTTL.Duration < 0 {
+		return errors.New("plugin schema cache ttl should not be negative")
+	}
+	if cfg.APISIX.AdminAPIMaxRetries < 0 {
+		return errors.New("admin api max retries should not be negative")
+	}
+	if cfg.APISIX.AdminAPIMaxRetryBackoff.Duration < 0 {
+		return errors.New("admin api max retry backoff should not be negative")
+	}
+	if cfg.APISIX.AdminAPIBatchConcurrency <= 0 {
+		return errors.New("admin api batch concurrency should be positive")
+	}
+	if cfg.APISIX.AdminAPITimeout.Duration <= 0 {
+		return errors.New("admin api timeout should be positive")
+	}
+	if cfg.APISIX.AdminAPIDialTimeout.Duration <= 0 {
+		return errors.New("admin api dial timeout should be positive")
+	}
+	if cfg.APISIX.AdminAPIMaxIdleConnsPerHost <= 0 {
+		return errors.New("admin api max idle conns per host should be positive")
+	}
+	for _, typ := range cfg.APISIX.DiscoveryTypes {
+		if _, ok := _discoveryTypes[typ]; !ok {
+			return fmt.Errorf("unsupported discovery type %s", typ)
+		}
+	}
+	if !_idPrefixRegex.MatchString(cfg.APISIX.IDPrefix) {
+		return errors.New("apisix id prefix should be at most 32 letters, digits or underscores")
+	}
+	clusters := map[string]struct{}{cfg.APISIX.DefaultClusterName: {}}
+	for _, cluster := range cfg.APISIX.Clusters {
+		if cluster.Name == "" {
+			return errors.New("apisix cluster name is required")
+		}
+		if _, ok := clusters[cluster.Name]; ok {
+			return fmt.Errorf("duplicated apisix cluster %s", cluster.Name)
+		}
+		if cluster.BaseURL == "" {
+			return fmt.Errorf("apisix base url of cluster %s is required", cluster.Name)
+		}
+		clusters[cluster.Name] = struct{}{}
+	}
+	switch cfg.OrphanGC {
+	case "":
+		cfg.OrphanGC = OrphanGCDisabled
+	case OrphanGCDisabled, OrphanGCDryRun, OrphanGCEnabled:
+		break
+	default:
+		return errors.New("unsupported orphan gc mode")
+	}
+	if err := cfg.verifyEnabledControllers(); err != nil {
+		return err
+	}
+	if err := cfg.verifyServiceUpstreamAnnotations(); err != nil {
+		return err
+	}
 	cfg.Kubernetes.AppNamespaces = purifyAppNamespaces(cfg.Kubernetes.AppNamespaces)
 	ok, err := cfg.verifyNamespaceSelector()
 	if !ok {
@@ -226,47 +642,78 @@ func purifyAppNamespaces(namespaces []string) []string {
 	return ultimate
 }
 
+func (cfg *Config) verifyServiceUpstreamAnnotations() error {
+	for _, key := range cfg.Kubernetes.ServiceUpstreamAnnotations {
+		supported := false
+		for _, anno := range annotations.ServiceUpstreamAnnotations {
+			if key == anno {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return fmt.Errorf("unsupported service upstream annotation %s", key)
+		}
+	}
+	return nil
+}
+
+func (cfg *Config) verifyEnabledControllers() error {
+	for _, name := range cfg.Kubernetes.EnabledControllers {
+		if _, ok := _controllerDependencies[name]; !ok {
+			return fmt.Errorf("unknown controller %s", name)
+		}
+	}
+	for _, name := range cfg.Kubernetes.EnabledControllers {
+		for _, dep := range _controllerDependencies[name] {
+			if !cfg.Kubernetes.IsControllerEnabled(dep) {
+				return fmt.Errorf("controller %s requires controller %s to be enabled", name, dep)
+			}
+		}
+	}
+	return nil
+}
+
 func (cfg *Config) verifyNamespaceSelector() (bool, error) {
-	labels := cfg.Kubernetes.NamespaceSelector
+	selectors := cfg.Kubernetes.NamespaceSelector
 	// default is [""]
-	if len(labels) == 1 && labels[0] == "" {
+	if len(selectors) == 1 && selectors[0] == "" {
 		cfg.Kubernetes.NamespaceSelector = []string{}
 	}
 
+	// Each item is a label selector like "apisix.ingress=watching" or
+	// "env in (prod,staging)", they're ANDed.
+	// ref: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#label-selectors
 	for _, s := range cfg.Kubernetes.NamespaceSelector {
-		parts := strings.Split(s, "=")
-		if len(parts) != 2 {
-			return false, fmt.Errorf("Illegal namespaceSelector: %s, should be key-value pairs divided by = ", s)
-		} else {
-			if err := cfg.validateLabelKey(parts[0]); err != nil {
-				return false, err
-			}
-			if err := cfg.validateLabelValue(parts[1]); err != nil {
-				return false, err
-			}
+		if _, err := labels.Parse(s); err != nil {
+			return false, fmt.Errorf("Illegal namespaceSelector: %s, %s", s, err)
 		}
 	}
 	return true, nil
 }
 
-// validateLabelKey validate the key part of label
-// ref: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#syntax-and-character-set
-func (cfg *Config) validateLabelKey(key string) error {
-	errorMsg := validation.IsQualifiedName(key)
-	msg := strings.Join(errorMsg, "\n")
-	if msg == "" {
-		return nil
-	}
-	return fmt.Errorf("Illegal namespaceSelector: %s, "+msg, key)
+// RestartRequiredChanges returns the config items which are changed in
+// newCfg but cannot be reloaded at runtime, items are named by their
+// json keys, like "kubernetes.resync_interval".
+func (cfg *Config) RestartRequiredChanges(newCfg *Config) []string {
+	return diffConfigItems("", reflect.ValueOf(*cfg), reflect.ValueOf(*newCfg))
 }
 
-// validateLabelValue validate the value part of label
-// ref: https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/#syntax-and-character-set
-func (cfg *Config) validateLabelValue(value string) error {
-	errorMsg := validation.IsValidLabelValue(value)
-	msg := strings.Join(errorMsg, "\n")
-	if msg == "" {
-		return nil
+func diffConfigItems(prefix string, prev, curr reflect.Value) []string {
+	var changes []string
+	for i := 0; i < prev.NumField(); i++ {
+		field := prev.Type().Field(i)
+		name := prefix + strings.Split(field.Tag.Get("json"), ",")[0]
+		if _, ok := _reloadableConfigItems[name]; ok {
+			continue
+		}
+		if field.Type == reflect.TypeOf(KubernetesConfig{}) || field.Type == reflect.TypeOf(APISIXConfig{}) {
+			changes = append(changes, diffConfigItems(name+".", prev.Field(i), curr.Field(i))...)
+			continue
+		}
+		if !reflect.DeepEqual(prev.Field(i).Interface(), curr.Field(i).Interface()) {
+			changes = append(changes, name)
+		}
 	}
-	return fmt.Errorf("Illegal namespaceSelector: %s, "+msg, value)
+	return changes
 }
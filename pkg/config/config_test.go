@@ -18,11 +18,13 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
@@ -30,6 +32,7 @@ func TestNewConfigFromFile(t *testing.T) {
 	cfg := &Config{
 		LogLevel:                   "warn",
 		LogOutput:                  "stdout",
+		LogSampling:                LogSamplingConfig{Thereafter: 100},
 		HTTPListen:                 ":9090",
 		HTTPSListen:                ":9443",
 		IngressPublishService:      "",
@@ -38,6 +41,16 @@ func TestNewConfigFromFile(t *testing.T) {
 		KeyFilePath:                "/etc/webhook/certs/key.pem",
 		EnableProfiling:            true,
 		ApisixResourceSyncInterval: types.TimeDuration{Duration: 200 * time.Second},
+		ApisixResourceSyncJitter:   0.1,
+		Workers:                    1,
+		OrphanGC:                   OrphanGCDisabled,
+		ResyncTimeout:              types.TimeDuration{Duration: 5 * time.Minute},
+		ReadinessTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+		ApisixUpstreamDefaults: ApisixUpstreamDefaultsConfig{
+			LoadBalancer:        "roundrobin",
+			Scheme:              "http",
+			HealthCheckInterval: types.TimeDuration{Duration: time.Second},
+		},
 		Kubernetes: KubernetesConfig{
 			ResyncInterval:             types.TimeDuration{Duration: time.Hour},
 			Kubeconfig:                 "/path/to/foo/baz",
@@ -50,11 +63,26 @@ func TestNewConfigFromFile(t *testing.T) {
 			ApisixConsumerVersion:      ApisixV2beta3,
 			ApisixTlsVersion:           ApisixV2beta3,
 			ApisixClusterConfigVersion: ApisixV2beta3,
+			FinalizerTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+			ElectionLeaseDuration:      types.TimeDuration{Duration: 15 * time.Second},
+			ElectionRenewDeadline:      types.TimeDuration{Duration: 5 * time.Second},
+			ElectionRetryPeriod:        types.TimeDuration{Duration: 2 * time.Second},
+			ElectionDrainTimeout:       types.TimeDuration{Duration: 5 * time.Second},
+			ClusterDomain:              "cluster.local",
 		},
 		APISIX: APISIXConfig{
-			DefaultClusterName:     "default",
-			DefaultClusterBaseURL:  "http://127.0.0.1:8080/apisix",
-			DefaultClusterAdminKey: "123456",
+			DefaultClusterName:                  "default",
+			DefaultClusterBaseURL:               "http://127.0.0.1:8080/apisix",
+			DefaultClusterAdminKey:              "123456",
+			PluginSchemaCacheTTL:                types.TimeDuration{Duration: 10 * time.Minute},
+			AdminAPIHealthCheckTTL:              types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIHealthCheckFailureThreshold: 3,
+			AdminAPIMaxRetries:                  3,
+			AdminAPIMaxRetryBackoff:             types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIBatchConcurrency:            8,
+			AdminAPITimeout:                     types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIDialTimeout:                 types.TimeDuration{Duration: 3 * time.Second},
+			AdminAPIMaxIdleConnsPerHost:         8,
 		},
 	}
 
@@ -88,6 +116,7 @@ ingress_publish_service: ""
 ingress_status_address: []
 enable_profiling: true
 apisix-resource-sync-interval: 200s
+apisix-resource-sync-jitter: 0.1
 kubernetes:
   kubeconfig: /path/to/foo/baz
   resync_interval: 1h0m0s
@@ -117,6 +146,7 @@ func TestConfigWithEnvVar(t *testing.T) {
 	cfg := &Config{
 		LogLevel:                   "warn",
 		LogOutput:                  "stdout",
+		LogSampling:                LogSamplingConfig{Thereafter: 100},
 		HTTPListen:                 ":9090",
 		HTTPSListen:                ":9443",
 		IngressPublishService:      "",
@@ -125,6 +155,16 @@ func TestConfigWithEnvVar(t *testing.T) {
 		KeyFilePath:                "/etc/webhook/certs/key.pem",
 		EnableProfiling:            true,
 		ApisixResourceSyncInterval: types.TimeDuration{Duration: 200 * time.Second},
+		ApisixResourceSyncJitter:   0.1,
+		Workers:                    1,
+		OrphanGC:                   OrphanGCDisabled,
+		ResyncTimeout:              types.TimeDuration{Duration: 5 * time.Minute},
+		ReadinessTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+		ApisixUpstreamDefaults: ApisixUpstreamDefaultsConfig{
+			LoadBalancer:        "roundrobin",
+			Scheme:              "http",
+			HealthCheckInterval: types.TimeDuration{Duration: time.Second},
+		},
 		Kubernetes: KubernetesConfig{
 			ResyncInterval:             types.TimeDuration{Duration: time.Hour},
 			Kubeconfig:                 "",
@@ -137,11 +177,26 @@ func TestConfigWithEnvVar(t *testing.T) {
 			ApisixConsumerVersion:      ApisixV2beta3,
 			ApisixTlsVersion:           ApisixV2beta3,
 			ApisixClusterConfigVersion: ApisixV2beta3,
+			FinalizerTimeout:           types.TimeDuration{Duration: 5 * time.Minute},
+			ElectionLeaseDuration:      types.TimeDuration{Duration: 15 * time.Second},
+			ElectionRenewDeadline:      types.TimeDuration{Duration: 5 * time.Second},
+			ElectionRetryPeriod:        types.TimeDuration{Duration: 2 * time.Second},
+			ElectionDrainTimeout:       types.TimeDuration{Duration: 5 * time.Second},
+			ClusterDomain:              "cluster.local",
 		},
 		APISIX: APISIXConfig{
-			DefaultClusterName:     "default",
-			DefaultClusterBaseURL:  "http://127.0.0.1:8080/apisix",
-			DefaultClusterAdminKey: "123456",
+			DefaultClusterName:                  "default",
+			DefaultClusterBaseURL:               "http://127.0.0.1:8080/apisix",
+			DefaultClusterAdminKey:              "123456",
+			PluginSchemaCacheTTL:                types.TimeDuration{Duration: 10 * time.Minute},
+			AdminAPIHealthCheckTTL:              types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIHealthCheckFailureThreshold: 3,
+			AdminAPIMaxRetries:                  3,
+			AdminAPIMaxRetryBackoff:             types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIBatchConcurrency:            8,
+			AdminAPITimeout:                     types.TimeDuration{Duration: 5 * time.Second},
+			AdminAPIDialTimeout:                 types.TimeDuration{Duration: 3 * time.Second},
+			AdminAPIMaxIdleConnsPerHost:         8,
 		},
 	}
 
@@ -200,6 +255,7 @@ ingress_publish_service: ""
 ingress_status_address: []
 enable_profiling: true
 apisix-resource-sync-interval: 200s
+apisix-resource-sync-jitter: 0.1
 kubernetes:
   resync_interval: 1h0m0s
   kubeconfig: "{{.KUBECONFIG}}"
@@ -292,4 +348,187 @@ apisix:
 	err = newCfg.Validate()
 	assert.NotNil(t, err)
 	assert.Equal(t, err.Error(), "controller resync interval too small", "bad error: ", err)
+
+	cfg := NewDefaultConfig()
+	cfg.APISIX.DefaultClusterBaseURL = "http://127.0.0.1:1234/apisix"
+	assert.False(t, cfg.EnableProfiling)
+	cfg.ProfilingMutexFraction = -1
+	assert.Equal(t, "profiling mutex fraction should not be negative", cfg.Validate().Error())
+	cfg.ProfilingMutexFraction = 0
+	cfg.ProfilingBlockRate = -1
+	assert.Equal(t, "profiling block rate should not be negative", cfg.Validate().Error())
+	cfg.ProfilingBlockRate = 0
+
+	cfg.Kubernetes.NamespaceSelector = []string{"apisix.ingress=watching", "env in (prod,staging)", "!legacy"}
+	assert.Nil(t, cfg.Validate())
+	cfg.Kubernetes.NamespaceSelector = []string{"env in (prod"}
+	assert.Contains(t, cfg.Validate().Error(), "Illegal namespaceSelector: env in (prod")
+	cfg.Kubernetes.NamespaceSelector = nil
+
+	cfg.Kubernetes.LeaderResyncRamp = types.TimeDuration{Duration: -time.Second}
+	assert.Equal(t, "leader resync ramp should not be negative", cfg.Validate().Error())
+	cfg.Kubernetes.LeaderResyncRamp = types.TimeDuration{Duration: time.Minute}
+
+	cfg.EndpointDebounceWindow = types.TimeDuration{Duration: -time.Second}
+	assert.Equal(t, "endpoint debounce window should not be negative", cfg.Validate().Error())
+	cfg.EndpointDebounceWindow = types.TimeDuration{Duration: time.Second}
+
+	cfg.EndpointDebounceMaxWait = types.TimeDuration{Duration: -time.Second}
+	assert.Equal(t, "endpoint debounce max wait should not be negative", cfg.Validate().Error())
+	cfg.EndpointDebounceMaxWait = types.TimeDuration{Duration: 10 * time.Second}
+
+	cfg.Kubernetes.ElectionLeaseDuration = types.TimeDuration{}
+	assert.Equal(t, "election lease duration should be positive", cfg.Validate().Error())
+	cfg.Kubernetes.ElectionLeaseDuration = types.TimeDuration{Duration: 5 * time.Second}
+	assert.Equal(t, "election renew deadline should be less than the lease duration", cfg.Validate().Error())
+	cfg.Kubernetes.ElectionLeaseDuration = types.TimeDuration{Duration: 30 * time.Second}
+	cfg.Kubernetes.ElectionRetryPeriod = types.TimeDuration{}
+	assert.Equal(t, "election retry period should be positive", cfg.Validate().Error())
+	cfg.Kubernetes.ElectionRetryPeriod = types.TimeDuration{Duration: 5 * time.Second}
+	assert.Equal(t, "election renew deadline should be greater than 1.2 times the retry period", cfg.Validate().Error())
+	cfg.Kubernetes.ElectionRenewDeadline = types.TimeDuration{Duration: 10 * time.Second}
+	assert.Nil(t, cfg.Validate())
+	cfg.Kubernetes.ElectionDrainTimeout = types.TimeDuration{}
+	assert.Equal(t, "election drain timeout should be positive", cfg.Validate().Error())
+	cfg.Kubernetes.ElectionDrainTimeout = types.TimeDuration{Duration: time.Second}
+
+	cfg.Kubernetes.ClusterDomain = ""
+	assert.Equal(t, "cluster domain should not be empty", cfg.Validate().Error())
+	cfg.Kubernetes.ClusterDomain = "cluster.local"
+
+	cfg.APISIX.AdminAPIBatchConcurrency = 0
+	assert.Equal(t, "admin api batch concurrency should be positive", cfg.Validate().Error())
+	cfg.APISIX.AdminAPIBatchConcurrency = 1
+
+	cfg.APISIX.AdminAPITimeout = types.TimeDuration{}
+	assert.Equal(t, "admin api timeout should be positive", cfg.Validate().Error())
+	cfg.APISIX.AdminAPITimeout = types.TimeDuration{Duration: time.Second}
+
+	cfg.APISIX.AdminAPIDialTimeout = types.TimeDuration{Duration: -time.Second}
+	assert.Equal(t, "admin api dial timeout should be positive", cfg.Validate().Error())
+	cfg.APISIX.AdminAPIDialTimeout = types.TimeDuration{Duration: time.Second}
+
+	cfg.APISIX.AdminAPIMaxIdleConnsPerHost = 0
+	assert.Equal(t, "admin api max idle conns per host should be positive", cfg.Validate().Error())
+	cfg.APISIX.AdminAPIMaxIdleConnsPerHost = 2
+
+	cfg.APISIX.DiscoveryTypes = []string{"dns", "zookeeper"}
+	assert.Equal(t, "unsupported discovery type zookeeper", cfg.Validate().Error())
+	cfg.APISIX.DiscoveryTypes = []string{"dns", "nacos"}
+
+	cfg.APISIX.IDPrefix = "staging-1"
+	assert.Equal(t, "apisix id prefix should be at most 32 letters, digits or underscores", cfg.Validate().Error())
+	cfg.APISIX.IDPrefix = strings.Repeat("a", 33)
+	assert.Equal(t, "apisix id prefix should be at most 32 letters, digits or underscores", cfg.Validate().Error())
+	cfg.APISIX.IDPrefix = "staging_1"
+
+	cfg.Kubernetes.ServiceUpstreamAnnotations = []string{annotations.AnnotationsUpstreamScheme, "k8s.apisix.apache.org/upstream-port"}
+	assert.Equal(t, "unsupported service upstream annotation k8s.apisix.apache.org/upstream-port", cfg.Validate().Error())
+	cfg.Kubernetes.ServiceUpstreamAnnotations = []string{annotations.AnnotationsUpstreamScheme}
+	assert.Nil(t, cfg.Validate())
+
+	cfg.Kubernetes.EnabledControllers = []string{ControllerApisixRoute, "apisix_gateway"}
+	assert.Equal(t, "unknown controller apisix_gateway", cfg.Validate().Error())
+	cfg.Kubernetes.EnabledControllers = []string{ControllerApisixRoute}
+	assert.Equal(t, "controller apisix_route requires controller apisix_upstream to be enabled", cfg.Validate().Error())
+	cfg.Kubernetes.EnabledControllers = []string{ControllerApisixRoute, ControllerApisixUpstream}
+	assert.Nil(t, cfg.Validate())
+	assert.True(t, cfg.Kubernetes.IsControllerEnabled(ControllerApisixRoute))
+	assert.False(t, cfg.Kubernetes.IsControllerEnabled(ControllerApisixConsumer))
+	cfg.Kubernetes.EnabledControllers = nil
+	assert.True(t, cfg.Kubernetes.IsControllerEnabled(ControllerApisixConsumer))
+}
+
+func TestConfigClusters(t *testing.T) {
+	cfg := NewDefaultConfig()
+	cfg.APISIX.DefaultClusterBaseURL = "http://127.0.0.1:9180/apisix/admin"
+	cfg.APISIX.Clusters = []APISIXClusterConfig{
+		{Name: "staging", BaseURL: "http://staging:9180/apisix/admin"},
+	}
+	assert.Nil(t, cfg.Validate())
+
+	cfg.APISIX.Clusters = []APISIXClusterConfig{
+		{Name: "default", BaseURL: "http://staging:9180/apisix/admin"},
+	}
+	assert.Equal(t, "duplicated apisix cluster default", cfg.Validate().Error())
+
+	cfg.APISIX.Clusters = []APISIXClusterConfig{
+		{Name: "staging"},
+	}
+	assert.Equal(t, "apisix base url of cluster staging is required", cfg.Validate().Error())
+
+	cfg.APISIX.Clusters = []APISIXClusterConfig{
+		{BaseURL: "http://staging:9180/apisix/admin"},
+	}
+	assert.Equal(t, "apisix cluster name is required", cfg.Validate().Error())
+}
+
+func TestConfigRestartRequiredChanges(t *testing.T) {
+	cfg := NewDefaultConfig()
+	newCfg := NewDefaultConfig()
+	assert.Len(t, cfg.RestartRequiredChanges(newCfg), 0)
+
+	newCfg.LogLevel = "debug"
+	newCfg.ApisixResourceSyncInterval = types.TimeDuration{Duration: time.Hour}
+	newCfg.ApisixResourceSyncJitter = 0.5
+	newCfg.Workers = 4
+	assert.Len(t, cfg.RestartRequiredChanges(newCfg), 0)
+
+	newCfg.HTTPListen = ":9091"
+	newCfg.Kubernetes.ResyncInterval = types.TimeDuration{Duration: time.Hour}
+	newCfg.APISIX.Clusters = []APISIXClusterConfig{
+		{Name: "staging", BaseURL: "http://staging:9180/apisix/admin"},
+	}
+	assert.Equal(t, []string{"http_listen", "kubernetes.resync_interval", "apisix.clusters"}, cfg.RestartRequiredChanges(newCfg))
+}
+
+func TestEnvName(t *testing.T) {
+	assert.Equal(t, "APISIX_INGRESS_LOG_LEVEL", EnvName("log_level"))
+	assert.Equal(t, "APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL", EnvName("kubernetes", "resync_interval"))
+	assert.Equal(t, "APISIX_INGRESS_APISIX_RESOURCE_SYNC_INTERVAL", EnvName("apisix-resource-sync-interval"))
+}
+
+func TestConfigApplyEnv(t *testing.T) {
+	cfg := NewDefaultConfig()
+	err := cfg.ApplyEnv([]string{
+		"APISIX_INGRESS_LOG_LEVEL=debug",
+		"APISIX_INGRESS_ENABLE_PROFILING=false",
+		"APISIX_INGRESS_LOG_SAMPLING_INITIAL=10",
+		"APISIX_INGRESS_APISIX_RESOURCE_SYNC_JITTER=0.5",
+		"APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL=12h",
+		"APISIX_INGRESS_KUBERNETES_APP_NAMESPACES=default, apisix",
+		"APISIX_INGRESS_KUBERNETES_NAMESPACE_SELECTOR=[\"a=b,c\"]",
+		"APISIX_INGRESS_APISIX_DEFAULT_CLUSTER_BASE_URL=http://apisix:9180/apisix/admin",
+		"APISIX_INGRESS_UNKNOWN=foo",
+		"LOG_LEVEL=error",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.False(t, cfg.EnableProfiling)
+	assert.Equal(t, 10, cfg.LogSampling.Initial)
+	assert.Equal(t, 0.5, cfg.ApisixResourceSyncJitter)
+	assert.Equal(t, 12*time.Hour, cfg.Kubernetes.ResyncInterval.Duration)
+	assert.Equal(t, []string{"default", "apisix"}, cfg.Kubernetes.AppNamespaces)
+	assert.Equal(t, []string{"a=b,c"}, cfg.Kubernetes.NamespaceSelector)
+	assert.Equal(t, "http://apisix:9180/apisix/admin", cfg.APISIX.DefaultClusterBaseURL)
+	// Items without environment variables are untouched.
+	assert.Equal(t, NewDefaultConfig().HTTPListen, cfg.HTTPListen)
+
+	cfg = NewDefaultConfig()
+	err = cfg.ApplyEnv([]string{
+		"APISIX_INGRESS_APISIX_CLUSTERS=[{name: staging, base_url: \"http://staging:9180/apisix/admin\"}]",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []APISIXClusterConfig{
+		{Name: "staging", BaseURL: "http://staging:9180/apisix/admin"},
+	}, cfg.APISIX.Clusters)
+
+	cfg = NewDefaultConfig()
+	err = cfg.ApplyEnv([]string{"APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL=soon"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid environment variable APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL")
+
+	err = cfg.ApplyEnv([]string{"APISIX_INGRESS_ENDPOINT_BATCH_MAX=many"})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "invalid environment variable APISIX_INGRESS_ENDPOINT_BATCH_MAX")
 }
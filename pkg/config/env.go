@@ -0,0 +1,117 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// EnvPrefix is the prefix of the environment variables which override the
+// configuration items.
+const EnvPrefix = "APISIX_INGRESS_"
+
+var _timeDurationType = reflect.TypeOf(types.TimeDuration{})
+
+// EnvName returns the name of the environment variable which overrides the
+// configuration item of the JSON keys, e.g. the one of ["kubernetes",
+// "resync_interval"] is APISIX_INGRESS_KUBERNETES_RESYNC_INTERVAL.
+func EnvName(keys ...string) string {
+	name := strings.ToUpper(strings.Join(keys, "_"))
+	return EnvPrefix + strings.ReplaceAll(name, "-", "_")
+}
+
+// ApplyEnv overrides the configuration items by the environment variables
+// named by EnvName, environ is in the form of os.Environ(). The string lists
+// are comma separated unless they're in the YAML flow style like "[a, b]",
+// and the other non-string values are parsed as YAML.
+func (cfg *Config) ApplyEnv(environ []string) error {
+	env := make(map[string]string)
+	for _, e := range environ {
+		pair := strings.SplitN(e, "=", 2)
+		if len(pair) == 2 && strings.HasPrefix(pair[0], EnvPrefix) {
+			env[pair[0]] = pair[1]
+		}
+	}
+	if len(env) == 0 {
+		return nil
+	}
+	return applyEnv(reflect.ValueOf(cfg).Elem(), nil, env)
+}
+
+// ApplyOSEnv overrides the configuration items by the environment variables
+// of the process.
+func (cfg *Config) ApplyOSEnv() error {
+	return cfg.ApplyEnv(os.Environ())
+}
+
+func applyEnv(v reflect.Value, keys []string, env map[string]string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		path := append(append([]string{}, keys...), key)
+		if field.Type.Kind() == reflect.Struct && field.Type != _timeDurationType {
+			if err := applyEnv(v.Field(i), path, env); err != nil {
+				return err
+			}
+			continue
+		}
+		name := EnvName(path...)
+		value, ok := env[name]
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(v.Field(i), value); err != nil {
+			return fmt.Errorf("invalid environment variable %s: %s", name, err)
+		}
+	}
+	return nil
+}
+
+func setEnvValue(v reflect.Value, value string) error {
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+		return nil
+	case v.Type() == reflect.TypeOf([]string(nil)) && !strings.HasPrefix(strings.TrimSpace(value), "["):
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+		return nil
+	default:
+		// Decode into a zero value so that the lists and structs are
+		// replaced rather than merged.
+		ptr := reflect.New(v.Type())
+		if err := yaml.UnmarshalStrict([]byte(value), ptr.Interface()); err != nil {
+			return err
+		}
+		v.Set(ptr.Elem())
+		return nil
+	}
+}
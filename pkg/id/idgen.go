@@ -18,22 +18,63 @@ package id
 import (
 	"fmt"
 	"hash/crc32"
-	"reflect"
-	"unsafe"
+	"hash/fnv"
 )
 
-// GenID generates an ID according to the raw material.
-func GenID(raw string) string {
+// _prefix is the prefix of the generated IDs, so that the controllers
+// connecting to the same APISIX don't collide with each other.
+var _prefix string
+
+// SetPrefix sets the prefix of the IDs generated by GenID, it should be
+// called once before any ID is generated.
+func SetPrefix(prefix string) {
+	_prefix = prefix
+}
+
+// Prefix returns the prefix of the IDs generated by GenID.
+func Prefix() string {
+	return _prefix
+}
+
+// Kind is the kind of the APISIX object an ID is generated for.
+type Kind string
+
+// The kinds of the APISIX objects with generated IDs.
+const (
+	Route        Kind = "route"
+	StreamRoute  Kind = "stream_route"
+	Upstream     Kind = "upstream"
+	SSL          Kind = "ssl"
+	PluginConfig Kind = "plugin_config"
+	GlobalRule   Kind = "global_rule"
+)
+
+// GenID generates an ID of the object kind according to the raw material.
+// The raw material, e.g. the composed name of a route, should contain the
+// namespace of the Kubernetes resource so that the IDs are unique across
+// namespaces, and the kind is hashed too so that the objects of different
+// kinds translated from the same name (e.g. the route and the plugin config
+// of an Ingress rule) don't share the ID. A 64-bit hash is used to make
+// collisions unlikely even with lots of objects. The ID is prefixed with the
+// prefix set by SetPrefix, if any.
+func GenID(kind Kind, raw string) string {
 	if raw == "" {
 		return ""
 	}
-	sh := &reflect.SliceHeader{
-		Data: (*reflect.StringHeader)(unsafe.Pointer(&raw)).Data,
-		Len:  len(raw),
-		Cap:  len(raw),
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(string(kind) + ":" + raw))
+	if _prefix != "" {
+		return fmt.Sprintf("%s_%016x", _prefix, h.Sum64())
 	}
-	p := *(*[]byte)(unsafe.Pointer(sh))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
 
-	res := crc32.ChecksumIEEE(p)
-	return fmt.Sprintf("%x", res)
+// GenLegacyID generates an ID in the CRC32 form used by the earlier versions,
+// which collides much more easily. It's only used to compose short names and
+// to find the APISIX objects created by the earlier versions.
+func GenLegacyID(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	return fmt.Sprintf("%x", crc32.ChecksumIEEE([]byte(raw)))
 }
@@ -22,9 +22,33 @@ import (
 )
 
 func TestGenID(t *testing.T) {
-	hash := GenID("")
+	hash := GenID(Route, "")
 	assert.Len(t, hash, 0)
 
-	assert.Equal(t, GenID("111"), GenID("111"))
-	assert.NotEqual(t, GenID("112"), GenID("111"))
+	assert.Equal(t, GenID(Route, "111"), GenID(Route, "111"))
+	assert.NotEqual(t, GenID(Route, "112"), GenID(Route, "111"))
+	// The objects of different kinds with the same name don't collide.
+	assert.NotEqual(t, GenID(Route, "111"), GenID(PluginConfig, "111"))
+}
+
+func TestGenLegacyID(t *testing.T) {
+	assert.Len(t, GenLegacyID(""), 0)
+	// The legacy IDs should be kept as is, or the objects created by the
+	// earlier versions can't be found.
+	assert.Equal(t, "46eb0e41", GenLegacyID("default_httpbin-route_rule1"))
+	assert.NotEqual(t, GenLegacyID("default_httpbin-route_rule1"), GenID(Route, "default_httpbin-route_rule1"))
+	assert.Len(t, GenID(Route, "default_httpbin-route_rule1"), 16)
+}
+
+func TestGenIDWithPrefix(t *testing.T) {
+	raw := "default_httpbin-route_rule1"
+	plain := GenID(Route, raw)
+
+	SetPrefix("staging")
+	defer SetPrefix("")
+	assert.Equal(t, "staging", Prefix())
+	assert.Equal(t, "staging_"+plain, GenID(Route, raw))
+	assert.Len(t, GenID(Route, ""), 0)
+	// Legacy IDs are never prefixed.
+	assert.Equal(t, "46eb0e41", GenLegacyID(raw))
 }
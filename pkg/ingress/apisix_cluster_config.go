@@ -17,6 +17,7 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"go.uber.org/zap"
@@ -27,23 +28,27 @@ import (
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 type apisixClusterConfigController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newApisixClusterConfigController() *apisixClusterConfigController {
 	ctl := &apisixClusterConfigController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Second, 60*time.Second, 5), "ApisixClusterConfig"),
-		workers:    1,
 	}
 	c.apisixClusterConfigInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -64,19 +69,24 @@ func (c *apisixClusterConfigController) run(ctx context.Context) {
 		log.Error("cache sync failed")
 		return
 	}
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
-	<-ctx.Done()
+	c.controller.initialSync.expect("ApisixClusterConfig", c.controller.apisixClusterConfigInformer.GetIndexer().ListKeys())
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixClusterConfigController) runWorker(ctx context.Context) {
+func (c *apisixClusterConfigController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixClusterConfig")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixClusterConfig", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixClusterConfig", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
@@ -132,20 +142,17 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 	switch event.GroupVersion {
 	case config.ApisixV2beta3:
 		acc := multiVersioned.V2beta3()
-		// Currently we don't handle multiple cluster, so only process
-		// the default apisix cluster.
-		if acc.Name != c.controller.cfg.APISIX.DefaultClusterName {
-			log.Infow("ignore non-default apisix cluster config",
-				zap.String("default_cluster_name", c.controller.cfg.APISIX.DefaultClusterName),
+		// Only process the apisix clusters configured in the controller.
+		if !c.controller.hasCluster(acc.Name) {
+			log.Infow("ignore ApisixClusterConfig of unknown apisix cluster",
+				zap.Strings("clusters", c.controller.clusterNames()),
 				zap.Any("ApisixClusterConfig", acc),
 			)
 			return nil
 		}
-		// Cluster delete is dangerous.
-		// TODO handle delete?
+		// Cluster delete is dangerous, only the features on it are reset.
 		if ev.Type == types.EventDelete {
-			log.Error("ApisixClusterConfig delete event for default apisix cluster will be ignored")
-			return nil
+			return c.deleteGlobalRule(ctx, acc.Name)
 		}
 
 		if acc.Spec.Admin != nil {
@@ -186,13 +193,7 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			zap.Any("object", globalRule),
 		)
 
-		// TODO multiple cluster support
-		if ev.Type == types.EventAdd {
-			_, err = c.controller.apisix.Cluster(acc.Name).GlobalRule().Create(ctx, globalRule)
-		} else {
-			_, err = c.controller.apisix.Cluster(acc.Name).GlobalRule().Update(ctx, globalRule)
-		}
-		if err != nil {
+		if err := c.syncGlobalRule(ctx, acc.Name, ev.Type, globalRule); err != nil {
 			log.Errorw("failed to reflect global_rule changes to apisix cluster",
 				zap.Any("global_rule", globalRule),
 				zap.Any("cluster", acc.Name),
@@ -206,19 +207,25 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 		return nil
 	case config.ApisixV2:
 		acc := multiVersioned.V2()
-		// Currently we don't handle multiple cluster, so only process
-		// the default apisix cluster.
-		if acc.Name != c.controller.cfg.APISIX.DefaultClusterName {
-			log.Infow("ignore non-default apisix cluster config",
-				zap.String("default_cluster_name", c.controller.cfg.APISIX.DefaultClusterName),
+		// Only process the apisix clusters configured in the controller.
+		if !c.controller.hasCluster(acc.Name) {
+			log.Infow("ignore ApisixClusterConfig of unknown apisix cluster",
+				zap.Strings("clusters", c.controller.clusterNames()),
 				zap.Any("ApisixClusterConfig", acc),
 			)
 			return nil
 		}
-		// Cluster delete is dangerous.
-		// TODO handle delete?
+		// Cluster delete is dangerous, only the features on it are reset.
 		if ev.Type == types.EventDelete {
-			log.Error("ApisixClusterConfig delete event for default apisix cluster will be ignored")
+			if err := c.deleteGlobalRule(ctx, acc.Name); err != nil {
+				return err
+			}
+			peers := c.pluginMetadataPeers(acc)
+			if err := c.deletePluginMetadata(ctx, acc.Name, unownedPluginMetadata(translation.RemovedPluginMetadata(acc, nil), peers)); err != nil {
+				return err
+			}
+			// The peers might take over the plugin_metadata it owned.
+			c.resyncPeers(peers)
 			return nil
 		}
 
@@ -245,6 +252,28 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			}
 		}
 
+		pluginMetadata, err := c.controller.translator.TranslatePluginMetadataV2(acc)
+		if err != nil {
+			log.Errorw("failed to translate plugin_metadata of ApisixClusterConfig",
+				zap.Error(err),
+				zap.String("key", key),
+				zap.Any("object", acc),
+			)
+			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse, acc.GetGeneration())
+			return err
+		}
+		peers := c.pluginMetadataPeers(acc)
+		if err := checkPluginMetadataOwner(acc, peers); err != nil {
+			log.Errorw("plugin_metadata of ApisixClusterConfig conflicts with others",
+				zap.Error(err),
+				zap.String("key", key),
+			)
+			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse, acc.GetGeneration())
+			return err
+		}
+
 		globalRule, err := c.controller.translator.TranslateClusterConfigV2(acc)
 		if err != nil {
 			log.Errorw("failed to translate ApisixClusterConfig",
@@ -256,17 +285,14 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse, acc.GetGeneration())
 			return err
 		}
+		if acc.Spec.Monitoring != nil && acc.Spec.Monitoring.Tracing != nil {
+			translation.ExcludeRoutesFromTracing(globalRule, c.controller.tracingDisabledRouteIDs(acc.Name))
+		}
 		log.Debugw("translated global_rule",
 			zap.Any("object", globalRule),
 		)
 
-		// TODO multiple cluster support
-		if ev.Type == types.EventAdd {
-			_, err = c.controller.apisix.Cluster(acc.Name).GlobalRule().Create(ctx, globalRule)
-		} else {
-			_, err = c.controller.apisix.Cluster(acc.Name).GlobalRule().Update(ctx, globalRule)
-		}
-		if err != nil {
+		if err := c.syncGlobalRule(ctx, acc.Name, ev.Type, globalRule); err != nil {
 			log.Errorw("failed to reflect global_rule changes to apisix cluster",
 				zap.Any("global_rule", globalRule),
 				zap.Any("cluster", acc.Name),
@@ -275,6 +301,27 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse, acc.GetGeneration())
 			return err
 		}
+
+		var removed []*apisixv1.PluginMetadata
+		if event.OldObject != nil && event.OldObject.GroupVersion() == config.ApisixV2 {
+			removed = translation.RemovedPluginMetadata(event.OldObject.V2(), acc)
+		} else if acc.Spec.PluginMetadata != nil {
+			// Without the previous object (e.g. after a restart or a leader
+			// change), the removals are found from APISIX.
+			removed, err = c.livePluginMetadataRemovals(ctx, acc)
+		}
+		if err == nil {
+			err = c.syncPluginMetadata(ctx, acc.Name, pluginMetadata, unownedPluginMetadata(removed, peers))
+		}
+		if err != nil {
+			log.Errorw("failed to reflect plugin_metadata changes to apisix cluster",
+				zap.Error(err),
+				zap.Any("cluster", acc.Name),
+			)
+			c.controller.recorderEvent(acc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+			c.controller.recordStatus(acc, _resourceSyncAborted, err, metav1.ConditionFalse, acc.GetGeneration())
+			return err
+		}
 		c.controller.recorderEvent(acc, corev1.EventTypeNormal, _resourceSynced, nil)
 		c.controller.recordStatus(acc, _resourceSynced, nil, metav1.ConditionTrue, acc.GetGeneration())
 		return nil
@@ -283,6 +330,244 @@ func (c *apisixClusterConfigController) sync(ctx context.Context, ev *types.Even
 	}
 }
 
+// syncGlobalRule reflects the translated global rule to the APISIX cluster.
+// A global rule without any plugins, e.g. after all the monitoring toggles
+// are removed, is deleted rather than left behind.
+func (c *apisixClusterConfigController) syncGlobalRule(ctx context.Context, cluster string, evType types.EventType, gr *apisixv1.GlobalRule) error {
+	if len(gr.Plugins) == 0 {
+		return c.deleteGlobalRule(ctx, cluster)
+	}
+	var err error
+	if evType == types.EventAdd {
+		_, err = c.controller.apisix.Cluster(cluster).GlobalRule().Create(ctx, gr)
+	} else {
+		_, err = c.controller.apisix.Cluster(cluster).GlobalRule().Update(ctx, gr)
+	}
+	return err
+}
+
+// deleteGlobalRule deletes the global rule of the APISIX cluster, it's fine
+// if the global rule doesn't exist.
+func (c *apisixClusterConfigController) deleteGlobalRule(ctx context.Context, cluster string) error {
+	gr := &apisixv1.GlobalRule{ID: id.GenID(id.GlobalRule, cluster)}
+	if err := c.controller.apisix.Cluster(cluster).GlobalRule().Delete(ctx, gr); err != nil {
+		log.Errorw("failed to delete global_rule",
+			zap.String("cluster", cluster),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// syncPluginMetadata reflects the plugin_metadata to the APISIX cluster,
+// the removed ones are deleted after the others are updated.
+func (c *apisixClusterConfigController) syncPluginMetadata(ctx context.Context, cluster string, pms, removed []*apisixv1.PluginMetadata) error {
+	for _, pm := range pms {
+		if _, err := c.controller.apisix.Cluster(cluster).PluginMetadata().Update(ctx, pm); err != nil {
+			return err
+		}
+	}
+	return c.deletePluginMetadata(ctx, cluster, removed)
+}
+
+// livePluginMetadataRemovals returns the plugin_metadata in APISIX which are
+// not declared by the ApisixClusterConfig.
+func (c *apisixClusterConfigController) livePluginMetadataRemovals(ctx context.Context, acc *configv2.ApisixClusterConfig) ([]*apisixv1.PluginMetadata, error) {
+	plugins, err := c.controller.apisix.Cluster(acc.Name).Plugin().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(plugins)
+
+	var removed []*apisixv1.PluginMetadata
+	for _, name := range plugins {
+		if _, ok := acc.Spec.PluginMetadata[name]; ok {
+			continue
+		}
+		if _, err := c.controller.apisix.Cluster(acc.Name).PluginMetadata().Get(ctx, name); err != nil {
+			if err == apisixcache.ErrNotFound {
+				continue
+			}
+			return nil, err
+		}
+		removed = append(removed, &apisixv1.PluginMetadata{Name: name})
+	}
+	return removed, nil
+}
+
+// pluginMetadataPeers returns the other ApisixClusterConfigs which apply to
+// the same APISIX, i.e. the clusters sharing the Admin API.
+func (c *apisixClusterConfigController) pluginMetadataPeers(acc *configv2.ApisixClusterConfig) []*configv2.ApisixClusterConfig {
+	baseURL := c.adminBaseURL(acc)
+	var peers []*configv2.ApisixClusterConfig
+	for _, obj := range c.controller.apisixClusterConfigInformer.GetIndexer().List() {
+		peer, ok := obj.(*configv2.ApisixClusterConfig)
+		if !ok || peer.Name == acc.Name || !c.controller.hasCluster(peer.Name) {
+			continue
+		}
+		if c.adminBaseURL(peer) == baseURL {
+			peers = append(peers, peer)
+		}
+	}
+	return peers
+}
+
+// adminBaseURL returns the Admin API the ApisixClusterConfig applies to.
+func (c *apisixClusterConfigController) adminBaseURL(acc *configv2.ApisixClusterConfig) string {
+	if acc.Spec.Admin != nil && acc.Spec.Admin.BaseURL != "" {
+		return acc.Spec.Admin.BaseURL
+	}
+	return c.controller.clusterBaseURL(acc.Name)
+}
+
+// resyncPeers re-syncs the ApisixClusterConfigs.
+func (c *apisixClusterConfigController) resyncPeers(peers []*configv2.ApisixClusterConfig) {
+	for _, peer := range peers {
+		c.workqueue.Add(&types.Event{
+			Type: types.EventUpdate,
+			Object: kube.ApisixClusterConfigEvent{
+				Key:          peer.Name,
+				GroupVersion: config.ApisixV2,
+			},
+		})
+	}
+}
+
+// checkPluginMetadataOwner rejects the plugin_metadata which are declared by
+// an older peer, otherwise they would overwrite each other.
+func checkPluginMetadataOwner(acc *configv2.ApisixClusterConfig, peers []*configv2.ApisixClusterConfig) error {
+	names := make([]string, 0, len(acc.Spec.PluginMetadata))
+	for name := range acc.Spec.PluginMetadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, peer := range peers {
+		if !olderClusterConfig(peer, acc) {
+			continue
+		}
+		for _, name := range names {
+			if _, ok := peer.Spec.PluginMetadata[name]; ok {
+				return fmt.Errorf("plugin_metadata %s is already managed by ApisixClusterConfig %s", name, peer.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// olderClusterConfig reports whether a was created before b, the name breaks
+// the tie.
+func olderClusterConfig(a, b *configv2.ApisixClusterConfig) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// unownedPluginMetadata filters out the plugin_metadata declared by the peers,
+// so that they are not deleted on behalf of another ApisixClusterConfig.
+func unownedPluginMetadata(pms []*apisixv1.PluginMetadata, peers []*configv2.ApisixClusterConfig) []*apisixv1.PluginMetadata {
+	var unowned []*apisixv1.PluginMetadata
+	for _, pm := range pms {
+		owned := false
+		for _, peer := range peers {
+			if _, ok := peer.Spec.PluginMetadata[pm.Name]; ok {
+				owned = true
+				break
+			}
+		}
+		if !owned {
+			unowned = append(unowned, pm)
+		}
+	}
+	return unowned
+}
+
+// deletePluginMetadata deletes the plugin_metadata from the APISIX cluster,
+// it's fine if some of them don't exist.
+func (c *apisixClusterConfigController) deletePluginMetadata(ctx context.Context, cluster string, pms []*apisixv1.PluginMetadata) error {
+	for _, pm := range pms {
+		if err := c.controller.apisix.Cluster(cluster).PluginMetadata().Delete(ctx, pm); err != nil {
+			log.Errorw("failed to delete plugin_metadata",
+				zap.String("cluster", cluster),
+				zap.String("plugin", pm.Name),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// resyncAll re-syncs all the ApisixClusterConfigs, e.g. when the routes
+// excluded from tracing change.
+func (c *apisixClusterConfigController) resyncAll() {
+	for _, obj := range c.controller.apisixClusterConfigInformer.GetIndexer().List() {
+		acc, err := kube.NewApisixClusterConfig(obj)
+		if err != nil {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			continue
+		}
+		c.workqueue.Add(&types.Event{
+			Type: types.EventUpdate,
+			Object: kube.ApisixClusterConfigEvent{
+				Key:          key,
+				GroupVersion: acc.GroupVersion(),
+			},
+		})
+	}
+}
+
+// tracingDisabledRouteIDs returns the IDs of the routes pushed to the
+// cluster whose ApisixRoutes have the disable-tracing annotation.
+func (c *Controller) tracingDisabledRouteIDs(cluster string) []string {
+	var ids []string
+	for _, obj := range c.apisixRouteInformer.GetIndexer().List() {
+		ar, err := kube.NewApisixRoute(obj)
+		if err != nil || !isTracingDisabled(ar) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.isWatchingNamespace(key) {
+			continue
+		}
+		if name, err := c.resourceCluster(apisixRouteMeta(ar)); err != nil || name != cluster {
+			continue
+		}
+		var tctx *translation.TranslateContext
+		switch ar.GroupVersion() {
+		case kube.ApisixRouteV2beta2:
+			tctx, err = c.translator.TranslateRouteV2beta2NotStrictly(ar.V2beta2())
+		case kube.ApisixRouteV2beta3:
+			tctx, err = c.translator.TranslateRouteV2beta3NotStrictly(ar.V2beta3())
+		default:
+			tctx, err = c.translator.TranslateRouteV2NotStrictly(ar.V2())
+		}
+		if err != nil {
+			log.Warnw("failed to translate ApisixRoute excluded from tracing",
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			continue
+		}
+		for _, r := range tctx.Routes {
+			ids = append(ids, r.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// isTracingDisabled reports whether the ApisixRoute has the disable-tracing
+// annotation.
+func isTracingDisabled(ar kube.ApisixRoute) bool {
+	return apisixRouteMeta(ar).GetAnnotations()[annotations.AnnotationsDisableTracing] == "true"
+}
+
 func (c *apisixClusterConfigController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
@@ -323,7 +608,7 @@ func (c *apisixClusterConfigController) onAdd(obj interface{}) {
 		zap.Any("object", obj),
 	)
 
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixClusterConfigEvent{
 			Key:          key,
@@ -404,7 +689,8 @@ func (c *apisixClusterConfigController) onDelete(obj interface{}) {
 	c.controller.MetricsCollector.IncrEvents("clusterConfig", "delete")
 }
 
-func (c *apisixClusterConfigController) ResourceSync() {
+func (c *apisixClusterConfigController) ResourceSync() []string {
+	var keys []string
 	objs := c.controller.apisixClusterConfigInformer.GetIndexer().List()
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
@@ -418,8 +704,9 @@ func (c *apisixClusterConfigController) ResourceSync() {
 		acc, err := kube.NewApisixClusterConfig(obj)
 		if err != nil {
 			log.Errorw("found ApisixClusterConfig resource with bad type", zap.String("error", err.Error()))
-			return
+			return keys
 		}
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.ApisixClusterConfigEvent{
@@ -428,4 +715,5 @@ func (c *apisixClusterConfigController) ResourceSync() {
 			},
 		})
 	}
+	return keys
 }
@@ -0,0 +1,64 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestPluginMetadataOwnership(t *testing.T) {
+	now := time.Now()
+	newACC := func(name string, created time.Time, plugins ...string) *configv2.ApisixClusterConfig {
+		acc := &configv2.ApisixClusterConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(created),
+			},
+			Spec: configv2.ApisixClusterConfigSpec{
+				PluginMetadata: map[string]configv2.Config{},
+			},
+		}
+		for _, plugin := range plugins {
+			acc.Spec.PluginMetadata[plugin] = configv2.Config{}
+		}
+		return acc
+	}
+	older := newACC("default", now, "http-logger")
+	newer := newACC("another", now.Add(time.Minute), "http-logger", "error-page")
+
+	// The older one keeps the plugin_metadata.
+	assert.Nil(t, checkPluginMetadataOwner(older, []*configv2.ApisixClusterConfig{newer}))
+	assert.EqualError(t, checkPluginMetadataOwner(newer, []*configv2.ApisixClusterConfig{older}),
+		"plugin_metadata http-logger is already managed by ApisixClusterConfig default")
+
+	// The name breaks the tie.
+	newer.CreationTimestamp = older.CreationTimestamp
+	assert.Nil(t, checkPluginMetadataOwner(newer, []*configv2.ApisixClusterConfig{older}))
+	assert.Error(t, checkPluginMetadataOwner(older, []*configv2.ApisixClusterConfig{newer}))
+
+	// The plugin_metadata declared by the peers are not deleted.
+	removed := unownedPluginMetadata([]*apisixv1.PluginMetadata{
+		{Name: "http-logger"},
+		{Name: "syslog"},
+	}, []*configv2.ApisixClusterConfig{newer})
+	assert.Equal(t, []*apisixv1.PluginMetadata{{Name: "syslog"}}, removed)
+}
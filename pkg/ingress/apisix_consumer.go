@@ -30,19 +30,21 @@ import (
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 type apisixConsumerController struct {
-	controller *Controller
-	workqueue  workqueue.RateLimitingInterface
-	workers    int
+	controller   *Controller
+	workqueue    workqueue.RateLimitingInterface
+	revalidation *consumerRevalidation
 }
 
 func (c *Controller) newApisixConsumerController() *apisixConsumerController {
 	ctl := &apisixConsumerController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixConsumer"),
-		workers:    1,
+
+		revalidation: newConsumerRevalidation(),
 	}
 	ctl.controller.apisixConsumerInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -61,26 +63,33 @@ func (c *apisixConsumerController) run(ctx context.Context) {
 		log.Error("cache sync failed")
 		return
 	}
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
+	c.controller.initialSync.expect("ApisixConsumer", c.controller.watchingKeys(c.controller.apisixConsumerInformer))
+	if interval := c.controller.cfg.ConsumerRevalidateInterval.Duration; interval > 0 {
+		go c.revalidateLoop(ctx, interval)
 	}
-	<-ctx.Done()
-	c.workqueue.ShutDown()
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixConsumerController) runWorker(ctx context.Context) {
+func (c *apisixConsumerController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixConsumer")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixConsumer", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixConsumer", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
 }
 
-func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) error {
+func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) (err error) {
 	event := ev.Object.(kube.ApisixConsumerEvent)
 	key := event.Key
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
@@ -88,6 +97,9 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 		log.Errorf("found ApisixConsumer resource with invalid meta namespace key %s: %s", key, err)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, key) {
+		return nil
+	}
 
 	var multiVersioned kube.ApisixConsumer
 	switch event.GroupVersion {
@@ -117,15 +129,38 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 			return nil
 		}
 	}
+	if ev.Type != types.EventDelete && multiVersioned != nil && isFinalizing(apisixConsumerMeta(multiVersioned)) {
+		// The resyncs find the objects being deleted whose events were
+		// dropped, e.g. while the namespace is paused.
+		ev = &types.Event{Type: types.EventDelete, Object: ev.Object, Tombstone: multiVersioned}
+	}
 	if ev.Type == types.EventDelete {
-		if multiVersioned != nil {
+		if multiVersioned == nil {
+			multiVersioned = ev.Tombstone.(kube.ApisixConsumer)
+		} else if !isFinalizing(apisixConsumerMeta(multiVersioned)) {
 			// We still find the resource while we are processing the DELETE event,
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
 			log.Warnf("discard the stale ApisixConsumer delete event since the %s exists", key)
 			return nil
 		}
-		multiVersioned = ev.Tombstone.(kube.ApisixConsumer)
+		defer func() {
+			err = c.controller.finalize(ctx, apisixConsumerMeta(multiVersioned), err)
+		}()
+	} else if err = c.controller.ensureFinalizer(ctx, apisixConsumerMeta(multiVersioned)); err != nil {
+		return err
+	}
+	cluster, err := c.controller.resourceCluster(apisixConsumerMeta(multiVersioned))
+	if err != nil {
+		if ev.Type == types.EventDelete {
+			// Nothing was pushed since the cluster is unknown.
+			return nil
+		}
+		log.Errorw("failed to find the apisix cluster of ApisixConsumer",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		return err
 	}
 
 	switch event.GroupVersion {
@@ -147,7 +182,7 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 			zap.Any("ApisixConsumer", ac),
 		)
 
-		if err := c.controller.syncConsumer(ctx, consumer, ev.Type); err != nil {
+		if err := c.syncConsumer(ctx, ev, cluster, consumer); err != nil {
 			log.Errorw("failed to sync Consumer to APISIX",
 				zap.Error(err),
 				zap.Any("consumer", consumer),
@@ -176,7 +211,7 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 			zap.Any("ApisixConsumer", ac),
 		)
 
-		if err := c.controller.syncConsumer(ctx, consumer, ev.Type); err != nil {
+		if err := c.syncConsumer(ctx, ev, cluster, consumer); err != nil {
 			log.Errorw("failed to sync Consumer to APISIX",
 				zap.Error(err),
 				zap.Any("consumer", consumer),
@@ -191,6 +226,21 @@ func (c *apisixConsumerController) sync(ctx context.Context, ev *types.Event) er
 	return nil
 }
 
+// syncConsumer pushes the consumer to the APISIX cluster of the
+// ApisixConsumer, it's moved from the old cluster if the cluster of the
+// updated ApisixConsumer changes.
+func (c *apisixConsumerController) syncConsumer(ctx context.Context, ev *types.Event, cluster string, consumer *apisixv1.Consumer) error {
+	event := ev.Object.(kube.ApisixConsumerEvent)
+	if ev.Type != types.EventUpdate || event.OldObject == nil {
+		return c.controller.syncConsumer(ctx, cluster, consumer, ev.Type)
+	}
+	oldCluster, err := c.controller.resourceCluster(apisixConsumerMeta(event.OldObject))
+	if err != nil {
+		oldCluster = ""
+	}
+	return c.controller.syncUpdatedConsumer(ctx, oldCluster, cluster, consumer)
+}
+
 func (c *apisixConsumerController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
@@ -232,7 +282,7 @@ func (c *apisixConsumerController) onAdd(obj interface{}) {
 		zap.Any("object", obj),
 	)
 
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixConsumerEvent{
 			Key:          key,
@@ -269,6 +319,18 @@ func (c *apisixConsumerController) onUpdate(oldObj, newObj interface{}) {
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
 	)
+	if isFinalizing(apisixConsumerMeta(curr)) {
+		c.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.ApisixConsumerEvent{
+				Key:          key,
+				GroupVersion: curr.GroupVersion(),
+			},
+			Tombstone: curr,
+		})
+		c.controller.MetricsCollector.IncrEvents("consumer", "delete")
+		return
+	}
 
 	c.workqueue.Add(&types.Event{
 		Type: types.EventUpdate,
@@ -301,26 +363,31 @@ func (c *apisixConsumerController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixConsumer resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.isWatchingNamespace(key) {
-		return
-	}
-	log.Debugw("ApisixConsumer delete event arrived",
-		zap.Any("final state", ac),
-	)
-	c.workqueue.Add(&types.Event{
+	ev := &types.Event{
 		Type: types.EventDelete,
 		Object: kube.ApisixConsumerEvent{
 			Key:          key,
 			GroupVersion: ac.GroupVersion(),
 		},
 		Tombstone: ac,
-	})
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("ApisixConsumer delete event arrived",
+		zap.Any("final state", ac),
+	)
+	c.workqueue.Add(ev)
 
 	c.controller.MetricsCollector.IncrEvents("consumer", "delete")
 }
 
-func (c *apisixConsumerController) ResourceSync() {
-	objs := c.controller.apisixConsumerInformer.GetIndexer().List()
+func (c *apisixConsumerController) ResourceSync(namespace string) []string {
+	var keys []string
+	objs := c.controller.resourceSyncObjects(c.controller.apisixConsumerInformer, namespace)
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -333,8 +400,9 @@ func (c *apisixConsumerController) ResourceSync() {
 		ac, err := kube.NewApisixConsumer(obj)
 		if err != nil {
 			log.Errorw("found ApisixConsumer resource with bad type", zap.String("error", err.Error()))
-			return
+			return keys
 		}
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.ApisixConsumerEvent{
@@ -343,4 +411,5 @@ func (c *apisixConsumerController) ResourceSync() {
 			},
 		})
 	}
+	return keys
 }
@@ -37,14 +37,12 @@ import (
 type apisixPluginConfigController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newApisixPluginConfigController() *apisixPluginConfigController {
 	ctl := &apisixPluginConfigController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixPluginConfig"),
-		workers:    1,
 	}
 	c.apisixPluginConfigInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -67,31 +65,39 @@ func (c *apisixPluginConfigController) run(ctx context.Context) {
 		return
 	}
 
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
-	<-ctx.Done()
+	c.controller.initialSync.expect("ApisixPluginConfig", c.controller.watchingKeys(c.controller.apisixPluginConfigInformer))
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixPluginConfigController) runWorker(ctx context.Context) {
+func (c *apisixPluginConfigController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixPluginConfig")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixPluginConfig", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixPluginConfig", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
 }
 
-func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event) error {
+func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event) (err error) {
 	obj := ev.Object.(kube.ApisixPluginConfigEvent)
 	namespace, name, err := cache.SplitMetaNamespaceKey(obj.Key)
 	if err != nil {
 		log.Errorf("invalid resource key: %s", obj.Key)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, obj.Key) {
+		return nil
+	}
 	var (
 		apc  kube.ApisixPluginConfig
 		tctx *translation.TranslateContext
@@ -122,8 +128,15 @@ func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event
 			return nil
 		}
 	}
+	if ev.Type != types.EventDelete && apc != nil && isFinalizing(apisixPluginConfigMeta(apc)) {
+		// The resyncs find the objects being deleted whose events were
+		// dropped, e.g. while the namespace is paused.
+		ev = &types.Event{Type: types.EventDelete, Object: ev.Object, Tombstone: apc}
+	}
 	if ev.Type == types.EventDelete {
-		if apc != nil {
+		if apc == nil {
+			apc = ev.Tombstone.(kube.ApisixPluginConfig)
+		} else if !isFinalizing(apisixPluginConfigMeta(apc)) {
 			// We still find the resource while we are processing the DELETE event,
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
@@ -132,7 +145,23 @@ func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event
 			)
 			return nil
 		}
-		apc = ev.Tombstone.(kube.ApisixPluginConfig)
+		defer func() {
+			err = c.controller.finalize(ctx, apisixPluginConfigMeta(apc), err)
+		}()
+	} else if err = c.controller.ensureFinalizer(ctx, apisixPluginConfigMeta(apc)); err != nil {
+		return err
+	}
+	cluster, err := c.controller.resourceCluster(apisixPluginConfigMeta(apc))
+	if err != nil {
+		if ev.Type == types.EventDelete {
+			// Nothing was pushed since the cluster is unknown.
+			return nil
+		}
+		log.Errorw("failed to find the apisix cluster of ApisixPluginConfig",
+			zap.String("key", obj.Key),
+			zap.Error(err),
+		)
+		return err
 	}
 
 	switch obj.GroupVersion {
@@ -176,12 +205,15 @@ func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event
 		added   *utils.Manifest
 		updated *utils.Manifest
 		deleted *utils.Manifest
+		// om and oldCluster are set for the update events.
+		om         *utils.Manifest
+		oldCluster string
 	)
 
 	if ev.Type == types.EventDelete {
 		deleted = m
 	} else if ev.Type == types.EventAdd {
-		added = m
+		added = c.controller.filterResyncManifest(ev, cluster, m)
 	} else {
 		var oldCtx *translation.TranslateContext
 		switch obj.GroupVersion {
@@ -200,13 +232,28 @@ func (c *apisixPluginConfigController) sync(ctx context.Context, ev *types.Event
 			return err
 		}
 
-		om := &utils.Manifest{
+		om = &utils.Manifest{
 			PluginConfigs: oldCtx.PluginConfigs,
 		}
-		added, updated, deleted = m.Diff(om)
+		if oldCluster, err = c.controller.resourceCluster(apisixPluginConfigMeta(obj.OldObject)); err != nil {
+			oldCluster = ""
+		}
 	}
 
-	return c.controller.syncManifests(ctx, added, updated, deleted)
+	if om != nil {
+		err = c.controller.syncUpdatedManifests(ctx, oldCluster, cluster, om, m)
+	} else {
+		err = c.controller.syncManifestsToCluster(ctx, cluster, added, updated, deleted)
+	}
+	if err != nil {
+		return err
+	}
+	// Ingresses which reference this ApisixPluginConfig should be
+	// re-synced, so that they can be aware of the change.
+	if c.controller.ingressController != nil {
+		c.controller.ingressController.syncByPluginConfig(namespace, name)
+	}
+	return nil
 }
 
 func (c *apisixPluginConfigController) handleSyncErr(obj interface{}, errOrigin error) {
@@ -295,7 +342,7 @@ func (c *apisixPluginConfigController) onAdd(obj interface{}) {
 		zap.Any("object", obj))
 
 	apc := kube.MustNewApisixPluginConfig(obj)
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixPluginConfigEvent{
 			Key:          key,
@@ -324,6 +371,18 @@ func (c *apisixPluginConfigController) onUpdate(oldObj, newObj interface{}) {
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
 	)
+	if isFinalizing(apisixPluginConfigMeta(curr)) {
+		c.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.ApisixPluginConfigEvent{
+				Key:          key,
+				GroupVersion: curr.GroupVersion(),
+			},
+			Tombstone: curr,
+		})
+		c.controller.MetricsCollector.IncrEvents("PluginConfig", "delete")
+		return
+	}
 	c.workqueue.Add(&types.Event{
 		Type: types.EventUpdate,
 		Object: kube.ApisixPluginConfigEvent{
@@ -350,26 +409,31 @@ func (c *apisixPluginConfigController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixPluginConfig resource with bad meta namesapce key: %s", err)
 		return
 	}
-	if !c.controller.isWatchingNamespace(key) {
-		return
-	}
-	log.Debugw("ApisixPluginConfig delete event arrived",
-		zap.Any("final state", apc),
-	)
-	c.workqueue.Add(&types.Event{
+	ev := &types.Event{
 		Type: types.EventDelete,
 		Object: kube.ApisixPluginConfigEvent{
 			Key:          key,
 			GroupVersion: apc.GroupVersion(),
 		},
 		Tombstone: apc,
-	})
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("ApisixPluginConfig delete event arrived",
+		zap.Any("final state", apc),
+	)
+	c.workqueue.Add(ev)
 
 	c.controller.MetricsCollector.IncrEvents("PluginConfig", "delete")
 }
 
-func (c *apisixPluginConfigController) ResourceSync() {
-	objs := c.controller.apisixPluginConfigInformer.GetIndexer().List()
+func (c *apisixPluginConfigController) ResourceSync(snapshots utils.Snapshots, namespace string) []string {
+	var keys []string
+	objs := c.controller.resourceSyncObjects(c.controller.apisixPluginConfigInformer, namespace)
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -380,12 +444,15 @@ func (c *apisixPluginConfigController) ResourceSync() {
 			continue
 		}
 		apc := kube.MustNewApisixPluginConfig(obj)
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.ApisixPluginConfigEvent{
 				Key:          key,
 				GroupVersion: apc.GroupVersion(),
 			},
+			Snapshot: snapshots,
 		})
 	}
+	return keys
 }
@@ -16,12 +16,15 @@ package ingress
 
 import (
 	"context"
+	"errors"
+	"reflect"
 	"time"
 
 	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
@@ -39,14 +42,12 @@ import (
 type apisixRouteController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newApisixRouteController() *apisixRouteController {
 	ctl := &apisixRouteController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixRoute"),
-		workers:    1,
 	}
 	c.apisixRouteInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -55,6 +56,43 @@ func (c *Controller) newApisixRouteController() *apisixRouteController {
 			DeleteFunc: ctl.onDelete,
 		},
 	)
+	c.configMapInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    ctl.onConfigMapChange,
+			UpdateFunc: ctl.onConfigMapUpdate,
+			DeleteFunc: ctl.onConfigMapChange,
+		},
+	)
+	c.apisixUpstreamInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    ctl.onApisixUpstreamChange,
+			UpdateFunc: ctl.onApisixUpstreamUpdate,
+			DeleteFunc: ctl.onApisixUpstreamChange,
+		},
+	)
+	c.apisixPluginConfigInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			AddFunc:    ctl.onPluginConfigChange,
+			UpdateFunc: ctl.onPluginConfigUpdate,
+			DeleteFunc: ctl.onPluginConfigChange,
+		},
+	)
+	if c.apisixRateLimitPolicyInformer != nil {
+		c.apisixRateLimitPolicyInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    ctl.onRateLimitPolicyChange,
+				UpdateFunc: ctl.onRateLimitPolicyUpdate,
+				DeleteFunc: ctl.onRateLimitPolicyChange,
+			},
+		)
+		c.secretInformer.AddEventHandler(
+			cache.ResourceEventHandlerFuncs{
+				AddFunc:    ctl.onSecretChange,
+				UpdateFunc: ctl.onSecretUpdate,
+				DeleteFunc: ctl.onSecretChange,
+			},
+		)
+	}
 	return ctl
 }
 
@@ -63,37 +101,50 @@ func (c *apisixRouteController) run(ctx context.Context) {
 	defer log.Info("ApisixRoute controller exited")
 	defer c.workqueue.ShutDown()
 
-	ok := cache.WaitForCacheSync(ctx.Done(), c.controller.apisixRouteInformer.HasSynced)
+	synced := []cache.InformerSynced{c.controller.apisixRouteInformer.HasSynced, c.controller.configMapInformer.HasSynced,
+		c.controller.apisixPluginConfigInformer.HasSynced, c.controller.apisixUpstreamInformer.HasSynced}
+	if c.controller.apisixRateLimitPolicyInformer != nil {
+		synced = append(synced, c.controller.apisixRateLimitPolicyInformer.HasSynced, c.controller.secretInformer.HasSynced)
+	}
+	ok := cache.WaitForCacheSync(ctx.Done(), synced...)
 	if !ok {
 		log.Error("cache sync failed")
 		return
 	}
 
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
-	<-ctx.Done()
+	c.controller.initialSync.expect("ApisixRoute", c.controller.watchingKeys(c.controller.apisixRouteInformer))
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixRouteController) runWorker(ctx context.Context) {
+func (c *apisixRouteController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixRoute")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixRoute", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixRoute", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
 }
 
-func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error {
+func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) (err error) {
 	obj := ev.Object.(kube.ApisixRouteEvent)
 	namespace, name, err := cache.SplitMetaNamespaceKey(obj.Key)
 	if err != nil {
 		log.Errorf("invalid resource key: %s", obj.Key)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, obj.Key) {
+		return nil
+	}
 	var (
 		ar   kube.ApisixRoute
 		tctx *translation.TranslateContext
@@ -124,8 +175,15 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 			return nil
 		}
 	}
+	if ev.Type != types.EventDelete && ar != nil && isFinalizing(apisixRouteMeta(ar)) {
+		// The resyncs find the objects being deleted whose events were
+		// dropped, e.g. while the namespace is paused.
+		ev = &types.Event{Type: types.EventDelete, Object: ev.Object, Tombstone: ar}
+	}
 	if ev.Type == types.EventDelete {
-		if ar != nil {
+		if ar == nil {
+			ar = ev.Tombstone.(kube.ApisixRoute)
+		} else if !isFinalizing(apisixRouteMeta(ar)) {
 			// We still find the resource while we are processing the DELETE event,
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
@@ -134,7 +192,23 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 			)
 			return nil
 		}
-		ar = ev.Tombstone.(kube.ApisixRoute)
+		defer func() {
+			err = c.controller.finalize(ctx, apisixRouteMeta(ar), err)
+		}()
+	} else if err = c.controller.ensureFinalizer(ctx, apisixRouteMeta(ar)); err != nil {
+		return err
+	}
+	cluster, err := c.controller.resourceCluster(apisixRouteMeta(ar))
+	if err != nil {
+		if ev.Type == types.EventDelete {
+			// Nothing was pushed since the cluster is unknown.
+			return nil
+		}
+		log.Errorw("failed to find the apisix cluster of ApisixRoute",
+			zap.String("key", obj.Key),
+			zap.Error(err),
+		)
+		return err
 	}
 
 	switch obj.GroupVersion {
@@ -153,10 +227,12 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 		}
 	case kube.ApisixRouteV2beta3:
 		if ev.Type != types.EventDelete {
-			if err = c.checkPluginNameIfNotEmptyV2beta3(ctx, ar.V2beta3()); err == nil {
+			c.controller.warnDeprecated(ar.V2beta3(), "ApisixRoute", kube.ApisixRouteV2beta3, kube.ApisixRouteV2)
+			if err = c.checkPluginNameIfNotEmptyV2beta3(ctx, cluster, ar.V2beta3()); err == nil {
 				tctx, err = c.controller.translator.TranslateRouteV2beta3(ar.V2beta3())
 			}
 		} else {
+			c.controller.forgetDeprecated("ApisixRoute", namespace, name)
 			tctx, err = c.controller.translator.TranslateRouteV2beta3NotStrictly(ar.V2beta3())
 		}
 		if err != nil {
@@ -168,7 +244,7 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 		}
 	case kube.ApisixRouteV2:
 		if ev.Type != types.EventDelete {
-			if err = c.checkPluginNameIfNotEmptyV2(ctx, ar.V2()); err == nil {
+			if err = c.checkPluginNameIfNotEmptyV2(ctx, cluster, ar.V2()); err == nil {
 				tctx, err = c.controller.translator.TranslateRouteV2(ar.V2())
 			}
 		} else {
@@ -206,7 +282,7 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 	if ev.Type == types.EventDelete {
 		deleted = m
 	} else if ev.Type == types.EventAdd {
-		added = m
+		added = c.controller.filterResyncManifest(ev, cluster, m)
 	} else {
 		var oldCtx *translation.TranslateContext
 		switch obj.GroupVersion {
@@ -233,25 +309,30 @@ func (c *apisixRouteController) sync(ctx context.Context, ev *types.Event) error
 			StreamRoutes:  oldCtx.StreamRoutes,
 			PluginConfigs: oldCtx.PluginConfigs,
 		}
-		added, updated, deleted = m.Diff(om)
+		oldCluster, err := c.controller.resourceCluster(apisixRouteMeta(obj.OldObject))
+		if err != nil {
+			oldCluster = ""
+		}
+		return c.controller.syncUpdatedManifests(ctx, oldCluster, cluster, om, m)
 	}
 
-	return c.controller.syncManifests(ctx, added, updated, deleted)
+	return c.controller.syncManifestsToCluster(ctx, cluster, added, updated, deleted)
 }
 
-func (c *apisixRouteController) checkPluginNameIfNotEmptyV2beta3(ctx context.Context, in *v2beta3.ApisixRoute) error {
+func (c *apisixRouteController) checkPluginNameIfNotEmptyV2beta3(ctx context.Context, cluster string, in *v2beta3.ApisixRoute) error {
 	for _, v := range in.Spec.HTTP {
 		if v.PluginConfigName != "" {
-			_, err := c.controller.apisix.Cluster(c.controller.cfg.APISIX.DefaultClusterName).PluginConfig().Get(ctx, apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName))
+			pcNamespace, pcName := translation.ParsePluginConfigReference(in.Namespace, v.PluginConfigName)
+			_, err := c.controller.apisix.Cluster(cluster).PluginConfig().Get(ctx, apisixv1.ComposePluginConfigName(pcNamespace, pcName))
 			if err != nil {
 				if err == apisixcache.ErrNotFound {
 					log.Errorw("checkPluginNameIfNotEmptyV2beta3 error: plugin_config not found",
-						zap.String("name", apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName)),
+						zap.String("name", apisixv1.ComposePluginConfigName(pcNamespace, pcName)),
 						zap.Any("obj", in),
 						zap.Error(err))
 				} else {
 					log.Errorw("checkPluginNameIfNotEmptyV2beta3 PluginConfig get failed",
-						zap.String("name", apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName)),
+						zap.String("name", apisixv1.ComposePluginConfigName(pcNamespace, pcName)),
 						zap.Any("obj", in),
 						zap.Error(err))
 				}
@@ -262,19 +343,20 @@ func (c *apisixRouteController) checkPluginNameIfNotEmptyV2beta3(ctx context.Con
 	return nil
 }
 
-func (c *apisixRouteController) checkPluginNameIfNotEmptyV2(ctx context.Context, in *v2.ApisixRoute) error {
+func (c *apisixRouteController) checkPluginNameIfNotEmptyV2(ctx context.Context, cluster string, in *v2.ApisixRoute) error {
 	for _, v := range in.Spec.HTTP {
 		if v.PluginConfigName != "" {
-			_, err := c.controller.apisix.Cluster(c.controller.cfg.APISIX.DefaultClusterName).PluginConfig().Get(ctx, apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName))
+			pcNamespace, pcName := translation.ParsePluginConfigReference(in.Namespace, v.PluginConfigName)
+			_, err := c.controller.apisix.Cluster(cluster).PluginConfig().Get(ctx, apisixv1.ComposePluginConfigName(pcNamespace, pcName))
 			if err != nil {
 				if err == apisixcache.ErrNotFound {
 					log.Errorw("checkPluginNameIfNotEmptyV2 error: plugin_config not found",
-						zap.String("name", apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName)),
+						zap.String("name", apisixv1.ComposePluginConfigName(pcNamespace, pcName)),
 						zap.Any("obj", in),
 						zap.Error(err))
 				} else {
 					log.Errorw("checkPluginNameIfNotEmptyV2 PluginConfig get failed",
-						zap.String("name", apisixv1.ComposePluginConfigName(in.Namespace, v.PluginConfigName)),
+						zap.String("name", apisixv1.ComposePluginConfigName(pcNamespace, pcName)),
 						zap.Any("obj", in),
 						zap.Error(err))
 				}
@@ -341,17 +423,21 @@ func (c *apisixRouteController) handleSyncErr(obj interface{}, errOrigin error)
 		zap.Any("object", obj),
 		zap.Error(errOrigin),
 	)
+	reason := _resourceSyncAborted
+	if errors.Is(errOrigin, errRouteLimitExceeded) {
+		reason = _resourceSyncRejected
+	}
 	if errLocal == nil {
 		switch ar.GroupVersion() {
 		case kube.ApisixRouteV2beta2:
-			c.controller.recorderEvent(ar.V2beta2(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
-			c.controller.recordStatus(ar.V2beta2(), _resourceSyncAborted, errOrigin, metav1.ConditionFalse, ar.V2beta2().GetGeneration())
+			c.controller.recorderEvent(ar.V2beta2(), v1.EventTypeWarning, reason, errOrigin)
+			c.controller.recordStatus(ar.V2beta2(), reason, errOrigin, metav1.ConditionFalse, ar.V2beta2().GetGeneration())
 		case kube.ApisixRouteV2beta3:
-			c.controller.recorderEvent(ar.V2beta3(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
-			c.controller.recordStatus(ar.V2beta3(), _resourceSyncAborted, errOrigin, metav1.ConditionFalse, ar.V2beta3().GetGeneration())
+			c.controller.recorderEvent(ar.V2beta3(), v1.EventTypeWarning, reason, errOrigin)
+			c.controller.recordStatus(ar.V2beta3(), reason, errOrigin, metav1.ConditionFalse, ar.V2beta3().GetGeneration())
 		case kube.ApisixRouteV2:
-			c.controller.recorderEvent(ar.V2(), v1.EventTypeWarning, _resourceSyncAborted, errOrigin)
-			c.controller.recordStatus(ar.V2(), _resourceSyncAborted, errOrigin, metav1.ConditionFalse, ar.V2().GetGeneration())
+			c.controller.recorderEvent(ar.V2(), v1.EventTypeWarning, reason, errOrigin)
+			c.controller.recordStatus(ar.V2(), reason, errOrigin, metav1.ConditionFalse, ar.V2().GetGeneration())
 		}
 	} else {
 		log.Errorw("failed list ApisixRoute",
@@ -377,13 +463,16 @@ func (c *apisixRouteController) onAdd(obj interface{}) {
 		zap.Any("object", obj))
 
 	ar := kube.MustNewApisixRoute(obj)
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixRouteEvent{
 			Key:          key,
 			GroupVersion: ar.GroupVersion(),
 		},
 	})
+	if isTracingDisabled(ar) && c.controller.apisixClusterConfigController != nil {
+		c.controller.apisixClusterConfigController.resyncAll()
+	}
 
 	c.controller.MetricsCollector.IncrEvents("route", "add")
 }
@@ -406,6 +495,18 @@ func (c *apisixRouteController) onUpdate(oldObj, newObj interface{}) {
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
 	)
+	if isFinalizing(apisixRouteMeta(curr)) {
+		c.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.ApisixRouteEvent{
+				Key:          key,
+				GroupVersion: curr.GroupVersion(),
+			},
+			Tombstone: curr,
+		})
+		c.controller.MetricsCollector.IncrEvents("route", "delete")
+		return
+	}
 	c.workqueue.Add(&types.Event{
 		Type: types.EventUpdate,
 		Object: kube.ApisixRouteEvent{
@@ -414,6 +515,10 @@ func (c *apisixRouteController) onUpdate(oldObj, newObj interface{}) {
 			OldObject:    prev,
 		},
 	})
+	// The route IDs may change as well as the annotation.
+	if (isTracingDisabled(prev) || isTracingDisabled(curr)) && c.controller.apisixClusterConfigController != nil {
+		c.controller.apisixClusterConfigController.resyncAll()
+	}
 
 	c.controller.MetricsCollector.IncrEvents("route", "update")
 }
@@ -432,26 +537,342 @@ func (c *apisixRouteController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixRoute resource with bad meta namesapce key: %s", err)
 		return
 	}
-	if !c.controller.isWatchingNamespace(key) {
-		return
-	}
-	log.Debugw("ApisixRoute delete event arrived",
-		zap.Any("final state", ar),
-	)
-	c.workqueue.Add(&types.Event{
+	ev := &types.Event{
 		Type: types.EventDelete,
 		Object: kube.ApisixRouteEvent{
 			Key:          key,
 			GroupVersion: ar.GroupVersion(),
 		},
 		Tombstone: ar,
-	})
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("ApisixRoute delete event arrived",
+		zap.Any("final state", ar),
+	)
+	c.workqueue.Add(ev)
+	if isTracingDisabled(ar) && c.controller.apisixClusterConfigController != nil {
+		c.controller.apisixClusterConfigController.resyncAll()
+	}
 
 	c.controller.MetricsCollector.IncrEvents("route", "delete")
 }
 
-func (c *apisixRouteController) ResourceSync() {
+func (c *apisixRouteController) onConfigMapUpdate(oldObj, newObj interface{}) {
+	prev, ok := oldObj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+	curr, ok := newObj.(*v1.ConfigMap)
+	if !ok {
+		return
+	}
+	// Skip the updates which don't touch the data (e.g. the ones on
+	// leader election records).
+	if reflect.DeepEqual(prev.Data, curr.Data) {
+		return
+	}
+	c.onConfigMapChange(newObj)
+}
+
+func (c *apisixRouteController) onConfigMapChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ConfigMap resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	c.syncByConfigMap(namespace, name)
+}
+
+// syncByConfigMap re-syncs all ApisixRoutes which load plugins or body
+// templates from the given ConfigMap.
+func (c *apisixRouteController) syncByConfigMap(namespace, name string) {
+	c.syncReferencingRoutes(namespace, "ConfigMap", name, func(ar *v2.ApisixRoute) bool {
+		return referencesConfigMap(ar, name)
+	})
+}
+
+func (c *apisixRouteController) onRateLimitPolicyUpdate(oldObj, newObj interface{}) {
+	prev, ok := oldObj.(*v2.ApisixRateLimitPolicy)
+	if !ok {
+		return
+	}
+	curr, ok := newObj.(*v2.ApisixRateLimitPolicy)
+	if !ok {
+		return
+	}
+	if prev.ResourceVersion >= curr.ResourceVersion {
+		return
+	}
+	c.onRateLimitPolicyChange(newObj)
+}
+
+func (c *apisixRouteController) onRateLimitPolicyChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ApisixRateLimitPolicy resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	c.syncByRateLimitPolicy(namespace, name)
+}
+
+// syncByRateLimitPolicy re-syncs all ApisixRoutes whose limitCount
+// references the given ApisixRateLimitPolicy.
+func (c *apisixRouteController) syncByRateLimitPolicy(namespace, name string) {
+	c.syncReferencingRoutes(namespace, "ApisixRateLimitPolicy", name, func(ar *v2.ApisixRoute) bool {
+		return referencesRateLimitPolicy(ar, name)
+	})
+}
+
+func (c *apisixRouteController) onPluginConfigUpdate(oldObj, newObj interface{}) {
+	prev, err := kube.NewApisixPluginConfig(oldObj)
+	if err != nil {
+		return
+	}
+	curr, err := kube.NewApisixPluginConfig(newObj)
+	if err != nil {
+		return
+	}
+	if prev.ResourceVersion() >= curr.ResourceVersion() {
+		return
+	}
+	c.onPluginConfigChange(newObj)
+}
+
+func (c *apisixRouteController) onPluginConfigChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ApisixPluginConfig resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	// ApisixPluginConfigs might be referenced across namespaces.
+	c.syncRoutes("ApisixPluginConfig", namespace, name, func(ar *v2.ApisixRoute) bool {
+		return referencesPluginConfig(ar, namespace, name)
+	})
+}
+
+func (c *apisixRouteController) onApisixUpstreamUpdate(oldObj, newObj interface{}) {
+	prev, ok := oldObj.(*v2beta3.ApisixUpstream)
+	if !ok {
+		return
+	}
+	curr, ok := newObj.(*v2beta3.ApisixUpstream)
+	if !ok {
+		return
+	}
+	if prev.ResourceVersion >= curr.ResourceVersion {
+		return
+	}
+	// Only the weights of the subsets are translated into the routes.
+	if reflect.DeepEqual(upstreamSubsets(prev), upstreamSubsets(curr)) {
+		return
+	}
+	c.onApisixUpstreamChange(newObj)
+}
+
+// onApisixUpstreamChange re-syncs the ApisixRoutes whose traffic is split
+// across the weighted subsets of the ApisixUpstream.
+func (c *apisixRouteController) onApisixUpstreamChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ApisixUpstream resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	c.syncReferencingRoutes(namespace, "ApisixUpstream", name, func(ar *v2.ApisixRoute) bool {
+		return referencesService(ar, name)
+	})
+}
+
+func (c *apisixRouteController) onSecretUpdate(oldObj, newObj interface{}) {
+	prev, ok := oldObj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	curr, ok := newObj.(*v1.Secret)
+	if !ok {
+		return
+	}
+	if reflect.DeepEqual(prev.Data, curr.Data) {
+		return
+	}
+	c.onSecretChange(newObj)
+}
+
+// onSecretChange re-syncs the ApisixRoutes using the ApisixRateLimitPolicies
+// whose redis password is stored in the Secret.
+func (c *apisixRouteController) onSecretChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found secret resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	policies, err := c.controller.apisixRateLimitPolicyLister.ApisixRateLimitPolicies(namespace).List(labels.Everything())
+	if err != nil {
+		log.Errorw("failed to list ApisixRateLimitPolicies",
+			zap.Error(err),
+			zap.String("namespace", namespace),
+		)
+		return
+	}
+	for _, policy := range policies {
+		if rateLimitPolicyUsesSecret(policy, name) {
+			c.syncByRateLimitPolicy(namespace, policy.Name)
+		}
+	}
+}
+
+// syncReferencingRoutes re-syncs the v2 ApisixRoutes in the namespace
+// which reference the changed object according to refers.
+func (c *apisixRouteController) syncReferencingRoutes(namespace, kind, name string, refers func(*v2.ApisixRoute) bool) {
+	c.syncRoutes(kind, namespace, name, func(ar *v2.ApisixRoute) bool {
+		return ar.Namespace == namespace && refers(ar)
+	})
+}
+
+// syncRoutes re-syncs the v2 ApisixRoutes in all namespaces which reference
+// the changed object according to refers.
+func (c *apisixRouteController) syncRoutes(kind, namespace, name string, refers func(*v2.ApisixRoute) bool) {
 	objs := c.controller.apisixRouteInformer.GetIndexer().List()
+	for _, obj := range objs {
+		ar, ok := obj.(*v2.ApisixRoute)
+		if !ok || !refers(ar) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			log.Errorw("found ApisixRoute resource with bad meta namespace key", zap.String("error", err.Error()))
+			continue
+		}
+		log.Debugw("resync ApisixRoute since the referenced object changed",
+			zap.String("ApisixRoute", key),
+			zap.String("kind", kind),
+			zap.String("object", namespace+"/"+name),
+		)
+		c.workqueue.Add(&types.Event{
+			Type: types.EventAdd,
+			Object: kube.ApisixRouteEvent{
+				Key:          key,
+				GroupVersion: kube.ApisixRouteV2,
+			},
+		})
+	}
+}
+
+// referencesRateLimitPolicy reports whether any rule of the ApisixRoute
+// uses the ApisixRateLimitPolicy in limitCount.
+func referencesRateLimitPolicy(ar *v2.ApisixRoute, name string) bool {
+	for _, part := range ar.Spec.HTTP {
+		if part.LimitCount != nil && part.LimitCount.PolicyRef == name {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitPolicyUsesSecret reports whether the redis password of the
+// ApisixRateLimitPolicy is stored in the Secret.
+func rateLimitPolicyUsesSecret(policy *v2.ApisixRateLimitPolicy, name string) bool {
+	if r := policy.Spec.Redis; r != nil && r.PasswordSecretRef != nil && r.PasswordSecretRef.Name == name {
+		return true
+	}
+	if rc := policy.Spec.RedisCluster; rc != nil && rc.PasswordSecretRef != nil && rc.PasswordSecretRef.Name == name {
+		return true
+	}
+	return false
+}
+
+// upstreamSubsets returns the subsets of the ApisixUpstream.
+func upstreamSubsets(au *v2beta3.ApisixUpstream) []v2beta3.ApisixUpstreamSubset {
+	if au.Spec == nil {
+		return nil
+	}
+	return au.Spec.Subsets
+}
+
+// referencesService reports whether any rule of the ApisixRoute proxies to
+// the Service.
+func referencesService(ar *v2.ApisixRoute, name string) bool {
+	for _, part := range ar.Spec.HTTP {
+		for _, backend := range part.Backends {
+			if backend.ServiceName == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// referencesConfigMap reports whether any rule of the ApisixRoute loads
+// plugins or body templates from the ConfigMap.
+func referencesConfigMap(ar *v2.ApisixRoute, name string) bool {
+	for _, part := range ar.Spec.HTTP {
+		if part.PluginsFrom != nil && part.PluginsFrom.ConfigMapName == name {
+			return true
+		}
+		if part.BodyTransformer != nil && part.BodyTransformer.ConfigMapName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// referencesPluginConfig reports whether any rule of the ApisixRoute merges
+// its plugins with the ApisixPluginConfig.
+func referencesPluginConfig(ar *v2.ApisixRoute, namespace, name string) bool {
+	for _, part := range ar.Spec.HTTP {
+		if part.PluginConfigName == "" || len(part.Plugins) == 0 {
+			continue
+		}
+		pcNamespace, pcName := translation.ParsePluginConfigReference(ar.Namespace, part.PluginConfigName)
+		if pcNamespace == namespace && pcName == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *apisixRouteController) ResourceSync(snapshots utils.Snapshots, namespace string) []string {
+	var keys []string
+	objs := c.controller.resourceSyncObjects(c.controller.apisixRouteInformer, namespace)
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -462,12 +883,15 @@ func (c *apisixRouteController) ResourceSync() {
 			continue
 		}
 		ar := kube.MustNewApisixRoute(obj)
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.ApisixRouteEvent{
 				Key:          key,
 				GroupVersion: ar.GroupVersion(),
 			},
+			Snapshot: snapshots,
 		})
 	}
+	return keys
 }
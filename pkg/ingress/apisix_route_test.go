@@ -0,0 +1,355 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestApisixUpstreamSubsetsResyncRoutes(t *testing.T) {
+	newRoute := func(name, svc string) *configv2.ApisixRoute {
+		return &configv2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+			},
+			Spec: configv2.ApisixRouteSpec{
+				HTTP: []configv2.ApisixRouteHTTP{
+					{
+						Name:     "rule1",
+						Backends: []configv2.ApisixRouteHTTPBackend{{ServiceName: svc}},
+					},
+				},
+			},
+		}
+	}
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv2.ApisixRoute{}, 0, cache.Indexers{})
+	assert.Nil(t, informer.GetIndexer().Add(newRoute("canary", "httpbin")))
+	assert.Nil(t, informer.GetIndexer().Add(newRoute("other", "nginx")))
+
+	ctl := &apisixRouteController{
+		controller: &Controller{
+			apisixRouteInformer: informer,
+			namespaceProvider:   namespace.NewMockWatchingProvider([]string{"default"}),
+		},
+		workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer ctl.workqueue.ShutDown()
+
+	weight := 10
+	prev := &v2beta3.ApisixUpstream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "httpbin",
+			Namespace:       "default",
+			ResourceVersion: "1",
+		},
+		Spec: &v2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: v2beta3.ApisixUpstreamConfig{
+				Subsets: []v2beta3.ApisixUpstreamSubset{
+					{Name: "v1", Labels: map[string]string{"version": "v1"}, Weight: &weight},
+				},
+			},
+		},
+	}
+
+	// Changes out of the subsets are ignored.
+	curr := prev.DeepCopy()
+	curr.ResourceVersion = "2"
+	curr.Spec.Scheme = "https"
+	ctl.onApisixUpstreamUpdate(prev, curr)
+	assert.Equal(t, 0, ctl.workqueue.Len())
+
+	newWeight := 50
+	curr = prev.DeepCopy()
+	curr.ResourceVersion = "2"
+	curr.Spec.Subsets[0].Weight = &newWeight
+	ctl.onApisixUpstreamUpdate(prev, curr)
+	assert.Equal(t, 1, ctl.workqueue.Len())
+	obj, _ := ctl.workqueue.Get()
+	assert.Equal(t, kube.ApisixRouteEvent{Key: "default/canary", GroupVersion: kube.ApisixRouteV2}, obj.(*types.Event).Object)
+	ctl.workqueue.Done(obj)
+
+	// Deleting the ApisixUpstream drops the weighted subsets.
+	ctl.onApisixUpstreamChange(curr)
+	assert.Equal(t, 1, ctl.workqueue.Len())
+}
+
+// pausedWatchingProvider pauses the reconciliation of the namespaces.
+type pausedWatchingProvider struct {
+	namespace.WatchingProvider
+	paused map[string]bool
+}
+
+func (p *pausedWatchingProvider) IsWatchingNamespace(key string) bool {
+	return !p.IsPausedNamespace(key) && p.WatchingProvider.IsWatchingNamespace(key)
+}
+
+func (p *pausedWatchingProvider) IsPausedNamespace(key string) bool {
+	ns, _, _ := cache.SplitMetaNamespaceKey(key)
+	return p.paused[ns]
+}
+
+func TestApisixRouteDeleteInPausedNamespace(t *testing.T) {
+	provider := &pausedWatchingProvider{
+		WatchingProvider: namespace.NewMockWatchingProvider([]string{"default"}),
+		paused:           map[string]bool{"default": true},
+	}
+	ctl := &apisixRouteController{
+		controller: &Controller{
+			apisixRouteInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv2.ApisixRoute{}, 0,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}),
+			namespaceProvider: provider,
+			MetricsCollector:  metrics.NewPrometheusCollector(),
+		},
+		workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer ctl.workqueue.ShutDown()
+	ctl.controller.apisixRouteController = ctl
+
+	ar := &configv2.ApisixRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ApisixRoute",
+			APIVersion: "apisix.apache.org/v2",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+	}
+	// The deletion is kept while the namespace is paused.
+	ctl.onDelete(ar)
+	assert.Equal(t, 0, ctl.workqueue.Len())
+
+	// And it's applied once the reconciliation is resumed.
+	provider.paused["default"] = false
+	ctl.controller.resyncNamespace("default")
+	assert.Equal(t, 1, ctl.workqueue.Len())
+	obj, _ := ctl.workqueue.Get()
+	ev := obj.(*types.Event)
+	assert.Equal(t, types.EventType(types.EventDelete), ev.Type)
+	assert.Equal(t, kube.ApisixRouteEvent{Key: "default/foo", GroupVersion: kube.ApisixRouteV2}, ev.Object)
+	ctl.workqueue.Done(obj)
+
+	// Nothing is left to apply.
+	ctl.controller.resyncNamespace("default")
+	assert.Equal(t, 0, ctl.workqueue.Len())
+}
+
+// fakeAdminAPI is an in-memory APISIX Admin API, the objects are keyed by
+// their etcd keys, e.g. /apisix/upstreams/1.
+type fakeAdminAPI struct {
+	mu      sync.Mutex
+	objects map[string]json.RawMessage
+}
+
+func (f *fakeAdminAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := "/apisix" + strings.TrimPrefix(r.URL.Path, "/apisix/admin")
+	switch r.Method {
+	case http.MethodGet:
+		if value, ok := f.objects[key]; ok {
+			data, _ := json.Marshal(map[string]interface{}{
+				"node": map[string]interface{}{"key": key, "value": value},
+			})
+			_, _ = w.Write(data)
+			return
+		}
+		items := []map[string]interface{}{}
+		for k, v := range f.objects {
+			if strings.HasPrefix(k, key+"/") {
+				items = append(items, map[string]interface{}{"key": k, "value": v})
+			}
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"count": len(items),
+			"node":  map[string]interface{}{"key": key, "nodes": items},
+		})
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		value, _ := ioutil.ReadAll(r.Body)
+		f.objects[key] = value
+		data, _ := json.Marshal(map[string]interface{}{
+			"action": "set",
+			"node":   map[string]interface{}{"key": key, "value": json.RawMessage(value)},
+		})
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		if _, ok := f.objects[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.objects, key)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeAdminAPI) has(key string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.objects[key]
+	return ok
+}
+
+func TestApisixRouteCanaryRemovalDeletesUpstream(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "default",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{Name: "port1", Port: 80, TargetPort: intstr.FromInt(9080)},
+				{Name: "port2", Port: 443, TargetPort: intstr.FromInt(9443)},
+			},
+		},
+	}
+	ep := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "default",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Ports: []corev1.EndpointPort{
+					{Name: "port1", Port: 9080},
+					{Name: "port2", Port: 9443},
+				},
+				Addresses: []corev1.EndpointAddress{{IP: "192.168.1.1"}},
+			},
+		},
+	}
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(), 0)
+	svcInformer := factory.Core().V1().Services()
+	assert.Nil(t, svcInformer.Informer().GetIndexer().Add(svc))
+	epLister, epInformer := kube.NewEndpointListerAndInformer(factory, false)
+	assert.Nil(t, epInformer.GetIndexer().Add(ep))
+	apisixFactory := apisixinformers.NewSharedInformerFactory(fakeapisix.NewSimpleClientset(), 0)
+	tr := translation.NewTranslator(&translation.TranslatorOptions{
+		EndpointLister:       epLister,
+		ServiceLister:        svcInformer.Lister(),
+		ApisixUpstreamLister: apisixFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+	})
+
+	admin := &fakeAdminAPI{objects: make(map[string]json.RawMessage)}
+	srv := httptest.NewServer(admin)
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli, err := apisix.NewClient()
+	assert.Nil(t, err)
+	assert.Nil(t, cli.AddCluster(ctx, &apisix.ClusterOptions{
+		Name:             "default",
+		BaseURL:          srv.URL + "/apisix/admin",
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	}))
+	assert.Nil(t, cli.Cluster("default").HasSynced(ctx))
+
+	weight := 10
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "default",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{ServiceName: "svc", ServicePort: intstr.FromInt(80)},
+					},
+					Canary: &configv2.ApisixRouteHTTPCanary{
+						Rules: []configv2.ApisixRouteHTTPCanaryRule{
+							{
+								Backends: []configv2.ApisixRouteHTTPBackend{
+									{ServiceName: "svc", ServicePort: intstr.FromInt(443), Weight: &weight},
+								},
+								StableWeight: 90,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	translate := func(ar *configv2.ApisixRoute) *utils.Manifest {
+		tctx, err := tr.TranslateRouteV2(ar)
+		assert.Nil(t, err)
+		return &utils.Manifest{
+			Routes:    tctx.Routes,
+			Upstreams: tctx.Upstreams,
+		}
+	}
+
+	om := translate(ar)
+	assert.Len(t, om.Upstreams, 2)
+	assert.Nil(t, utils.SyncManifests(ctx, cli, "default", om, nil, nil))
+	var canaryKey string
+	for _, ups := range om.Upstreams {
+		if ups.ID != om.Routes[0].UpstreamId {
+			canaryKey = "/apisix/upstreams/" + ups.ID
+		}
+	}
+	assert.True(t, admin.has(canaryKey))
+
+	// Removing the canary backend drops the last reference to its upstream
+	// with the route update, the upstream is deleted after that.
+	newAr := ar.DeepCopy()
+	newAr.Spec.HTTP[0].Canary = nil
+	m := translate(newAr)
+	added, updated, deleted := m.Diff(om)
+	assert.Nil(t, added)
+	assert.Len(t, updated.Routes, 1)
+	assert.Len(t, deleted.Upstreams, 1)
+	assert.Nil(t, utils.SyncManifests(ctx, cli, "default", added, updated, deleted))
+	assert.False(t, admin.has(canaryKey))
+	assert.True(t, admin.has("/apisix/upstreams/"+m.Upstreams[0].ID))
+	assert.True(t, admin.has("/apisix/routes/"+m.Routes[0].ID))
+}
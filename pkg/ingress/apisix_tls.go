@@ -17,6 +17,7 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -24,11 +25,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
@@ -37,14 +41,12 @@ import (
 type apisixTlsController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newApisixTlsController() *apisixTlsController {
 	ctl := &apisixTlsController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixTls"),
-		workers:    1,
 	}
 	ctl.controller.apisixTlsInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -65,26 +67,30 @@ func (c *apisixTlsController) run(ctx context.Context) {
 		log.Errorf("informers sync failed")
 		return
 	}
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
+	c.controller.initialSync.expect("ApisixTls", c.controller.watchingKeys(c.controller.apisixTlsInformer))
 
-	<-ctx.Done()
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixTlsController) runWorker(ctx context.Context) {
+func (c *apisixTlsController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixTls")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixTls", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixTls", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
 }
 
-func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
+func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) (err error) {
 	event := ev.Object.(kube.ApisixTlsEvent)
 	key := event.Key
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
@@ -92,6 +98,9 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 		log.Errorf("found ApisixTls resource with invalid meta namespace key %s: %s", key, err)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, key) {
+		return nil
+	}
 
 	var multiVersionedTls kube.ApisixTls
 	switch event.GroupVersion {
@@ -121,15 +130,38 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			return nil
 		}
 	}
+	if ev.Type != types.EventDelete && multiVersionedTls != nil && isFinalizing(apisixTlsMeta(multiVersionedTls)) {
+		// The resyncs find the objects being deleted whose events were
+		// dropped, e.g. while the namespace is paused.
+		ev = &types.Event{Type: types.EventDelete, Object: ev.Object, Tombstone: multiVersionedTls}
+	}
 	if ev.Type == types.EventDelete {
-		if multiVersionedTls != nil {
+		if multiVersionedTls == nil {
+			multiVersionedTls = ev.Tombstone.(kube.ApisixTls)
+		} else if !isFinalizing(apisixTlsMeta(multiVersionedTls)) {
 			// We still find the resource while we are processing the DELETE event,
 			// that means object with same namespace and name was created, discarding
 			// this stale DELETE event.
 			log.Warnf("discard the stale ApisixTls delete event since the %s exists", key)
 			return nil
 		}
-		multiVersionedTls = ev.Tombstone.(kube.ApisixTls)
+		defer func() {
+			err = c.controller.finalize(ctx, apisixTlsMeta(multiVersionedTls), err)
+		}()
+	} else if err = c.controller.ensureFinalizer(ctx, apisixTlsMeta(multiVersionedTls)); err != nil {
+		return err
+	}
+	cluster, err := c.controller.resourceCluster(apisixTlsMeta(multiVersionedTls))
+	if err != nil {
+		if ev.Type == types.EventDelete {
+			// Nothing was pushed since the cluster is unknown.
+			return nil
+		}
+		log.Errorw("failed to find the apisix cluster of ApisixTls",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		return err
 	}
 
 	switch event.GroupVersion {
@@ -159,7 +191,7 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			}
 		}
 
-		if err := c.controller.syncSSL(ctx, ssl, ev.Type); err != nil {
+		if err := c.syncSSL(ctx, ev, cluster, ssl); err != nil {
 			log.Errorw("failed to sync SSL to APISIX",
 				zap.Error(err),
 				zap.Any("ssl", ssl),
@@ -168,12 +200,15 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse, tls.GetGeneration())
 			return err
 		}
+		if err := c.verifySSL(ctx, cluster, tls, tls.GetGeneration(), ssl, ev.Type); err != nil {
+			return err
+		}
 		c.controller.recorderEvent(tls, corev1.EventTypeNormal, _resourceSynced, nil)
 		c.controller.recordStatus(tls, _resourceSynced, nil, metav1.ConditionTrue, tls.GetGeneration())
 		return err
 	case config.ApisixV2:
 		tls := multiVersionedTls.V2()
-		ssl, err := c.controller.translator.TranslateSSLV2(tls)
+		ssl, err := c.controller.translateApisixTlsV2(key, tls)
 		if err != nil {
 			log.Errorw("failed to translate ApisixTls",
 				zap.Error(err),
@@ -197,7 +232,7 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			}
 		}
 
-		if err := c.controller.syncSSL(ctx, ssl, ev.Type); err != nil {
+		if err := c.syncSSL(ctx, ev, cluster, ssl); err != nil {
 			log.Errorw("failed to sync SSL to APISIX",
 				zap.Error(err),
 				zap.Any("ssl", ssl),
@@ -206,6 +241,9 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 			c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse, tls.GetGeneration())
 			return err
 		}
+		if err := c.verifySSL(ctx, cluster, tls, tls.GetGeneration(), ssl, ev.Type); err != nil {
+			return err
+		}
 		c.controller.recorderEvent(tls, corev1.EventTypeNormal, _resourceSynced, nil)
 		c.controller.recordStatus(tls, _resourceSynced, nil, metav1.ConditionTrue, tls.GetGeneration())
 		return err
@@ -214,6 +252,127 @@ func (c *apisixTlsController) sync(ctx context.Context, ev *types.Event) error {
 	}
 }
 
+// syncSSL pushes the SSL to the APISIX cluster of the ApisixTls, it's moved
+// from the old cluster if the cluster of the updated ApisixTls changes.
+func (c *apisixTlsController) syncSSL(ctx context.Context, ev *types.Event, cluster string, ssl *v1.Ssl) error {
+	event := ev.Object.(kube.ApisixTlsEvent)
+	if ev.Type != types.EventUpdate || event.OldObject == nil {
+		return c.controller.syncSSL(ctx, cluster, ssl, ev.Type)
+	}
+	oldCluster, err := c.controller.resourceCluster(apisixTlsMeta(event.OldObject))
+	if err != nil {
+		oldCluster = ""
+	}
+	return c.controller.syncUpdatedSSL(ctx, oldCluster, cluster, ssl)
+}
+
+// verifySSL reads the SSL object back from the APISIX cluster when
+// verify_ssl_snis is enabled, the ApisixTls is reported as partially synced
+// if some SNIs were not registered. The error is returned so that the event
+// is retried.
+func (c *apisixTlsController) verifySSL(ctx context.Context, cluster string, tls runtime.Object, generation int64, ssl *v1.Ssl, evType types.EventType) error {
+	if !c.controller.cfg.APISIX.VerifySSLSNIs || evType == types.EventDelete {
+		return nil
+	}
+	missing, err := c.controller.apisix.Cluster(cluster).SSL().VerifySNIs(ctx, ssl)
+	if err != nil {
+		log.Errorw("failed to read SSL object back from APISIX",
+			zap.Error(err),
+			zap.Any("ssl", ssl),
+		)
+		return err
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	err = fmt.Errorf("SNIs not registered in APISIX: %s", strings.Join(missing, ", "))
+	log.Warnw("SSL object was partially registered in APISIX",
+		zap.Error(err),
+		zap.Any("ssl", ssl),
+	)
+	c.controller.recorderEvent(tls, corev1.EventTypeWarning, _resourceSyncPartial, err)
+	c.controller.recordStatus(tls, _resourceSyncPartial, err, metav1.ConditionFalse, generation)
+	return err
+}
+
+// translateApisixTlsV2 translates the ApisixTls, the SNIs discovered
+// from the certificate SANs are skipped if other ApisixTls list them in
+// the hosts.
+func (c *Controller) translateApisixTlsV2(key string, tls *configv2.ApisixTls) (*v1.Ssl, error) {
+	ssl, err := c.translator.TranslateSSLV2(tls)
+	if err != nil || !tls.Spec.AutoSNI {
+		return ssl, err
+	}
+	claimed := make(map[string]struct{})
+	for _, obj := range c.apisixTlsInformer.GetStore().List() {
+		otherKey, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || otherKey == key {
+			continue
+		}
+		other, err := kube.NewApisixTls(obj)
+		if err != nil {
+			continue
+		}
+		for _, host := range apisixTlsHosts(other) {
+			claimed[host] = struct{}{}
+		}
+	}
+	snis, skipped := skipConflictingSANs(tls.Spec.Hosts, ssl.Snis, claimed)
+	if len(skipped) > 0 {
+		log.Warnw("skip certificate SANs which are hosts of other ApisixTls",
+			zap.String("ApisixTls", key),
+			zap.Strings("SANs", skipped),
+		)
+	}
+	if len(snis) == 0 {
+		return nil, translation.ErrEmptySNIs
+	}
+	ssl.Snis = snis
+	return ssl, nil
+}
+
+// skipConflictingSANs removes the SNIs which are not in hosts but
+// claimed by others, it returns the remaining and the removed SNIs.
+func skipConflictingSANs(hosts []configv2.HostType, snis []string, claimed map[string]struct{}) ([]string, []string) {
+	own := make(map[string]struct{}, len(hosts))
+	for _, host := range hosts {
+		own[string(host)] = struct{}{}
+	}
+	var (
+		kept    []string
+		skipped []string
+	)
+	for _, sni := range snis {
+		if _, ok := own[sni]; !ok {
+			if _, ok := claimed[sni]; ok {
+				skipped = append(skipped, sni)
+				continue
+			}
+		}
+		kept = append(kept, sni)
+	}
+	return kept, skipped
+}
+
+func apisixTlsHosts(tls kube.ApisixTls) []string {
+	var hosts []string
+	switch tls.GroupVersion() {
+	case config.ApisixV2beta3:
+		if spec := tls.V2beta3().Spec; spec != nil {
+			for _, host := range spec.Hosts {
+				hosts = append(hosts, string(host))
+			}
+		}
+	case config.ApisixV2:
+		if spec := tls.V2().Spec; spec != nil {
+			for _, host := range spec.Hosts {
+				hosts = append(hosts, string(host))
+			}
+		}
+	}
+	return hosts
+}
+
 func (c *apisixTlsController) syncSecretSSL(secretKey string, apisixTlsKey string, ssl *v1.Ssl, event types.EventType) {
 	if ssls, ok := c.controller.secretSSLMap.Load(secretKey); ok {
 		sslMap := ssls.(*sync.Map)
@@ -275,7 +434,7 @@ func (c *apisixTlsController) onAdd(obj interface{}) {
 	log.Debugw("ApisixTls add event arrived",
 		zap.Any("object", obj),
 	)
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.ApisixTlsEvent{
 			Key:          key,
@@ -312,6 +471,18 @@ func (c *apisixTlsController) onUpdate(prev, curr interface{}) {
 		zap.Any("new object", curr),
 		zap.Any("old object", prev),
 	)
+	if isFinalizing(apisixTlsMeta(newTls)) {
+		c.workqueue.Add(&types.Event{
+			Type: types.EventDelete,
+			Object: kube.ApisixTlsEvent{
+				Key:          key,
+				GroupVersion: newTls.GroupVersion(),
+			},
+			Tombstone: newTls,
+		})
+		c.controller.MetricsCollector.IncrEvents("TLS", "delete")
+		return
+	}
 	c.workqueue.Add(&types.Event{
 		Type: types.EventUpdate,
 		Object: kube.ApisixTlsEvent{
@@ -342,26 +513,31 @@ func (c *apisixTlsController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixTls resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.isWatchingNamespace(key) {
-		return
-	}
-	log.Debugw("ApisixTls delete event arrived",
-		zap.Any("final state", obj),
-	)
-	c.workqueue.Add(&types.Event{
+	ev := &types.Event{
 		Type: types.EventDelete,
 		Object: kube.ApisixTlsEvent{
 			Key:          key,
 			GroupVersion: tls.GroupVersion(),
 		},
 		Tombstone: tls,
-	})
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("ApisixTls delete event arrived",
+		zap.Any("final state", obj),
+	)
+	c.workqueue.Add(ev)
 
 	c.controller.MetricsCollector.IncrEvents("TLS", "delete")
 }
 
-func (c *apisixTlsController) ResourceSync() {
-	objs := c.controller.apisixTlsInformer.GetIndexer().List()
+func (c *apisixTlsController) ResourceSync(namespace string) []string {
+	var keys []string
+	objs := c.controller.resourceSyncObjects(c.controller.apisixTlsInformer, namespace)
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -376,6 +552,7 @@ func (c *apisixTlsController) ResourceSync() {
 			log.Errorw("ApisixTls sync failed, found ApisixTls resource with bad type", zap.Error(err))
 			continue
 		}
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.ApisixTlsEvent{
@@ -384,4 +561,5 @@ func (c *apisixTlsController) ResourceSync() {
 			},
 		})
 	}
+	return keys
 }
@@ -0,0 +1,135 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestSkipConflictingSANs(t *testing.T) {
+	claimed := map[string]struct{}{
+		"api.example.com": {},
+		"example.com":     {},
+	}
+	snis, skipped := skipConflictingSANs(
+		[]configv2.HostType{"example.com"},
+		[]string{"example.com", "*.example.com", "api.example.com"},
+		claimed,
+	)
+	assert.Equal(t, []string{"example.com", "*.example.com"}, snis)
+	assert.Equal(t, []string{"api.example.com"}, skipped)
+
+	snis, skipped = skipConflictingSANs(nil, []string{"api.example.com"}, claimed)
+	assert.Nil(t, snis)
+	assert.Equal(t, []string{"api.example.com"}, skipped)
+}
+
+type fakeVerifyAPISIX struct {
+	apisix.APISIX
+	ssl *fakeVerifySSL
+}
+
+func (f *fakeVerifyAPISIX) Cluster(string) apisix.Cluster {
+	return &fakeVerifyCluster{ssl: f.ssl}
+}
+
+type fakeVerifyCluster struct {
+	apisix.Cluster
+	ssl *fakeVerifySSL
+}
+
+func (f *fakeVerifyCluster) SSL() apisix.SSL {
+	return f.ssl
+}
+
+type fakeVerifySSL struct {
+	apisix.SSL
+	missing []string
+	err     error
+	calls   int
+}
+
+func (f *fakeVerifySSL) VerifySNIs(_ context.Context, _ *v1.Ssl) ([]string, error) {
+	f.calls++
+	return f.missing, f.err
+}
+
+func TestApisixTlsVerifySSL(t *testing.T) {
+	tls := &configv2.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "tls",
+			Namespace:  "default",
+			Generation: 1,
+		},
+	}
+	apisixClient := fakeapisix.NewSimpleClientset(tls)
+	recorder := record.NewFakeRecorder(10)
+	fakeSSL := &fakeVerifySSL{}
+	cfg := config.NewDefaultConfig()
+	c := &apisixTlsController{
+		controller: &Controller{
+			cfg:        cfg,
+			apisix:     &fakeVerifyAPISIX{ssl: fakeSSL},
+			recorder:   recorder,
+			kubeClient: &kube.KubeClient{APISIXClient: apisixClient},
+		},
+	}
+	ssl := &v1.Ssl{ID: "1", Snis: []string{"a.com", "b.com"}}
+
+	// Nothing is read back unless it's enabled.
+	assert.Nil(t, c.verifySSL(context.Background(), "default", tls, 1, ssl, types.EventAdd))
+	assert.Equal(t, 0, fakeSSL.calls)
+
+	cfg.APISIX.VerifySSLSNIs = true
+	assert.Nil(t, c.verifySSL(context.Background(), "default", tls, 1, ssl, types.EventDelete))
+	assert.Equal(t, 0, fakeSSL.calls)
+
+	assert.Nil(t, c.verifySSL(context.Background(), "default", tls, 1, ssl, types.EventAdd))
+	assert.Equal(t, 1, fakeSSL.calls)
+	assert.Len(t, recorder.Events, 0)
+
+	// The read-back failure is retried.
+	fakeSSL.err = errors.New("connection refused")
+	assert.Equal(t, fakeSSL.err, c.verifySSL(context.Background(), "default", tls, 1, ssl, types.EventUpdate))
+	assert.Len(t, recorder.Events, 0)
+
+	fakeSSL.err = nil
+	fakeSSL.missing = []string{"b.com"}
+	err := c.verifySSL(context.Background(), "default", tls, 1, ssl, types.EventUpdate)
+	assert.EqualError(t, err, "SNIs not registered in APISIX: b.com")
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning ResourceSyncPartial")
+	obj, err := apisixClient.ApisixV2().ApisixTlses("default").Get(context.Background(), "tls", metav1.GetOptions{})
+	assert.Nil(t, err)
+	cond := meta.FindStatusCondition(obj.Status.Conditions, _conditionType)
+	assert.NotNil(t, cond)
+	assert.Equal(t, _resourceSyncPartial, cond.Reason)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+}
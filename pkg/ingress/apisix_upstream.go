@@ -16,33 +16,71 @@ package ingress
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
-	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
+// _upstreamTLSVerifyDisabled is the event reason for the ApisixUpstreams
+// which disable the TLS verification.
+const _upstreamTLSVerifyDisabled = "UpstreamTLSVerifyDisabled"
+
+// errUpstreamTLSVerifyDisabled is reported in the status of the
+// ApisixUpstreams which disable the TLS verification.
+var errUpstreamTLSVerifyDisabled = errors.New("synced, but the TLS verification of the upstream is disabled")
+
+// _apisixUpstreamServiceIndex indexes the ApisixUpstreams by the keys of
+// the Services they aggregate.
+const _apisixUpstreamServiceIndex = "services"
+
+func apisixUpstreamServiceIndexFunc(obj interface{}) ([]string, error) {
+	au, ok := obj.(*configv2beta3.ApisixUpstream)
+	if !ok || au.Spec == nil {
+		return nil, nil
+	}
+	keys := make([]string, 0, len(au.Spec.Services))
+	for _, ref := range au.Spec.Services {
+		ns := ref.Namespace
+		if ns == "" {
+			ns = au.Namespace
+		}
+		keys = append(keys, ns+"/"+ref.Name)
+	}
+	return keys, nil
+}
+
 type apisixUpstreamController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newApisixUpstreamController() *apisixUpstreamController {
 	ctl := &apisixUpstreamController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ApisixUpstream"),
-		workers:    1,
+	}
+	if err := ctl.controller.apisixUpstreamInformer.AddIndexers(cache.Indexers{
+		_apisixUpstreamServiceIndex: apisixUpstreamServiceIndexFunc,
+	}); err != nil {
+		log.Errorw("failed to add the service index of ApisixUpstream",
+			zap.Error(err),
+		)
 	}
 	ctl.controller.apisixUpstreamInformer.AddEventHandler(
 		cache.ResourceEventHandlerFuncs{
@@ -63,20 +101,24 @@ func (c *apisixUpstreamController) run(ctx context.Context) {
 		log.Error("cache sync failed")
 		return
 	}
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
+	c.controller.initialSync.expect("ApisixUpstream", c.controller.watchingKeys(c.controller.apisixUpstreamInformer))
 
-	<-ctx.Done()
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *apisixUpstreamController) runWorker(ctx context.Context) {
+func (c *apisixUpstreamController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("ApisixUpstream")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("ApisixUpstream", obj.(*types.Event), err)
+		c.controller.manualResync.observe("ApisixUpstream", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
@@ -93,6 +135,9 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 		log.Errorf("found ApisixUpstream resource with invalid meta namespace key %s: %s", key, err)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, key) {
+		return nil
+	}
 
 	au, err := c.controller.apisixUpstreamLister.ApisixUpstreams(namespace).Get(name)
 	if err != nil {
@@ -125,82 +170,231 @@ func (c *apisixUpstreamController) sync(ctx context.Context, ev *types.Event) er
 		}
 	}
 
+	// The Service is not required by the ApisixUpstream with external nodes.
+	external := au.Spec != nil && len(au.Spec.ExternalNodes) > 0
 	svc, err := c.controller.svcLister.Services(namespace).Get(name)
-	if err != nil {
+	if err != nil && !(external && k8serrors.IsNotFound(err)) {
 		log.Errorf("failed to get service %s: %s", key, err)
 		c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
 		c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
 		return err
 	}
+	if svc == nil && ev.Type == types.EventDelete {
+		// There is no Service to restore the upstreams with, the routes
+		// referencing them fail to sync from now on.
+		return nil
+	}
 
 	var subsets []configv2beta3.ApisixUpstreamSubset
 	subsets = append(subsets, configv2beta3.ApisixUpstreamSubset{})
 	if au.Spec != nil && len(au.Spec.Subsets) > 0 {
 		subsets = append(subsets, au.Spec.Subsets...)
 	}
-	clusterName := c.controller.cfg.APISIX.DefaultClusterName
-	for _, port := range svc.Spec.Ports {
-		for _, subset := range subsets {
-			upsName := apisixv1.ComposeUpstreamName(namespace, name, subset.Name, port.Port)
-			// TODO: multiple cluster
-			ups, err := c.controller.apisix.Cluster(clusterName).Upstream().Get(ctx, upsName)
-			if err != nil {
-				if err == apisixcache.ErrNotFound {
-					continue
-				}
-				log.Errorf("failed to get upstream %s: %s", upsName, err)
-				c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
-				c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
-				return err
-			}
-			var newUps *apisixv1.Upstream
-			if au.Spec != nil && ev.Type != types.EventDelete {
-				cfg, ok := portLevelSettings[port.Port]
-				if !ok {
-					cfg = &au.Spec.ApisixUpstreamConfig
-				}
-				// FIXME Same ApisixUpstreamConfig might be translated multiple times.
-				newUps, err = c.controller.translator.TranslateUpstreamConfig(cfg)
+	aggregated := ev.Type != types.EventDelete && au.Spec != nil && len(au.Spec.Services) > 0
+	discovered := ev.Type != types.EventDelete && au.Spec != nil && au.Spec.Discovery != nil
+	external = external && ev.Type != types.EventDelete
+	for _, clusterName := range c.controller.clusterNames() {
+		ports, err := c.upstreamPorts(ctx, clusterName, namespace, name, svc)
+		if err != nil {
+			log.Errorw("failed to list the ports of upstreams",
+				zap.String("key", key),
+				zap.String("cluster", clusterName),
+				zap.Error(err),
+			)
+			return err
+		}
+		for _, port := range ports {
+			for _, subset := range subsets {
+				upsName := apisixv1.ComposeUpstreamName(namespace, name, subset.Name, port)
+				upstreams, err := c.controller.apisix.Cluster(clusterName).Upstream().ListByName(ctx, upsName)
 				if err != nil {
-					log.Errorw("found malformed ApisixUpstream",
-						zap.Any("object", au),
-						zap.Error(err),
-					)
+					log.Errorf("failed to list upstreams %s: %s", upsName, err)
 					c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
 					c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
 					return err
 				}
-			} else {
-				newUps = apisixv1.NewDefaultUpstream()
-			}
+				for _, ups := range upstreams {
+					var newUps *apisixv1.Upstream
+					if au.Spec != nil && ev.Type != types.EventDelete {
+						cfg, ok := portLevelSettings[port]
+						if !ok {
+							cfg = &au.Spec.ApisixUpstreamConfig
+						}
+						// FIXME Same ApisixUpstreamConfig might be translated multiple times.
+						newUps, err = c.controller.translator.TranslateUpstreamConfig(cfg)
+						if err != nil {
+							log.Errorw("found malformed ApisixUpstream",
+								zap.Any("object", au),
+								zap.Error(err),
+							)
+							c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+							c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
+							return err
+						}
+					} else {
+						// Fall back to the Service annotations (if any) once the
+						// ApisixUpstream is gone.
+						newUps, err = c.controller.translator.TranslateServiceUpstreamConfig(svc)
+						if err != nil {
+							log.Warnw("failed to translate Service upstream annotations, use the default upstream",
+								zap.String("service", key),
+								zap.Error(err),
+							)
+							newUps = apisixv1.NewDefaultUpstream()
+						}
+					}
 
-			newUps.Metadata = ups.Metadata
-			newUps.Nodes = ups.Nodes
-			log.Debugw("updating upstream since ApisixUpstream changed",
-				zap.String("event", ev.Type.String()),
-				zap.Any("upstream", newUps),
-				zap.Any("ApisixUpstream", au),
-			)
-			if _, err := c.controller.apisix.Cluster(clusterName).Upstream().Update(ctx, newUps); err != nil {
-				log.Errorw("failed to update upstream",
-					zap.Error(err),
-					zap.Any("upstream", newUps),
-					zap.Any("ApisixUpstream", au),
-					zap.String("cluster", clusterName),
-				)
-				c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
-				c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
-				return err
+					newUps.Metadata = ups.Metadata
+					if external {
+						if newUps.Nodes, err = c.controller.translator.TranslateExternalNodes(au.Spec.ExternalNodes, port); err != nil {
+							log.Errorw("found ApisixUpstream with invalid external nodes",
+								zap.Any("object", au),
+								zap.Error(err),
+							)
+							c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+							c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
+							return err
+						}
+					} else if discovered {
+						if err = c.controller.translator.TranslateUpstreamDiscovery(au.Spec.Discovery, newUps); err != nil {
+							log.Errorw("found ApisixUpstream with invalid discovery",
+								zap.Any("object", au),
+								zap.Error(err),
+							)
+							c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+							c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
+							return err
+						}
+					} else if aggregated {
+						var selector labels.Selector
+						selector, err = translation.SubsetSelector(&subset)
+						if err == nil {
+							newUps.Nodes, err = c.controller.translator.TranslateServiceRefsNodes(namespace, au.Spec.Services, port, selector)
+						}
+						if err != nil {
+							log.Errorw("failed to translate the nodes of the aggregated services",
+								zap.Any("object", au),
+								zap.Error(err),
+							)
+							c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+							c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
+							return err
+						}
+					} else {
+						newUps.Nodes = ups.Nodes
+						if resolvedByServiceDNS(ups, namespace, name) {
+							newUps.DiscoveryType = ups.DiscoveryType
+							newUps.ServiceName = ups.ServiceName
+						} else if newUps.Nodes == nil {
+							// The discovery is removed, the nodes are restored
+							// by the endpoints resync below.
+							newUps.Nodes = apisixv1.UpstreamNodes{}
+						}
+					}
+					log.Debugw("updating upstream since ApisixUpstream changed",
+						zap.String("event", ev.Type.String()),
+						zap.Any("upstream", newUps),
+						zap.Any("ApisixUpstream", au),
+					)
+					if err := c.controller.updateUpstream(ctx, clusterName, ups, newUps); err != nil {
+						log.Errorw("failed to update upstream",
+							zap.Error(err),
+							zap.Any("upstream", newUps),
+							zap.Any("ApisixUpstream", au),
+							zap.String("cluster", clusterName),
+						)
+						c.controller.recorderEvent(au, corev1.EventTypeWarning, _resourceSyncAborted, err)
+						c.controller.recordStatus(au, _resourceSyncAborted, err, metav1.ConditionFalse, au.GetGeneration())
+						return err
+					}
+				}
 			}
 		}
 	}
+	if !aggregated && !discovered && !external && ev.Type != types.EventAdd {
+		// The services might be removed from the ApisixUpstream, restore
+		// the nodes to the endpoints of the Service.
+		if err := c.controller.resyncEndpoint(ctx, namespace, name); err != nil {
+			log.Errorw("failed to resync the endpoints of the ApisixUpstream",
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			return err
+		}
+	}
 	if ev.Type != types.EventDelete {
 		c.controller.recorderEvent(au, corev1.EventTypeNormal, _resourceSynced, nil)
-		c.controller.recordStatus(au, _resourceSynced, nil, metav1.ConditionTrue, au.GetGeneration())
+		if skipsTLSVerify(au) {
+			log.Warnw("TLS verification of the upstream is disabled, the identity of the backend is not checked",
+				zap.String("ApisixUpstream", key),
+			)
+			c.controller.recorderEventS(au, corev1.EventTypeWarning, _upstreamTLSVerifyDisabled, errUpstreamTLSVerifyDisabled.Error())
+			c.controller.recordStatus(au, _resourceSynced, errUpstreamTLSVerifyDisabled, metav1.ConditionTrue, au.GetGeneration())
+		} else {
+			c.controller.recordStatus(au, _resourceSynced, nil, metav1.ConditionTrue, au.GetGeneration())
+		}
 	}
 	return err
 }
 
+// skipsTLSVerify reports whether the ApisixUpstream disables the TLS
+// verification on any port.
+func skipsTLSVerify(au *configv2beta3.ApisixUpstream) bool {
+	if au.Spec == nil {
+		return false
+	}
+	disabled := func(tls *configv2beta3.ApisixUpstreamTLS) bool {
+		return tls != nil && tls.Verify != nil && !*tls.Verify
+	}
+	if disabled(au.Spec.TLS) {
+		return true
+	}
+	for _, port := range au.Spec.PortLevelSettings {
+		if disabled(port.TLS) {
+			return true
+		}
+	}
+	return false
+}
+
+// upstreamPorts returns the ports of the upstreams of the ApisixUpstream, they
+// are the Service ports, or the ports in the names of the existing upstreams
+// if there is no Service, e.g. for the ApisixUpstream with external nodes.
+func (c *apisixUpstreamController) upstreamPorts(ctx context.Context, clusterName, namespace, name string, svc *corev1.Service) ([]int32, error) {
+	if svc != nil {
+		ports := make([]int32, 0, len(svc.Spec.Ports))
+		for _, port := range svc.Spec.Ports {
+			ports = append(ports, port.Port)
+		}
+		return ports, nil
+	}
+	upstreams, err := c.controller.apisix.Cluster(clusterName).Upstream().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return upstreamPortsByName(upstreams, namespace, name), nil
+}
+
+// upstreamPortsByName picks the ports from the names of the upstreams composed
+// by apisixv1.ComposeUpstreamName without subset.
+func upstreamPortsByName(upstreams []*apisixv1.Upstream, namespace, name string) []int32 {
+	var ports []int32
+	prefix := namespace + "_" + name + "_"
+	for _, ups := range upstreams {
+		if !strings.HasPrefix(ups.Name, prefix) {
+			continue
+		}
+		port, err := strconv.ParseInt(strings.TrimPrefix(ups.Name, prefix), 10, 32)
+		if err != nil {
+			// With subset.
+			continue
+		}
+		ports = append(ports, int32(port))
+	}
+	sort.Slice(ports, func(i, j int) bool { return ports[i] < ports[j] })
+	return ports
+}
+
 func (c *apisixUpstreamController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
@@ -237,7 +431,7 @@ func (c *apisixUpstreamController) onAdd(obj interface{}) {
 	log.Debugw("ApisixUpstream add event arrived",
 		zap.Any("object", obj))
 
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type:   types.EventAdd,
 		Object: key,
 	})
@@ -287,23 +481,51 @@ func (c *apisixUpstreamController) onDelete(obj interface{}) {
 		log.Errorf("found ApisixUpstream resource with bad meta namespace key: %s", err)
 		return
 	}
+	ev := &types.Event{
+		Type:      types.EventDelete,
+		Object:    key,
+		Tombstone: au,
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
 	if !c.controller.isWatchingNamespace(key) {
 		return
 	}
 	log.Debugw("ApisixUpstream delete event arrived",
 		zap.Any("final state", au),
 	)
-	c.workqueue.Add(&types.Event{
-		Type:      types.EventDelete,
-		Object:    key,
-		Tombstone: au,
-	})
+	c.workqueue.Add(ev)
 
 	c.controller.MetricsCollector.IncrEvents("upstream", "delete")
 }
 
-func (c *apisixUpstreamController) ResourceSync() {
-	clusterConfigs := c.controller.apisixUpstreamInformer.GetIndexer().List()
+// resyncSubsets re-syncs the ApisixUpstreams with subsets in the namespace,
+// e.g. when the pod labels change.
+func (c *apisixUpstreamController) resyncSubsets(namespace string) {
+	objs, err := c.controller.apisixUpstreamInformer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Errorw("failed to list ApisixUpstreams",
+			zap.String("namespace", namespace),
+			zap.Error(err),
+		)
+		return
+	}
+	for _, obj := range objs {
+		au := obj.(*configv2beta3.ApisixUpstream)
+		if au.Spec == nil || len(au.Spec.Subsets) == 0 {
+			continue
+		}
+		c.workqueue.Add(&types.Event{
+			Type:   types.EventUpdate,
+			Object: au.Namespace + "/" + au.Name,
+		})
+	}
+}
+
+func (c *apisixUpstreamController) ResourceSync(namespace string) []string {
+	var keys []string
+	clusterConfigs := c.controller.resourceSyncObjects(c.controller.apisixUpstreamInformer, namespace)
 	for _, clusterConfig := range clusterConfigs {
 		key, err := cache.MetaNamespaceKeyFunc(clusterConfig)
 		if err != nil {
@@ -313,9 +535,11 @@ func (c *apisixUpstreamController) ResourceSync() {
 		if !c.controller.isWatchingNamespace(key) {
 			continue
 		}
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type:   types.EventAdd,
 			Object: key,
 		})
 	}
+	return keys
 }
@@ -0,0 +1,38 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestUpstreamPortsByName(t *testing.T) {
+	var upstreams []*apisixv1.Upstream
+	for _, name := range []string{
+		apisixv1.ComposeUpstreamName("default", "db-proxy", "", 5432),
+		apisixv1.ComposeUpstreamName("default", "db-proxy", "", 443),
+		apisixv1.ComposeUpstreamName("default", "db-proxy", "v1", 80),
+		apisixv1.ComposeUpstreamName("default", "db-proxy-v2", "", 80),
+		apisixv1.ComposeUpstreamName("test", "db-proxy", "", 8080),
+	} {
+		upstreams = append(upstreams, &apisixv1.Upstream{Metadata: apisixv1.Metadata{Name: name}})
+	}
+	assert.Equal(t, []int32{443, 5432}, upstreamPortsByName(upstreams, "default", "db-proxy"))
+	assert.Nil(t, upstreamPortsByName(upstreams, "default", "httpbin"))
+}
@@ -0,0 +1,204 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// clusterNames returns the names of all the configured APISIX clusters, the
+// default cluster goes first.
+func (c *Controller) clusterNames() []string {
+	names := []string{c.cfg.APISIX.DefaultClusterName}
+	for _, cluster := range c.cfg.APISIX.Clusters {
+		names = append(names, cluster.Name)
+	}
+	return names
+}
+
+// hasCluster reports whether the APISIX cluster is configured.
+func (c *Controller) hasCluster(name string) bool {
+	for _, cluster := range c.clusterNames() {
+		if cluster == name {
+			return true
+		}
+	}
+	return false
+}
+
+// clusterBaseURL returns the Admin API base url of the APISIX cluster.
+func (c *Controller) clusterBaseURL(name string) string {
+	if name == c.cfg.APISIX.DefaultClusterName {
+		return c.cfg.APISIX.DefaultClusterBaseURL
+	}
+	for _, cluster := range c.cfg.APISIX.Clusters {
+		if cluster.Name == name {
+			return cluster.BaseURL
+		}
+	}
+	return ""
+}
+
+// resourceCluster returns the APISIX cluster selected by the annotations of
+// the resource.
+func (c *Controller) resourceCluster(obj metav1.Object) (string, error) {
+	name := obj.GetAnnotations()[annotations.AnnotationsAPISIXCluster]
+	if name == "" {
+		return c.cfg.APISIX.DefaultClusterName, nil
+	}
+	if !c.hasCluster(name) {
+		return "", fmt.Errorf("unknown apisix cluster %s", name)
+	}
+	return name, nil
+}
+
+// addClusters adds the APISIX clusters besides the default one, the clusters
+// failed to be added are skipped, and the resources selecting them will fail
+// to sync.
+func (c *Controller) addClusters(ctx context.Context) {
+	for _, cluster := range c.cfg.APISIX.Clusters {
+		clusterOpts := &apisix.ClusterOptions{
+			Name:                cluster.Name,
+			AdminKey:            cluster.AdminKey,
+			BaseURL:             cluster.BaseURL,
+			MetricsCollector:    c.MetricsCollector,
+			AuditLogger:         c.auditLogger,
+			SchemaCacheTTL:      c.cfg.APISIX.PluginSchemaCacheTTL.Duration,
+			MaxRetries:          c.cfg.APISIX.AdminAPIMaxRetries,
+			MaxRetryBackoff:     c.cfg.APISIX.AdminAPIMaxRetryBackoff.Duration,
+			BatchConcurrency:    c.cfg.APISIX.AdminAPIBatchConcurrency,
+			Timeout:             c.cfg.APISIX.AdminAPITimeout.Duration,
+			DialTimeout:         c.cfg.APISIX.AdminAPIDialTimeout.Duration,
+			MaxIdleConnsPerHost: c.cfg.APISIX.AdminAPIMaxIdleConnsPerHost,
+		}
+		if err := c.apisix.AddCluster(ctx, clusterOpts); err != nil && err != apisix.ErrDuplicatedCluster {
+			log.Errorw("failed to add apisix cluster",
+				zap.String("cluster", cluster.Name),
+				zap.Error(err),
+			)
+			continue
+		}
+		if err := c.apisix.Cluster(cluster.Name).HasSynced(ctx); err != nil {
+			log.Errorw("failed to wait the apisix cluster to be ready",
+				zap.String("cluster", cluster.Name),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// deleteClusters deletes all the APISIX clusters.
+func (c *Controller) deleteClusters() {
+	for _, name := range c.clusterNames() {
+		c.apisix.DeleteCluster(name)
+	}
+}
+
+// syncUpdatedManifests syncs the difference between the old and the new
+// manifests of a resource, which might move to another APISIX cluster. In
+// that case the old objects are deleted from the old cluster after the new
+// ones are created. An empty oldCluster means the old objects were not
+// pushed to any cluster.
+func (c *Controller) syncUpdatedManifests(ctx context.Context, oldCluster, cluster string, om, m *utils.Manifest) error {
+	if oldCluster == cluster {
+		added, updated, deleted := m.Diff(om)
+		return c.syncManifestsToCluster(ctx, cluster, added, updated, deleted)
+	}
+	if err := c.syncManifestsToCluster(ctx, cluster, m, nil, nil); err != nil {
+		return err
+	}
+	if oldCluster == "" {
+		return nil
+	}
+	log.Infow("resource moved to another apisix cluster",
+		zap.String("old_cluster", oldCluster),
+		zap.String("cluster", cluster),
+	)
+	return c.syncManifestsToCluster(ctx, oldCluster, nil, nil, om)
+}
+
+// syncUpdatedSSL is like syncUpdatedManifests but for the SSL translated from
+// the ApisixTls.
+func (c *Controller) syncUpdatedSSL(ctx context.Context, oldCluster, cluster string, ssl *apisixv1.Ssl) error {
+	if oldCluster == cluster {
+		return c.syncSSL(ctx, cluster, ssl, types.EventUpdate)
+	}
+	if err := c.syncSSL(ctx, cluster, ssl, types.EventAdd); err != nil {
+		return err
+	}
+	if oldCluster == "" {
+		return nil
+	}
+	log.Infow("resource moved to another apisix cluster",
+		zap.String("old_cluster", oldCluster),
+		zap.String("cluster", cluster),
+	)
+	return c.syncSSL(ctx, oldCluster, ssl, types.EventDelete)
+}
+
+// syncUpdatedConsumer is like syncUpdatedManifests but for the consumer
+// translated from the ApisixConsumer.
+func (c *Controller) syncUpdatedConsumer(ctx context.Context, oldCluster, cluster string, consumer *apisixv1.Consumer) error {
+	if oldCluster == cluster {
+		return c.syncConsumer(ctx, cluster, consumer, types.EventUpdate)
+	}
+	if err := c.syncConsumer(ctx, cluster, consumer, types.EventAdd); err != nil {
+		return err
+	}
+	if oldCluster == "" {
+		return nil
+	}
+	log.Infow("resource moved to another apisix cluster",
+		zap.String("old_cluster", oldCluster),
+		zap.String("cluster", cluster),
+	)
+	return c.syncConsumer(ctx, oldCluster, consumer, types.EventDelete)
+}
+
+// apisixRouteMeta returns the object meta of the ApisixRoute.
+func apisixRouteMeta(ar kube.ApisixRoute) metav1.Object {
+	switch ar.GroupVersion() {
+	case kube.ApisixRouteV2beta2:
+		return ar.V2beta2()
+	case kube.ApisixRouteV2beta3:
+		return ar.V2beta3()
+	default:
+		return ar.V2()
+	}
+}
+
+// ingressMeta returns the object meta of the Ingress.
+func ingressMeta(ing kube.Ingress) metav1.Object {
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		return ing.V1()
+	case kube.IngressV1beta1:
+		return ing.V1beta1()
+	default:
+		return ing.ExtensionsV1beta1()
+	}
+}
@@ -0,0 +1,221 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestResourceCluster(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.APISIX.DefaultClusterName = "default"
+	cfg.APISIX.Clusters = []config.APISIXClusterConfig{
+		{Name: "edge", BaseURL: "http://apisix-edge:9180/apisix/admin"},
+	}
+	c := &Controller{cfg: cfg}
+
+	assert.Equal(t, []string{"default", "edge"}, c.clusterNames())
+	assert.True(t, c.hasCluster("default"))
+	assert.True(t, c.hasCluster("edge"))
+	assert.False(t, c.hasCluster("internal"))
+
+	obj := &metav1.ObjectMeta{Name: "httpbin", Namespace: "default"}
+	cluster, err := c.resourceCluster(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, "default", cluster)
+
+	obj.Annotations = map[string]string{annotations.AnnotationsAPISIXCluster: "edge"}
+	cluster, err = c.resourceCluster(obj)
+	assert.Nil(t, err)
+	assert.Equal(t, "edge", cluster)
+
+	obj.Annotations[annotations.AnnotationsAPISIXCluster] = "internal"
+	_, err = c.resourceCluster(obj)
+	assert.Equal(t, "unknown apisix cluster internal", err.Error())
+}
+
+type fakeClustersAPISIX struct {
+	apisix.APISIX
+	calls []string
+}
+
+func (f *fakeClustersAPISIX) Cluster(name string) apisix.Cluster {
+	return &fakeClustersCluster{name: name, apisix: f}
+}
+
+type fakeClustersCluster struct {
+	apisix.Cluster
+	name   string
+	apisix *fakeClustersAPISIX
+}
+
+func (f *fakeClustersCluster) SSL() apisix.SSL {
+	return &fakeClustersSSL{cluster: f}
+}
+
+func (f *fakeClustersCluster) Consumer() apisix.Consumer {
+	return &fakeClustersConsumer{cluster: f}
+}
+
+func (f *fakeClustersCluster) record(op string) {
+	f.apisix.calls = append(f.apisix.calls, f.name+":"+op)
+}
+
+type fakeClustersSSL struct {
+	apisix.SSL
+	cluster *fakeClustersCluster
+}
+
+func (f *fakeClustersSSL) Create(_ context.Context, ssl *v1.Ssl) (*v1.Ssl, error) {
+	f.cluster.record("create ssl")
+	return ssl, nil
+}
+
+func (f *fakeClustersSSL) Update(_ context.Context, ssl *v1.Ssl) (*v1.Ssl, error) {
+	f.cluster.record("update ssl")
+	return ssl, nil
+}
+
+func (f *fakeClustersSSL) Delete(_ context.Context, _ *v1.Ssl) error {
+	f.cluster.record("delete ssl")
+	return nil
+}
+
+type fakeClustersConsumer struct {
+	apisix.Consumer
+	cluster *fakeClustersCluster
+}
+
+func (f *fakeClustersConsumer) Create(_ context.Context, consumer *v1.Consumer) (*v1.Consumer, error) {
+	f.cluster.record("create consumer")
+	return consumer, nil
+}
+
+func (f *fakeClustersConsumer) Update(_ context.Context, consumer *v1.Consumer) (*v1.Consumer, error) {
+	f.cluster.record("update consumer")
+	return consumer, nil
+}
+
+func (f *fakeClustersConsumer) Delete(_ context.Context, _ *v1.Consumer) error {
+	f.cluster.record("delete consumer")
+	return nil
+}
+
+func TestSyncSSLAndConsumerToAnnotatedCluster(t *testing.T) {
+	cfg := config.NewDefaultConfig()
+	cfg.APISIX.DefaultClusterName = "default"
+	cfg.APISIX.Clusters = []config.APISIXClusterConfig{
+		{Name: "edge", BaseURL: "http://apisix-edge:9180/apisix/admin"},
+	}
+	fake := &fakeClustersAPISIX{}
+	c := &Controller{cfg: cfg, apisix: fake}
+	ctx := context.Background()
+
+	tls := kube.MustNewApisixTls(&configv2beta3.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "tls",
+			Namespace:   "default",
+			Annotations: map[string]string{annotations.AnnotationsAPISIXCluster: "edge"},
+		},
+	})
+	cluster, err := c.resourceCluster(apisixTlsMeta(tls))
+	assert.Nil(t, err)
+	assert.Equal(t, "edge", cluster)
+
+	consumer := kube.MustNewApisixConsumer(&configv2beta3.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "jack",
+			Namespace:   "default",
+			Annotations: map[string]string{annotations.AnnotationsAPISIXCluster: "edge"},
+		},
+	})
+	cluster, err = c.resourceCluster(apisixConsumerMeta(consumer))
+	assert.Nil(t, err)
+	assert.Equal(t, "edge", cluster)
+
+	ssl := &v1.Ssl{ID: "1"}
+	assert.Nil(t, c.syncSSL(ctx, "edge", ssl, types.EventAdd))
+	assert.Nil(t, c.syncUpdatedSSL(ctx, "edge", "edge", ssl))
+	assert.Equal(t, []string{"edge:create ssl", "edge:update ssl"}, fake.calls)
+
+	// The annotation moved the ApisixTls from the default cluster to edge.
+	fake.calls = nil
+	assert.Nil(t, c.syncUpdatedSSL(ctx, "default", "edge", ssl))
+	assert.Equal(t, []string{"edge:create ssl", "default:delete ssl"}, fake.calls)
+
+	fake.calls = nil
+	ac := &v1.Consumer{Username: "jack"}
+	assert.Nil(t, c.syncConsumer(ctx, "edge", ac, types.EventAdd))
+	assert.Nil(t, c.syncUpdatedConsumer(ctx, "default", "edge", ac))
+	assert.Equal(t, []string{"edge:create consumer", "edge:create consumer", "default:delete consumer"}, fake.calls)
+}
+
+func TestFilterResyncManifestPerCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli, err := apisix.NewClient()
+	assert.Nil(t, err)
+	for _, name := range []string{"default", "edge"} {
+		srv := httptest.NewServer(&fakeAdminAPI{objects: make(map[string]json.RawMessage)})
+		defer srv.Close()
+		assert.Nil(t, cli.AddCluster(ctx, &apisix.ClusterOptions{
+			Name:             name,
+			BaseURL:          srv.URL + "/apisix/admin",
+			MetricsCollector: metrics.NewPrometheusCollector(),
+		}))
+		assert.Nil(t, cli.Cluster(name).HasSynced(ctx))
+	}
+	c := &Controller{
+		cfg:              config.NewDefaultConfig(),
+		apisix:           cli,
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	}
+
+	// The route is in the default cluster, but drifted away from the edge
+	// cluster.
+	route := routeOf("default", "httpbin")
+	m := &utils.Manifest{Routes: []*v1.Route{route}}
+	assert.Nil(t, c.syncManifestsToCluster(ctx, "default", m, nil, nil))
+
+	snapshots := make(utils.Snapshots)
+	for _, name := range []string{"default", "edge"} {
+		snapshot, err := utils.NewSnapshot(ctx, cli, name)
+		assert.Nil(t, err)
+		snapshots[name] = snapshot
+	}
+	ev := &types.Event{Type: types.EventAdd, Snapshot: snapshots}
+	assert.Empty(t, c.filterResyncManifest(ev, "default", m).Routes)
+	assert.Equal(t, m.Routes, c.filterResyncManifest(ev, "edge", m).Routes)
+
+	// Everything is pushed to the clusters without snapshot.
+	delete(snapshots, "default")
+	assert.Equal(t, m, c.filterResyncManifest(ev, "default", m))
+}
@@ -17,36 +17,49 @@ package ingress
 import (
 	"context"
 	"sync"
+	"sync/atomic"
 
+	"github.com/hashicorp/go-multierror"
 	"go.uber.org/zap"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 // CompareResources used to compare the object IDs in resources and APISIX
-// Find out the rest of objects in APISIX
+// Find out the rest of objects in each APISIX cluster, i.e. the ones not
+// translated from the resources which select the cluster,
 // AND warn them in log.
 // This func is NOT concurrency safe.
 // cc https://github.com/apache/apisix-ingress-controller/pull/742#discussion_r757197791
 func (c *Controller) CompareResources(ctx context.Context) error {
 	var (
-		wg                 sync.WaitGroup
-		routeMapK8S        = new(sync.Map)
-		streamRouteMapK8S  = new(sync.Map)
-		upstreamMapK8S     = new(sync.Map)
-		sslMapK8S          = new(sync.Map)
-		consumerMapK8S     = new(sync.Map)
-		pluginConfigMapK8S = new(sync.Map)
+		wg sync.WaitGroup
+		// expected holds the objects translated from the Kubernetes
+		// resources of each APISIX cluster.
+		expected = make(map[string]*expectedResources)
 
-		routeMapA6        = make(map[string]string)
-		streamRouteMapA6  = make(map[string]string)
-		upstreamMapA6     = make(map[string]string)
-		sslMapA6          = make(map[string]string)
-		consumerMapA6     = make(map[string]string)
-		pluginConfigMapA6 = make(map[string]string)
+		// incomplete is set once any Kubernetes resource fails to be
+		// listed or translated, the orphan resources won't be deleted
+		// in such a case.
+		incomplete int32
 	)
+	for _, cluster := range c.clusterNames() {
+		expected[cluster] = newExpectedResources()
+	}
+	// expect returns the expected objects of the APISIX cluster selected
+	// by the resource, nil if the cluster is unknown, the resource is not
+	// synced to any cluster then.
+	expect := func(obj v1.Object) *expectedResources {
+		cluster, err := c.resourceCluster(obj)
+		if err != nil {
+			return nil
+		}
+		return expected[cluster]
+	}
 
 	namespaces := c.namespaceProvider.WatchingNamespaces()
 	for _, key := range namespaces {
@@ -56,213 +69,426 @@ func (c *Controller) CompareResources(ctx context.Context) error {
 			defer wg.Done()
 			// ApisixRoute
 			opts := v1.ListOptions{}
-			switch c.cfg.Kubernetes.ApisixRouteVersion {
-			case config.ApisixRouteV2beta3:
-				retRoutes, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixRoutes(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, r := range retRoutes.Items {
-						tc, err := c.translator.TranslateRouteV2beta3NotStrictly(&r)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							// routes
-							for _, route := range tc.Routes {
-								routeMapK8S.Store(route.ID, route.ID)
+			if c.cfg.Kubernetes.IsControllerEnabled(config.ControllerApisixRoute) {
+				switch c.cfg.Kubernetes.ApisixRouteVersion {
+				case config.ApisixRouteV2beta3:
+					retRoutes, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixRoutes(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, r := range retRoutes.Items {
+							tc, err := c.translator.TranslateRouteV2beta3NotStrictly(&r)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&r); e != nil {
+								// routes
+								for _, route := range tc.Routes {
+									e.routes.Store(route.ID, route.ID)
+								}
+								// streamRoutes
+								for _, stRoute := range tc.StreamRoutes {
+									e.streamRoutes.Store(stRoute.ID, stRoute.ID)
+								}
+								// upstreams
+								for _, upstream := range tc.Upstreams {
+									e.upstreams.Store(upstream.Name, upstream.ID)
+								}
+								// ssl
+								for _, ssl := range tc.SSL {
+									e.ssls.Store(ssl.ID, ssl.ID)
+								}
+								// pluginConfigs
+								for _, pluginConfig := range tc.PluginConfigs {
+									e.pluginConfigs.Store(pluginConfig.ID, pluginConfig.ID)
+								}
 							}
-							// streamRoutes
-							for _, stRoute := range tc.StreamRoutes {
-								streamRouteMapK8S.Store(stRoute.ID, stRoute.ID)
-							}
-							// upstreams
-							for _, upstream := range tc.Upstreams {
-								upstreamMapK8S.Store(upstream.ID, upstream.ID)
-							}
-							// ssl
-							for _, ssl := range tc.SSL {
-								sslMapK8S.Store(ssl.ID, ssl.ID)
-							}
-							// pluginConfigs
-							for _, pluginConfig := range tc.PluginConfigs {
-								pluginConfigMapK8S.Store(pluginConfig.ID, pluginConfig.ID)
+						}
+					}
+				case config.ApisixRouteV2:
+					retRoutes, err := c.kubeClient.APISIXClient.ApisixV2().ApisixRoutes(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, r := range retRoutes.Items {
+							tc, err := c.translator.TranslateRouteV2NotStrictly(&r)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&r); e != nil {
+								// routes
+								for _, route := range tc.Routes {
+									e.routes.Store(route.ID, route.ID)
+								}
+								// streamRoutes
+								for _, stRoute := range tc.StreamRoutes {
+									e.streamRoutes.Store(stRoute.ID, stRoute.ID)
+								}
+								// upstreams
+								for _, upstream := range tc.Upstreams {
+									e.upstreams.Store(upstream.Name, upstream.ID)
+								}
+								// ssl
+								for _, ssl := range tc.SSL {
+									e.ssls.Store(ssl.ID, ssl.ID)
+								}
+								// pluginConfigs
+								for _, pluginConfig := range tc.PluginConfigs {
+									e.pluginConfigs.Store(pluginConfig.ID, pluginConfig.ID)
+								}
 							}
 						}
 					}
+				default:
+					log.Errorw("failed to sync ApisixRoute, unexpected version",
+						zap.String("version", c.cfg.Kubernetes.ApisixRouteVersion),
+					)
+					atomic.StoreInt32(&incomplete, 1)
 				}
-			case config.ApisixRouteV2:
-				retRoutes, err := c.kubeClient.APISIXClient.ApisixV2().ApisixRoutes(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, r := range retRoutes.Items {
-						tc, err := c.translator.TranslateRouteV2NotStrictly(&r)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							// routes
-							for _, route := range tc.Routes {
-								routeMapK8S.Store(route.ID, route.ID)
-							}
-							// streamRoutes
-							for _, stRoute := range tc.StreamRoutes {
-								streamRouteMapK8S.Store(stRoute.ID, stRoute.ID)
-							}
-							// upstreams
-							for _, upstream := range tc.Upstreams {
-								upstreamMapK8S.Store(upstream.ID, upstream.ID)
-							}
-							// ssl
-							for _, ssl := range tc.SSL {
-								sslMapK8S.Store(ssl.ID, ssl.ID)
+			}
+			// todo ApisixUpstream
+			// ApisixUpstream should be synced with ApisixRoute resource
+
+			if c.cfg.Kubernetes.IsControllerEnabled(config.ControllerApisixPluginConfig) {
+				switch c.cfg.Kubernetes.ApisixPluginConfigVersion {
+				case config.ApisixV2beta3:
+					retPluginConfigs, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixPluginConfigs(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else {
+						for _, pc := range retPluginConfigs.Items {
+							tc, err := c.translator.TranslatePluginConfigV2beta3NotStrictly(&pc)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+							} else if e := expect(&pc); e != nil {
+								for _, pluginConfig := range tc.PluginConfigs {
+									e.pluginConfigs.Store(pluginConfig.ID, pluginConfig.ID)
+								}
 							}
-							// pluginConfigs
-							for _, pluginConfig := range tc.PluginConfigs {
-								pluginConfigMapK8S.Store(pluginConfig.ID, pluginConfig.ID)
+						}
+					}
+				case config.ApisixV2:
+					retPluginConfigs, err := c.kubeClient.APISIXClient.ApisixV2().ApisixPluginConfigs(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else {
+						for _, pc := range retPluginConfigs.Items {
+							tc, err := c.translator.TranslatePluginConfigV2NotStrictly(&pc)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+							} else if e := expect(&pc); e != nil {
+								for _, pluginConfig := range tc.PluginConfigs {
+									e.pluginConfigs.Store(pluginConfig.ID, pluginConfig.ID)
+								}
 							}
 						}
 					}
+				default:
+					log.Errorw("failed to sync ApisixPluginConfig, unexpected version",
+						zap.String("version", c.cfg.Kubernetes.ApisixPluginConfigVersion),
+					)
+					atomic.StoreInt32(&incomplete, 1)
 				}
-			default:
-				log.Errorw("failed to sync ApisixRoute, unexpected version",
-					zap.String("version", c.cfg.Kubernetes.ApisixRouteVersion),
-				)
 			}
-			// todo ApisixUpstream and ApisixPluginConfig
-			// ApisixUpstream and ApisixPluginConfig should be synced with ApisixRoute resource
 
-			switch c.cfg.Kubernetes.ApisixTlsVersion {
-			case config.ApisixV2beta3:
-				retSSL, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixTlses(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, s := range retSSL.Items {
-						ssl, err := c.translator.TranslateSSLV2Beta3(&s)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							sslMapK8S.Store(ssl.ID, ssl.ID)
+			// Ingress
+			if c.cfg.Kubernetes.IsControllerEnabled(config.ControllerIngress) {
+				var ingresses []kube.Ingress
+				switch c.cfg.Kubernetes.IngressVersion {
+				case config.IngressNetworkingV1:
+					retIngresses, err := c.kubeClient.Client.NetworkingV1().Ingresses(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else {
+						for i := range retIngresses.Items {
+							ingresses = append(ingresses, kube.MustNewIngress(&retIngresses.Items[i]))
+						}
+					}
+				case config.IngressNetworkingV1beta1:
+					retIngresses, err := c.kubeClient.Client.NetworkingV1beta1().Ingresses(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else {
+						for i := range retIngresses.Items {
+							ingresses = append(ingresses, kube.MustNewIngress(&retIngresses.Items[i]))
+						}
+					}
+				default:
+					retIngresses, err := c.kubeClient.Client.ExtensionsV1beta1().Ingresses(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else {
+						for i := range retIngresses.Items {
+							ingresses = append(ingresses, kube.MustNewIngress(&retIngresses.Items[i]))
 						}
 					}
 				}
-			case config.ApisixV2:
-				retSSL, err := c.kubeClient.APISIXClient.ApisixV2().ApisixTlses(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, s := range retSSL.Items {
-						ssl, err := c.translator.TranslateSSLV2(&s)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							sslMapK8S.Store(ssl.ID, ssl.ID)
+				for _, ing := range ingresses {
+					tc, err := c.translator.TranslateIngress(ing, true)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+					} else if e := expect(ingressMeta(ing)); e != nil {
+						for _, route := range tc.Routes {
+							e.routes.Store(route.ID, route.ID)
+						}
+						for _, upstream := range tc.Upstreams {
+							e.upstreams.Store(upstream.Name, upstream.ID)
+						}
+						for _, pluginConfig := range tc.PluginConfigs {
+							e.pluginConfigs.Store(pluginConfig.ID, pluginConfig.ID)
+						}
+						for _, ssl := range tc.SSL {
+							e.ssls.Store(ssl.ID, ssl.ID)
 						}
 					}
 				}
-			default:
-				log.Errorw("failed to sync ApisixTls, unexpected version",
-					zap.String("version", c.cfg.Kubernetes.ApisixTlsVersion),
-				)
 			}
 
-			switch c.cfg.Kubernetes.ApisixConsumerVersion {
-			case config.ApisixV2beta3:
-				// ApisixConsumer
-				retConsumer, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixConsumers(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, con := range retConsumer.Items {
-						consumer, err := c.translator.TranslateApisixConsumerV2beta3(&con)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							consumerMapK8S.Store(consumer.Username, consumer.Username)
+			if c.cfg.Kubernetes.IsControllerEnabled(config.ControllerApisixTls) {
+				switch c.cfg.Kubernetes.ApisixTlsVersion {
+				case config.ApisixV2beta3:
+					retSSL, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixTlses(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, s := range retSSL.Items {
+							ssl, err := c.translator.TranslateSSLV2Beta3(&s)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&s); e != nil {
+								e.ssls.Store(ssl.ID, ssl.ID)
+							}
+						}
+					}
+				case config.ApisixV2:
+					retSSL, err := c.kubeClient.APISIXClient.ApisixV2().ApisixTlses(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, s := range retSSL.Items {
+							ssl, err := c.translator.TranslateSSLV2(&s)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&s); e != nil {
+								e.ssls.Store(ssl.ID, ssl.ID)
+							}
 						}
 					}
+				default:
+					log.Errorw("failed to sync ApisixTls, unexpected version",
+						zap.String("version", c.cfg.Kubernetes.ApisixTlsVersion),
+					)
+					atomic.StoreInt32(&incomplete, 1)
 				}
-			case config.ApisixV2:
-				// ApisixConsumer
-				retConsumer, err := c.kubeClient.APISIXClient.ApisixV2().ApisixConsumers(ns).List(ctx, opts)
-				if err != nil {
-					log.Error(err.Error())
-					ctx.Done()
-				} else {
-					for _, con := range retConsumer.Items {
-						consumer, err := c.translator.TranslateApisixConsumerV2(&con)
-						if err != nil {
-							log.Error(err.Error())
-							ctx.Done()
-						} else {
-							consumerMapK8S.Store(consumer.Username, consumer.Username)
+			}
+
+			if c.cfg.Kubernetes.IsControllerEnabled(config.ControllerApisixConsumer) {
+				switch c.cfg.Kubernetes.ApisixConsumerVersion {
+				case config.ApisixV2beta3:
+					// ApisixConsumer
+					retConsumer, err := c.kubeClient.APISIXClient.ApisixV2beta3().ApisixConsumers(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, con := range retConsumer.Items {
+							consumer, err := c.translator.TranslateApisixConsumerV2beta3(&con)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&con); e != nil {
+								e.consumers.Store(consumer.Username, consumer.Username)
+							}
+						}
+					}
+				case config.ApisixV2:
+					// ApisixConsumer
+					retConsumer, err := c.kubeClient.APISIXClient.ApisixV2().ApisixConsumers(ns).List(ctx, opts)
+					if err != nil {
+						log.Error(err.Error())
+						atomic.StoreInt32(&incomplete, 1)
+						ctx.Done()
+					} else {
+						for _, con := range retConsumer.Items {
+							consumer, err := c.translator.TranslateApisixConsumerV2(&con)
+							if err != nil {
+								log.Error(err.Error())
+								atomic.StoreInt32(&incomplete, 1)
+								ctx.Done()
+							} else if e := expect(&con); e != nil {
+								e.consumers.Store(consumer.Username, consumer.Username)
+							}
 						}
 					}
+				default:
+					log.Errorw("failed to sync ApisixConsumer, unexpected version",
+						zap.String("version", c.cfg.Kubernetes.ApisixConsumerVersion),
+					)
+					atomic.StoreInt32(&incomplete, 1)
 				}
-			default:
-				log.Errorw("failed to sync ApisixConsumer, unexpected version",
-					zap.String("version", c.cfg.Kubernetes.ApisixConsumerVersion),
-				)
 			}
 		}(key)
 	}
 	wg.Wait()
 
+	gc := c.cfg.OrphanGC != config.OrphanGCDisabled
+	dryRun := gc && c.orphanGCDryRun(namespaces, atomic.LoadInt32(&incomplete) == 1)
+	var merr *multierror.Error
+	for _, cluster := range c.clusterNames() {
+		if err := c.compareClusterResources(ctx, cluster, expected[cluster], gc, dryRun); err != nil {
+			log.Errorw("failed to compare resources",
+				zap.String("cluster", cluster),
+				zap.Error(err),
+			)
+			merr = multierror.Append(merr, err)
+		}
+	}
+	return merr.ErrorOrNil()
+}
+
+// expectedResources holds the IDs of the APISIX objects translated from the
+// Kubernetes resources, except that the upstreams are keyed by the names.
+type expectedResources struct {
+	routes        *sync.Map
+	streamRoutes  *sync.Map
+	upstreams     *sync.Map
+	ssls          *sync.Map
+	consumers     *sync.Map
+	pluginConfigs *sync.Map
+}
+
+func newExpectedResources() *expectedResources {
+	return &expectedResources{
+		routes:        new(sync.Map),
+		streamRoutes:  new(sync.Map),
+		upstreams:     new(sync.Map),
+		ssls:          new(sync.Map),
+		consumers:     new(sync.Map),
+		pluginConfigs: new(sync.Map),
+	}
+}
+
+// compareClusterResources finds out the objects in the APISIX cluster which
+// are not expected, warns them in log, and collects them if gc is true.
+func (c *Controller) compareClusterResources(ctx context.Context, clusterName string, expected *expectedResources, gc, dryRun bool) error {
+	var (
+		routeMapA6        = make(map[string]string)
+		streamRouteMapA6  = make(map[string]string)
+		upstreamMapA6     = make(map[string]string)
+		upstreamNamesA6   = make(map[string]string)
+		sslMapA6          = make(map[string]string)
+		consumerMapA6     = make(map[string]string)
+		pluginConfigMapA6 = make(map[string]string)
+	)
 	// 2.get all cache routes
-	if err := c.listRouteCache(ctx, routeMapA6); err != nil {
+	if err := c.listRouteCache(ctx, clusterName, routeMapA6); err != nil {
 		return err
 	}
-	if err := c.listStreamRouteCache(ctx, streamRouteMapA6); err != nil {
+	if err := c.listStreamRouteCache(ctx, clusterName, streamRouteMapA6); err != nil {
 		return err
 	}
-	if err := c.listUpstreamCache(ctx, upstreamMapA6); err != nil {
+	if err := c.listUpstreamCache(ctx, clusterName, upstreamMapA6, upstreamNamesA6); err != nil {
 		return err
 	}
-	if err := c.listSSLCache(ctx, sslMapA6); err != nil {
+	if err := c.listSSLCache(ctx, clusterName, sslMapA6); err != nil {
 		return err
 	}
-	if err := c.listConsumerCache(ctx, consumerMapA6); err != nil {
+	if err := c.listConsumerCache(ctx, clusterName, consumerMapA6); err != nil {
 		return err
 	}
-	if err := c.listPluginConfigCache(ctx, pluginConfigMapA6); err != nil {
+	if err := c.listPluginConfigCache(ctx, clusterName, pluginConfigMapA6); err != nil {
 		return err
 	}
 	// 3.compare
-	routeResult := findRedundant(routeMapA6, routeMapK8S)
-	streamRouteResult := findRedundant(streamRouteMapA6, streamRouteMapK8S)
-	upstreamResult := findRedundant(upstreamMapA6, upstreamMapK8S)
-	sslResult := findRedundant(sslMapA6, sslMapK8S)
-	consumerResult := findRedundant(consumerMapA6, consumerMapK8S)
-	pluginConfigResult := findRedundant(pluginConfigMapA6, pluginConfigMapK8S)
+	routeResult := findRedundant(routeMapA6, expected.routes)
+	streamRouteResult := findRedundant(streamRouteMapA6, expected.streamRoutes)
+	upstreamResult := findRedundantUpstreams(upstreamMapA6, upstreamNamesA6, expected.upstreams)
+	sslResult := findRedundant(sslMapA6, expected.ssls)
+	consumerResult := findRedundant(consumerMapA6, expected.consumers)
+	pluginConfigResult := findRedundant(pluginConfigMapA6, expected.pluginConfigs)
 	// 4.warn
-	warnRedundantResources(routeResult, "route")
-	warnRedundantResources(streamRouteResult, "streamRoute")
-	warnRedundantResources(upstreamResult, "upstream")
-	warnRedundantResources(sslResult, "ssl")
-	warnRedundantResources(consumerResult, "consumer")
-	warnRedundantResources(pluginConfigResult, "pluginConfig")
-
+	warnRedundantResources(clusterName, routeResult, "route")
+	warnRedundantResources(clusterName, streamRouteResult, "streamRoute")
+	warnRedundantResources(clusterName, upstreamResult, "upstream")
+	warnRedundantResources(clusterName, sslResult, "ssl")
+	warnRedundantResources(clusterName, consumerResult, "consumer")
+	warnRedundantResources(clusterName, pluginConfigResult, "pluginConfig")
+	// 5.gc
+	if !gc {
+		return nil
+	}
+	c.collectOrphanResources(ctx, clusterName, &orphanResources{
+		routes:        routeResult,
+		streamRoutes:  streamRouteResult,
+		upstreams:     upstreamResult,
+		ssls:          sslResult,
+		consumers:     consumerResult,
+		pluginConfigs: pluginConfigResult,
+	}, dryRun)
 	return nil
 }
 
+// orphanGCDryRun reports whether the orphan APISIX resources should only be
+// listed, which is forced once the comparison may miss some Kubernetes
+// resources.
+func (c *Controller) orphanGCDryRun(namespaces []string, incomplete bool) bool {
+	if c.cfg.OrphanGC == config.OrphanGCDryRun {
+		return true
+	}
+	if incomplete {
+		log.Warn("not all Kubernetes resources are translated, orphan APISIX resources will only be listed")
+		return true
+	}
+	// The namespaces may fail to be listed, nothing is compared then.
+	if len(namespaces) == 0 {
+		log.Warn("no namespace is watched, orphan APISIX resources will only be listed")
+		return true
+	}
+	if c.cfg.Kubernetes.EnableGatewayAPI {
+		log.Warn("Gateway API resources are not compared, orphan APISIX resources will only be listed")
+		return true
+	}
+	if len(c.cfg.Kubernetes.EnabledControllers) > 0 {
+		log.Warn("resources of the disabled controllers are not compared, orphan APISIX resources will only be listed")
+		return true
+	}
+	return false
+}
+
 // log warn
-func warnRedundantResources(resources map[string]string, t string) {
+func warnRedundantResources(clusterName string, resources map[string]string, t string) {
 	for k := range resources {
-		log.Warnf("%s: %s in APISIX but do not in declare yaml", t, k)
+		log.Warnf("%s: %s in APISIX cluster %s but do not in declare yaml", t, k, clusterName)
 	}
 }
 
-// findRedundant find redundant item which in src and do not in dest
+// findRedundant find redundant item which in src and do not in dest,
+// the value is kept so that the managed-by label of the APISIX object
+// can be checked later.
 func findRedundant(src map[string]string, dest *sync.Map) map[string]string {
 	result := make(map[string]string)
 	for k, v := range src {
@@ -274,73 +500,87 @@ func findRedundant(src map[string]string, dest *sync.Map) map[string]string {
 	return result
 }
 
-func (c *Controller) listRouteCache(ctx context.Context, routeMapA6 map[string]string) error {
-	routesInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).Route().List(ctx)
+// findRedundantUpstreams is like findRedundant, but the upstreams are
+// compared by the names. Their IDs are generated from the configs (see
+// apisixv1.ComposeUpstreamID), which are not translated for the comparison.
+func findRedundantUpstreams(src, names map[string]string, dest *sync.Map) map[string]string {
+	result := make(map[string]string)
+	for k, v := range src {
+		if _, ok := dest.Load(names[k]); !ok {
+			result[k] = v
+		}
+	}
+	return result
+}
+
+func (c *Controller) listRouteCache(ctx context.Context, clusterName string, routeMapA6 map[string]string) error {
+	routesInA6, err := c.apisix.Cluster(clusterName).Route().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, ra := range routesInA6 {
-			routeMapA6[ra.ID] = ra.ID
+			routeMapA6[ra.ID] = ra.Labels[apisixv1.ManagedByLabel]
 		}
 	}
 	return nil
 }
 
-func (c *Controller) listStreamRouteCache(ctx context.Context, streamRouteMapA6 map[string]string) error {
-	streamRoutesInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).StreamRoute().List(ctx)
+func (c *Controller) listStreamRouteCache(ctx context.Context, clusterName string, streamRouteMapA6 map[string]string) error {
+	streamRoutesInA6, err := c.apisix.Cluster(clusterName).StreamRoute().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, ra := range streamRoutesInA6 {
-			streamRouteMapA6[ra.ID] = ra.ID
+			streamRouteMapA6[ra.ID] = ra.Labels[apisixv1.ManagedByLabel]
 		}
 	}
 	return nil
 }
 
-func (c *Controller) listUpstreamCache(ctx context.Context, upstreamMapA6 map[string]string) error {
-	upstreamsInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).Upstream().List(ctx)
+func (c *Controller) listUpstreamCache(ctx context.Context, clusterName string, upstreamMapA6, upstreamNamesA6 map[string]string) error {
+	upstreamsInA6, err := c.apisix.Cluster(clusterName).Upstream().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, ra := range upstreamsInA6 {
-			upstreamMapA6[ra.ID] = ra.ID
+			upstreamMapA6[ra.ID] = ra.Labels[apisixv1.ManagedByLabel]
+			upstreamNamesA6[ra.ID] = ra.Name
 		}
 	}
 	return nil
 }
 
-func (c *Controller) listSSLCache(ctx context.Context, sslMapA6 map[string]string) error {
-	sslInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).SSL().List(ctx)
+func (c *Controller) listSSLCache(ctx context.Context, clusterName string, sslMapA6 map[string]string) error {
+	sslInA6, err := c.apisix.Cluster(clusterName).SSL().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, s := range sslInA6 {
-			sslMapA6[s.ID] = s.ID
+			sslMapA6[s.ID] = s.Labels[apisixv1.ManagedByLabel]
 		}
 	}
 	return nil
 }
 
-func (c *Controller) listConsumerCache(ctx context.Context, consumerMapA6 map[string]string) error {
-	consumerInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).Consumer().List(ctx)
+func (c *Controller) listConsumerCache(ctx context.Context, clusterName string, consumerMapA6 map[string]string) error {
+	consumerInA6, err := c.apisix.Cluster(clusterName).Consumer().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, con := range consumerInA6 {
-			consumerMapA6[con.Username] = con.Username
+			consumerMapA6[con.Username] = con.Labels[apisixv1.ManagedByLabel]
 		}
 	}
 	return nil
 }
 
-func (c *Controller) listPluginConfigCache(ctx context.Context, pluginConfigMapA6 map[string]string) error {
-	pluginConfigInA6, err := c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).PluginConfig().List(ctx)
+func (c *Controller) listPluginConfigCache(ctx context.Context, clusterName string, pluginConfigMapA6 map[string]string) error {
+	pluginConfigInA6, err := c.apisix.Cluster(clusterName).PluginConfig().List(ctx)
 	if err != nil {
 		return err
 	} else {
 		for _, ra := range pluginConfigInA6 {
-			pluginConfigMapA6[ra.ID] = ra.ID
+			pluginConfigMapA6[ra.ID] = ra.Labels[apisixv1.ManagedByLabel]
 		}
 	}
 	return nil
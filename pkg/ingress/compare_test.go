@@ -0,0 +1,252 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type fakeCompareAPISIX struct {
+	apisix.APISIX
+	cluster *fakeCompareCluster
+	// clusters are the clusters besides the default one.
+	clusters map[string]*fakeCompareCluster
+}
+
+func (f *fakeCompareAPISIX) Cluster(name string) apisix.Cluster {
+	if cluster, ok := f.clusters[name]; ok {
+		return cluster
+	}
+	return f.cluster
+}
+
+// fakeCompareCluster holds SSLs only, the other objects are always empty.
+type fakeCompareCluster struct {
+	apisix.Cluster
+	ssls        []*apisixv1.Ssl
+	deletedSSLs []string
+}
+
+func (f *fakeCompareCluster) Route() apisix.Route               { return &fakeCompareRoute{} }
+func (f *fakeCompareCluster) StreamRoute() apisix.StreamRoute   { return &fakeCompareStreamRoute{} }
+func (f *fakeCompareCluster) Upstream() apisix.Upstream         { return &fakeCompareUpstream{} }
+func (f *fakeCompareCluster) Consumer() apisix.Consumer         { return &fakeCompareConsumer{} }
+func (f *fakeCompareCluster) PluginConfig() apisix.PluginConfig { return &fakeComparePluginConfig{} }
+func (f *fakeCompareCluster) SSL() apisix.SSL                   { return &fakeCompareSSL{cluster: f} }
+
+type fakeCompareRoute struct{ apisix.Route }
+
+func (f *fakeCompareRoute) List(context.Context) ([]*apisixv1.Route, error) { return nil, nil }
+
+type fakeCompareStreamRoute struct{ apisix.StreamRoute }
+
+func (f *fakeCompareStreamRoute) List(context.Context) ([]*apisixv1.StreamRoute, error) {
+	return nil, nil
+}
+
+type fakeCompareUpstream struct{ apisix.Upstream }
+
+func (f *fakeCompareUpstream) List(context.Context) ([]*apisixv1.Upstream, error) { return nil, nil }
+
+type fakeCompareConsumer struct{ apisix.Consumer }
+
+func (f *fakeCompareConsumer) List(context.Context) ([]*apisixv1.Consumer, error) { return nil, nil }
+
+type fakeComparePluginConfig struct{ apisix.PluginConfig }
+
+func (f *fakeComparePluginConfig) List(context.Context) ([]*apisixv1.PluginConfig, error) {
+	return nil, nil
+}
+
+type fakeCompareSSL struct {
+	apisix.SSL
+	cluster *fakeCompareCluster
+}
+
+func (f *fakeCompareSSL) List(context.Context) ([]*apisixv1.Ssl, error) {
+	return f.cluster.ssls, nil
+}
+
+func (f *fakeCompareSSL) Delete(_ context.Context, ssl *apisixv1.Ssl) error {
+	f.cluster.deletedSSLs = append(f.cluster.deletedSSLs, ssl.ID)
+	return nil
+}
+
+func TestCompareResourcesKeepsIngressSSL(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"example.com"},
+					SecretName: "foo-tls",
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-tls",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"cert": []byte("cert"),
+			"key":  []byte("key"),
+		},
+	}
+	client := fake.NewSimpleClientset(ing)
+	secretInformer := informers.NewSharedInformerFactory(client, 0).Core().V1().Secrets()
+	assert.Nil(t, secretInformer.Informer().GetIndexer().Add(secret))
+
+	ingressSSLID := id.GenID(id.SSL, translation.ComposeIngressSSLName("default", "foo"))
+	cluster := &fakeCompareCluster{
+		ssls: []*apisixv1.Ssl{
+			{
+				ID:     ingressSSLID,
+				Labels: map[string]string{apisixv1.ManagedByLabel: apisixv1.ManagedBy()},
+			},
+			{
+				ID:     "orphan",
+				Labels: map[string]string{apisixv1.ManagedByLabel: apisixv1.ManagedBy()},
+			},
+		},
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.OrphanGC = config.OrphanGCEnabled
+	c := &Controller{
+		cfg:               cfg,
+		namespaceProvider: namespace.NewMockWatchingProvider([]string{"default"}),
+		kubeClient: &kube.KubeClient{
+			Client:       client,
+			APISIXClient: fakeapisix.NewSimpleClientset(),
+		},
+		translator: translation.NewTranslator(&translation.TranslatorOptions{
+			SecretLister: secretInformer.Lister(),
+		}),
+		apisix: &fakeCompareAPISIX{cluster: cluster},
+	}
+
+	assert.Nil(t, c.CompareResources(context.Background()))
+	// The SSL of the Ingress TLS is kept, only the orphan is collected.
+	assert.Equal(t, []string{"orphan"}, cluster.deletedSSLs)
+}
+
+func TestCompareResourcesPerCluster(t *testing.T) {
+	// The Ingress is moved from the default cluster to cluster2.
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "default",
+			Annotations: map[string]string{annotations.AnnotationsAPISIXCluster: "cluster2"},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{
+					Hosts:      []string{"example.com"},
+					SecretName: "foo-tls",
+				},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo-tls",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"cert": []byte("cert"),
+			"key":  []byte("key"),
+		},
+	}
+	client := fake.NewSimpleClientset(ing)
+	secretInformer := informers.NewSharedInformerFactory(client, 0).Core().V1().Secrets()
+	assert.Nil(t, secretInformer.Informer().GetIndexer().Add(secret))
+
+	ingressSSL := &apisixv1.Ssl{
+		ID:     id.GenID(id.SSL, translation.ComposeIngressSSLName("default", "foo")),
+		Labels: map[string]string{apisixv1.ManagedByLabel: apisixv1.ManagedBy()},
+	}
+	orphan := &apisixv1.Ssl{
+		ID:     "orphan",
+		Labels: map[string]string{apisixv1.ManagedByLabel: apisixv1.ManagedBy()},
+	}
+	defaultCluster := &fakeCompareCluster{ssls: []*apisixv1.Ssl{ingressSSL}}
+	cluster2 := &fakeCompareCluster{ssls: []*apisixv1.Ssl{ingressSSL, orphan}}
+	cfg := config.NewDefaultConfig()
+	cfg.OrphanGC = config.OrphanGCEnabled
+	cfg.APISIX.Clusters = []config.APISIXClusterConfig{{Name: "cluster2"}}
+	c := &Controller{
+		cfg:               cfg,
+		namespaceProvider: namespace.NewMockWatchingProvider([]string{"default"}),
+		kubeClient: &kube.KubeClient{
+			Client:       client,
+			APISIXClient: fakeapisix.NewSimpleClientset(),
+		},
+		translator: translation.NewTranslator(&translation.TranslatorOptions{
+			SecretLister: secretInformer.Lister(),
+		}),
+		apisix: &fakeCompareAPISIX{
+			cluster:  defaultCluster,
+			clusters: map[string]*fakeCompareCluster{"cluster2": cluster2},
+		},
+	}
+
+	assert.Nil(t, c.CompareResources(context.Background()))
+	// The SSL left in the default cluster is an orphan there.
+	assert.Equal(t, []string{ingressSSL.ID}, defaultCluster.deletedSSLs)
+	// The non-default cluster is compared too.
+	assert.Equal(t, []string{"orphan"}, cluster2.deletedSSLs)
+}
+
+func TestFindRedundantUpstreams(t *testing.T) {
+	upstreamMapA6 := map[string]string{
+		"1": apisixv1.ManagedBy(),
+		"2": apisixv1.ManagedBy(),
+		"3": apisixv1.ManagedBy(),
+	}
+	upstreamNamesA6 := map[string]string{
+		"1": "default_foo_80",
+		// The upstream of the same Service port with another config.
+		"2": "default_foo_80",
+		"3": "default_bar_80",
+	}
+	upstreamMapK8S := new(sync.Map)
+	upstreamMapK8S.Store("default_foo_80", id.GenID(id.Upstream, "default_foo_80"))
+	assert.Equal(t, map[string]string{
+		"3": apisixv1.ManagedBy(),
+	}, findRedundantUpstreams(upstreamMapA6, upstreamNamesA6, upstreamMapK8S))
+}
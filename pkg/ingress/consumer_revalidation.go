@@ -0,0 +1,235 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xeipuuv/gojsonschema"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+const (
+	// _conditionTypePluginSchemaValid is the condition type which reports
+	// whether the resource is still valid against the latest plugin schemas.
+	_conditionTypePluginSchemaValid = "PluginSchemaValid"
+	// _resourceSchemaInvalid is used when a resource becomes invalid against
+	// the latest plugin schemas.
+	_resourceSchemaInvalid = "ResourceSchemaInvalid"
+	// _resourceSchemaValid is used when an invalid resource becomes valid again.
+	_resourceSchemaValid = "ResourceSchemaValid"
+)
+
+// consumerRevalidation keeps the states across the re-validation passes of
+// ApisixConsumers, it's only accessed by the re-validation loop.
+type consumerRevalidation struct {
+	// schemas records the plugin schemas seen in the last pass.
+	// type: Map<PluginName, SchemaContent>
+	schemas map[string]string
+	// invalid records the ApisixConsumers which are invalid in the last pass.
+	// type: Map<ApisixConsumerKey, ErrorMessage>
+	invalid map[string]string
+}
+
+func newConsumerRevalidation() *consumerRevalidation {
+	return &consumerRevalidation{
+		schemas: make(map[string]string),
+		invalid: make(map[string]string),
+	}
+}
+
+// revalidateLoop re-validates the ApisixConsumers regularly until ctx is done.
+func (c *apisixConsumerController) revalidateLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.revalidate(ctx)
+		}
+	}
+}
+
+// revalidate fetches the schemas of the auth plugins used by ApisixConsumers,
+// once the schema of a plugin changes, the ApisixConsumers using it will be
+// validated again, the newly invalid (and the recovered) ones are reported
+// by events and the PluginSchemaValid condition.
+func (c *apisixConsumerController) revalidate(ctx context.Context) {
+	schemaClient := c.controller.apisix.Cluster(c.controller.cfg.APISIX.DefaultClusterName).Schema()
+	schemas := make(map[string]string)
+	getSchema := func(name string) (string, bool) {
+		if content, ok := schemas[name]; ok {
+			return content, true
+		}
+		schema, err := schemaClient.GetPluginSchema(ctx, name)
+		if err != nil {
+			log.Warnw("failed to get plugin schema, skip re-validating the consumers using it",
+				zap.String("plugin", name),
+				zap.Error(err),
+			)
+			return "", false
+		}
+		schemas[name] = schema.Content
+		return schema.Content, true
+	}
+
+	invalid := make(map[string]string)
+	for _, obj := range c.controller.apisixConsumerInformer.GetIndexer().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.controller.isWatchingNamespace(key) {
+			continue
+		}
+		ac, err := kube.NewApisixConsumer(obj)
+		if err != nil {
+			continue
+		}
+		consumer, err := c.translateConsumer(ac)
+		if err != nil {
+			// Translation errors are reported by the sync.
+			continue
+		}
+
+		changed := false
+		for name := range consumer.Plugins {
+			content, ok := getSchema(name)
+			if ok && c.revalidation.schemas[name] != content {
+				changed = true
+			}
+		}
+		if !changed {
+			if msg, ok := c.revalidation.invalid[key]; ok {
+				invalid[key] = msg
+			}
+			continue
+		}
+
+		err = validateConsumerPlugins(consumer, schemas)
+		_, wasInvalid := c.revalidation.invalid[key]
+		if err != nil {
+			invalid[key] = err.Error()
+			if !wasInvalid {
+				log.Warnw("ApisixConsumer is invalid against the latest plugin schema",
+					zap.String("key", key),
+					zap.Error(err),
+				)
+				c.recordPluginSchemaValidity(ac, err)
+			}
+		} else if wasInvalid {
+			c.recordPluginSchemaValidity(ac, nil)
+		}
+	}
+
+	for name, content := range schemas {
+		c.revalidation.schemas[name] = content
+	}
+	c.revalidation.invalid = invalid
+}
+
+func (c *apisixConsumerController) translateConsumer(ac kube.ApisixConsumer) (*apisixv1.Consumer, error) {
+	switch ac.GroupVersion() {
+	case config.ApisixV2beta3:
+		return c.controller.translator.TranslateApisixConsumerV2beta3(ac.V2beta3())
+	case config.ApisixV2:
+		return c.controller.translator.TranslateApisixConsumerV2(ac.V2())
+	default:
+		return nil, fmt.Errorf("unsupported ApisixConsumer group version %s", ac.GroupVersion())
+	}
+}
+
+// validateConsumerPlugins validates the plugins of consumer against the schemas,
+// plugins without schema are skipped.
+func validateConsumerPlugins(consumer *apisixv1.Consumer, schemas map[string]string) error {
+	var msgs []string
+	for name, cfg := range consumer.Plugins {
+		schema, ok := schemas[name]
+		if !ok {
+			continue
+		}
+		result, err := gojsonschema.Validate(gojsonschema.NewStringLoader(schema), gojsonschema.NewGoLoader(cfg))
+		if err != nil {
+			msgs = append(msgs, fmt.Sprintf("plugin %s: %s", name, err))
+			continue
+		}
+		for _, desc := range result.Errors() {
+			msgs = append(msgs, fmt.Sprintf("plugin %s: %s", name, desc))
+		}
+	}
+	if len(msgs) > 0 {
+		return errors.New(strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+// recordPluginSchemaValidity reports the validity of the ApisixConsumer against
+// the latest plugin schemas by event and status condition.
+func (c *apisixConsumerController) recordPluginSchemaValidity(ac kube.ApisixConsumer, err error) {
+	condition := metav1.Condition{
+		Type:    _conditionTypePluginSchemaValid,
+		Status:  metav1.ConditionTrue,
+		Reason:  _resourceSchemaValid,
+		Message: "valid against the latest plugin schemas",
+	}
+	eventType := corev1.EventTypeNormal
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = _resourceSchemaInvalid
+		condition.Message = err.Error()
+		eventType = corev1.EventTypeWarning
+	}
+
+	var (
+		object       metav1.Object
+		errRecord    error
+		apisixClient = c.controller.kubeClient.APISIXClient
+	)
+	switch ac.GroupVersion() {
+	case config.ApisixV2beta3:
+		v := ac.V2beta3().DeepCopy()
+		object = v
+		condition.ObservedGeneration = v.GetGeneration()
+		c.controller.recorderEventS(v, eventType, condition.Reason, condition.Message)
+		meta.SetStatusCondition(&v.Status.Conditions, condition)
+		_, errRecord = apisixClient.ApisixV2beta3().ApisixConsumers(v.Namespace).UpdateStatus(context.TODO(), v, metav1.UpdateOptions{})
+	case config.ApisixV2:
+		v := ac.V2().DeepCopy()
+		object = v
+		condition.ObservedGeneration = v.GetGeneration()
+		c.controller.recorderEventS(v, eventType, condition.Reason, condition.Message)
+		meta.SetStatusCondition(&v.Status.Conditions, condition)
+		_, errRecord = apisixClient.ApisixV2().ApisixConsumers(v.Namespace).UpdateStatus(context.TODO(), v, metav1.UpdateOptions{})
+	}
+	if errRecord != nil {
+		log.Errorw("failed to record status change for ApisixConsumer",
+			zap.Error(errRecord),
+			zap.String("name", object.GetName()),
+			zap.String("namespace", object.GetNamespace()),
+		)
+	}
+}
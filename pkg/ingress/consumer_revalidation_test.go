@@ -0,0 +1,140 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type fakeSchemaAPISIX struct {
+	apisix.APISIX
+	cluster *fakeSchemaCluster
+}
+
+func (f *fakeSchemaAPISIX) Cluster(string) apisix.Cluster {
+	return f.cluster
+}
+
+type fakeSchemaCluster struct {
+	apisix.Cluster
+	schema *fakeSchema
+}
+
+func (f *fakeSchemaCluster) Schema() apisix.Schema {
+	return f.schema
+}
+
+type fakeSchema struct {
+	apisix.Schema
+	plugins map[string]string
+}
+
+func (f *fakeSchema) GetPluginSchema(_ context.Context, name string) (*apisixv1.Schema, error) {
+	return &apisixv1.Schema{
+		Name:    "plugins/" + name,
+		Content: f.plugins[name],
+	}, nil
+}
+
+func TestApisixConsumerRevalidation(t *testing.T) {
+	ac := &configv2.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "jack",
+			Namespace:  "default",
+			Generation: 1,
+		},
+		Spec: configv2.ApisixConsumerSpec{
+			AuthParameter: configv2.ApisixConsumerAuthParameter{
+				KeyAuth: &configv2.ApisixConsumerKeyAuth{
+					Value: &configv2.ApisixConsumerKeyAuthValue{Key: "short"},
+				},
+			},
+		},
+	}
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv2.ApisixConsumer{}, 0, cache.Indexers{})
+	assert.Nil(t, informer.GetIndexer().Add(ac))
+
+	apisixClient := fakeapisix.NewSimpleClientset(ac)
+	schema := &fakeSchema{
+		plugins: map[string]string{
+			"key-auth": `{"type": "object", "properties": {"key": {"type": "string"}}}`,
+		},
+	}
+	recorder := record.NewFakeRecorder(10)
+	cfg := config.NewDefaultConfig()
+	c := &apisixConsumerController{
+		controller: &Controller{
+			cfg:                    cfg,
+			apisix:                 &fakeSchemaAPISIX{cluster: &fakeSchemaCluster{schema: schema}},
+			recorder:               recorder,
+			kubeClient:             &kube.KubeClient{APISIXClient: apisixClient},
+			translator:             translation.NewTranslator(&translation.TranslatorOptions{}),
+			namespaceProvider:      namespace.NewMockWatchingProvider([]string{"default"}),
+			apisixConsumerInformer: informer,
+		},
+		revalidation: newConsumerRevalidation(),
+	}
+
+	getCondition := func() *metav1.Condition {
+		obj, err := apisixClient.ApisixV2().ApisixConsumers("default").Get(context.Background(), "jack", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return meta.FindStatusCondition(obj.Status.Conditions, _conditionTypePluginSchemaValid)
+	}
+
+	// The consumer is valid, nothing is reported.
+	c.revalidate(context.Background())
+	assert.Len(t, recorder.Events, 0)
+	assert.Nil(t, getCondition())
+
+	// The schema changes and the key becomes too short.
+	schema.plugins["key-auth"] = `{"type": "object", "properties": {"key": {"type": "string", "minLength": 8}}}`
+	c.revalidate(context.Background())
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Warning ResourceSchemaInvalid plugin key-auth: key")
+	cond := getCondition()
+	assert.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionFalse, cond.Status)
+	assert.Equal(t, _resourceSchemaInvalid, cond.Reason)
+	assert.Equal(t, int64(1), cond.ObservedGeneration)
+
+	// Nothing changes, the consumer is not reported again.
+	c.revalidate(context.Background())
+	assert.Len(t, recorder.Events, 0)
+
+	// The schema is relaxed, the consumer recovers.
+	schema.plugins["key-auth"] = `{"type": "object", "properties": {"key": {"type": "string", "minLength": 1}}}`
+	c.revalidate(context.Background())
+	assert.Len(t, recorder.Events, 1)
+	assert.Contains(t, <-recorder.Events, "Normal ResourceSchemaValid")
+	cond = getCondition()
+	assert.NotNil(t, cond)
+	assert.Equal(t, metav1.ConditionTrue, cond.Status)
+}
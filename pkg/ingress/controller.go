@@ -17,7 +17,10 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"math/rand"
 	"os"
+	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,15 +39,17 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	"github.com/apache/apisix-ingress-controller/pkg/api"
+	"github.com/apache/apisix-ingress-controller/pkg/api/validation"
 	"github.com/apache/apisix-ingress-controller/pkg/apisix"
-	apisixcache "github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
 	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/gateway"
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	apisixscheme "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/scheme"
+	listersv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2"
 	listersv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2beta3"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
@@ -62,6 +67,12 @@ const (
 	_messageResourceSynced = "%s synced successfully"
 	// _resourceSyncAborted is used when a resource synced failed
 	_resourceSyncAborted = "ResourceSyncAborted"
+	// _resourceSyncPartial is used when a resource was only partially
+	// accepted by APISIX
+	_resourceSyncPartial = "ResourceSyncPartial"
+	// _resourceSyncRejected is used when a resource was rejected by the
+	// controller due to the route count limit
+	_resourceSyncRejected = "ResourceSyncRejected"
 	// _messageResourceFailed is used to report error
 	_messageResourceFailed = "%s synced failed, with error: %s"
 	// minimum interval for ingress sync to APISIX
@@ -79,6 +90,8 @@ type Controller struct {
 	apiServer        *api.Server
 	MetricsCollector metrics.Collector
 	kubeClient       *kube.KubeClient
+	// auditLogger records the writes to APISIX, nil means disabled.
+	auditLogger apisix.AuditLogger
 	// recorder event
 	recorder record.EventRecorder
 	// this map enrolls which ApisixTls objects refer to a Kubernetes
@@ -86,10 +99,53 @@ type Controller struct {
 	// type: Map<SecretKey, Map<ApisixTlsKey, ApisixTls>>
 	// SecretKey is `namespace_name`, ApisixTlsKey is kube style meta key: `namespace/name`
 	secretSSLMap *sync.Map
+	// resourceSyncLock protects the resource sync settings in cfg which
+	// can be changed by Reload.
+	resourceSyncLock sync.RWMutex
+	// resourceSyncReload notifies the resource sync loop that its
+	// settings are reloaded.
+	resourceSyncReload chan struct{}
+	// manualResync collects the requests to resync all the resources
+	// right away.
+	manualResync *manualResync
+	// legacyIDsMigrated is set once no APISIX object with legacy ID is
+	// left, it's only accessed by the resource sync loop.
+	legacyIDsMigrated bool
+	// routeLimitLocks serializes the route creations of each cluster when
+	// the route count limit is enabled, so that concurrent syncs cannot
+	// exceed it.
+	// type: Map<cluster name, *sync.Mutex>
+	routeLimitLocks sync.Map
+	// deprecationWarned records the generation of objects that the
+	// deprecation warning has been emitted for.
+	// type: Map<kind/namespace/name, int64>
+	deprecationWarned sync.Map
+	// workerHeartbeats tracks the workers of controllers so that the
+	// stuck ones can be detected.
+	workerHeartbeats *workerHeartbeats
+	// workerPool holds the number of workers of each resource controller.
+	workerPool *workerPool
+	// initialSync tracks the initial sync of resources, the controller
+	// reports not ready until it's done.
+	initialSync *initialSyncTracker
+	// leaderResync spreads the initial events after the leader acquisition,
+	// it's nil if the ramp is disabled.
+	leaderResync *leaderResync
+	// endpointBatcher batches the upstream nodes updates caused by endpoints
+	// changes, it's nil if batching is disabled.
+	endpointBatcher *endpointBatcher
+	// pausedDeletes are the deletions of the resources in the paused
+	// namespaces, they are enqueued once the reconciliation is resumed.
+	// type: Map<namespace, []func()>
+	pausedDeletes     map[string][]func()
+	pausedDeletesLock sync.Mutex
 
 	// leaderContextCancelFunc will be called when apisix-ingress-controller
 	// decides to give up its leader role.
 	leaderContextCancelFunc context.CancelFunc
+	// leaderRunning tracks the leader routine, so that the leader states are
+	// cleaned up only after the workqueues are drained.
+	leaderRunning sync.WaitGroup
 
 	// common informers and listers
 	podInformer                 cache.SharedIndexInformer
@@ -102,6 +158,8 @@ type Controller struct {
 	ingressInformer             cache.SharedIndexInformer
 	secretInformer              cache.SharedIndexInformer
 	secretLister                listerscorev1.SecretLister
+	configMapInformer           cache.SharedIndexInformer
+	configMapLister             listerscorev1.ConfigMapLister
 	apisixUpstreamInformer      cache.SharedIndexInformer
 	apisixUpstreamLister        listersv2beta3.ApisixUpstreamLister
 	apisixRouteLister           kube.ApisixRouteLister
@@ -114,6 +172,10 @@ type Controller struct {
 	apisixConsumerLister        kube.ApisixConsumerLister
 	apisixPluginConfigInformer  cache.SharedIndexInformer
 	apisixPluginConfigLister    kube.ApisixPluginConfigLister
+	// apisixRateLimitPolicyInformer and apisixRateLimitPolicyLister are
+	// nil unless ApisixRoute v2 is watched.
+	apisixRateLimitPolicyInformer cache.SharedIndexInformer
+	apisixRateLimitPolicyLister   listersv2.ApisixRateLimitPolicyLister
 
 	// resource controllers
 	podController           *podController
@@ -121,6 +183,7 @@ type Controller struct {
 	endpointSliceController *endpointSliceController
 	ingressController       *ingressController
 	secretController        *secretController
+	serviceController       *serviceController
 
 	namespaceProvider namespace.WatchingProvider
 	gatewayProvider   *gateway.Provider
@@ -140,6 +203,10 @@ func NewController(cfg *config.Config) (*Controller, error) {
 	if podNamespace == "" {
 		podNamespace = "default"
 	}
+	// The IDs are generated everywhere, so the prefix is set before any
+	// object is translated.
+	id.SetPrefix(cfg.APISIX.IDPrefix)
+
 	client, err := apisix.NewClient()
 	if err != nil {
 		return nil, err
@@ -154,6 +221,17 @@ func NewController(cfg *config.Config) (*Controller, error) {
 	if err != nil {
 		return nil, err
 	}
+	// the admission webhooks check the references of objects with it.
+	validation.SetReferenceClient(kubeClient)
+	validation.SetAllowServerlessFunctions(cfg.Kubernetes.AllowServerlessFunctions)
+
+	var auditLogger apisix.AuditLogger
+	if cfg.AuditLogOutput != "" {
+		auditLogger, err = apisix.NewAuditLogger(cfg.AuditLogOutput)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	// recorder
 	utilruntime.Must(apisixscheme.AddToScheme(scheme.Scheme))
@@ -168,11 +246,21 @@ func NewController(cfg *config.Config) (*Controller, error) {
 		apisix:           client,
 		MetricsCollector: metrics.NewPrometheusCollector(),
 		kubeClient:       kubeClient,
+		auditLogger:      auditLogger,
 		secretSSLMap:     new(sync.Map),
+		workerHeartbeats: newWorkerHeartbeats(),
+		workerPool:       newWorkerPool(cfg.Workers),
 		recorder:         eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: _component}),
 
-		podCache: types.NewPodCache(),
+		podCache:           types.NewPodCache(),
+		resourceSyncReload: make(chan struct{}, 1),
+		manualResync:       newManualResync(),
 	}
+	c.initialSync = newInitialSyncTracker(enabledInitialSyncKinds(&cfg.Kubernetes), func() {
+		c.apiServer.ReadinessState.Lock()
+		defer c.apiServer.ReadinessState.Unlock()
+		c.apiServer.ReadinessState.Ready = true
+	})
 	return c, nil
 }
 
@@ -189,6 +277,11 @@ func (c *Controller) initWhenStartLeading() {
 	kubeFactory := c.kubeClient.NewSharedIndexInformerFactory()
 	apisixFactory := c.kubeClient.NewAPISIXSharedIndexInformerFactory()
 
+	// The deferred deletions refer to the workqueues of the last term.
+	c.pausedDeletesLock.Lock()
+	c.pausedDeletes = make(map[string][]func())
+	c.pausedDeletesLock.Unlock()
+
 	c.podLister = kubeFactory.Core().V1().Pods().Lister()
 	c.epLister, c.epInformer = kube.NewEndpointListerAndInformer(kubeFactory, c.cfg.Kubernetes.WatchEndpointSlices)
 	c.svcLister = kubeFactory.Core().V1().Services().Lister()
@@ -198,6 +291,7 @@ func (c *Controller) initWhenStartLeading() {
 		kubeFactory.Extensions().V1beta1().Ingresses().Lister(),
 	)
 	c.secretLister = kubeFactory.Core().V1().Secrets().Lister()
+	c.configMapLister = kubeFactory.Core().V1().ConfigMaps().Lister()
 	c.apisixRouteLister = kube.NewApisixRouteLister(
 		apisixFactory.Apisix().V2beta2().ApisixRoutes().Lister(),
 		apisixFactory.Apisix().V2beta3().ApisixRoutes().Lister(),
@@ -220,6 +314,9 @@ func (c *Controller) initWhenStartLeading() {
 		apisixFactory.Apisix().V2beta3().ApisixPluginConfigs().Lister(),
 		apisixFactory.Apisix().V2().ApisixPluginConfigs().Lister(),
 	)
+	if c.cfg.Kubernetes.ApisixRouteVersion == config.ApisixRouteV2 {
+		c.apisixRateLimitPolicyLister = apisixFactory.Apisix().V2().ApisixRateLimitPolicies().Lister()
+	}
 
 	c.translator = translation.NewTranslator(&translation.TranslatorOptions{
 		PodCache:             c.podCache,
@@ -228,7 +325,22 @@ func (c *Controller) initWhenStartLeading() {
 		ServiceLister:        c.svcLister,
 		ApisixUpstreamLister: c.apisixUpstreamLister,
 		SecretLister:         c.secretLister,
+		ConfigMapLister:      c.configMapLister,
 		UseEndpointSlices:    c.cfg.Kubernetes.WatchEndpointSlices,
+
+		ApisixRateLimitPolicyLister: c.apisixRateLimitPolicyLister,
+		ApisixPluginConfigLister:    c.apisixPluginConfigLister,
+		ApisixPluginConfigVersion:   c.cfg.Kubernetes.ApisixPluginConfigVersion,
+		ApisixConsumerLister:        c.apisixConsumerLister,
+		ApisixConsumerVersion:       c.cfg.Kubernetes.ApisixConsumerVersion,
+
+		AllowCrossNamespacePluginConfig: c.cfg.Kubernetes.AllowCrossNamespacePluginConfig,
+		AllowServerlessFunctions:        c.cfg.Kubernetes.AllowServerlessFunctions,
+		RouteLabelKeys:                  c.cfg.Kubernetes.RouteLabelKeys,
+		RouteLabelPrefix:                c.cfg.Kubernetes.RouteLabelPrefix,
+		ServiceUpstreamAnnotations:      c.cfg.Kubernetes.ServiceUpstreamAnnotations,
+		ClusterDomain:                   c.cfg.Kubernetes.ClusterDomain,
+		DiscoveryTypes:                  c.cfg.APISIX.DiscoveryTypes,
 	})
 
 	if c.cfg.Kubernetes.IngressVersion == config.IngressNetworkingV1 {
@@ -293,28 +405,103 @@ func (c *Controller) initWhenStartLeading() {
 	c.apisixUpstreamInformer = apisixFactory.Apisix().V2beta3().ApisixUpstreams().Informer()
 	c.apisixClusterConfigInformer = apisixClusterConfigInformer
 	c.secretInformer = kubeFactory.Core().V1().Secrets().Informer()
+	c.configMapInformer = kubeFactory.Core().V1().ConfigMaps().Informer()
 	c.apisixTlsInformer = apisixTlsInformer
 	c.apisixConsumerInformer = apisixConsumerInformer
 	c.apisixPluginConfigInformer = apisixPluginConfigInformer
+	if c.cfg.Kubernetes.ApisixRouteVersion == config.ApisixRouteV2 {
+		c.apisixRateLimitPolicyInformer = apisixFactory.Apisix().V2().ApisixRateLimitPolicies().Informer()
+	}
 
 	if c.cfg.Kubernetes.WatchEndpointSlices {
 		c.endpointSliceController = c.newEndpointSliceController()
 	} else {
 		c.endpointsController = c.newEndpointsController()
 	}
+	if c.cfg.EndpointBatchWindow.Duration > 0 {
+		c.endpointBatcher = newEndpointBatcher(c.cfg.EndpointBatchWindow.Duration, c.cfg.EndpointBatchMax,
+			func(ctx context.Context, u *upstreamNodesUpdate) error {
+				return c.syncUpstreamNodesChangeToCluster(ctx, u.cluster, u.nodes, u.upstream, u.clusterIP)
+			},
+		)
+	}
 	c.podController = c.newPodController()
-	c.apisixUpstreamController = c.newApisixUpstreamController()
-	c.ingressController = c.newIngressController()
-	c.apisixRouteController = c.newApisixRouteController()
-	c.apisixClusterConfigController = c.newApisixClusterConfigController()
-	c.apisixTlsController = c.newApisixTlsController()
 	c.secretController = c.newSecretController()
-	c.apisixConsumerController = c.newApisixConsumerController()
-	c.apisixPluginConfigController = c.newApisixPluginConfigController()
+	if len(c.cfg.Kubernetes.ServiceUpstreamAnnotations) > 0 {
+		c.serviceController = c.newServiceController()
+	}
+	// The informers of the disabled controllers are kept for the listers,
+	// but they are neither started nor have event handlers.
+	kc := &c.cfg.Kubernetes
+	if kc.IsControllerEnabled(config.ControllerApisixUpstream) {
+		c.apisixUpstreamController = c.newApisixUpstreamController()
+	}
+	if kc.IsControllerEnabled(config.ControllerIngress) {
+		c.ingressController = c.newIngressController()
+	}
+	if kc.IsControllerEnabled(config.ControllerApisixRoute) {
+		c.apisixRouteController = c.newApisixRouteController()
+	}
+	if kc.IsControllerEnabled(config.ControllerApisixClusterConfig) {
+		c.apisixClusterConfigController = c.newApisixClusterConfigController()
+	}
+	if kc.IsControllerEnabled(config.ControllerApisixTls) {
+		c.apisixTlsController = c.newApisixTlsController()
+	}
+	if kc.IsControllerEnabled(config.ControllerApisixConsumer) {
+		c.apisixConsumerController = c.newApisixConsumerController()
+	}
+	if kc.IsControllerEnabled(config.ControllerApisixPluginConfig) {
+		c.apisixPluginConfigController = c.newApisixPluginConfigController()
+	}
 }
 
 func (c *Controller) syncManifests(ctx context.Context, added, updated, deleted *utils.Manifest) error {
-	return utils.SyncManifests(ctx, c.apisix, c.cfg.APISIX.DefaultClusterName, added, updated, deleted)
+	return c.syncManifestsToCluster(ctx, c.cfg.APISIX.DefaultClusterName, added, updated, deleted)
+}
+
+// syncManifestsToCluster pushes the manifests to the given APISIX cluster.
+func (c *Controller) syncManifestsToCluster(ctx context.Context, clusterName string, added, updated, deleted *utils.Manifest) error {
+	var guards []utils.RouteGuard
+	if limit := c.cfg.APISIX.MaxRouteCount; limit > 0 {
+		// The cache is kept in sync with the writes, so no need to list
+		// the routes from APISIX each time.
+		existing, err := c.apisix.Cluster(clusterName).RouteIDs()
+		if err != nil {
+			return err
+		}
+		// Reject the sync before anything is written, the limit is checked
+		// again when the routes are created since other syncs might have
+		// added routes in the meantime.
+		if err := checkRouteLimit(existing, added, updated, deleted, limit); err != nil {
+			c.MetricsCollector.IncrRouteLimitRejection()
+			return err
+		}
+		added, updated = moveAbsentRoutes(existing, added, updated)
+		guards = append(guards, c.routeLimitGuard(clusterName, limit))
+	}
+	if c.cfg.APISIX.RollbackOnFailure {
+		return utils.SyncManifestsWithRollback(ctx, c.apisix, clusterName, added, updated, deleted, guards...)
+	}
+	return utils.SyncManifests(ctx, c.apisix, clusterName, added, updated, deleted, guards...)
+}
+
+// filterResyncManifest drops the resources which are unchanged in the APISIX
+// cluster from the manifest if the event is generated by the periodic resync.
+func (c *Controller) filterResyncManifest(ev *types.Event, clusterName string, m *utils.Manifest) *utils.Manifest {
+	snapshots, ok := ev.Snapshot.(utils.Snapshots)
+	if !ok || snapshots[clusterName] == nil {
+		return m
+	}
+	snapshot := snapshots[clusterName]
+	filtered, stats := snapshot.Filter(m)
+	for resource, n := range stats.Skipped {
+		c.MetricsCollector.IncrResyncResources(resource, "skipped", n)
+	}
+	for resource, n := range stats.Pushed {
+		c.MetricsCollector.IncrResyncResources(resource, "pushed", n)
+	}
+	return filtered
 }
 
 // recorderEvent recorder events for resources
@@ -354,6 +541,14 @@ func (c *Controller) Run(stop chan struct{}) error {
 		}
 	}()
 
+	if timeout := c.cfg.ReadinessTimeout.Duration; timeout > 0 {
+		// Avoid staying unready forever if some resources cannot be synced.
+		timer := time.AfterFunc(timeout, func() {
+			c.initialSync.forceReady("readiness timeout exceeded")
+		})
+		defer timer.Stop()
+	}
+
 	lock := &resourcelock.LeaseLock{
 		LeaseMeta: metav1.ObjectMeta{
 			Namespace: c.namespace,
@@ -367,11 +562,15 @@ func (c *Controller) Run(stop chan struct{}) error {
 	}
 	cfg := leaderelection.LeaderElectionConfig{
 		Lock:          lock,
-		LeaseDuration: 15 * time.Second,
-		RenewDeadline: 5 * time.Second,
-		RetryPeriod:   2 * time.Second,
+		LeaseDuration: c.cfg.Kubernetes.ElectionLeaseDuration.Duration,
+		RenewDeadline: c.cfg.Kubernetes.ElectionRenewDeadline.Duration,
+		RetryPeriod:   c.cfg.Kubernetes.ElectionRetryPeriod.Duration,
 		Callbacks: leaderelection.LeaderCallbacks{
-			OnStartedLeading: c.run,
+			OnStartedLeading: func(ctx context.Context) {
+				c.leaderRunning.Add(1)
+				defer c.leaderRunning.Done()
+				c.run(ctx)
+			},
 			OnNewLeader: func(identity string) {
 				log.Warnf("found a new leader %s", identity)
 				if identity != c.name {
@@ -380,22 +579,27 @@ func (c *Controller) Run(stop chan struct{}) error {
 						zap.String("pod", c.name),
 					)
 					c.MetricsCollector.ResetLeader(false)
-					// delete the old APISIX cluster, so that the cached state
+					// candidates never sync resources, so they are always ready.
+					c.initialSync.forceReady("running as a candidate")
+					// delete the old APISIX clusters, so that the cached state
 					// like synchronization won't be used next time the candidate
 					// becomes the leader again.
-					c.apisix.DeleteCluster(c.cfg.APISIX.DefaultClusterName)
+					c.deleteClusters()
 				}
 			},
 			OnStoppedLeading: func() {
+				// wait for the workers to exit after their in-flight syncs
+				// are canceled.
+				c.waitForLeaderExit()
 				log.Infow("controller now is running as a candidate",
 					zap.String("namespace", c.namespace),
 					zap.String("pod", c.name),
 				)
 				c.MetricsCollector.ResetLeader(false)
-				// delete the old APISIX cluster, so that the cached state
+				// delete the old APISIX clusters, so that the cached state
 				// like synchronization won't be used next time the candidate
 				// becomes the leader again.
-				c.apisix.DeleteCluster(c.cfg.APISIX.DefaultClusterName)
+				c.deleteClusters()
 			},
 		},
 		ReleaseOnCancel: true,
@@ -432,11 +636,22 @@ func (c *Controller) run(ctx context.Context) {
 	// give up leader
 	defer c.leaderContextCancelFunc()
 
+	// mark as the leader before any syncs, so that they are recorded in metrics.
+	c.MetricsCollector.ResetLeader(true)
+
 	clusterOpts := &apisix.ClusterOptions{
-		Name:             c.cfg.APISIX.DefaultClusterName,
-		AdminKey:         c.cfg.APISIX.DefaultClusterAdminKey,
-		BaseURL:          c.cfg.APISIX.DefaultClusterBaseURL,
-		MetricsCollector: c.MetricsCollector,
+		Name:                c.cfg.APISIX.DefaultClusterName,
+		AdminKey:            c.cfg.APISIX.DefaultClusterAdminKey,
+		BaseURL:             c.cfg.APISIX.DefaultClusterBaseURL,
+		MetricsCollector:    c.MetricsCollector,
+		AuditLogger:         c.auditLogger,
+		SchemaCacheTTL:      c.cfg.APISIX.PluginSchemaCacheTTL.Duration,
+		MaxRetries:          c.cfg.APISIX.AdminAPIMaxRetries,
+		MaxRetryBackoff:     c.cfg.APISIX.AdminAPIMaxRetryBackoff.Duration,
+		BatchConcurrency:    c.cfg.APISIX.AdminAPIBatchConcurrency,
+		Timeout:             c.cfg.APISIX.AdminAPITimeout.Duration,
+		DialTimeout:         c.cfg.APISIX.AdminAPIDialTimeout.Duration,
+		MaxIdleConnsPerHost: c.cfg.APISIX.AdminAPIMaxIdleConnsPerHost,
 	}
 	err := c.apisix.AddCluster(ctx, clusterOpts)
 	if err != nil && err != apisix.ErrDuplicatedCluster {
@@ -456,14 +671,35 @@ func (c *Controller) run(ctx context.Context) {
 		}
 		return
 	}
+	c.addClusters(ctx)
 
 	c.initWhenStartLeading()
 
-	c.namespaceProvider, err = namespace.NewWatchingProvider(ctx, c.kubeClient, c.cfg)
+	c.namespaceProvider, err = namespace.NewWatchingProvider(ctx, c.kubeClient, c.cfg, c.resyncNamespace)
 	if err != nil {
 		ctx.Done()
 		return
 	}
+	c.apiServer.AdminAPIState.SetCheck(c.apisix.Cluster(c.cfg.APISIX.DefaultClusterName).AdminAPIHealthCheck)
+	defer c.apiServer.AdminAPIState.SetCheck(nil)
+
+	c.apiServer.NamespacesState.Lock()
+	c.apiServer.NamespacesState.Namespaces = c.namespaceProvider.NamespaceSelections
+	c.apiServer.NamespacesState.Unlock()
+	defer func() {
+		c.apiServer.NamespacesState.Lock()
+		c.apiServer.NamespacesState.Namespaces = nil
+		c.apiServer.NamespacesState.Unlock()
+	}()
+
+	c.apiServer.ResyncState.Lock()
+	c.apiServer.ResyncState.Resync = c.manualResync.request
+	c.apiServer.ResyncState.Unlock()
+	defer func() {
+		c.apiServer.ResyncState.Lock()
+		c.apiServer.ResyncState.Resync = nil
+		c.apiServer.ResyncState.Unlock()
+	}()
 
 	c.gatewayProvider, err = gateway.NewGatewayProvider(&gateway.ProviderOptions{
 		Cfg:               c.cfg,
@@ -486,44 +722,80 @@ func (c *Controller) run(ctx context.Context) {
 		return
 	}
 
+	// The informers are started from scratch, so all the objects are
+	// reconciled again, spread them over the ramp.
+	c.leaderResync = newLeaderResync(c.cfg.Kubernetes.LeaderResyncRamp.Duration)
+
 	e := utils.ParallelExecutor{}
 
 	e.Add(func() {
-		c.checkClusterHealth(ctx, cancelFunc)
-	})
-	e.Add(func() {
-		c.podInformer.Run(ctx.Done())
+		c.leaderResync.run(ctx, c.MetricsCollector)
 	})
 	e.Add(func() {
-		c.epInformer.Run(ctx.Done())
-	})
-	e.Add(func() {
-		c.svcInformer.Run(ctx.Done())
+		c.checkClusterHealth(ctx, cancelFunc)
 	})
 	e.Add(func() {
-		c.ingressInformer.Run(ctx.Done())
+		c.checkWorkers(ctx)
 	})
 	e.Add(func() {
-		c.apisixRouteInformer.Run(ctx.Done())
+		c.podInformer.Run(ctx.Done())
 	})
 	e.Add(func() {
-		c.apisixUpstreamInformer.Run(ctx.Done())
+		c.epInformer.Run(ctx.Done())
 	})
 	e.Add(func() {
-		c.apisixClusterConfigInformer.Run(ctx.Done())
+		c.svcInformer.Run(ctx.Done())
 	})
+	if c.ingressController != nil {
+		e.Add(func() {
+			c.ingressInformer.Run(ctx.Done())
+		})
+	}
+	if c.apisixRouteController != nil {
+		e.Add(func() {
+			c.apisixRouteInformer.Run(ctx.Done())
+		})
+	}
+	// ApisixRoutes split the traffic across the weighted subsets of the
+	// ApisixUpstreams.
+	if c.apisixUpstreamController != nil || c.apisixRouteController != nil {
+		e.Add(func() {
+			c.apisixUpstreamInformer.Run(ctx.Done())
+		})
+	}
+	if c.apisixClusterConfigController != nil {
+		e.Add(func() {
+			c.apisixClusterConfigInformer.Run(ctx.Done())
+		})
+	}
 	e.Add(func() {
 		c.secretInformer.Run(ctx.Done())
 	})
 	e.Add(func() {
-		c.apisixTlsInformer.Run(ctx.Done())
-	})
-	e.Add(func() {
-		c.apisixConsumerInformer.Run(ctx.Done())
-	})
-	e.Add(func() {
-		c.apisixPluginConfigInformer.Run(ctx.Done())
+		c.configMapInformer.Run(ctx.Done())
 	})
+	if c.apisixTlsController != nil {
+		e.Add(func() {
+			c.apisixTlsInformer.Run(ctx.Done())
+		})
+	}
+	// Ingresses check the ApisixConsumers of the auth-consumers annotation.
+	if c.apisixConsumerController != nil || c.ingressController != nil {
+		e.Add(func() {
+			c.apisixConsumerInformer.Run(ctx.Done())
+		})
+	}
+	// ApisixRoutes merge their plugins with the ApisixPluginConfigs.
+	if c.apisixPluginConfigController != nil || c.apisixRouteController != nil {
+		e.Add(func() {
+			c.apisixPluginConfigInformer.Run(ctx.Done())
+		})
+	}
+	if c.apisixRateLimitPolicyInformer != nil && c.apisixRouteController != nil {
+		e.Add(func() {
+			c.apisixRateLimitPolicyInformer.Run(ctx.Done())
+		})
+	}
 	e.Add(func() {
 		c.podController.run(ctx)
 	})
@@ -534,10 +806,20 @@ func (c *Controller) run(ctx context.Context) {
 			c.endpointsController.run(ctx)
 		}
 	})
+	if c.endpointBatcher != nil {
+		e.Add(func() {
+			c.endpointBatcher.run(ctx)
+		})
+	}
 
 	e.Add(func() {
 		c.namespaceProvider.Run(ctx)
 	})
+	if c.cfg.Kubernetes.EnableFinalizers {
+		e.Add(func() {
+			c.releaseFinalizersLoop(ctx)
+		})
+	}
 
 	if c.cfg.Kubernetes.EnableGatewayAPI {
 		e.Add(func() {
@@ -545,35 +827,53 @@ func (c *Controller) run(ctx context.Context) {
 		})
 	}
 
-	e.Add(func() {
-		c.apisixUpstreamController.run(ctx)
-	})
-	e.Add(func() {
-		c.ingressController.run(ctx)
-	})
-	e.Add(func() {
-		c.apisixRouteController.run(ctx)
-	})
-	e.Add(func() {
-		c.apisixClusterConfigController.run(ctx)
-	})
-	e.Add(func() {
-		c.apisixTlsController.run(ctx)
-	})
+	if c.apisixUpstreamController != nil {
+		e.Add(func() {
+			c.apisixUpstreamController.run(ctx)
+		})
+	}
+	if c.ingressController != nil {
+		e.Add(func() {
+			c.ingressController.run(ctx)
+		})
+	}
+	if c.apisixRouteController != nil {
+		e.Add(func() {
+			c.apisixRouteController.run(ctx)
+		})
+	}
+	if c.apisixClusterConfigController != nil {
+		e.Add(func() {
+			c.apisixClusterConfigController.run(ctx)
+		})
+	}
+	if c.apisixTlsController != nil {
+		e.Add(func() {
+			c.apisixTlsController.run(ctx)
+		})
+	}
 	e.Add(func() {
 		c.secretController.run(ctx)
 	})
-	e.Add(func() {
-		c.apisixConsumerController.run(ctx)
-	})
-	e.Add(func() {
-		c.apisixPluginConfigController.run(ctx)
-	})
+	if c.serviceController != nil {
+		e.Add(func() {
+			c.serviceController.run(ctx)
+		})
+	}
+	if c.apisixConsumerController != nil {
+		e.Add(func() {
+			c.apisixConsumerController.run(ctx)
+		})
+	}
+	if c.apisixPluginConfigController != nil {
+		e.Add(func() {
+			c.apisixPluginConfigController.run(ctx)
+		})
+	}
 
 	e.Add(func() {
-		c.resourceSyncLoop(ctx, c.cfg.ApisixResourceSyncInterval.Duration)
+		c.resourceSyncLoop(ctx)
 	})
-	c.MetricsCollector.ResetLeader(true)
 
 	log.Infow("controller now is running as leader",
 		zap.String("namespace", c.namespace),
@@ -598,11 +898,62 @@ func (c *Controller) isWatchingNamespace(key string) (ok bool) {
 	return c.namespaceProvider.IsWatchingNamespace(key)
 }
 
-func (c *Controller) syncSSL(ctx context.Context, ssl *apisixv1.Ssl, event types.EventType) error {
+// deferPausedDelete keeps the deletion of a resource in a paused namespace,
+// enqueue is called once the reconciliation of the namespace is resumed, so
+// that the APISIX objects translated from the resource are not left behind.
+// It returns true if the deletion is deferred.
+func (c *Controller) deferPausedDelete(key string, enqueue func()) bool {
+	if !c.namespaceProvider.IsPausedNamespace(key) {
+		return false
+	}
+	ns, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return false
+	}
+	c.pausedDeletesLock.Lock()
+	defer c.pausedDeletesLock.Unlock()
+	if c.pausedDeletes == nil {
+		c.pausedDeletes = make(map[string][]func())
+	}
+	c.pausedDeletes[ns] = append(c.pausedDeletes[ns], enqueue)
+	log.Infow("deletion deferred since the reconciliation of the namespace is paused",
+		zap.String("key", key),
+	)
+	return true
+}
+
+// enqueuePausedDeletes enqueues the deletions deferred while the
+// reconciliation of the namespace is paused.
+func (c *Controller) enqueuePausedDeletes(namespace string) {
+	c.pausedDeletesLock.Lock()
+	deletes := c.pausedDeletes[namespace]
+	delete(c.pausedDeletes, namespace)
+	c.pausedDeletesLock.Unlock()
+
+	for _, enqueue := range deletes {
+		enqueue()
+	}
+}
+
+// skipUnwatched checks whether the event should be skipped since the
+// namespace of the resource is no longer watched, e.g. its labels don't
+// match the namespace selector any more after the event is queued. The
+// deletions are still handled.
+func (c *Controller) skipUnwatched(ev *types.Event, key string) bool {
+	if ev.Type == types.EventDelete || c.isWatchingNamespace(key) {
+		return false
+	}
+	log.Infow("skip the event since the namespace is no longer watched",
+		zap.String("event_type", ev.Type.String()),
+		zap.String("key", key),
+	)
+	return true
+}
+
+func (c *Controller) syncSSL(ctx context.Context, clusterName string, ssl *apisixv1.Ssl, event types.EventType) error {
 	var (
 		err error
 	)
-	clusterName := c.cfg.APISIX.DefaultClusterName
 	if event == types.EventDelete {
 		err = c.apisix.Cluster(clusterName).SSL().Delete(ctx, ssl)
 	} else if event == types.EventUpdate {
@@ -613,8 +964,7 @@ func (c *Controller) syncSSL(ctx context.Context, ssl *apisixv1.Ssl, event types
 	return err
 }
 
-func (c *Controller) syncConsumer(ctx context.Context, consumer *apisixv1.Consumer, event types.EventType) (err error) {
-	clusterName := c.cfg.APISIX.DefaultClusterName
+func (c *Controller) syncConsumer(ctx context.Context, clusterName string, consumer *apisixv1.Consumer, event types.EventType) (err error) {
 	if event == types.EventDelete {
 		err = c.apisix.Cluster(clusterName).Consumer().Delete(ctx, consumer)
 	} else if event == types.EventUpdate {
@@ -631,6 +981,62 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 		return err
 	}
 	svcName := ep.ServiceName()
+	if err := c.syncServiceUpstreams(ctx, namespace, svcName, ep); err != nil {
+		return err
+	}
+	// The endpoints might also be aggregated by the ApisixUpstreams of
+	// other Services.
+	if c.apisixUpstreamController == nil {
+		return nil
+	}
+	objs, err := c.apisixUpstreamInformer.GetIndexer().ByIndex(_apisixUpstreamServiceIndex, namespace+"/"+svcName)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		au := obj.(*configv2beta3.ApisixUpstream)
+		if au.Namespace == namespace && au.Name == svcName {
+			continue
+		}
+		if !c.isWatchingNamespace(au.Namespace + "/" + au.Name) {
+			continue
+		}
+		if err := c.syncServiceUpstreams(ctx, au.Namespace, au.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resyncEndpoint syncs the upstream nodes of the Service with its current
+// endpoints, it's a no-op if the endpoints don't exist.
+func (c *Controller) resyncEndpoint(ctx context.Context, namespace, svcName string) error {
+	var (
+		ep  kube.Endpoint
+		err error
+	)
+	if c.cfg.Kubernetes.WatchEndpointSlices {
+		ep, err = c.epLister.GetEndpointSlices(namespace, svcName)
+	} else {
+		ep, err = c.epLister.GetEndpoint(namespace, svcName)
+	}
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, err := ep.Namespace(); err != nil {
+		// No EndpointSlices.
+		return nil
+	}
+	return c.syncEndpoint(ctx, ep)
+}
+
+// syncServiceUpstreams syncs the nodes of the upstreams of the Service, the
+// nodes come from the given endpoints, or from the Services referenced by
+// the ApisixUpstream, in which case the endpoints can be nil.
+func (c *Controller) syncServiceUpstreams(ctx context.Context, namespace, svcName string, ep kube.Endpoint) error {
 	svc, err := c.svcLister.Services(namespace).Get(svcName)
 	if err != nil {
 		if k8serrors.IsNotFound(err) {
@@ -648,14 +1054,38 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 			log.Errorf("failed to get ApisixUpstream %s/%s: %s", namespace, svcName, err)
 			return err
 		}
+	} else if au.Spec != nil && len(au.Spec.ExternalNodes) > 0 {
+		log.Debugw("upstreams use the external nodes, ignore endpoints change",
+			zap.String("namespace", namespace),
+			zap.String("service", svcName),
+		)
+		return nil
 	} else if au.Spec != nil && len(au.Spec.Subsets) > 0 {
 		subsets = append(subsets, au.Spec.Subsets...)
 	}
+	aggregated := au != nil && au.Spec != nil && len(au.Spec.Services) > 0
+	if !aggregated && ep == nil {
+		return nil
+	}
 
 	clusters := c.apisix.ListClusters()
 	for _, port := range svc.Spec.Ports {
-		for _, subset := range subsets {
-			nodes, err := c.translator.TranslateUpstreamNodes(ep, port.Port, subset.Labels)
+		for i := range subsets {
+			subset := &subsets[i]
+			selector, err := translation.SubsetSelector(subset)
+			if err != nil {
+				log.Errorw("found ApisixUpstream with invalid subset",
+					zap.Error(err),
+					zap.String("subset", subset.Name),
+				)
+				continue
+			}
+			var nodes apisixv1.UpstreamNodes
+			if aggregated {
+				nodes, err = c.translator.TranslateServiceRefsNodes(namespace, au.Spec.Services, port.Port, selector)
+			} else {
+				nodes, err = c.translator.TranslateUpstreamNodes(ep, port.Port, selector)
+			}
 			if err != nil {
 				log.Errorw("failed to translate upstream nodes",
 					zap.Error(err),
@@ -665,7 +1095,16 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 			}
 			name := apisixv1.ComposeUpstreamName(namespace, svcName, subset.Name, port.Port)
 			for _, cluster := range clusters {
-				if err := c.syncUpstreamNodesChangeToCluster(ctx, cluster, nodes, name); err != nil {
+				if c.endpointBatcher != nil {
+					c.endpointBatcher.add(&upstreamNodesUpdate{
+						cluster:   cluster,
+						upstream:  name,
+						nodes:     nodes,
+						clusterIP: svc.Spec.ClusterIP,
+					})
+					continue
+				}
+				if err := c.syncUpstreamNodesChangeToCluster(ctx, cluster, nodes, name, svc.Spec.ClusterIP); err != nil {
 					return err
 				}
 			}
@@ -674,38 +1113,83 @@ func (c *Controller) syncEndpoint(ctx context.Context, ep kube.Endpoint) error {
 	return nil
 }
 
-func (c *Controller) syncUpstreamNodesChangeToCluster(ctx context.Context, cluster apisix.Cluster, nodes apisixv1.UpstreamNodes, upsName string) error {
-	upstream, err := cluster.Upstream().Get(ctx, upsName)
+func (c *Controller) syncUpstreamNodesChangeToCluster(ctx context.Context, cluster apisix.Cluster, nodes apisixv1.UpstreamNodes, upsName, svcClusterIP string) error {
+	// The upstreams with the same name but different configs (thus IDs)
+	// share the nodes.
+	upstreams, err := cluster.Upstream().ListByName(ctx, upsName)
 	if err != nil {
-		if err == apisixcache.ErrNotFound {
-			log.Warnw("upstream is not referenced",
+		log.Errorw("failed to list upstreams",
+			zap.String("upstream", upsName),
+			zap.String("cluster", cluster.String()),
+			zap.Error(err),
+		)
+		return err
+	}
+	if len(upstreams) == 0 {
+		log.Warnw("upstream is not referenced",
+			zap.String("cluster", cluster.String()),
+			zap.String("upstream", upsName),
+		)
+		return nil
+	}
+
+	updated := &utils.Manifest{}
+	for _, upstream := range upstreams {
+		if resolvedByService(upstream, svcClusterIP) {
+			log.Debugw("upstream is resolved with the service granularity, ignore endpoints change",
 				zap.String("cluster", cluster.String()),
 				zap.String("upstream", upsName),
 			)
-			return nil
-		} else {
-			log.Errorw("failed to get upstream",
-				zap.String("upstream", upsName),
+			continue
+		}
+		if upstream.DiscoveryType != "" {
+			log.Debugw("upstream is resolved by service discovery, ignore endpoints change",
 				zap.String("cluster", cluster.String()),
-				zap.Error(err),
+				zap.String("upstream", upsName),
 			)
-			return err
+			continue
 		}
-	}
 
-	upstream.Nodes = nodes
-
-	log.Debugw("upstream binds new nodes",
-		zap.Any("upstream", upstream),
-		zap.String("cluster", cluster.String()),
-	)
+		if reflect.DeepEqual(upstream.Nodes, nodes) {
+			log.Debugw("upstream nodes are unchanged, ignore endpoints change",
+				zap.String("cluster", cluster.String()),
+				zap.String("upstream", upsName),
+			)
+			c.MetricsCollector.IncrSkippedWrites("upstream")
+			continue
+		}
+		upstream.Nodes = nodes
 
-	updated := &utils.Manifest{
-		Upstreams: []*apisixv1.Upstream{upstream},
+		log.Debugw("upstream binds new nodes",
+			zap.Any("upstream", upstream),
+			zap.String("cluster", cluster.String()),
+		)
+		updated.Upstreams = append(updated.Upstreams, upstream)
+	}
+	if len(updated.Upstreams) == 0 {
+		return nil
 	}
 	return c.syncManifests(ctx, nil, updated, nil)
 }
 
+// resolvedByService reports whether the upstream was translated with the
+// "service" resolve granularity, i.e. its only node is the service cluster IP.
+// Such upstreams don't track the endpoints.
+func resolvedByService(ups *apisixv1.Upstream, svcClusterIP string) bool {
+	if svcClusterIP == "" || svcClusterIP == v1.ClusterIPNone {
+		return false
+	}
+	return len(ups.Nodes) == 1 && ups.Nodes[0].Host == svcClusterIP
+}
+
+// resolvedByServiceDNS reports whether the upstream was translated with the
+// "dns" resolve granularity, i.e. it's resolved by the DNS name of the
+// Service. Such upstreams keep the discovery when the ApisixUpstream changes.
+func resolvedByServiceDNS(ups *apisixv1.Upstream, namespace, svcName string) bool {
+	return ups.DiscoveryType == apisixv1.DiscoveryTypeDNS &&
+		strings.HasPrefix(ups.ServiceName, svcName+"."+namespace+".svc.")
+}
+
 func (c *Controller) checkClusterHealth(ctx context.Context, cancelFunc context.CancelFunc) {
 	defer cancelFunc()
 	t := time.NewTicker(5 * time.Second)
@@ -732,40 +1216,115 @@ func (c *Controller) checkClusterHealth(ctx context.Context, cancelFunc context.
 	}
 }
 
-func (c *Controller) syncAllResources() {
-	wg := sync.WaitGroup{}
-	goAttach := func(handler func()) {
+// syncAllResources queues all the resources to sync, it returns the keys of
+// the queued resources by their kinds.
+func (c *Controller) syncAllResources(ctx context.Context) map[string][]string {
+	// Objects might be changed behind us in APISIX, write them again
+	// even if they are unchanged since the last write.
+	for _, name := range c.clusterNames() {
+		c.apisix.Cluster(name).ResetWriteCache()
+	}
+
+	// Fetch the resources of each APISIX cluster once, so that the unchanged
+	// resources won't be pushed again. Fall back to a full resync of the
+	// cluster if it fails.
+	snapshots := make(utils.Snapshots)
+	for _, name := range c.clusterNames() {
+		snapshot, err := utils.NewSnapshot(ctx, c.apisix, name)
+		if err != nil {
+			log.Warnw("failed to fetch APISIX resources, all resources will be pushed",
+				zap.String("cluster", name),
+				zap.Error(err),
+			)
+			continue
+		}
+		snapshots[name] = snapshot
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		keys = make(map[string][]string)
+	)
+	goAttach := func(kind string, handler func() []string) {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			handler()
+			queued := handler()
+			mu.Lock()
+			keys[kind] = queued
+			mu.Unlock()
 		}()
 	}
-	goAttach(func() {
-		c.apisixConsumerController.ResourceSync()
-	})
-	goAttach(func() {
-		c.apisixRouteController.ResourceSync()
-	})
-	goAttach(func() {
-		c.apisixClusterConfigController.ResourceSync()
-	})
-	goAttach(func() {
-		c.apisixPluginConfigController.ResourceSync()
-	})
-	goAttach(func() {
-		c.apisixUpstreamController.ResourceSync()
-	})
-	goAttach(func() {
-		c.apisixTlsController.ResourceSync()
-	})
-	goAttach(func() {
-		c.ingressController.ResourceSync()
-	})
+	if c.apisixConsumerController != nil {
+		goAttach("ApisixConsumer", func() []string {
+			return c.apisixConsumerController.ResourceSync("")
+		})
+	}
+	if c.apisixRouteController != nil {
+		goAttach("ApisixRoute", func() []string {
+			return c.apisixRouteController.ResourceSync(snapshots, "")
+		})
+	}
+	if c.apisixClusterConfigController != nil {
+		goAttach("ApisixClusterConfig", func() []string {
+			return c.apisixClusterConfigController.ResourceSync()
+		})
+	}
+	if c.apisixPluginConfigController != nil {
+		goAttach("ApisixPluginConfig", func() []string {
+			return c.apisixPluginConfigController.ResourceSync(snapshots, "")
+		})
+	}
+	if c.apisixUpstreamController != nil {
+		goAttach("ApisixUpstream", func() []string {
+			return c.apisixUpstreamController.ResourceSync("")
+		})
+	}
+	if c.apisixTlsController != nil {
+		goAttach("ApisixTls", func() []string {
+			return c.apisixTlsController.ResourceSync("")
+		})
+	}
+	if c.ingressController != nil {
+		goAttach("Ingress", func() []string {
+			return c.ingressController.ResourceSync(snapshots, "")
+		})
+	}
 	wg.Wait()
+	return keys
+}
+
+// Reload applies the config items which can be changed at runtime, now
+// they are the apisix resource sync interval and jitter, and the number of
+// workers.
+func (c *Controller) Reload(cfg *config.Config) {
+	c.resourceSyncLock.Lock()
+	c.cfg.ApisixResourceSyncInterval = cfg.ApisixResourceSyncInterval
+	c.cfg.ApisixResourceSyncJitter = cfg.ApisixResourceSyncJitter
+	c.cfg.Workers = cfg.Workers
+	c.resourceSyncLock.Unlock()
+
+	select {
+	case c.resourceSyncReload <- struct{}{}:
+	default:
+	}
+
+	if c.workerPool.resize(cfg.Workers) {
+		log.Infow("worker pools resized",
+			zap.Int("workers", cfg.Workers),
+		)
+	}
 }
 
-func (c *Controller) resourceSyncLoop(ctx context.Context, interval time.Duration) {
+// nextResourceSyncInterval returns the duration to wait before the next
+// resource sync, according to the current settings.
+func (c *Controller) nextResourceSyncInterval() time.Duration {
+	c.resourceSyncLock.RLock()
+	interval := c.cfg.ApisixResourceSyncInterval.Duration
+	jitter := c.cfg.ApisixResourceSyncJitter
+	c.resourceSyncLock.RUnlock()
+
 	// The interval shall not be less than 60 seconds.
 	if interval < _mininumApisixResourceSyncInterval {
 		log.Warnw("The apisix-resource-sync-interval shall not be less than 60 seconds.",
@@ -773,15 +1332,101 @@ func (c *Controller) resourceSyncLoop(ctx context.Context, interval time.Duratio
 		)
 		interval = _mininumApisixResourceSyncInterval
 	}
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
+	return jitterInterval(interval, jitter)
+}
+
+// resourceSyncObjects returns the objects in the informer to resync, they
+// are limited to the namespace unless it's empty.
+func (c *Controller) resourceSyncObjects(informer cache.SharedIndexInformer, namespace string) []interface{} {
+	if namespace == "" {
+		return informer.GetIndexer().List()
+	}
+	objs, err := informer.GetIndexer().ByIndex(cache.NamespaceIndex, namespace)
+	if err != nil {
+		log.Errorw("failed to list objects by namespace",
+			zap.String("namespace", namespace),
+			zap.Error(err),
+		)
+		return nil
+	}
+	return objs
+}
+
+// resyncNamespace resyncs the resources in the namespace, it's called once
+// the reconciliation of the namespace is resumed, so that the changes made
+// while it's paused are applied.
+func (c *Controller) resyncNamespace(namespace string) {
+	c.enqueuePausedDeletes(namespace)
+	if c.apisixConsumerController != nil {
+		c.apisixConsumerController.ResourceSync(namespace)
+	}
+	if c.apisixRouteController != nil {
+		c.apisixRouteController.ResourceSync(nil, namespace)
+	}
+	if c.apisixPluginConfigController != nil {
+		c.apisixPluginConfigController.ResourceSync(nil, namespace)
+	}
+	if c.apisixUpstreamController != nil {
+		c.apisixUpstreamController.ResourceSync(namespace)
+	}
+	if c.apisixTlsController != nil {
+		c.apisixTlsController.ResourceSync(namespace)
+	}
+	if c.ingressController != nil {
+		c.ingressController.ResourceSync(nil, namespace)
+	}
+}
+
+func (c *Controller) resourceSyncLoop(ctx context.Context) {
+	timer := time.NewTimer(c.nextResourceSyncInterval())
+	defer timer.Stop()
+	// The legacy objects are migrated once the initial sync is done, so
+	// that they don't stay until the first periodic sync.
+	initialSynced := c.initialSync.done()
 	for {
 		select {
-		case <-ticker.C:
-			c.syncAllResources()
+		case <-initialSynced:
+			initialSynced = nil
+			if !c.legacyIDsMigrated {
+				c.legacyIDsMigrated = c.migrateLegacyIDs(ctx)
+			}
+			continue
+		case <-timer.C:
+			c.syncAllResources(ctx)
+			if !c.legacyIDsMigrated {
+				c.legacyIDsMigrated = c.migrateLegacyIDs(ctx)
+			}
+			timer.Reset(c.nextResourceSyncInterval())
+			continue
+		case <-c.resourceSyncReload:
+			if !timer.Stop() {
+				<-timer.C
+			}
+			timer.Reset(c.nextResourceSyncInterval())
+			continue
+		case <-c.manualResync.notify:
+			progress := c.manualResync.take()
+			log.Infow("manual resync started",
+				zap.Int("requests", len(progress.waiters)),
+			)
+			start := time.Now()
+			c.manualResync.queued(progress, c.syncAllResources(ctx))
+			log.Infow("manual resync queued all the resources",
+				zap.Duration("duration", time.Since(start)),
+			)
 			continue
 		case <-ctx.Done():
+			c.manualResync.abort()
 			return
 		}
 	}
 }
+
+// jitterInterval returns a random duration in the range
+// [interval, interval * (1 + jitter)).
+func jitterInterval(interval time.Duration, jitter float64) time.Duration {
+	if jitter <= 0 {
+		return interval
+	}
+	return interval + time.Duration(rand.Float64()*jitter*float64(interval))
+}
@@ -0,0 +1,72 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestJitterInterval(t *testing.T) {
+	interval := time.Minute
+	assert.Equal(t, interval, jitterInterval(interval, 0))
+
+	for i := 0; i < 100; i++ {
+		d := jitterInterval(interval, 0.5)
+		assert.GreaterOrEqual(t, d, interval)
+		assert.Less(t, d, interval+30*time.Second)
+	}
+}
+
+func TestResolvedByService(t *testing.T) {
+	// Translated with the service granularity.
+	ups := &apisixv1.Upstream{
+		Nodes: apisixv1.UpstreamNodes{
+			{Host: "10.0.5.12", Port: 80, Weight: 100},
+		},
+	}
+	assert.True(t, resolvedByService(ups, "10.0.5.12"))
+	assert.False(t, resolvedByService(ups, ""))
+	assert.False(t, resolvedByService(ups, "None"))
+
+	// Translated with the endpoint granularity.
+	ups.Nodes = apisixv1.UpstreamNodes{
+		{Host: "192.168.1.1", Port: 9080, Weight: 100},
+		{Host: "192.168.1.2", Port: 9080, Weight: 100},
+	}
+	assert.False(t, resolvedByService(ups, "10.0.5.12"))
+	ups.Nodes = ups.Nodes[:1]
+	assert.False(t, resolvedByService(ups, "10.0.5.12"))
+}
+
+func TestResolvedByServiceDNS(t *testing.T) {
+	ups := &apisixv1.Upstream{
+		DiscoveryType: apisixv1.DiscoveryTypeDNS,
+		ServiceName:   "foo.default.svc.cluster.local:80",
+	}
+	assert.True(t, resolvedByServiceDNS(ups, "default", "foo"))
+	assert.False(t, resolvedByServiceDNS(ups, "default", "fo"))
+
+	// Configured by the discovery of ApisixUpstream.
+	ups.ServiceName = "foo.example.com:80"
+	assert.False(t, resolvedByServiceDNS(ups, "default", "foo"))
+	ups.DiscoveryType = "nacos"
+	ups.ServiceName = "foo.default.svc.cluster.local:80"
+	assert.False(t, resolvedByServiceDNS(ups, "default", "foo"))
+}
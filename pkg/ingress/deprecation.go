@@ -0,0 +1,65 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// _resourceDeprecated is used when a resource of deprecated api version is reconciled.
+	_resourceDeprecated = "ResourceDeprecated"
+	// _messageResourceDeprecated is the message of the deprecation warning.
+	_messageResourceDeprecated = "%s %s is deprecated, please migrate to %s"
+)
+
+// warnDeprecated emits a warning log and a warning event if the object is of
+// a deprecated api version, it's a no-op unless warnings are enabled. The
+// warning is emitted once for each generation of the object, so that resyncs
+// won't flood the logs and events.
+func (c *Controller) warnDeprecated(object runtime.Object, kind, version, replacement string) {
+	if !c.cfg.Kubernetes.WarnDeprecatedVersions {
+		return
+	}
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		return
+	}
+	key := kind + "/" + meta.GetNamespace() + "/" + meta.GetName()
+	if gen, ok := c.deprecationWarned.Load(key); ok && gen.(int64) == meta.GetGeneration() {
+		return
+	}
+	c.deprecationWarned.Store(key, meta.GetGeneration())
+
+	msg := fmt.Sprintf(_messageResourceDeprecated, kind, version, replacement)
+	log.Warnw(msg,
+		zap.String("namespace", meta.GetNamespace()),
+		zap.String("name", meta.GetName()),
+	)
+	c.recorderEventS(object, v1.EventTypeWarning, _resourceDeprecated, msg)
+}
+
+// forgetDeprecated removes the deprecation record of the object, it should
+// be called when the object is deleted.
+func (c *Controller) forgetDeprecated(kind, namespace, name string) {
+	c.deprecationWarned.Delete(kind + "/" + namespace + "/" + name)
+}
@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	listersv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+var _errTranslateAborted = errors.New("translate aborted")
+
+type abortedTranslator struct {
+	translation.Translator
+}
+
+func (t *abortedTranslator) TranslateRouteV2beta3(*configv2beta3.ApisixRoute) (*translation.TranslateContext, error) {
+	return nil, _errTranslateAborted
+}
+
+func newDeprecationTestController(t *testing.T, enabled bool) (*apisixRouteController, *record.FakeRecorder) {
+	ar := &configv2beta3.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "httpbin",
+			Namespace:  "default",
+			Generation: 1,
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.Nil(t, indexer.Add(ar))
+
+	cfg := config.NewDefaultConfig()
+	cfg.Kubernetes.WarnDeprecatedVersions = enabled
+	recorder := record.NewFakeRecorder(10)
+	return &apisixRouteController{
+		controller: &Controller{
+			cfg:               cfg,
+			recorder:          recorder,
+			translator:        &abortedTranslator{},
+			apisixRouteLister: kube.NewApisixRouteLister(nil, listersv2beta3.NewApisixRouteLister(indexer), nil),
+			namespaceProvider: namespace.NewMockWatchingProvider([]string{"default"}),
+		},
+	}, recorder
+}
+
+func syncDeprecationTestRoute(t *testing.T, c *apisixRouteController) {
+	ev := &types.Event{
+		Type: types.EventAdd,
+		Object: kube.ApisixRouteEvent{
+			Key:          "default/httpbin",
+			GroupVersion: kube.ApisixRouteV2beta3,
+		},
+	}
+	assert.Equal(t, _errTranslateAborted, c.sync(context.Background(), ev))
+}
+
+func TestApisixRouteV2beta3DeprecationWarning(t *testing.T) {
+	c, recorder := newDeprecationTestController(t, true)
+	syncDeprecationTestRoute(t, c)
+	assert.Len(t, recorder.Events, 1)
+	assert.Equal(t, "Warning ResourceDeprecated ApisixRoute apisix.apache.org/v2beta3 is deprecated, please migrate to apisix.apache.org/v2", <-recorder.Events)
+
+	// The warning is emitted only once for the same generation.
+	syncDeprecationTestRoute(t, c)
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestApisixRouteV2beta3DeprecationWarningDisabled(t *testing.T) {
+	c, recorder := newDeprecationTestController(t, false)
+	syncDeprecationTestRoute(t, c)
+	assert.Len(t, recorder.Events, 0)
+}
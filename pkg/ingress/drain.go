@@ -0,0 +1,123 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// runWorkers runs the workers until ctx is done, then shuts down the workqueue
+// and waits for the workers to exit. The workers share ctx, so the in-flight
+// Admin API calls are canceled once the leader role is lost, as another
+// leader may be writing to APISIX already, and the queued events are
+// discarded (see nextEvent). The interrupted events are left to the next
+// leader, which resyncs all the resources anyway.
+//
+// The number of workers follows the worker pool size, the extra workers are
+// stopped once their current events are handled when the pool shrinks.
+func (c *Controller) runWorkers(ctx context.Context, queue workqueue.Interface, worker func(ctx context.Context, stop <-chan struct{})) {
+	var (
+		wg    sync.WaitGroup
+		stops []chan struct{}
+	)
+	for leading := true; leading; {
+		size, resized := c.workerPool.get()
+		for len(stops) < size {
+			stop := make(chan struct{})
+			stops = append(stops, stop)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				worker(ctx, stop)
+			}()
+		}
+		for len(stops) > size {
+			close(stops[len(stops)-1])
+			stops = stops[:len(stops)-1]
+		}
+		select {
+		case <-resized:
+		case <-ctx.Done():
+			leading = false
+		}
+	}
+
+	queue.ShutDown()
+	timeout := c.cfg.Kubernetes.ElectionDrainTimeout.Duration
+	if !waitTimeout(&wg, timeout) {
+		log.Warnw("workers don't exit in time after the in-flight syncs are canceled",
+			zap.Duration("timeout", timeout),
+		)
+	}
+}
+
+// nextEvent gets the next event from the workqueue for the workers, quit is
+// true once the workqueue is shut down, i.e. the controller gives up the
+// leader role. The events still queued then are discarded, they are left to
+// the next leader. It also quits once the worker is stopped by shrinking the
+// worker pool.
+func nextEvent(queue workqueue.Interface, stop <-chan struct{}) (obj interface{}, quit bool) {
+	select {
+	case <-stop:
+		return nil, true
+	default:
+	}
+	obj, quit = queue.Get()
+	if quit {
+		return nil, true
+	}
+	if queue.ShuttingDown() {
+		queue.Done(obj)
+		return nil, true
+	}
+	return obj, false
+}
+
+// waitForLeaderExit waits for the leader routine to exit, so that the leader
+// states can be cleaned up safely, it never blocks longer than twice the drain
+// timeout, which leaves the workers the drain timeout to exit.
+func (c *Controller) waitForLeaderExit() {
+	deadline := 2 * c.cfg.Kubernetes.ElectionDrainTimeout.Duration
+	if !waitTimeout(&c.leaderRunning, deadline) {
+		log.Warnw("leader routine doesn't exit in time",
+			zap.Duration("deadline", deadline),
+		)
+	}
+}
+
+// waitTimeout waits for the WaitGroup with a timeout, it returns false if the
+// timeout expires.
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
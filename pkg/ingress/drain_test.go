@@ -0,0 +1,154 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestRunWorkersDrain(t *testing.T) {
+	queue := workqueue.New()
+	c := &Controller{cfg: config.NewDefaultConfig(), workerPool: newWorkerPool(1)}
+
+	var (
+		mu      sync.Mutex
+		handled []interface{}
+	)
+	started := make(chan struct{})
+	worker := func(ctx context.Context, stop <-chan struct{}) {
+		for {
+			obj, quit := nextEvent(queue, stop)
+			if quit {
+				return
+			}
+			if obj == "slow" {
+				close(started)
+				// The in-flight syncs are canceled once the leader role
+				// is lost, like the Admin API calls.
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+					t.Error("in-flight sync is not canceled")
+				}
+			}
+			mu.Lock()
+			handled = append(handled, obj)
+			mu.Unlock()
+			queue.Done(obj)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.runWorkers(ctx, queue, worker)
+		close(done)
+	}()
+
+	queue.Add("slow")
+	<-started
+	queue.Add("queued")
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(c.cfg.Kubernetes.ElectionDrainTimeout.Duration):
+		t.Fatal("workqueue is not drained")
+	}
+	assert.True(t, queue.ShuttingDown())
+	// The in-flight event is interrupted, while the queued one is left to
+	// the next leader.
+	assert.Equal(t, []interface{}{"slow"}, handled)
+}
+
+func TestRunWorkersResize(t *testing.T) {
+	queue := workqueue.New()
+	c := &Controller{cfg: config.NewDefaultConfig(), workerPool: newWorkerPool(1)}
+
+	var running int32
+	worker := func(ctx context.Context, stop <-chan struct{}) {
+		atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			obj, quit := nextEvent(queue, stop)
+			if quit {
+				return
+			}
+			queue.Done(obj)
+		}
+	}
+	workers := func() int32 {
+		return atomic.LoadInt32(&running)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		c.runWorkers(ctx, queue, worker)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return workers() == 1 }, time.Second, time.Millisecond)
+
+	assert.False(t, c.workerPool.resize(1))
+	assert.True(t, c.workerPool.resize(3))
+	assert.Eventually(t, func() bool { return workers() == 3 }, time.Second, time.Millisecond)
+
+	// The stopped workers exit after handling the event they are waiting for.
+	assert.True(t, c.workerPool.resize(1))
+	i := 0
+	assert.Eventually(t, func() bool {
+		i++
+		queue.Add(i)
+		return workers() == 1
+	}, time.Second, time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, int32(0), workers())
+}
+
+func TestWaitTimeout(t *testing.T) {
+	var wg sync.WaitGroup
+	assert.True(t, waitTimeout(&wg, time.Millisecond))
+
+	wg.Add(1)
+	assert.False(t, waitTimeout(&wg, 10*time.Millisecond))
+	wg.Done()
+	assert.True(t, waitTimeout(&wg, time.Second))
+}
+
+func TestWaitForLeaderExit(t *testing.T) {
+	c := &Controller{cfg: config.NewDefaultConfig()}
+	c.cfg.Kubernetes.ElectionDrainTimeout = types.TimeDuration{Duration: 10 * time.Millisecond}
+
+	c.leaderRunning.Add(1)
+	defer c.leaderRunning.Done()
+	start := time.Now()
+	c.waitForLeaderExit()
+	// The leader states are cleaned up after twice the drain timeout even
+	// if the leader routine is stuck.
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+	assert.Less(t, time.Since(start), time.Second)
+}
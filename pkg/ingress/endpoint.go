@@ -33,14 +33,18 @@ import (
 type endpointsController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
+	// debouncer coalesces the rapid changes of the same Service, it's nil
+	// if the debounce window isn't configured.
+	debouncer *endpointDebouncer
 }
 
 func (c *Controller) newEndpointsController() *endpointsController {
 	ctl := &endpointsController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "endpoints"),
-		workers:    1,
+	}
+	if c.cfg.EndpointDebounceWindow.Duration > 0 {
+		ctl.debouncer = newEndpointDebouncer(c.cfg.EndpointDebounceWindow.Duration, c.cfg.EndpointDebounceMaxWait.Duration, ctl.workqueue.Add)
 	}
 
 	ctl.controller.epInformer.AddEventHandler(
@@ -64,9 +68,9 @@ func (c *endpointsController) run(ctx context.Context) {
 		return
 	}
 
-	handler := func() {
+	handler := func(ctx context.Context, stop <-chan struct{}) {
 		for {
-			obj, shutdown := c.workqueue.Get()
+			obj, shutdown := nextEvent(c.workqueue, stop)
 			if shutdown {
 				return
 			}
@@ -77,11 +81,7 @@ func (c *endpointsController) run(ctx context.Context) {
 		}
 	}
 
-	for i := 0; i < c.workers; i++ {
-		go handler()
-	}
-
-	<-ctx.Done()
+	c.controller.runWorkers(ctx, c.workqueue, handler)
 }
 
 func (c *endpointsController) sync(ctx context.Context, ev *types.Event) error {
@@ -98,13 +98,14 @@ func (c *endpointsController) sync(ctx context.Context, ev *types.Event) error {
 		newestEp = ep
 	}
 	if ev.Type == types.EventDelete && newestEp != nil {
-		clusterName := c.controller.cfg.APISIX.DefaultClusterName
-		err = c.controller.apisix.Cluster(clusterName).UpstreamServiceRelation().Delete(ctx,
-			&v1.UpstreamServiceRelation{
-				ServiceName: ns + "_" + newestEp.ServiceName(),
-			})
-		if err != nil {
-			return err
+		for _, clusterName := range c.controller.clusterNames() {
+			err = c.controller.apisix.Cluster(clusterName).UpstreamServiceRelation().Delete(ctx,
+				&v1.UpstreamServiceRelation{
+					ServiceName: ns + "_" + newestEp.ServiceName(),
+				})
+			if err != nil {
+				return err
+			}
 		}
 	}
 	return c.controller.syncEndpoint(ctx, newestEp)
@@ -144,7 +145,7 @@ func (c *endpointsController) onAdd(obj interface{}) {
 	log.Debugw("endpoints add event arrived",
 		zap.String("object-key", key))
 
-	c.workqueue.Add(&types.Event{
+	c.enqueue(key, &types.Event{
 		Type: types.EventAdd,
 		// TODO pass key.
 		Object: kube.NewEndpoint(obj.(*corev1.Endpoints)),
@@ -172,7 +173,7 @@ func (c *endpointsController) onUpdate(prev, curr interface{}) {
 		zap.Any("new object", currEp),
 		zap.Any("old object", prevEp),
 	)
-	c.workqueue.Add(&types.Event{
+	c.enqueue(key, &types.Event{
 		Type: types.EventUpdate,
 		// TODO pass key.
 		Object: kube.NewEndpoint(currEp),
@@ -201,6 +202,11 @@ func (c *endpointsController) onDelete(obj interface{}) {
 	log.Debugw("endpoints delete event arrived",
 		zap.Any("final state", ep),
 	)
+	// The delete event isn't delayed, it supersedes the pending changes
+	// since the sync reads the latest endpoints anyway.
+	if c.debouncer != nil {
+		c.debouncer.cancel(ep.Namespace + "/" + ep.Name)
+	}
 	c.workqueue.Add(&types.Event{
 		Type:   types.EventDelete,
 		Object: kube.NewEndpoint(ep),
@@ -208,3 +214,13 @@ func (c *endpointsController) onDelete(obj interface{}) {
 
 	c.controller.MetricsCollector.IncrEvents("endpoints", "delete")
 }
+
+// enqueue adds the event to the workqueue, after the debounce window if
+// it's configured.
+func (c *endpointsController) enqueue(key string, ev *types.Event) {
+	if c.debouncer == nil {
+		c.workqueue.Add(ev)
+		return
+	}
+	c.debouncer.add(key, ev)
+}
@@ -0,0 +1,190 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// upstreamNodesUpdate is a pending change of the upstream nodes, which is
+// caused by the endpoints change.
+type upstreamNodesUpdate struct {
+	cluster  apisix.Cluster
+	upstream string
+	nodes    apisixv1.UpstreamNodes
+	// clusterIP is the service cluster IP, upstreams resolved with it
+	// are not updated.
+	clusterIP string
+}
+
+func (u *upstreamNodesUpdate) key() string {
+	return u.cluster.String() + "/" + u.upstream
+}
+
+// endpointBatcher collects the upstream nodes updates and pushes them in
+// batches, so that the frequent endpoints changes cause fewer admin calls.
+// A batch is pushed when the window elapses since its first update, or
+// when the number of pending upstreams reaches the max size. Updates of
+// the same upstream are merged, only the latest nodes are pushed.
+type endpointBatcher struct {
+	window time.Duration
+	// max is the maximum number of upstreams in a batch, zero means no limit.
+	max  int
+	push func(context.Context, *upstreamNodesUpdate) error
+
+	mu      sync.Mutex
+	pending map[string]*upstreamNodesUpdate
+	order   []string
+	// notify is signaled when the first update of a batch arrives.
+	notify chan struct{}
+	// full is signaled when the pending updates reach the max size.
+	full chan struct{}
+}
+
+func newEndpointBatcher(window time.Duration, max int, push func(context.Context, *upstreamNodesUpdate) error) *endpointBatcher {
+	return &endpointBatcher{
+		window:  window,
+		max:     max,
+		push:    push,
+		pending: make(map[string]*upstreamNodesUpdate),
+		notify:  make(chan struct{}, 1),
+		full:    make(chan struct{}, 1),
+	}
+}
+
+// add queues an update, it replaces the pending update of the same upstream.
+func (b *endpointBatcher) add(u *upstreamNodesUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.addLocked(u)
+}
+
+func (b *endpointBatcher) addLocked(u *upstreamNodesUpdate) {
+	key := u.key()
+	if _, ok := b.pending[key]; !ok {
+		b.order = append(b.order, key)
+	}
+	b.pending[key] = u
+	b.signalLocked()
+}
+
+func (b *endpointBatcher) signalLocked() {
+	if len(b.order) == 0 {
+		return
+	}
+	trySignal(b.notify)
+	if b.max > 0 && len(b.order) >= b.max {
+		trySignal(b.full)
+	}
+}
+
+func trySignal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// take removes at most max pending updates in the arrival order.
+func (b *endpointBatcher) take() []*upstreamNodesUpdate {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// The signals belong to the taken updates.
+	select {
+	case <-b.full:
+	default:
+	}
+	select {
+	case <-b.notify:
+	default:
+	}
+
+	n := len(b.order)
+	if b.max > 0 && n > b.max {
+		n = b.max
+	}
+	batch := make([]*upstreamNodesUpdate, 0, n)
+	for _, key := range b.order[:n] {
+		batch = append(batch, b.pending[key])
+		delete(b.pending, key)
+	}
+	b.order = append([]string(nil), b.order[n:]...)
+	// The remaining updates start a new batch.
+	b.signalLocked()
+	return batch
+}
+
+// flush pushes a batch, failures are isolated per upstream, the failed
+// updates are retried in the next batch unless newer ones arrive.
+func (b *endpointBatcher) flush(ctx context.Context) {
+	batch := b.take()
+	var failed []*upstreamNodesUpdate
+	for _, u := range batch {
+		if err := b.push(ctx, u); err != nil {
+			log.Errorw("failed to push upstream nodes in batch, will retry",
+				zap.String("cluster", u.cluster.String()),
+				zap.String("upstream", u.upstream),
+				zap.Error(err),
+			)
+			failed = append(failed, u)
+		}
+	}
+	if len(failed) == 0 || ctx.Err() != nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, u := range failed {
+		if _, ok := b.pending[u.key()]; !ok {
+			b.addLocked(u)
+		}
+	}
+}
+
+func (b *endpointBatcher) run(ctx context.Context) {
+	log.Infow("endpoint batcher started",
+		zap.Duration("window", b.window),
+		zap.Int("max", b.max),
+	)
+	defer log.Info("endpoint batcher exited")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-b.notify:
+		}
+
+		timer := time.NewTimer(b.window)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case <-b.full:
+			timer.Stop()
+		}
+		b.flush(ctx)
+	}
+}
@@ -0,0 +1,146 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+)
+
+type fakeNamedCluster struct {
+	apisix.Cluster
+	name string
+}
+
+func (f *fakeNamedCluster) String() string {
+	return f.name
+}
+
+func newTestEndpointBatcher(window time.Duration, max int, push func(*upstreamNodesUpdate) error) (*endpointBatcher, chan string) {
+	pushed := make(chan string, 16)
+	b := newEndpointBatcher(window, max, func(_ context.Context, u *upstreamNodesUpdate) error {
+		if err := push(u); err != nil {
+			return err
+		}
+		pushed <- u.upstream
+		return nil
+	})
+	return b, pushed
+}
+
+func receiveUpstreams(t *testing.T, ch chan string, n int, timeout time.Duration) []string {
+	var names []string
+	for i := 0; i < n; i++ {
+		select {
+		case name := <-ch:
+			names = append(names, name)
+		case <-time.After(timeout):
+			t.Fatalf("expected %d upstreams to be pushed, got %v", n, names)
+		}
+	}
+	return names
+}
+
+func assertNothingPushed(t *testing.T, ch chan string, wait time.Duration) {
+	select {
+	case name := <-ch:
+		t.Fatalf("unexpected push of upstream %s", name)
+	case <-time.After(wait):
+	}
+}
+
+func TestEndpointBatcherWindow(t *testing.T) {
+	cluster := &fakeNamedCluster{name: "default"}
+	b, pushed := newTestEndpointBatcher(300*time.Millisecond, 0, func(*upstreamNodesUpdate) error { return nil })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.run(ctx)
+
+	start := time.Now()
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "a"})
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "b"})
+	// Updates of the same upstream are merged.
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "a"})
+
+	assertNothingPushed(t, pushed, 100*time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, receiveUpstreams(t, pushed, 2, time.Second))
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	assertNothingPushed(t, pushed, 400*time.Millisecond)
+}
+
+func TestEndpointBatcherMax(t *testing.T) {
+	cluster := &fakeNamedCluster{name: "default"}
+	b, pushed := newTestEndpointBatcher(time.Hour, 2, func(*upstreamNodesUpdate) error { return nil })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.run(ctx)
+
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "a"})
+	assertNothingPushed(t, pushed, 100*time.Millisecond)
+
+	// The batch is full, it's pushed without waiting for the window.
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "b"})
+	assert.Equal(t, []string{"a", "b"}, receiveUpstreams(t, pushed, 2, time.Second))
+
+	// A new batch is started, it's not full yet.
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "c"})
+	assertNothingPushed(t, pushed, 100*time.Millisecond)
+}
+
+func TestEndpointBatcherTakeRespectsMax(t *testing.T) {
+	cluster := &fakeNamedCluster{name: "default"}
+	b := newEndpointBatcher(time.Hour, 2, nil)
+	for _, name := range []string{"a", "b", "c"} {
+		b.add(&upstreamNodesUpdate{cluster: cluster, upstream: name})
+	}
+
+	batch := b.take()
+	assert.Len(t, batch, 2)
+	assert.Equal(t, "a", batch[0].upstream)
+	assert.Equal(t, "b", batch[1].upstream)
+
+	batch = b.take()
+	assert.Len(t, batch, 1)
+	assert.Equal(t, "c", batch[0].upstream)
+	assert.Len(t, b.take(), 0)
+}
+
+func TestEndpointBatcherErrorIsolation(t *testing.T) {
+	cluster := &fakeNamedCluster{name: "default"}
+	failures := 1
+	b, pushed := newTestEndpointBatcher(100*time.Millisecond, 0, func(u *upstreamNodesUpdate) error {
+		if u.upstream == "a" && failures > 0 {
+			failures--
+			return errors.New("injected error")
+		}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.run(ctx)
+
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "a"})
+	b.add(&upstreamNodesUpdate{cluster: cluster, upstream: "b"})
+
+	// The failure of "a" doesn't block "b", and "a" is retried in the next batch.
+	assert.Equal(t, []string{"b"}, receiveUpstreams(t, pushed, 1, time.Second))
+	assert.Equal(t, []string{"a"}, receiveUpstreams(t, pushed, 1, time.Second))
+}
@@ -0,0 +1,111 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"sync"
+	"time"
+)
+
+// endpointDebouncer coalesces the rapid endpoints events of a Service, an
+// event is enqueued only after no newer events of the same Service arrive
+// within the window, and only the latest one is enqueued. The syncs always
+// read the latest endpoints, so the final state is still pushed. The pending
+// event is enqueued once it's delayed for maxWait anyway.
+type endpointDebouncer struct {
+	window  time.Duration
+	maxWait time.Duration
+	enqueue func(interface{})
+
+	mu    sync.Mutex
+	items map[string]*debouncedEvent
+}
+
+type debouncedEvent struct {
+	timer *time.Timer
+	obj   interface{}
+	// deadline is when the event must be enqueued even if the Service
+	// keeps changing.
+	deadline time.Time
+}
+
+// newEndpointDebouncer creates an endpointDebouncer, maxWait defaults to ten
+// times the window if it's not positive.
+func newEndpointDebouncer(window, maxWait time.Duration, enqueue func(interface{})) *endpointDebouncer {
+	if maxWait <= 0 {
+		maxWait = 10 * window
+	}
+	return &endpointDebouncer{
+		window:  window,
+		maxWait: maxWait,
+		enqueue: enqueue,
+		items:   make(map[string]*debouncedEvent),
+	}
+}
+
+// add replaces the pending event of the Service with obj and restarts its
+// window, but not beyond the deadline of the pending event.
+func (d *endpointDebouncer) add(key string, obj interface{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if item, ok := d.items[key]; ok {
+		item.obj = obj
+		// The timer might have fired, then the latest obj is enqueued by
+		// it right away.
+		if item.timer.Stop() {
+			delay := d.window
+			if left := time.Until(item.deadline); left < delay {
+				delay = left
+			}
+			item.timer.Reset(delay)
+		}
+		return
+	}
+	item := &debouncedEvent{
+		obj:      obj,
+		deadline: time.Now().Add(d.maxWait),
+	}
+	item.timer = time.AfterFunc(d.window, func() {
+		d.fire(key, item)
+	})
+	d.items[key] = item
+}
+
+func (d *endpointDebouncer) fire(key string, item *debouncedEvent) {
+	d.mu.Lock()
+	if d.items[key] != item {
+		// Canceled.
+		d.mu.Unlock()
+		return
+	}
+	delete(d.items, key)
+	obj := item.obj
+	d.mu.Unlock()
+
+	d.enqueue(obj)
+}
+
+// cancel drops the pending event of the Service, e.g. when an event which
+// shouldn't be delayed is enqueued directly.
+func (d *endpointDebouncer) cancel(key string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if item, ok := d.items[key]; ok {
+		item.timer.Stop()
+		delete(d.items, key)
+	}
+}
@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointDebouncerCoalesces(t *testing.T) {
+	enqueued := make(chan interface{}, 16)
+	d := newEndpointDebouncer(50*time.Millisecond, 0, func(obj interface{}) {
+		enqueued <- obj
+	})
+
+	for i := 0; i < 5; i++ {
+		d.add("default/httpbin", i)
+		time.Sleep(10 * time.Millisecond)
+	}
+	d.add("default/nginx", "nginx")
+
+	var got []interface{}
+	for i := 0; i < 2; i++ {
+		select {
+		case obj := <-enqueued:
+			got = append(got, obj)
+		case <-time.After(time.Second):
+			t.Fatalf("expected 2 events to be enqueued, got %v", got)
+		}
+	}
+	assert.ElementsMatch(t, []interface{}{4, "nginx"}, got)
+
+	select {
+	case obj := <-enqueued:
+		t.Fatalf("unexpected event %v", obj)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestEndpointDebouncerCancel(t *testing.T) {
+	enqueued := make(chan interface{}, 16)
+	d := newEndpointDebouncer(20*time.Millisecond, 0, func(obj interface{}) {
+		enqueued <- obj
+	})
+
+	d.add("default/httpbin", "update")
+	d.cancel("default/httpbin")
+	select {
+	case obj := <-enqueued:
+		t.Fatalf("unexpected event %v", obj)
+	case <-time.After(60 * time.Millisecond):
+	}
+
+	// The Service can be debounced again after canceled.
+	d.add("default/httpbin", "update")
+	select {
+	case obj := <-enqueued:
+		assert.Equal(t, "update", obj)
+	case <-time.After(time.Second):
+		t.Fatal("expected the event to be enqueued")
+	}
+}
+
+func TestEndpointDebouncerMaxWait(t *testing.T) {
+	enqueued := make(chan interface{}, 16)
+	d := newEndpointDebouncer(50*time.Millisecond, 100*time.Millisecond, func(obj interface{}) {
+		enqueued <- obj
+	})
+
+	// The Service keeps changing within the window, it's still flushed
+	// once the max wait is reached.
+	start := time.Now()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; time.Since(start) < 300*time.Millisecond; i++ {
+			d.add("default/httpbin", i)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	select {
+	case <-enqueued:
+		assert.Less(t, time.Since(start), 250*time.Millisecond)
+	case <-time.After(time.Second):
+		t.Fatal("expected the event to be flushed by the max wait")
+	}
+	<-done
+}
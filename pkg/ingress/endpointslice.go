@@ -40,14 +40,18 @@ type endpointSliceEvent struct {
 type endpointSliceController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
+	// debouncer coalesces the rapid changes of the same Service, it's nil
+	// if the debounce window isn't configured.
+	debouncer *endpointDebouncer
 }
 
 func (c *Controller) newEndpointSliceController() *endpointSliceController {
 	ctl := &endpointSliceController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(time.Second, 60*time.Second, 5), "endpointSlice"),
-		workers:    1,
+	}
+	if c.cfg.EndpointDebounceWindow.Duration > 0 {
+		ctl.debouncer = newEndpointDebouncer(c.cfg.EndpointDebounceWindow.Duration, c.cfg.EndpointDebounceMaxWait.Duration, ctl.workqueue.Add)
 	}
 
 	ctl.controller.epInformer.AddEventHandler(
@@ -71,9 +75,9 @@ func (c *endpointSliceController) run(ctx context.Context) {
 		return
 	}
 
-	handler := func() {
+	handler := func(ctx context.Context, stop <-chan struct{}) {
 		for {
-			obj, shutdown := c.workqueue.Get()
+			obj, shutdown := nextEvent(c.workqueue, stop)
 			if shutdown {
 				return
 			}
@@ -84,11 +88,7 @@ func (c *endpointSliceController) run(ctx context.Context) {
 		}
 	}
 
-	for i := 0; i < c.workers; i++ {
-		go handler()
-	}
-
-	<-ctx.Done()
+	c.controller.runWorkers(ctx, c.workqueue, handler)
 }
 
 func (c *endpointSliceController) sync(ctx context.Context, ev *types.Event) error {
@@ -152,7 +152,7 @@ func (c *endpointSliceController) onAdd(obj interface{}) {
 		zap.String("object-key", key),
 	)
 
-	c.workqueue.Add(&types.Event{
+	c.enqueue(ep.Namespace+"/"+svcName, &types.Event{
 		Type: types.EventAdd,
 		Object: endpointSliceEvent{
 			Key:         key,
@@ -192,7 +192,7 @@ func (c *endpointSliceController) onUpdate(prev, curr interface{}) {
 		zap.Any("new object", currEp),
 		zap.Any("old object", prevEp),
 	)
-	c.workqueue.Add(&types.Event{
+	c.enqueue(currEp.Namespace+"/"+svcName, &types.Event{
 		Type: types.EventUpdate,
 		// TODO pass key.
 		Object: endpointSliceEvent{
@@ -231,6 +231,11 @@ func (c *endpointSliceController) onDelete(obj interface{}) {
 	log.Debugw("endpoints delete event arrived",
 		zap.Any("object-key", key),
 	)
+	// The delete event isn't delayed, it supersedes the pending changes
+	// since the sync reads all the latest endpointSlices of the Service.
+	if c.debouncer != nil {
+		c.debouncer.cancel(ep.Namespace + "/" + svcName)
+	}
 	c.workqueue.Add(&types.Event{
 		Type: types.EventDelete,
 		Object: endpointSliceEvent{
@@ -241,3 +246,13 @@ func (c *endpointSliceController) onDelete(obj interface{}) {
 
 	c.controller.MetricsCollector.IncrEvents("endpointSlice", "delete")
 }
+
+// enqueue adds the event to the workqueue, after the debounce window if
+// it's configured. The endpointSlices are debounced by their Service.
+func (c *endpointSliceController) enqueue(svcKey string, ev *types.Event) {
+	if c.debouncer == nil {
+		c.workqueue.Add(ev)
+		return
+	}
+	c.debouncer.add(svcKey, ev)
+}
@@ -0,0 +1,343 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// _apisixFinalizer blocks the deletion of an object until the APISIX
+	// resources translated from it are deleted.
+	_apisixFinalizer = "apisix.apache.org/finalizer"
+	// _resourceFinalizeTimeout is used when the finalizer is removed without
+	// the APISIX resources being deleted.
+	_resourceFinalizeTimeout = "FinalizeTimeout"
+	// _finalizerReleaseInterval is the interval to check the objects being
+	// deleted which are not handled by this controller.
+	_finalizerReleaseInterval = time.Minute
+)
+
+// hasFinalizer returns true if the object has the APISIX finalizer.
+func hasFinalizer(meta metav1.Object) bool {
+	for _, f := range meta.GetFinalizers() {
+		if f == _apisixFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// isFinalizing returns true if the object is being deleted, but the deletion
+// is still blocked by the APISIX finalizer.
+func isFinalizing(meta metav1.Object) bool {
+	return meta.GetDeletionTimestamp() != nil && hasFinalizer(meta)
+}
+
+// ensureFinalizer adds the APISIX finalizer to the object if finalizers are
+// enabled and the object is not being deleted.
+func (c *Controller) ensureFinalizer(ctx context.Context, meta metav1.Object) error {
+	if !c.cfg.Kubernetes.EnableFinalizers {
+		return nil
+	}
+	if meta.GetDeletionTimestamp() != nil || hasFinalizer(meta) {
+		return nil
+	}
+	finalizers := append(append([]string{}, meta.GetFinalizers()...), _apisixFinalizer)
+	return c.patchFinalizers(ctx, meta, finalizers)
+}
+
+// finalize removes the APISIX finalizer from the object being deleted after
+// its APISIX resources were deleted, i.e. syncErr is nil. In case the deletion
+// keeps failing after the finalizer timeout, the finalizer is removed anyway
+// so that the object deletion won't be blocked forever. It's a no-op for
+// objects without the finalizer, so the syncErr is returned as is.
+func (c *Controller) finalize(ctx context.Context, meta metav1.Object, syncErr error) error {
+	if !isFinalizing(meta) {
+		return syncErr
+	}
+	if syncErr != nil {
+		if time.Since(meta.GetDeletionTimestamp().Time) < c.cfg.Kubernetes.FinalizerTimeout.Duration {
+			return syncErr
+		}
+		log.Errorw("failed to delete APISIX resources before the finalizer timeout, removing the finalizer anyway",
+			zap.String("namespace", meta.GetNamespace()),
+			zap.String("name", meta.GetName()),
+			zap.Error(syncErr),
+		)
+		c.recorderEventS(meta.(runtime.Object), v1.EventTypeWarning, _resourceFinalizeTimeout,
+			fmt.Sprintf("failed to delete APISIX resources before the finalizer timeout: %s", syncErr))
+	}
+	return c.removeFinalizer(ctx, meta)
+}
+
+// removeFinalizer removes the APISIX finalizer from the object.
+func (c *Controller) removeFinalizer(ctx context.Context, meta metav1.Object) error {
+	var finalizers []string
+	for _, f := range meta.GetFinalizers() {
+		if f != _apisixFinalizer {
+			finalizers = append(finalizers, f)
+		}
+	}
+	return c.patchFinalizers(ctx, meta, finalizers)
+}
+
+// releaseFinalizersLoop removes the APISIX finalizer periodically from the
+// objects being deleted which are not handled by this controller.
+func (c *Controller) releaseFinalizersLoop(ctx context.Context) {
+	ticker := time.NewTicker(_finalizerReleaseInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.releaseFinalizers(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// releaseFinalizers removes the APISIX finalizer from the objects being
+// deleted which are not handled by this controller, i.e. their namespaces
+// are paused or no longer watched, or their controllers are disabled, once
+// the finalizer timeout elapses. The APISIX resources translated from them
+// are left untouched, but the deletions won't be blocked forever.
+func (c *Controller) releaseFinalizers(ctx context.Context) {
+	for _, kind := range []string{
+		config.ControllerApisixRoute,
+		config.ControllerApisixTls,
+		config.ControllerApisixConsumer,
+		config.ControllerApisixPluginConfig,
+	} {
+		objs, err := c.listFinalizerObjects(ctx, kind)
+		if err != nil {
+			log.Errorw("failed to list objects with finalizers",
+				zap.String("controller", kind),
+				zap.Error(err),
+			)
+			continue
+		}
+		enabled := c.cfg.Kubernetes.IsControllerEnabled(kind)
+		for _, meta := range objs {
+			if !isFinalizing(meta) {
+				continue
+			}
+			if enabled && c.isWatchingNamespace(meta.GetNamespace()+"/"+meta.GetName()) {
+				continue
+			}
+			if time.Since(meta.GetDeletionTimestamp().Time) < c.cfg.Kubernetes.FinalizerTimeout.Duration {
+				continue
+			}
+			log.Warnw("removing the finalizer of the object not handled by the controller",
+				zap.String("controller", kind),
+				zap.String("namespace", meta.GetNamespace()),
+				zap.String("name", meta.GetName()),
+			)
+			c.recorderEventS(meta.(runtime.Object), v1.EventTypeWarning, _resourceFinalizeTimeout,
+				"the finalizer is removed since the object is not handled by the controller")
+			// The failures are logged, it's retried in the next round.
+			_ = c.removeFinalizer(ctx, meta)
+		}
+	}
+}
+
+// listFinalizerObjects lists the objects of the resource controller in all
+// namespaces, they are listed from the API server since the informers of
+// the disabled controllers are not started.
+func (c *Controller) listFinalizerObjects(ctx context.Context, kind string) ([]metav1.Object, error) {
+	var (
+		client = c.kubeClient.APISIXClient
+		kc     = &c.cfg.Kubernetes
+		opts   = metav1.ListOptions{}
+		objs   []metav1.Object
+	)
+	switch kind {
+	case config.ControllerApisixRoute:
+		switch kc.ApisixRouteVersion {
+		case config.ApisixRouteV2beta2:
+			list, err := client.ApisixV2beta2().ApisixRoutes(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		case config.ApisixRouteV2beta3:
+			list, err := client.ApisixV2beta3().ApisixRoutes(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		default:
+			list, err := client.ApisixV2().ApisixRoutes(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	case config.ControllerApisixTls:
+		if kc.ApisixTlsVersion == config.ApisixV2beta3 {
+			list, err := client.ApisixV2beta3().ApisixTlses(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		} else {
+			list, err := client.ApisixV2().ApisixTlses(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	case config.ControllerApisixConsumer:
+		if kc.ApisixConsumerVersion == config.ApisixV2beta3 {
+			list, err := client.ApisixV2beta3().ApisixConsumers(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		} else {
+			list, err := client.ApisixV2().ApisixConsumers(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	case config.ControllerApisixPluginConfig:
+		if kc.ApisixPluginConfigVersion == config.ApisixV2beta3 {
+			list, err := client.ApisixV2beta3().ApisixPluginConfigs(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		} else {
+			list, err := client.ApisixV2().ApisixPluginConfigs(metav1.NamespaceAll).List(ctx, opts)
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+		}
+	}
+	return objs, nil
+}
+
+// patchFinalizers replaces the finalizers of the object, the patch fails with
+// a conflict if the object was changed since it's observed.
+func (c *Controller) patchFinalizers(ctx context.Context, meta metav1.Object, finalizers []string) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"finalizers":      finalizers,
+			"resourceVersion": meta.GetResourceVersion(),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var (
+		client    = c.kubeClient.APISIXClient
+		namespace = meta.GetNamespace()
+		name      = meta.GetName()
+		pt        = k8stypes.MergePatchType
+		opts      = metav1.PatchOptions{}
+	)
+	switch meta.(type) {
+	case *configv2beta2.ApisixRoute:
+		_, err = client.ApisixV2beta2().ApisixRoutes(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2beta3.ApisixRoute:
+		_, err = client.ApisixV2beta3().ApisixRoutes(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2.ApisixRoute:
+		_, err = client.ApisixV2().ApisixRoutes(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2beta3.ApisixTls:
+		_, err = client.ApisixV2beta3().ApisixTlses(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2.ApisixTls:
+		_, err = client.ApisixV2().ApisixTlses(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2beta3.ApisixConsumer:
+		_, err = client.ApisixV2beta3().ApisixConsumers(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2.ApisixConsumer:
+		_, err = client.ApisixV2().ApisixConsumers(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2beta3.ApisixPluginConfig:
+		_, err = client.ApisixV2beta3().ApisixPluginConfigs(namespace).Patch(ctx, name, pt, data, opts)
+	case *configv2.ApisixPluginConfig:
+		_, err = client.ApisixV2().ApisixPluginConfigs(namespace).Patch(ctx, name, pt, data, opts)
+	default:
+		return fmt.Errorf("unsupported object type %T for finalizers", meta)
+	}
+	if err != nil {
+		log.Errorw("failed to patch finalizers",
+			zap.String("namespace", namespace),
+			zap.String("name", name),
+			zap.Strings("finalizers", finalizers),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// apisixTlsMeta returns the object meta of the ApisixTls.
+func apisixTlsMeta(tls kube.ApisixTls) metav1.Object {
+	if tls.GroupVersion() == config.ApisixV2beta3 {
+		return tls.V2beta3()
+	}
+	return tls.V2()
+}
+
+// apisixConsumerMeta returns the object meta of the ApisixConsumer.
+func apisixConsumerMeta(ac kube.ApisixConsumer) metav1.Object {
+	if ac.GroupVersion() == config.ApisixV2beta3 {
+		return ac.V2beta3()
+	}
+	return ac.V2()
+}
+
+// apisixPluginConfigMeta returns the object meta of the ApisixPluginConfig.
+func apisixPluginConfigMeta(apc kube.ApisixPluginConfig) metav1.Object {
+	if apc.GroupVersion() == config.ApisixV2beta3 {
+		return apc.V2beta3()
+	}
+	return apc.V2()
+}
@@ -0,0 +1,137 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+)
+
+func TestFinalizer(t *testing.T) {
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "httpbin",
+			Namespace:  "default",
+			Finalizers: []string{"foo"},
+		},
+	}
+	apisixClient := fakeapisix.NewSimpleClientset(ar)
+	recorder := record.NewFakeRecorder(10)
+	cfg := config.NewDefaultConfig()
+	c := &Controller{
+		cfg:        cfg,
+		recorder:   recorder,
+		kubeClient: &kube.KubeClient{APISIXClient: apisixClient},
+	}
+	ctx := context.Background()
+	get := func() *configv2.ApisixRoute {
+		obj, err := apisixClient.ApisixV2().ApisixRoutes("default").Get(ctx, "httpbin", metav1.GetOptions{})
+		assert.Nil(t, err)
+		return obj
+	}
+
+	// Finalizers are disabled.
+	assert.Nil(t, c.ensureFinalizer(ctx, ar))
+	assert.Equal(t, []string{"foo"}, get().Finalizers)
+
+	cfg.Kubernetes.EnableFinalizers = true
+	assert.Nil(t, c.ensureFinalizer(ctx, ar))
+	ar = get()
+	assert.Equal(t, []string{"foo", _apisixFinalizer}, ar.Finalizers)
+	assert.False(t, isFinalizing(ar))
+
+	// The deletion of APISIX resources failed, retry it.
+	syncErr := errors.New("connection refused")
+	ar.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.True(t, isFinalizing(ar))
+	assert.Equal(t, syncErr, c.finalize(ctx, ar, syncErr))
+	assert.Equal(t, []string{"foo", _apisixFinalizer}, get().Finalizers)
+	assert.Len(t, recorder.Events, 0)
+
+	// The finalizer is removed anyway after the timeout.
+	ar.DeletionTimestamp = &metav1.Time{Time: time.Now().Add(-cfg.Kubernetes.FinalizerTimeout.Duration)}
+	assert.Nil(t, c.finalize(ctx, ar, syncErr))
+	assert.Equal(t, []string{"foo"}, get().Finalizers)
+	assert.Contains(t, <-recorder.Events, "Warning FinalizeTimeout failed to delete APISIX resources before the finalizer timeout: connection refused")
+
+	// The finalizer is removed once the APISIX resources were deleted.
+	assert.Nil(t, c.ensureFinalizer(ctx, get()))
+	ar = get()
+	ar.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Nil(t, c.finalize(ctx, ar, nil))
+	assert.Equal(t, []string{"foo"}, get().Finalizers)
+
+	// Objects without the finalizer are not touched.
+	ar = get()
+	ar.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	assert.Equal(t, syncErr, c.finalize(ctx, ar, syncErr))
+}
+
+func TestReleaseFinalizers(t *testing.T) {
+	newRoute := func(namespace, name string, deleted time.Time) *configv2.ApisixRoute {
+		return &configv2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				Finalizers:        []string{_apisixFinalizer},
+				DeletionTimestamp: &metav1.Time{Time: deleted},
+			},
+		}
+	}
+	cfg := config.NewDefaultConfig()
+	cfg.Kubernetes.ApisixRouteVersion = config.ApisixRouteV2
+	expired := time.Now().Add(-cfg.Kubernetes.FinalizerTimeout.Duration)
+	apisixClient := fakeapisix.NewSimpleClientset(
+		newRoute("default", "watched", expired),
+		newRoute("other", "expired", expired),
+		newRoute("other", "recent", time.Now()),
+	)
+	c := &Controller{
+		cfg:               cfg,
+		recorder:          record.NewFakeRecorder(10),
+		kubeClient:        &kube.KubeClient{APISIXClient: apisixClient},
+		namespaceProvider: namespace.NewMockWatchingProvider([]string{"default"}),
+	}
+	ctx := context.Background()
+	finalizers := func(namespace, name string) []string {
+		obj, err := apisixClient.ApisixV2().ApisixRoutes(namespace).Get(ctx, name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		return obj.Finalizers
+	}
+
+	c.releaseFinalizers(ctx)
+	// The watched ones are finalized by the resource controller.
+	assert.Equal(t, []string{_apisixFinalizer}, finalizers("default", "watched"))
+	assert.Nil(t, finalizers("other", "expired"))
+	assert.Equal(t, []string{_apisixFinalizer}, finalizers("other", "recent"))
+
+	// All of them are released once the controller is disabled.
+	cfg.Kubernetes.EnabledControllers = []string{config.ControllerIngress}
+	c.releaseFinalizers(ctx)
+	assert.Nil(t, finalizers("default", "watched"))
+	assert.Equal(t, []string{_apisixFinalizer}, finalizers("other", "recent"))
+}
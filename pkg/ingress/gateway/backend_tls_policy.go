@@ -0,0 +1,343 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/gateway/types"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// _caCertKey is the key of the CA certificate in the ConfigMaps referenced
+	// by BackendTLSPolicy.
+	_caCertKey = "ca.crt"
+	// _wellKnownCACertsSystem means the system trusted CA certificates.
+	_wellKnownCACertsSystem = "System"
+)
+
+var backendTLSPolicyResource = schema.GroupVersionResource{
+	Group:    "gateway.networking.k8s.io",
+	Version:  "v1alpha2",
+	Resource: "backendtlspolicies",
+}
+
+// backendTLSPolicyController watches BackendTLSPolicy objects and the ConfigMaps
+// they reference, HTTPRoutes backed by the target Services are re-synced once
+// any of them changes.
+type backendTLSPolicyController struct {
+	controller *Provider
+
+	policyInformer    cache.SharedIndexInformer
+	configMapInformer cache.SharedIndexInformer
+	configMapLister   listerscorev1.ConfigMapLister
+
+	policiesLock sync.RWMutex
+	// meta key ("ns/name") of BackendTLSPolicy -> BackendTLSPolicy
+	policies map[string]*types.BackendTLSPolicy
+}
+
+// backendTLSPolicyServed reports whether the BackendTLSPolicy resource is
+// served by the API server, it's not a part of the Gateway API CRDs which
+// this controller is built with.
+func backendTLSPolicyServed(client discovery.DiscoveryInterface) (bool, error) {
+	resources, err := client.ServerResourcesForGroupVersion(backendTLSPolicyResource.GroupVersion().String())
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == backendTLSPolicyResource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func newBackendTLSPolicyController(c *Provider) (*backendTLSPolicyController, error) {
+	dynamicClient, err := dynamic.NewForConfig(c.RestConfig)
+	if err != nil {
+		return nil, err
+	}
+	resync := c.Cfg.Kubernetes.ResyncInterval.Duration
+	dynamicFactory := dynamicinformer.NewDynamicSharedInformerFactory(dynamicClient, resync)
+	kubeFactory := informers.NewSharedInformerFactory(c.KubeClient, resync)
+
+	ctrl := &backendTLSPolicyController{
+		controller:        c,
+		policyInformer:    dynamicFactory.ForResource(backendTLSPolicyResource).Informer(),
+		configMapInformer: kubeFactory.Core().V1().ConfigMaps().Informer(),
+		configMapLister:   kubeFactory.Core().V1().ConfigMaps().Lister(),
+		policies:          make(map[string]*types.BackendTLSPolicy),
+	}
+
+	ctrl.policyInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.onPolicyAdd,
+		UpdateFunc: ctrl.onPolicyUpdate,
+		DeleteFunc: ctrl.onPolicyDelete,
+	})
+	ctrl.configMapInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctrl.onConfigMapChange,
+		UpdateFunc: func(_, newObj interface{}) { ctrl.onConfigMapChange(newObj) },
+		DeleteFunc: ctrl.onConfigMapChange,
+	})
+	return ctrl, nil
+}
+
+func (c *backendTLSPolicyController) run(ctx context.Context) {
+	log.Info("gateway BackendTLSPolicy controller started")
+	defer log.Info("gateway BackendTLSPolicy controller exited")
+
+	go c.policyInformer.Run(ctx.Done())
+	go c.configMapInformer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), c.policyInformer.HasSynced, c.configMapInformer.HasSynced) {
+		log.Error("sync BackendTLSPolicy cache failed")
+		return
+	}
+	<-ctx.Done()
+}
+
+// hasSynced reports whether the policies and the ConfigMaps were listed, the
+// translation of HTTPRoutes should wait for it.
+func (c *backendTLSPolicyController) hasSynced() bool {
+	return c.policyInformer.HasSynced() && c.configMapInformer.HasSynced()
+}
+
+// backendTLS resolves the BackendTLSPolicy attached to the Service. The oldest
+// policy wins if there are multiple policies targeting the same Service.
+func (c *backendTLSPolicyController) backendTLS(namespace, service string) (*types.BackendTLS, error) {
+	c.policiesLock.RLock()
+	var candidates []*types.BackendTLSPolicy
+	for _, policy := range c.policies {
+		if policy.Namespace == namespace && policy.Spec.TargetRef.Name == service {
+			candidates = append(candidates, policy)
+		}
+	}
+	c.policiesLock.RUnlock()
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ti, tj := candidates[i].CreationTimestamp, candidates[j].CreationTimestamp
+		if ti.Equal(&tj) {
+			return candidates[i].Name < candidates[j].Name
+		}
+		return ti.Before(&tj)
+	})
+	return resolveBackendTLSPolicy(candidates[0], c.configMapLister)
+}
+
+// resolveBackendTLSPolicy checks the policy and loads the CA certificates it
+// references.
+func resolveBackendTLSPolicy(policy *types.BackendTLSPolicy, lister listerscorev1.ConfigMapLister) (*types.BackendTLS, error) {
+	tls := policy.Spec.TLS
+	if tls.Hostname == "" {
+		return nil, fmt.Errorf("BackendTLSPolicy %s/%s: empty hostname", policy.Namespace, policy.Name)
+	}
+	if len(tls.CACertRefs) == 0 {
+		if tls.WellKnownCACerts == nil || *tls.WellKnownCACerts != _wellKnownCACertsSystem {
+			return nil, fmt.Errorf("BackendTLSPolicy %s/%s: either caCertRefs or wellKnownCACerts \"System\" should be specified",
+				policy.Namespace, policy.Name)
+		}
+	}
+
+	res := &types.BackendTLS{
+		Hostname: tls.Hostname,
+	}
+	for _, ref := range tls.CACertRefs {
+		if ref.Group != "" || ref.Kind != "ConfigMap" {
+			return nil, fmt.Errorf("BackendTLSPolicy %s/%s: unsupported caCertRef %s/%s, only ConfigMap is supported",
+				policy.Namespace, policy.Name, ref.Kind, ref.Name)
+		}
+		cm, err := lister.ConfigMaps(policy.Namespace).Get(ref.Name)
+		if err != nil {
+			return nil, fmt.Errorf("BackendTLSPolicy %s/%s: failed to get caCertRef ConfigMap %s: %s",
+				policy.Namespace, policy.Name, ref.Name, err)
+		}
+		ca, ok := cm.Data[_caCertKey]
+		if !ok || ca == "" {
+			return nil, fmt.Errorf("BackendTLSPolicy %s/%s: ConfigMap %s has no %s",
+				policy.Namespace, policy.Name, ref.Name, _caCertKey)
+		}
+		res.CACerts = append(res.CACerts, ca)
+	}
+	return res, nil
+}
+
+// convertBackendTLSPolicy converts the object from the dynamic informer, nil
+// is returned if it doesn't target a Service in its namespace.
+func convertBackendTLSPolicy(obj interface{}) *types.BackendTLSPolicy {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	var policy types.BackendTLSPolicy
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &policy); err != nil {
+		log.Errorw("failed to convert BackendTLSPolicy",
+			zap.Error(err),
+			zap.String("namespace", u.GetNamespace()),
+			zap.String("name", u.GetName()),
+		)
+		return nil
+	}
+	ref := policy.Spec.TargetRef
+	if ref.Group != "" || ref.Kind != "Service" {
+		log.Warnw("ignore BackendTLSPolicy targeting non-Service object",
+			zap.String("namespace", policy.Namespace),
+			zap.String("name", policy.Name),
+			zap.String("kind", ref.Kind),
+		)
+		return nil
+	}
+	if ref.Namespace != nil && *ref.Namespace != policy.Namespace {
+		log.Warnw("ignore BackendTLSPolicy targeting Service in other namespace",
+			zap.String("namespace", policy.Namespace),
+			zap.String("name", policy.Name),
+		)
+		return nil
+	}
+	if ref.SectionName != nil {
+		log.Warnw("sectionName of BackendTLSPolicy is not supported, the policy applies to all ports",
+			zap.String("namespace", policy.Namespace),
+			zap.String("name", policy.Name),
+		)
+	}
+	return &policy
+}
+
+func (c *backendTLSPolicyController) onPolicyAdd(obj interface{}) {
+	c.setPolicy(nil, convertBackendTLSPolicy(obj))
+}
+
+func (c *backendTLSPolicyController) onPolicyUpdate(oldObj, newObj interface{}) {
+	oldPolicy := convertBackendTLSPolicy(oldObj)
+	newPolicy := convertBackendTLSPolicy(newObj)
+	if newPolicy == nil {
+		// The policy no longer targets a Service.
+		c.removePolicy(oldPolicy)
+		return
+	}
+	if oldPolicy != nil && oldPolicy.ResourceVersion >= newPolicy.ResourceVersion {
+		return
+	}
+	c.setPolicy(oldPolicy, newPolicy)
+}
+
+func (c *backendTLSPolicyController) onPolicyDelete(obj interface{}) {
+	c.removePolicy(convertBackendTLSPolicy(obj))
+}
+
+func (c *backendTLSPolicyController) setPolicy(oldPolicy, policy *types.BackendTLSPolicy) {
+	if policy == nil {
+		return
+	}
+	if !c.controller.NamespaceProvider.IsWatchingNamespace(policy.Namespace + "/" + policy.Name) {
+		return
+	}
+	c.policiesLock.Lock()
+	c.policies[policy.Namespace+"/"+policy.Name] = policy
+	c.policiesLock.Unlock()
+
+	log.Debugw("BackendTLSPolicy changed",
+		zap.String("namespace", policy.Namespace),
+		zap.String("name", policy.Name),
+	)
+	if oldPolicy != nil && oldPolicy.Spec.TargetRef.Name != policy.Spec.TargetRef.Name {
+		c.controller.gatewayHTTPRouteController.resyncBackend(oldPolicy.Namespace, oldPolicy.Spec.TargetRef.Name)
+	}
+	c.controller.gatewayHTTPRouteController.resyncBackend(policy.Namespace, policy.Spec.TargetRef.Name)
+}
+
+func (c *backendTLSPolicyController) removePolicy(policy *types.BackendTLSPolicy) {
+	if policy == nil {
+		return
+	}
+	key := policy.Namespace + "/" + policy.Name
+	c.policiesLock.Lock()
+	_, ok := c.policies[key]
+	delete(c.policies, key)
+	c.policiesLock.Unlock()
+
+	if ok {
+		log.Debugw("BackendTLSPolicy removed",
+			zap.String("namespace", policy.Namespace),
+			zap.String("name", policy.Name),
+		)
+		c.controller.gatewayHTTPRouteController.resyncBackend(policy.Namespace, policy.Spec.TargetRef.Name)
+	}
+}
+
+// onConfigMapChange re-syncs the Services whose BackendTLSPolicy references
+// the ConfigMap.
+func (c *backendTLSPolicyController) onConfigMapChange(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	cm, ok := obj.(*corev1.ConfigMap)
+	if !ok {
+		return
+	}
+
+	var services []string
+	c.policiesLock.RLock()
+	for _, policy := range c.policies {
+		if policy.Namespace != cm.Namespace {
+			continue
+		}
+		for _, ref := range policy.Spec.TLS.CACertRefs {
+			if ref.Kind == "ConfigMap" && ref.Name == cm.Name {
+				services = append(services, policy.Spec.TargetRef.Name)
+				break
+			}
+		}
+	}
+	c.policiesLock.RUnlock()
+
+	for _, svc := range services {
+		log.Debugw("CA certificate ConfigMap of BackendTLSPolicy changed",
+			zap.String("namespace", cm.Namespace),
+			zap.String("configmap", cm.Name),
+			zap.String("service", svc),
+		)
+		c.controller.gatewayHTTPRouteController.resyncBackend(cm.Namespace, svc)
+	}
+}
@@ -0,0 +1,154 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/gateway/types"
+)
+
+func TestConvertBackendTLSPolicy(t *testing.T) {
+	u := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1alpha2",
+		"kind":       "BackendTLSPolicy",
+		"metadata": map[string]interface{}{
+			"name":      "policy",
+			"namespace": "default",
+		},
+		"spec": map[string]interface{}{
+			"targetRef": map[string]interface{}{
+				"group": "",
+				"kind":  "Service",
+				"name":  "httpbin",
+			},
+			"tls": map[string]interface{}{
+				"caCertRefs": []interface{}{
+					map[string]interface{}{"group": "", "kind": "ConfigMap", "name": "ca"},
+				},
+				"hostname": "httpbin.example.com",
+			},
+		},
+	}}
+	policy := convertBackendTLSPolicy(u)
+	assert.NotNil(t, policy)
+	assert.Equal(t, "default", policy.Namespace)
+	assert.Equal(t, "httpbin", policy.Spec.TargetRef.Name)
+	assert.Equal(t, "httpbin.example.com", policy.Spec.TLS.Hostname)
+	assert.Equal(t, []types.BackendTLSPolicyCACertRef{{Kind: "ConfigMap", Name: "ca"}}, policy.Spec.TLS.CACertRefs)
+
+	assert.NotNil(t, convertBackendTLSPolicy(cache.DeletedFinalStateUnknown{Key: "default/policy", Obj: u}))
+
+	// Only Services in the same namespace can be targeted.
+	u.Object["spec"].(map[string]interface{})["targetRef"].(map[string]interface{})["namespace"] = "other"
+	assert.Nil(t, convertBackendTLSPolicy(u))
+	u.Object["spec"].(map[string]interface{})["targetRef"] = map[string]interface{}{
+		"group": "apps",
+		"kind":  "Deployment",
+		"name":  "httpbin",
+	}
+	assert.Nil(t, convertBackendTLSPolicy(u))
+}
+
+func TestResolveBackendTLSPolicy(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.Nil(t, indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ca", Namespace: "default"},
+		Data:       map[string]string{"ca.crt": "CA"},
+	}))
+	assert.Nil(t, indexer.Add(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "empty", Namespace: "default"},
+	}))
+	lister := listerscorev1.NewConfigMapLister(indexer)
+	system := "System"
+
+	policy := func(tls types.BackendTLSPolicyConfig) *types.BackendTLSPolicy {
+		return &types.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy", Namespace: "default"},
+			Spec: types.BackendTLSPolicySpec{
+				TargetRef: types.BackendTLSPolicyTargetRef{Kind: "Service", Name: "httpbin"},
+				TLS:       tls,
+			},
+		}
+	}
+
+	tls, err := resolveBackendTLSPolicy(policy(types.BackendTLSPolicyConfig{
+		CACertRefs: []types.BackendTLSPolicyCACertRef{{Kind: "ConfigMap", Name: "ca"}},
+		Hostname:   "httpbin.example.com",
+	}), lister)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.BackendTLS{Hostname: "httpbin.example.com", CACerts: []string{"CA"}}, tls)
+
+	tls, err = resolveBackendTLSPolicy(policy(types.BackendTLSPolicyConfig{
+		WellKnownCACerts: &system,
+		Hostname:         "httpbin.example.com",
+	}), lister)
+	assert.Nil(t, err)
+	assert.Equal(t, &types.BackendTLS{Hostname: "httpbin.example.com"}, tls)
+
+	for _, cfg := range []types.BackendTLSPolicyConfig{
+		{WellKnownCACerts: &system},
+		{Hostname: "httpbin.example.com"},
+		{CACertRefs: []types.BackendTLSPolicyCACertRef{{Kind: "Secret", Name: "ca"}}, Hostname: "httpbin.example.com"},
+		{CACertRefs: []types.BackendTLSPolicyCACertRef{{Kind: "ConfigMap", Name: "missing"}}, Hostname: "httpbin.example.com"},
+		{CACertRefs: []types.BackendTLSPolicyCACertRef{{Kind: "ConfigMap", Name: "empty"}}, Hostname: "httpbin.example.com"},
+	} {
+		_, err := resolveBackendTLSPolicy(policy(cfg), lister)
+		assert.NotNil(t, err, cfg)
+	}
+}
+
+func TestBackendTLSPolicyOldestWins(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	system := "System"
+	c := &backendTLSPolicyController{
+		configMapLister: listerscorev1.NewConfigMapLister(indexer),
+		policies:        make(map[string]*types.BackendTLSPolicy),
+	}
+	for i, hostname := range []string{"new.example.com", "old.example.com"} {
+		c.policies["default/"+hostname] = &types.BackendTLSPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              hostname,
+				Namespace:         "default",
+				CreationTimestamp: metav1.Unix(int64(100-i), 0),
+			},
+			Spec: types.BackendTLSPolicySpec{
+				TargetRef: types.BackendTLSPolicyTargetRef{Kind: "Service", Name: "httpbin"},
+				TLS: types.BackendTLSPolicyConfig{
+					WellKnownCACerts: &system,
+					Hostname:         hostname,
+				},
+			},
+		}
+	}
+
+	tls, err := c.backendTLS("default", "httpbin")
+	assert.Nil(t, err)
+	assert.Equal(t, "old.example.com", tls.Hostname)
+
+	tls, err = c.backendTLS("default", "other")
+	assert.Nil(t, err)
+	assert.Nil(t, tls)
+}
@@ -16,10 +16,13 @@ package gateway
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"go.uber.org/zap"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
@@ -56,7 +59,11 @@ func (c *gatewayHTTPRouteController) run(ctx context.Context) {
 	defer log.Info("gateway HTTPRoute controller exited")
 	defer c.workqueue.ShutDown()
 
-	if !cache.WaitForCacheSync(ctx.Done(), c.controller.gatewayHTTPRouteInformer.HasSynced) {
+	synced := []cache.InformerSynced{c.controller.gatewayHTTPRouteInformer.HasSynced}
+	if c.controller.backendTLSPolicyController != nil {
+		synced = append(synced, c.controller.backendTLSPolicyController.hasSynced)
+	}
+	if !cache.WaitForCacheSync(ctx.Done(), synced...) {
 		log.Error("sync Gateway HTTPRoute cache failed")
 		return
 	}
@@ -123,8 +130,14 @@ func (c *gatewayHTTPRouteController) sync(ctx context.Context, ev *types.Event)
 		httpRoute = ev.Tombstone.(*gatewayv1alpha2.HTTPRoute)
 	}
 
-	tctx, err := c.controller.translator.TranslateGatewayHTTPRouteV1Alpha2(httpRoute)
-
+	var parents []routeParentResult
+	tctx := translation.DefaultEmptyTranslateContext()
+	if ev.Type == types.EventDelete || c.isAttached(httpRoute, &parents) {
+		tctx, err = c.controller.translator.TranslateGatewayHTTPRouteV1Alpha2(httpRoute)
+	}
+	if ev.Type != types.EventDelete {
+		c.recordStatus(ctx, httpRoute, parents, err)
+	}
 	if err != nil {
 		log.Errorw("failed to translate gateway HTTPRoute",
 			zap.Error(err),
@@ -153,9 +166,11 @@ func (c *gatewayHTTPRouteController) sync(ctx context.Context, ev *types.Event)
 	} else if ev.Type == types.EventAdd {
 		added = m
 	} else {
-		var oldCtx *translation.TranslateContext
+		oldCtx := translation.DefaultEmptyTranslateContext()
 		oldObj := ev.OldObject.(*gatewayv1alpha2.HTTPRoute)
-		oldCtx, err = c.controller.translator.TranslateGatewayHTTPRouteV1Alpha2(oldObj)
+		if c.isAttached(oldObj, nil) {
+			oldCtx, err = c.controller.translator.TranslateGatewayHTTPRouteV1Alpha2(oldObj)
+		}
 		if err != nil {
 			log.Errorw("failed to translate old HTTPRoute",
 				zap.String("version", oldObj.APIVersion),
@@ -176,6 +191,88 @@ func (c *gatewayHTTPRouteController) sync(ctx context.Context, ev *types.Event)
 	return utils.SyncManifests(ctx, c.controller.APISIX, c.controller.APISIXClusterName, added, updated, deleted)
 }
 
+// isAttached reports whether the HTTPRoute is attached to the Gateways of this
+// controller, the resolved parents are stored in parents if it's not nil.
+func (c *gatewayHTTPRouteController) isAttached(httpRoute *gatewayv1alpha2.HTTPRoute, parents *[]routeParentResult) bool {
+	results := c.controller.resolveParentRefs(httpRoute.Namespace, "HTTPRoute", httpRoute.Spec.ParentRefs)
+	if parents != nil {
+		*parents = results
+	}
+	return hasAcceptedParent(httpRoute.Spec.ParentRefs, results)
+}
+
+// recordStatus writes the Accepted and ResolvedRefs conditions for the parent
+// Gateways of this controller.
+func (c *gatewayHTTPRouteController) recordStatus(ctx context.Context, httpRoute *gatewayv1alpha2.HTTPRoute,
+	parents []routeParentResult, translateErr error) {
+	if len(parents) == 0 {
+		return
+	}
+	statuses := routeParentStatuses(httpRoute.Status.Parents, parents, translateErr, httpRoute.Generation)
+	if reflect.DeepEqual(statuses, httpRoute.Status.Parents) {
+		return
+	}
+	httpRoute = httpRoute.DeepCopy()
+	httpRoute.Status.Parents = statuses
+	_, err := c.controller.gatewayClient.GatewayV1alpha2().HTTPRoutes(httpRoute.Namespace).UpdateStatus(ctx, httpRoute, metav1.UpdateOptions{})
+	if err != nil {
+		log.Errorw("failed to record status change for HTTPRoute",
+			zap.Error(err),
+			zap.String("name", httpRoute.Name),
+			zap.String("namespace", httpRoute.Namespace),
+		)
+	}
+}
+
+// resyncBackend re-syncs the HTTPRoutes which have backendRefs to the Service,
+// it's used when the BackendTLSPolicy of the Service changes.
+func (c *gatewayHTTPRouteController) resyncBackend(namespace, service string) {
+	httpRoutes, err := c.controller.gatewayHTTPRouteLister.List(labels.Everything())
+	if err != nil {
+		log.Errorw("failed to list HTTPRoutes",
+			zap.Error(err),
+		)
+		return
+	}
+	for _, httpRoute := range httpRoutes {
+		if !httpRouteReferencesService(httpRoute, namespace, service) {
+			continue
+		}
+		key := httpRoute.Namespace + "/" + httpRoute.Name
+		if !c.controller.NamespaceProvider.IsWatchingNamespace(key) {
+			continue
+		}
+		log.Debugw("resync HTTPRoute for backend change",
+			zap.String("key", key),
+			zap.String("service", namespace+"/"+service),
+		)
+		// Upstreams and routes are created idempotently, so the add event is
+		// used to push the whole HTTPRoute again.
+		c.workqueue.Add(&types.Event{
+			Type:   types.EventAdd,
+			Object: key,
+		})
+	}
+}
+
+func httpRouteReferencesService(httpRoute *gatewayv1alpha2.HTTPRoute, namespace, service string) bool {
+	for _, rule := range httpRoute.Spec.Rules {
+		for _, backend := range rule.BackendRefs {
+			if backend.Kind != nil && *backend.Kind != "Service" {
+				continue
+			}
+			ns := httpRoute.Namespace
+			if backend.Namespace != nil {
+				ns = string(*backend.Namespace)
+			}
+			if ns == namespace && string(backend.Name) == service {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (c *gatewayHTTPRouteController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
@@ -220,5 +317,72 @@ func (c *gatewayHTTPRouteController) onAdd(obj interface{}) {
 		Object: key,
 	})
 }
-func (c *gatewayHTTPRouteController) onUpdate(oldObj, newObj interface{}) {}
-func (c *gatewayHTTPRouteController) OnDelete(obj interface{})            {}
+
+func (c *gatewayHTTPRouteController) onUpdate(oldObj, newObj interface{}) {
+	oldRoute := oldObj.(*gatewayv1alpha2.HTTPRoute)
+	newRoute := newObj.(*gatewayv1alpha2.HTTPRoute)
+	if oldRoute.ResourceVersion >= newRoute.ResourceVersion {
+		return
+	}
+	// Status updates (including the ones by this controller) don't change the
+	// generation, they don't need to be synced.
+	if oldRoute.Generation == newRoute.Generation {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(newObj)
+	if err != nil {
+		log.Errorw("found gateway HTTPRoute resource with bad meta namespace key",
+			zap.Error(err),
+		)
+		return
+	}
+	if !c.controller.NamespaceProvider.IsWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("gateway HTTPRoute update event arrived",
+		zap.Any("old object", oldObj),
+		zap.Any("new object", newObj),
+	)
+
+	c.workqueue.Add(&types.Event{
+		Type:      types.EventUpdate,
+		Object:    key,
+		OldObject: oldRoute,
+	})
+}
+
+func (c *gatewayHTTPRouteController) OnDelete(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorw("failed to handle deletion HTTPRoute meta key",
+			zap.Error(err),
+			zap.Any("obj", obj),
+		)
+		return
+	}
+
+	httpRoute, ok := obj.(*gatewayv1alpha2.HTTPRoute)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorw("HTTPRoute in bad tombstone state",
+				zap.String("key", key),
+				zap.Any("obj", obj),
+			)
+			return
+		}
+		httpRoute = tombstone.Obj.(*gatewayv1alpha2.HTTPRoute)
+	}
+	if !c.controller.NamespaceProvider.IsWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("gateway HTTPRoute delete event arrived",
+		zap.Any("final state", httpRoute),
+	)
+
+	c.workqueue.Add(&types.Event{
+		Type:      types.EventDelete,
+		Object:    key,
+		Tombstone: httpRoute,
+	})
+}
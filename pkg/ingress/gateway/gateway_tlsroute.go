@@ -33,10 +33,12 @@ package gateway
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"go.uber.org/zap"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
@@ -140,8 +142,14 @@ func (c *gatewayTLSRouteController) sync(ctx context.Context, ev *types.Event) e
 		tlsRoute = ev.Tombstone.(*gatewayv1alpha2.TLSRoute)
 	}
 
-	tctx, err := c.controller.translator.TranslateGatewayTLSRouteV1Alpha2(tlsRoute)
-
+	var parents []routeParentResult
+	tctx := translation.DefaultEmptyTranslateContext()
+	if ev.Type == types.EventDelete || c.isAttached(tlsRoute, &parents) {
+		tctx, err = c.controller.translator.TranslateGatewayTLSRouteV1Alpha2(tlsRoute)
+	}
+	if ev.Type != types.EventDelete {
+		c.recordStatus(ctx, tlsRoute, parents, err)
+	}
 	if err != nil {
 		log.Warnw("failed to translate gateway TLSRoute",
 			zap.Error(err),
@@ -170,9 +178,11 @@ func (c *gatewayTLSRouteController) sync(ctx context.Context, ev *types.Event) e
 	} else if ev.Type == types.EventAdd {
 		added = m
 	} else {
-		var oldCtx *translation.TranslateContext
+		oldCtx := translation.DefaultEmptyTranslateContext()
 		oldObj := ev.OldObject.(*gatewayv1alpha2.TLSRoute)
-		oldCtx, err = c.controller.translator.TranslateGatewayTLSRouteV1Alpha2(oldObj)
+		if c.isAttached(oldObj, nil) {
+			oldCtx, err = c.controller.translator.TranslateGatewayTLSRouteV1Alpha2(oldObj)
+		}
 		if err != nil {
 			log.Errorw("failed to translate old TLSRoute",
 				zap.String("version", oldObj.APIVersion),
@@ -193,6 +203,40 @@ func (c *gatewayTLSRouteController) sync(ctx context.Context, ev *types.Event) e
 	return utils.SyncManifests(ctx, c.controller.APISIX, c.controller.APISIXClusterName, added, updated, deleted)
 }
 
+// isAttached reports whether the TLSRoute is attached to the passthrough TLS
+// listeners of this controller, the resolved parents are stored in parents if
+// it's not nil.
+func (c *gatewayTLSRouteController) isAttached(tlsRoute *gatewayv1alpha2.TLSRoute, parents *[]routeParentResult) bool {
+	results := c.controller.resolveParentRefs(tlsRoute.Namespace, "TLSRoute", tlsRoute.Spec.ParentRefs)
+	if parents != nil {
+		*parents = results
+	}
+	return hasAcceptedParent(tlsRoute.Spec.ParentRefs, results)
+}
+
+// recordStatus writes the Accepted and ResolvedRefs conditions for the parent
+// Gateways of this controller.
+func (c *gatewayTLSRouteController) recordStatus(ctx context.Context, tlsRoute *gatewayv1alpha2.TLSRoute,
+	parents []routeParentResult, translateErr error) {
+	if len(parents) == 0 {
+		return
+	}
+	statuses := routeParentStatuses(tlsRoute.Status.Parents, parents, translateErr, tlsRoute.Generation)
+	if reflect.DeepEqual(statuses, tlsRoute.Status.Parents) {
+		return
+	}
+	tlsRoute = tlsRoute.DeepCopy()
+	tlsRoute.Status.Parents = statuses
+	_, err := c.controller.gatewayClient.GatewayV1alpha2().TLSRoutes(tlsRoute.Namespace).UpdateStatus(ctx, tlsRoute, metav1.UpdateOptions{})
+	if err != nil {
+		log.Errorw("failed to record status change for TLSRoute",
+			zap.Error(err),
+			zap.String("name", tlsRoute.Name),
+			zap.String("namespace", tlsRoute.Namespace),
+		)
+	}
+}
+
 func (c *gatewayTLSRouteController) handleSyncErr(obj interface{}, err error) {
 	if err == nil {
 		c.workqueue.Forget(obj)
@@ -237,5 +281,72 @@ func (c *gatewayTLSRouteController) onAdd(obj interface{}) {
 		Object: key,
 	})
 }
-func (c *gatewayTLSRouteController) onUpdate(oldObj, newObj interface{}) {}
-func (c *gatewayTLSRouteController) OnDelete(obj interface{})            {}
+
+func (c *gatewayTLSRouteController) onUpdate(oldObj, newObj interface{}) {
+	oldRoute := oldObj.(*gatewayv1alpha2.TLSRoute)
+	newRoute := newObj.(*gatewayv1alpha2.TLSRoute)
+	if oldRoute.ResourceVersion >= newRoute.ResourceVersion {
+		return
+	}
+	// Status updates (including the ones by this controller) don't change the
+	// generation, they don't need to be synced.
+	if oldRoute.Generation == newRoute.Generation {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(newObj)
+	if err != nil {
+		log.Errorw("found gateway TLSRoute resource with bad meta namespace key",
+			zap.Error(err),
+		)
+		return
+	}
+	if !c.controller.NamespaceProvider.IsWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("gateway TLSRoute update event arrived",
+		zap.Any("old object", oldObj),
+		zap.Any("new object", newObj),
+	)
+
+	c.workqueue.Add(&types.Event{
+		Type:      types.EventUpdate,
+		Object:    key,
+		OldObject: oldRoute,
+	})
+}
+
+func (c *gatewayTLSRouteController) OnDelete(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorw("failed to handle deletion TLSRoute meta key",
+			zap.Error(err),
+			zap.Any("obj", obj),
+		)
+		return
+	}
+
+	tlsRoute, ok := obj.(*gatewayv1alpha2.TLSRoute)
+	if !ok {
+		tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+		if !ok {
+			log.Errorw("TLSRoute in bad tombstone state",
+				zap.String("key", key),
+				zap.Any("obj", obj),
+			)
+			return
+		}
+		tlsRoute = tombstone.Obj.(*gatewayv1alpha2.TLSRoute)
+	}
+	if !c.controller.NamespaceProvider.IsWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("gateway TLSRoute delete event arrived",
+		zap.Any("final state", tlsRoute),
+	)
+
+	c.workqueue.Add(&types.Event{
+		Type:      types.EventDelete,
+		Object:    key,
+		Tombstone: tlsRoute,
+	})
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"sync"
 
+	"go.uber.org/zap"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
@@ -38,6 +39,7 @@ import (
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/metrics"
 )
 
@@ -77,6 +79,9 @@ type Provider struct {
 	gatewayTLSRouteController *gatewayTLSRouteController
 	gatewayTLSRouteInformer   cache.SharedIndexInformer
 	gatewayTLSRouteLister     gatewaylistersv1alpha2.TLSRouteLister
+
+	// nil if BackendTLSPolicy is not served by the API server.
+	backendTLSPolicyController *backendTLSPolicyController
 }
 
 type ProviderOptions struct {
@@ -115,11 +120,11 @@ func NewGatewayProvider(opts *ProviderOptions) (*Provider, error) {
 
 		ProviderOptions: opts,
 		gatewayClient:   gatewayKubeClient,
-
-		translator: gatewaytranslation.NewTranslator(&gatewaytranslation.TranslatorOptions{
-			KubeTranslator: opts.KubeTranslator,
-		}),
 	}
+	p.translator = gatewaytranslation.NewTranslator(&gatewaytranslation.TranslatorOptions{
+		KubeTranslator: opts.KubeTranslator,
+		BackendTLS:     p.backendTLS,
+	})
 
 	gatewayFactory := gatewayexternalversions.NewSharedInformerFactory(p.gatewayClient, p.Cfg.Kubernetes.ResyncInterval.Duration)
 
@@ -145,6 +150,21 @@ func NewGatewayProvider(opts *ProviderOptions) (*Provider, error) {
 	p.gatewayHTTPRouteController = newGatewayHTTPRouteController(p)
 	p.gatewayTLSRouteController = newGatewayTLSRouteController(p)
 
+	served, err := backendTLSPolicyServed(p.KubeClient.Discovery())
+	if err != nil {
+		return nil, err
+	}
+	if served {
+		p.backendTLSPolicyController, err = newBackendTLSPolicyController(p)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		log.Infow("BackendTLSPolicy is not served, skip watching it",
+			zap.String("group_version", backendTLSPolicyResource.GroupVersion().String()),
+		)
+	}
+
 	return p, nil
 }
 
@@ -183,9 +203,23 @@ func (p *Provider) Run(ctx context.Context) {
 		p.gatewayTLSRouteController.run(ctx)
 	})
 
+	if p.backendTLSPolicyController != nil {
+		e.Add(func() {
+			p.backendTLSPolicyController.run(ctx)
+		})
+	}
+
 	e.Wait()
 }
 
+// backendTLS returns the BackendTLSPolicy settings for the Service.
+func (p *Provider) backendTLS(namespace, service string) (*types.BackendTLS, error) {
+	if p.backendTLSPolicyController == nil {
+		return nil, nil
+	}
+	return p.backendTLSPolicyController.backendTLS(namespace, service)
+}
+
 func (p *Provider) AddGatewayClass(name string) {
 	p.gatewayClassesLock.Lock()
 	defer p.gatewayClassesLock.Unlock()
@@ -0,0 +1,265 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// RouteReasonAccepted means the route is attached to the Gateway.
+	RouteReasonAccepted = "Accepted"
+	// RouteReasonNoMatchingListener means the listener referenced by the
+	// sectionName doesn't exist in the Gateway.
+	RouteReasonNoMatchingListener = "NoMatchingListener"
+	// RouteReasonNotAllowedByListeners means no listeners of the Gateway
+	// allow the route to attach.
+	RouteReasonNotAllowedByListeners = "NotAllowedByListeners"
+	// RouteReasonTLSNotPassthrough means the TLS listener terminates TLS,
+	// while TLSRoutes are only supported in the passthrough mode.
+	RouteReasonTLSNotPassthrough = "TLSNotPassthrough"
+)
+
+// routeParentResult is the result of resolving a parentRef of a route.
+type routeParentResult struct {
+	ref gatewayv1alpha2.ParentRef
+	// accepted indicates whether the route is attached to the Gateway.
+	accepted bool
+	reason   string
+	message  string
+}
+
+// resolveParentRefs resolves the parentRefs of a route to the Gateways owned by
+// this controller, refs to the other parents are left out, since their status is
+// not reported by this controller.
+func (p *Provider) resolveParentRefs(routeNs string, kind gatewayv1alpha2.Kind, refs []gatewayv1alpha2.ParentRef) []routeParentResult {
+	var results []routeParentResult
+	for _, ref := range refs {
+		if ref.Group != nil && *ref.Group != gatewayv1alpha2.GroupName {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != "Gateway" {
+			continue
+		}
+		ns := routeNs
+		if ref.Namespace != nil {
+			ns = string(*ref.Namespace)
+		}
+		gateway, err := p.gatewayLister.Gateways(ns).Get(string(ref.Name))
+		if err != nil {
+			log.Debugw("failed to get the parent Gateway of route",
+				zap.String("gateway", ns+"/"+string(ref.Name)),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !p.HasGatewayClass(string(gateway.Spec.GatewayClassName)) {
+			continue
+		}
+		results = append(results, p.resolveGatewayListeners(gateway, ref, routeNs, kind))
+	}
+	return results
+}
+
+// resolveGatewayListeners checks whether the route can attach to the listeners
+// (or the one specified by the sectionName) of the Gateway.
+func (p *Provider) resolveGatewayListeners(gateway *gatewayv1alpha2.Gateway, ref gatewayv1alpha2.ParentRef,
+	routeNs string, kind gatewayv1alpha2.Kind) routeParentResult {
+	result := routeParentResult{ref: ref}
+	found := false
+	var terminating []string
+	for i := range gateway.Spec.Listeners {
+		listener := &gateway.Spec.Listeners[i]
+		if ref.SectionName != nil && *ref.SectionName != listener.Name {
+			continue
+		}
+		found = true
+		if kind == "TLSRoute" && listener.Protocol == gatewayv1alpha2.TLSProtocolType && !isPassthroughListener(listener) {
+			terminating = append(terminating, string(listener.Name))
+			continue
+		}
+		if p.listenerAllowsRoute(listener, gateway.Namespace, routeNs, kind) {
+			result.accepted = true
+			result.reason = RouteReasonAccepted
+			result.message = "Route is accepted by the Gateway"
+			return result
+		}
+	}
+	if !found {
+		result.reason = RouteReasonNoMatchingListener
+		result.message = fmt.Sprintf("listener %s not found in the Gateway", *ref.SectionName)
+		return result
+	}
+	if len(terminating) > 0 {
+		result.reason = RouteReasonTLSNotPassthrough
+		result.message = fmt.Sprintf("listener %s terminates TLS, TLSRoute requires the Passthrough mode", strings.Join(terminating, ","))
+		return result
+	}
+	result.reason = RouteReasonNotAllowedByListeners
+	result.message = "no listeners of the Gateway allow the route"
+	return result
+}
+
+// isPassthroughListener reports whether the listener passes the TLS connections
+// through without terminating them, the TLS mode is Terminate by default.
+func isPassthroughListener(listener *gatewayv1alpha2.Listener) bool {
+	return listener.TLS != nil && listener.TLS.Mode != nil && *listener.TLS.Mode == gatewayv1alpha2.TLSModePassthrough
+}
+
+// listenerAllowsRoute checks the protocol and the allowedRoutes of the listener.
+func (p *Provider) listenerAllowsRoute(listener *gatewayv1alpha2.Listener, gatewayNs, routeNs string, kind gatewayv1alpha2.Kind) bool {
+	switch kind {
+	case "HTTPRoute":
+		if listener.Protocol != gatewayv1alpha2.HTTPProtocolType && listener.Protocol != gatewayv1alpha2.HTTPSProtocolType {
+			return false
+		}
+	case "TLSRoute":
+		if listener.Protocol != gatewayv1alpha2.TLSProtocolType {
+			return false
+		}
+	}
+
+	allowed := listener.AllowedRoutes
+	if allowed == nil {
+		return gatewayNs == routeNs
+	}
+	if len(allowed.Kinds) > 0 {
+		match := false
+		for _, k := range allowed.Kinds {
+			if (k.Group == nil || *k.Group == gatewayv1alpha2.GroupName) && k.Kind == kind {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	from := gatewayv1alpha2.NamespacesFromSame
+	if allowed.Namespaces != nil && allowed.Namespaces.From != nil {
+		from = *allowed.Namespaces.From
+	}
+	switch from {
+	case gatewayv1alpha2.NamespacesFromAll:
+		return true
+	case gatewayv1alpha2.NamespacesFromSelector:
+		if allowed.Namespaces.Selector == nil {
+			return false
+		}
+		selector, err := metav1.LabelSelectorAsSelector(allowed.Namespaces.Selector)
+		if err != nil {
+			log.Warnw("listener with invalid namespace selector",
+				zap.String("listener", string(listener.Name)),
+				zap.Error(err),
+			)
+			return false
+		}
+		ns, err := p.KubeClient.CoreV1().Namespaces().Get(context.TODO(), routeNs, metav1.GetOptions{})
+		if err != nil {
+			log.Errorw("failed to get namespace of route",
+				zap.String("namespace", routeNs),
+				zap.Error(err),
+			)
+			return false
+		}
+		return selector.Matches(labels.Set(ns.Labels))
+	default:
+		return gatewayNs == routeNs
+	}
+}
+
+// routeParentStatuses builds the status of the route for the resolved parents,
+// the status written by the other controllers is kept.
+func routeParentStatuses(existing []gatewayv1alpha2.RouteParentStatus, results []routeParentResult,
+	resolvedErr error, generation int64) []gatewayv1alpha2.RouteParentStatus {
+	var parents []gatewayv1alpha2.RouteParentStatus
+	for _, ps := range existing {
+		if ps.ControllerName != GatewayClassName {
+			parents = append(parents, ps)
+		}
+	}
+
+	resolved := metav1.Condition{
+		Type:               string(gatewayv1alpha2.ConditionRouteResolvedRefs),
+		Status:             metav1.ConditionTrue,
+		Reason:             string(gatewayv1alpha2.ConditionRouteResolvedRefs),
+		Message:            "All references are resolved",
+		ObservedGeneration: generation,
+	}
+	if resolvedErr != nil {
+		resolved.Status = metav1.ConditionFalse
+		resolved.Reason = "InvalidBackendRefs"
+		resolved.Message = resolvedErr.Error()
+	}
+
+	for _, result := range results {
+		// Keep the transition time of the unchanged conditions.
+		var conditions []metav1.Condition
+		for _, ps := range existing {
+			if ps.ControllerName == GatewayClassName && reflect.DeepEqual(ps.ParentRef, result.ref) {
+				conditions = append(conditions, ps.Conditions...)
+				break
+			}
+		}
+		accepted := metav1.Condition{
+			Type:               string(gatewayv1alpha2.ConditionRouteAccepted),
+			Status:             metav1.ConditionFalse,
+			Reason:             result.reason,
+			Message:            result.message,
+			ObservedGeneration: generation,
+		}
+		if result.accepted {
+			accepted.Status = metav1.ConditionTrue
+		}
+		meta.SetStatusCondition(&conditions, accepted)
+		meta.SetStatusCondition(&conditions, resolved)
+
+		parents = append(parents, gatewayv1alpha2.RouteParentStatus{
+			ParentRef:      result.ref,
+			ControllerName: GatewayClassName,
+			Conditions:     conditions,
+		})
+	}
+	return parents
+}
+
+// hasAcceptedParent reports whether the route should be translated, routes
+// without parentRefs are always translated.
+func hasAcceptedParent(refs []gatewayv1alpha2.ParentRef, results []routeParentResult) bool {
+	if len(refs) == 0 {
+		return true
+	}
+	for _, result := range results {
+		if result.accepted {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,233 @@
+// Licensed to the Apache Software Foundation (ASF) under one
+// or more contributor license agreements.  See the NOTICE file
+// distributed with this work for additional information
+// regarding copyright ownership.  The ASF licenses this file
+// to you under the Apache License, Version 2.0 (the
+// "License"); you may not use this file except in compliance
+// with the License.  You may obtain a copy of the License at
+//
+//   http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+//
+package gateway
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+	gatewaylistersv1alpha2 "sigs.k8s.io/gateway-api/pkg/client/listers/gateway/apis/v1alpha2"
+)
+
+func newTestProvider(t *testing.T, gateways ...*gatewayv1alpha2.Gateway) *Provider {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, gw := range gateways {
+		assert.Nil(t, indexer.Add(gw))
+	}
+	p := &Provider{
+		gatewayClasses: map[string]struct{}{"apisix": {}},
+		gatewayLister:  gatewaylistersv1alpha2.NewGatewayLister(indexer),
+		ProviderOptions: &ProviderOptions{
+			KubeClient: fake.NewSimpleClientset(&corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "team-a",
+					Labels: map[string]string{"gateway": "allowed"},
+				},
+			}),
+		},
+	}
+	return p
+}
+
+func TestResolveParentRefs(t *testing.T) {
+	all := gatewayv1alpha2.NamespacesFromAll
+	selector := gatewayv1alpha2.NamespacesFromSelector
+	p := newTestProvider(t,
+		&gatewayv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+			Spec: gatewayv1alpha2.GatewaySpec{
+				GatewayClassName: "apisix",
+				Listeners: []gatewayv1alpha2.Listener{
+					{Name: "http", Protocol: gatewayv1alpha2.HTTPProtocolType, Port: 80},
+					{Name: "tls", Protocol: gatewayv1alpha2.TLSProtocolType, Port: 443},
+					{
+						Name:     "public",
+						Protocol: gatewayv1alpha2.HTTPProtocolType,
+						Port:     8080,
+						AllowedRoutes: &gatewayv1alpha2.AllowedRoutes{
+							Namespaces: &gatewayv1alpha2.RouteNamespaces{From: &all},
+						},
+					},
+					{
+						Name:     "selected",
+						Protocol: gatewayv1alpha2.HTTPProtocolType,
+						Port:     8081,
+						AllowedRoutes: &gatewayv1alpha2.AllowedRoutes{
+							Namespaces: &gatewayv1alpha2.RouteNamespaces{
+								From: &selector,
+								Selector: &metav1.LabelSelector{
+									MatchLabels: map[string]string{"gateway": "allowed"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		&gatewayv1alpha2.Gateway{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+			Spec: gatewayv1alpha2.GatewaySpec{
+				GatewayClassName: "istio",
+			},
+		},
+	)
+
+	section := func(name string) *gatewayv1alpha2.SectionName {
+		s := gatewayv1alpha2.SectionName(name)
+		return &s
+	}
+	ns := gatewayv1alpha2.Namespace("default")
+
+	// Gateways of the other controllers and the missing ones are left out.
+	results := p.resolveParentRefs("default", "HTTPRoute", []gatewayv1alpha2.ParentRef{
+		{Name: "other"},
+		{Name: "missing"},
+		{Name: "gw"},
+	})
+	assert.Len(t, results, 1)
+	assert.True(t, results[0].accepted)
+	assert.Equal(t, RouteReasonAccepted, results[0].reason)
+
+	// The listener protocol should match the route kind.
+	results = p.resolveParentRefs("default", "HTTPRoute", []gatewayv1alpha2.ParentRef{
+		{Name: "gw", SectionName: section("tls")},
+		{Name: "gw", SectionName: section("missing")},
+	})
+	assert.Len(t, results, 2)
+	assert.False(t, results[0].accepted)
+	assert.Equal(t, RouteReasonNotAllowedByListeners, results[0].reason)
+	assert.False(t, results[1].accepted)
+	assert.Equal(t, RouteReasonNoMatchingListener, results[1].reason)
+
+	// Routes in the other namespaces.
+	results = p.resolveParentRefs("team-b", "HTTPRoute", []gatewayv1alpha2.ParentRef{
+		{Name: "gw", Namespace: &ns, SectionName: section("http")},
+		{Name: "gw", Namespace: &ns, SectionName: section("public")},
+		{Name: "gw", Namespace: &ns, SectionName: section("selected")},
+	})
+	assert.Len(t, results, 3)
+	assert.False(t, results[0].accepted)
+	assert.True(t, results[1].accepted)
+	assert.False(t, results[2].accepted)
+
+	results = p.resolveParentRefs("team-a", "HTTPRoute", []gatewayv1alpha2.ParentRef{
+		{Name: "gw", Namespace: &ns, SectionName: section("selected")},
+	})
+	assert.Len(t, results, 1)
+	assert.True(t, results[0].accepted)
+
+	assert.True(t, hasAcceptedParent(nil, nil))
+	assert.False(t, hasAcceptedParent([]gatewayv1alpha2.ParentRef{{Name: "other"}}, nil))
+}
+
+func TestResolveTLSRouteParentRefs(t *testing.T) {
+	passthrough := gatewayv1alpha2.TLSModePassthrough
+	terminate := gatewayv1alpha2.TLSModeTerminate
+	p := newTestProvider(t, &gatewayv1alpha2.Gateway{
+		ObjectMeta: metav1.ObjectMeta{Name: "gw", Namespace: "default"},
+		Spec: gatewayv1alpha2.GatewaySpec{
+			GatewayClassName: "apisix",
+			Listeners: []gatewayv1alpha2.Listener{
+				{Name: "http", Protocol: gatewayv1alpha2.HTTPProtocolType, Port: 80},
+				{
+					Name:     "passthrough",
+					Protocol: gatewayv1alpha2.TLSProtocolType,
+					Port:     443,
+					TLS:      &gatewayv1alpha2.GatewayTLSConfig{Mode: &passthrough},
+				},
+				{
+					Name:     "terminate",
+					Protocol: gatewayv1alpha2.TLSProtocolType,
+					Port:     8443,
+					TLS:      &gatewayv1alpha2.GatewayTLSConfig{Mode: &terminate},
+				},
+				{
+					// The default mode is Terminate.
+					Name:     "default",
+					Protocol: gatewayv1alpha2.TLSProtocolType,
+					Port:     9443,
+				},
+			},
+		},
+	})
+	section := func(name string) *gatewayv1alpha2.SectionName {
+		s := gatewayv1alpha2.SectionName(name)
+		return &s
+	}
+
+	results := p.resolveParentRefs("default", "TLSRoute", []gatewayv1alpha2.ParentRef{
+		{Name: "gw"},
+		{Name: "gw", SectionName: section("passthrough")},
+		{Name: "gw", SectionName: section("terminate")},
+		{Name: "gw", SectionName: section("default")},
+		{Name: "gw", SectionName: section("http")},
+	})
+	assert.Len(t, results, 5)
+	assert.True(t, results[0].accepted)
+	assert.True(t, results[1].accepted)
+	assert.False(t, results[2].accepted)
+	assert.Equal(t, RouteReasonTLSNotPassthrough, results[2].reason)
+	assert.Equal(t, "listener terminate terminates TLS, TLSRoute requires the Passthrough mode", results[2].message)
+	assert.False(t, results[3].accepted)
+	assert.Equal(t, RouteReasonTLSNotPassthrough, results[3].reason)
+	assert.False(t, results[4].accepted)
+	assert.Equal(t, RouteReasonNotAllowedByListeners, results[4].reason)
+}
+
+func TestRouteParentStatuses(t *testing.T) {
+	gw := gatewayv1alpha2.ParentRef{Name: "gw"}
+	existing := []gatewayv1alpha2.RouteParentStatus{
+		{
+			ParentRef:      gatewayv1alpha2.ParentRef{Name: "foreign"},
+			ControllerName: "example.net/gateway-controller",
+		},
+	}
+	results := []routeParentResult{
+		{ref: gw, accepted: true, reason: RouteReasonAccepted, message: "ok"},
+	}
+
+	statuses := routeParentStatuses(existing, results, nil, 2)
+	assert.Len(t, statuses, 2)
+	assert.Equal(t, existing[0], statuses[0])
+	assert.Equal(t, gw, statuses[1].ParentRef)
+	assert.Equal(t, gatewayv1alpha2.GatewayController(GatewayClassName), statuses[1].ControllerName)
+	conds := statuses[1].Conditions
+	assert.Len(t, conds, 2)
+	assert.Equal(t, "Accepted", conds[0].Type)
+	assert.Equal(t, metav1.ConditionTrue, conds[0].Status)
+	assert.Equal(t, int64(2), conds[0].ObservedGeneration)
+	assert.Equal(t, "ResolvedRefs", conds[1].Type)
+	assert.Equal(t, metav1.ConditionTrue, conds[1].Status)
+
+	// The same result keeps the status unchanged, so it's not written again.
+	assert.Equal(t, statuses, routeParentStatuses(statuses, results, nil, 2))
+
+	// Failed to resolve the backends.
+	statuses = routeParentStatuses(statuses, results, errors.New("service default/foo not found"), 3)
+	conds = statuses[1].Conditions
+	assert.Equal(t, metav1.ConditionTrue, conds[0].Status)
+	assert.Equal(t, "ResolvedRefs", conds[1].Type)
+	assert.Equal(t, metav1.ConditionFalse, conds[1].Status)
+	assert.Equal(t, "service default/foo not found", conds[1].Message)
+}
@@ -104,7 +104,12 @@ func (t *translator) TranslateGatewayHTTPRouteV1Alpha2(httpRoute *gatewayv1alpha
 			ups.Labels["meta_backend"] = utils.TruncateString(string(backend.Name), 64)
 			ups.Labels["meta_port"] = fmt.Sprintf("%v", int32(*backend.Port))
 
-			ups.ID = id.GenID(name)
+			if err := t.applyBackendTLS(ups, ns, string(backend.Name)); err != nil {
+				return nil, errors.Wrap(err, fmt.Sprintf("failed to translate Rules[%v].BackendRefs[%v]", i, j))
+			}
+
+			ups.Name = name
+			ups.ID = apisixv1.ComposeUpstreamID(ups)
 			ctx.AddUpstream(ups)
 			ruleUpstreams = append(ruleUpstreams, ups)
 
@@ -148,7 +153,7 @@ func (t *translator) TranslateGatewayHTTPRouteV1Alpha2(httpRoute *gatewayv1alpha
 			}
 
 			name := apisixv1.ComposeRouteName(httpRoute.Namespace, httpRoute.Name, fmt.Sprintf("%d-%d", i, j))
-			route.ID = id.GenID(name)
+			route.ID = id.GenID(id.Route, name)
 			route.Hosts = hosts
 
 			// Bind Upstream
@@ -171,6 +176,7 @@ func (t *translator) TranslateGatewayHTTPRouteV1Alpha2(httpRoute *gatewayv1alpha
 		//filters := rule.Filters
 	}
 
+	translation.SetSourceLabels(ctx, "HTTPRoute", httpRoute)
 	return ctx, nil
 }
 
@@ -268,3 +274,28 @@ func (t *translator) translateGatewayHTTPRouteMatch(match *gatewayv1alpha2.HTTPR
 
 	return route, nil
 }
+
+// applyBackendTLS makes the upstream connect the Service over TLS if there is
+// a BackendTLSPolicy attached to it. The hostname of the policy is used as the
+// SNI, and the certificate of the backend is verified.
+func (t *translator) applyBackendTLS(ups *apisixv1.Upstream, ns, svc string) error {
+	if t.BackendTLS == nil {
+		return nil
+	}
+	tls, err := t.BackendTLS(ns, svc)
+	if err != nil {
+		return err
+	}
+	if tls == nil {
+		return nil
+	}
+	verify := true
+	ups.Scheme = apisixv1.SchemeHTTPS
+	ups.PassHost = apisixv1.PassHostRewrite
+	ups.UpstreamHost = tls.Hostname
+	if ups.TLS == nil {
+		ups.TLS = &apisixv1.ClientTLS{}
+	}
+	ups.TLS.Verify = &verify
+	return nil
+}
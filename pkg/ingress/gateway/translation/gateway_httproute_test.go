@@ -16,6 +16,7 @@ package gateway_translation
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/client-go/tools/cache"
 	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
 
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/gateway/types"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
 	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
@@ -406,4 +408,63 @@ func TestTranslateGatewayHTTPRouteRegexMatch(t *testing.T) {
 	assert.Equal(t, 9080, u.Nodes[0].Port)
 }
 
+func TestTranslateGatewayHTTPRouteBackendTLS(t *testing.T) {
+	refPortNumber := func(i gatewayv1alpha2.PortNumber) *gatewayv1alpha2.PortNumber {
+		return &i
+	}
+
+	tr, processCh := mockHTTPRouteTranslator(t)
+	<-processCh
+	<-processCh
+
+	httpRoute := &gatewayv1alpha2.HTTPRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "http_route",
+			Namespace: "test",
+		},
+		Spec: gatewayv1alpha2.HTTPRouteSpec{
+			Rules: []gatewayv1alpha2.HTTPRouteRule{
+				{
+					BackendRefs: []gatewayv1alpha2.HTTPBackendRef{
+						{
+							BackendRef: gatewayv1alpha2.BackendRef{
+								BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+									Name: "svc",
+									Port: refPortNumber(443),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tctx, err := tr.TranslateGatewayHTTPRouteV1Alpha2(httpRoute)
+	assert.Nil(t, err)
+	assert.Equal(t, "http", tctx.Upstreams[0].Scheme)
+	assert.Nil(t, tctx.Upstreams[0].TLS)
+
+	tr.BackendTLS = func(namespace, service string) (*types.BackendTLS, error) {
+		assert.Equal(t, "test", namespace)
+		assert.Equal(t, "svc", service)
+		return &types.BackendTLS{Hostname: "svc.example.com"}, nil
+	}
+	tctx, err = tr.TranslateGatewayHTTPRouteV1Alpha2(httpRoute)
+	assert.Nil(t, err)
+	u := tctx.Upstreams[0]
+	assert.Equal(t, "https", u.Scheme)
+	assert.Equal(t, "rewrite", u.PassHost)
+	assert.Equal(t, "svc.example.com", u.UpstreamHost)
+	assert.NotNil(t, u.TLS.Verify)
+	assert.True(t, *u.TLS.Verify)
+
+	tr.BackendTLS = func(namespace, service string) (*types.BackendTLS, error) {
+		return nil, errors.New("ConfigMap ca not found")
+	}
+	_, err = tr.TranslateGatewayHTTPRouteV1Alpha2(httpRoute)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "ConfigMap ca not found")
+}
+
 // TODO: Multiple BackendRefs, Multiple Rules, Multiple Matches
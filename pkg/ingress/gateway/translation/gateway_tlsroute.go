@@ -35,7 +35,8 @@ import (
 func (t *translator) TranslateGatewayTLSRouteV1Alpha2(tlsRoute *gatewayv1alpha2.TLSRoute) (*translation.TranslateContext, error) {
 	ctx := translation.DefaultEmptyTranslateContext()
 
-	// TODO: Handle ParentRefs
+	// The ParentRefs are resolved by the controller, only the TLSRoutes attached
+	// to the passthrough listeners are translated.
 
 	var hosts []string
 	for _, hostname := range tlsRoute.Spec.Hostnames {
@@ -86,6 +87,8 @@ func (t *translator) TranslateGatewayTLSRouteV1Alpha2(tlsRoute *gatewayv1alpha2.
 				continue
 			}
 
+			// The upstream keeps the default scheme, so the TLS connections are
+			// proxied as is, without being terminated or re-encrypted.
 			ups, err := t.KubeTranslator.TranslateUpstream(ns, string(backend.Name), "", int32(*backend.Port))
 			if err != nil {
 				return nil, errors.Wrap(err, fmt.Sprintf("failed to translate Rules[%v].BackendRefs[%v]", i, j))
@@ -96,7 +99,8 @@ func (t *translator) TranslateGatewayTLSRouteV1Alpha2(tlsRoute *gatewayv1alpha2.
 			ups.Labels["meta_backend"] = utils.TruncateString(string(backend.Name), 64)
 			ups.Labels["meta_port"] = fmt.Sprintf("%v", int32(*backend.Port))
 
-			ups.ID = id.GenID(name)
+			ups.Name = name
+			ups.ID = apisixv1.ComposeUpstreamID(ups)
 			ctx.AddUpstream(ups)
 			ruleUpstreams = append(ruleUpstreams, ups)
 		}
@@ -110,7 +114,7 @@ func (t *translator) TranslateGatewayTLSRouteV1Alpha2(tlsRoute *gatewayv1alpha2.
 		for _, host := range hosts {
 			route := apisixv1.NewDefaultStreamRoute()
 			name := apisixv1.ComposeRouteName(tlsRoute.Namespace, tlsRoute.Name, fmt.Sprintf("%d-%s", i, host))
-			route.ID = id.GenID(name)
+			route.ID = id.GenID(id.StreamRoute, name)
 
 			route.Labels["meta_namespace"] = utils.TruncateString(tlsRoute.Namespace, 64)
 			route.Labels["meta_tlsroute"] = utils.TruncateString(tlsRoute.Name, 64)
@@ -128,5 +132,6 @@ func (t *translator) TranslateGatewayTLSRouteV1Alpha2(tlsRoute *gatewayv1alpha2.
 		}
 	}
 
+	translation.SetSourceLabels(ctx, "TLSRoute", tlsRoute)
 	return ctx, nil
 }
@@ -0,0 +1,76 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package gateway_translation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	v1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestTranslateGatewayTLSRouteSNI(t *testing.T) {
+	tr, processCh := mockHTTPRouteTranslator(t)
+	<-processCh
+	<-processCh
+
+	port := gatewayv1alpha2.PortNumber(443)
+	tlsRoute := &gatewayv1alpha2.TLSRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls-route",
+			Namespace: "test",
+		},
+		Spec: gatewayv1alpha2.TLSRouteSpec{
+			Hostnames: []gatewayv1alpha2.Hostname{"foo.org", "bar.org"},
+			Rules: []gatewayv1alpha2.TLSRouteRule{
+				{
+					BackendRefs: []gatewayv1alpha2.BackendRef{
+						{
+							BackendObjectReference: gatewayv1alpha2.BackendObjectReference{
+								Name: "svc",
+								Port: &port,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tctx, err := tr.TranslateGatewayTLSRouteV1Alpha2(tlsRoute)
+	assert.Nil(t, err)
+	assert.Len(t, tctx.Upstreams, 1)
+	ups := tctx.Upstreams[0]
+	assert.Equal(t, "test_svc_443", ups.Name)
+	assert.Equal(t, v1.ComposeUpstreamID(ups), ups.ID)
+	// The TLS connections are proxied as is.
+	assert.Equal(t, v1.SchemeHTTP, ups.Scheme)
+	assert.Equal(t, v1.UpstreamNodes{
+		{Host: "192.168.1.1", Port: 9443, Weight: 100},
+		{Host: "192.168.1.2", Port: 9443, Weight: 100},
+	}, ups.Nodes)
+
+	assert.Len(t, tctx.StreamRoutes, 2)
+	for i, host := range []string{"foo.org", "bar.org"} {
+		sr := tctx.StreamRoutes[i]
+		assert.Equal(t, host, sr.SNI)
+		assert.Equal(t, ups.ID, sr.UpstreamId)
+		assert.Equal(t, id.GenID(id.StreamRoute, v1.ComposeRouteName("test", "tls-route", "0-"+host)), sr.ID)
+	}
+}
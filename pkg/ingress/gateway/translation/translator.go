@@ -26,6 +26,9 @@ import (
 
 type TranslatorOptions struct {
 	KubeTranslator translation.Translator
+	// BackendTLS returns the BackendTLSPolicy settings for the Service, nil
+	// is returned if there is no policy attached to it.
+	BackendTLS func(namespace, service string) (*types.BackendTLS, error)
 }
 
 type translator struct {
@@ -17,7 +17,10 @@
 //
 package types
 
-import gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	gatewayv1alpha2 "sigs.k8s.io/gateway-api/apis/v1alpha2"
+)
 
 type ListenerConf struct {
 	// Gateway namespace
@@ -33,3 +36,46 @@ type ListenerConf struct {
 	RouteNamespace *gatewayv1alpha2.RouteNamespaces
 	AllowedKinds   []gatewayv1alpha2.RouteGroupKind
 }
+
+// BackendTLSPolicy is the gateway.networking.k8s.io/v1alpha2 BackendTLSPolicy,
+// it's not shipped by the Gateway API version in use so only the fields used
+// by the controller are kept.
+type BackendTLSPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BackendTLSPolicySpec `json:"spec"`
+}
+
+type BackendTLSPolicySpec struct {
+	TargetRef BackendTLSPolicyTargetRef `json:"targetRef"`
+	TLS       BackendTLSPolicyConfig    `json:"tls"`
+}
+
+type BackendTLSPolicyTargetRef struct {
+	Group       string  `json:"group"`
+	Kind        string  `json:"kind"`
+	Name        string  `json:"name"`
+	Namespace   *string `json:"namespace,omitempty"`
+	SectionName *string `json:"sectionName,omitempty"`
+}
+
+type BackendTLSPolicyConfig struct {
+	CACertRefs       []BackendTLSPolicyCACertRef `json:"caCertRefs,omitempty"`
+	WellKnownCACerts *string                     `json:"wellKnownCACerts,omitempty"`
+	Hostname         string                      `json:"hostname"`
+}
+
+type BackendTLSPolicyCACertRef struct {
+	Group string `json:"group"`
+	Kind  string `json:"kind"`
+	Name  string `json:"name"`
+}
+
+// BackendTLS is the resolved BackendTLSPolicy of a Service.
+type BackendTLS struct {
+	// Hostname is used as the SNI and to verify the certificate of the backend.
+	Hostname string
+	// CACerts are the PEM encoded CA certificates referenced by the policy.
+	CACerts []string
+}
@@ -0,0 +1,210 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// orphanResources contains the APISIX objects which are not derived from
+// any Kubernetes resource, keyed by the object ID (or the username for
+// consumers), the value is the managed-by label of the object.
+type orphanResources struct {
+	routes        map[string]string
+	streamRoutes  map[string]string
+	upstreams     map[string]string
+	ssls          map[string]string
+	consumers     map[string]string
+	pluginConfigs map[string]string
+}
+
+// managedOrphans picks the orphan objects which are marked as managed by
+// apisix-ingress-controller, objects created by others (including the
+// controllers with different ID prefixes) are never touched.
+func managedOrphans(resources map[string]string) map[string]struct{} {
+	managedBy := apisixv1.ManagedBy()
+	result := make(map[string]struct{})
+	for k, v := range resources {
+		if v == managedBy {
+			result[k] = struct{}{}
+		}
+	}
+	return result
+}
+
+// collectOrphanResources deletes the orphan APISIX objects in the cluster which
+// are managed by apisix-ingress-controller, when dryRun is true, these objects
+// are just logged. Routes are deleted before the objects they reference, and
+// upstreams are deleted at last.
+func (c *Controller) collectOrphanResources(ctx context.Context, clusterName string, orphans *orphanResources, dryRun bool) {
+	cluster := c.apisix.Cluster(clusterName)
+
+	gc := func(kind string, candidates map[string]string, del func(id string) error) {
+		ids := make([]string, 0, len(candidates))
+		for id := range managedOrphans(candidates) {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			if dryRun {
+				log.Infow("orphan APISIX resource would be deleted (dry run)",
+					zap.String("kind", kind),
+					zap.String("id", id),
+				)
+				continue
+			}
+			if err := del(id); err != nil {
+				log.Errorw("failed to delete orphan APISIX resource",
+					zap.String("kind", kind),
+					zap.String("id", id),
+					zap.Error(err),
+				)
+				continue
+			}
+			log.Infow("orphan APISIX resource deleted",
+				zap.String("kind", kind),
+				zap.String("id", id),
+			)
+		}
+	}
+
+	routes, err := cluster.Route().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list routes", zap.Error(err))
+		return
+	}
+	gc("route", orphans.routes, func(id string) error {
+		for _, r := range routes {
+			if r.ID == id {
+				return cluster.Route().Delete(ctx, r)
+			}
+		}
+		return nil
+	})
+
+	streamRoutes, err := cluster.StreamRoute().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list stream routes", zap.Error(err))
+		return
+	}
+	gc("streamRoute", orphans.streamRoutes, func(id string) error {
+		for _, r := range streamRoutes {
+			if r.ID == id {
+				return cluster.StreamRoute().Delete(ctx, r)
+			}
+		}
+		return nil
+	})
+
+	pluginConfigs, err := cluster.PluginConfig().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list plugin configs", zap.Error(err))
+		return
+	}
+	gc("pluginConfig", orphans.pluginConfigs, func(id string) error {
+		for _, pc := range pluginConfigs {
+			if pc.ID == id {
+				return cluster.PluginConfig().Delete(ctx, pc)
+			}
+		}
+		return nil
+	})
+
+	ssls, err := cluster.SSL().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list ssls", zap.Error(err))
+		return
+	}
+	gc("ssl", orphans.ssls, func(id string) error {
+		for _, s := range ssls {
+			if s.ID == id {
+				return cluster.SSL().Delete(ctx, s)
+			}
+		}
+		return nil
+	})
+
+	consumers, err := cluster.Consumer().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list consumers", zap.Error(err))
+		return
+	}
+	gc("consumer", orphans.consumers, func(username string) error {
+		for _, con := range consumers {
+			if con.Username == username {
+				return cluster.Consumer().Delete(ctx, con)
+			}
+		}
+		return nil
+	})
+
+	// Upstreams still referenced by other routes, e.g. the ones only used in
+	// the traffic-split plugin, are kept.
+	referenced := referencedUpstreams(routes, streamRoutes,
+		managedOrphans(orphans.routes), managedOrphans(orphans.streamRoutes))
+	upstreamOrphans := make(map[string]string, len(orphans.upstreams))
+	for k, v := range orphans.upstreams {
+		if _, ok := referenced[k]; !ok {
+			upstreamOrphans[k] = v
+		}
+	}
+
+	upstreams, err := cluster.Upstream().List(ctx)
+	if err != nil {
+		log.Errorw("failed to list upstreams", zap.Error(err))
+		return
+	}
+	gc("upstream", upstreamOrphans, func(id string) error {
+		for _, u := range upstreams {
+			if u.ID == id {
+				return cluster.Upstream().Delete(ctx, u)
+			}
+		}
+		return nil
+	})
+}
+
+// referencedUpstreams returns the IDs of upstreams referenced by the routes and
+// stream routes, except the ones in the removed sets.
+func referencedUpstreams(routes []*apisixv1.Route, streamRoutes []*apisixv1.StreamRoute,
+	removedRoutes, removedStreamRoutes map[string]struct{}) map[string]struct{} {
+	referenced := make(map[string]struct{})
+	for _, r := range routes {
+		if _, ok := removedRoutes[r.ID]; ok {
+			continue
+		}
+		if r.UpstreamId != "" {
+			referenced[r.UpstreamId] = struct{}{}
+		}
+		for _, id := range apisixv1.TrafficSplitUpstreamIDs(r) {
+			referenced[id] = struct{}{}
+		}
+	}
+	for _, sr := range streamRoutes {
+		if _, ok := removedStreamRoutes[sr.ID]; ok {
+			continue
+		}
+		if sr.UpstreamId != "" {
+			referenced[sr.UpstreamId] = struct{}{}
+		}
+	}
+	return referenced
+}
@@ -0,0 +1,114 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestManagedOrphans(t *testing.T) {
+	a6 := map[string]string{
+		"1": apisixv1.ManagedBy(),
+		"2": apisixv1.ManagedBy(),
+		"3": "",
+		"4": "someone-else",
+	}
+	k8s := new(sync.Map)
+	k8s.Store("1", "1")
+
+	orphans := managedOrphans(findRedundant(a6, k8s))
+	assert.Equal(t, map[string]struct{}{"2": {}}, orphans)
+
+	k8s.Store("2", "2")
+	assert.Len(t, managedOrphans(findRedundant(a6, k8s)), 0)
+}
+
+func TestManagedOrphansWithIDPrefix(t *testing.T) {
+	a6 := map[string]string{
+		"1": "apisix-ingress-controller",
+		"2": "apisix-ingress-controller.staging",
+		"3": "apisix-ingress-controller.prod",
+	}
+
+	id.SetPrefix("staging")
+	defer id.SetPrefix("")
+	assert.Equal(t, "apisix-ingress-controller.staging", apisixv1.ManagedBy())
+	// Objects of the controllers with other prefixes (or without prefix) are
+	// ignored.
+	assert.Equal(t, map[string]struct{}{"2": {}}, managedOrphans(a6))
+
+	id.SetPrefix("")
+	assert.Equal(t, map[string]struct{}{"1": {}}, managedOrphans(a6))
+}
+
+func TestReferencedUpstreams(t *testing.T) {
+	r1 := apisixv1.NewDefaultRoute()
+	r1.ID = "r1"
+	r1.UpstreamId = "u1"
+	r1.Plugins = apisixv1.Plugins{
+		"traffic-split": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"weighted_upstreams": []interface{}{
+						map[string]interface{}{"upstream_id": "u2", "weight": 10},
+						map[string]interface{}{"weight": 0},
+					},
+				},
+			},
+		},
+	}
+	r2 := apisixv1.NewDefaultRoute()
+	r2.ID = "r2"
+	r2.UpstreamId = "u3"
+	sr := &apisixv1.StreamRoute{ID: "sr1", UpstreamId: "u4"}
+
+	referenced := referencedUpstreams([]*apisixv1.Route{r1, r2}, []*apisixv1.StreamRoute{sr},
+		map[string]struct{}{"r2": {}}, nil)
+	assert.Equal(t, map[string]struct{}{"u1": {}, "u2": {}, "u4": {}}, referenced)
+}
+
+func TestOrphanGCDryRun(t *testing.T) {
+	cases := []struct {
+		name       string
+		mode       string
+		namespaces []string
+		incomplete bool
+		gateway    bool
+		dryRun     bool
+	}{
+		{name: "enabled", mode: config.OrphanGCEnabled, namespaces: []string{"default"}},
+		{name: "dry-run", mode: config.OrphanGCDryRun, namespaces: []string{"default"}, dryRun: true},
+		{name: "incomplete", mode: config.OrphanGCEnabled, namespaces: []string{"default"}, incomplete: true, dryRun: true},
+		// e.g. listing the namespaces failed in the watch-all mode.
+		{name: "no namespace", mode: config.OrphanGCEnabled, dryRun: true},
+		{name: "gateway api", mode: config.OrphanGCEnabled, namespaces: []string{"default"}, gateway: true, dryRun: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.NewDefaultConfig()
+			cfg.OrphanGC = tc.mode
+			cfg.Kubernetes.EnableGatewayAPI = tc.gateway
+			c := &Controller{cfg: cfg}
+			assert.Equal(t, tc.dryRun, c.orphanGCDryRun(tc.namespaces, tc.incomplete))
+		})
+	}
+}
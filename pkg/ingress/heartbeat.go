@@ -0,0 +1,154 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+const (
+	// _workerStaleThreshold is the duration after which a worker busy with
+	// a single event is considered stuck.
+	_workerStaleThreshold = 5 * time.Minute
+	// _workerCheckInterval is the interval to check the worker heartbeats.
+	_workerCheckInterval = 30 * time.Second
+)
+
+// workerHeartbeat tracks the heartbeat of a single controller worker, the
+// worker beats when it starts to handle an event and goes idle after that.
+type workerHeartbeat struct {
+	name       string
+	controller string
+	hbs        *workerHeartbeats
+}
+
+// busy records that the worker starts to handle an event.
+func (hb *workerHeartbeat) busy() {
+	hb.hbs.Lock()
+	defer hb.hbs.Unlock()
+	hb.hbs.busySince[hb.name] = hb.hbs.now()
+}
+
+// idle records that the worker finished handling the event.
+func (hb *workerHeartbeat) idle() {
+	hb.hbs.Lock()
+	defer hb.hbs.Unlock()
+	hb.hbs.busySince[hb.name] = time.Time{}
+}
+
+// unregister removes the worker, it should be called once the worker exits.
+func (hb *workerHeartbeat) unregister() {
+	hb.hbs.Lock()
+	defer hb.hbs.Unlock()
+	delete(hb.hbs.busySince, hb.name)
+	delete(hb.hbs.controllers, hb.name)
+}
+
+// workerHeartbeats tracks the heartbeats of all controller workers.
+type workerHeartbeats struct {
+	sync.Mutex
+	// busySince is the time the worker started to handle the current event,
+	// zero if the worker is idle.
+	busySince   map[string]time.Time
+	controllers map[string]string
+	seq         map[string]int
+	now         func() time.Time
+}
+
+func newWorkerHeartbeats() *workerHeartbeats {
+	return &workerHeartbeats{
+		busySince:   make(map[string]time.Time),
+		controllers: make(map[string]string),
+		seq:         make(map[string]int),
+		now:         time.Now,
+	}
+}
+
+// register registers a worker of the controller.
+func (hbs *workerHeartbeats) register(controller string) *workerHeartbeat {
+	hbs.Lock()
+	defer hbs.Unlock()
+	name := fmt.Sprintf("%s-%d", controller, hbs.seq[controller])
+	hbs.seq[controller]++
+	hbs.busySince[name] = time.Time{}
+	hbs.controllers[name] = controller
+	return &workerHeartbeat{
+		name:       name,
+		controller: controller,
+		hbs:        hbs,
+	}
+}
+
+// stale returns the sorted names of the workers which have been busy for
+// longer than the threshold, and the number of them per controller.
+func (hbs *workerHeartbeats) stale(threshold time.Duration) ([]string, map[string]int) {
+	hbs.Lock()
+	defer hbs.Unlock()
+	var (
+		names  []string
+		counts = make(map[string]int)
+		now    = hbs.now()
+	)
+	for name, controller := range hbs.controllers {
+		// Report zero for the controllers without stuck workers, so that
+		// the recovered ones can be reset.
+		if _, ok := counts[controller]; !ok {
+			counts[controller] = 0
+		}
+		since := hbs.busySince[name]
+		if !since.IsZero() && now.Sub(since) > threshold {
+			names = append(names, name)
+			counts[controller]++
+		}
+	}
+	sort.Strings(names)
+	return names, counts
+}
+
+// checkWorkers reports the stuck workers through the metrics and the
+// workers health endpoint periodically.
+func (c *Controller) checkWorkers(ctx context.Context) {
+	t := time.NewTicker(_workerCheckInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		names, counts := c.workerHeartbeats.stale(_workerStaleThreshold)
+		for controller, n := range counts {
+			c.MetricsCollector.SetStaleWorkers(controller, n)
+		}
+		if len(names) > 0 {
+			log.Warnw("some controller workers are stuck",
+				zap.Strings("workers", names),
+				zap.Duration("threshold", _workerStaleThreshold),
+			)
+		}
+		c.apiServer.WorkersHealthState.Lock()
+		c.apiServer.WorkersHealthState.StaleWorkers = names
+		c.apiServer.WorkersHealthState.Unlock()
+	}
+}
@@ -0,0 +1,62 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkerHeartbeats(t *testing.T) {
+	now := time.Now()
+	hbs := newWorkerHeartbeats()
+	hbs.now = func() time.Time {
+		return now
+	}
+
+	route := hbs.register("ApisixRoute")
+	tls := hbs.register("ApisixTls")
+
+	names, counts := hbs.stale(time.Minute)
+	assert.Empty(t, names)
+	assert.Equal(t, map[string]int{"ApisixRoute": 0, "ApisixTls": 0}, counts)
+
+	// The route worker gets stuck in handling an event, while the tls
+	// worker keeps handling events.
+	route.busy()
+	tls.busy()
+	now = now.Add(30 * time.Second)
+	tls.idle()
+	names, _ = hbs.stale(time.Minute)
+	assert.Empty(t, names)
+
+	now = now.Add(time.Minute)
+	tls.busy()
+	names, counts = hbs.stale(time.Minute)
+	assert.Equal(t, []string{"ApisixRoute-0"}, names)
+	assert.Equal(t, map[string]int{"ApisixRoute": 1, "ApisixTls": 0}, counts)
+
+	// The worker recovers.
+	route.idle()
+	names, counts = hbs.stale(time.Minute)
+	assert.Empty(t, names)
+	assert.Equal(t, map[string]int{"ApisixRoute": 0, "ApisixTls": 0}, counts)
+
+	route.unregister()
+	_, counts = hbs.stale(time.Minute)
+	assert.Equal(t, map[string]int{"ApisixTls": 0}, counts)
+}
@@ -17,16 +17,21 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 
+	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
@@ -38,14 +43,12 @@ const (
 type ingressController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newIngressController() *ingressController {
 	ctl := &ingressController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "ingress"),
-		workers:    1,
 	}
 
 	c.ingressInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -53,6 +56,12 @@ func (c *Controller) newIngressController() *ingressController {
 		UpdateFunc: ctl.onUpdate,
 		DeleteFunc: ctl.OnDelete,
 	})
+	// Ingresses check the ApisixConsumers of the auth-consumers annotation.
+	c.apisixConsumerInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ctl.onApisixConsumerChange,
+		UpdateFunc: ctl.onApisixConsumerUpdate,
+		DeleteFunc: ctl.onApisixConsumerChange,
+	})
 	return ctl
 }
 
@@ -61,23 +70,28 @@ func (c *ingressController) run(ctx context.Context) {
 	defer log.Infof("ingress controller exited")
 	defer c.workqueue.ShutDown()
 
-	if !cache.WaitForCacheSync(ctx.Done(), c.controller.ingressInformer.HasSynced) {
+	if !cache.WaitForCacheSync(ctx.Done(), c.controller.ingressInformer.HasSynced, c.controller.apisixConsumerInformer.HasSynced) {
 		log.Errorf("cache sync failed")
 		return
 	}
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
-	<-ctx.Done()
+	c.controller.initialSync.expect("Ingress", c.effectiveKeys())
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *ingressController) runWorker(ctx context.Context) {
+func (c *ingressController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("Ingress")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.controller.initialSync.observe("Ingress", obj.(*types.Event), err)
+		c.controller.manualResync.observe("Ingress", obj.(*types.Event), err)
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
@@ -90,6 +104,9 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 		log.Errorf("found ingress resource with invalid meta namespace key %s: %s", ingEv.Key, err)
 		return err
 	}
+	if c.controller.skipUnwatched(ev, ingEv.Key) {
+		return nil
+	}
 
 	var ing kube.Ingress
 	switch ingEv.GroupVersion {
@@ -126,6 +143,28 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 		}
 		ing = ev.Tombstone.(kube.Ingress)
 	}
+	cluster, err := c.controller.resourceCluster(ingressMeta(ing))
+	if err != nil {
+		if ev.Type == types.EventDelete {
+			// Nothing was pushed since the cluster is unknown.
+			return nil
+		}
+		log.Errorw("failed to find the apisix cluster of ingress",
+			zap.String("key", ingEv.Key),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	if ev.Type != types.EventDelete {
+		if err := c.checkPluginConfigIfNotEmpty(namespace, ing); err != nil {
+			log.Errorw("failed to find the ApisixPluginConfig referenced by ingress",
+				zap.Error(err),
+				zap.Any("ingress", ing),
+			)
+			return err
+		}
+	}
 
 	tctx, err := c.controller.translator.TranslateIngress(ing)
 	if err != nil {
@@ -160,7 +199,7 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 	if ev.Type == types.EventDelete {
 		deleted = m
 	} else if ev.Type == types.EventAdd {
-		added = m
+		added = c.controller.filterResyncManifest(ev, cluster, m)
 	} else {
 		// In the update event, there is no need to verify the upstream in the old ingress,
 		// and the update is based on the latest ingress
@@ -180,9 +219,19 @@ func (c *ingressController) sync(ctx context.Context, ev *types.Event) error {
 			SSLs:          oldCtx.SSL,
 			PluginConfigs: oldCtx.PluginConfigs,
 		}
-		added, updated, deleted = m.Diff(om)
+		oldCluster, err := c.controller.resourceCluster(ingressMeta(ingEv.OldObject))
+		if err != nil {
+			oldCluster = ""
+		}
+		if err := c.controller.syncUpdatedManifests(ctx, oldCluster, cluster, om, m); err != nil {
+			log.Errorw("failed to sync ingress artifacts",
+				zap.Error(err),
+			)
+			return err
+		}
+		return nil
 	}
-	if err := c.controller.syncManifests(ctx, added, updated, deleted); err != nil {
+	if err := c.controller.syncManifestsToCluster(ctx, cluster, added, updated, deleted); err != nil {
 		log.Errorw("failed to sync ingress artifacts",
 			zap.Error(err),
 		)
@@ -288,7 +337,7 @@ func (c *ingressController) onAdd(obj interface{}) {
 		return
 	}
 
-	c.workqueue.Add(&types.Event{
+	c.controller.leaderResync.add(c.workqueue, &types.Event{
 		Type: types.EventAdd,
 		Object: kube.IngressEvent{
 			Key:          key,
@@ -355,32 +404,50 @@ func (c *ingressController) OnDelete(obj interface{}) {
 		log.Errorf("found ingress resource with bad meta namespace key: %s", err)
 		return
 	}
-	if !c.controller.isWatchingNamespace(key) {
-		return
-	}
-	valid := c.isIngressEffective(ing)
-	if valid {
-		log.Debugw("ingress delete event arrived",
-			zap.Any("final state", ing),
-		)
-	} else {
+	if !c.isIngressEffective(ing) {
 		log.Debugw("ignore noneffective ingress delete event",
 			zap.Any("object", ing),
 		)
 		return
 	}
-	c.workqueue.Add(&types.Event{
+	ev := &types.Event{
 		Type: types.EventDelete,
 		Object: kube.IngressEvent{
 			Key:          key,
 			GroupVersion: ing.GroupVersion(),
 		},
 		Tombstone: ing,
-	})
+	}
+	if c.controller.deferPausedDelete(key, func() { c.workqueue.Add(ev) }) {
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("ingress delete event arrived",
+		zap.Any("final state", ing),
+	)
+	c.workqueue.Add(ev)
 
 	c.controller.MetricsCollector.IncrEvents("ingress", "delete")
 }
 
+// effectiveKeys returns the keys of ingresses which should be handled by
+// this controller.
+func (c *ingressController) effectiveKeys() []string {
+	var keys []string
+	for _, obj := range c.controller.ingressInformer.GetIndexer().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil || !c.controller.isWatchingNamespace(key) {
+			continue
+		}
+		if c.isIngressEffective(kube.MustNewIngress(obj)) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
 func (c *ingressController) isIngressEffective(ing kube.Ingress) bool {
 	var (
 		ic  *string
@@ -407,8 +474,157 @@ func (c *ingressController) isIngressEffective(ing kube.Ingress) bool {
 	return false
 }
 
-func (c *ingressController) ResourceSync() {
+// checkPluginConfigIfNotEmpty checks whether the ApisixPluginConfig referenced
+// by the ingress annotation exists, note cross namespace referencing is forbidden
+// unless it's allowed explicitly.
+func (c *ingressController) checkPluginConfigIfNotEmpty(namespace string, ing kube.Ingress) error {
+	ref := ingressAnnotations(ing)[annotations.AnnotationsPluginConfigName]
+	if ref == "" {
+		return nil
+	}
+	namespace, name := translation.ParsePluginConfigReference(namespace, ref)
+	var err error
+	switch c.controller.cfg.Kubernetes.ApisixPluginConfigVersion {
+	case config.ApisixV2beta3:
+		_, err = c.controller.apisixPluginConfigLister.V2beta3(namespace, name)
+	case config.ApisixV2:
+		_, err = c.controller.apisixPluginConfigLister.V2(namespace, name)
+	default:
+		err = fmt.Errorf("unsupported ApisixPluginConfig group version %s", c.controller.cfg.Kubernetes.ApisixPluginConfigVersion)
+	}
+	return err
+}
+
+// syncByPluginConfig re-syncs all Ingresses which reference the given
+// ApisixPluginConfig through the plugin-config-name annotation.
+func (c *ingressController) syncByPluginConfig(namespace, name string) {
 	objs := c.controller.ingressInformer.GetIndexer().List()
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil {
+			continue
+		}
+		ref := accessor.GetAnnotations()[annotations.AnnotationsPluginConfigName]
+		if ref == "" {
+			continue
+		}
+		if pcNamespace, pcName := translation.ParsePluginConfigReference(accessor.GetNamespace(), ref); pcNamespace != namespace || pcName != name {
+			continue
+		}
+		ing := kube.MustNewIngress(obj)
+		if !c.isIngressEffective(ing) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			log.Errorw("found Ingress resource with bad meta namespace key", zap.String("error", err.Error()))
+			continue
+		}
+		log.Debugw("resync ingress since the referenced ApisixPluginConfig changed",
+			zap.String("ingress", key),
+			zap.String("plugin_config", namespace+"/"+name),
+		)
+		c.workqueue.Add(&types.Event{
+			Type: types.EventAdd,
+			Object: kube.IngressEvent{
+				Key:          key,
+				GroupVersion: ing.GroupVersion(),
+			},
+		})
+	}
+}
+
+func (c *ingressController) onApisixConsumerUpdate(oldObj, newObj interface{}) {
+	prev, err := kube.NewApisixConsumer(oldObj)
+	if err != nil {
+		return
+	}
+	curr, err := kube.NewApisixConsumer(newObj)
+	if err != nil {
+		return
+	}
+	if prev.ResourceVersion() >= curr.ResourceVersion() {
+		return
+	}
+	c.onApisixConsumerChange(newObj)
+}
+
+func (c *ingressController) onApisixConsumerChange(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		log.Errorf("found ApisixConsumer resource with bad meta namespace key: %s", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return
+	}
+	c.syncByAuthConsumer(namespace, name)
+}
+
+// syncByAuthConsumer re-syncs the Ingresses in the namespace which list the
+// given ApisixConsumer in the auth-consumers annotation.
+func (c *ingressController) syncByAuthConsumer(namespace, name string) {
+	objs := c.controller.ingressInformer.GetIndexer().List()
+	for _, obj := range objs {
+		accessor, err := meta.Accessor(obj)
+		if err != nil || accessor.GetNamespace() != namespace {
+			continue
+		}
+		if !listsAuthConsumer(accessor.GetAnnotations(), name) {
+			continue
+		}
+		ing := kube.MustNewIngress(obj)
+		if !c.isIngressEffective(ing) {
+			continue
+		}
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			log.Errorw("found Ingress resource with bad meta namespace key", zap.String("error", err.Error()))
+			continue
+		}
+		log.Debugw("resync ingress since the listed ApisixConsumer changed",
+			zap.String("ingress", key),
+			zap.String("consumer", namespace+"/"+name),
+		)
+		c.workqueue.Add(&types.Event{
+			Type: types.EventAdd,
+			Object: kube.IngressEvent{
+				Key:          key,
+				GroupVersion: ing.GroupVersion(),
+			},
+		})
+	}
+}
+
+// listsAuthConsumer reports whether the auth-consumers annotation lists the
+// ApisixConsumer.
+func listsAuthConsumer(anno map[string]string, name string) bool {
+	for _, consumer := range annotations.NewExtractor(anno).GetStringsAnnotation(annotations.AnnotationsAuthConsumers) {
+		if strings.TrimSpace(consumer) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func ingressAnnotations(ing kube.Ingress) map[string]string {
+	switch ing.GroupVersion() {
+	case kube.IngressV1:
+		return ing.V1().GetAnnotations()
+	case kube.IngressV1beta1:
+		return ing.V1beta1().GetAnnotations()
+	default:
+		return ing.ExtensionsV1beta1().GetAnnotations()
+	}
+}
+
+func (c *ingressController) ResourceSync(snapshots utils.Snapshots, namespace string) []string {
+	var keys []string
+	objs := c.controller.resourceSyncObjects(c.controller.ingressInformer, namespace)
 	for _, obj := range objs {
 		key, err := cache.MetaNamespaceKeyFunc(obj)
 		if err != nil {
@@ -419,12 +635,15 @@ func (c *ingressController) ResourceSync() {
 			continue
 		}
 		ing := kube.MustNewIngress(obj)
+		keys = append(keys, key)
 		c.workqueue.Add(&types.Event{
 			Type: types.EventAdd,
 			Object: kube.IngressEvent{
 				Key:          key,
 				GroupVersion: ing.GroupVersion(),
 			},
+			Snapshot: snapshots,
 		})
 	}
+	return keys
 }
@@ -22,9 +22,15 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
 
 	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/namespace"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
 )
 
 func TestIsIngressEffective(t *testing.T) {
@@ -169,3 +175,55 @@ func TestIsIngressEffective(t *testing.T) {
 	// Spec.IngressClassName takes the precedence.
 	assert.Equal(t, false, c.isIngressEffective(ing))
 }
+
+func TestApisixConsumerResyncIngresses(t *testing.T) {
+	newIngress := func(ns, name, consumers string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+				Annotations: map[string]string{
+					_ingressKey:                          "apisix",
+					annotations.AnnotationsAuthType:      "keyAuth",
+					annotations.AnnotationsAuthConsumers: consumers,
+				},
+			},
+		}
+	}
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{})
+	assert.Nil(t, informer.GetIndexer().Add(newIngress("default", "listed", "jack, rose")))
+	assert.Nil(t, informer.GetIndexer().Add(newIngress("default", "unlisted", "rose")))
+	assert.Nil(t, informer.GetIndexer().Add(newIngress("another", "listed", "jack")))
+
+	ctl := &ingressController{
+		controller: &Controller{
+			cfg:               config.NewDefaultConfig(),
+			ingressInformer:   informer,
+			namespaceProvider: namespace.NewMockWatchingProvider([]string{"default", "another"}),
+		},
+		workqueue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+	}
+	defer ctl.workqueue.ShutDown()
+
+	ac := &configv2.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "jack",
+			Namespace:       "default",
+			ResourceVersion: "1",
+		},
+	}
+	ctl.onApisixConsumerChange(ac)
+	assert.Equal(t, 1, ctl.workqueue.Len())
+	obj, _ := ctl.workqueue.Get()
+	assert.Equal(t, kube.IngressEvent{Key: "default/listed", GroupVersion: kube.IngressV1}, obj.(*types.Event).Object)
+	ctl.workqueue.Done(obj)
+
+	// Stale updates are ignored.
+	ctl.onApisixConsumerUpdate(ac, ac)
+	assert.Equal(t, 0, ctl.workqueue.Len())
+
+	curr := ac.DeepCopy()
+	curr.ResourceVersion = "2"
+	ctl.onApisixConsumerUpdate(ac, curr)
+	assert.Equal(t, 1, ctl.workqueue.Len())
+}
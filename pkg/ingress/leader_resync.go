@@ -0,0 +1,77 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// leaderResync spreads the events of the objects added after the leader
+// acquisition, which are mostly the ones listed by the informers, over a
+// ramp, so that the new leader doesn't push all the resources to APISIX
+// at once.
+type leaderResync struct {
+	deadline time.Time
+}
+
+// newLeaderResync creates a leaderResync whose ramp starts now, nil is
+// returned if the ramp is disabled.
+func newLeaderResync(ramp time.Duration) *leaderResync {
+	if ramp <= 0 {
+		return nil
+	}
+	return &leaderResync{
+		deadline: time.Now().Add(ramp),
+	}
+}
+
+// add adds the event to the queue after a random delay within the rest of
+// the ramp, the event is added immediately once the ramp is over.
+func (r *leaderResync) add(queue workqueue.RateLimitingInterface, ev *types.Event) {
+	if r == nil {
+		queue.Add(ev)
+		return
+	}
+	remaining := time.Until(r.deadline)
+	if remaining <= 0 {
+		queue.Add(ev)
+		return
+	}
+	queue.AddAfter(ev, time.Duration(rand.Int63n(int64(remaining))))
+}
+
+// run marks the resync as in progress in the metrics until the ramp is over.
+func (r *leaderResync) run(ctx context.Context, collector metrics.Collector) {
+	if r == nil {
+		return
+	}
+	collector.SetLeaderResync(true)
+	defer collector.SetLeaderResync(false)
+
+	timer := time.NewTimer(time.Until(r.deadline))
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,54 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestLeaderResync(t *testing.T) {
+	queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer queue.ShutDown()
+
+	// Disabled, the events are added immediately.
+	var r *leaderResync
+	assert.Nil(t, newLeaderResync(0))
+	r.add(queue, &types.Event{Object: "default/a"})
+	assert.Equal(t, 1, queue.Len())
+	obj, _ := queue.Get()
+	queue.Done(obj)
+
+	// The events are delayed within the ramp.
+	r = newLeaderResync(200 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		r.add(queue, &types.Event{Object: "default/b"})
+	}
+	assert.Less(t, queue.Len(), 10)
+	assert.Eventually(t, func() bool {
+		return queue.Len() == 10
+	}, time.Second, 10*time.Millisecond)
+
+	// The ramp is over.
+	time.Sleep(time.Until(r.deadline))
+	r.add(queue, &types.Event{Object: "default/c"})
+	assert.Equal(t, 11, queue.Len())
+}
@@ -0,0 +1,274 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// legacyIDPair is the ID of an APISIX object generated by the earlier versions
+// (see id.GenLegacyID) and the ID generated for the same object now.
+type legacyIDPair struct {
+	legacy  string
+	current string
+}
+
+// namedLegacyIDPairs returns the ID pairs of objects of the kind whose IDs are
+// generated from the names.
+func namedLegacyIDPairs(kind id.Kind, names map[string]struct{}) []legacyIDPair {
+	pairs := make([]legacyIDPair, 0, len(names))
+	for name := range names {
+		pairs = append(pairs, legacyIDPair{
+			legacy:  id.GenLegacyID(name),
+			current: id.GenID(kind, name),
+		})
+	}
+	return pairs
+}
+
+// upstreamLegacyIDPairs returns the ID pairs of upstreams. The IDs of upstreams
+// are generated from the configs now (see apisixv1.ComposeUpstreamID), so an
+// upstream with the ID generated from the name by the earlier versions is
+// paired with the other upstreams with the same name. So is the upstream with
+// the legacy ID if legacy is true.
+func upstreamLegacyIDPairs(upstreams []*apisixv1.Upstream, legacy bool) []legacyIDPair {
+	var pairs []legacyIDPair
+	for _, u := range upstreams {
+		named := id.GenID(id.Upstream, u.Name)
+		if u.ID == named || u.ID == id.GenLegacyID(u.Name) {
+			continue
+		}
+		pairs = append(pairs, legacyIDPair{legacy: named, current: u.ID})
+		if legacy {
+			pairs = append(pairs, legacyIDPair{legacy: id.GenLegacyID(u.Name), current: u.ID})
+		}
+	}
+	return pairs
+}
+
+// streamRouteLegacyIDPairs returns the ID pairs of stream routes. Stream
+// routes have no names, so a legacy one is paired with the stream route with
+// current ID which serves the same port and SNI.
+func streamRouteLegacyIDPairs(streamRoutes []*apisixv1.StreamRoute) []legacyIDPair {
+	currentLen := len(id.GenID(id.StreamRoute, "-"))
+	current := make(map[string]string)
+	for _, r := range streamRoutes {
+		if len(r.ID) == currentLen {
+			current[fmt.Sprintf("%d/%s", r.ServerPort, r.SNI)] = r.ID
+		}
+	}
+	var pairs []legacyIDPair
+	for _, r := range streamRoutes {
+		if len(r.ID) == currentLen {
+			continue
+		}
+		if cur, ok := current[fmt.Sprintf("%d/%s", r.ServerPort, r.SNI)]; ok {
+			pairs = append(pairs, legacyIDPair{legacy: r.ID, current: cur})
+		}
+	}
+	return pairs
+}
+
+// replacedLegacyObjects picks the objects with legacy IDs which are replaced by
+// the ones with current IDs, the objects are given as a map from the ID to
+// the managed-by label.
+func replacedLegacyObjects(objects map[string]string, pairs []legacyIDPair) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range pairs {
+		if pair.legacy == pair.current {
+			continue
+		}
+		label, ok := objects[pair.legacy]
+		if !ok {
+			continue
+		}
+		if _, ok := objects[pair.current]; ok {
+			result[pair.legacy] = label
+		}
+	}
+	return result
+}
+
+// migrateLegacyIDs deletes the APISIX objects created by the earlier versions
+// whose IDs might collide, once they are replaced by the objects with the
+// current IDs, so they won't be orphaned after upgrading. Objects are only
+// deleted when their replacements exist, so it's safe to run before all the
+// resources are synced. It returns true if there is no legacy object left.
+func (c *Controller) migrateLegacyIDs(ctx context.Context) bool {
+	// The legacy IDs are never prefixed, so the objects with them belong to
+	// the controllers without ID prefix. The prefixed controllers only
+	// migrate the upstreams with the IDs generated from the names.
+	legacy := id.Prefix() == ""
+	var (
+		sslPairs        []legacyIDPair
+		globalRulePairs []legacyIDPair
+	)
+	if legacy {
+		for _, key := range c.apisixTlsInformer.GetIndexer().ListKeys() {
+			name := strings.Replace(key, "/", "_", 1)
+			sslPairs = append(sslPairs, legacyIDPair{
+				legacy:  id.GenLegacyID(name),
+				current: id.GenID(id.SSL, name),
+			})
+		}
+		for _, key := range c.ingressInformer.GetIndexer().ListKeys() {
+			ns, name, err := cache.SplitMetaNamespaceKey(key)
+			if err != nil {
+				continue
+			}
+			sslPairs = append(sslPairs, legacyIDPair{
+				legacy:  id.GenLegacyID(ns + "_" + name + "-tls"),
+				current: id.GenID(id.SSL, translation.ComposeIngressSSLName(ns, name)),
+			})
+		}
+		for _, name := range c.apisixClusterConfigInformer.GetIndexer().ListKeys() {
+			globalRulePairs = append(globalRulePairs, legacyIDPair{
+				legacy:  id.GenLegacyID(name),
+				current: id.GenID(id.GlobalRule, name),
+			})
+		}
+	}
+
+	done := true
+	for _, clusterName := range c.clusterNames() {
+		found, err := c.migrateClusterLegacyIDs(ctx, clusterName, legacy, sslPairs, globalRulePairs)
+		if err != nil {
+			log.Errorw("failed to migrate APISIX objects with legacy IDs",
+				zap.String("cluster", clusterName),
+				zap.Error(err),
+			)
+			done = false
+			continue
+		}
+		if found > 0 {
+			log.Infow("migrating APISIX objects with legacy IDs",
+				zap.String("cluster", clusterName),
+				zap.Int("objects", found),
+			)
+			done = false
+		}
+	}
+	return done
+}
+
+// migrateClusterLegacyIDs deletes the replaced legacy objects in the cluster,
+// it returns the number of legacy objects found. Only the upstreams are
+// migrated if legacy is false.
+func (c *Controller) migrateClusterLegacyIDs(ctx context.Context, clusterName string, legacy bool, sslPairs, globalRulePairs []legacyIDPair) (int, error) {
+	cluster := c.apisix.Cluster(clusterName)
+
+	// The IDs of routes and plugin configs are generated from their names,
+	// except that the plugin configs translated from Ingress use the route
+	// names. The upstreams are paired by upstreamLegacyIDPairs.
+	names := make(map[string]struct{})
+	routes, err := cluster.Route().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	routeObjects := make(map[string]string, len(routes))
+	for _, r := range routes {
+		routeObjects[r.ID] = r.Labels[apisixv1.ManagedByLabel]
+		names[r.Name] = struct{}{}
+	}
+	streamRoutes, err := cluster.StreamRoute().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	streamRouteObjects := make(map[string]string, len(streamRoutes))
+	for _, r := range streamRoutes {
+		streamRouteObjects[r.ID] = r.Labels[apisixv1.ManagedByLabel]
+	}
+	upstreams, err := cluster.Upstream().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	upstreamObjects := make(map[string]string, len(upstreams))
+	for _, u := range upstreams {
+		upstreamObjects[u.ID] = u.Labels[apisixv1.ManagedByLabel]
+	}
+	pluginConfigs, err := cluster.PluginConfig().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pluginConfigObjects := make(map[string]string, len(pluginConfigs))
+	for _, pc := range pluginConfigs {
+		pluginConfigObjects[pc.ID] = pc.Labels[apisixv1.ManagedByLabel]
+		names[pc.Name] = struct{}{}
+	}
+	ssls, err := cluster.SSL().List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sslObjects := make(map[string]string, len(ssls))
+	for _, s := range ssls {
+		sslObjects[s.ID] = s.Labels[apisixv1.ManagedByLabel]
+	}
+
+	orphans := &orphanResources{
+		upstreams: replacedLegacyObjects(upstreamObjects, upstreamLegacyIDPairs(upstreams, legacy)),
+	}
+	if legacy {
+		orphans.routes = replacedLegacyObjects(routeObjects, namedLegacyIDPairs(id.Route, names))
+		orphans.streamRoutes = replacedLegacyObjects(streamRouteObjects, streamRouteLegacyIDPairs(streamRoutes))
+		orphans.ssls = replacedLegacyObjects(sslObjects, sslPairs)
+		orphans.pluginConfigs = replacedLegacyObjects(pluginConfigObjects, namedLegacyIDPairs(id.PluginConfig, names))
+	}
+	found := len(managedOrphans(orphans.routes)) + len(managedOrphans(orphans.streamRoutes)) +
+		len(managedOrphans(orphans.upstreams)) + len(managedOrphans(orphans.ssls)) +
+		len(managedOrphans(orphans.pluginConfigs))
+	if found > 0 {
+		c.collectOrphanResources(ctx, clusterName, orphans, false)
+	}
+
+	if !legacy {
+		return found, nil
+	}
+	// Global rules are not labeled, they are picked by the names of
+	// ApisixClusterConfigs.
+	globalRules, err := cluster.GlobalRule().List(ctx)
+	if err != nil {
+		return found, err
+	}
+	globalRuleObjects := make(map[string]string, len(globalRules))
+	for _, gr := range globalRules {
+		globalRuleObjects[gr.ID] = apisixv1.ManagedBy()
+	}
+	for legacy := range replacedLegacyObjects(globalRuleObjects, globalRulePairs) {
+		found++
+		if err := cluster.GlobalRule().Delete(ctx, &apisixv1.GlobalRule{ID: legacy}); err != nil {
+			log.Errorw("failed to delete global_rule with legacy ID",
+				zap.String("cluster", clusterName),
+				zap.String("id", legacy),
+				zap.Error(err),
+			)
+			continue
+		}
+		log.Infow("global_rule with legacy ID deleted",
+			zap.String("cluster", clusterName),
+			zap.String("id", legacy),
+		)
+	}
+	return found, nil
+}
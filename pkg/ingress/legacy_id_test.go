@@ -0,0 +1,148 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestReplacedLegacyObjects(t *testing.T) {
+	pairs := namedLegacyIDPairs(id.Route, map[string]struct{}{
+		"default_foo_rule1": {},
+		"default_bar_rule1": {},
+		"default_baz_rule1": {},
+	})
+	objects := map[string]string{
+		// foo is migrated.
+		id.GenLegacyID("default_foo_rule1"):     apisixv1.ManagedBy(),
+		id.GenID(id.Route, "default_foo_rule1"): apisixv1.ManagedBy(),
+		// bar is not synced yet.
+		id.GenLegacyID("default_bar_rule1"): apisixv1.ManagedBy(),
+		// baz is created by the current version.
+		id.GenID(id.Route, "default_baz_rule1"): apisixv1.ManagedBy(),
+		"user-created":                          "",
+	}
+	assert.Equal(t, map[string]string{
+		id.GenLegacyID("default_foo_rule1"): apisixv1.ManagedBy(),
+	}, replacedLegacyObjects(objects, pairs))
+
+	objects[id.GenID(id.Route, "default_bar_rule1")] = apisixv1.ManagedBy()
+	assert.Len(t, replacedLegacyObjects(objects, pairs), 2)
+
+	// Objects of other kinds translated from the same names are not paired.
+	assert.Len(t, replacedLegacyObjects(objects, namedLegacyIDPairs(id.PluginConfig, map[string]struct{}{
+		"default_foo_rule1": {},
+	})), 0)
+}
+
+func TestStreamRouteLegacyIDPairs(t *testing.T) {
+	legacy := &apisixv1.StreamRoute{ID: id.GenLegacyID("default_foo_rule1_tcp"), ServerPort: 9100}
+	current := &apisixv1.StreamRoute{ID: id.GenID(id.StreamRoute, "default_foo_rule1_tcp"), ServerPort: 9100}
+	other := &apisixv1.StreamRoute{ID: id.GenLegacyID("default_bar_rule1_tcp"), ServerPort: 9200}
+
+	pairs := streamRouteLegacyIDPairs([]*apisixv1.StreamRoute{legacy, current, other})
+	assert.Equal(t, []legacyIDPair{{legacy: legacy.ID, current: current.ID}}, pairs)
+}
+
+func TestUpstreamLegacyIDPairs(t *testing.T) {
+	named := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: id.GenID(id.Upstream, "default_foo_80"), Name: "default_foo_80"}}
+	current := apisixv1.NewDefaultUpstream()
+	current.Name = "default_foo_80"
+	current.ID = apisixv1.ComposeUpstreamID(current)
+	other := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: id.GenLegacyID("default_bar_80"), Name: "default_bar_80"}}
+
+	pairs := upstreamLegacyIDPairs([]*apisixv1.Upstream{named, current, other}, false)
+	assert.Equal(t, []legacyIDPair{{legacy: named.ID, current: current.ID}}, pairs)
+
+	pairs = upstreamLegacyIDPairs([]*apisixv1.Upstream{named, current, other}, true)
+	assert.Equal(t, []legacyIDPair{
+		{legacy: named.ID, current: current.ID},
+		{legacy: id.GenLegacyID("default_foo_80"), current: current.ID},
+	}, pairs)
+}
+
+type fakeMigrateAPISIX struct {
+	apisix.APISIX
+	route *fakeMigrateRoute
+}
+
+func (f *fakeMigrateAPISIX) Cluster(string) apisix.Cluster {
+	return &fakeMigrateCluster{route: f.route}
+}
+
+type fakeMigrateCluster struct {
+	apisix.Cluster
+	route *fakeMigrateRoute
+}
+
+func (f *fakeMigrateCluster) Route() apisix.Route {
+	return f.route
+}
+
+type fakeMigrateRoute struct {
+	apisix.Route
+	listed chan struct{}
+}
+
+func (f *fakeMigrateRoute) List(context.Context) ([]*apisixv1.Route, error) {
+	f.listed <- struct{}{}
+	return nil, errors.New("unavailable")
+}
+
+func TestMigrateLegacyIDsAfterInitialSync(t *testing.T) {
+	route := &fakeMigrateRoute{listed: make(chan struct{}, 1)}
+	c := &Controller{
+		cfg:                         config.NewDefaultConfig(),
+		apisix:                      &fakeMigrateAPISIX{route: route},
+		apisixTlsInformer:           cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv2.ApisixTls{}, 0, cache.Indexers{}),
+		ingressInformer:             cache.NewSharedIndexInformer(&cache.ListWatch{}, &networkingv1.Ingress{}, 0, cache.Indexers{}),
+		apisixClusterConfigInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &configv2.ApisixClusterConfig{}, 0, cache.Indexers{}),
+		resourceSyncReload:          make(chan struct{}, 1),
+		manualResync:                newManualResync(),
+		initialSync:                 newInitialSyncTracker([]string{"ApisixRoute"}, nil),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.resourceSyncLoop(ctx)
+
+	// Nothing is migrated before the initial sync is done.
+	select {
+	case <-route.listed:
+		t.Fatal("legacy IDs are migrated before the initial sync is done")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The migration runs right after the initial sync, not after the
+	// first periodic sync.
+	c.initialSync.expect("ApisixRoute", nil)
+	select {
+	case <-route.listed:
+	case <-time.After(time.Second):
+		t.Fatal("legacy IDs are not migrated after the initial sync")
+	}
+}
@@ -0,0 +1,224 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	apirouter "github.com/apache/apisix-ingress-controller/pkg/api/router"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// errResyncAborted is reported to the manual resync requests when the
+// controller stops leading before the resync completes.
+var errResyncAborted = errors.New("resync aborted since the controller is no longer leading")
+
+// manualResync collects the manual resync requests. The requests arriving
+// before a resync starts are coalesced into it, the ones arriving during a
+// resync are served by the next one, so every request sees a resync which
+// starts after it.
+type manualResync struct {
+	mu      sync.Mutex
+	waiters []chan error
+	// notify is signaled when there are pending requests.
+	notify chan struct{}
+	// running are the resyncs whose resources are not all synced yet.
+	running []*resyncProgress
+}
+
+// resyncProgress tracks the resources queued by a manual resync.
+type resyncProgress struct {
+	waiters []chan error
+	// synced contains the keys of resources synced since the resync
+	// starts, it's only used before the queued resources are known.
+	synced map[string]map[string]struct{}
+	// pending contains the keys of the queued resources not synced yet,
+	// it's nil before the queued resources are known.
+	pending map[string]map[string]struct{}
+	// queued is the number of the queued resources.
+	queued int
+	failed int
+}
+
+func newManualResync() *manualResync {
+	return &manualResync{
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// request asks for a resync, the returned channel receives nil once all the
+// resources queued by it are synced, or the error if some of them failed,
+// and progress reports the progress of the resync.
+func (m *manualResync) request() (<-chan error, func() apirouter.ResyncProgress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan error, 1)
+	m.waiters = append(m.waiters, ch)
+	trySignal(m.notify)
+	return ch, func() apirouter.ResyncProgress {
+		return m.progress(ch)
+	}
+}
+
+// progress returns the progress of the resync serving the request, it's
+// empty if the resync doesn't start yet or is already done.
+func (m *manualResync) progress(ch chan error) apirouter.ResyncProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.running {
+		for _, waiter := range p.waiters {
+			if waiter != ch {
+				continue
+			}
+			if p.pending == nil {
+				return apirouter.ResyncProgress{}
+			}
+			pending := 0
+			for _, keys := range p.pending {
+				pending += len(keys)
+			}
+			return apirouter.ResyncProgress{
+				Queued: p.queued,
+				Synced: p.queued - pending,
+				Failed: p.failed,
+			}
+		}
+	}
+	return apirouter.ResyncProgress{}
+}
+
+// take removes the pending requests, they are served by the resync which
+// is about to start. The resources synced from now on are recorded, since
+// they might be synced before the resync finishes queueing them.
+func (m *manualResync) take() *resyncProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p := &resyncProgress{
+		waiters: m.waiters,
+		synced:  make(map[string]map[string]struct{}),
+	}
+	m.waiters = nil
+	m.running = append(m.running, p)
+	return p
+}
+
+// queued records the resources queued by the resync, keyed by the kind, the
+// requests are notified once they are all synced.
+func (m *manualResync) queued(p *resyncProgress, keys map[string][]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p.pending = make(map[string]map[string]struct{})
+	for kind, kindKeys := range keys {
+		p.queued += len(kindKeys)
+		for _, key := range kindKeys {
+			if _, ok := p.synced[kind][key]; ok {
+				continue
+			}
+			if p.pending[kind] == nil {
+				p.pending[kind] = make(map[string]struct{})
+			}
+			p.pending[kind][key] = struct{}{}
+		}
+	}
+	p.synced = nil
+	m.check()
+}
+
+// observe records the sync result of the event.
+func (m *manualResync) observe(kind string, ev *types.Event, err error) {
+	key := eventKey(ev)
+	// The resource might be deleted before it's synced.
+	failed := err != nil && !k8serrors.IsNotFound(err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, p := range m.running {
+		if p.pending == nil {
+			if p.synced[kind] == nil {
+				p.synced[kind] = make(map[string]struct{})
+			}
+			p.synced[kind][key] = struct{}{}
+			continue
+		}
+		if _, ok := p.pending[kind][key]; !ok {
+			continue
+		}
+		delete(p.pending[kind], key)
+		if failed {
+			p.failed++
+		}
+	}
+	m.check()
+}
+
+// abort notifies all the requests that the resyncs won't complete.
+func (m *manualResync) abort() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.running {
+		notifyResync(p.waiters, errResyncAborted)
+	}
+	m.running = nil
+	notifyResync(m.waiters, errResyncAborted)
+	m.waiters = nil
+}
+
+// check notifies the requests of the resyncs whose resources are all synced.
+func (m *manualResync) check() {
+	running := m.running[:0]
+	for _, p := range m.running {
+		if p.pending == nil || !p.done() {
+			running = append(running, p)
+			continue
+		}
+		var err error
+		if p.failed > 0 {
+			err = fmt.Errorf("%d resources failed to sync, they are retried in the background", p.failed)
+		}
+		log.Infow("manual resync completed",
+			zap.Int("requests", len(p.waiters)),
+			zap.Int("failed", p.failed),
+		)
+		notifyResync(p.waiters, err)
+	}
+	m.running = running
+}
+
+func (p *resyncProgress) done() bool {
+	for _, keys := range p.pending {
+		if len(keys) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func notifyResync(waiters []chan error, err error) {
+	for _, ch := range waiters {
+		ch <- err
+		close(ch)
+	}
+}
@@ -0,0 +1,121 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apirouter "github.com/apache/apisix-ingress-controller/pkg/api/router"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// resyncResult returns the result of the resync request, ok is false if
+// it's not completed yet.
+func resyncResult(ch <-chan error) (ok bool, err error) {
+	select {
+	case err = <-ch:
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func routeEvent(key string) *types.Event {
+	return &types.Event{
+		Type:   types.EventAdd,
+		Object: kube.ApisixRouteEvent{Key: key, GroupVersion: kube.ApisixRouteV2},
+	}
+}
+
+func TestManualResyncCoalesces(t *testing.T) {
+	m := newManualResync()
+
+	first, _ := m.request()
+	second, _ := m.request()
+	// Both requests are pending, only one resync is signaled.
+	<-m.notify
+	assert.Len(t, m.notify, 0)
+
+	p := m.take()
+	assert.Len(t, p.waiters, 2)
+
+	// The request arriving during the resync is served by the next one.
+	third, _ := m.request()
+	m.queued(p, nil)
+	ok, _ := resyncResult(first)
+	assert.True(t, ok)
+	ok, _ = resyncResult(second)
+	assert.True(t, ok)
+	ok, _ = resyncResult(third)
+	assert.False(t, ok)
+
+	<-m.notify
+	m.queued(m.take(), nil)
+	ok, err := resyncResult(third)
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.Len(t, m.take().waiters, 0)
+}
+
+func TestManualResyncWaitsForSync(t *testing.T) {
+	m := newManualResync()
+	done, progress := m.request()
+	assert.Equal(t, apirouter.ResyncProgress{}, progress())
+	p := m.take()
+
+	// foo is synced before the resync finishes queueing it.
+	m.observe("ApisixRoute", routeEvent("default/foo"), nil)
+	assert.Equal(t, apirouter.ResyncProgress{}, progress())
+	m.queued(p, map[string][]string{
+		"ApisixRoute": {"default/foo", "default/bar", "default/baz"},
+	})
+	ok, _ := resyncResult(done)
+	assert.False(t, ok)
+	assert.Equal(t, apirouter.ResyncProgress{Queued: 3, Synced: 1}, progress())
+
+	// The events of other kinds are not counted.
+	m.observe("ApisixTls", &types.Event{
+		Object: kube.ApisixTlsEvent{Key: "default/bar"},
+	}, nil)
+	m.observe("ApisixRoute", routeEvent("default/bar"), nil)
+	ok, _ = resyncResult(done)
+	assert.False(t, ok)
+	assert.Equal(t, apirouter.ResyncProgress{Queued: 3, Synced: 2}, progress())
+
+	m.observe("ApisixRoute", routeEvent("default/baz"), errors.New("connection refused"))
+	ok, err := resyncResult(done)
+	assert.True(t, ok)
+	assert.EqualError(t, err, "1 resources failed to sync, they are retried in the background")
+	assert.Len(t, m.running, 0)
+}
+
+func TestManualResyncAbort(t *testing.T) {
+	m := newManualResync()
+	running, _ := m.request()
+	m.queued(m.take(), map[string][]string{"ApisixRoute": {"default/foo"}})
+	pending, _ := m.request()
+
+	m.abort()
+	ok, err := resyncResult(running)
+	assert.True(t, ok)
+	assert.Equal(t, errResyncAborted, err)
+	ok, err = resyncResult(pending)
+	assert.True(t, ok)
+	assert.Equal(t, errResyncAborted, err)
+}
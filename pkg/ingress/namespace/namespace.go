@@ -91,16 +91,34 @@ func (c *namespaceController) sync(ctx context.Context, ev *types.Event) error {
 		if err != nil {
 			return err
 		} else {
-			// if labels of namespace contains the watchingLabels, the namespace should be set to controller.watchingNamespaces
-			if c.controller.watchingLabels.IsSubsetOf(namespace.Labels) {
-				c.controller.watchingNamespaces.Store(namespace.Name, struct{}{})
+			// if labels of namespace match the selector, the namespace should be set to controller.watchingNamespaces
+			selected := false
+			if c.controller.matchNamespace(namespace) {
+				selected = c.controller.selectNamespace(namespace.Name)
+			} else {
+				c.controller.unselectNamespace(namespace.Name)
+			}
+			resumed := c.controller.updatePaused(namespace)
+			// The events of the resources in the namespace were dropped
+			// before it's selected or resumed, reconcile them again. The
+			// ones queued before it's unselected are skipped by the
+			// resource controllers.
+			if (selected || resumed) && c.controller.onResync != nil && c.controller.IsWatchingNamespace(namespace.Name+"/") {
+				c.controller.onResync(namespace.Name)
 			}
 		}
 	} else { // type == types.EventDelete
 		namespace := ev.Tombstone.(*corev1.Namespace)
+		// The deletions of the resources in a paused namespace are deferred,
+		// resync it so that they are applied.
+		paused := c.controller.IsPausedNamespace(namespace.Name + "/")
 		if _, ok := c.controller.watchingNamespaces.Load(namespace.Name); ok {
 			c.controller.watchingNamespaces.Delete(namespace.Name)
 		}
+		c.controller.pausedNamespaces.Delete(namespace.Name)
+		if paused && c.controller.onResync != nil {
+			c.controller.onResync(namespace.Name)
+		}
 		// do nothing, if the namespace did not in controller.watchingNamespaces
 	}
 	return nil
@@ -0,0 +1,196 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package namespace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/apache/apisix-ingress-controller/pkg/api/router"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestNamespaceSelectionsFollowLabels(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "explicit"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Labels: map[string]string{"apisix": "on"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bar"}},
+	)
+	provider := &watchingProvider{
+		kube:               &kube.KubeClient{Client: client},
+		watchingNamespaces: new(sync.Map),
+		selector:           labels.SelectorFromSet(labels.Set{"apisix": "on"}),
+	}
+	provider.watchingNamespaces.Store("explicit", SelectedExplicitly)
+	ctl := &namespaceController{controller: provider}
+	ctx := context.Background()
+	assert.Nil(t, provider.initWatchingNamespacesByLabels(ctx))
+
+	state := &router.NamespacesState{Namespaces: provider.NamespaceSelections}
+	r := gin.New()
+	router.MountNamespaces(r, state, "s3cret")
+	getNamespaces := func() map[string]string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/debug/namespaces", nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Namespaces []struct {
+				Name       string `json:"name"`
+				SelectedBy string `json:"selected_by"`
+			} `json:"namespaces"`
+		}
+		assert.Nil(t, json.NewDecoder(w.Body).Decode(&resp))
+		selections := make(map[string]string)
+		for _, ns := range resp.Namespaces {
+			selections[ns.Name] = ns.SelectedBy
+		}
+		return selections
+	}
+	assert.Equal(t, map[string]string{"explicit": "explicit", "foo": "label"}, getNamespaces())
+
+	updateLabels := func(name string, labels map[string]string) {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		ns.Labels = labels
+		_, err = client.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		assert.Nil(t, err)
+		assert.Nil(t, ctl.sync(ctx, &types.Event{Type: types.EventUpdate, Object: name}))
+	}
+
+	// bar is selected once it's labeled.
+	updateLabels("bar", map[string]string{"apisix": "on"})
+	assert.Equal(t, map[string]string{"explicit": "explicit", "foo": "label", "bar": "label"}, getNamespaces())
+
+	// foo is no longer watched after the label is removed.
+	updateLabels("foo", nil)
+	assert.Equal(t, map[string]string{"explicit": "explicit", "bar": "label"}, getNamespaces())
+
+	// The explicitly specified namespaces don't depend on the labels.
+	updateLabels("explicit", nil)
+	assert.Equal(t, map[string]string{"explicit": "explicit", "bar": "label"}, getNamespaces())
+}
+
+func TestNamespaceReconcilePaused(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: map[string]string{ReconcilePausedAnnotation: "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bar"}},
+	)
+	var resumed []string
+	provider := &watchingProvider{
+		kube:               &kube.KubeClient{Client: client},
+		watchingNamespaces: new(sync.Map),
+		onResync: func(ns string) {
+			resumed = append(resumed, ns)
+		},
+	}
+	ctl := &namespaceController{controller: provider}
+	ctx := context.Background()
+	assert.Nil(t, provider.initPausedNamespaces(ctx))
+
+	assert.False(t, provider.IsWatchingNamespace("foo/route"))
+	assert.True(t, provider.IsPausedNamespace("foo/route"))
+	assert.True(t, provider.IsWatchingNamespace("bar/route"))
+	assert.False(t, provider.IsPausedNamespace("bar/route"))
+
+	updateAnnotations := func(name string, annotations map[string]string) {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		ns.Annotations = annotations
+		_, err = client.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		assert.Nil(t, err)
+		assert.Nil(t, ctl.sync(ctx, &types.Event{Type: types.EventUpdate, Object: name}))
+	}
+
+	updateAnnotations("bar", map[string]string{ReconcilePausedAnnotation: "true"})
+	assert.False(t, provider.IsWatchingNamespace("bar/route"))
+	assert.Len(t, resumed, 0)
+
+	// foo is resynced once the annotation is removed.
+	updateAnnotations("foo", nil)
+	assert.True(t, provider.IsWatchingNamespace("foo/route"))
+	assert.Equal(t, []string{"foo"}, resumed)
+
+	// Unrelated updates don't trigger resyncs.
+	updateAnnotations("foo", map[string]string{"foo": "bar"})
+	assert.Equal(t, []string{"foo"}, resumed)
+
+	// The paused bar is resynced once it's deleted, so that the deletions
+	// deferred are applied.
+	ns, err := client.CoreV1().Namespaces().Get(ctx, "bar", metav1.GetOptions{})
+	assert.Nil(t, err)
+	assert.Nil(t, ctl.sync(ctx, &types.Event{Type: types.EventDelete, Object: "bar", Tombstone: ns}))
+	assert.False(t, provider.IsPausedNamespace("bar/route"))
+	assert.Equal(t, []string{"foo", "bar"}, resumed)
+}
+
+func TestNamespaceSelectorExpression(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "prod", Labels: map[string]string{"env": "prod"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Labels: map[string]string{"env": "prod", "legacy": "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "dev", Labels: map[string]string{"env": "dev"}}},
+	)
+	selector, err := labels.Parse("env in (prod,staging),!legacy")
+	assert.Nil(t, err)
+	var resynced []string
+	provider := &watchingProvider{
+		kube:               &kube.KubeClient{Client: client},
+		watchingNamespaces: new(sync.Map),
+		selector:           selector,
+		onResync: func(ns string) {
+			resynced = append(resynced, ns)
+		},
+	}
+	ctl := &namespaceController{controller: provider}
+	ctx := context.Background()
+	assert.Nil(t, provider.initWatchingNamespacesByLabels(ctx))
+	assert.Equal(t, map[string]string{"prod": SelectedByLabel}, provider.NamespaceSelections())
+
+	updateLabels := func(name string, labels map[string]string) {
+		ns, err := client.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		ns.Labels = labels
+		_, err = client.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+		assert.Nil(t, err)
+		assert.Nil(t, ctl.sync(ctx, &types.Event{Type: types.EventUpdate, Object: name}))
+	}
+
+	// The resources in dev are resynced once it starts matching.
+	updateLabels("dev", map[string]string{"env": "staging"})
+	assert.True(t, provider.IsWatchingNamespace("dev/route"))
+	assert.Equal(t, []string{"dev"}, resynced)
+
+	// Unrelated label changes don't trigger resyncs.
+	updateLabels("dev", map[string]string{"env": "staging", "team": "a"})
+	assert.Equal(t, []string{"dev"}, resynced)
+
+	updateLabels("prod", map[string]string{"env": "prod", "legacy": "true"})
+	assert.False(t, provider.IsWatchingNamespace("prod/route"))
+	assert.Equal(t, []string{"dev"}, resynced)
+}
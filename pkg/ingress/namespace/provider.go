@@ -34,33 +34,55 @@ import (
 	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
-	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+const (
+	// SelectedExplicitly means the namespace is specified in app_namespaces.
+	SelectedExplicitly = "explicit"
+	// SelectedByLabel means the namespace matches the namespace_selector.
+	SelectedByLabel = "label"
+	// SelectedAll means the namespace is watched since there is no filter.
+	SelectedAll = "all"
+
+	// ReconcilePausedAnnotation is the Namespace annotation which pauses
+	// the reconciliation of the resources in the namespace when it's
+	// "true", the APISIX objects derived from them are left untouched.
+	ReconcilePausedAnnotation = "apisix.apache.org/reconcile-paused"
 )
 
 type WatchingProvider interface {
 	Run(ctx context.Context)
 	IsWatchingNamespace(key string) bool
+	// IsPausedNamespace checks whether the namespace of the resource would
+	// be watched but its reconciliation is paused.
+	IsPausedNamespace(key string) bool
 	WatchingNamespaces() []string
+	// NamespaceSelections returns the watching namespaces and how each
+	// one is selected (SelectedExplicitly, SelectedByLabel or SelectedAll).
+	NamespaceSelections() map[string]string
 }
 
-func NewWatchingProvider(ctx context.Context, kube *kube.KubeClient, cfg *config.Config) (WatchingProvider, error) {
-	var (
-		watchingNamespaces = new(sync.Map)
-		watchingLabels     = make(map[string]string)
-	)
+// NewWatchingProvider creates a WatchingProvider, onResync is called with the
+// namespace name once the resources in it should be reconciled again, i.e.
+// the reconciliation of a paused namespace is resumed (or the paused namespace
+// is deleted), or the namespace starts matching the namespace selector.
+func NewWatchingProvider(ctx context.Context, kube *kube.KubeClient, cfg *config.Config, onResync func(string)) (WatchingProvider, error) {
+	watchingNamespaces := new(sync.Map)
 	if len(cfg.Kubernetes.AppNamespaces) > 1 || cfg.Kubernetes.AppNamespaces[0] != v1.NamespaceAll {
 		for _, ns := range cfg.Kubernetes.AppNamespaces {
-			watchingNamespaces.Store(ns, struct{}{})
+			watchingNamespaces.Store(ns, SelectedExplicitly)
 		}
 	}
-	// support namespace label-selector
-	for _, selector := range cfg.Kubernetes.NamespaceSelector {
-		labelSlice := strings.Split(selector, "=")
-		watchingLabels[labelSlice[0]] = labelSlice[1]
+	// The selectors are ANDed, both the equality-based and the set-based
+	// requirements are supported.
+	selector, err := labels.Parse(strings.Join(cfg.Kubernetes.NamespaceSelector, ","))
+	if err != nil {
+		return nil, err
 	}
 
-	// watchingNamespaces and watchingLabels are empty means to monitor all namespaces.
-	if !validation.HasValueInSyncMap(watchingNamespaces) && len(watchingLabels) == 0 {
+	// watchingNamespaces and selector are empty means to monitor all namespaces.
+	watchAll := !validation.HasValueInSyncMap(watchingNamespaces) && selector.Empty()
+	if watchAll {
 		opts := metav1.ListOptions{}
 		// list all namespaces
 		nsList, err := kube.Client.CoreV1().Namespaces().List(ctx, opts)
@@ -70,7 +92,7 @@ func NewWatchingProvider(ctx context.Context, kube *kube.KubeClient, cfg *config
 		} else {
 			wns := new(sync.Map)
 			for _, v := range nsList.Items {
-				wns.Store(v.Name, struct{}{})
+				wns.Store(v.Name, SelectedAll)
 			}
 			watchingNamespaces = wns
 		}
@@ -81,7 +103,9 @@ func NewWatchingProvider(ctx context.Context, kube *kube.KubeClient, cfg *config
 		cfg:  cfg,
 
 		watchingNamespaces: watchingNamespaces,
-		watchingLabels:     watchingLabels,
+		selector:           selector,
+		watchAll:           watchAll,
+		onResync:           onResync,
 	}
 
 	kubeFactory := kube.NewSharedIndexInformerFactory()
@@ -90,10 +114,13 @@ func NewWatchingProvider(ctx context.Context, kube *kube.KubeClient, cfg *config
 
 	c.controller = newNamespaceController(c)
 
-	err := c.initWatchingNamespacesByLabels(ctx)
+	err = c.initWatchingNamespacesByLabels(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if err := c.initPausedNamespaces(ctx); err != nil {
+		return nil, err
+	}
 	return c, nil
 }
 
@@ -102,7 +129,16 @@ type watchingProvider struct {
 	cfg  *config.Config
 
 	watchingNamespaces *sync.Map
-	watchingLabels     types.Labels
+	// selector selects the namespaces to watch by labels, nil means none
+	// is selected by labels.
+	selector labels.Selector
+	// watchAll is true if neither app_namespaces nor namespace_selector
+	// is specified.
+	watchAll bool
+	// pausedNamespaces are the namespaces whose reconciliation is paused
+	// by the ReconcilePausedAnnotation.
+	pausedNamespaces sync.Map
+	onResync         func(string)
 
 	namespaceInformer cache.SharedIndexInformer
 	namespaceLister   listerscorev1.NamespaceLister
@@ -111,9 +147,11 @@ type watchingProvider struct {
 }
 
 func (c *watchingProvider) initWatchingNamespacesByLabels(ctx context.Context) error {
-	labelSelector := metav1.LabelSelector{MatchLabels: c.watchingLabels}
+	if c.selector == nil || c.selector.Empty() {
+		return nil
+	}
 	opts := metav1.ListOptions{
-		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
+		LabelSelector: c.selector.String(),
 	}
 	namespaces, err := c.kube.Client.CoreV1().Namespaces().List(ctx, opts)
 	if err != nil {
@@ -123,12 +161,79 @@ func (c *watchingProvider) initWatchingNamespacesByLabels(ctx context.Context) e
 
 	for _, ns := range namespaces.Items {
 		nss = append(nss, ns.Name)
-		c.watchingNamespaces.Store(ns.Name, struct{}{})
+		c.selectNamespace(ns.Name)
 	}
 	log.Infow("label selector watching namespaces", zap.Strings("namespaces", nss))
 	return nil
 }
 
+// initPausedNamespaces finds out the namespaces whose reconciliation is
+// paused, so that they are skipped before the namespace informer syncs.
+func (c *watchingProvider) initPausedNamespaces(ctx context.Context) error {
+	namespaces, err := c.kube.Client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for i := range namespaces.Items {
+		c.updatePaused(&namespaces.Items[i])
+	}
+	return nil
+}
+
+// updatePaused records whether the reconciliation of the namespace is
+// paused, and returns true if it's resumed.
+func (c *watchingProvider) updatePaused(ns *v1.Namespace) bool {
+	if ns.Annotations[ReconcilePausedAnnotation] == "true" {
+		if _, loaded := c.pausedNamespaces.LoadOrStore(ns.Name, struct{}{}); !loaded {
+			log.Infow("reconciliation of namespace paused", zap.String("namespace", ns.Name))
+		}
+		return false
+	}
+	if _, loaded := c.pausedNamespaces.LoadAndDelete(ns.Name); loaded {
+		log.Infow("reconciliation of namespace resumed", zap.String("namespace", ns.Name))
+		return true
+	}
+	return false
+}
+
+// matchNamespace checks whether the namespace should be watched according
+// to its labels.
+func (c *watchingProvider) matchNamespace(ns *v1.Namespace) bool {
+	if c.selector != nil && !c.selector.Empty() {
+		return c.selector.Matches(labels.Set(ns.Labels))
+	}
+	return c.watchAll
+}
+
+// selectNamespace watches the namespace which matches the label selector,
+// the explicitly specified namespaces are kept as is. It returns true if
+// the namespace was not watched before.
+func (c *watchingProvider) selectNamespace(name string) bool {
+	selection := SelectedByLabel
+	if c.selector == nil || c.selector.Empty() {
+		selection = SelectedAll
+	}
+	if v, ok := c.watchingNamespaces.Load(name); ok {
+		if v == SelectedExplicitly {
+			return false
+		}
+		c.watchingNamespaces.Store(name, selection)
+		return false
+	}
+	c.watchingNamespaces.Store(name, selection)
+	log.Infow("namespace is watched", zap.String("namespace", name), zap.String("selected_by", selection))
+	return true
+}
+
+// unselectNamespace stops watching the namespace which no longer matches
+// the label selector.
+func (c *watchingProvider) unselectNamespace(name string) {
+	if v, ok := c.watchingNamespaces.Load(name); ok && v == SelectedByLabel {
+		c.watchingNamespaces.Delete(name)
+		log.Infow("namespace is no longer watched", zap.String("namespace", name))
+	}
+}
+
 func (c *watchingProvider) Run(ctx context.Context) {
 	e := utils.ParallelExecutor{}
 
@@ -152,9 +257,41 @@ func (c *watchingProvider) WatchingNamespaces() []string {
 	return keys
 }
 
+func (c *watchingProvider) NamespaceSelections() map[string]string {
+	selections := make(map[string]string)
+	c.watchingNamespaces.Range(func(key, value interface{}) bool {
+		selections[key.(string)] = value.(string)
+		return true
+	})
+	return selections
+}
+
 // IsWatchingNamespace accepts a resource key, getting the namespace part
 // and checking whether the namespace is being watched.
 func (c *watchingProvider) IsWatchingNamespace(key string) (ok bool) {
+	// The resources in the paused namespaces are not reconciled.
+	if ns, _, err := cache.SplitMetaNamespaceKey(key); err == nil {
+		if _, paused := c.pausedNamespaces.Load(ns); paused {
+			return false
+		}
+	}
+	return c.isSelected(key)
+}
+
+func (c *watchingProvider) IsPausedNamespace(key string) bool {
+	ns, _, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return false
+	}
+	if _, paused := c.pausedNamespaces.Load(ns); !paused {
+		return false
+	}
+	return c.isSelected(key)
+}
+
+// isSelected checks whether the namespace of the resource is selected by
+// app_namespaces or namespace_selector, no matter it's paused or not.
+func (c *watchingProvider) isSelected(key string) (ok bool) {
 	if !validation.HasValueInSyncMap(c.watchingNamespaces) {
 		ok = true
 		return
@@ -40,6 +40,18 @@ func (c *mockWatchingProvider) WatchingNamespaces() []string {
 	return c.namespaces
 }
 
+func (c *mockWatchingProvider) NamespaceSelections() map[string]string {
+	selections := make(map[string]string)
+	for _, ns := range c.namespaces {
+		selections[ns] = SelectedExplicitly
+	}
+	return selections
+}
+
+func (c *mockWatchingProvider) IsPausedNamespace(key string) bool {
+	return false
+}
+
 func (c *mockWatchingProvider) IsWatchingNamespace(key string) (ok bool) {
 	ns, _, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
@@ -16,6 +16,7 @@ package ingress
 
 import (
 	"context"
+	"reflect"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
@@ -114,6 +115,10 @@ func (c *podController) onUpdate(oldObj, newObj interface{}) {
 			)
 		}
 	}
+	if !reflect.DeepEqual(prev.Labels, curr.Labels) && c.controller.apisixUpstreamController != nil {
+		// The pod might join or leave the ApisixUpstream subsets.
+		c.controller.apisixUpstreamController.resyncSubsets(curr.Namespace)
+	}
 
 	c.controller.MetricsCollector.IncrEvents("pod", "update")
 }
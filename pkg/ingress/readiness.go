@@ -0,0 +1,206 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+// _initialSyncKinds are the kinds of resources which should be synced to
+// APISIX before the controller reports ready.
+var _initialSyncKinds = []string{
+	"ApisixRoute",
+	"ApisixUpstream",
+	"ApisixTls",
+	"ApisixConsumer",
+	"ApisixPluginConfig",
+	"ApisixClusterConfig",
+	"Ingress",
+}
+
+// _kindControllers are the controllers of the kinds in _initialSyncKinds.
+var _kindControllers = map[string]string{
+	"ApisixRoute":         config.ControllerApisixRoute,
+	"ApisixUpstream":      config.ControllerApisixUpstream,
+	"ApisixTls":           config.ControllerApisixTls,
+	"ApisixConsumer":      config.ControllerApisixConsumer,
+	"ApisixPluginConfig":  config.ControllerApisixPluginConfig,
+	"ApisixClusterConfig": config.ControllerApisixClusterConfig,
+	"Ingress":             config.ControllerIngress,
+}
+
+// enabledInitialSyncKinds returns the kinds in _initialSyncKinds whose
+// controllers are enabled, the others are never synced.
+func enabledInitialSyncKinds(cfg *config.KubernetesConfig) []string {
+	var kinds []string
+	for _, kind := range _initialSyncKinds {
+		if cfg.IsControllerEnabled(_kindControllers[kind]) {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// initialSyncTracker tracks whether the resources existing when the controllers
+// start have all been synced to APISIX successfully.
+type initialSyncTracker struct {
+	sync.Mutex
+	// pending contains the keys of resources not synced yet, a kind is absent
+	// until its controller lists the existing resources.
+	pending map[string]map[string]struct{}
+	kinds   []string
+	ready   bool
+	onReady func()
+	// synced is closed once the tracker is ready.
+	synced chan struct{}
+}
+
+func newInitialSyncTracker(kinds []string, onReady func()) *initialSyncTracker {
+	return &initialSyncTracker{
+		pending: make(map[string]map[string]struct{}),
+		kinds:   kinds,
+		onReady: onReady,
+		synced:  make(chan struct{}),
+	}
+}
+
+// expect records the keys of resources which should be synced for the kind,
+// it should be called once the informer cache of the kind is synced.
+func (t *initialSyncTracker) expect(kind string, keys []string) {
+	t.Lock()
+	defer t.Unlock()
+	if _, ok := t.pending[kind]; ok {
+		return
+	}
+	pending := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		pending[key] = struct{}{}
+	}
+	t.pending[kind] = pending
+	t.check()
+}
+
+// observe records the sync result of the event.
+func (t *initialSyncTracker) observe(kind string, ev *types.Event, err error) {
+	// The resource might be deleted before it's synced.
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return
+	}
+	key := eventKey(ev)
+	t.Lock()
+	defer t.Unlock()
+	if pending, ok := t.pending[kind]; ok {
+		delete(pending, key)
+	}
+	t.check()
+}
+
+// forceReady marks the tracker ready whatever the sync progress is.
+func (t *initialSyncTracker) forceReady(reason string) {
+	t.Lock()
+	defer t.Unlock()
+	if t.ready {
+		return
+	}
+	log.Warnw("force the controller ready before the initial sync is done",
+		zap.String("reason", reason),
+		zap.Strings("pending_kinds", t.pendingKinds()),
+	)
+	t.markReady()
+}
+
+func (t *initialSyncTracker) isReady() bool {
+	t.Lock()
+	defer t.Unlock()
+	return t.ready
+}
+
+// done returns a channel which is closed once the tracker is ready.
+func (t *initialSyncTracker) done() <-chan struct{} {
+	return t.synced
+}
+
+func (t *initialSyncTracker) check() {
+	if t.ready || len(t.pendingKinds()) > 0 {
+		return
+	}
+	log.Info("initial sync is done, the controller is ready")
+	t.markReady()
+}
+
+func (t *initialSyncTracker) markReady() {
+	t.ready = true
+	close(t.synced)
+	if t.onReady != nil {
+		t.onReady()
+	}
+}
+
+func (t *initialSyncTracker) pendingKinds() []string {
+	var kinds []string
+	for _, kind := range t.kinds {
+		if pending, ok := t.pending[kind]; !ok || len(pending) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// eventKey returns the resource key of the event.
+func eventKey(ev *types.Event) string {
+	switch obj := ev.Object.(type) {
+	case string:
+		return obj
+	case kube.ApisixRouteEvent:
+		return obj.Key
+	case kube.ApisixTlsEvent:
+		return obj.Key
+	case kube.ApisixConsumerEvent:
+		return obj.Key
+	case kube.ApisixPluginConfigEvent:
+		return obj.Key
+	case kube.ApisixClusterConfigEvent:
+		return obj.Key
+	case kube.IngressEvent:
+		return obj.Key
+	default:
+		return ""
+	}
+}
+
+// watchingKeys returns the keys of objects in the informer which are in the
+// watching namespaces.
+func (c *Controller) watchingKeys(informer cache.SharedIndexInformer) []string {
+	var keys []string
+	for _, obj := range informer.GetIndexer().List() {
+		key, err := cache.MetaNamespaceKeyFunc(obj)
+		if err != nil {
+			continue
+		}
+		if c.isWatchingNamespace(key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,94 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+)
+
+func TestInitialSyncTracker(t *testing.T) {
+	notified := 0
+	tracker := newInitialSyncTracker([]string{"ApisixRoute", "ApisixUpstream"}, func() {
+		notified++
+	})
+	routeEvent := func(key string) *types.Event {
+		return &types.Event{
+			Object: kube.ApisixRouteEvent{Key: key, GroupVersion: kube.ApisixRouteV2},
+		}
+	}
+
+	tracker.expect("ApisixRoute", []string{"default/foo", "default/bar"})
+	assert.False(t, tracker.isReady())
+	assert.Equal(t, []string{"ApisixRoute", "ApisixUpstream"}, tracker.pendingKinds())
+
+	// Failed syncs don't count.
+	tracker.observe("ApisixRoute", routeEvent("default/foo"), errors.New("timeout"))
+	tracker.observe("ApisixRoute", routeEvent("default/foo"), nil)
+	tracker.observe("ApisixRoute", routeEvent("default/bar"),
+		k8serrors.NewNotFound(schema.GroupResource{Resource: "apisixroutes"}, "bar"))
+	assert.Equal(t, []string{"ApisixUpstream"}, tracker.pendingKinds())
+	assert.False(t, tracker.isReady())
+
+	tracker.expect("ApisixUpstream", []string{"default/baz"})
+	assert.False(t, tracker.isReady())
+	tracker.observe("ApisixUpstream", &types.Event{Object: "default/baz"}, nil)
+	assert.True(t, tracker.isReady())
+	assert.Equal(t, 1, notified)
+	select {
+	case <-tracker.done():
+	default:
+		t.Fatal("done channel is not closed once ready")
+	}
+
+	// Once ready, it's always ready.
+	tracker.forceReady("test")
+	assert.True(t, tracker.isReady())
+	assert.Equal(t, 1, notified)
+}
+
+func TestInitialSyncTrackerForceReady(t *testing.T) {
+	notified := 0
+	tracker := newInitialSyncTracker([]string{"ApisixRoute"}, func() {
+		notified++
+	})
+	tracker.expect("ApisixRoute", []string{"default/foo"})
+	assert.False(t, tracker.isReady())
+
+	tracker.forceReady("test")
+	assert.True(t, tracker.isReady())
+	assert.Equal(t, 1, notified)
+
+	// No resources to sync.
+	tracker = newInitialSyncTracker([]string{"ApisixRoute"}, nil)
+	tracker.expect("ApisixRoute", nil)
+	assert.True(t, tracker.isReady())
+}
+
+func TestEnabledInitialSyncKinds(t *testing.T) {
+	kc := &config.KubernetesConfig{}
+	assert.Equal(t, _initialSyncKinds, enabledInitialSyncKinds(kc))
+
+	kc.EnabledControllers = []string{config.ControllerApisixRoute, config.ControllerApisixUpstream}
+	assert.Equal(t, []string{"ApisixRoute", "ApisixUpstream"}, enabledInitialSyncKinds(kc))
+}
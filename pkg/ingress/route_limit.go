@@ -0,0 +1,115 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+var (
+	errRouteLimitExceeded = errors.New("route count limit exceeded")
+)
+
+// checkRouteLimit checks whether the number of routes in the cluster will
+// exceed the limit after applying the manifests. Routes in updated are also
+// counted since they might be rejected before and still absent. Syncs which
+// don't increase the number of routes are always allowed.
+func checkRouteLimit(existing map[string]struct{}, added, updated, deleted *utils.Manifest, limit int) error {
+	routes := make(map[string]struct{}, len(existing))
+	for id := range existing {
+		routes[id] = struct{}{}
+	}
+	if deleted != nil {
+		for _, r := range deleted.Routes {
+			delete(routes, r.ID)
+		}
+	}
+	for _, m := range []*utils.Manifest{added, updated} {
+		if m == nil {
+			continue
+		}
+		for _, r := range m.Routes {
+			routes[r.ID] = struct{}{}
+		}
+	}
+	if len(routes) > limit && len(routes) > len(existing) {
+		return fmt.Errorf("%w: %d routes would be in the cluster, the limit is %d",
+			errRouteLimitExceeded, len(routes), limit)
+	}
+	return nil
+}
+
+// moveAbsentRoutes moves the updated routes which are absent from the cluster
+// to the added ones, since they are created by the update, e.g. the routes
+// rejected by the limit before.
+func moveAbsentRoutes(existing map[string]struct{}, added, updated *utils.Manifest) (*utils.Manifest, *utils.Manifest) {
+	if updated == nil {
+		return added, updated
+	}
+	var present, absent []*apisixv1.Route
+	for _, r := range updated.Routes {
+		if _, ok := existing[r.ID]; ok {
+			present = append(present, r)
+		} else {
+			absent = append(absent, r)
+		}
+	}
+	if len(absent) == 0 {
+		return added, updated
+	}
+	newAdded := &utils.Manifest{}
+	if added != nil {
+		*newAdded = *added
+	}
+	newAdded.Routes = append(append([]*apisixv1.Route{}, newAdded.Routes...), absent...)
+	newUpdated := *updated
+	newUpdated.Routes = present
+	return newAdded, &newUpdated
+}
+
+// routeLimitGuard returns the guard which checks the route count limit of the
+// cluster before the routes are created. It holds the lock of the cluster until
+// the routes are written, so that the concurrent syncs of the cluster cannot
+// exceed the limit, while the syncs of other clusters are not blocked.
+func (c *Controller) routeLimitGuard(clusterName string, limit int) utils.RouteGuard {
+	return func(added, deleted []*apisixv1.Route) (func(), error) {
+		v, _ := c.routeLimitLocks.LoadOrStore(clusterName, &sync.Mutex{})
+		lock := v.(*sync.Mutex)
+		lock.Lock()
+
+		existing, err := c.apisix.Cluster(clusterName).RouteIDs()
+		if err != nil {
+			lock.Unlock()
+			return nil, err
+		}
+		// The routes deleted by the sync are gone by now, count them in so
+		// that replacing routes is still allowed when the limit is reached.
+		for _, r := range deleted {
+			existing[r.ID] = struct{}{}
+		}
+		err = checkRouteLimit(existing, &utils.Manifest{Routes: added}, nil, &utils.Manifest{Routes: deleted}, limit)
+		if err != nil {
+			lock.Unlock()
+			c.MetricsCollector.IncrRouteLimitRejection()
+			return nil, err
+		}
+		return lock.Unlock, nil
+	}
+}
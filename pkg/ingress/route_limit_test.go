@@ -0,0 +1,128 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func routeOf(namespace, name string) *apisixv1.Route {
+	r := apisixv1.NewDefaultRoute()
+	r.Name = apisixv1.ComposeRouteName(namespace, name, "rule1")
+	r.ID = id.GenID(id.Route, r.Name)
+	return r
+}
+
+func TestCheckRouteLimit(t *testing.T) {
+	limit := 3
+	existing := make(map[string]struct{})
+
+	// Routes from different namespaces share the same limit.
+	for _, ns := range []string{"ns1", "ns2", "ns3"} {
+		added := &utils.Manifest{Routes: []*apisixv1.Route{routeOf(ns, "httpbin")}}
+		assert.Nil(t, checkRouteLimit(existing, added, nil, nil, limit))
+		existing[added.Routes[0].ID] = struct{}{}
+	}
+
+	// The N+1th route is rejected.
+	added := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns4", "httpbin")}}
+	err := checkRouteLimit(existing, added, nil, nil, limit)
+	assert.True(t, errors.Is(err, errRouteLimitExceeded))
+
+	// Also for a route which was rejected before and comes back as updated.
+	err = checkRouteLimit(existing, nil, added, nil, limit)
+	assert.True(t, errors.Is(err, errRouteLimitExceeded))
+
+	// Updating the existing routes is allowed.
+	updated := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns1", "httpbin")}}
+	assert.Nil(t, checkRouteLimit(existing, nil, updated, nil, limit))
+
+	// Replacing a route is allowed.
+	deleted := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns1", "httpbin")}}
+	assert.Nil(t, checkRouteLimit(existing, added, nil, deleted, limit))
+}
+
+func TestSyncManifestsRouteLimitPerCluster(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cli, err := apisix.NewClient()
+	assert.Nil(t, err)
+	for _, name := range []string{"default", "cluster2"} {
+		srv := httptest.NewServer(&fakeAdminAPI{objects: make(map[string]json.RawMessage)})
+		defer srv.Close()
+		assert.Nil(t, cli.AddCluster(ctx, &apisix.ClusterOptions{
+			Name:             name,
+			BaseURL:          srv.URL + "/apisix/admin",
+			MetricsCollector: metrics.NewPrometheusCollector(),
+		}))
+		assert.Nil(t, cli.Cluster(name).HasSynced(ctx))
+	}
+
+	cfg := config.NewDefaultConfig()
+	cfg.APISIX.MaxRouteCount = 2
+	c := &Controller{
+		cfg:              cfg,
+		apisix:           cli,
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	}
+
+	// The limit applies to each cluster separately.
+	for _, cluster := range []string{"default", "cluster2"} {
+		for _, ns := range []string{"ns1", "ns2"} {
+			added := &utils.Manifest{Routes: []*apisixv1.Route{routeOf(ns, "httpbin")}}
+			assert.Nil(t, c.syncManifestsToCluster(ctx, cluster, added, nil, nil))
+		}
+		added := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns3", "httpbin")}}
+		err := c.syncManifestsToCluster(ctx, cluster, added, nil, nil)
+		assert.True(t, errors.Is(err, errRouteLimitExceeded))
+
+		ids, err := cli.Cluster(cluster).RouteIDs()
+		assert.Nil(t, err)
+		assert.Len(t, ids, 2)
+	}
+
+	// A rejected route coming back as updated is still counted.
+	updated := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns3", "httpbin")}}
+	err = c.syncManifestsToCluster(ctx, "default", nil, updated, nil)
+	assert.True(t, errors.Is(err, errRouteLimitExceeded))
+
+	// Replacing a route is allowed once the limit is reached.
+	deleted := &utils.Manifest{Routes: []*apisixv1.Route{routeOf("ns1", "httpbin")}}
+	assert.Nil(t, c.syncManifestsToCluster(ctx, "default", nil, updated, deleted))
+	ids, err := cli.Cluster("default").RouteIDs()
+	assert.Nil(t, err)
+	assert.Len(t, ids, 2)
+	assert.Contains(t, ids, updated.Routes[0].ID)
+
+	// The route creations in a cluster don't wait for other clusters.
+	v, _ := c.routeLimitLocks.LoadOrStore("default", &sync.Mutex{})
+	v.(*sync.Mutex).Lock()
+	defer v.(*sync.Mutex).Unlock()
+	assert.Nil(t, c.syncManifestsToCluster(ctx, "cluster2", nil, updated, deleted))
+}
@@ -39,14 +39,12 @@ import (
 type secretController struct {
 	controller *Controller
 	workqueue  workqueue.RateLimitingInterface
-	workers    int
 }
 
 func (c *Controller) newSecretController() *secretController {
 	ctl := &secretController{
 		controller: c,
 		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "Secrets"),
-		workers:    1,
 	}
 
 	ctl.controller.secretInformer.AddEventHandler(
@@ -70,20 +68,20 @@ func (c *secretController) run(ctx context.Context) {
 		return
 	}
 
-	for i := 0; i < c.workers; i++ {
-		go c.runWorker(ctx)
-	}
-
-	<-ctx.Done()
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
 }
 
-func (c *secretController) runWorker(ctx context.Context) {
+func (c *secretController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("Secret")
+	defer hb.unregister()
 	for {
-		obj, quit := c.workqueue.Get()
+		obj, quit := nextEvent(c.workqueue, stop)
 		if quit {
 			return
 		}
+		hb.busy()
 		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
 		c.workqueue.Done(obj)
 		c.handleSyncErr(obj, err)
 	}
@@ -209,7 +207,10 @@ func (c *secretController) syncV2Beta3Handler(ctx context.Context, ev *types.Eve
 		// Use another goroutine to send requests, to avoid
 		// long time lock occupying.
 		go func(ssl *apisixv1.Ssl, tls *configv2beta3.ApisixTls) {
-			err := c.controller.syncSSL(ctx, ssl, ev.Type)
+			cluster, err := c.controller.resourceCluster(tls)
+			if err == nil {
+				err = c.controller.syncSSL(ctx, cluster, ssl, ev.Type)
+			}
 			if err != nil {
 				log.Errorw("failed to sync ssl to APISIX",
 					zap.Error(err),
@@ -266,6 +267,23 @@ func (c *secretController) syncV2Handler(ctx context.Context, ev *types.Event, s
 			// sync ssl
 			ssl.Cert = string(cert)
 			ssl.Key = string(pkey)
+			if tls.Spec.AutoSNI {
+				// The SANs change with the certificate.
+				newSSL, err := c.controller.translateApisixTlsV2(tlsMetaKey, tls)
+				if err != nil {
+					log.Errorw("failed to translate ApisixTls",
+						zap.String("ApisixTls", tlsMetaKey),
+						zap.Error(err),
+					)
+					go func(tls *configv2.ApisixTls) {
+						c.controller.recorderEventS(tls, corev1.EventTypeWarning, _resourceSyncAborted,
+							fmt.Sprintf("sync from secret %s changes failed, error: %s", secretKey, err.Error()))
+						c.controller.recordStatus(tls, _resourceSyncAborted, err, metav1.ConditionFalse, tls.GetGeneration())
+					}(tls)
+					return true
+				}
+				ssl.Snis = newSSL.Snis
+			}
 		} else if tls.Spec.Client != nil &&
 			tls.Spec.Client.CASecret.Namespace == secret.Namespace && tls.Spec.Client.CASecret.Name == secret.Name {
 			ca, _, err := c.controller.translator.ExtractKeyPair(secret, false)
@@ -294,7 +312,10 @@ func (c *secretController) syncV2Handler(ctx context.Context, ev *types.Event, s
 		// Use another goroutine to send requests, to avoid
 		// long time lock occupying.
 		go func(ssl *apisixv1.Ssl, tls *configv2.ApisixTls) {
-			err := c.controller.syncSSL(ctx, ssl, ev.Type)
+			cluster, err := c.controller.resourceCluster(tls)
+			if err == nil {
+				err = c.controller.syncSSL(ctx, cluster, ssl, ev.Type)
+			}
 			if err != nil {
 				log.Errorw("failed to sync ssl to APISIX",
 					zap.Error(err),
@@ -0,0 +1,212 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingress
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// serviceController watches the honored upstream annotations of Services
+// and syncs the upstreams of the Services without ApisixUpstream.
+type serviceController struct {
+	controller *Controller
+	workqueue  workqueue.RateLimitingInterface
+}
+
+func (c *Controller) newServiceController() *serviceController {
+	ctl := &serviceController{
+		controller: c,
+		workqueue:  workqueue.NewNamedRateLimitingQueue(workqueue.NewItemFastSlowRateLimiter(1*time.Second, 60*time.Second, 5), "Service"),
+	}
+
+	// Upstreams are created and removed along with the routes, so only
+	// the annotation changes are concerned.
+	ctl.controller.svcInformer.AddEventHandler(
+		cache.ResourceEventHandlerFuncs{
+			UpdateFunc: ctl.onUpdate,
+		},
+	)
+
+	return ctl
+}
+
+func (c *serviceController) run(ctx context.Context) {
+	log.Info("service controller started")
+	defer log.Info("service controller exited")
+	defer c.workqueue.ShutDown()
+
+	if ok := cache.WaitForCacheSync(ctx.Done(), c.controller.svcInformer.HasSynced); !ok {
+		log.Error("informers sync failed")
+		return
+	}
+
+	c.controller.runWorkers(ctx, c.workqueue, c.runWorker)
+}
+
+func (c *serviceController) runWorker(ctx context.Context, stop <-chan struct{}) {
+	hb := c.controller.workerHeartbeats.register("Service")
+	defer hb.unregister()
+	for {
+		obj, quit := nextEvent(c.workqueue, stop)
+		if quit {
+			return
+		}
+		hb.busy()
+		err := c.sync(ctx, obj.(*types.Event))
+		hb.idle()
+		c.workqueue.Done(obj)
+		c.handleSyncErr(obj, err)
+	}
+}
+
+func (c *serviceController) sync(ctx context.Context, ev *types.Event) error {
+	key := ev.Object.(string)
+	namespace, name, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		log.Errorf("invalid resource key: %s", key)
+		return err
+	}
+	svc, err := c.controller.svcLister.Services(namespace).Get(name)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			log.Errorw("failed to get Service",
+				zap.String("key", key),
+				zap.Error(err),
+			)
+			return err
+		}
+		log.Warnw("Service was deleted before it can be delivered",
+			zap.String("key", key),
+		)
+		return nil
+	}
+
+	// ApisixUpstream takes precedence over the Service annotations.
+	_, err = c.controller.apisixUpstreamLister.ApisixUpstreams(namespace).Get(name)
+	if err == nil {
+		log.Debugw("Service has ApisixUpstream, ignore its upstream annotations",
+			zap.String("key", key),
+		)
+		return nil
+	}
+	if !k8serrors.IsNotFound(err) {
+		log.Errorw("failed to get ApisixUpstream",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	cfg, err := c.controller.translator.TranslateServiceUpstreamConfig(svc)
+	if err != nil {
+		log.Errorw("found malformed Service upstream annotations",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+		c.controller.recorderEvent(svc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+		// Don't need to retry.
+		return nil
+	}
+
+	for _, clusterName := range c.controller.clusterNames() {
+		for _, port := range svc.Spec.Ports {
+			upsName := apisixv1.ComposeUpstreamName(namespace, name, "", port.Port)
+			upstreams, err := c.controller.apisix.Cluster(clusterName).Upstream().ListByName(ctx, upsName)
+			if err != nil {
+				log.Errorf("failed to list upstreams %s: %s", upsName, err)
+				return err
+			}
+			for _, ups := range upstreams {
+				newUps := cfg.DeepCopy()
+				newUps.Metadata = ups.Metadata
+				newUps.Nodes = ups.Nodes
+				newUps.DiscoveryType = ups.DiscoveryType
+				newUps.ServiceName = ups.ServiceName
+				log.Debugw("updating upstream since Service upstream annotations changed",
+					zap.Any("upstream", newUps),
+					zap.String("service", key),
+				)
+				if err := c.controller.updateUpstream(ctx, clusterName, ups, newUps); err != nil {
+					log.Errorw("failed to update upstream",
+						zap.Error(err),
+						zap.Any("upstream", newUps),
+						zap.String("service", key),
+						zap.String("cluster", clusterName),
+					)
+					c.controller.recorderEvent(svc, corev1.EventTypeWarning, _resourceSyncAborted, err)
+					return err
+				}
+			}
+		}
+	}
+	c.controller.recorderEvent(svc, corev1.EventTypeNormal, _resourceSynced, nil)
+	return nil
+}
+
+func (c *serviceController) handleSyncErr(obj interface{}, err error) {
+	if err == nil {
+		c.workqueue.Forget(obj)
+		c.controller.MetricsCollector.IncrSyncOperation("service", "success")
+		return
+	}
+	log.Warnw("sync service failed, will retry",
+		zap.Any("object", obj),
+		zap.Error(err),
+	)
+	c.workqueue.AddRateLimited(obj)
+	c.controller.MetricsCollector.IncrSyncOperation("service", "failure")
+}
+
+func (c *serviceController) onUpdate(prev, curr interface{}) {
+	prevSvc := prev.(*corev1.Service)
+	currSvc := curr.(*corev1.Service)
+
+	if prevSvc.GetResourceVersion() >= currSvc.GetResourceVersion() {
+		return
+	}
+	if !translation.ServiceUpstreamAnnotationsChanged(c.controller.cfg.Kubernetes.ServiceUpstreamAnnotations, prevSvc, currSvc) {
+		return
+	}
+	key, err := cache.MetaNamespaceKeyFunc(currSvc)
+	if err != nil {
+		log.Errorf("found Service object with bad namespace/name: %s, ignore it", err)
+		return
+	}
+	if !c.controller.isWatchingNamespace(key) {
+		return
+	}
+	log.Debugw("service upstream annotations changed",
+		zap.String("key", key),
+	)
+	c.workqueue.Add(&types.Event{
+		Type:   types.EventUpdate,
+		Object: key,
+	})
+
+	c.controller.MetricsCollector.IncrEvents("service", "update")
+}
@@ -17,7 +17,10 @@ package ingress
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/hashicorp/go-multierror"
 	"go.uber.org/zap"
 	apiv1 "k8s.io/api/core/v1"
 	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
@@ -35,16 +38,55 @@ import (
 )
 
 const (
-	_conditionType        = "ResourcesAvailable"
+	_conditionType = "ResourcesAvailable"
+	// _conditionTypeSynced reports whether the observed generation of the
+	// resource was synced to APISIX.
+	_conditionTypeSynced  = "Synced"
 	_commonSuccessMessage = "Sync Successfully"
 )
 
-// verifyGeneration verify generation to decide whether to update status
+// verifyGeneration verify generation to decide whether to update status, the
+// status of the same generation is updated only if the condition changes, e.g.
+// a failed sync succeeds after retrying.
 func (c *Controller) verifyGeneration(conditions *[]metav1.Condition, newCondition metav1.Condition) bool {
 	existingCondition := meta.FindStatusCondition(*conditions, newCondition.Type)
-	if existingCondition != nil && existingCondition.ObservedGeneration >= newCondition.ObservedGeneration {
+	if existingCondition == nil {
+		return true
+	}
+	if existingCondition.ObservedGeneration != newCondition.ObservedGeneration {
+		return existingCondition.ObservedGeneration < newCondition.ObservedGeneration
+	}
+	return existingCondition.Status != newCondition.Status ||
+		existingCondition.Reason != newCondition.Reason ||
+		existingCondition.Message != newCondition.Message
+}
+
+// conditionMessage returns the message of the condition for the sync error,
+// the errors aggregated in a multierror are joined in one line so the error
+// messages from APISIX are shown as is.
+func conditionMessage(err error) string {
+	var merr *multierror.Error
+	if errors.As(err, &merr) && len(merr.Errors) > 0 {
+		messages := make([]string, 0, len(merr.Errors))
+		for _, e := range merr.Errors {
+			messages = append(messages, e.Error())
+		}
+		return strings.Join(messages, "; ")
+	}
+	return err.Error()
+}
+
+// setRouteConditions sets the conditions of ApisixRoute, a Synced condition is
+// kept along with the ResourcesAvailable one. It reports whether the
+// conditions are changed.
+func (c *Controller) setRouteConditions(conditions *[]metav1.Condition, condition metav1.Condition) bool {
+	synced := condition
+	synced.Type = _conditionTypeSynced
+	if !c.verifyGeneration(conditions, condition) && !c.verifyGeneration(conditions, synced) {
 		return false
 	}
+	meta.SetStatusCondition(conditions, condition)
+	meta.SetStatusCondition(conditions, synced)
 	return true
 }
 
@@ -53,7 +95,7 @@ func (c *Controller) recordStatus(at interface{}, reason string, err error, stat
 	// build condition
 	message := _commonSuccessMessage
 	if err != nil {
-		message = err.Error()
+		message = conditionMessage(err)
 	}
 	condition := metav1.Condition{
 		Type:               _conditionType,
@@ -127,8 +169,7 @@ func (c *Controller) recordStatus(at interface{}, reason string, err error, stat
 			conditions := make([]metav1.Condition, 0)
 			v.Status.Conditions = conditions
 		}
-		if c.verifyGeneration(&v.Status.Conditions, condition) {
-			meta.SetStatusCondition(&v.Status.Conditions, condition)
+		if c.setRouteConditions(&v.Status.Conditions, condition) {
 			if _, errRecord := client.ApisixV2beta2().ApisixRoutes(v.Namespace).
 				UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
 				log.Errorw("failed to record status change for ApisixRoute",
@@ -144,8 +185,7 @@ func (c *Controller) recordStatus(at interface{}, reason string, err error, stat
 			conditions := make([]metav1.Condition, 0)
 			v.Status.Conditions = conditions
 		}
-		if c.verifyGeneration(&v.Status.Conditions, condition) {
-			meta.SetStatusCondition(&v.Status.Conditions, condition)
+		if c.setRouteConditions(&v.Status.Conditions, condition) {
 			if _, errRecord := client.ApisixV2beta3().ApisixRoutes(v.Namespace).
 				UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
 				log.Errorw("failed to record status change for ApisixRoute",
@@ -155,6 +195,22 @@ func (c *Controller) recordStatus(at interface{}, reason string, err error, stat
 				)
 			}
 		}
+	case *configv2.ApisixRoute:
+		// set to status
+		if v.Status.Conditions == nil {
+			conditions := make([]metav1.Condition, 0)
+			v.Status.Conditions = conditions
+		}
+		if c.setRouteConditions(&v.Status.Conditions, condition) {
+			if _, errRecord := client.ApisixV2().ApisixRoutes(v.Namespace).
+				UpdateStatus(context.TODO(), v, metav1.UpdateOptions{}); errRecord != nil {
+				log.Errorw("failed to record status change for ApisixRoute",
+					zap.Error(errRecord),
+					zap.String("name", v.Name),
+					zap.String("namespace", v.Namespace),
+				)
+			}
+		}
 	case *configv2beta3.ApisixConsumer:
 		// set to status
 		if v.Status.Conditions == nil {
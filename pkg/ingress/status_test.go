@@ -0,0 +1,88 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+)
+
+func TestSetRouteConditions(t *testing.T) {
+	c := &Controller{}
+	var conditions []metav1.Condition
+
+	failed := metav1.Condition{
+		Type:               _conditionType,
+		Reason:             _resourceSyncAborted,
+		Status:             metav1.ConditionFalse,
+		Message:            "service not found",
+		ObservedGeneration: 1,
+	}
+	assert.True(t, c.setRouteConditions(&conditions, failed))
+	synced := meta.FindStatusCondition(conditions, _conditionTypeSynced)
+	assert.NotNil(t, synced)
+	assert.Equal(t, metav1.ConditionFalse, synced.Status)
+	assert.Equal(t, "service not found", synced.Message)
+	assert.Equal(t, int64(1), synced.ObservedGeneration)
+
+	// Nothing changes when retrying.
+	assert.False(t, c.setRouteConditions(&conditions, failed))
+
+	// The same generation is synced after retrying.
+	succeeded := metav1.Condition{
+		Type:               _conditionType,
+		Reason:             _resourceSynced,
+		Status:             metav1.ConditionTrue,
+		Message:            _commonSuccessMessage,
+		ObservedGeneration: 1,
+	}
+	assert.True(t, c.setRouteConditions(&conditions, succeeded))
+	assert.True(t, meta.IsStatusConditionTrue(conditions, _conditionTypeSynced))
+	assert.True(t, meta.IsStatusConditionTrue(conditions, _conditionType))
+
+	// Stale generations are ignored.
+	failed.ObservedGeneration = 0
+	assert.False(t, c.setRouteConditions(&conditions, failed))
+	assert.True(t, meta.IsStatusConditionTrue(conditions, _conditionTypeSynced))
+
+	// The Synced condition is added to the routes synced by earlier versions.
+	conditions = []metav1.Condition{succeeded}
+	assert.True(t, c.setRouteConditions(&conditions, succeeded))
+	assert.True(t, meta.IsStatusConditionTrue(conditions, _conditionTypeSynced))
+}
+
+func TestConditionMessage(t *testing.T) {
+	assert.Equal(t, "service not found", conditionMessage(errors.New("service not found")))
+
+	var merr *multierror.Error
+	merr = multierror.Append(merr, fmt.Errorf("failed to create route %s: %w", "default_httpbin_rule1", &apisix.AdminAPIError{
+		StatusCode: 400,
+		Message:    `failed to check the configuration of plugin limit-count err: property "count" is required`,
+	}))
+	assert.Equal(t, `failed to create route default_httpbin_rule1: unexpected status code 400: failed to check the configuration of plugin limit-count err: property "count" is required`,
+		conditionMessage(merr))
+
+	merr = multierror.Append(merr, errors.New("timeout"))
+	assert.Equal(t, `failed to create route default_httpbin_rule1: unexpected status code 400: failed to check the configuration of plugin limit-count err: property "count" is required; timeout`,
+		conditionMessage(merr))
+}
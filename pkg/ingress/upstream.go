@@ -0,0 +1,70 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// updateUpstream applies the upstream ups, which is changed from the existing
+// upstream old, to the cluster. The upstream ID is generated from the config
+// (see apisixv1.ComposeUpstreamID), so the upstream is only updated in place if
+// the ID stays the same. Otherwise it's replaced, the upstream with the new ID
+// is created, the routes and stream routes are switched to it, and then the
+// old one is deleted.
+func (c *Controller) updateUpstream(ctx context.Context, clusterName string, old, ups *apisixv1.Upstream) error {
+	cluster := c.apisix.Cluster(clusterName)
+	ups.ID = apisixv1.ComposeUpstreamID(ups)
+	if ups.ID == old.ID {
+		_, err := cluster.Upstream().Update(ctx, ups)
+		return err
+	}
+
+	updated := &utils.Manifest{}
+	routes, err := cluster.Cache().ListRoutes()
+	if err != nil {
+		return err
+	}
+	for _, r := range routes {
+		if apisixv1.ReplaceRouteUpstreamID(r, old.ID, ups.ID) {
+			updated.Routes = append(updated.Routes, r)
+		}
+	}
+	streamRoutes, err := cluster.Cache().ListStreamRoutes()
+	if err != nil {
+		return err
+	}
+	for _, sr := range streamRoutes {
+		if sr.UpstreamId == old.ID {
+			sr.UpstreamId = ups.ID
+			updated.StreamRoutes = append(updated.StreamRoutes, sr)
+		}
+	}
+	log.Infow("replacing upstream since its config changed",
+		zap.String("name", ups.Name),
+		zap.String("old_id", old.ID),
+		zap.String("new_id", ups.ID),
+		zap.String("cluster", clusterName),
+	)
+	added := &utils.Manifest{Upstreams: []*apisixv1.Upstream{ups}}
+	deleted := &utils.Manifest{Upstreams: []*apisixv1.Upstream{old}}
+	return c.syncManifestsToCluster(ctx, clusterName, added, updated, deleted)
+}
@@ -0,0 +1,86 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	"github.com/apache/apisix-ingress-controller/pkg/metrics"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestUpdateUpstream(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	srv := httptest.NewServer(&fakeAdminAPI{objects: make(map[string]json.RawMessage)})
+	defer srv.Close()
+	cli, err := apisix.NewClient()
+	assert.Nil(t, err)
+	assert.Nil(t, cli.AddCluster(ctx, &apisix.ClusterOptions{
+		Name:             "default",
+		BaseURL:          srv.URL + "/apisix/admin",
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	}))
+	cluster := cli.Cluster("default")
+	assert.Nil(t, cluster.HasSynced(ctx))
+	c := &Controller{
+		cfg:              config.NewDefaultConfig(),
+		apisix:           cli,
+		MetricsCollector: metrics.NewPrometheusCollector(),
+	}
+
+	ups := apisixv1.NewDefaultUpstream()
+	ups.Name = apisixv1.ComposeUpstreamName("default", "httpbin", "", 80)
+	ups.Nodes = apisixv1.UpstreamNodes{{Host: "10.0.0.1", Port: 80, Weight: 100}}
+	ups.ID = apisixv1.ComposeUpstreamID(ups)
+	route := routeOf("default", "httpbin")
+	route.UpstreamId = ups.ID
+	added := &utils.Manifest{Upstreams: []*apisixv1.Upstream{ups}, Routes: []*apisixv1.Route{route}}
+	assert.Nil(t, c.syncManifestsToCluster(ctx, "default", added, nil, nil))
+
+	// The nodes are updated in place.
+	newUps := ups.DeepCopy()
+	newUps.Nodes = apisixv1.UpstreamNodes{{Host: "10.0.0.2", Port: 80, Weight: 100}}
+	assert.Nil(t, c.updateUpstream(ctx, "default", ups, newUps))
+	upstreams, err := cluster.Upstream().ListByName(ctx, ups.Name)
+	assert.Nil(t, err)
+	assert.Len(t, upstreams, 1)
+	assert.Equal(t, ups.ID, upstreams[0].ID)
+	assert.Equal(t, newUps.Nodes, upstreams[0].Nodes)
+
+	// The upstream is replaced if the config changes, and the route is
+	// switched to the new one.
+	old := upstreams[0]
+	newUps = old.DeepCopy()
+	newUps.Scheme = apisixv1.SchemeHTTPS
+	assert.Nil(t, c.updateUpstream(ctx, "default", old, newUps))
+	assert.NotEqual(t, old.ID, newUps.ID)
+	upstreams, err = cluster.Upstream().ListByName(ctx, ups.Name)
+	assert.Nil(t, err)
+	assert.Len(t, upstreams, 1)
+	assert.Equal(t, newUps.ID, upstreams[0].ID)
+	routes, err := cluster.Route().List(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, routes, 1)
+	assert.Equal(t, newUps.ID, routes[0].UpstreamId)
+}
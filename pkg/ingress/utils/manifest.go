@@ -16,6 +16,7 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	"github.com/hashicorp/go-multierror"
@@ -174,6 +175,66 @@ type Manifest struct {
 	PluginConfigs []*apisixv1.PluginConfig
 }
 
+func (m *Manifest) batch() *apisix.Batch {
+	return &apisix.Batch{
+		SSLs:          m.SSLs,
+		Upstreams:     m.Upstreams,
+		PluginConfigs: m.PluginConfigs,
+		Routes:        m.Routes,
+		StreamRoutes:  m.StreamRoutes,
+	}
+}
+
+// withoutRoutes returns the manifest without the routes, and without the
+// upstreams and plugin configs which are only used by them, i.e. not used
+// by the stream routes in the manifest or by the objects in others.
+func (m *Manifest) withoutRoutes(others ...*Manifest) *Manifest {
+	upstreams := make(map[string]struct{})
+	pluginConfigs := make(map[string]struct{})
+	for _, r := range m.Routes {
+		upstreams[r.UpstreamId] = struct{}{}
+		for _, id := range apisixv1.TrafficSplitUpstreamIDs(r) {
+			upstreams[id] = struct{}{}
+		}
+		pluginConfigs[r.PluginConfigId] = struct{}{}
+	}
+	used := func(sources []*Manifest) {
+		for _, o := range sources {
+			if o == nil {
+				continue
+			}
+			for _, r := range o.Routes {
+				delete(upstreams, r.UpstreamId)
+				for _, id := range apisixv1.TrafficSplitUpstreamIDs(r) {
+					delete(upstreams, id)
+				}
+				delete(pluginConfigs, r.PluginConfigId)
+			}
+			for _, sr := range o.StreamRoutes {
+				delete(upstreams, sr.UpstreamId)
+			}
+		}
+	}
+	used(others)
+	used([]*Manifest{{StreamRoutes: m.StreamRoutes}})
+
+	rest := &Manifest{
+		SSLs:         m.SSLs,
+		StreamRoutes: m.StreamRoutes,
+	}
+	for _, u := range m.Upstreams {
+		if _, ok := upstreams[u.ID]; !ok {
+			rest.Upstreams = append(rest.Upstreams, u)
+		}
+	}
+	for _, pc := range m.PluginConfigs {
+		if _, ok := pluginConfigs[pc.ID]; !ok {
+			rest.PluginConfigs = append(rest.PluginConfigs, pc)
+		}
+	}
+	return rest
+}
+
 func (m *Manifest) Diff(om *Manifest) (added, updated, deleted *Manifest) {
 	sa, su, sd := DiffSSL(om.SSLs, m.SSLs)
 	ar, ur, dr := DiffRoutes(om.Routes, m.Routes)
@@ -211,30 +272,91 @@ func (m *Manifest) Diff(om *Manifest) (added, updated, deleted *Manifest) {
 	return
 }
 
-func SyncManifests(ctx context.Context, apisix apisix.APISIX, clusterName string, added, updated, deleted *Manifest) error {
+// RouteGuard guards the creation of the added routes in SyncManifests, it's
+// called with the added routes and the routes deleted by the same sync right
+// before the added objects are written. If it fails, the added routes and the
+// upstreams and plugin configs only used by them are skipped, the rest of the
+// manifest is still synced. The returned function is called once the added
+// objects are written.
+type RouteGuard func(added, deleted []*apisixv1.Route) (release func(), err error)
+
+func SyncManifests(ctx context.Context, apisix apisix.APISIX, clusterName string, added, updated, deleted *Manifest, guards ...RouteGuard) error {
 	var merr *multierror.Error
 
 	if deleted != nil {
 		for _, ssl := range deleted.SSLs {
 			if err := apisix.Cluster(clusterName).SSL().Delete(ctx, ssl); err != nil {
-				merr = multierror.Append(merr, err)
+				merr = multierror.Append(merr, fmt.Errorf("failed to delete ssl %s: %w", ssl.ID, err))
 			}
 		}
 		for _, r := range deleted.Routes {
 			if err := apisix.Cluster(clusterName).Route().Delete(ctx, r); err != nil {
-				merr = multierror.Append(merr, err)
+				merr = multierror.Append(merr, fmt.Errorf("failed to delete route %s: %w", r.Name, err))
 			}
 		}
 		for _, sr := range deleted.StreamRoutes {
 			if err := apisix.Cluster(clusterName).StreamRoute().Delete(ctx, sr); err != nil {
+				merr = multierror.Append(merr, fmt.Errorf("failed to delete stream_route %s: %w", sr.ID, err))
+			}
+		}
+	}
+	if added != nil && len(guards) > 0 && len(added.Routes) > 0 {
+		// The guards run before the added objects are written, if any of
+		// them rejects, only the added routes and the objects used by them
+		// only are skipped, the rest of the manifest is still synced.
+		var deletedRoutes []*apisixv1.Route
+		if deleted != nil {
+			deletedRoutes = deleted.Routes
+		}
+		var (
+			releases []func()
+			rejected error
+		)
+		for _, guard := range guards {
+			release, err := guard(added.Routes, deletedRoutes)
+			if err != nil {
+				rejected = err
+				break
+			}
+			releases = append(releases, release)
+		}
+		if rejected != nil {
+			for i := len(releases) - 1; i >= 0; i-- {
+				releases[i]()
+			}
+			merr = multierror.Append(merr, rejected)
+			if err := apisix.Cluster(clusterName).BatchCreate(ctx, added.withoutRoutes(updated).batch()); err != nil {
+				merr = multierror.Append(merr, err)
+			}
+		} else {
+			err := apisix.Cluster(clusterName).BatchCreate(ctx, added.batch())
+			for i := len(releases) - 1; i >= 0; i-- {
+				releases[i]()
+			}
+			if err != nil {
 				merr = multierror.Append(merr, err)
 			}
 		}
+	} else if added != nil {
+		// Upstreams are created before the routes due to the dependencies.
+		if err := apisix.Cluster(clusterName).BatchCreate(ctx, added.batch()); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	if updated != nil {
+		if err := apisix.Cluster(clusterName).BatchUpdate(ctx, updated.batch()); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	// Upstreams and plugin configs are deleted after the routes are updated,
+	// the updated routes might be the last ones referencing them, e.g. when
+	// a canary backend is removed from the traffic-split plugin.
+	if deleted != nil {
 		for _, u := range deleted.Upstreams {
 			if err := apisix.Cluster(clusterName).Upstream().Delete(ctx, u); err != nil {
 				// Upstream might be referenced by other routes.
 				if err != cache.ErrStillInUse {
-					merr = multierror.Append(merr, err)
+					merr = multierror.Append(merr, fmt.Errorf("failed to delete upstream %s: %w", u.Name, err))
 				} else {
 					log.Infow("upstream was referenced by other routes",
 						zap.String("upstream_id", u.ID),
@@ -247,7 +369,7 @@ func SyncManifests(ctx context.Context, apisix apisix.APISIX, clusterName string
 			if err := apisix.Cluster(clusterName).PluginConfig().Delete(ctx, pc); err != nil {
 				// pluginConfig might be referenced by other routes.
 				if err != cache.ErrStillInUse {
-					merr = multierror.Append(merr, err)
+					merr = multierror.Append(merr, fmt.Errorf("failed to delete plugin_config %s: %w", pc.Name, err))
 				} else {
 					log.Infow("plugin_config was referenced by other routes",
 						zap.String("plugin_config_id", pc.ID),
@@ -257,61 +379,6 @@ func SyncManifests(ctx context.Context, apisix apisix.APISIX, clusterName string
 			}
 		}
 	}
-	if added != nil {
-		// Should create upstreams firstly due to the dependencies.
-		for _, ssl := range added.SSLs {
-			if _, err := apisix.Cluster(clusterName).SSL().Create(ctx, ssl); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, u := range added.Upstreams {
-			if _, err := apisix.Cluster(clusterName).Upstream().Create(ctx, u); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, pc := range added.PluginConfigs {
-			if _, err := apisix.Cluster(clusterName).PluginConfig().Create(ctx, pc); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, r := range added.Routes {
-			if _, err := apisix.Cluster(clusterName).Route().Create(ctx, r); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, sr := range added.StreamRoutes {
-			if _, err := apisix.Cluster(clusterName).StreamRoute().Create(ctx, sr); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-	}
-	if updated != nil {
-		for _, ssl := range updated.SSLs {
-			if _, err := apisix.Cluster(clusterName).SSL().Update(ctx, ssl); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, r := range updated.Upstreams {
-			if _, err := apisix.Cluster(clusterName).Upstream().Update(ctx, r); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, pc := range updated.PluginConfigs {
-			if _, err := apisix.Cluster(clusterName).PluginConfig().Update(ctx, pc); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, r := range updated.Routes {
-			if _, err := apisix.Cluster(clusterName).Route().Update(ctx, r); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-		for _, sr := range updated.StreamRoutes {
-			if _, err := apisix.Cluster(clusterName).StreamRoute().Create(ctx, sr); err != nil {
-				merr = multierror.Append(merr, err)
-			}
-		}
-	}
 	if merr != nil {
 		return merr
 	}
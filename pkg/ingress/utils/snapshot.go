@@ -0,0 +1,180 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// Snapshot is the state of APISIX resources fetched at once, it's used by the
+// periodic resync to skip pushing the resources which are unchanged.
+type Snapshot struct {
+	routes        map[string]*apisixv1.Route
+	upstreams     map[string]*apisixv1.Upstream
+	streamRoutes  map[string]*apisixv1.StreamRoute
+	pluginConfigs map[string]*apisixv1.PluginConfig
+	ssls          map[string]*apisixv1.Ssl
+}
+
+// Snapshots are the snapshots of the APISIX clusters keyed by the cluster
+// names, the resources of a cluster without snapshot are all pushed.
+type Snapshots map[string]*Snapshot
+
+// NewSnapshot fetches the routes, upstreams, stream routes, plugin configs and
+// ssls from the APISIX cluster, keyed by their IDs.
+func NewSnapshot(ctx context.Context, apisix apisix.APISIX, clusterName string) (*Snapshot, error) {
+	cluster := apisix.Cluster(clusterName)
+	s := &Snapshot{
+		routes:        make(map[string]*apisixv1.Route),
+		upstreams:     make(map[string]*apisixv1.Upstream),
+		streamRoutes:  make(map[string]*apisixv1.StreamRoute),
+		pluginConfigs: make(map[string]*apisixv1.PluginConfig),
+		ssls:          make(map[string]*apisixv1.Ssl),
+	}
+
+	routes, err := cluster.Route().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range routes {
+		s.routes[r.ID] = r
+	}
+	upstreams, err := cluster.Upstream().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range upstreams {
+		s.upstreams[u.ID] = u
+	}
+	streamRoutes, err := cluster.StreamRoute().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, sr := range streamRoutes {
+		s.streamRoutes[sr.ID] = sr
+	}
+	pluginConfigs, err := cluster.PluginConfig().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pc := range pluginConfigs {
+		s.pluginConfigs[pc.ID] = pc
+	}
+	ssls, err := cluster.SSL().List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, ssl := range ssls {
+		s.ssls[ssl.ID] = ssl
+	}
+	return s, nil
+}
+
+// SnapshotStats counts the resources which are skipped or pushed, keyed by the
+// resource type.
+type SnapshotStats struct {
+	Skipped map[string]int
+	Pushed  map[string]int
+}
+
+func (st *SnapshotStats) record(resource string, skipped bool) {
+	if skipped {
+		st.Skipped[resource]++
+	} else {
+		st.Pushed[resource]++
+	}
+}
+
+// Filter returns a manifest which only contains the resources absent or
+// different in the snapshot.
+func (s *Snapshot) Filter(m *Manifest) (*Manifest, *SnapshotStats) {
+	stats := &SnapshotStats{
+		Skipped: make(map[string]int),
+		Pushed:  make(map[string]int),
+	}
+	if m == nil {
+		return nil, stats
+	}
+
+	filtered := &Manifest{}
+	for _, r := range m.Routes {
+		old, ok := s.routes[r.ID]
+		skip := ok && resourceEqual(old, r)
+		stats.record("route", skip)
+		if !skip {
+			filtered.Routes = append(filtered.Routes, r)
+		}
+	}
+	for _, u := range m.Upstreams {
+		old, ok := s.upstreams[u.ID]
+		skip := ok && resourceEqual(old, u)
+		stats.record("upstream", skip)
+		if !skip {
+			filtered.Upstreams = append(filtered.Upstreams, u)
+		}
+	}
+	for _, sr := range m.StreamRoutes {
+		old, ok := s.streamRoutes[sr.ID]
+		skip := ok && resourceEqual(old, sr)
+		stats.record("streamRoute", skip)
+		if !skip {
+			filtered.StreamRoutes = append(filtered.StreamRoutes, sr)
+		}
+	}
+	for _, pc := range m.PluginConfigs {
+		old, ok := s.pluginConfigs[pc.ID]
+		skip := ok && resourceEqual(old, pc)
+		stats.record("pluginConfig", skip)
+		if !skip {
+			filtered.PluginConfigs = append(filtered.PluginConfigs, pc)
+		}
+	}
+	for _, ssl := range m.SSLs {
+		old, ok := s.ssls[ssl.ID]
+		skip := ok && resourceEqual(old, ssl)
+		stats.record("ssl", skip)
+		if !skip {
+			filtered.SSLs = append(filtered.SSLs, ssl)
+		}
+	}
+	return filtered, stats
+}
+
+// resourceEqual compares two resources by their JSON representations, so that
+// the plugin configs decoded from APISIX (generic maps) can be compared with
+// the typed ones generated by the translator.
+func resourceEqual(a, b interface{}) bool {
+	var x, y interface{}
+	if err := normalize(a, &x); err != nil {
+		return false
+	}
+	if err := normalize(b, &y); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func normalize(in interface{}, out *interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
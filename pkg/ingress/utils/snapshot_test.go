@@ -0,0 +1,95 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestSnapshotFilter(t *testing.T) {
+	unchanged := apisixv1.NewDefaultRoute()
+	unchanged.ID = "1"
+	unchanged.Uris = []string{"/foo"}
+	unchanged.Plugins = apisixv1.Plugins{
+		"traffic-split": &apisixv1.TrafficSplitConfig{
+			Rules: []apisixv1.TrafficSplitConfigRule{
+				{
+					WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+						{UpstreamID: "u2", Weight: 10},
+						{Weight: 5},
+					},
+				},
+			},
+		},
+	}
+	changed := apisixv1.NewDefaultRoute()
+	changed.ID = "2"
+	changed.Uris = []string{"/bar"}
+	added := apisixv1.NewDefaultRoute()
+	added.ID = "3"
+
+	ups := apisixv1.NewDefaultUpstream()
+	ups.ID = "u1"
+	ups.Nodes = apisixv1.UpstreamNodes{{Host: "10.0.0.1", Port: 80, Weight: 100}}
+
+	// The objects in snapshot come from APISIX, plugin configs are generic maps.
+	oldChanged := apisixv1.NewDefaultRoute()
+	oldChanged.ID = "2"
+	oldChanged.Uris = []string{"/baz"}
+	oldUnchanged := apisixv1.NewDefaultRoute()
+	oldUnchanged.ID = "1"
+	oldUnchanged.Uris = []string{"/foo"}
+	oldUnchanged.Plugins = apisixv1.Plugins{
+		"traffic-split": map[string]interface{}{
+			"rules": []interface{}{
+				map[string]interface{}{
+					"weighted_upstreams": []interface{}{
+						map[string]interface{}{"upstream_id": "u2", "weight": float64(10)},
+						map[string]interface{}{"weight": float64(5)},
+					},
+				},
+			},
+		},
+	}
+	oldUps := apisixv1.NewDefaultUpstream()
+	oldUps.ID = "u1"
+	oldUps.Nodes = apisixv1.UpstreamNodes{{Host: "10.0.0.1", Port: 80, Weight: 100}}
+
+	s := &Snapshot{
+		routes: map[string]*apisixv1.Route{
+			"1": oldUnchanged,
+			"2": oldChanged,
+		},
+		upstreams: map[string]*apisixv1.Upstream{
+			"u1": oldUps,
+		},
+	}
+
+	filtered, stats := s.Filter(&Manifest{
+		Routes:    []*apisixv1.Route{unchanged, changed, added},
+		Upstreams: []*apisixv1.Upstream{ups},
+	})
+	assert.Equal(t, []*apisixv1.Route{changed, added}, filtered.Routes)
+	assert.Nil(t, filtered.Upstreams)
+	assert.Equal(t, map[string]int{"route": 1, "upstream": 1}, stats.Skipped)
+	assert.Equal(t, map[string]int{"route": 2}, stats.Pushed)
+
+	filtered, _ = s.Filter(nil)
+	assert.Nil(t, filtered)
+}
@@ -0,0 +1,128 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
+)
+
+// SyncManifestsWithRollback is like SyncManifests, but if any object fails to
+// be applied, the whole manifest is reverted, so that the dependent objects
+// (e.g. an upstream and its routes) are not left half applied in APISIX.
+// The rollback is also applied with SyncManifests, without the guards since
+// it only restores the previous objects.
+func SyncManifestsWithRollback(ctx context.Context, apisix apisix.APISIX, clusterName string, added, updated, deleted *Manifest, guards ...RouteGuard) error {
+	// The previous objects are taken from the cache before it's changed
+	// by the sync.
+	undoAdded, undoUpdated, undoDeleted, err := revertManifests(apisix.Cluster(clusterName).Cache(), added, updated, deleted)
+	if err != nil {
+		return err
+	}
+	err = SyncManifests(ctx, apisix, clusterName, added, updated, deleted, guards...)
+	if err == nil {
+		return nil
+	}
+	log.Warnw("failed to sync manifest, rolling back",
+		zap.String("cluster", clusterName),
+		zap.Error(err),
+	)
+	if rerr := SyncManifests(ctx, apisix, clusterName, undoAdded, undoUpdated, undoDeleted); rerr != nil {
+		log.Errorw("failed to roll back manifest",
+			zap.String("cluster", clusterName),
+			zap.Error(rerr),
+		)
+		return multierror.Append(err, rerr)
+	}
+	return err
+}
+
+// revertManifests returns the manifests which revert the given ones: the
+// added objects are deleted, the deleted objects are created again, and the
+// updated objects are restored to the cached ones (or deleted if they were
+// absent).
+func revertManifests(c cache.Cache, added, updated, deleted *Manifest) (undoAdded, undoUpdated, undoDeleted *Manifest, err error) {
+	undoUpdated = &Manifest{}
+	undoDeleted = &Manifest{}
+	if added != nil {
+		undoDeleted.SSLs = append(undoDeleted.SSLs, added.SSLs...)
+		undoDeleted.Routes = append(undoDeleted.Routes, added.Routes...)
+		undoDeleted.StreamRoutes = append(undoDeleted.StreamRoutes, added.StreamRoutes...)
+		undoDeleted.Upstreams = append(undoDeleted.Upstreams, added.Upstreams...)
+		undoDeleted.PluginConfigs = append(undoDeleted.PluginConfigs, added.PluginConfigs...)
+	}
+	if updated != nil {
+		for _, ssl := range updated.SSLs {
+			prev, err := c.GetSSL(ssl.ID)
+			if err == cache.ErrNotFound {
+				undoDeleted.SSLs = append(undoDeleted.SSLs, ssl)
+			} else if err != nil {
+				return nil, nil, nil, err
+			} else {
+				undoUpdated.SSLs = append(undoUpdated.SSLs, prev)
+			}
+		}
+		for _, r := range updated.Routes {
+			prev, err := c.GetRoute(r.ID)
+			if err == cache.ErrNotFound {
+				undoDeleted.Routes = append(undoDeleted.Routes, r)
+			} else if err != nil {
+				return nil, nil, nil, err
+			} else {
+				undoUpdated.Routes = append(undoUpdated.Routes, prev)
+			}
+		}
+		for _, sr := range updated.StreamRoutes {
+			prev, err := c.GetStreamRoute(sr.ID)
+			if err == cache.ErrNotFound {
+				undoDeleted.StreamRoutes = append(undoDeleted.StreamRoutes, sr)
+			} else if err != nil {
+				return nil, nil, nil, err
+			} else {
+				undoUpdated.StreamRoutes = append(undoUpdated.StreamRoutes, prev)
+			}
+		}
+		for _, u := range updated.Upstreams {
+			prev, err := c.GetUpstream(u.ID)
+			if err == cache.ErrNotFound {
+				undoDeleted.Upstreams = append(undoDeleted.Upstreams, u)
+			} else if err != nil {
+				return nil, nil, nil, err
+			} else {
+				undoUpdated.Upstreams = append(undoUpdated.Upstreams, prev)
+			}
+		}
+		for _, pc := range updated.PluginConfigs {
+			prev, err := c.GetPluginConfig(pc.ID)
+			if err == cache.ErrNotFound {
+				undoDeleted.PluginConfigs = append(undoDeleted.PluginConfigs, pc)
+			} else if err != nil {
+				return nil, nil, nil, err
+			} else {
+				undoUpdated.PluginConfigs = append(undoUpdated.PluginConfigs, prev)
+			}
+		}
+	}
+	// The deleted objects in the manifest are the ones which were applied
+	// before, they are created as is.
+	undoAdded = deleted
+	return
+}
@@ -0,0 +1,301 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/pkg/apisix"
+	"github.com/apache/apisix-ingress-controller/pkg/apisix/cache"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+type fakeTxnAPISIX struct {
+	apisix.APISIX
+	cluster *fakeTxnCluster
+}
+
+func (f *fakeTxnAPISIX) Cluster(string) apisix.Cluster {
+	return f.cluster
+}
+
+type fakeTxnCluster struct {
+	apisix.Cluster
+	cache     cache.Cache
+	routes    *fakeTxnRoutes
+	upstreams *fakeTxnUpstreams
+}
+
+func (f *fakeTxnCluster) Cache() cache.Cache {
+	return f.cache
+}
+
+func (f *fakeTxnCluster) Route() apisix.Route {
+	return f.routes
+}
+
+func (f *fakeTxnCluster) Upstream() apisix.Upstream {
+	return f.upstreams
+}
+
+func (f *fakeTxnCluster) BatchCreate(ctx context.Context, b *apisix.Batch) error {
+	var err error
+	for _, u := range b.Upstreams {
+		if _, e := f.upstreams.Create(ctx, u); e != nil {
+			err = e
+		}
+	}
+	for _, r := range b.Routes {
+		if _, e := f.routes.Create(ctx, r); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+func (f *fakeTxnCluster) BatchUpdate(ctx context.Context, b *apisix.Batch) error {
+	var err error
+	for _, u := range b.Upstreams {
+		if _, e := f.upstreams.Update(ctx, u); e != nil {
+			err = e
+		}
+	}
+	return err
+}
+
+type fakeTxnRoutes struct {
+	apisix.Route
+	objects map[string]*apisixv1.Route
+	// fail is the ID of the route whose creation fails.
+	fail string
+}
+
+func (f *fakeTxnRoutes) Create(_ context.Context, r *apisixv1.Route) (*apisixv1.Route, error) {
+	if r.ID == f.fail {
+		return nil, errors.New("injected failure")
+	}
+	f.objects[r.ID] = r
+	return r, nil
+}
+
+func (f *fakeTxnRoutes) Delete(_ context.Context, r *apisixv1.Route) error {
+	delete(f.objects, r.ID)
+	return nil
+}
+
+type fakeTxnUpstreams struct {
+	apisix.Upstream
+	objects map[string]*apisixv1.Upstream
+}
+
+func (f *fakeTxnUpstreams) Create(_ context.Context, u *apisixv1.Upstream) (*apisixv1.Upstream, error) {
+	f.objects[u.ID] = u
+	return u, nil
+}
+
+func (f *fakeTxnUpstreams) Update(_ context.Context, u *apisixv1.Upstream) (*apisixv1.Upstream, error) {
+	f.objects[u.ID] = u
+	return u, nil
+}
+
+func (f *fakeTxnUpstreams) Delete(_ context.Context, u *apisixv1.Upstream) error {
+	delete(f.objects, u.ID)
+	return nil
+}
+
+func TestSyncManifestsWithRollback(t *testing.T) {
+	oldUps := &apisixv1.Upstream{
+		Metadata: apisixv1.Metadata{ID: "2", Name: "ups2"},
+		Type:     "roundrobin",
+	}
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	// The cache reflects APISIX before the sync.
+	assert.Nil(t, db.InsertUpstream(oldUps))
+	cluster := &fakeTxnCluster{
+		cache: db,
+		routes: &fakeTxnRoutes{
+			objects: map[string]*apisixv1.Route{},
+			fail:    "4",
+		},
+		upstreams: &fakeTxnUpstreams{
+			objects: map[string]*apisixv1.Upstream{
+				"2": oldUps,
+			},
+		},
+	}
+	cli := &fakeTxnAPISIX{cluster: cluster}
+
+	added := &Manifest{
+		Upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "1", Name: "ups1"}},
+		},
+		Routes: []*apisixv1.Route{
+			{Metadata: apisixv1.Metadata{ID: "3", Name: "route1"}, UpstreamId: "1"},
+			{Metadata: apisixv1.Metadata{ID: "4", Name: "route2"}, UpstreamId: "2"},
+		},
+	}
+	updated := &Manifest{
+		Upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "2", Name: "ups2"}, Type: "chash"},
+		},
+	}
+
+	// Without the rollback, the objects before the failure stay applied.
+	err = SyncManifests(context.Background(), cli, "default", added, updated, nil)
+	assert.Error(t, err)
+	assert.Len(t, cluster.upstreams.objects, 2)
+	assert.Len(t, cluster.routes.objects, 1)
+
+	// Start over from the original state.
+	cluster.upstreams.objects = map[string]*apisixv1.Upstream{"2": oldUps}
+	cluster.routes.objects = map[string]*apisixv1.Route{}
+
+	// The second route fails, the first route and the added upstream are
+	// removed, and the updated upstream is restored.
+	err = SyncManifestsWithRollback(context.Background(), cli, "default", added, updated, nil)
+	assert.Contains(t, err.Error(), "injected failure")
+	assert.Empty(t, cluster.routes.objects)
+	assert.Equal(t, map[string]*apisixv1.Upstream{"2": oldUps}, cluster.upstreams.objects)
+
+	// Nothing is reverted once all objects are applied.
+	cluster.routes.fail = ""
+	err = SyncManifestsWithRollback(context.Background(), cli, "default", added, updated, nil)
+	assert.NoError(t, err)
+	assert.Len(t, cluster.routes.objects, 2)
+	assert.Len(t, cluster.upstreams.objects, 2)
+	assert.Equal(t, "chash", cluster.upstreams.objects["2"].Type)
+}
+
+func TestSyncManifestsRouteGuard(t *testing.T) {
+	db, err := cache.NewMemDBCache()
+	assert.Nil(t, err)
+	cluster := &fakeTxnCluster{
+		cache:     db,
+		routes:    &fakeTxnRoutes{objects: map[string]*apisixv1.Route{}},
+		upstreams: &fakeTxnUpstreams{objects: map[string]*apisixv1.Upstream{}},
+	}
+	cli := &fakeTxnAPISIX{cluster: cluster}
+	added := &Manifest{
+		Upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "1", Name: "ups1"}},
+		},
+		Routes: []*apisixv1.Route{
+			{Metadata: apisixv1.Metadata{ID: "2", Name: "route1"}, UpstreamId: "1"},
+		},
+	}
+	deleted := &Manifest{
+		Routes: []*apisixv1.Route{
+			{Metadata: apisixv1.Metadata{ID: "3", Name: "route2"}},
+		},
+	}
+
+	// The guard runs before the added objects are written, and is held
+	// until they are written.
+	released := false
+	guard := func(routes, deletedRoutes []*apisixv1.Route) (func(), error) {
+		assert.Empty(t, cluster.upstreams.objects)
+		assert.Empty(t, cluster.routes.objects)
+		assert.Equal(t, added.Routes, routes)
+		assert.Equal(t, deleted.Routes, deletedRoutes)
+		return func() {
+			assert.Len(t, cluster.upstreams.objects, 1)
+			assert.Len(t, cluster.routes.objects, 1)
+			released = true
+		}, nil
+	}
+	err = SyncManifests(context.Background(), cli, "default", added, nil, deleted, guard)
+	assert.NoError(t, err)
+	assert.True(t, released)
+
+	// Only the added routes and the objects used by them only are skipped
+	// if the guard fails, the rest of the manifest is still synced.
+	oldUps := &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "4", Name: "ups4"}}
+	cluster.upstreams.objects = map[string]*apisixv1.Upstream{
+		"4": oldUps,
+		"5": {Metadata: apisixv1.Metadata{ID: "5", Name: "ups5"}},
+	}
+	cluster.routes.objects = map[string]*apisixv1.Route{}
+	added.Upstreams = append(added.Upstreams, &apisixv1.Upstream{Metadata: apisixv1.Metadata{ID: "6", Name: "ups6"}})
+	updated := &Manifest{
+		Upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "5", Name: "ups5"}, Type: "chash"},
+		},
+	}
+	deleted = &Manifest{Upstreams: []*apisixv1.Upstream{oldUps}}
+	released = false
+	accept := func(_, _ []*apisixv1.Route) (func(), error) {
+		return func() { released = true }, nil
+	}
+	reject := func(_, _ []*apisixv1.Route) (func(), error) {
+		return nil, errors.New("rejected")
+	}
+	err = SyncManifests(context.Background(), cli, "default", added, updated, deleted, accept, reject)
+	assert.Contains(t, err.Error(), "rejected")
+	assert.Empty(t, cluster.routes.objects)
+	assert.Len(t, cluster.upstreams.objects, 2)
+	assert.NotContains(t, cluster.upstreams.objects, "1")
+	assert.Contains(t, cluster.upstreams.objects, "6")
+	assert.Equal(t, "chash", cluster.upstreams.objects["5"].Type)
+	// The guards already held are released.
+	assert.True(t, released)
+}
+
+func TestManifestWithoutRoutes(t *testing.T) {
+	m := &Manifest{
+		Routes: []*apisixv1.Route{
+			{
+				Metadata:       apisixv1.Metadata{ID: "1"},
+				UpstreamId:     "1",
+				PluginConfigId: "1",
+				Plugins: apisixv1.Plugins{
+					"traffic-split": &apisixv1.TrafficSplitConfig{
+						Rules: []apisixv1.TrafficSplitConfigRule{
+							{
+								WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+									{UpstreamID: "2", Weight: 10},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		StreamRoutes: []*apisixv1.StreamRoute{{ID: "1", UpstreamId: "3"}},
+		Upstreams: []*apisixv1.Upstream{
+			{Metadata: apisixv1.Metadata{ID: "1"}},
+			{Metadata: apisixv1.Metadata{ID: "2"}},
+			{Metadata: apisixv1.Metadata{ID: "3"}},
+			{Metadata: apisixv1.Metadata{ID: "4"}},
+		},
+		PluginConfigs: []*apisixv1.PluginConfig{
+			{Metadata: apisixv1.Metadata{ID: "1"}},
+			{Metadata: apisixv1.Metadata{ID: "2"}},
+		},
+	}
+	updated := &Manifest{
+		Routes: []*apisixv1.Route{{Metadata: apisixv1.Metadata{ID: "2"}, UpstreamId: "1"}},
+	}
+	rest := m.withoutRoutes(updated, nil)
+	assert.Empty(t, rest.Routes)
+	assert.Equal(t, m.StreamRoutes, rest.StreamRoutes)
+	// Upstream 2 and plugin config 1 are only used by the skipped route.
+	assert.Equal(t, []*apisixv1.Upstream{m.Upstreams[0], m.Upstreams[2], m.Upstreams[3]}, rest.Upstreams)
+	assert.Equal(t, []*apisixv1.PluginConfig{m.PluginConfigs[1]}, rest.PluginConfigs)
+}
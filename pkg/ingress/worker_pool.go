@@ -0,0 +1,54 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package ingress
+
+import "sync"
+
+// workerPool holds the number of workers of each resource controller, it can
+// be changed at runtime by reloading the configuration.
+type workerPool struct {
+	mu      sync.Mutex
+	size    int
+	resized chan struct{}
+}
+
+func newWorkerPool(size int) *workerPool {
+	return &workerPool{
+		size:    size,
+		resized: make(chan struct{}),
+	}
+}
+
+// get returns the pool size and a channel which is closed once the size is
+// changed.
+func (p *workerPool) get() (int, <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size, p.resized
+}
+
+// resize changes the pool size, the running controllers start or stop workers
+// accordingly. It returns false if the size is unchanged.
+func (p *workerPool) resize(size int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if size == p.size {
+		return false
+	}
+	p.size = size
+	close(p.resized)
+	p.resized = make(chan struct{})
+	return true
+}
@@ -106,7 +106,7 @@ type ApisixUpstreamConfig struct {
 	// +optional
 	LoadBalancer *LoadBalancer `json:"loadbalancer,omitempty" yaml:"loadbalancer,omitempty"`
 	// The scheme used to talk with the upstream.
-	// Now value can be http, grpc.
+	// Now value can be http, https, grpc, grpcs.
 	// +optional
 	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
 
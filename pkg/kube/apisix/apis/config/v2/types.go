@@ -54,6 +54,17 @@ type UpstreamTimeout struct {
 	Read    metav1.Duration `json:"read,omitempty" yaml:"read,omitempty"`
 }
 
+// UpstreamKeepalivePool is settings for the keepalive connection pool to the
+// upstream, the APISIX default is used for the items not set.
+type UpstreamKeepalivePool struct {
+	// Size is the maximum number of idle connections kept in the pool.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// IdleTimeout is the idle timeout of the connections in the pool.
+	IdleTimeout metav1.Duration `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+	// Requests is the maximum number of requests served by a connection.
+	Requests int `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
 // ApisixRouteHTTP represents a single route in for HTTP traffic.
 type ApisixRouteHTTP struct {
 	// The rule name, cannot be empty.
@@ -61,17 +72,286 @@ type ApisixRouteHTTP struct {
 	// Route priority, when multiple routes contains
 	// same URI path (for path matching), route with
 	// higher priority will take effect.
-	Priority int                  `json:"priority,omitempty" yaml:"priority,omitempty"`
-	Timeout  *UpstreamTimeout     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
-	Match    ApisixRouteHTTPMatch `json:"match,omitempty" yaml:"match,omitempty"`
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// Enable indicates whether the route rule is in service, a disabled
+	// rule is not pushed to APISIX (and is removed if it was pushed before).
+	// Rules are enabled by default.
+	Enable  *bool                `json:"enable,omitempty" yaml:"enable,omitempty"`
+	Timeout *UpstreamTimeout     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	Match   ApisixRouteHTTPMatch `json:"match,omitempty" yaml:"match,omitempty"`
 	// Backends represents potential backends to proxy after the route
 	// rule matched. When number of backends are more than one, traffic-split
 	// plugin in APISIX will be used to split traffic based on the backend weight.
-	Backends         []ApisixRouteHTTPBackend  `json:"backends,omitempty" yaml:"backends,omitempty"`
-	Websocket        bool                      `json:"websocket" yaml:"websocket"`
-	PluginConfigName string                    `json:"plugin_config_name,omitempty" yaml:"plugin_config_name,omitempty"`
-	Plugins          []ApisixRouteHTTPPlugin   `json:"plugins,omitempty" yaml:"plugins,omitempty"`
-	Authentication   ApisixRouteAuthentication `json:"authentication,omitempty" yaml:"authentication,omitempty"`
+	Backends         []ApisixRouteHTTPBackend `json:"backends,omitempty" yaml:"backends,omitempty"`
+	Websocket        bool                     `json:"websocket" yaml:"websocket"`
+	PluginConfigName string                   `json:"plugin_config_name,omitempty" yaml:"plugin_config_name,omitempty"`
+	Plugins          []ApisixRouteHTTPPlugin  `json:"plugins,omitempty" yaml:"plugins,omitempty"`
+	// PluginsFrom loads plugins from a ConfigMap, the inline Plugins take
+	// precedence over the ones with the same name.
+	PluginsFrom    *ApisixRouteHTTPPluginsFrom `json:"pluginsFrom,omitempty" yaml:"pluginsFrom,omitempty"`
+	Authentication ApisixRouteAuthentication   `json:"authentication,omitempty" yaml:"authentication,omitempty"`
+	// FileLogger enables the file-logger plugin for this route, access
+	// logs will be written to the specified path in the given format.
+	FileLogger *ApisixRouteHTTPFileLogger `json:"fileLogger,omitempty" yaml:"fileLogger,omitempty"`
+	// MethodBackends routes the requests to different backends according to
+	// the request method, requests with the other methods are proxied to the
+	// Backends.
+	MethodBackends []ApisixRouteHTTPMethodBackend `json:"methodBackends,omitempty" yaml:"methodBackends,omitempty"`
+	// Rewrite rewrites the request path before proxying, it's translated
+	// to the proxy-rewrite plugin.
+	Rewrite *ApisixRouteHTTPRewrite `json:"rewrite,omitempty" yaml:"rewrite,omitempty"`
+	// Canary splits the traffic to the canary backends by the request
+	// attributes and weights, it's translated to the traffic-split plugin.
+	Canary *ApisixRouteHTTPCanary `json:"canary,omitempty" yaml:"canary,omitempty"`
+	// IPRestriction allows or denies the requests by the client address,
+	// it's translated to the ip-restriction plugin.
+	IPRestriction *ApisixRouteHTTPIPRestriction `json:"ipRestriction,omitempty" yaml:"ipRestriction,omitempty"`
+	// LimitCount limits the number of requests in a time window, it's
+	// translated to the limit-count plugin.
+	LimitCount *ApisixRouteHTTPLimitCount `json:"limitCount,omitempty" yaml:"limitCount,omitempty"`
+	// BodyTransformer transforms the request and response bodies with the
+	// templates stored in a ConfigMap, it's translated to the
+	// body-transformer plugin.
+	BodyTransformer *ApisixRouteHTTPBodyTransformer `json:"bodyTransformer,omitempty" yaml:"bodyTransformer,omitempty"`
+	// ForwardAuth delegates the authorization to an external service, it's
+	// translated to the forward-auth plugin.
+	ForwardAuth *ApisixRouteHTTPForwardAuth `json:"forwardAuth,omitempty" yaml:"forwardAuth,omitempty"`
+	// Cors enables the CORS of a route rule, it's translated to the cors
+	// plugin.
+	Cors *ApisixRouteHTTPCors `json:"cors,omitempty" yaml:"cors,omitempty"`
+	// ResponseRewrite rewrites the status code, headers or body of the
+	// response, it's translated to the response-rewrite plugin.
+	ResponseRewrite *ApisixRouteHTTPResponseRewrite `json:"responseRewrite,omitempty" yaml:"responseRewrite,omitempty"`
+	// RequestID adds a request ID to the requests without it, it's
+	// translated to the request-id plugin.
+	RequestID *ApisixRouteHTTPRequestID `json:"requestId,omitempty" yaml:"requestId,omitempty"`
+	// Serverless runs inline Lua functions for the requests, it's
+	// translated to the serverless-pre-function and
+	// serverless-post-function plugins.
+	// The functions run inside APISIX without any sandbox, they can read
+	// and change all the requests, the secrets and the filesystem of the
+	// gateway, so they are rejected unless allow_serverless_functions is
+	// enabled in the controller.
+	Serverless *ApisixRouteHTTPServerless `json:"serverless,omitempty" yaml:"serverless,omitempty"`
+}
+
+// ApisixRouteHTTPServerless is the inline Lua functions of a route rule.
+type ApisixRouteHTTPServerless struct {
+	// Pre runs the functions at the beginning of the phase, it's
+	// translated to the serverless-pre-function plugin.
+	Pre *ApisixRouteHTTPServerlessFunctions `json:"pre,omitempty" yaml:"pre,omitempty"`
+	// Post runs the functions at the end of the phase, it's translated to
+	// the serverless-post-function plugin.
+	Post *ApisixRouteHTTPServerlessFunctions `json:"post,omitempty" yaml:"post,omitempty"`
+}
+
+// ApisixRouteHTTPServerlessFunctions is the Lua functions run in a phase.
+type ApisixRouteHTTPServerlessFunctions struct {
+	// Phase is the phase to run the functions, can be "rewrite", "access",
+	// "header_filter", "body_filter", "log" or "before_proxy", default is
+	// "access".
+	Phase string `json:"phase,omitempty" yaml:"phase,omitempty"`
+	// Functions are the Lua functions run in order, each of them should
+	// be a string like "return function(conf, ctx) ... end".
+	Functions []string `json:"functions" yaml:"functions"`
+}
+
+// ApisixRouteHTTPRequestID is the request ID settings of a route rule.
+type ApisixRouteHTTPRequestID struct {
+	// HeaderName is the header carrying the request ID, default is
+	// "X-Request-Id".
+	HeaderName string `json:"headerName,omitempty" yaml:"headerName,omitempty"`
+	// Algorithm generates the request ID, can be "uuid", "snowflake" or
+	// "range_id", default is "uuid".
+	Algorithm string `json:"algorithm,omitempty" yaml:"algorithm,omitempty"`
+	// IncludeInResponse indicates whether to set the request ID in the
+	// response header, default is true.
+	IncludeInResponse *bool `json:"includeInResponse,omitempty" yaml:"includeInResponse,omitempty"`
+}
+
+// ApisixRouteHTTPResponseRewrite is the response rewrite settings of a
+// route rule.
+type ApisixRouteHTTPResponseRewrite struct {
+	// StatusCode replaces the status code of the response, in the range
+	// [200, 598].
+	StatusCode int `json:"statusCode,omitempty" yaml:"statusCode,omitempty"`
+	// Headers modifies the headers of the response.
+	Headers *ApisixRouteHTTPResponseRewriteHeaders `json:"headers,omitempty" yaml:"headers,omitempty"`
+	// Body replaces the body of the response.
+	Body string `json:"body,omitempty" yaml:"body,omitempty"`
+	// BodyBase64 replaces the body of the response with the base64
+	// decoded content, it can't be used together with Body.
+	BodyBase64 string `json:"bodyBase64,omitempty" yaml:"bodyBase64,omitempty"`
+}
+
+// ApisixRouteHTTPResponseRewriteHeaders modifies the response headers.
+type ApisixRouteHTTPResponseRewriteHeaders struct {
+	// Set sets the headers, the existing values are overridden.
+	Set map[string]string `json:"set,omitempty" yaml:"set,omitempty"`
+	// Add appends the headers, the existing values are kept. It requires
+	// APISIX 3.0 or later.
+	Add map[string]string `json:"add,omitempty" yaml:"add,omitempty"`
+	// Remove removes the headers.
+	Remove []string `json:"remove,omitempty" yaml:"remove,omitempty"`
+}
+
+// ApisixRouteHTTPCors is the CORS settings of a route rule, the omitted
+// fields have defaults which allow any origin, method and header unless
+// AllowCredentials is true.
+type ApisixRouteHTTPCors struct {
+	// AllowOrigins are the origins allowed, like "https://example.com",
+	// default is "*" (any origin), which cannot be used together with
+	// AllowCredentials.
+	AllowOrigins []string `json:"allowOrigins,omitempty" yaml:"allowOrigins,omitempty"`
+	// AllowMethods are the methods allowed, default is "*", or the
+	// common methods if AllowCredentials is true.
+	AllowMethods []string `json:"allowMethods,omitempty" yaml:"allowMethods,omitempty"`
+	// AllowHeaders are the request headers allowed, default is "*", or
+	// the CORS-safelisted headers and Authorization if AllowCredentials
+	// is true.
+	AllowHeaders []string `json:"allowHeaders,omitempty" yaml:"allowHeaders,omitempty"`
+	// ExposeHeaders are the response headers exposed to the client.
+	ExposeHeaders []string `json:"exposeHeaders,omitempty" yaml:"exposeHeaders,omitempty"`
+	// MaxAge is the seconds the preflight result can be cached, default
+	// is 5, -1 disables the caching.
+	MaxAge *int `json:"maxAge,omitempty" yaml:"maxAge,omitempty"`
+	// AllowCredentials allows the requests with credentials (cookies,
+	// authorization headers or TLS client certificates).
+	AllowCredentials bool `json:"allowCredentials,omitempty" yaml:"allowCredentials,omitempty"`
+}
+
+// ApisixRouteHTTPForwardAuth delegates the authorization of a route rule
+// to an external service, requests are proxied only if it responds 2xx.
+type ApisixRouteHTTPForwardAuth struct {
+	// URI is the URL of the authorization service.
+	URI string `json:"uri" yaml:"uri"`
+	// SSLVerify indicates whether to verify the certificate of the
+	// authorization service, default is true.
+	SSLVerify *bool `json:"sslVerify,omitempty" yaml:"sslVerify,omitempty"`
+	// RequestHeaders are the client request headers sent to the
+	// authorization service.
+	RequestHeaders []string `json:"requestHeaders,omitempty" yaml:"requestHeaders,omitempty"`
+	// UpstreamHeaders are the authorization service response headers
+	// sent to the upstream if the request is allowed.
+	UpstreamHeaders []string `json:"upstreamHeaders,omitempty" yaml:"upstreamHeaders,omitempty"`
+	// ClientHeaders are the authorization service response headers sent
+	// to the client if the request is rejected.
+	ClientHeaders []string `json:"clientHeaders,omitempty" yaml:"clientHeaders,omitempty"`
+}
+
+// ApisixRouteHTTPBodyTransformer transforms the bodies of a route rule,
+// at least one of Request and Response should be specified.
+type ApisixRouteHTTPBodyTransformer struct {
+	// ConfigMapName is the name of the ConfigMap which stores the
+	// templates, it should be in the same namespace as the ApisixRoute.
+	ConfigMapName string `json:"configMapName" yaml:"configMapName"`
+	// Request transforms the request body.
+	Request *ApisixRouteHTTPBodyTransformerTemplate `json:"request,omitempty" yaml:"request,omitempty"`
+	// Response transforms the response body.
+	Response *ApisixRouteHTTPBodyTransformerTemplate `json:"response,omitempty" yaml:"response,omitempty"`
+}
+
+// ApisixRouteHTTPBodyTransformerTemplate references a body-transformer
+// template in the ConfigMap.
+type ApisixRouteHTTPBodyTransformerTemplate struct {
+	// TemplateKey is the ConfigMap key whose value is the template.
+	TemplateKey string `json:"templateKey" yaml:"templateKey"`
+	// InputFormat is the format of the body, e.g. "json" or "xml", it's
+	// decided by the Content-Type header if not specified.
+	InputFormat string `json:"inputFormat,omitempty" yaml:"inputFormat,omitempty"`
+}
+
+// ApisixRouteHTTPLimitCount limits the number of requests of a route rule.
+type ApisixRouteHTTPLimitCount struct {
+	// Count is the maximum number of requests in the time window.
+	Count int `json:"count" yaml:"count"`
+	// TimeWindow is the length of the time window in seconds.
+	TimeWindow int `json:"timeWindow" yaml:"timeWindow"`
+	// Key is the variable to count the requests by, default is
+	// "remote_addr".
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+	// RejectedCode is the status code of the rejected requests, default
+	// is 503.
+	RejectedCode int `json:"rejectedCode,omitempty" yaml:"rejectedCode,omitempty"`
+	// PolicyRef is the name of an ApisixRateLimitPolicy in the same
+	// namespace, whose counter store is shared by all the APISIX nodes.
+	// The counters are local to each node without it.
+	PolicyRef string `json:"policyRef,omitempty" yaml:"policyRef,omitempty"`
+}
+
+// ApisixRouteHTTPIPRestriction restricts the client addresses of a route
+// rule, only one of Allowlist and Denylist can be specified.
+type ApisixRouteHTTPIPRestriction struct {
+	// Allowlist are the IPs or CIDRs allowed to access the route, the
+	// others are rejected.
+	Allowlist []string `json:"allowlist,omitempty" yaml:"allowlist,omitempty"`
+	// Denylist are the IPs or CIDRs rejected.
+	Denylist []string `json:"denylist,omitempty" yaml:"denylist,omitempty"`
+}
+
+// ApisixRouteHTTPPluginsFrom references the plugins stored in a ConfigMap.
+type ApisixRouteHTTPPluginsFrom struct {
+	// ConfigMapName is the name of the ConfigMap, it should be in the same
+	// namespace as the ApisixRoute.
+	ConfigMapName string `json:"configMapName" yaml:"configMapName"`
+	// Key is the ConfigMap key whose value is a JSON object mapping plugin
+	// names to their configurations, default is "plugins.json".
+	Key string `json:"key,omitempty" yaml:"key,omitempty"`
+}
+
+// ApisixRouteHTTPCanary represents the canary release of a route rule.
+// Rules are evaluated in order, the first one whose conditions are met
+// takes effect, requests matching no rules are proxied to the Backends.
+type ApisixRouteHTTPCanary struct {
+	Rules []ApisixRouteHTTPCanaryRule `json:"rules" yaml:"rules"`
+}
+
+// ApisixRouteHTTPCanaryRule splits the matched requests between the
+// canary backends and the stable backend by weights.
+type ApisixRouteHTTPCanaryRule struct {
+	// Exprs are the match conditions, all of them should be met. A rule
+	// without conditions applies to all requests, so it's weight based only.
+	Exprs []ApisixRouteHTTPMatchExpr `json:"exprs,omitempty" yaml:"exprs,omitempty"`
+	// Backends are the canary backends, the weight defaults to 100.
+	Backends []ApisixRouteHTTPBackend `json:"backends" yaml:"backends"`
+	// StableWeight is the weight of the stable backend (the first one of
+	// the route rule backends), default is 0.
+	StableWeight int `json:"stableWeight,omitempty" yaml:"stableWeight,omitempty"`
+}
+
+// ApisixRouteHTTPRewrite rewrites the request path with a regex.
+type ApisixRouteHTTPRewrite struct {
+	// Regex is the regular expression to match the request path.
+	Regex string `json:"regex" yaml:"regex"`
+	// Replacement is the new path, capture groups of the Regex can be
+	// referenced by $N or ${N} (e.g. /new/$1), $$ is a literal $.
+	Replacement string `json:"replacement" yaml:"replacement"`
+}
+
+// IsEnabled reports whether the route rule should be pushed to APISIX.
+func (r *ApisixRouteHTTP) IsEnabled() bool {
+	return r.Enable == nil || *r.Enable
+}
+
+// ApisixRouteHTTPMethodBackend proxies the requests with the given methods
+// to a specific backend.
+type ApisixRouteHTTPMethodBackend struct {
+	// Methods are the HTTP request methods (e.g. GET, POST) to match.
+	Methods []string `json:"methods" yaml:"methods"`
+	// Backend is the backend to proxy, weight is ignored.
+	Backend ApisixRouteHTTPBackend `json:"backend" yaml:"backend"`
+}
+
+// ApisixRouteHTTPFileLogger is the file-logger plugin configuration
+// in ApisixRoute.
+type ApisixRouteHTTPFileLogger struct {
+	// Path is the absolute path of the log file, it must be writable
+	// by APISIX.
+	Path string `json:"path" yaml:"path"`
+	// LogFormat specifies the log format, keys are the field names in
+	// the log entry, values are the nginx variables (prefixed with $)
+	// or constant strings.
+	LogFormat map[string]string `json:"logFormat,omitempty" yaml:"logFormat,omitempty"`
 }
 
 // ApisixRouteHTTPBackend represents a HTTP backend (a Kuberentes Service).
@@ -99,8 +379,12 @@ type ApisixRouteHTTPMatch struct {
 	// configured, path could be exact or prefix, for prefix path,
 	// append "*" after it, for instance, "/foo*".
 	Paths []string `json:"paths" yaml:"paths"`
-	// HTTP request method predicates.
+	// HTTP request method predicates, "*" or "ALL" means any method.
 	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// NotMethods are the HTTP request methods which are not matched, it
+	// can't be used together with Methods.
+	// +optional
+	NotMethods []string `json:"notMethods,omitempty" yaml:"notMethods,omitempty"`
 	// HTTP Host predicates, host can be a wildcard domain or
 	// an exact domain. For wildcard domain, only one generic
 	// level is allowed, for instance, "*.foo.com" is valid but
@@ -121,6 +405,22 @@ type ApisixRouteHTTPMatch struct {
 	//       - "127.0.0.1"
 	//       - "10.0.5.11"
 	NginxVars []ApisixRouteHTTPMatchExpr `json:"exprs,omitempty" yaml:"exprs,omitempty"`
+	// GRPC matches the gRPC requests by the full method instead of the
+	// paths, the upstream should use the grpc or grpcs scheme.
+	// +optional
+	GRPC *ApisixRouteHTTPMatchGRPC `json:"grpc,omitempty" yaml:"grpc,omitempty"`
+}
+
+// ApisixRouteHTTPMatchGRPC matches the gRPC requests, the route paths are
+// the gRPC full methods like "/helloworld.Greeter/SayHello".
+type ApisixRouteHTTPMatchGRPC struct {
+	// Service is the fully qualified gRPC service name, like
+	// "helloworld.Greeter".
+	Service string `json:"service" yaml:"service"`
+	// Methods are the gRPC methods of the service, all methods of the
+	// service are matched if it's empty.
+	// +optional
+	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
 }
 
 // ApisixRouteHTTPMatchExpr represents a binary route match expression .
@@ -160,6 +460,13 @@ type ApisixRouteHTTPPlugin struct {
 	Enable bool `json:"enable" yaml:"enable"`
 	// Plugin configuration.
 	Config ApisixRouteHTTPPluginConfig `json:"config" yaml:"config"`
+	// Priority overrides the default execution priority of this plugin,
+	// plugins with higher priority run earlier. It's translated to the
+	// _meta.priority field in APISIX.
+	Priority *int64 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// Filter runs the plugin only on the requests matching all the
+	// expressions. It's translated to the _meta.filter field in APISIX.
+	Filter []ApisixRouteHTTPMatchExpr `json:"filter,omitempty" yaml:"filter,omitempty"`
 }
 
 // ApisixRouteHTTPPluginConfig is the configuration for
@@ -271,6 +578,12 @@ type ApisixClusterConfigSpec struct {
 	// Admin contains the Admin API information about APISIX cluster.
 	// +optional
 	Admin *ApisixClusterAdminConfig `json:"admin" yaml:"admin"`
+	// PluginMetadata is the plugin_metadata of the APISIX Cluster, keyed
+	// by the plugin name. The metadata of each plugin should be a JSON
+	// object, it's removed from the APISIX Cluster once the entry is
+	// removed or the ApisixClusterConfig is deleted.
+	// +optional
+	PluginMetadata map[string]Config `json:"pluginMetadata,omitempty" yaml:"pluginMetadata,omitempty"`
 }
 
 // ApisixClusterMonitoringConfig categories all monitoring related features.
@@ -281,12 +594,21 @@ type ApisixClusterMonitoringConfig struct {
 	// Skywalking is the config for using Skywalking in APISIX Cluster.
 	// +optional
 	Skywalking ApisixClusterSkywalkingConfig `json:"skywalking" yaml:"skywalking"`
+	// Tracing enables a tracing plugin on all the routes of the APISIX
+	// Cluster, the ApisixRoutes with the disable-tracing annotation are
+	// excluded.
+	// +optional
+	Tracing *ApisixClusterTracingConfig `json:"tracing,omitempty" yaml:"tracing,omitempty"`
 }
 
 // ApisixClusterPrometheusConfig is the config for using Prometheus in APISIX Cluster.
 type ApisixClusterPrometheusConfig struct {
 	// Enable means whether enable Prometheus or not.
 	Enable bool `json:"enable" yaml:"enable"`
+	// PreferName means whether the route name instead of the route id is
+	// used as the route label of the metrics.
+	// +optional
+	PreferName bool `json:"preferName,omitempty" yaml:"preferName,omitempty"`
 }
 
 // ApisixClusterSkywalkingConfig is the config for using Skywalking in APISIX Cluster.
@@ -297,6 +619,24 @@ type ApisixClusterSkywalkingConfig struct {
 	SampleRatio float64 `json:"sampleRatio" yaml:"sampleRatio"`
 }
 
+// ApisixClusterTracingConfig is the config for tracing in APISIX Cluster.
+type ApisixClusterTracingConfig struct {
+	// Plugin is the tracing plugin, "zipkin" or "opentelemetry".
+	Plugin string `json:"plugin" yaml:"plugin"`
+	// Endpoint is the URL of the collector which the spans are reported
+	// to, e.g. "http://zipkin:9411/api/v2/spans". It's only used by the
+	// zipkin plugin, the collector of the opentelemetry plugin is set in
+	// the plugin_attr of APISIX.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+	// SampleRatio is the ratio of the sampled requests, in (0, 1], the
+	// default is 1.
+	SampleRatio float64 `json:"sampleRatio,omitempty" yaml:"sampleRatio,omitempty"`
+	// ServiceName is the service name in the spans, "${cluster}" is
+	// replaced with the name of the APISIX Cluster. It's only used by the
+	// zipkin plugin.
+	ServiceName string `json:"serviceName,omitempty" yaml:"serviceName,omitempty"`
+}
+
 // ApisixClusterAdminConfig is the admin config for the corresponding APISIX Cluster.
 type ApisixClusterAdminConfig struct {
 	// BaseURL is the base URL for the APISIX Admin API.
@@ -445,6 +785,69 @@ type ApisixUpstreamSpec struct {
 	ApisixUpstreamConfig `json:",inline" yaml:",inline"`
 
 	PortLevelSettings []PortLevelSettings `json:"portLevelSettings,omitempty" yaml:"portLevelSettings,omitempty"`
+
+	// Services aggregates the ready endpoints of the listed Services,
+	// possibly in other namespaces, as the upstream nodes instead of the
+	// endpoints of the Service with the same name as the ApisixUpstream.
+	// +optional
+	Services []ApisixUpstreamServiceRef `json:"services,omitempty" yaml:"services,omitempty"`
+
+	// Discovery resolves the upstream nodes with the service discovery of
+	// APISIX instead of the endpoints of the Service, the Service is only
+	// used to declare the ports.
+	// +optional
+	Discovery *ApisixUpstreamDiscovery `json:"discovery,omitempty" yaml:"discovery,omitempty"`
+
+	// ExternalNodes are the static upstream nodes, e.g. the backends out of
+	// the Kubernetes cluster. The endpoints are not used at all, and the
+	// Service with the same name is not required.
+	// +optional
+	ExternalNodes []ApisixUpstreamExternalNode `json:"externalNodes,omitempty" yaml:"externalNodes,omitempty"`
+}
+
+// ApisixUpstreamExternalNode is a static upstream node.
+type ApisixUpstreamExternalNode struct {
+	// Host is the IP address or the domain name of the node.
+	Host string `json:"host" yaml:"host"`
+	// Port is the port of the node, default is the same port as the
+	// upstream.
+	// +optional
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Weight is the weight of the node, default is 100.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// ApisixUpstreamDiscovery is the service discovery of APISIX which resolves
+// the upstream nodes.
+type ApisixUpstreamDiscovery struct {
+	// Type is the discovery type, like "dns", "consul" or "nacos", it should
+	// be enabled in APISIX.
+	Type string `json:"type" yaml:"type"`
+	// ServiceName is the name of the service in the discovery.
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// Args are the arguments of the discovery, like "namespace_id" and
+	// "group_name" of nacos.
+	// +optional
+	Args map[string]string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// ApisixUpstreamServiceRef references a Service whose endpoints are
+// aggregated into the upstream.
+type ApisixUpstreamServiceRef struct {
+	// Namespace is the namespace of the Service, default is the namespace
+	// of the ApisixUpstream.
+	// +optional
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Name is the name of the Service.
+	Name string `json:"name" yaml:"name"`
+	// Port is the port of the Service, default is the same port as the
+	// upstream.
+	// +optional
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Weight is the weight of the nodes from the Service, default is 100.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // ApisixUpstreamConfig contains rich features on APISIX Upstream, for instance
@@ -455,39 +858,83 @@ type ApisixUpstreamConfig struct {
 	// +optional
 	LoadBalancer *LoadBalancer `json:"loadbalancer,omitempty" yaml:"loadbalancer,omitempty"`
 	// The scheme used to talk with the upstream.
-	// Now value can be http, grpc.
+	// Now value can be http, https, grpc, grpcs.
 	// +optional
 	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
 
+	// PassHost configures the Host header passed to the upstream, can be
+	// "pass" (the client request Host), "node" (the endpoint address) or
+	// "rewrite" (the UpstreamHost), default is "pass".
+	// +optional
+	PassHost string `json:"passHost,omitempty" yaml:"passHost,omitempty"`
+	// UpstreamHost is the Host header passed to the upstream, it's required
+	// when PassHost is "rewrite", and forbidden otherwise.
+	// +optional
+	UpstreamHost string `json:"upstreamHost,omitempty" yaml:"upstreamHost,omitempty"`
+
 	// How many times that the proxy (Apache APISIX) should do when
 	// errors occur (error, timeout or bad http status codes like 500, 502).
 	// +optional
 	Retries *int `json:"retries,omitempty" yaml:"retries,omitempty"`
 
+	// RetryTimeout limits the total time that the proxy (Apache APISIX)
+	// can spend on retries, zero means no limit.
+	// +optional
+	RetryTimeout *metav1.Duration `json:"retryTimeout,omitempty" yaml:"retryTimeout,omitempty"`
+
 	// Timeout settings for the read, send and connect to the upstream.
 	// +optional
 	Timeout *UpstreamTimeout `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 
+	// KeepalivePool configures the keepalive connection pool to the upstream,
+	// the APISIX default is used if it's not set.
+	// +optional
+	KeepalivePool *UpstreamKeepalivePool `json:"keepalivePool,omitempty" yaml:"keepalivePool,omitempty"`
+
 	// The health check configurations for the upstream.
 	// +optional
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
 
-	// Set the client certificate when connecting to TLS upstream.
+	// Set the client certificate when connecting to TLS upstream, it can only
+	// be used with the https and grpcs schemes.
 	// +optional
 	TLSSecret *ApisixSecret `json:"tlsSecret,omitempty" yaml:"tlsSecret,omitempty"`
 
+	// TLS configures the TLS connection to the upstream, it can only be
+	// used with the https and grpcs schemes.
+	// +optional
+	TLS *ApisixUpstreamTLS `json:"tls,omitempty" yaml:"tls,omitempty"`
+
 	// Subsets groups the service endpoints by their labels. Usually used to differentiate
 	// service versions.
 	// +optional
 	Subsets []ApisixUpstreamSubset `json:"subsets,omitempty" yaml:"subsets,omitempty"`
 }
 
+// ApisixUpstreamTLS is the TLS settings of the connection to the upstream.
+type ApisixUpstreamTLS struct {
+	// Verify indicates whether to verify the certificate of the upstream,
+	// default is true. Only disable it for the backends with self-signed
+	// certificates.
+	// +optional
+	Verify *bool `json:"verify,omitempty" yaml:"verify,omitempty"`
+}
+
 // ApisixUpstreamSubset defines a single endpoints group of one Service.
 type ApisixUpstreamSubset struct {
 	// Name is the name of subset.
 	Name string `json:"name" yaml:"name"`
 	// Labels is the label set of this subset.
-	Labels map[string]string `json:"labels" yaml:"labels"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Selector selects the endpoints whose target pods match it, it's
+	// used together with Labels if both are specified.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Weight is the traffic weight of this subset, when weights are set,
+	// routes referencing the Service without a subset will split the
+	// traffic across the weighted subsets.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // PortLevelSettings configures the ApisixUpstreamConfig for each individual port. It inherits
@@ -647,15 +1094,20 @@ type HostType string
 
 // ApisixTlsSpec is the specification of ApisixSSL.
 type ApisixTlsSpec struct {
-	// +required
-	// +kubebuilder:validation:Required
+	// Hosts can be omitted if AutoSNI is enabled.
+	// +optional
 	// +kubebuilder:validation:MinItems=1
-	Hosts []HostType `json:"hosts" yaml:"hosts,omitempty"`
+	Hosts []HostType `json:"hosts,omitempty" yaml:"hosts,omitempty"`
 	// +required
 	// +kubebuilder:validation:Required
 	Secret ApisixSecret `json:"secret" yaml:"secret"`
 	// +optional
 	Client *ApisixMutualTlsClientConfig `json:"client,omitempty" yaml:"client,omitempty"`
+	// AutoSNI registers the DNS names in the SAN list of the certificate
+	// as SNIs besides the Hosts, the ones listed in the Hosts of other
+	// ApisixTls are skipped.
+	// +optional
+	AutoSNI bool `json:"autoSNI,omitempty" yaml:"autoSNI,omitempty"`
 }
 
 // ApisixSecret describes the Kubernetes Secret name and namespace.
@@ -705,3 +1157,66 @@ type ApisixPluginConfigList struct {
 	metav1.ListMeta `json:"metadata" yaml:"metadata"`
 	Items           []ApisixPluginConfig `json:"items,omitempty" yaml:"items,omitempty"`
 }
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRateLimitPolicy is the Schema for the ApisixRateLimitPolicy resource.
+// It describes a shared counter store (e.g. Redis) once, so that the
+// rate limiting of ApisixRoutes can reference it.
+type ApisixRateLimitPolicy struct {
+	metav1.TypeMeta   `json:",inline" yaml:",inline"`
+	metav1.ObjectMeta `json:"metadata" yaml:"metadata"`
+
+	Spec ApisixRateLimitPolicySpec `json:"spec" yaml:"spec"`
+}
+
+// ApisixRateLimitPolicySpec defines the counter store of rate limiting.
+type ApisixRateLimitPolicySpec struct {
+	// Policy is the counter store, "redis" or "redis-cluster", default
+	// is "redis".
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	// Redis is the Redis server for the "redis" policy.
+	Redis *ApisixRateLimitPolicyRedis `json:"redis,omitempty" yaml:"redis,omitempty"`
+	// RedisCluster is the Redis cluster for the "redis-cluster" policy.
+	RedisCluster *ApisixRateLimitPolicyRedisCluster `json:"redisCluster,omitempty" yaml:"redisCluster,omitempty"`
+}
+
+// ApisixRateLimitPolicyRedis is a Redis server.
+type ApisixRateLimitPolicyRedis struct {
+	Host string `json:"host" yaml:"host"`
+	// Port defaults to 6379.
+	Port     int `json:"port,omitempty" yaml:"port,omitempty"`
+	Database int `json:"database,omitempty" yaml:"database,omitempty"`
+	// Timeout is the timeout of Redis operations in milliseconds,
+	// default is 1000.
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// PasswordSecretRef references a Secret in the same namespace, whose
+	// "password" key is the Redis password.
+	PasswordSecretRef *corev1.LocalObjectReference `json:"passwordSecretRef,omitempty" yaml:"passwordSecretRef,omitempty"`
+}
+
+// ApisixRateLimitPolicyRedisCluster is a Redis cluster.
+type ApisixRateLimitPolicyRedisCluster struct {
+	// Nodes are the addresses (host:port) of the cluster nodes.
+	Nodes []string `json:"nodes" yaml:"nodes"`
+	// Name is the name of the cluster, it should be unique among the
+	// clusters used by APISIX.
+	Name string `json:"name" yaml:"name"`
+	// Timeout is the timeout of Redis operations in milliseconds,
+	// default is 1000.
+	Timeout int `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// PasswordSecretRef references a Secret in the same namespace, whose
+	// "password" key is the Redis password.
+	PasswordSecretRef *corev1.LocalObjectReference `json:"passwordSecretRef,omitempty" yaml:"passwordSecretRef,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ApisixRateLimitPolicyList contains a list of ApisixRateLimitPolicy.
+type ApisixRateLimitPolicyList struct {
+	metav1.TypeMeta `json:",inline" yaml:",inline"`
+	metav1.ListMeta `json:"metadata" yaml:"metadata"`
+	Items           []ApisixRateLimitPolicy `json:"items,omitempty" yaml:"items,omitempty"`
+}
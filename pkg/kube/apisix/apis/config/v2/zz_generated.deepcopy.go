@@ -182,13 +182,20 @@ func (in *ApisixClusterConfigSpec) DeepCopyInto(out *ApisixClusterConfigSpec) {
 	if in.Monitoring != nil {
 		in, out := &in.Monitoring, &out.Monitoring
 		*out = new(ApisixClusterMonitoringConfig)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Admin != nil {
 		in, out := &in.Admin, &out.Admin
 		*out = new(ApisixClusterAdminConfig)
 		**out = **in
 	}
+	if in.PluginMetadata != nil {
+		in, out := &in.PluginMetadata, &out.PluginMetadata
+		*out = make(map[string]Config, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 	return
 }
 
@@ -207,6 +214,11 @@ func (in *ApisixClusterMonitoringConfig) DeepCopyInto(out *ApisixClusterMonitori
 	*out = *in
 	out.Prometheus = in.Prometheus
 	out.Skywalking = in.Skywalking
+	if in.Tracing != nil {
+		in, out := &in.Tracing, &out.Tracing
+		*out = new(ApisixClusterTracingConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -252,6 +264,22 @@ func (in *ApisixClusterSkywalkingConfig) DeepCopy() *ApisixClusterSkywalkingConf
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixClusterTracingConfig) DeepCopyInto(out *ApisixClusterTracingConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixClusterTracingConfig.
+func (in *ApisixClusterTracingConfig) DeepCopy() *ApisixClusterTracingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixClusterTracingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixConsumer) DeepCopyInto(out *ApisixConsumer) {
 	*out = *in
@@ -687,6 +715,139 @@ func (in *ApisixPluginConfigSpec) DeepCopy() *ApisixPluginConfigSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRateLimitPolicy) DeepCopyInto(out *ApisixRateLimitPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRateLimitPolicy.
+func (in *ApisixRateLimitPolicy) DeepCopy() *ApisixRateLimitPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRateLimitPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApisixRateLimitPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRateLimitPolicyList) DeepCopyInto(out *ApisixRateLimitPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApisixRateLimitPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRateLimitPolicyList.
+func (in *ApisixRateLimitPolicyList) DeepCopy() *ApisixRateLimitPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRateLimitPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ApisixRateLimitPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRateLimitPolicyRedis) DeepCopyInto(out *ApisixRateLimitPolicyRedis) {
+	*out = *in
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRateLimitPolicyRedis.
+func (in *ApisixRateLimitPolicyRedis) DeepCopy() *ApisixRateLimitPolicyRedis {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRateLimitPolicyRedis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRateLimitPolicyRedisCluster) DeepCopyInto(out *ApisixRateLimitPolicyRedisCluster) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PasswordSecretRef != nil {
+		in, out := &in.PasswordSecretRef, &out.PasswordSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRateLimitPolicyRedisCluster.
+func (in *ApisixRateLimitPolicyRedisCluster) DeepCopy() *ApisixRateLimitPolicyRedisCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRateLimitPolicyRedisCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRateLimitPolicySpec) DeepCopyInto(out *ApisixRateLimitPolicySpec) {
+	*out = *in
+	if in.Redis != nil {
+		in, out := &in.Redis, &out.Redis
+		*out = new(ApisixRateLimitPolicyRedis)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RedisCluster != nil {
+		in, out := &in.RedisCluster, &out.RedisCluster
+		*out = new(ApisixRateLimitPolicyRedisCluster)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRateLimitPolicySpec.
+func (in *ApisixRateLimitPolicySpec) DeepCopy() *ApisixRateLimitPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRateLimitPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixRoute) DeepCopyInto(out *ApisixRoute) {
 	*out = *in
@@ -768,6 +929,11 @@ func (in *ApisixRouteAuthenticationKeyAuth) DeepCopy() *ApisixRouteAuthenticatio
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixRouteHTTP) DeepCopyInto(out *ApisixRouteHTTP) {
 	*out = *in
+	if in.Enable != nil {
+		in, out := &in.Enable, &out.Enable
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(UpstreamTimeout)
@@ -788,7 +954,74 @@ func (in *ApisixRouteHTTP) DeepCopyInto(out *ApisixRouteHTTP) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PluginsFrom != nil {
+		in, out := &in.PluginsFrom, &out.PluginsFrom
+		*out = new(ApisixRouteHTTPPluginsFrom)
+		**out = **in
+	}
 	out.Authentication = in.Authentication
+	if in.FileLogger != nil {
+		in, out := &in.FileLogger, &out.FileLogger
+		*out = new(ApisixRouteHTTPFileLogger)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MethodBackends != nil {
+		in, out := &in.MethodBackends, &out.MethodBackends
+		*out = make([]ApisixRouteHTTPMethodBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rewrite != nil {
+		in, out := &in.Rewrite, &out.Rewrite
+		*out = new(ApisixRouteHTTPRewrite)
+		**out = **in
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(ApisixRouteHTTPCanary)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPRestriction != nil {
+		in, out := &in.IPRestriction, &out.IPRestriction
+		*out = new(ApisixRouteHTTPIPRestriction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitCount != nil {
+		in, out := &in.LimitCount, &out.LimitCount
+		*out = new(ApisixRouteHTTPLimitCount)
+		**out = **in
+	}
+	if in.BodyTransformer != nil {
+		in, out := &in.BodyTransformer, &out.BodyTransformer
+		*out = new(ApisixRouteHTTPBodyTransformer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForwardAuth != nil {
+		in, out := &in.ForwardAuth, &out.ForwardAuth
+		*out = new(ApisixRouteHTTPForwardAuth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cors != nil {
+		in, out := &in.Cors, &out.Cors
+		*out = new(ApisixRouteHTTPCors)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ResponseRewrite != nil {
+		in, out := &in.ResponseRewrite, &out.ResponseRewrite
+		*out = new(ApisixRouteHTTPResponseRewrite)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RequestID != nil {
+		in, out := &in.RequestID, &out.RequestID
+		*out = new(ApisixRouteHTTPRequestID)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Serverless != nil {
+		in, out := &in.Serverless, &out.Serverless
+		*out = new(ApisixRouteHTTPServerless)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -825,129 +1058,587 @@ func (in *ApisixRouteHTTPBackend) DeepCopy() *ApisixRouteHTTPBackend {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApisixRouteHTTPMatch) DeepCopyInto(out *ApisixRouteHTTPMatch) {
+func (in *ApisixRouteHTTPBodyTransformer) DeepCopyInto(out *ApisixRouteHTTPBodyTransformer) {
 	*out = *in
-	if in.Paths != nil {
-		in, out := &in.Paths, &out.Paths
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Methods != nil {
-		in, out := &in.Methods, &out.Methods
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.Hosts != nil {
-		in, out := &in.Hosts, &out.Hosts
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.RemoteAddrs != nil {
-		in, out := &in.RemoteAddrs, &out.RemoteAddrs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(ApisixRouteHTTPBodyTransformerTemplate)
+		**out = **in
 	}
-	if in.NginxVars != nil {
-		in, out := &in.NginxVars, &out.NginxVars
-		*out = make([]ApisixRouteHTTPMatchExpr, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(ApisixRouteHTTPBodyTransformerTemplate)
+		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatch.
-func (in *ApisixRouteHTTPMatch) DeepCopy() *ApisixRouteHTTPMatch {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPBodyTransformer.
+func (in *ApisixRouteHTTPBodyTransformer) DeepCopy() *ApisixRouteHTTPBodyTransformer {
 	if in == nil {
 		return nil
 	}
-	out := new(ApisixRouteHTTPMatch)
+	out := new(ApisixRouteHTTPBodyTransformer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApisixRouteHTTPMatchExpr) DeepCopyInto(out *ApisixRouteHTTPMatchExpr) {
+func (in *ApisixRouteHTTPBodyTransformerTemplate) DeepCopyInto(out *ApisixRouteHTTPBodyTransformerTemplate) {
 	*out = *in
-	out.Subject = in.Subject
-	if in.Set != nil {
-		in, out := &in.Set, &out.Set
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPBodyTransformerTemplate.
+func (in *ApisixRouteHTTPBodyTransformerTemplate) DeepCopy() *ApisixRouteHTTPBodyTransformerTemplate {
+	if in == nil {
+		return nil
 	}
-	if in.Value != nil {
-		in, out := &in.Value, &out.Value
-		*out = new(string)
-		**out = **in
+	out := new(ApisixRouteHTTPBodyTransformerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPCanary) DeepCopyInto(out *ApisixRouteHTTPCanary) {
+	*out = *in
+	if in.Rules != nil {
+		in, out := &in.Rules, &out.Rules
+		*out = make([]ApisixRouteHTTPCanaryRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatchExpr.
-func (in *ApisixRouteHTTPMatchExpr) DeepCopy() *ApisixRouteHTTPMatchExpr {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPCanary.
+func (in *ApisixRouteHTTPCanary) DeepCopy() *ApisixRouteHTTPCanary {
 	if in == nil {
 		return nil
 	}
-	out := new(ApisixRouteHTTPMatchExpr)
+	out := new(ApisixRouteHTTPCanary)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApisixRouteHTTPMatchExprSubject) DeepCopyInto(out *ApisixRouteHTTPMatchExprSubject) {
+func (in *ApisixRouteHTTPCanaryRule) DeepCopyInto(out *ApisixRouteHTTPCanaryRule) {
 	*out = *in
+	if in.Exprs != nil {
+		in, out := &in.Exprs, &out.Exprs
+		*out = make([]ApisixRouteHTTPMatchExpr, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Backends != nil {
+		in, out := &in.Backends, &out.Backends
+		*out = make([]ApisixRouteHTTPBackend, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatchExprSubject.
-func (in *ApisixRouteHTTPMatchExprSubject) DeepCopy() *ApisixRouteHTTPMatchExprSubject {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPCanaryRule.
+func (in *ApisixRouteHTTPCanaryRule) DeepCopy() *ApisixRouteHTTPCanaryRule {
 	if in == nil {
 		return nil
 	}
-	out := new(ApisixRouteHTTPMatchExprSubject)
+	out := new(ApisixRouteHTTPCanaryRule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApisixRouteHTTPPlugin) DeepCopyInto(out *ApisixRouteHTTPPlugin) {
+func (in *ApisixRouteHTTPCors) DeepCopyInto(out *ApisixRouteHTTPCors) {
 	*out = *in
-	in.Config.DeepCopyInto(&out.Config)
+	if in.AllowOrigins != nil {
+		in, out := &in.AllowOrigins, &out.AllowOrigins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowMethods != nil {
+		in, out := &in.AllowMethods, &out.AllowMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowHeaders != nil {
+		in, out := &in.AllowHeaders, &out.AllowHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExposeHeaders != nil {
+		in, out := &in.ExposeHeaders, &out.ExposeHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPPlugin.
-func (in *ApisixRouteHTTPPlugin) DeepCopy() *ApisixRouteHTTPPlugin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPCors.
+func (in *ApisixRouteHTTPCors) DeepCopy() *ApisixRouteHTTPCors {
 	if in == nil {
 		return nil
 	}
-	out := new(ApisixRouteHTTPPlugin)
+	out := new(ApisixRouteHTTPCors)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ApisixRouteList) DeepCopyInto(out *ApisixRouteList) {
+func (in *ApisixRouteHTTPFileLogger) DeepCopyInto(out *ApisixRouteHTTPFileLogger) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]ApisixRoute, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.LogFormat != nil {
+		in, out := &in.LogFormat, &out.LogFormat
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteList.
-func (in *ApisixRouteList) DeepCopy() *ApisixRouteList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPFileLogger.
+func (in *ApisixRouteHTTPFileLogger) DeepCopy() *ApisixRouteHTTPFileLogger {
 	if in == nil {
 		return nil
 	}
-	out := new(ApisixRouteList)
+	out := new(ApisixRouteHTTPFileLogger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPForwardAuth) DeepCopyInto(out *ApisixRouteHTTPForwardAuth) {
+	*out = *in
+	if in.SSLVerify != nil {
+		in, out := &in.SSLVerify, &out.SSLVerify
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RequestHeaders != nil {
+		in, out := &in.RequestHeaders, &out.RequestHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UpstreamHeaders != nil {
+		in, out := &in.UpstreamHeaders, &out.UpstreamHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ClientHeaders != nil {
+		in, out := &in.ClientHeaders, &out.ClientHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPForwardAuth.
+func (in *ApisixRouteHTTPForwardAuth) DeepCopy() *ApisixRouteHTTPForwardAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPForwardAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPIPRestriction) DeepCopyInto(out *ApisixRouteHTTPIPRestriction) {
+	*out = *in
+	if in.Allowlist != nil {
+		in, out := &in.Allowlist, &out.Allowlist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Denylist != nil {
+		in, out := &in.Denylist, &out.Denylist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPIPRestriction.
+func (in *ApisixRouteHTTPIPRestriction) DeepCopy() *ApisixRouteHTTPIPRestriction {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPIPRestriction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPLimitCount) DeepCopyInto(out *ApisixRouteHTTPLimitCount) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPLimitCount.
+func (in *ApisixRouteHTTPLimitCount) DeepCopy() *ApisixRouteHTTPLimitCount {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPLimitCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPMatch) DeepCopyInto(out *ApisixRouteHTTPMatch) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NotMethods != nil {
+		in, out := &in.NotMethods, &out.NotMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RemoteAddrs != nil {
+		in, out := &in.RemoteAddrs, &out.RemoteAddrs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NginxVars != nil {
+		in, out := &in.NginxVars, &out.NginxVars
+		*out = make([]ApisixRouteHTTPMatchExpr, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.GRPC != nil {
+		in, out := &in.GRPC, &out.GRPC
+		*out = new(ApisixRouteHTTPMatchGRPC)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatch.
+func (in *ApisixRouteHTTPMatch) DeepCopy() *ApisixRouteHTTPMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPMatchExpr) DeepCopyInto(out *ApisixRouteHTTPMatchExpr) {
+	*out = *in
+	out.Subject = in.Subject
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatchExpr.
+func (in *ApisixRouteHTTPMatchExpr) DeepCopy() *ApisixRouteHTTPMatchExpr {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPMatchExpr)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPMatchExprSubject) DeepCopyInto(out *ApisixRouteHTTPMatchExprSubject) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatchExprSubject.
+func (in *ApisixRouteHTTPMatchExprSubject) DeepCopy() *ApisixRouteHTTPMatchExprSubject {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPMatchExprSubject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPMatchGRPC) DeepCopyInto(out *ApisixRouteHTTPMatchGRPC) {
+	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMatchGRPC.
+func (in *ApisixRouteHTTPMatchGRPC) DeepCopy() *ApisixRouteHTTPMatchGRPC {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPMatchGRPC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPMethodBackend) DeepCopyInto(out *ApisixRouteHTTPMethodBackend) {
+	*out = *in
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Backend.DeepCopyInto(&out.Backend)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPMethodBackend.
+func (in *ApisixRouteHTTPMethodBackend) DeepCopy() *ApisixRouteHTTPMethodBackend {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPMethodBackend)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPPlugin) DeepCopyInto(out *ApisixRouteHTTPPlugin) {
+	*out = *in
+	in.Config.DeepCopyInto(&out.Config)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make([]ApisixRouteHTTPMatchExpr, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPPlugin.
+func (in *ApisixRouteHTTPPlugin) DeepCopy() *ApisixRouteHTTPPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPPluginsFrom) DeepCopyInto(out *ApisixRouteHTTPPluginsFrom) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPPluginsFrom.
+func (in *ApisixRouteHTTPPluginsFrom) DeepCopy() *ApisixRouteHTTPPluginsFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPPluginsFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPRequestID) DeepCopyInto(out *ApisixRouteHTTPRequestID) {
+	*out = *in
+	if in.IncludeInResponse != nil {
+		in, out := &in.IncludeInResponse, &out.IncludeInResponse
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPRequestID.
+func (in *ApisixRouteHTTPRequestID) DeepCopy() *ApisixRouteHTTPRequestID {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPRequestID)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPResponseRewrite) DeepCopyInto(out *ApisixRouteHTTPResponseRewrite) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(ApisixRouteHTTPResponseRewriteHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPResponseRewrite.
+func (in *ApisixRouteHTTPResponseRewrite) DeepCopy() *ApisixRouteHTTPResponseRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPResponseRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPResponseRewriteHeaders) DeepCopyInto(out *ApisixRouteHTTPResponseRewriteHeaders) {
+	*out = *in
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Add != nil {
+		in, out := &in.Add, &out.Add
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPResponseRewriteHeaders.
+func (in *ApisixRouteHTTPResponseRewriteHeaders) DeepCopy() *ApisixRouteHTTPResponseRewriteHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPResponseRewriteHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPRewrite) DeepCopyInto(out *ApisixRouteHTTPRewrite) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPRewrite.
+func (in *ApisixRouteHTTPRewrite) DeepCopy() *ApisixRouteHTTPRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPServerless) DeepCopyInto(out *ApisixRouteHTTPServerless) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = new(ApisixRouteHTTPServerlessFunctions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = new(ApisixRouteHTTPServerlessFunctions)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPServerless.
+func (in *ApisixRouteHTTPServerless) DeepCopy() *ApisixRouteHTTPServerless {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPServerless)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteHTTPServerlessFunctions) DeepCopyInto(out *ApisixRouteHTTPServerlessFunctions) {
+	*out = *in
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteHTTPServerlessFunctions.
+func (in *ApisixRouteHTTPServerlessFunctions) DeepCopy() *ApisixRouteHTTPServerlessFunctions {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteHTTPServerlessFunctions)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixRouteList) DeepCopyInto(out *ApisixRouteList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ApisixRoute, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixRouteList.
+func (in *ApisixRouteList) DeepCopy() *ApisixRouteList {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixRouteList)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1215,11 +1906,21 @@ func (in *ApisixUpstreamConfig) DeepCopyInto(out *ApisixUpstreamConfig) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.RetryTimeout != nil {
+		in, out := &in.RetryTimeout, &out.RetryTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(UpstreamTimeout)
 		**out = **in
 	}
+	if in.KeepalivePool != nil {
+		in, out := &in.KeepalivePool, &out.KeepalivePool
+		*out = new(UpstreamKeepalivePool)
+		**out = **in
+	}
 	if in.HealthCheck != nil {
 		in, out := &in.HealthCheck, &out.HealthCheck
 		*out = new(HealthCheck)
@@ -1230,6 +1931,11 @@ func (in *ApisixUpstreamConfig) DeepCopyInto(out *ApisixUpstreamConfig) {
 		*out = new(ApisixSecret)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ApisixUpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Subsets != nil {
 		in, out := &in.Subsets, &out.Subsets
 		*out = make([]ApisixUpstreamSubset, len(*in))
@@ -1250,6 +1956,50 @@ func (in *ApisixUpstreamConfig) DeepCopy() *ApisixUpstreamConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamDiscovery) DeepCopyInto(out *ApisixUpstreamDiscovery) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamDiscovery.
+func (in *ApisixUpstreamDiscovery) DeepCopy() *ApisixUpstreamDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamExternalNode) DeepCopyInto(out *ApisixUpstreamExternalNode) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamExternalNode.
+func (in *ApisixUpstreamExternalNode) DeepCopy() *ApisixUpstreamExternalNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamExternalNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixUpstreamList) DeepCopyInto(out *ApisixUpstreamList) {
 	*out = *in
@@ -1283,6 +2033,27 @@ func (in *ApisixUpstreamList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamServiceRef) DeepCopyInto(out *ApisixUpstreamServiceRef) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamServiceRef.
+func (in *ApisixUpstreamServiceRef) DeepCopy() *ApisixUpstreamServiceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamServiceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixUpstreamSpec) DeepCopyInto(out *ApisixUpstreamSpec) {
 	*out = *in
@@ -1294,6 +2065,25 @@ func (in *ApisixUpstreamSpec) DeepCopyInto(out *ApisixUpstreamSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ApisixUpstreamServiceRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(ApisixUpstreamDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalNodes != nil {
+		in, out := &in.ExternalNodes, &out.ExternalNodes
+		*out = make([]ApisixUpstreamExternalNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1317,6 +2107,16 @@ func (in *ApisixUpstreamSubset) DeepCopyInto(out *ApisixUpstreamSubset) {
 			(*out)[key] = val
 		}
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -1330,6 +2130,27 @@ func (in *ApisixUpstreamSubset) DeepCopy() *ApisixUpstreamSubset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamTLS) DeepCopyInto(out *ApisixUpstreamTLS) {
+	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamTLS.
+func (in *ApisixUpstreamTLS) DeepCopy() *ApisixUpstreamTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
@@ -1475,6 +2296,23 @@ func (in *PortLevelSettings) DeepCopy() *PortLevelSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamKeepalivePool) DeepCopyInto(out *UpstreamKeepalivePool) {
+	*out = *in
+	out.IdleTimeout = in.IdleTimeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamKeepalivePool.
+func (in *UpstreamKeepalivePool) DeepCopy() *UpstreamKeepalivePool {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamKeepalivePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamTimeout) DeepCopyInto(out *UpstreamTimeout) {
 	*out = *in
@@ -63,6 +63,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ApisixConsumerList{},
 		&ApisixPluginConfig{},
 		&ApisixPluginConfigList{},
+		&ApisixRateLimitPolicy{},
+		&ApisixRateLimitPolicyList{},
 		&ApisixRoute{},
 		&ApisixRouteList{},
 		&ApisixTls{},
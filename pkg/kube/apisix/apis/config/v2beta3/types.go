@@ -55,6 +55,17 @@ type UpstreamTimeout struct {
 	Read    metav1.Duration `json:"read,omitempty" yaml:"read,omitempty"`
 }
 
+// UpstreamKeepalivePool is settings for the keepalive connection pool to the
+// upstream, the APISIX default is used for the items not set.
+type UpstreamKeepalivePool struct {
+	// Size is the maximum number of idle connections kept in the pool.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// IdleTimeout is the idle timeout of the connections in the pool.
+	IdleTimeout metav1.Duration `json:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+	// Requests is the maximum number of requests served by a connection.
+	Requests int `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
 // ApisixRouteHTTP represents a single route in for HTTP traffic.
 type ApisixRouteHTTP struct {
 	// The rule name, cannot be empty.
@@ -100,8 +111,12 @@ type ApisixRouteHTTPMatch struct {
 	// configured, path could be exact or prefix, for prefix path,
 	// append "*" after it, for instance, "/foo*".
 	Paths []string `json:"paths" yaml:"paths"`
-	// HTTP request method predicates.
+	// HTTP request method predicates, "*" or "ALL" means any method.
 	Methods []string `json:"methods,omitempty" yaml:"methods,omitempty"`
+	// NotMethods are the HTTP request methods which are not matched, it
+	// can't be used together with Methods.
+	// +optional
+	NotMethods []string `json:"notMethods,omitempty" yaml:"notMethods,omitempty"`
 	// HTTP Host predicates, host can be a wildcard domain or
 	// an exact domain. For wildcard domain, only one generic
 	// level is allowed, for instance, "*.foo.com" is valid but
@@ -161,6 +176,13 @@ type ApisixRouteHTTPPlugin struct {
 	Enable bool `json:"enable" yaml:"enable"`
 	// Plugin configuration.
 	Config ApisixRouteHTTPPluginConfig `json:"config" yaml:"config"`
+	// Priority overrides the default execution priority of this plugin,
+	// plugins with higher priority run earlier. It's translated to the
+	// _meta.priority field in APISIX.
+	Priority *int64 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	// Filter runs the plugin only on the requests matching all the
+	// expressions. It's translated to the _meta.filter field in APISIX.
+	Filter []v2.ApisixRouteHTTPMatchExpr `json:"filter,omitempty" yaml:"filter,omitempty"`
 }
 
 // ApisixRouteHTTPPluginConfig is the configuration for
@@ -288,6 +310,10 @@ type ApisixClusterMonitoringConfig struct {
 type ApisixClusterPrometheusConfig struct {
 	// Enable means whether enable Prometheus or not.
 	Enable bool `json:"enable" yaml:"enable"`
+	// PreferName means whether the route name instead of the route id is
+	// used as the route label of the metrics.
+	// +optional
+	PreferName bool `json:"preferName,omitempty" yaml:"preferName,omitempty"`
 }
 
 // ApisixClusterSkywalkingConfig is the config for using Skywalking in APISIX Cluster.
@@ -446,6 +472,69 @@ type ApisixUpstreamSpec struct {
 	ApisixUpstreamConfig `json:",inline" yaml:",inline"`
 
 	PortLevelSettings []PortLevelSettings `json:"portLevelSettings,omitempty" yaml:"portLevelSettings,omitempty"`
+
+	// Services aggregates the ready endpoints of the listed Services,
+	// possibly in other namespaces, as the upstream nodes instead of the
+	// endpoints of the Service with the same name as the ApisixUpstream.
+	// +optional
+	Services []ApisixUpstreamServiceRef `json:"services,omitempty" yaml:"services,omitempty"`
+
+	// Discovery resolves the upstream nodes with the service discovery of
+	// APISIX instead of the endpoints of the Service, the Service is only
+	// used to declare the ports.
+	// +optional
+	Discovery *ApisixUpstreamDiscovery `json:"discovery,omitempty" yaml:"discovery,omitempty"`
+
+	// ExternalNodes are the static upstream nodes, e.g. the backends out of
+	// the Kubernetes cluster. The endpoints are not used at all, and the
+	// Service with the same name is not required.
+	// +optional
+	ExternalNodes []ApisixUpstreamExternalNode `json:"externalNodes,omitempty" yaml:"externalNodes,omitempty"`
+}
+
+// ApisixUpstreamExternalNode is a static upstream node.
+type ApisixUpstreamExternalNode struct {
+	// Host is the IP address or the domain name of the node.
+	Host string `json:"host" yaml:"host"`
+	// Port is the port of the node, default is the same port as the
+	// upstream.
+	// +optional
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Weight is the weight of the node, default is 100.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// ApisixUpstreamDiscovery is the service discovery of APISIX which resolves
+// the upstream nodes.
+type ApisixUpstreamDiscovery struct {
+	// Type is the discovery type, like "dns", "consul" or "nacos", it should
+	// be enabled in APISIX.
+	Type string `json:"type" yaml:"type"`
+	// ServiceName is the name of the service in the discovery.
+	ServiceName string `json:"serviceName" yaml:"serviceName"`
+	// Args are the arguments of the discovery, like "namespace_id" and
+	// "group_name" of nacos.
+	// +optional
+	Args map[string]string `json:"args,omitempty" yaml:"args,omitempty"`
+}
+
+// ApisixUpstreamServiceRef references a Service whose endpoints are
+// aggregated into the upstream.
+type ApisixUpstreamServiceRef struct {
+	// Namespace is the namespace of the Service, default is the namespace
+	// of the ApisixUpstream.
+	// +optional
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// Name is the name of the Service.
+	Name string `json:"name" yaml:"name"`
+	// Port is the port of the Service, default is the same port as the
+	// upstream.
+	// +optional
+	Port int32 `json:"port,omitempty" yaml:"port,omitempty"`
+	// Weight is the weight of the nodes from the Service, default is 100.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // ApisixUpstreamConfig contains rich features on APISIX Upstream, for instance
@@ -456,39 +545,83 @@ type ApisixUpstreamConfig struct {
 	// +optional
 	LoadBalancer *LoadBalancer `json:"loadbalancer,omitempty" yaml:"loadbalancer,omitempty"`
 	// The scheme used to talk with the upstream.
-	// Now value can be http, grpc.
+	// Now value can be http, https, grpc, grpcs.
 	// +optional
 	Scheme string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
 
+	// PassHost configures the Host header passed to the upstream, can be
+	// "pass" (the client request Host), "node" (the endpoint address) or
+	// "rewrite" (the UpstreamHost), default is "pass".
+	// +optional
+	PassHost string `json:"passHost,omitempty" yaml:"passHost,omitempty"`
+	// UpstreamHost is the Host header passed to the upstream, it's required
+	// when PassHost is "rewrite", and forbidden otherwise.
+	// +optional
+	UpstreamHost string `json:"upstreamHost,omitempty" yaml:"upstreamHost,omitempty"`
+
 	// How many times that the proxy (Apache APISIX) should do when
 	// errors occur (error, timeout or bad http status codes like 500, 502).
 	// +optional
 	Retries *int `json:"retries,omitempty" yaml:"retries,omitempty"`
 
+	// RetryTimeout limits the total time that the proxy (Apache APISIX)
+	// can spend on retries, zero means no limit.
+	// +optional
+	RetryTimeout *metav1.Duration `json:"retryTimeout,omitempty" yaml:"retryTimeout,omitempty"`
+
 	// Timeout settings for the read, send and connect to the upstream.
 	// +optional
 	Timeout *UpstreamTimeout `json:"timeout,omitempty" yaml:"timeout,omitempty"`
 
+	// KeepalivePool configures the keepalive connection pool to the upstream,
+	// the APISIX default is used if it's not set.
+	// +optional
+	KeepalivePool *UpstreamKeepalivePool `json:"keepalivePool,omitempty" yaml:"keepalivePool,omitempty"`
+
 	// The health check configurations for the upstream.
 	// +optional
 	HealthCheck *HealthCheck `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
 
-	// Set the client certificate when connecting to TLS upstream.
+	// Set the client certificate when connecting to TLS upstream, it can only
+	// be used with the https and grpcs schemes.
 	// +optional
 	TLSSecret *ApisixSecret `json:"tlsSecret,omitempty" yaml:"tlsSecret,omitempty"`
 
+	// TLS configures the TLS connection to the upstream, it can only be
+	// used with the https and grpcs schemes.
+	// +optional
+	TLS *ApisixUpstreamTLS `json:"tls,omitempty" yaml:"tls,omitempty"`
+
 	// Subsets groups the service endpoints by their labels. Usually used to differentiate
 	// service versions.
 	// +optional
 	Subsets []ApisixUpstreamSubset `json:"subsets,omitempty" yaml:"subsets,omitempty"`
 }
 
+// ApisixUpstreamTLS is the TLS settings of the connection to the upstream.
+type ApisixUpstreamTLS struct {
+	// Verify indicates whether to verify the certificate of the upstream,
+	// default is true. Only disable it for the backends with self-signed
+	// certificates.
+	// +optional
+	Verify *bool `json:"verify,omitempty" yaml:"verify,omitempty"`
+}
+
 // ApisixUpstreamSubset defines a single endpoints group of one Service.
 type ApisixUpstreamSubset struct {
 	// Name is the name of subset.
 	Name string `json:"name" yaml:"name"`
 	// Labels is the label set of this subset.
-	Labels map[string]string `json:"labels" yaml:"labels"`
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Selector selects the endpoints whose target pods match it, it's
+	// used together with Labels if both are specified.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+	// Weight is the traffic weight of this subset, when weights are set,
+	// routes referencing the Service without a subset will split the
+	// traffic across the weighted subsets.
+	// +optional
+	Weight *int `json:"weight,omitempty" yaml:"weight,omitempty"`
 }
 
 // PortLevelSettings configures the ApisixUpstreamConfig for each individual port. It inherits
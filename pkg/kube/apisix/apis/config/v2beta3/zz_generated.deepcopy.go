@@ -838,6 +838,11 @@ func (in *ApisixRouteHTTPMatch) DeepCopyInto(out *ApisixRouteHTTPMatch) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.NotMethods != nil {
+		in, out := &in.NotMethods, &out.NotMethods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Hosts != nil {
 		in, out := &in.Hosts, &out.Hosts
 		*out = make([]string, len(*in))
@@ -915,6 +920,18 @@ func (in *ApisixRouteHTTPMatchExprSubject) DeepCopy() *ApisixRouteHTTPMatchExprS
 func (in *ApisixRouteHTTPPlugin) DeepCopyInto(out *ApisixRouteHTTPPlugin) {
 	*out = *in
 	in.Config.DeepCopyInto(&out.Config)
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Filter != nil {
+		in, out := &in.Filter, &out.Filter
+		*out = make([]v2.ApisixRouteHTTPMatchExpr, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1216,11 +1233,21 @@ func (in *ApisixUpstreamConfig) DeepCopyInto(out *ApisixUpstreamConfig) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.RetryTimeout != nil {
+		in, out := &in.RetryTimeout, &out.RetryTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(UpstreamTimeout)
 		**out = **in
 	}
+	if in.KeepalivePool != nil {
+		in, out := &in.KeepalivePool, &out.KeepalivePool
+		*out = new(UpstreamKeepalivePool)
+		**out = **in
+	}
 	if in.HealthCheck != nil {
 		in, out := &in.HealthCheck, &out.HealthCheck
 		*out = new(HealthCheck)
@@ -1231,6 +1258,11 @@ func (in *ApisixUpstreamConfig) DeepCopyInto(out *ApisixUpstreamConfig) {
 		*out = new(ApisixSecret)
 		**out = **in
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ApisixUpstreamTLS)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Subsets != nil {
 		in, out := &in.Subsets, &out.Subsets
 		*out = make([]ApisixUpstreamSubset, len(*in))
@@ -1251,6 +1283,50 @@ func (in *ApisixUpstreamConfig) DeepCopy() *ApisixUpstreamConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamDiscovery) DeepCopyInto(out *ApisixUpstreamDiscovery) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamDiscovery.
+func (in *ApisixUpstreamDiscovery) DeepCopy() *ApisixUpstreamDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamExternalNode) DeepCopyInto(out *ApisixUpstreamExternalNode) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamExternalNode.
+func (in *ApisixUpstreamExternalNode) DeepCopy() *ApisixUpstreamExternalNode {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamExternalNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixUpstreamList) DeepCopyInto(out *ApisixUpstreamList) {
 	*out = *in
@@ -1284,6 +1360,27 @@ func (in *ApisixUpstreamList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamServiceRef) DeepCopyInto(out *ApisixUpstreamServiceRef) {
+	*out = *in
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamServiceRef.
+func (in *ApisixUpstreamServiceRef) DeepCopy() *ApisixUpstreamServiceRef {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamServiceRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ApisixUpstreamSpec) DeepCopyInto(out *ApisixUpstreamSpec) {
 	*out = *in
@@ -1295,6 +1392,25 @@ func (in *ApisixUpstreamSpec) DeepCopyInto(out *ApisixUpstreamSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]ApisixUpstreamServiceRef, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Discovery != nil {
+		in, out := &in.Discovery, &out.Discovery
+		*out = new(ApisixUpstreamDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExternalNodes != nil {
+		in, out := &in.ExternalNodes, &out.ExternalNodes
+		*out = make([]ApisixUpstreamExternalNode, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1318,6 +1434,16 @@ func (in *ApisixUpstreamSubset) DeepCopyInto(out *ApisixUpstreamSubset) {
 			(*out)[key] = val
 		}
 	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Weight != nil {
+		in, out := &in.Weight, &out.Weight
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -1331,6 +1457,27 @@ func (in *ApisixUpstreamSubset) DeepCopy() *ApisixUpstreamSubset {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApisixUpstreamTLS) DeepCopyInto(out *ApisixUpstreamTLS) {
+	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApisixUpstreamTLS.
+func (in *ApisixUpstreamTLS) DeepCopy() *ApisixUpstreamTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ApisixUpstreamTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HealthCheck) DeepCopyInto(out *HealthCheck) {
 	*out = *in
@@ -1476,6 +1623,23 @@ func (in *PortLevelSettings) DeepCopy() *PortLevelSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpstreamKeepalivePool) DeepCopyInto(out *UpstreamKeepalivePool) {
+	*out = *in
+	out.IdleTimeout = in.IdleTimeout
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpstreamKeepalivePool.
+func (in *UpstreamKeepalivePool) DeepCopy() *UpstreamKeepalivePool {
+	if in == nil {
+		return nil
+	}
+	out := new(UpstreamKeepalivePool)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UpstreamTimeout) DeepCopyInto(out *UpstreamTimeout) {
 	*out = *in
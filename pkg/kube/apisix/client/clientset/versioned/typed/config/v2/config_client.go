@@ -28,6 +28,7 @@ type ApisixV2Interface interface {
 	ApisixClusterConfigsGetter
 	ApisixConsumersGetter
 	ApisixPluginConfigsGetter
+	ApisixRateLimitPoliciesGetter
 	ApisixRoutesGetter
 	ApisixTlsesGetter
 	ApisixUpstreamsGetter
@@ -50,6 +51,10 @@ func (c *ApisixV2Client) ApisixPluginConfigs(namespace string) ApisixPluginConfi
 	return newApisixPluginConfigs(c, namespace)
 }
 
+func (c *ApisixV2Client) ApisixRateLimitPolicies(namespace string) ApisixRateLimitPolicyInterface {
+	return newApisixRateLimitPolicies(c, namespace)
+}
+
 func (c *ApisixV2Client) ApisixRoutes(namespace string) ApisixRouteInterface {
 	return newApisixRoutes(c, namespace)
 }
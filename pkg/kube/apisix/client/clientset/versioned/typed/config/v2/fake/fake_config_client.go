@@ -39,6 +39,10 @@ func (c *FakeApisixV2) ApisixPluginConfigs(namespace string) v2.ApisixPluginConf
 	return &FakeApisixPluginConfigs{c, namespace}
 }
 
+func (c *FakeApisixV2) ApisixRateLimitPolicies(namespace string) v2.ApisixRateLimitPolicyInterface {
+	return &FakeApisixRateLimitPolicies{c, namespace}
+}
+
 func (c *FakeApisixV2) ApisixRoutes(namespace string) v2.ApisixRouteInterface {
 	return &FakeApisixRoutes{c, namespace}
 }
@@ -23,6 +23,8 @@ type ApisixConsumerExpansion interface{}
 
 type ApisixPluginConfigExpansion interface{}
 
+type ApisixRateLimitPolicyExpansion interface{}
+
 type ApisixRouteExpansion interface{}
 
 type ApisixTlsExpansion interface{}
@@ -29,6 +29,8 @@ type Interface interface {
 	ApisixConsumers() ApisixConsumerInformer
 	// ApisixPluginConfigs returns a ApisixPluginConfigInformer.
 	ApisixPluginConfigs() ApisixPluginConfigInformer
+	// ApisixRateLimitPolicies returns a ApisixRateLimitPolicyInformer.
+	ApisixRateLimitPolicies() ApisixRateLimitPolicyInformer
 	// ApisixRoutes returns a ApisixRouteInformer.
 	ApisixRoutes() ApisixRouteInformer
 	// ApisixTlses returns a ApisixTlsInformer.
@@ -63,6 +65,11 @@ func (v *version) ApisixPluginConfigs() ApisixPluginConfigInformer {
 	return &apisixPluginConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// ApisixRateLimitPolicies returns a ApisixRateLimitPolicyInformer.
+func (v *version) ApisixRateLimitPolicies() ApisixRateLimitPolicyInformer {
+	return &apisixRateLimitPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // ApisixRoutes returns a ApisixRouteInformer.
 func (v *version) ApisixRoutes() ApisixRouteInformer {
 	return &apisixRouteInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
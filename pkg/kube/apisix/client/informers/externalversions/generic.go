@@ -60,6 +60,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixConsumers().Informer()}, nil
 	case v2.SchemeGroupVersion.WithResource("apisixpluginconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixPluginConfigs().Informer()}, nil
+	case v2.SchemeGroupVersion.WithResource("apisixratelimitpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixRateLimitPolicies().Informer()}, nil
 	case v2.SchemeGroupVersion.WithResource("apisixroutes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apisix().V2().ApisixRoutes().Informer()}, nil
 	case v2.SchemeGroupVersion.WithResource("apisixtlses"):
@@ -37,6 +37,14 @@ type ApisixPluginConfigListerExpansion interface{}
 // ApisixPluginConfigNamespaceLister.
 type ApisixPluginConfigNamespaceListerExpansion interface{}
 
+// ApisixRateLimitPolicyListerExpansion allows custom methods to be added to
+// ApisixRateLimitPolicyLister.
+type ApisixRateLimitPolicyListerExpansion interface{}
+
+// ApisixRateLimitPolicyNamespaceListerExpansion allows custom methods to be added to
+// ApisixRateLimitPolicyNamespaceLister.
+type ApisixRateLimitPolicyNamespaceListerExpansion interface{}
+
 // ApisixRouteListerExpansion allows custom methods to be added to
 // ApisixRouteLister.
 type ApisixRouteListerExpansion interface{}
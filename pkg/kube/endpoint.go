@@ -32,6 +32,17 @@ import (
 type HostPort struct {
 	Host string
 	Port int
+	// PodName is the name of the target pod, it's empty if the endpoint
+	// doesn't target a pod.
+	PodName string
+}
+
+// targetPodName returns the pod name of the target reference.
+func targetPodName(ref *corev1.ObjectReference) string {
+	if ref == nil || ref.Kind != "Pod" {
+		return ""
+	}
+	return ref.Name
 }
 
 // EndpointLister is an encapsulation for the lister of Kubernetes
@@ -156,8 +167,9 @@ func (e *endpoint) Endpoints(svcPort *corev1.ServicePort) []HostPort {
 			if epPort != -1 {
 				for _, addr := range subset.Addresses {
 					addrs = append(addrs, HostPort{
-						Host: addr.IP,
-						Port: epPort,
+						Host:    addr.IP,
+						Port:    epPort,
+						PodName: targetPodName(addr.TargetRef),
 					})
 				}
 			}
@@ -180,8 +192,9 @@ func (e *endpoint) Endpoints(svcPort *corev1.ServicePort) []HostPort {
 					}
 					for _, addr := range ep.Addresses {
 						addrs = append(addrs, HostPort{
-							Host: addr,
-							Port: epPort,
+							Host:    addr,
+							Port:    epPort,
+							PodName: targetPodName(ep.TargetRef),
 						})
 					}
 				}
@@ -15,8 +15,13 @@
 package translation
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+
 	"go.uber.org/zap"
 
+	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	apisix "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
@@ -52,3 +57,74 @@ func (t *translator) translateAnnotations(anno map[string]string) apisix.Plugins
 	}
 	return plugins
 }
+
+// translateAuthConsumers restricts the auth plugin enabled by the auth-type
+// annotation to the ApisixConsumers listed in the auth-consumers annotation
+// by the consumer-restriction plugin. The ApisixConsumers should be in the
+// namespace of the Ingress and use the same auth type, which is checked if
+// verify is true.
+func (t *translator) translateAuthConsumers(namespace string, anno map[string]string, plugins apisix.Plugins, verify bool) error {
+	extractor := annotations.NewExtractor(anno)
+	names := extractor.GetStringsAnnotation(annotations.AnnotationsAuthConsumers)
+	if len(names) == 0 {
+		return nil
+	}
+	authType := extractor.GetStringAnnotation(annotations.AnnotationsAuthType)
+	if authType != "keyAuth" && authType != "basicAuth" {
+		return &translateError{
+			field:  annotations.AnnotationsAuthConsumers,
+			reason: "requires the auth-type annotation to be keyAuth or basicAuth",
+		}
+	}
+	whitelist := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if verify {
+			if err := t.checkAuthConsumer(namespace, name, authType); err != nil {
+				return &translateError{
+					field:  annotations.AnnotationsAuthConsumers,
+					reason: err.Error(),
+				}
+			}
+		}
+		whitelist = append(whitelist, apisix.ComposeConsumerName(namespace, name))
+	}
+	if len(whitelist) == 0 {
+		return nil
+	}
+	plugins["consumer-restriction"] = &apisix.ConsumerRestrictionConfig{
+		Whitelist: whitelist,
+	}
+	return nil
+}
+
+// checkAuthConsumer checks the ApisixConsumer exists and uses authType.
+func (t *translator) checkAuthConsumer(namespace, name, authType string) error {
+	if t.ApisixConsumerLister == nil {
+		return errors.New("ApisixConsumer is not watched")
+	}
+	var keyAuth, basicAuth bool
+	switch t.ApisixConsumerVersion {
+	case config.ApisixV2beta3:
+		ac, err := t.ApisixConsumerLister.V2beta3(namespace, name)
+		if err != nil {
+			return err
+		}
+		keyAuth = ac.V2beta3().Spec.AuthParameter.KeyAuth != nil
+		basicAuth = ac.V2beta3().Spec.AuthParameter.BasicAuth != nil
+	default:
+		ac, err := t.ApisixConsumerLister.V2(namespace, name)
+		if err != nil {
+			return err
+		}
+		keyAuth = ac.V2().Spec.AuthParameter.KeyAuth != nil
+		basicAuth = ac.V2().Spec.AuthParameter.BasicAuth != nil
+	}
+	if (authType == "keyAuth" && !keyAuth) || (authType == "basicAuth" && !basicAuth) {
+		return fmt.Errorf("ApisixConsumer %s doesn't use %s", name, authType)
+	}
+	return nil
+}
@@ -18,11 +18,6 @@ import (
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
-const (
-	// auth-type: keyAuth | basicAuth
-	_authType = AnnotationsPrefix + "auth-type"
-)
-
 type basicAuth struct{}
 
 // NewkeyBasicHandler creates a handler to convert
@@ -36,7 +31,7 @@ func (b *basicAuth) PluginName() string {
 }
 
 func (b *basicAuth) Handle(e Extractor) (interface{}, error) {
-	if e.GetStringAnnotation(_authType) != "basicAuth" {
+	if e.GetStringAnnotation(AnnotationsAuthType) != "basicAuth" {
 		return nil, nil
 	}
 	plugin := apisixv1.BasicAuthConfig{}
@@ -56,7 +51,7 @@ func (k *keyAuth) PluginName() string {
 }
 
 func (k *keyAuth) Handle(e Extractor) (interface{}, error) {
-	if e.GetStringAnnotation(_authType) != "keyAuth" {
+	if e.GetStringAnnotation(AnnotationsAuthType) != "keyAuth" {
 		return nil, nil
 	}
 	plugin := apisixv1.KeyAuthConfig{}
@@ -21,8 +21,60 @@ import (
 const (
 	// AnnotationsPrefix is the apisix annotation prefix
 	AnnotationsPrefix = "k8s.apisix.apache.org/"
+
+	// AnnotationsPluginConfigName is the annotation to reference an ApisixPluginConfig
+	// (in the same namespace), its plugins will be used by all routes of the Ingress.
+	AnnotationsPluginConfigName = AnnotationsPrefix + "plugin-config-name"
+
+	// AnnotationsRouteHosts is the annotation on a Service which contains the
+	// comma separated hosts, ApisixRoute rules without hosts use them when the
+	// Service is the first backend.
+	AnnotationsRouteHosts = AnnotationsPrefix + "route-hosts"
+
+	// AnnotationsAPISIXCluster is the annotation to select the APISIX cluster
+	// which the routes of the ApisixRoute or Ingress are pushed to, the
+	// default cluster is used if it's absent.
+	AnnotationsAPISIXCluster = AnnotationsPrefix + "apisix-cluster"
+
+	// AnnotationsDisableTracing is the annotation to exclude the routes of
+	// the ApisixRoute from the tracing enabled by ApisixClusterConfig, the
+	// value should be "true".
+	AnnotationsDisableTracing = AnnotationsPrefix + "disable-tracing"
+
+	// AnnotationsAuthType is the annotation to enable an auth plugin on the
+	// routes of the Ingress, the value should be keyAuth or basicAuth.
+	AnnotationsAuthType = AnnotationsPrefix + "auth-type"
+
+	// AnnotationsAuthConsumers is the annotation which contains the comma
+	// separated names of the ApisixConsumers (in the same namespace) allowed
+	// to access the routes of the Ingress, they should use the auth type of
+	// AnnotationsAuthType.
+	AnnotationsAuthConsumers = AnnotationsPrefix + "auth-consumers"
+
+	// AnnotationsUpstreamScheme, AnnotationsUpstreamLoadBalancer,
+	// AnnotationsUpstreamRetries and AnnotationsUpstream*Timeout are the
+	// annotations on a Service which configure its upstreams when there is
+	// no ApisixUpstream of the Service, the timeouts are durations like "5s".
+	// Only the ones enabled by the controller configuration are honored.
+	AnnotationsUpstreamScheme         = AnnotationsPrefix + "upstream-scheme"
+	AnnotationsUpstreamLoadBalancer   = AnnotationsPrefix + "upstream-lb-type"
+	AnnotationsUpstreamRetries        = AnnotationsPrefix + "upstream-retries"
+	AnnotationsUpstreamConnectTimeout = AnnotationsPrefix + "upstream-connect-timeout"
+	AnnotationsUpstreamReadTimeout    = AnnotationsPrefix + "upstream-read-timeout"
+	AnnotationsUpstreamSendTimeout    = AnnotationsPrefix + "upstream-send-timeout"
 )
 
+// ServiceUpstreamAnnotations are the Service annotations which can
+// configure the upstreams.
+var ServiceUpstreamAnnotations = []string{
+	AnnotationsUpstreamScheme,
+	AnnotationsUpstreamLoadBalancer,
+	AnnotationsUpstreamRetries,
+	AnnotationsUpstreamConnectTimeout,
+	AnnotationsUpstreamReadTimeout,
+	AnnotationsUpstreamSendTimeout,
+}
+
 // Extractor encapsulates some auxiliary methods to extract annotations.
 type Extractor interface {
 	// GetStringAnnotation returns the string value of the target annotation.
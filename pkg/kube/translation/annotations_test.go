@@ -0,0 +1,97 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestTranslateAuthConsumers(t *testing.T) {
+	apisixClient := fakeapisix.NewSimpleClientset()
+	factory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	indexer := factory.Apisix().V2().ApisixConsumers().Informer().GetIndexer()
+	assert.Nil(t, indexer.Add(&configv2.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{Name: "jack", Namespace: "test"},
+		Spec: configv2.ApisixConsumerSpec{
+			AuthParameter: configv2.ApisixConsumerAuthParameter{
+				KeyAuth: &configv2.ApisixConsumerKeyAuth{},
+			},
+		},
+	}))
+	assert.Nil(t, indexer.Add(&configv2.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{Name: "rose", Namespace: "test"},
+		Spec: configv2.ApisixConsumerSpec{
+			AuthParameter: configv2.ApisixConsumerAuthParameter{
+				BasicAuth: &configv2.ApisixConsumerBasicAuth{},
+			},
+		},
+	}))
+	tr := &translator{
+		&TranslatorOptions{
+			ApisixConsumerLister: kube.NewApisixConsumerLister(
+				factory.Apisix().V2beta3().ApisixConsumers().Lister(),
+				factory.Apisix().V2().ApisixConsumers().Lister(),
+			),
+			ApisixConsumerVersion: config.ApisixV2,
+		},
+	}
+
+	anno := map[string]string{
+		"k8s.apisix.apache.org/auth-type":      "keyAuth",
+		"k8s.apisix.apache.org/auth-consumers": "jack",
+	}
+	plugins := tr.translateAnnotations(anno)
+	assert.Nil(t, tr.translateAuthConsumers("test", anno, plugins, true))
+	assert.Equal(t, apisixv1.Plugins{
+		"key-auth": &apisixv1.KeyAuthConfig{},
+		"consumer-restriction": &apisixv1.ConsumerRestrictionConfig{
+			Whitelist: []string{"test_jack"},
+		},
+	}, plugins)
+
+	// The consumer uses another auth type.
+	anno["k8s.apisix.apache.org/auth-consumers"] = "jack, rose"
+	err := tr.translateAuthConsumers("test", anno, make(apisixv1.Plugins), true)
+	assert.Equal(t, "k8s.apisix.apache.org/auth-consumers: ApisixConsumer rose doesn't use keyAuth", err.Error())
+
+	// The consumer doesn't exist.
+	anno["k8s.apisix.apache.org/auth-consumers"] = "lucy"
+	err = tr.translateAuthConsumers("test", anno, make(apisixv1.Plugins), true)
+	assert.Contains(t, err.Error(), "not found")
+	// The consumers are not checked without verification.
+	plugins = make(apisixv1.Plugins)
+	assert.Nil(t, tr.translateAuthConsumers("test", anno, plugins, false))
+	assert.Equal(t, []string{"test_lucy"}, plugins["consumer-restriction"].(*apisixv1.ConsumerRestrictionConfig).Whitelist)
+
+	// The consumers require the auth type.
+	delete(anno, "k8s.apisix.apache.org/auth-type")
+	err = tr.translateAuthConsumers("test", anno, make(apisixv1.Plugins), false)
+	assert.Equal(t, "k8s.apisix.apache.org/auth-consumers: requires the auth-type annotation to be keyAuth or basicAuth", err.Error())
+
+	// No consumer restriction by default.
+	plugins = make(apisixv1.Plugins)
+	assert.Nil(t, tr.translateAuthConsumers("test", map[string]string{}, plugins, true))
+	assert.Len(t, plugins, 0)
+}
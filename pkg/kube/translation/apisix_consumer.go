@@ -62,6 +62,7 @@ func (t *translator) TranslateApisixConsumerV2beta3(ac *configv2beta3.ApisixCons
 	consumer := apisixv1.NewDefaultConsumer()
 	consumer.Username = apisixv1.ComposeConsumerName(ac.Namespace, ac.Name)
 	consumer.Plugins = plugins
+	consumer.Labels = mergeLabels(consumer.Labels, sourceLabels("ApisixConsumer", ac))
 	return consumer, nil
 }
 
@@ -105,5 +106,6 @@ func (t *translator) TranslateApisixConsumerV2(ac *configv2.ApisixConsumer) (*ap
 	consumer := apisixv1.NewDefaultConsumer()
 	consumer.Username = apisixv1.ComposeConsumerName(ac.Namespace, ac.Name)
 	consumer.Plugins = plugins
+	consumer.Labels = mergeLabels(consumer.Labels, sourceLabels("ApisixConsumer", ac))
 	return consumer, nil
 }
@@ -20,6 +20,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
@@ -280,3 +281,29 @@ func TestTranslateApisixConsumerV2(t *testing.T) {
 	// No test test cases for secret references as we already test them
 	// in plugin_test.go.
 }
+
+func TestTranslateApisixConsumerWithIDPrefix(t *testing.T) {
+	ac := &configv2.ApisixConsumer{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "jack",
+			Namespace: "qa",
+		},
+		Spec: configv2.ApisixConsumerSpec{
+			AuthParameter: configv2.ApisixConsumerAuthParameter{
+				BasicAuth: &configv2.ApisixConsumerBasicAuth{
+					Value: &configv2.ApisixConsumerBasicAuthValue{
+						Username: "jack",
+						Password: "jacknice",
+					},
+				},
+			},
+		},
+	}
+
+	id.SetPrefix("staging")
+	defer id.SetPrefix("")
+	consumer, err := (&translator{}).TranslateApisixConsumerV2(ac)
+	assert.Nil(t, err)
+	assert.Equal(t, "staging_qa_jack", consumer.Username)
+	assert.Equal(t, "apisix-ingress-controller.staging", consumer.Labels["managed-by"])
+}
@@ -15,8 +15,13 @@
 package translation
 
 import (
+	"errors"
+	"strings"
+
 	"go.uber.org/zap"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
+	"github.com/apache/apisix-ingress-controller/pkg/config"
 	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
@@ -24,6 +29,29 @@ import (
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
+var _errCrossNamespacePluginConfig = errors.New("referencing ApisixPluginConfig in other namespaces is not allowed")
+
+// ParsePluginConfigReference parses the ApisixPluginConfig reference of a
+// resource in the namespace ns, the reference is either a name in the same
+// namespace or in the form of "namespace/name".
+func ParsePluginConfigReference(ns, ref string) (string, string) {
+	if i := strings.IndexByte(ref, '/'); i >= 0 {
+		return ref[:i], ref[i+1:]
+	}
+	return ns, ref
+}
+
+// translatePluginConfigID returns the ID of the ApisixPluginConfig referenced
+// by a resource in the namespace ns. Cross namespace references are rejected
+// when verifying unless they are allowed explicitly.
+func (t *translator) translatePluginConfigID(ns, ref string, verify bool) (string, error) {
+	pcNamespace, pcName := ParsePluginConfigReference(ns, ref)
+	if verify && pcNamespace != ns && !t.AllowCrossNamespacePluginConfig {
+		return "", _errCrossNamespacePluginConfig
+	}
+	return id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName(pcNamespace, pcName)), nil
+}
+
 func (t *translator) TranslatePluginConfigV2beta3(config *configv2beta3.ApisixPluginConfig) (*TranslateContext, error) {
 	ctx := DefaultEmptyTranslateContext()
 	pluginMap := make(apisixv1.Plugins)
@@ -45,13 +73,32 @@ func (t *translator) TranslatePluginConfigV2beta3(config *configv2beta3.ApisixPl
 			} else {
 				pluginMap[plugin.Name] = make(map[string]interface{})
 			}
+			cfg, err := t.translatePluginMeta(plugin.Name, plugin.Config, plugin.Priority, plugin.Filter)
+			if err != nil {
+				log.Errorw("ApisixPluginConfig with invalid plugin meta",
+					zap.Error(err),
+					zap.Any("ApisixPluginConfig", config),
+				)
+				return nil, err
+			}
+			if cfg != nil {
+				pluginMap[plugin.Name] = cfg
+			}
 		}
 	}
+	if err := t.checkServerlessPlugins("plugins", pluginMap); err != nil {
+		log.Errorw("ApisixPluginConfig with serverless plugins",
+			zap.Error(err),
+			zap.Any("ApisixPluginConfig", config),
+		)
+		return nil, err
+	}
 	pc := apisixv1.NewDefaultPluginConfig()
 	pc.Name = apisixv1.ComposePluginConfigName(config.Namespace, config.Name)
-	pc.ID = id.GenID(pc.Name)
+	pc.ID = id.GenID(id.PluginConfig, pc.Name)
 	pc.Plugins = pluginMap
 	ctx.AddPluginConfig(pc)
+	SetSourceLabels(ctx, "ApisixPluginConfig", config)
 	return ctx, nil
 }
 
@@ -59,7 +106,7 @@ func (t *translator) TranslatePluginConfigV2beta3NotStrictly(config *configv2bet
 	ctx := DefaultEmptyTranslateContext()
 	pc := apisixv1.NewDefaultPluginConfig()
 	pc.Name = apisixv1.ComposePluginConfigName(config.Namespace, config.Name)
-	pc.ID = id.GenID(pc.Name)
+	pc.ID = id.GenID(id.PluginConfig, pc.Name)
 	ctx.AddPluginConfig(pc)
 	return ctx, nil
 }
@@ -85,13 +132,32 @@ func (t *translator) TranslatePluginConfigV2(config *configv2.ApisixPluginConfig
 			} else {
 				pluginMap[plugin.Name] = make(map[string]interface{})
 			}
+			cfg, err := t.translatePluginMeta(plugin.Name, plugin.Config, plugin.Priority, plugin.Filter)
+			if err != nil {
+				log.Errorw("ApisixPluginConfig with invalid plugin meta",
+					zap.Error(err),
+					zap.Any("ApisixPluginConfig", config),
+				)
+				return nil, err
+			}
+			if cfg != nil {
+				pluginMap[plugin.Name] = cfg
+			}
 		}
 	}
+	if err := t.checkServerlessPlugins("plugins", pluginMap); err != nil {
+		log.Errorw("ApisixPluginConfig with serverless plugins",
+			zap.Error(err),
+			zap.Any("ApisixPluginConfig", config),
+		)
+		return nil, err
+	}
 	pc := apisixv1.NewDefaultPluginConfig()
 	pc.Name = apisixv1.ComposePluginConfigName(config.Namespace, config.Name)
-	pc.ID = id.GenID(pc.Name)
+	pc.ID = id.GenID(id.PluginConfig, pc.Name)
 	pc.Plugins = pluginMap
 	ctx.AddPluginConfig(pc)
+	SetSourceLabels(ctx, "ApisixPluginConfig", config)
 	return ctx, nil
 }
 
@@ -99,7 +165,123 @@ func (t *translator) TranslatePluginConfigV2NotStrictly(config *configv2.ApisixP
 	ctx := DefaultEmptyTranslateContext()
 	pc := apisixv1.NewDefaultPluginConfig()
 	pc.Name = apisixv1.ComposePluginConfigName(config.Namespace, config.Name)
-	pc.ID = id.GenID(pc.Name)
+	pc.ID = id.GenID(id.PluginConfig, pc.Name)
 	ctx.AddPluginConfig(pc)
 	return ctx, nil
 }
+
+// mergePluginConfigPlugins deep merges the route plugins onto the same named
+// plugins of the referenced ApisixPluginConfig, since APISIX replaces them
+// wholesale, so that a route can tweak a part of a shared plugin. A disabled
+// route plugin disables the shared one.
+func (t *translator) mergePluginConfigPlugins(ns, ref string, plugins []configv2.ApisixRouteHTTPPlugin, pluginMap apisixv1.Plugins) error {
+	if t.ApisixPluginConfigLister == nil {
+		return nil
+	}
+	shared, err := t.pluginConfigPlugins(ns, ref)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			// The plugin config is still referenced by ID, routes are
+			// re-synced once it's created.
+			return nil
+		}
+		return err
+	}
+	for _, plugin := range plugins {
+		base, ok := shared[plugin.Name]
+		if !ok {
+			continue
+		}
+		if !plugin.Enable {
+			pluginMap[plugin.Name] = map[string]interface{}{"disable": true}
+			continue
+		}
+		var override map[string]interface{}
+		switch cfg := pluginMap[plugin.Name].(type) {
+		case map[string]interface{}:
+			override = cfg
+		case configv2.ApisixRouteHTTPPluginConfig:
+			override = cfg
+		default:
+			continue
+		}
+		pluginMap[plugin.Name] = mergePluginConfig(base, override)
+	}
+	return nil
+}
+
+// pluginConfigPlugins returns the configurations of the enabled plugins in
+// the referenced ApisixPluginConfig.
+func (t *translator) pluginConfigPlugins(ns, ref string) (map[string]map[string]interface{}, error) {
+	pcNamespace, pcName := ParsePluginConfigReference(ns, ref)
+	plugins := make(map[string]map[string]interface{})
+	switch t.ApisixPluginConfigVersion {
+	case config.ApisixV2beta3:
+		pc, err := t.ApisixPluginConfigLister.V2beta3(pcNamespace, pcName)
+		if err != nil {
+			return nil, err
+		}
+		for _, plugin := range pc.V2beta3().Spec.Plugins {
+			if plugin.Enable {
+				plugins[plugin.Name] = plugin.Config
+			}
+		}
+	default:
+		pc, err := t.ApisixPluginConfigLister.V2(pcNamespace, pcName)
+		if err != nil {
+			return nil, err
+		}
+		for _, plugin := range pc.V2().Spec.Plugins {
+			if plugin.Enable {
+				plugins[plugin.Name] = plugin.Config
+			}
+		}
+	}
+	return plugins, nil
+}
+
+// mergePluginConfig deep merges override onto base into a new object. Nested
+// objects are merged recursively, a null in override removes the field, and
+// the other values (arrays included) replace the ones in base. The base is
+// deep copied since it comes from the lister cache.
+func mergePluginConfig(base, override map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		out[k] = copyPluginConfigValue(v)
+	}
+	for k, v := range override {
+		if v == nil {
+			delete(out, k)
+			continue
+		}
+		if ov, ok := v.(map[string]interface{}); ok {
+			if bv, ok := out[k].(map[string]interface{}); ok {
+				out[k] = mergePluginConfig(bv, ov)
+				continue
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// copyPluginConfigValue deep copies the nested objects and arrays of a plugin
+// configuration.
+func copyPluginConfigValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			out[k] = copyPluginConfigValue(e)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, e := range v {
+			out[i] = copyPluginConfigValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -20,7 +20,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/apache/apisix-ingress-controller/pkg/config"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 func TestTranslatePluginConfigV2beta3(t *testing.T) {
@@ -112,3 +118,295 @@ func TestTranslatePluginConfigV2beta3NotStrictly(t *testing.T) {
 	assert.Len(t, ctx.PluginConfigs, 1)
 	assert.Len(t, ctx.PluginConfigs[0].Plugins, 0)
 }
+
+func TestTranslatePluginConfigV2WithPriority(t *testing.T) {
+	priority := int64(2600)
+	apc := &configv2.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apc",
+			Namespace: "test-ns",
+		},
+		Spec: configv2.ApisixPluginConfigSpec{
+			Plugins: []configv2.ApisixRouteHTTPPlugin{
+				{
+					Name:   "key-auth",
+					Enable: true,
+				},
+				{
+					Name:   "my-custom-plugin",
+					Enable: true,
+					Config: map[string]interface{}{
+						"key-1": 1,
+						"_meta": map[string]interface{}{
+							"disable": false,
+						},
+					},
+					Priority: &priority,
+				},
+			},
+		},
+	}
+	trans := &translator{}
+	ctx, err := trans.TranslatePluginConfigV2(apc)
+	assert.NoError(t, err)
+	assert.Len(t, ctx.PluginConfigs, 1)
+	plugins := ctx.PluginConfigs[0].Plugins
+	assert.Len(t, plugins, 2)
+	// priority is emitted only when it's set.
+	assert.Equal(t, map[string]interface{}{}, plugins["key-auth"])
+	assert.Equal(t, map[string]interface{}{
+		"key-1": 1,
+		"_meta": map[string]interface{}{
+			"disable":  false,
+			"priority": int64(2600),
+		},
+	}, plugins["my-custom-plugin"])
+	// the original object shouldn't be changed.
+	assert.NotContains(t, apc.Spec.Plugins[1].Config["_meta"], "priority")
+
+	apc.Spec.Plugins[1].Priority = nil
+	apc.Spec.Plugins[1].Config["_meta"] = map[string]interface{}{
+		"priority": 1.5,
+	}
+	_, err = trans.TranslatePluginConfigV2(apc)
+	assert.Error(t, err)
+}
+
+func TestTranslatePluginConfigV2WithFilter(t *testing.T) {
+	value := "on"
+	apc := &configv2.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apc",
+			Namespace: "test-ns",
+		},
+		Spec: configv2.ApisixPluginConfigSpec{
+			Plugins: []configv2.ApisixRouteHTTPPlugin{
+				{
+					Name:   "http-logger",
+					Enable: true,
+					Config: map[string]interface{}{
+						"uri": "http://127.0.0.1:1980/log",
+					},
+					Filter: []configv2.ApisixRouteHTTPMatchExpr{
+						{
+							Subject: configv2.ApisixRouteHTTPMatchExprSubject{
+								Scope: "Header",
+								Name:  "X-Debug",
+							},
+							Op:    "Equal",
+							Value: &value,
+						},
+					},
+				},
+			},
+		},
+	}
+	trans := &translator{}
+	ctx, err := trans.TranslatePluginConfigV2(apc)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"uri": "http://127.0.0.1:1980/log",
+		"_meta": map[string]interface{}{
+			"filter": apisixv1.Vars{
+				{{StrVal: "http_x_debug"}, {StrVal: "=="}, {StrVal: "on"}},
+			},
+		},
+	}, ctx.PluginConfigs[0].Plugins["http-logger"])
+	// the original object shouldn't be changed.
+	assert.NotContains(t, apc.Spec.Plugins[0].Config, "_meta")
+
+	apc.Spec.Plugins[0].Filter[0].Op = "Unknown"
+	_, err = trans.TranslatePluginConfigV2(apc)
+	assert.Equal(t, &translateError{field: "http-logger.filter", reason: "unknown operator"}, err)
+
+	apc.Spec.Plugins[0].Filter[0].Op = "Equal"
+	apc.Spec.Plugins[0].Config["_meta"] = map[string]interface{}{
+		"filter": []interface{}{},
+	}
+	_, err = trans.TranslatePluginConfigV2(apc)
+	assert.Equal(t, &translateError{field: "http-logger.filter", reason: "can't be used together with _meta.filter in config"}, err)
+}
+
+func TestTranslatePluginConfigWithServerlessPlugins(t *testing.T) {
+	fn := map[string]interface{}{"functions": []interface{}{"return function() end"}}
+	apc := &configv2.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apc",
+			Namespace: "test-ns",
+		},
+		Spec: configv2.ApisixPluginConfigSpec{
+			Plugins: []configv2.ApisixRouteHTTPPlugin{
+				{
+					Name:   "serverless-pre-function",
+					Enable: true,
+					Config: fn,
+				},
+			},
+		},
+	}
+	apc3 := &configv2beta3.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "apc",
+			Namespace: "test-ns",
+		},
+		Spec: configv2beta3.ApisixPluginConfigSpec{
+			Plugins: []configv2beta3.ApisixRouteHTTPPlugin{
+				{
+					Name:   "serverless-post-function",
+					Enable: true,
+					Config: fn,
+				},
+			},
+		},
+	}
+	tr := &translator{&TranslatorOptions{}}
+	_, err := tr.TranslatePluginConfigV2(apc)
+	assert.Equal(t, "plugins: plugin serverless-pre-function is not allowed since inline Lua functions are disabled by the controller", err.Error())
+	_, err = tr.TranslatePluginConfigV2beta3(apc3)
+	assert.Equal(t, "plugins: plugin serverless-post-function is not allowed since inline Lua functions are disabled by the controller", err.Error())
+
+	tr.AllowServerlessFunctions = true
+	ctx, err := tr.TranslatePluginConfigV2(apc)
+	assert.NoError(t, err)
+	assert.Contains(t, ctx.PluginConfigs[0].Plugins, "serverless-pre-function")
+	ctx, err = tr.TranslatePluginConfigV2beta3(apc3)
+	assert.NoError(t, err)
+	assert.Contains(t, ctx.PluginConfigs[0].Plugins, "serverless-post-function")
+}
+
+func TestMergePluginConfig(t *testing.T) {
+	base := map[string]interface{}{
+		"count":         100,
+		"time_window":   60,
+		"rejected_code": 503,
+		"policy":        "redis",
+		"redis": map[string]interface{}{
+			"host": "redis.default",
+			"port": 6379,
+		},
+		"allow": []interface{}{"a", "b"},
+	}
+	merged := mergePluginConfig(base, map[string]interface{}{
+		// override
+		"count": 10,
+		// addition
+		"key": "remote_addr",
+		// removal
+		"policy": nil,
+		"redis": map[string]interface{}{
+			"port":    6380,
+			"timeout": 1000,
+			"host":    nil,
+		},
+		// arrays are replaced
+		"allow": []interface{}{"c"},
+	})
+	assert.Equal(t, map[string]interface{}{
+		"count":         10,
+		"time_window":   60,
+		"rejected_code": 503,
+		"key":           "remote_addr",
+		"redis": map[string]interface{}{
+			"port":    6380,
+			"timeout": 1000,
+		},
+		"allow": []interface{}{"c"},
+	}, merged)
+
+	// The base is untouched.
+	assert.Equal(t, 100, base["count"])
+	assert.Equal(t, "redis.default", base["redis"].(map[string]interface{})["host"])
+
+	// The nested objects not overridden aren't shared with the base either.
+	merged = mergePluginConfig(base, map[string]interface{}{"count": 10})
+	merged["redis"].(map[string]interface{})["host"] = "redis.another"
+	merged["allow"].([]interface{})[0] = "c"
+	assert.Equal(t, "redis.default", base["redis"].(map[string]interface{})["host"])
+	assert.Equal(t, []interface{}{"a", "b"}, base["allow"])
+}
+
+func TestMergePluginConfigPlugins(t *testing.T) {
+	apc := &configv2.ApisixPluginConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "shared",
+			Namespace: "test-ns",
+		},
+		Spec: configv2.ApisixPluginConfigSpec{
+			Plugins: []configv2.ApisixRouteHTTPPlugin{
+				{
+					Name:   "limit-count",
+					Enable: true,
+					Config: map[string]interface{}{
+						"count":       100,
+						"time_window": 60,
+					},
+				},
+				{
+					Name:   "cors",
+					Enable: true,
+					Config: map[string]interface{}{
+						"allow_origins": "*",
+					},
+				},
+				{
+					Name:   "echo",
+					Enable: false,
+					Config: map[string]interface{}{
+						"body": "hello",
+					},
+				},
+			},
+		},
+	}
+	apisixClient := fakeapisix.NewSimpleClientset()
+	factory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	assert.Nil(t, factory.Apisix().V2().ApisixPluginConfigs().Informer().GetIndexer().Add(apc))
+	tr := &translator{
+		&TranslatorOptions{
+			ApisixPluginConfigLister: kube.NewApisixPluginConfigLister(
+				factory.Apisix().V2beta3().ApisixPluginConfigs().Lister(),
+				factory.Apisix().V2().ApisixPluginConfigs().Lister(),
+			),
+			ApisixPluginConfigVersion: config.ApisixV2,
+		},
+	}
+
+	plugins := []configv2.ApisixRouteHTTPPlugin{
+		{
+			Name:   "limit-count",
+			Enable: true,
+			Config: map[string]interface{}{"count": 10},
+		},
+		{
+			Name:   "cors",
+			Enable: false,
+		},
+		{
+			Name:   "echo",
+			Enable: true,
+			Config: map[string]interface{}{"before_body": "hi"},
+		},
+	}
+	pluginMap := apisixv1.Plugins{
+		"limit-count": configv2.ApisixRouteHTTPPluginConfig{"count": 10},
+		"echo":        configv2.ApisixRouteHTTPPluginConfig{"before_body": "hi"},
+	}
+	err := tr.mergePluginConfigPlugins("test-ns", "shared", plugins, pluginMap)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.Plugins{
+		"limit-count": map[string]interface{}{
+			"count":       10,
+			"time_window": 60,
+		},
+		// Disabled explicitly.
+		"cors": map[string]interface{}{"disable": true},
+		// Not enabled in the ApisixPluginConfig, kept as is.
+		"echo": configv2.ApisixRouteHTTPPluginConfig{"before_body": "hi"},
+	}, pluginMap)
+
+	// The missing ApisixPluginConfig isn't an error.
+	pluginMap = apisixv1.Plugins{"limit-count": configv2.ApisixRouteHTTPPluginConfig{"count": 10}}
+	err = tr.mergePluginConfigPlugins("test-ns", "missing", plugins, pluginMap)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.Plugins{"limit-count": configv2.ApisixRouteHTTPPluginConfig{"count": 10}}, pluginMap)
+}
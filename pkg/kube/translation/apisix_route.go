@@ -16,11 +16,17 @@ package translation
 
 import (
 	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
@@ -38,6 +44,8 @@ func (t *translator) TranslateRouteV2beta2(ar *configv2beta2.ApisixRoute) (*Tran
 	if err := t.translateStreamRouteV2beta2(ctx, ar); err != nil {
 		return nil, err
 	}
+	SetSourceLabels(ctx, "ApisixRoute", ar)
+	t.copyRouteLabels(ctx, ar.Labels)
 	return ctx, nil
 }
 
@@ -62,6 +70,8 @@ func (t *translator) TranslateRouteV2beta3(ar *configv2beta3.ApisixRoute) (*Tran
 	if err := t.translateStreamRouteV2beta3(ctx, ar); err != nil {
 		return nil, err
 	}
+	SetSourceLabels(ctx, "ApisixRoute", ar)
+	t.copyRouteLabels(ctx, ar.Labels)
 	return ctx, nil
 }
 
@@ -86,6 +96,8 @@ func (t *translator) TranslateRouteV2(ar *configv2.ApisixRoute) (*TranslateConte
 	if err := t.translateStreamRouteV2(ctx, ar); err != nil {
 		return nil, err
 	}
+	SetSourceLabels(ctx, "ApisixRoute", ar)
+	t.copyRouteLabels(ctx, ar.Labels)
 	return ctx, nil
 }
 
@@ -101,6 +113,58 @@ func (t *translator) TranslateRouteV2NotStrictly(ar *configv2.ApisixRoute) (*Tra
 	return ctx, nil
 }
 
+// _maxLabelValueLength is the maximum length of label values accepted
+// by APISIX.
+const _maxLabelValueLength = 64
+
+// copyRouteLabels copies the ApisixRoute labels selected by RouteLabelKeys
+// and RouteLabelPrefix to the labels of the translated routes and stream
+// routes. Values are truncated to the length limit of APISIX, empty ones
+// are skipped, and the labels set by the controller itself (e.g.
+// managed-by) are never overridden.
+func (t *translator) copyRouteLabels(ctx *TranslateContext, labels map[string]string) {
+	selected := make(map[string]string)
+	for k, v := range labels {
+		if v == "" || !t.isCopiedRouteLabel(k) {
+			continue
+		}
+		selected[k] = utils.TruncateString(v, _maxLabelValueLength)
+	}
+	if len(selected) == 0 {
+		return
+	}
+	for _, r := range ctx.Routes {
+		r.Labels = mergeLabels(r.Labels, selected)
+	}
+	for _, sr := range ctx.StreamRoutes {
+		sr.Labels = mergeLabels(sr.Labels, selected)
+	}
+}
+
+func (t *translator) isCopiedRouteLabel(key string) bool {
+	if t.RouteLabelPrefix != "" && strings.HasPrefix(key, t.RouteLabelPrefix) {
+		return true
+	}
+	for _, k := range t.RouteLabelKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeLabels(dst, src map[string]string) map[string]string {
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
 func (t *translator) translateHTTPRouteV2beta2(ctx *TranslateContext, ar *configv2beta2.ApisixRoute) error {
 	ruleNameMap := make(map[string]struct{})
 	for _, part := range ar.Spec.HTTP {
@@ -137,6 +201,14 @@ func (t *translator) translateHTTPRouteV2beta2(ctx *TranslateContext, ar *config
 			}
 		}
 
+		if err := t.checkServerlessPlugins("plugins", pluginMap); err != nil {
+			log.Errorw("ApisixRoute with serverless plugins",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+
 		// add KeyAuth and basicAuth plugin
 		if part.Authentication.Enable {
 			switch part.Authentication.Type {
@@ -169,17 +241,29 @@ func (t *translator) translateHTTPRouteV2beta2(ctx *TranslateContext, ar *config
 			return err
 		}
 
+		hosts := part.Match.Hosts
+		if len(hosts) == 0 {
+			hosts, err = t.translateServiceHosts(ar.Namespace, backend.ServiceName)
+			if err != nil {
+				log.Errorw("ApisixRoute refers to Service with invalid route hosts annotation",
+					zap.Error(err),
+					zap.String("service", backend.ServiceName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
+
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, svcPort)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		route.Priority = part.Priority
 		route.RemoteAddrs = part.Match.RemoteAddrs
 		route.Vars = exprs
-		route.Hosts = part.Match.Hosts
+		route.Hosts = hosts
 		route.Uris = part.Match.Paths
 		route.Methods = part.Match.Methods
-		route.UpstreamId = id.GenID(upstreamName)
 		route.EnableWebsocket = part.Websocket
 		route.Plugins = pluginMap
 
@@ -199,6 +283,18 @@ func (t *translator) translateHTTPRouteV2beta2(ctx *TranslateContext, ar *config
 				return err
 			}
 			route.Plugins["traffic-split"] = plugin
+		} else if backend.Subset == "" {
+			plugin, err := t.translateSubsetTrafficSplitPlugin(ctx, ar.Namespace, backend.ServiceName, backend.ResolveGranularity, svcClusterIP, svcPort)
+			if err != nil {
+				log.Errorw("failed to translate traffic-split plugin for subsets",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			if plugin != nil {
+				route.Plugins["traffic-split"] = plugin
+			}
 		}
 		ctx.AddRoute(route)
 		if !ctx.CheckUpstreamExist(upstreamName) {
@@ -208,6 +304,14 @@ func (t *translator) translateHTTPRouteV2beta2(ctx *TranslateContext, ar *config
 			}
 			ctx.AddUpstream(ups)
 		}
+		route.UpstreamId = ctx.getUpstream(upstreamName).ID
+		if err := validateRouteUpstreamScheme(route, ctx.getUpstream(upstreamName)); err != nil {
+			log.Errorw("ApisixRoute with incompatible upstream scheme",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
 	}
 	return nil
 }
@@ -237,19 +341,13 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 
 		var timeout *apisixv1.UpstreamTimeout
 		if part.Timeout != nil {
-			timeout = &apisixv1.UpstreamTimeout{
-				Connect: apisixv1.DefaultUpstreamTimeout,
-				Read:    apisixv1.DefaultUpstreamTimeout,
-				Send:    apisixv1.DefaultUpstreamTimeout,
-			}
-			if part.Timeout.Connect.Duration > 0 {
-				timeout.Connect = int(part.Timeout.Connect.Seconds())
-			}
-			if part.Timeout.Read.Duration > 0 {
-				timeout.Read = int(part.Timeout.Read.Seconds())
-			}
-			if part.Timeout.Send.Duration > 0 {
-				timeout.Send = int(part.Timeout.Send.Seconds())
+			timeout, err = translateRouteTimeout(part.Timeout.Connect.Duration, part.Timeout.Send.Duration, part.Timeout.Read.Duration)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid timeout",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
 			}
 		}
 		pluginMap := make(apisixv1.Plugins)
@@ -263,6 +361,25 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 			} else {
 				pluginMap[plugin.Name] = make(map[string]interface{})
 			}
+			cfg, err := t.translatePluginMeta(plugin.Name, plugin.Config, plugin.Priority, plugin.Filter)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid plugin meta",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			if cfg != nil {
+				pluginMap[plugin.Name] = cfg
+			}
+		}
+
+		if err := t.checkServerlessPlugins("plugins", pluginMap); err != nil {
+			log.Errorw("ApisixRoute with serverless plugins",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
 		}
 
 		// add KeyAuth and basicAuth plugin
@@ -294,6 +411,17 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 				return err
 			}
 		}
+		methods, methodExpr, err := translateRouteMethods(part.Match.Methods, part.Match.NotMethods)
+		if err != nil {
+			log.Errorw("ApisixRoute with bad methods",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+		if methodExpr != nil {
+			exprs = append(exprs, methodExpr)
+		}
 		if err := validateRemoteAddrs(part.Match.RemoteAddrs); err != nil {
 			log.Errorw("ApisixRoute with invalid remote addrs",
 				zap.Error(err),
@@ -302,23 +430,52 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 			)
 			return err
 		}
+		uris, err := translateRoutePaths(part.Match.Paths)
+		if err != nil {
+			log.Errorw("ApisixRoute with invalid paths",
+				zap.Error(err),
+				zap.Strings("paths", part.Match.Paths),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+
+		hosts := part.Match.Hosts
+		if len(hosts) == 0 {
+			hosts, err = t.translateServiceHosts(ar.Namespace, backend.ServiceName)
+			if err != nil {
+				log.Errorw("ApisixRoute refers to Service with invalid route hosts annotation",
+					zap.Error(err),
+					zap.String("service", backend.ServiceName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
 
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, svcPort)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		route.Priority = part.Priority
 		route.RemoteAddrs = part.Match.RemoteAddrs
 		route.Vars = exprs
-		route.Hosts = part.Match.Hosts
-		route.Uris = part.Match.Paths
-		route.Methods = part.Match.Methods
-		route.UpstreamId = id.GenID(upstreamName)
+		route.Hosts = hosts
+		route.Uris = uris
+		route.Methods = methods
 		route.EnableWebsocket = part.Websocket
 		route.Plugins = pluginMap
 		route.Timeout = timeout
 		if part.PluginConfigName != "" {
-			route.PluginConfigId = id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, part.PluginConfigName))
+			route.PluginConfigId, err = t.translatePluginConfigID(ar.Namespace, part.PluginConfigName, true)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid plugin config name",
+					zap.Error(err),
+					zap.String("plugin_config_name", part.PluginConfigName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
 		}
 
 		if len(backends) > 0 {
@@ -335,6 +492,18 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 				return err
 			}
 			route.Plugins["traffic-split"] = plugin
+		} else if backend.Subset == "" {
+			plugin, err := t.translateSubsetTrafficSplitPlugin(ctx, ar.Namespace, backend.ServiceName, backend.ResolveGranularity, svcClusterIP, svcPort)
+			if err != nil {
+				log.Errorw("failed to translate traffic-split plugin for subsets",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			if plugin != nil {
+				route.Plugins["traffic-split"] = plugin
+			}
 		}
 		ctx.AddRoute(route)
 		if !ctx.CheckUpstreamExist(upstreamName) {
@@ -344,6 +513,14 @@ func (t *translator) translateHTTPRouteV2beta3(ctx *TranslateContext, ar *config
 			}
 			ctx.AddUpstream(ups)
 		}
+		route.UpstreamId = ctx.getUpstream(upstreamName).ID
+		if err := validateRouteUpstreamScheme(route, ctx.getUpstream(upstreamName)); err != nil {
+			log.Errorw("ApisixRoute with incompatible upstream scheme",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
 	}
 	return nil
 }
@@ -355,6 +532,11 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 			return errors.New("duplicated route rule name")
 		}
 		ruleNameMap[part.Name] = struct{}{}
+		if !part.IsEnabled() {
+			// Disabled rules are left out so that their routes are
+			// removed from APISIX.
+			continue
+		}
 		backends := part.Backends
 		// Use the first backend as the default backend in Route,
 		// others will be configured in traffic-split plugin.
@@ -373,25 +555,31 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 
 		var timeout *apisixv1.UpstreamTimeout
 		if part.Timeout != nil {
-			timeout = &apisixv1.UpstreamTimeout{
-				Connect: apisixv1.DefaultUpstreamTimeout,
-				Read:    apisixv1.DefaultUpstreamTimeout,
-				Send:    apisixv1.DefaultUpstreamTimeout,
-			}
-			if part.Timeout.Connect.Duration > 0 {
-				timeout.Connect = int(part.Timeout.Connect.Seconds())
-			}
-			if part.Timeout.Read.Duration > 0 {
-				timeout.Read = int(part.Timeout.Read.Seconds())
-			}
-			if part.Timeout.Send.Duration > 0 {
-				timeout.Send = int(part.Timeout.Send.Seconds())
+			timeout, err = translateRouteTimeout(part.Timeout.Connect.Duration, part.Timeout.Send.Duration, part.Timeout.Read.Duration)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid timeout",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
 			}
 		}
 		pluginMap := make(apisixv1.Plugins)
-		// add route plugins
+		if part.PluginsFrom != nil {
+			pluginMap, err = t.translatePluginsFrom(ar.Namespace, part.PluginsFrom)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad pluginsFrom",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
+		// add route plugins, they take precedence over the ones from
+		// pluginsFrom.
 		for _, plugin := range part.Plugins {
 			if !plugin.Enable {
+				delete(pluginMap, plugin.Name)
 				continue
 			}
 			if plugin.Config != nil {
@@ -399,6 +587,35 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 			} else {
 				pluginMap[plugin.Name] = make(map[string]interface{})
 			}
+			cfg, err := t.translatePluginMeta(plugin.Name, plugin.Config, plugin.Priority, plugin.Filter)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid plugin meta",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			if cfg != nil {
+				pluginMap[plugin.Name] = cfg
+			}
+		}
+		if part.PluginConfigName != "" && len(part.Plugins) > 0 {
+			if err := t.mergePluginConfigPlugins(ar.Namespace, part.PluginConfigName, part.Plugins, pluginMap); err != nil {
+				log.Errorw("failed to merge plugins with ApisixPluginConfig",
+					zap.Error(err),
+					zap.String("plugin_config_name", part.PluginConfigName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
+
+		if err := t.checkServerlessPlugins("plugins", pluginMap); err != nil {
+			log.Errorw("ApisixRoute with serverless plugins",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
 		}
 
 		// add KeyAuth and basicAuth plugin
@@ -419,6 +636,224 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 			}
 		}
 
+		if part.FileLogger != nil {
+			if _, ok := pluginMap["file-logger"]; ok {
+				err := &translateError{field: "fileLogger", reason: "conflicts with the file-logger plugin"}
+				log.Errorw("ApisixRoute with both fileLogger and file-logger plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateFileLoggerPlugin(part.FileLogger)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad fileLogger",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["file-logger"] = cfg
+		}
+
+		if part.Canary != nil {
+			var err error
+			if _, ok := pluginMap["traffic-split"]; ok {
+				err = &translateError{field: "canary", reason: "conflicts with the traffic-split plugin"}
+			} else if len(backends) > 0 {
+				err = &translateError{field: "canary", reason: "cannot be used together with multiple backends"}
+			}
+			if err != nil {
+				log.Errorw("ApisixRoute with conflicting canary",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
+
+		if part.Rewrite != nil {
+			if _, ok := pluginMap["proxy-rewrite"]; ok {
+				err := &translateError{field: "rewrite", reason: "conflicts with the proxy-rewrite plugin"}
+				log.Errorw("ApisixRoute with both rewrite and proxy-rewrite plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateRewritePlugin(part.Rewrite)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad rewrite",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["proxy-rewrite"] = cfg
+		}
+
+		if part.IPRestriction != nil {
+			if _, ok := pluginMap["ip-restriction"]; ok {
+				err := &translateError{field: "ipRestriction", reason: "conflicts with the ip-restriction plugin"}
+				log.Errorw("ApisixRoute with both ipRestriction and ip-restriction plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateIPRestrictionPlugin(part.IPRestriction)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad ipRestriction",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["ip-restriction"] = cfg
+		}
+
+		if part.LimitCount != nil {
+			if _, ok := pluginMap["limit-count"]; ok {
+				err := &translateError{field: "limitCount", reason: "conflicts with the limit-count plugin"}
+				log.Errorw("ApisixRoute with both limitCount and limit-count plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateLimitCountPlugin(ar.Namespace, part.LimitCount)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad limitCount",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["limit-count"] = cfg
+		}
+
+		if part.BodyTransformer != nil {
+			if _, ok := pluginMap["body-transformer"]; ok {
+				err := &translateError{field: "bodyTransformer", reason: "conflicts with the body-transformer plugin"}
+				log.Errorw("ApisixRoute with both bodyTransformer and body-transformer plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateBodyTransformerPlugin(ar.Namespace, part.BodyTransformer)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad bodyTransformer",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["body-transformer"] = cfg
+		}
+
+		if part.ForwardAuth != nil {
+			if _, ok := pluginMap["forward-auth"]; ok {
+				err := &translateError{field: "forwardAuth", reason: "conflicts with the forward-auth plugin"}
+				log.Errorw("ApisixRoute with both forwardAuth and forward-auth plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateForwardAuthPlugin(part.ForwardAuth)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad forwardAuth",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["forward-auth"] = cfg
+		}
+
+		if part.Cors != nil {
+			if _, ok := pluginMap["cors"]; ok {
+				err := &translateError{field: "cors", reason: "conflicts with the cors plugin"}
+				log.Errorw("ApisixRoute with both cors field and cors plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateCorsPlugin(part.Cors)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad cors",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["cors"] = cfg
+		}
+
+		if part.ResponseRewrite != nil {
+			if _, ok := pluginMap["response-rewrite"]; ok {
+				err := &translateError{field: "responseRewrite", reason: "conflicts with the response-rewrite plugin"}
+				log.Errorw("ApisixRoute with both responseRewrite field and response-rewrite plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateResponseRewritePlugin(part.ResponseRewrite)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad responseRewrite",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["response-rewrite"] = cfg
+		}
+
+		if part.RequestID != nil {
+			if _, ok := pluginMap["request-id"]; ok {
+				err := &translateError{field: "requestId", reason: "conflicts with the request-id plugin"}
+				log.Errorw("ApisixRoute with both requestId field and request-id plugin",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			cfg, err := t.translateRequestIDPlugin(part.RequestID)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad requestId",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			pluginMap["request-id"] = cfg
+		}
+
+		if part.Serverless != nil {
+			plugins, err := t.translateServerlessPlugins(part.Serverless)
+			if err != nil {
+				log.Errorw("ApisixRoute with bad serverless",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			for name, cfg := range plugins {
+				if _, ok := pluginMap[name]; ok {
+					err := &translateError{field: "serverless", reason: "conflicts with the " + name + " plugin"}
+					log.Errorw("ApisixRoute with both serverless field and serverless plugin",
+						zap.Error(err),
+						zap.Any("ApisixRoute", ar),
+					)
+					return err
+				}
+				pluginMap[name] = cfg
+			}
+		}
+
 		var exprs [][]apisixv1.StringOrSlice
 		if part.Match.NginxVars != nil {
 			exprs, err = t.translateRouteMatchExprs(part.Match.NginxVars)
@@ -430,6 +865,17 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 				return err
 			}
 		}
+		methods, methodExpr, err := translateRouteMethods(part.Match.Methods, part.Match.NotMethods)
+		if err != nil {
+			log.Errorw("ApisixRoute with bad methods",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+		if methodExpr != nil {
+			exprs = append(exprs, methodExpr)
+		}
 		if err := validateRemoteAddrs(part.Match.RemoteAddrs); err != nil {
 			log.Errorw("ApisixRoute with invalid remote addrs",
 				zap.Error(err),
@@ -438,23 +884,64 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 			)
 			return err
 		}
+		uris, err := translateRoutePaths(part.Match.Paths)
+		if err != nil {
+			log.Errorw("ApisixRoute with invalid paths",
+				zap.Error(err),
+				zap.Strings("paths", part.Match.Paths),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+		if part.Match.GRPC != nil {
+			if err := ValidateGRPCMatch(&part.Match); err != nil {
+				log.Errorw("ApisixRoute with invalid grpc match",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			// gRPC requests are always POST requests to the full method path.
+			uris = translateGRPCMatch(part.Match.GRPC)
+			methods = []string{"POST"}
+		}
+
+		hosts := part.Match.Hosts
+		if len(hosts) == 0 {
+			hosts, err = t.translateServiceHosts(ar.Namespace, backend.ServiceName)
+			if err != nil {
+				log.Errorw("ApisixRoute refers to Service with invalid route hosts annotation",
+					zap.Error(err),
+					zap.String("service", backend.ServiceName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
 
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, svcPort)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		route.Priority = part.Priority
 		route.RemoteAddrs = part.Match.RemoteAddrs
 		route.Vars = exprs
-		route.Hosts = part.Match.Hosts
-		route.Uris = part.Match.Paths
-		route.Methods = part.Match.Methods
-		route.UpstreamId = id.GenID(upstreamName)
+		route.Hosts = hosts
+		route.Uris = uris
+		route.Methods = methods
 		route.EnableWebsocket = part.Websocket
 		route.Plugins = pluginMap
 		route.Timeout = timeout
 		if part.PluginConfigName != "" {
-			route.PluginConfigId = id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, part.PluginConfigName))
+			route.PluginConfigId, err = t.translatePluginConfigID(ar.Namespace, part.PluginConfigName, true)
+			if err != nil {
+				log.Errorw("ApisixRoute with invalid plugin config name",
+					zap.Error(err),
+					zap.String("plugin_config_name", part.PluginConfigName),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
 		}
 
 		if len(backends) > 0 {
@@ -471,6 +958,65 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 				return err
 			}
 			route.Plugins["traffic-split"] = plugin
+		} else if backend.Subset == "" {
+			plugin, err := t.translateSubsetTrafficSplitPlugin(ctx, ar.Namespace, backend.ServiceName, backend.ResolveGranularity, svcClusterIP, svcPort)
+			if err != nil {
+				log.Errorw("failed to translate traffic-split plugin for subsets",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			if plugin != nil {
+				route.Plugins["traffic-split"] = plugin
+			}
+		}
+		if len(part.MethodBackends) > 0 {
+			rules, err := t.translateMethodTrafficSplitRules(ctx, ar.Namespace, methods, part.MethodBackends)
+			if err != nil {
+				log.Errorw("failed to translate method backends",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			// The method rules go first so that they take precedence over the
+			// weighted rule which has no match conditions.
+			plugin := &apisixv1.TrafficSplitConfig{}
+			if cfg, ok := route.Plugins["traffic-split"]; ok {
+				plugin = cfg.(*apisixv1.TrafficSplitConfig)
+			}
+			plugin.Rules = append(rules, plugin.Rules...)
+			route.Plugins["traffic-split"] = plugin
+		}
+		if part.Canary != nil {
+			rules, err := t.translateCanaryTrafficSplitRules(ctx, ar.Namespace, part.Canary)
+			if err != nil {
+				log.Errorw("failed to translate canary",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+			// The canary rules go after the method rules, but before the
+			// rule without match conditions (e.g. the subsets rule).
+			plugin := &apisixv1.TrafficSplitConfig{}
+			if cfg, ok := route.Plugins["traffic-split"]; ok {
+				plugin = cfg.(*apisixv1.TrafficSplitConfig)
+			}
+			pos := len(plugin.Rules)
+			for i, rule := range plugin.Rules {
+				if len(rule.Match) == 0 {
+					pos = i
+					break
+				}
+			}
+			merged := make([]apisixv1.TrafficSplitConfigRule, 0, len(plugin.Rules)+len(rules))
+			merged = append(merged, plugin.Rules[:pos]...)
+			merged = append(merged, rules...)
+			merged = append(merged, plugin.Rules[pos:]...)
+			plugin.Rules = merged
+			route.Plugins["traffic-split"] = plugin
 		}
 		ctx.AddRoute(route)
 		if !ctx.CheckUpstreamExist(upstreamName) {
@@ -480,10 +1026,148 @@ func (t *translator) translateHTTPRouteV2(ctx *TranslateContext, ar *configv2.Ap
 			}
 			ctx.AddUpstream(ups)
 		}
+		route.UpstreamId = ctx.getUpstream(upstreamName).ID
+		if err := validateRouteUpstreamScheme(route, ctx.getUpstream(upstreamName)); err != nil {
+			log.Errorw("ApisixRoute with incompatible upstream scheme",
+				zap.Error(err),
+				zap.Any("ApisixRoute", ar),
+			)
+			return err
+		}
+		if part.Match.GRPC != nil {
+			if err := validateGRPCRouteUpstreams(ctx, route); err != nil {
+				log.Errorw("ApisixRoute with grpc match but non-grpc upstream",
+					zap.Error(err),
+					zap.Any("ApisixRoute", ar),
+				)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	_grpcServiceRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+	_grpcMethodRegex  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ValidateGRPCMatch checks the gRPC match of an ApisixRoute rule. Since the
+// paths and methods are derived from the gRPC service and methods, they
+// cannot be specified together with it.
+func ValidateGRPCMatch(match *configv2.ApisixRouteHTTPMatch) error {
+	if len(match.Paths) > 0 {
+		return &translateError{field: "match.grpc", reason: "can't be used together with match.paths"}
+	}
+	if len(match.Methods) > 0 || len(match.NotMethods) > 0 {
+		return &translateError{field: "match.grpc", reason: "can't be used together with match.methods or match.notMethods"}
+	}
+	if !_grpcServiceRegex.MatchString(match.GRPC.Service) {
+		return &translateError{field: "match.grpc.service", reason: fmt.Sprintf("invalid service name %q", match.GRPC.Service)}
+	}
+	for _, method := range match.GRPC.Methods {
+		if !_grpcMethodRegex.MatchString(method) {
+			return &translateError{field: "match.grpc.methods", reason: fmt.Sprintf("invalid method name %q", method)}
+		}
+	}
+	return nil
+}
+
+// translateGRPCMatch translates the gRPC match to route paths, which are the
+// gRPC full methods like "/helloworld.Greeter/SayHello".
+func translateGRPCMatch(match *configv2.ApisixRouteHTTPMatchGRPC) []string {
+	if len(match.Methods) == 0 {
+		return []string{"/" + match.Service + "/*"}
+	}
+	uris := make([]string, 0, len(match.Methods))
+	for _, method := range match.Methods {
+		uri := "/" + match.Service + "/" + method
+		if !containsString(uris, uri) {
+			uris = append(uris, uri)
+		}
+	}
+	return uris
+}
+
+// validateGRPCRouteUpstreams checks whether all upstreams of the route,
+// including the ones referenced by the traffic-split plugin, use the grpc or
+// grpcs scheme.
+func validateGRPCRouteUpstreams(ctx *TranslateContext, route *apisixv1.Route) error {
+	ids := map[string]struct{}{route.UpstreamId: {}}
+	if cfg, ok := route.Plugins["traffic-split"].(*apisixv1.TrafficSplitConfig); ok {
+		for _, rule := range cfg.Rules {
+			for _, wu := range rule.WeightedUpstreams {
+				if wu.UpstreamID != "" {
+					ids[wu.UpstreamID] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, ups := range ctx.Upstreams {
+		if _, ok := ids[ups.ID]; !ok {
+			continue
+		}
+		if ups.Scheme != apisixv1.SchemeGRPC && ups.Scheme != apisixv1.SchemeGRPCS {
+			scheme := ups.Scheme
+			if scheme == "" {
+				scheme = apisixv1.SchemeHTTP
+			}
+			return &translateError{
+				field:  "match.grpc",
+				reason: fmt.Sprintf("requires upstream with grpc or grpcs scheme, but upstream %s uses %s", ups.Name, scheme),
+			}
+		}
 	}
 	return nil
 }
 
+// validateRouteUpstreamScheme checks whether the route can be proxied to the
+// upstream, the websocket upgrade cannot be carried over gRPC.
+func validateRouteUpstreamScheme(route *apisixv1.Route, ups *apisixv1.Upstream) error {
+	if ups == nil || !route.EnableWebsocket {
+		return nil
+	}
+	if ups.Scheme == apisixv1.SchemeGRPC || ups.Scheme == apisixv1.SchemeGRPCS {
+		return &translateError{
+			field:  "websocket",
+			reason: fmt.Sprintf("not supported by upstream with %s scheme", ups.Scheme),
+		}
+	}
+	return nil
+}
+
+// translateRouteTimeout translates the timeout of a route rule, the unset
+// (zero) durations take the default value. Since APISIX accepts the timeout
+// in seconds, the durations should be no less than one second.
+func translateRouteTimeout(connect, send, read time.Duration) (*apisixv1.UpstreamTimeout, error) {
+	timeout := &apisixv1.UpstreamTimeout{
+		Connect: apisixv1.DefaultUpstreamTimeout,
+		Send:    apisixv1.DefaultUpstreamTimeout,
+		Read:    apisixv1.DefaultUpstreamTimeout,
+	}
+	for _, item := range []struct {
+		field    string
+		duration time.Duration
+		value    *int
+	}{
+		{"timeout.connect", connect, &timeout.Connect},
+		{"timeout.send", send, &timeout.Send},
+		{"timeout.read", read, &timeout.Read},
+	} {
+		if item.duration == 0 {
+			continue
+		}
+		if item.duration < time.Second {
+			return nil, &translateError{
+				field:  item.field,
+				reason: "should be at least 1s",
+			}
+		}
+		*item.value = int(item.duration.Seconds())
+	}
+	return timeout, nil
+}
+
 func (t *translator) translateRouteMatchExprs(nginxVars []configv2.ApisixRouteHTTPMatchExpr) ([][]apisixv1.StringOrSlice, error) {
 	var (
 		vars [][]apisixv1.StringOrSlice
@@ -568,10 +1252,21 @@ func (t *translator) translateRouteMatchExprs(nginxVars []configv2.ApisixRouteHT
 			if expr.Set == nil {
 				return nil, errors.New("empty set value")
 			}
+			if expr.Value != nil {
+				return nil, fmt.Errorf("value is not allowed with operator %s, use set instead", expr.Op)
+			}
 			this = append(this, apisixv1.StringOrSlice{
 				SliceVal: expr.Set,
 			})
 		} else if expr.Value != nil {
+			if expr.Set != nil {
+				return nil, fmt.Errorf("set is not allowed with operator %s, use value instead", expr.Op)
+			}
+			if expr.Op == _const.OpGreaterThan || expr.Op == _const.OpLessThan {
+				if _, err := strconv.ParseFloat(*expr.Value, 64); err != nil {
+					return nil, fmt.Errorf("value of operator %s must be a number", expr.Op)
+				}
+			}
 			this = append(this, apisixv1.StringOrSlice{
 				StrVal: *expr.Value,
 			})
@@ -584,6 +1279,41 @@ func (t *translator) translateRouteMatchExprs(nginxVars []configv2.ApisixRouteHT
 	return vars, nil
 }
 
+// translateRouteMethods translates the methods and notMethods of a route
+// match. "*" or "ALL" in methods means any method, so no methods are
+// returned; notMethods are translated to an expression against the
+// request_method variable.
+func translateRouteMethods(methods, notMethods []string) ([]string, []apisixv1.StringOrSlice, error) {
+	if len(methods) > 0 && len(notMethods) > 0 {
+		return nil, nil, &translateError{
+			field:  "match.notMethods",
+			reason: "can't be used together with match.methods",
+		}
+	}
+	for _, method := range methods {
+		if method == "*" || method == "ALL" {
+			return nil, nil, nil
+		}
+	}
+	if len(notMethods) == 0 {
+		return methods, nil, nil
+	}
+	for _, method := range notMethods {
+		if _, ok := _validHTTPMethods[method]; !ok {
+			return nil, nil, &translateError{
+				field:  "match.notMethods",
+				reason: fmt.Sprintf("invalid method %s", method),
+			}
+		}
+	}
+	return nil, []apisixv1.StringOrSlice{
+		{StrVal: "request_method"},
+		{StrVal: "!"},
+		{StrVal: "in"},
+		{SliceVal: notMethods},
+	}, nil
+}
+
 // translateHTTPRouteV2beta2NotStrictly translates http route with a loose way, only generate ID and Name for delete Event.
 func (t *translator) translateHTTPRouteV2beta2NotStrictly(ctx *TranslateContext, ar *configv2beta2.ApisixRoute) error {
 	for _, part := range ar.Spec.HTTP {
@@ -594,7 +1324,7 @@ func (t *translator) translateHTTPRouteV2beta2NotStrictly(ctx *TranslateContext,
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		ctx.AddRoute(route)
 		if !ctx.CheckUpstreamExist(upstreamName) {
 			ups, err := t.translateUpstreamNotStrictly(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
@@ -649,9 +1379,9 @@ func (t *translator) translateHTTPRouteV2beta3NotStrictly(ctx *TranslateContext,
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		if part.PluginConfigName != "" {
-			route.PluginConfigId = id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, part.PluginConfigName))
+			route.PluginConfigId, _ = t.translatePluginConfigID(ar.Namespace, part.PluginConfigName, false)
 		}
 
 		ctx.AddRoute(route)
@@ -669,6 +1399,9 @@ func (t *translator) translateHTTPRouteV2beta3NotStrictly(ctx *TranslateContext,
 // translateHTTPRouteV2NotStrictly translates http route with a loose way, only generate ID and Name for delete Event.
 func (t *translator) translateHTTPRouteV2NotStrictly(ctx *TranslateContext, ar *configv2.ApisixRoute) error {
 	for _, part := range ar.Spec.HTTP {
+		if !part.IsEnabled() {
+			continue
+		}
 		backends := part.Backends
 		// Use the first backend as the default backend in Route,
 		// others will be configured in traffic-split plugin.
@@ -708,9 +1441,9 @@ func (t *translator) translateHTTPRouteV2NotStrictly(ctx *TranslateContext, ar *
 		upstreamName := apisixv1.ComposeUpstreamName(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		route := apisixv1.NewDefaultRoute()
 		route.Name = apisixv1.ComposeRouteName(ar.Namespace, ar.Name, part.Name)
-		route.ID = id.GenID(route.Name)
+		route.ID = id.GenID(id.Route, route.Name)
 		if part.PluginConfigName != "" {
-			route.PluginConfigId = id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, part.PluginConfigName))
+			route.PluginConfigId, _ = t.translatePluginConfigID(ar.Namespace, part.PluginConfigName, false)
 		}
 
 		ctx.AddRoute(route)
@@ -744,7 +1477,7 @@ func (t *translator) translateStreamRouteV2beta2(ctx *TranslateContext, ar *conf
 		}
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 		if err != nil {
@@ -752,10 +1485,13 @@ func (t *translator) translateStreamRouteV2beta2(ctx *TranslateContext, ar *conf
 		}
 		sr.UpstreamId = ups.ID
 		ctx.AddStreamRoute(sr)
-		if !ctx.CheckUpstreamExist(ups.Name) {
-			ctx.AddUpstream(ups)
+		if err := addStreamUpstream(ctx, ups); err != nil {
+			log.Errorw("ApisixRoute with conflicting stream route backend",
+				zap.Error(err),
+				zap.Any("apisix_route", ar),
+			)
+			return err
 		}
-
 	}
 	return nil
 }
@@ -779,7 +1515,7 @@ func (t *translator) translateStreamRouteV2beta3(ctx *TranslateContext, ar *conf
 		}
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 		if err != nil {
@@ -787,10 +1523,13 @@ func (t *translator) translateStreamRouteV2beta3(ctx *TranslateContext, ar *conf
 		}
 		sr.UpstreamId = ups.ID
 		ctx.AddStreamRoute(sr)
-		if !ctx.CheckUpstreamExist(ups.Name) {
-			ctx.AddUpstream(ups)
+		if err := addStreamUpstream(ctx, ups); err != nil {
+			log.Errorw("ApisixRoute with conflicting stream route backend",
+				zap.Error(err),
+				zap.Any("apisix_route", ar),
+			)
+			return err
 		}
-
 	}
 	return nil
 }
@@ -814,7 +1553,7 @@ func (t *translator) translateStreamRouteV2(ctx *TranslateContext, ar *configv2.
 		}
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstream(ar.Namespace, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
 		if err != nil {
@@ -822,21 +1561,39 @@ func (t *translator) translateStreamRouteV2(ctx *TranslateContext, ar *configv2.
 		}
 		sr.UpstreamId = ups.ID
 		ctx.AddStreamRoute(sr)
-		if !ctx.CheckUpstreamExist(ups.Name) {
-			ctx.AddUpstream(ups)
+		if err := addStreamUpstream(ctx, ups); err != nil {
+			log.Errorw("ApisixRoute with conflicting stream route backend",
+				zap.Error(err),
+				zap.Any("apisix_route", ar),
+			)
+			return err
 		}
-
 	}
 	return nil
 }
 
 // translateStreamRouteNotStrictlyV2beta2 translates tcp route with a loose way, only generate ID and Name for delete Event.
+// addStreamUpstream adds the upstream of a stream route rule, which might be
+// shared with the HTTP route rules in the same ApisixRoute, in which case they
+// must resolve to the same nodes, or one of them would be overwritten.
+func addStreamUpstream(ctx *TranslateContext, ups *apisixv1.Upstream) error {
+	existing := ctx.getUpstream(ups.Name)
+	if existing == nil {
+		ctx.AddUpstream(ups)
+		return nil
+	}
+	if !reflect.DeepEqual(existing.Nodes, ups.Nodes) {
+		return fmt.Errorf("upstream %s is shared by rules with different resolveGranularity", ups.Name)
+	}
+	return nil
+}
+
 func (t *translator) translateStreamRouteNotStrictlyV2beta2(ctx *TranslateContext, ar *configv2beta2.ApisixRoute) error {
 	for _, part := range ar.Spec.Stream {
 		backend := &part.Backend
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstreamNotStrictly(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		if err != nil {
@@ -857,7 +1614,7 @@ func (t *translator) translateStreamRouteNotStrictlyV2beta3(ctx *TranslateContex
 		backend := &part.Backend
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstreamNotStrictly(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		if err != nil {
@@ -878,7 +1635,7 @@ func (t *translator) translateStreamRouteNotStrictlyV2(ctx *TranslateContext, ar
 		backend := &part.Backend
 		sr := apisixv1.NewDefaultStreamRoute()
 		name := apisixv1.ComposeStreamRouteName(ar.Namespace, ar.Name, part.Name)
-		sr.ID = id.GenID(name)
+		sr.ID = id.GenID(id.StreamRoute, name)
 		sr.ServerPort = part.Match.IngressPort
 		ups, err := t.translateUpstreamNotStrictly(ar.Namespace, backend.ServiceName, backend.Subset, backend.ServicePort.IntVal)
 		if err != nil {
@@ -16,8 +16,11 @@ package translation
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -25,15 +28,18 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
 	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
 	_const "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/const"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
@@ -187,6 +193,58 @@ func TestRouteMatchExpr(t *testing.T) {
 	assert.Equal(t, []string{"foo.com"}, results[9][2].SliceVal)
 }
 
+func TestRouteMatchExprOperators(t *testing.T) {
+	tr := &translator{}
+	num := "13"
+	text := "foo"
+	subject := configv2.ApisixRouteHTTPMatchExprSubject{
+		Scope: _const.ScopeQuery,
+		Name:  "id",
+	}
+	testCases := []struct {
+		op       string
+		value    *string
+		set      []string
+		expected []apisixv1.StringOrSlice
+		err      string
+	}{
+		{op: _const.OpEqual, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "=="}, {StrVal: "foo"}}},
+		{op: _const.OpNotEqual, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "~="}, {StrVal: "foo"}}},
+		{op: _const.OpGreaterThan, value: &num, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: ">"}, {StrVal: "13"}}},
+		{op: _const.OpLessThan, value: &num, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "<"}, {StrVal: "13"}}},
+		{op: _const.OpIn, set: []string{"1", "2"}, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "in"}, {SliceVal: []string{"1", "2"}}}},
+		{op: _const.OpNotIn, set: []string{"1", "2"}, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "!"}, {StrVal: "in"}, {SliceVal: []string{"1", "2"}}}},
+		{op: _const.OpRegexMatch, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "~~"}, {StrVal: "foo"}}},
+		{op: _const.OpRegexMatchCaseInsensitive, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "~*"}, {StrVal: "foo"}}},
+		{op: _const.OpRegexNotMatch, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "!"}, {StrVal: "~~"}, {StrVal: "foo"}}},
+		{op: _const.OpRegexNotMatchCaseInsensitive, value: &text, expected: []apisixv1.StringOrSlice{{StrVal: "arg_id"}, {StrVal: "!"}, {StrVal: "~*"}, {StrVal: "foo"}}},
+
+		{op: "Contains", value: &text, err: "unknown operator"},
+		{op: _const.OpIn, value: &text, err: "empty set value"},
+		{op: _const.OpNotIn, value: &text, set: []string{"1"}, err: "value is not allowed with operator NotIn, use set instead"},
+		{op: _const.OpEqual, set: []string{"1"}, err: "neither set nor value is provided"},
+		{op: _const.OpEqual, value: &text, set: []string{"1"}, err: "set is not allowed with operator Equal, use value instead"},
+		{op: _const.OpGreaterThan, value: &text, err: "value of operator GreaterThan must be a number"},
+		{op: _const.OpLessThan, value: &text, err: "value of operator LessThan must be a number"},
+	}
+	for _, tc := range testCases {
+		vars, err := tr.translateRouteMatchExprs([]configv2.ApisixRouteHTTPMatchExpr{
+			{
+				Subject: subject,
+				Op:      tc.op,
+				Value:   tc.value,
+				Set:     tc.set,
+			},
+		})
+		if tc.err != "" {
+			assert.EqualError(t, err, tc.err, tc.op)
+			continue
+		}
+		assert.NoError(t, err, tc.op)
+		assert.Equal(t, [][]apisixv1.StringOrSlice{tc.expected}, vars, tc.op)
+	}
+}
+
 func mockTranslator(t *testing.T) (*translator, <-chan struct{}) {
 	svc := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{},
@@ -405,7 +463,7 @@ func TestTranslateApisixRouteV2beta3WithEmptyPluginConfigName(t *testing.T) {
 	assert.Len(t, res.PluginConfigs, 0)
 	assert.Len(t, res.Routes, 3)
 	assert.Equal(t, "", res.Routes[0].PluginConfigId)
-	expectedPluginId := id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, ar.Spec.HTTP[1].PluginConfigName))
+	expectedPluginId := id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName(ar.Namespace, ar.Spec.HTTP[1].PluginConfigName))
 	assert.Equal(t, expectedPluginId, res.Routes[1].PluginConfigId)
 	assert.Equal(t, "", res.Routes[2].PluginConfigId)
 }
@@ -478,11 +536,1283 @@ func TestTranslateApisixRouteV2beta3NotStrictly(t *testing.T) {
 	assert.Equal(t, "test_svc1_81", tx.Upstreams[0].Name, "upstream1 name error")
 	assert.Equal(t, "test_svc2_82", tx.Upstreams[1].Name, "upstream2 name error")
 
-	assert.Equal(t, id.GenID("test_ar_rule1"), tx.Routes[0].ID, "route1 id error")
-	assert.Equal(t, id.GenID("test_ar_rule2"), tx.Routes[1].ID, "route2 id error")
-	assert.Equal(t, id.GenID(apisixv1.ComposePluginConfigName(ar.Namespace, ar.Spec.HTTP[0].PluginConfigName)), tx.Routes[0].PluginConfigId, "route1 PluginConfigId error")
+	assert.Equal(t, id.GenID(id.Route, "test_ar_rule1"), tx.Routes[0].ID, "route1 id error")
+	assert.Equal(t, id.GenID(id.Route, "test_ar_rule2"), tx.Routes[1].ID, "route2 id error")
+	assert.Equal(t, id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName(ar.Namespace, ar.Spec.HTTP[0].PluginConfigName)), tx.Routes[0].PluginConfigId, "route1 PluginConfigId error")
 	assert.Equal(t, "", tx.Routes[1].PluginConfigId, "route2 PluginConfigId error ")
 
-	assert.Equal(t, id.GenID("test_svc1_81"), tx.Upstreams[0].ID, "upstream1 id error")
-	assert.Equal(t, id.GenID("test_svc2_82"), tx.Upstreams[1].ID, "upstream2 id error")
+	assert.Equal(t, id.GenID(id.Upstream, "test_svc1_81"), tx.Upstreams[0].ID, "upstream1 id error")
+	assert.Equal(t, id.GenID(id.Upstream, "test_svc2_82"), tx.Upstreams[1].ID, "upstream2 id error")
+}
+
+func TestTranslateApisixRouteV2WithFileLogger(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{
+							"/*",
+						},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+					FileLogger: &configv2.ApisixRouteHTTPFileLogger{
+						Path: "/var/log/apisix/access.log",
+						LogFormat: map[string]string{
+							"host":        "$host",
+							"client_ip":   "$remote_addr",
+							"@timestamp":  "$time_iso8601",
+							"environment": "production",
+						},
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	cfg, ok := res.Routes[0].Plugins["file-logger"].(*apisixv1.FileLoggerConfig)
+	assert.True(t, ok)
+	assert.Equal(t, "/var/log/apisix/access.log", cfg.Path)
+	assert.Equal(t, ar.Spec.HTTP[0].FileLogger.LogFormat, cfg.LogFormat)
+
+	data, err := json.Marshal(res.Routes[0].Plugins["file-logger"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"path":"/var/log/apisix/access.log","log_format":{"host":"$host","client_ip":"$remote_addr","@timestamp":"$time_iso8601","environment":"production"}}`, string(data))
+
+	for _, path := range []string{"", "access.log", "/var/log/", "/var/log/../access.log"} {
+		ar.Spec.HTTP[0].FileLogger.Path = path
+		_, err = tr.TranslateRouteV2(ar)
+		assert.Error(t, err, path)
+	}
+
+	// The fileLogger conflicts with the file-logger plugin.
+	ar.Spec.HTTP[0].FileLogger.Path = "/var/log/apisix/access.log"
+	ar.Spec.HTTP[0].Plugins = []configv2.ApisixRouteHTTPPlugin{
+		{
+			Name:   "file-logger",
+			Enable: true,
+		},
+	}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "fileLogger: conflicts with the file-logger plugin")
+}
+
+func TestTranslateApisixRouteV2WithPluginsFrom(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "plugins",
+			Namespace: "test",
+		},
+		Data: map[string]string{
+			"plugins.json": `{"limit-count":{"count":10,"time_window":60},"cors":{"allow_origins":"*"}}`,
+			"bad.json":     `{"limit-count":`,
+			"array.json":   `{"limit-count":[1]}`,
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(cm))
+	tr.ConfigMapLister = listerscorev1.NewConfigMapLister(indexer)
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{
+							"/*",
+						},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+					PluginsFrom: &configv2.ApisixRouteHTTPPluginsFrom{
+						ConfigMapName: "plugins",
+					},
+					Plugins: []configv2.ApisixRouteHTTPPlugin{
+						{
+							Name:   "limit-count",
+							Enable: true,
+							Config: configv2.ApisixRouteHTTPPluginConfig{
+								"count":       float64(100),
+								"time_window": float64(60),
+							},
+						},
+						{
+							Name:   "echo",
+							Enable: true,
+						},
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	plugins := res.Routes[0].Plugins
+	assert.Len(t, plugins, 3)
+	// Inline plugins take precedence.
+	assert.Equal(t, configv2.ApisixRouteHTTPPluginConfig{"count": float64(100), "time_window": float64(60)}, plugins["limit-count"])
+	assert.Equal(t, map[string]interface{}{"allow_origins": "*"}, plugins["cors"])
+
+	// Disabled inline plugins remove the ones from the ConfigMap.
+	ar.Spec.HTTP[0].Plugins[0].Enable = false
+	res, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.NotContains(t, res.Routes[0].Plugins, "limit-count")
+	assert.Contains(t, res.Routes[0].Plugins, "cors")
+
+	ar.Spec.HTTP[0].PluginsFrom.Key = "bad.json"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Contains(t, err.Error(), "invalid plugins JSON in ConfigMap plugins")
+
+	ar.Spec.HTTP[0].PluginsFrom.Key = "array.json"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, "pluginsFrom: config of plugin limit-count in ConfigMap plugins should be an object", err.Error())
+
+	ar.Spec.HTTP[0].PluginsFrom.Key = "missing.json"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, "pluginsFrom.key: key missing.json not found in ConfigMap plugins", err.Error())
+
+	ar.Spec.HTTP[0].PluginsFrom.ConfigMapName = "missing"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+}
+
+func TestTranslateApisixRouteWithServerlessPlugins(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "plugins",
+			Namespace: "test",
+		},
+		Data: map[string]string{
+			"plugins.json": `{"serverless-post-function":{"functions":["return function() end"]}}`,
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(cm))
+	tr.ConfigMapLister = listerscorev1.NewConfigMapLister(indexer)
+
+	backends := []configv2.ApisixRouteHTTPBackend{
+		{
+			ServiceName: "svc",
+			ServicePort: intstr.IntOrString{
+				IntVal: 80,
+			},
+		},
+	}
+	fn := map[string]interface{}{"functions": []interface{}{"return function() end"}}
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: backends,
+					Plugins: []configv2.ApisixRouteHTTPPlugin{
+						{
+							Name:   "serverless-pre-function",
+							Enable: true,
+							Config: fn,
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err := tr.TranslateRouteV2(ar)
+	assert.Equal(t, "plugins: plugin serverless-pre-function is not allowed since inline Lua functions are disabled by the controller", err.Error())
+
+	// Disabled plugins are fine.
+	ar.Spec.HTTP[0].Plugins[0].Enable = false
+	_, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+
+	ar.Spec.HTTP[0].PluginsFrom = &configv2.ApisixRouteHTTPPluginsFrom{
+		ConfigMapName: "plugins",
+	}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, "plugins: plugin serverless-post-function is not allowed since inline Lua functions are disabled by the controller", err.Error())
+
+	ar3 := &configv2beta3.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2beta3.ApisixRouteSpec{
+			HTTP: []configv2beta3.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2beta3.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: backends,
+					Plugins: []configv2beta3.ApisixRouteHTTPPlugin{
+						{
+							Name:   "serverless-post-function",
+							Enable: true,
+							Config: fn,
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err = tr.TranslateRouteV2beta3(ar3)
+	assert.Error(t, err)
+
+	ar2 := &configv2beta2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2beta2.ApisixRouteSpec{
+			HTTP: []configv2beta2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2beta2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: backends,
+					Plugins: []configv2beta2.ApisixRouteHTTPPlugin{
+						{
+							Name:   "serverless-pre-function",
+							Enable: true,
+							Config: fn,
+						},
+					},
+				},
+			},
+		},
+	}
+	_, err = tr.TranslateRouteV2beta2(ar2)
+	assert.Error(t, err)
+
+	tr.AllowServerlessFunctions = true
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Contains(t, res.Routes[0].Plugins, "serverless-post-function")
+	_, err = tr.TranslateRouteV2beta3(ar3)
+	assert.NoError(t, err)
+	_, err = tr.TranslateRouteV2beta2(ar2)
+	assert.NoError(t, err)
+}
+
+func TestTranslateApisixRouteV2WithMultiplePaths(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{
+							"/foo",
+							"/foo/*",
+						},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	// "/foo" is an exact match while "/foo/*" matches all the sub paths,
+	// both of them should be kept.
+	assert.Equal(t, []string{"/foo", "/foo/*"}, res.Routes[0].Uris)
+	assert.Equal(t, "", res.Routes[0].Uri)
+}
+
+func TestTranslateApisixRouteV2WithMethods(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths:   []string{"/foo"},
+						Methods: []string{"GET", "ALL"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	assert.Nil(t, res.Routes[0].Methods)
+	assert.Nil(t, res.Routes[0].Vars)
+
+	ar.Spec.HTTP[0].Match.Methods = nil
+	ar.Spec.HTTP[0].Match.NotMethods = []string{"OPTIONS"}
+	ar.Spec.HTTP[0].Match.NginxVars = []configv2.ApisixRouteHTTPMatchExpr{
+		{
+			Subject: configv2.ApisixRouteHTTPMatchExprSubject{
+				Scope: "Header",
+				Name:  "X-Foo",
+			},
+			Op:    "Equal",
+			Value: &[]string{"bar"}[0],
+		},
+	}
+	res, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	assert.Nil(t, res.Routes[0].Methods)
+	assert.Equal(t, apisixv1.Vars{
+		{{StrVal: "http_x_foo"}, {StrVal: "=="}, {StrVal: "bar"}},
+		{{StrVal: "request_method"}, {StrVal: "!"}, {StrVal: "in"}, {SliceVal: []string{"OPTIONS"}}},
+	}, res.Routes[0].Vars)
+
+	ar.Spec.HTTP[0].Match.Methods = []string{"GET"}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, "match.notMethods: can't be used together with match.methods", err.Error())
+
+	ar.Spec.HTTP[0].Match.Methods = nil
+	ar.Spec.HTTP[0].Match.NotMethods = []string{"FOO"}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, "match.notMethods: invalid method FOO", err.Error())
+}
+
+func TestTranslateApisixRouteV2WithServiceHosts(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	svc, err := tr.ServiceLister.Services("test").Get("svc")
+	assert.Nil(t, err)
+	svc = svc.DeepCopy()
+	svc.Annotations = map[string]string{
+		annotations.AnnotationsRouteHosts: "foo.com, *.foo.org",
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.Nil(t, indexer.Add(svc))
+	tr.ServiceLister = listerscorev1.NewServiceLister(indexer)
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+				},
+				{
+					Name: "rule2",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Hosts: []string{"bar.com"},
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 2)
+	assert.Equal(t, []string{"foo.com", "*.foo.org"}, res.Routes[0].Hosts)
+	// Hosts in the route rule take the precedence.
+	assert.Equal(t, []string{"bar.com"}, res.Routes[1].Hosts)
+
+	svc.Annotations[annotations.AnnotationsRouteHosts] = "foo.com,foo bar"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+}
+
+func TestTranslateApisixRouteV2WithCrossNamespacePluginConfig(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+					PluginConfigName: "other/apc",
+				},
+			},
+		},
+	}
+	_, err := tr.TranslateRouteV2(ar)
+	assert.Equal(t, _errCrossNamespacePluginConfig, err)
+
+	// Deleting the route is not affected.
+	res, err := tr.TranslateRouteV2NotStrictly(ar)
+	assert.NoError(t, err)
+	assert.Equal(t, id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName("other", "apc")), res.Routes[0].PluginConfigId)
+
+	// Referencing in the same namespace explicitly.
+	ar.Spec.HTTP[0].PluginConfigName = "test/apc"
+	res, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Equal(t, id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName("test", "apc")), res.Routes[0].PluginConfigId)
+
+	tr.AllowCrossNamespacePluginConfig = true
+	ar.Spec.HTTP[0].PluginConfigName = "other/apc"
+	res, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Equal(t, id.GenID(id.PluginConfig, apisixv1.ComposePluginConfigName("other", "apc")), res.Routes[0].PluginConfigId)
+}
+
+func TestTranslateApisixRouteV2SharedUpstream(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	newRoute := func(name, path string, port int) *configv2.ApisixRoute {
+		return &configv2.ApisixRoute{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+			},
+			Spec: configv2.ApisixRouteSpec{
+				HTTP: []configv2.ApisixRouteHTTP{
+					{
+						Name: "rule1",
+						Match: configv2.ApisixRouteHTTPMatch{
+							Paths: []string{path},
+						},
+						Backends: []configv2.ApisixRouteHTTPBackend{
+							{
+								ServiceName: "svc",
+								ServicePort: intstr.FromInt(port),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	// Routes of different ApisixRoutes backed by the same Service port
+	// share one upstream object.
+	res1, err := tr.TranslateRouteV2(newRoute("ar1", "/foo", 80))
+	assert.NoError(t, err)
+	res2, err := tr.TranslateRouteV2(newRoute("ar2", "/bar", 80))
+	assert.NoError(t, err)
+	assert.Len(t, res1.Upstreams, 1)
+	assert.Len(t, res2.Upstreams, 1)
+	assert.Equal(t, res1.Upstreams[0].ID, res2.Upstreams[0].ID)
+	assert.Equal(t, res1.Upstreams[0], res2.Upstreams[0])
+	assert.Equal(t, res1.Upstreams[0].ID, res1.Routes[0].UpstreamId)
+	assert.Equal(t, res1.Upstreams[0].ID, res2.Routes[0].UpstreamId)
+	assert.NotEqual(t, res1.Routes[0].ID, res2.Routes[0].ID)
+
+	res3, err := tr.TranslateRouteV2(newRoute("ar3", "/baz", 443))
+	assert.NoError(t, err)
+	assert.Len(t, res3.Upstreams, 1)
+	assert.NotEqual(t, res1.Upstreams[0].ID, res3.Upstreams[0].ID)
+}
+
+func TestTranslateApisixRouteV2WithHTTPAndStream(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+			Stream: []configv2.ApisixRouteStream{
+				{
+					// The same name as the HTTP rule.
+					Name:     "rule1",
+					Protocol: "TCP",
+					Match: configv2.ApisixRouteStreamMatch{
+						IngressPort: 9100,
+					},
+					Backend: configv2.ApisixRouteStreamBackend{
+						ServiceName: "svc",
+						ServicePort: intstr.FromInt(80),
+					},
+				},
+				{
+					Name:     "rule2",
+					Protocol: "TCP",
+					Match: configv2.ApisixRouteStreamMatch{
+						IngressPort: 9101,
+					},
+					Backend: configv2.ApisixRouteStreamBackend{
+						ServiceName: "svc",
+						ServicePort: intstr.FromInt(443),
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	assert.Len(t, res.StreamRoutes, 2)
+	assert.Len(t, res.Upstreams, 2)
+
+	assert.Equal(t, id.GenID(id.Route, "test_ar_rule1"), res.Routes[0].ID)
+	assert.Equal(t, id.GenID(id.StreamRoute, "test_ar_rule1_tcp"), res.StreamRoutes[0].ID)
+	assert.Equal(t, int32(9100), res.StreamRoutes[0].ServerPort)
+	assert.Equal(t, id.GenID(id.StreamRoute, "test_ar_rule2_tcp"), res.StreamRoutes[1].ID)
+	assert.Equal(t, int32(9101), res.StreamRoutes[1].ServerPort)
+
+	// The HTTP rule and the first stream rule share the same upstream.
+	assert.Equal(t, res.Routes[0].UpstreamId, res.StreamRoutes[0].UpstreamId)
+	assert.Equal(t, "test_svc_80", res.Upstreams[0].Name)
+	assert.Equal(t, apisixv1.ComposeUpstreamID(res.Upstreams[0]), res.Upstreams[0].ID)
+	assert.Equal(t, "test_svc_443", res.Upstreams[1].Name)
+	assert.Equal(t, apisixv1.ComposeUpstreamID(res.Upstreams[1]), res.Upstreams[1].ID)
+	assert.Equal(t, res.Upstreams[1].ID, res.StreamRoutes[1].UpstreamId)
+
+	// Deleting the route removes objects of both types.
+	res, err = tr.TranslateRouteV2NotStrictly(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	assert.Len(t, res.StreamRoutes, 2)
+	assert.Len(t, res.Upstreams, 2)
+
+	// The shared upstream cannot be resolved in different ways.
+	svc, err := tr.ServiceLister.Services("test").Get("svc")
+	assert.Nil(t, err)
+	svc = svc.DeepCopy()
+	svc.Spec.ClusterIP = "10.0.5.12"
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.Nil(t, indexer.Add(svc))
+	tr.ServiceLister = listerscorev1.NewServiceLister(indexer)
+
+	ar.Spec.HTTP[0].Backends[0].ResolveGranularity = "service"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+}
+
+func TestTranslateRouteTimeout(t *testing.T) {
+	timeout, err := translateRouteTimeout(0, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, &apisixv1.UpstreamTimeout{
+		Connect: apisixv1.DefaultUpstreamTimeout,
+		Send:    apisixv1.DefaultUpstreamTimeout,
+		Read:    apisixv1.DefaultUpstreamTimeout,
+	}, timeout)
+
+	timeout, err = translateRouteTimeout(5*time.Second, 0, 90*time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, &apisixv1.UpstreamTimeout{
+		Connect: 5,
+		Send:    apisixv1.DefaultUpstreamTimeout,
+		Read:    90,
+	}, timeout)
+
+	_, err = translateRouteTimeout(-time.Second, 0, 0)
+	assert.Equal(t, &translateError{field: "timeout.connect", reason: "should be at least 1s"}, err)
+	_, err = translateRouteTimeout(0, 500*time.Millisecond, 0)
+	assert.Equal(t, &translateError{field: "timeout.send", reason: "should be at least 1s"}, err)
+}
+
+func TestTranslateApisixRouteV2WithMethodBackends(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+					MethodBackends: []configv2.ApisixRouteHTTPMethodBackend{
+						{
+							Methods: []string{"POST", "PUT"},
+							Backend: configv2.ApisixRouteHTTPBackend{
+								ServiceName: "svc",
+								ServicePort: intstr.FromInt(443),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tctx, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, tctx.Routes, 1)
+	assert.Len(t, tctx.Upstreams, 2)
+
+	assert.Equal(t, apisixv1.ComposeUpstreamName("test", "svc", "", 443), tctx.Upstreams[0].Name)
+	writeUps := tctx.Upstreams[0].ID
+	assert.Equal(t, &apisixv1.TrafficSplitConfig{
+		Rules: []apisixv1.TrafficSplitConfigRule{
+			{
+				Match: []apisixv1.TrafficSplitConfigRuleMatch{
+					{
+						Vars: apisixv1.Vars{
+							{{StrVal: "request_method"}, {StrVal: "in"}, {SliceVal: []string{"POST", "PUT"}}},
+						},
+					},
+				},
+				WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: writeUps, Weight: _defaultWeight},
+				},
+			},
+		},
+	}, tctx.Routes[0].Plugins["traffic-split"])
+
+	// Methods are validated.
+	ar.Spec.HTTP[0].MethodBackends[0].Methods = []string{"post"}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, &translateError{field: "methodBackends[0].methods", reason: "invalid method post"}, err)
+
+	// Methods which can never hit the route are rejected.
+	ar.Spec.HTTP[0].Match.Methods = []string{"GET", "POST"}
+	ar.Spec.HTTP[0].MethodBackends[0].Methods = []string{"POST", "PUT"}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Equal(t, &translateError{field: "methodBackends[0].methods", reason: "method PUT is not matched by the route"}, err)
+
+	// The backend Service should exist.
+	ar.Spec.HTTP[0].Match.Methods = nil
+	ar.Spec.HTTP[0].MethodBackends[0].Backend.ServiceName = "svc2"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+}
+
+func TestValidateRouteUpstreamScheme(t *testing.T) {
+	route := &apisixv1.Route{EnableWebsocket: true}
+	assert.Nil(t, validateRouteUpstreamScheme(route, nil))
+	assert.Nil(t, validateRouteUpstreamScheme(route, &apisixv1.Upstream{Scheme: apisixv1.SchemeHTTPS}))
+	assert.Equal(t, &translateError{
+		field:  "websocket",
+		reason: "not supported by upstream with grpcs scheme",
+	}, validateRouteUpstreamScheme(route, &apisixv1.Upstream{Scheme: apisixv1.SchemeGRPCS}))
+
+	route.EnableWebsocket = false
+	assert.Nil(t, validateRouteUpstreamScheme(route, &apisixv1.Upstream{Scheme: apisixv1.SchemeGRPC}))
+}
+
+func TestValidateGRPCMatch(t *testing.T) {
+	match := &configv2.ApisixRouteHTTPMatch{
+		GRPC: &configv2.ApisixRouteHTTPMatchGRPC{
+			Service: "helloworld.Greeter",
+			Methods: []string{"SayHello", "SayHello", "SayGoodbye"},
+		},
+	}
+	assert.Nil(t, ValidateGRPCMatch(match))
+	assert.Equal(t, []string{"/helloworld.Greeter/SayHello", "/helloworld.Greeter/SayGoodbye"}, translateGRPCMatch(match.GRPC))
+
+	match.GRPC.Methods = nil
+	assert.Equal(t, []string{"/helloworld.Greeter/*"}, translateGRPCMatch(match.GRPC))
+
+	match.Paths = []string{"/foo"}
+	assert.Equal(t, &translateError{
+		field:  "match.grpc",
+		reason: "can't be used together with match.paths",
+	}, ValidateGRPCMatch(match))
+
+	match.Paths = nil
+	match.Methods = []string{"GET"}
+	assert.Equal(t, &translateError{
+		field:  "match.grpc",
+		reason: "can't be used together with match.methods or match.notMethods",
+	}, ValidateGRPCMatch(match))
+
+	match.Methods = nil
+	match.GRPC.Service = "helloworld..Greeter"
+	assert.Equal(t, &translateError{
+		field:  "match.grpc.service",
+		reason: `invalid service name "helloworld..Greeter"`,
+	}, ValidateGRPCMatch(match))
+
+	match.GRPC.Service = "Greeter"
+	match.GRPC.Methods = []string{"Say/Hello"}
+	assert.Equal(t, &translateError{
+		field:  "match.grpc.methods",
+		reason: `invalid method name "Say/Hello"`,
+	}, ValidateGRPCMatch(match))
+}
+
+func TestValidateGRPCRouteUpstreams(t *testing.T) {
+	ctx := DefaultEmptyTranslateContext()
+	grpcUps := apisixv1.NewDefaultUpstream()
+	grpcUps.Name = "default_grpc_50051"
+	grpcUps.ID = id.GenID(id.Upstream, grpcUps.Name)
+	grpcUps.Scheme = apisixv1.SchemeGRPC
+	ctx.AddUpstream(grpcUps)
+	httpUps := apisixv1.NewDefaultUpstream()
+	httpUps.Name = "default_httpbin_80"
+	httpUps.ID = id.GenID(id.Upstream, httpUps.Name)
+	ctx.AddUpstream(httpUps)
+
+	route := apisixv1.NewDefaultRoute()
+	route.UpstreamId = grpcUps.ID
+	assert.Nil(t, validateGRPCRouteUpstreams(ctx, route))
+
+	route.Plugins = apisixv1.Plugins{"traffic-split": &apisixv1.TrafficSplitConfig{
+		Rules: []apisixv1.TrafficSplitConfigRule{
+			{
+				WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: httpUps.ID, Weight: 10},
+					{Weight: 10},
+				},
+			},
+		},
+	}}
+	assert.Equal(t, &translateError{
+		field:  "match.grpc",
+		reason: "requires upstream with grpc or grpcs scheme, but upstream default_httpbin_80 uses http",
+	}, validateGRPCRouteUpstreams(ctx, route))
+}
+
+func TestTranslateApisixRouteV2WithDisabledRule(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	disabled := false
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/foo"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+				{
+					Name:   "rule2",
+					Enable: &disabled,
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/bar"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(443),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tctx, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, tctx.Routes, 1)
+	assert.Len(t, tctx.Upstreams, 1)
+	assert.Equal(t, "test_ar_rule1", tctx.Routes[0].Name)
+
+	tctx, err = tr.TranslateRouteV2NotStrictly(ar)
+	assert.NoError(t, err)
+	assert.Len(t, tctx.Routes, 1)
+
+	// Re-enabling the rule brings its route back.
+	enabled := true
+	ar.Spec.HTTP[1].Enable = &enabled
+	tctx, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, tctx.Routes, 2)
+	assert.Len(t, tctx.Upstreams, 2)
+	assert.Equal(t, "test_ar_rule2", tctx.Routes[1].Name)
+}
+
+func TestTranslateApisixRouteV2WithPriority(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/foo"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+				{
+					Name:     "rule2",
+					Priority: 10,
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/foo"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	tctx, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, tctx.Routes, 2)
+	assert.Equal(t, 0, tctx.Routes[0].Priority)
+	assert.Equal(t, 10, tctx.Routes[1].Priority)
+
+	// The default priority isn't sent to APISIX.
+	data, err := json.Marshal(tctx.Routes[0])
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "priority")
+	data, err = json.Marshal(tctx.Routes[1])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"priority":10`)
+}
+
+func TestTranslateApisixRouteV2WithRewrite(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/old/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+					Rewrite: &configv2.ApisixRouteHTTPRewrite{
+						Regex:       `^/old/(.*)\.html$`,
+						Replacement: "/new/$1",
+					},
+				},
+			},
+		},
+	}
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	data, err := json.Marshal(res.Routes[0].Plugins["proxy-rewrite"])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"regex_uri":["^/old/(.*)\\.html$","/new/$1"]}`, string(data))
+
+	for _, replacement := range []string{"/new/${1}", "/new/$0", "/new/$$1", "/new"} {
+		ar.Spec.HTTP[0].Rewrite.Replacement = replacement
+		_, err = tr.TranslateRouteV2(ar)
+		assert.NoError(t, err, replacement)
+	}
+
+	for _, replacement := range []string{"/new/$2", "/new/${2}", "/new/${1", "/new/$a", "/new/$", "/new/${-1}"} {
+		ar.Spec.HTTP[0].Rewrite.Replacement = replacement
+		_, err = tr.TranslateRouteV2(ar)
+		assert.Error(t, err, replacement)
+	}
+
+	ar.Spec.HTTP[0].Rewrite = &configv2.ApisixRouteHTTPRewrite{Regex: "/old/(.*", Replacement: "/new/$1"}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+
+	// The rewrite conflicts with the proxy-rewrite plugin.
+	ar.Spec.HTTP[0].Rewrite = &configv2.ApisixRouteHTTPRewrite{Regex: "/old/(.*)", Replacement: "/new/$1"}
+	ar.Spec.HTTP[0].Plugins = []configv2.ApisixRouteHTTPPlugin{
+		{
+			Name:   "proxy-rewrite",
+			Enable: true,
+		},
+	}
+	_, err = tr.TranslateRouteV2(ar)
+	assert.Error(t, err)
+}
+
+func TestTranslateApisixRouteV2ResolveGranularity(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	svc, err := tr.ServiceLister.Services("test").Get("svc")
+	assert.Nil(t, err)
+	svc = svc.DeepCopy()
+	svc.Spec.ClusterIP = "10.0.5.12"
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	assert.Nil(t, indexer.Add(svc))
+	tr.ServiceLister = listerscorev1.NewServiceLister(indexer)
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// The endpoint granularity (default) uses the pod IPs as nodes.
+	for _, granularity := range []string{"", "endpoint"} {
+		ar.Spec.HTTP[0].Backends[0].ResolveGranularity = granularity
+		res, err := tr.TranslateRouteV2(ar)
+		assert.NoError(t, err)
+		assert.Len(t, res.Upstreams, 1)
+		assert.Equal(t, apisixv1.UpstreamNodes{
+			{Host: "192.168.1.1", Port: 9080, Weight: 100},
+			{Host: "192.168.1.2", Port: 9080, Weight: 100},
+		}, res.Upstreams[0].Nodes)
+	}
+
+	// The service granularity uses the cluster IP as the only node.
+	ar.Spec.HTTP[0].Backends[0].ResolveGranularity = "service"
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Upstreams, 1)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.5.12", Port: 80, Weight: 100},
+	}, res.Upstreams[0].Nodes)
+}
+
+func TestTranslateApisixRouteV2WithCanary(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+
+	value := "true"
+	weight := 10
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+					Canary: &configv2.ApisixRouteHTTPCanary{
+						Rules: []configv2.ApisixRouteHTTPCanaryRule{
+							{
+								Exprs: []configv2.ApisixRouteHTTPMatchExpr{
+									{
+										Subject: configv2.ApisixRouteHTTPMatchExprSubject{
+											Scope: _const.ScopeHeader,
+											Name:  "X-Canary",
+										},
+										Op:    _const.OpEqual,
+										Value: &value,
+									},
+								},
+								Backends: []configv2.ApisixRouteHTTPBackend{
+									{
+										ServiceName: "svc",
+										ServicePort: intstr.FromInt(443),
+									},
+								},
+							},
+							{
+								Backends: []configv2.ApisixRouteHTTPBackend{
+									{
+										ServiceName: "svc",
+										ServicePort: intstr.FromInt(443),
+										Weight:      &weight,
+									},
+								},
+								StableWeight: 90,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	res, err := tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	assert.Len(t, res.Routes, 1)
+	assert.Len(t, res.Upstreams, 2)
+	assert.Equal(t, "test_svc_80", res.Upstreams[1].Name)
+	assert.Equal(t, res.Upstreams[1].ID, res.Routes[0].UpstreamId)
+
+	assert.Equal(t, "test_svc_443", res.Upstreams[0].Name)
+	canaryID := res.Upstreams[0].ID
+	assert.Equal(t, &apisixv1.TrafficSplitConfig{
+		Rules: []apisixv1.TrafficSplitConfigRule{
+			{
+				Match: []apisixv1.TrafficSplitConfigRuleMatch{
+					{
+						Vars: apisixv1.Vars{
+							{{StrVal: "http_x_canary"}, {StrVal: "=="}, {StrVal: "true"}},
+						},
+					},
+				},
+				WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: canaryID, Weight: 100},
+					{Weight: 0},
+				},
+			},
+			{
+				WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: canaryID, Weight: 10},
+					{Weight: 90},
+				},
+			},
+		},
+	}, res.Routes[0].Plugins["traffic-split"])
+
+	// Negative weights.
+	canary := ar.Spec.HTTP[0].Canary
+	weight = -1
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "canary.rules[1].backends[0].weight: should not be negative")
+	weight = 0
+	_, err = tr.TranslateRouteV2(ar)
+	assert.NoError(t, err)
+	canary.Rules[1].StableWeight = -1
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "canary.rules[1].stableWeight: should not be negative")
+	canary.Rules[1].StableWeight = 0
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "canary.rules[1]: all weights are zero")
+	canary.Rules[1].StableWeight = 90
+
+	// The header name cannot be mapped to a valid APISIX variable.
+	canary.Rules[0].Exprs[0].Subject.Name = "x.canary"
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "canary.rules[0].exprs[0].subject: invalid APISIX variable http_x.canary")
+	canary.Rules[0].Exprs[0].Subject.Name = "X-Canary"
+
+	// Canary cannot be mixed with the weighted backends.
+	ar.Spec.HTTP[0].Backends = append(ar.Spec.HTTP[0].Backends, configv2.ApisixRouteHTTPBackend{
+		ServiceName: "svc",
+		ServicePort: intstr.FromInt(443),
+	})
+	_, err = tr.TranslateRouteV2(ar)
+	assert.EqualError(t, err, "canary: cannot be used together with multiple backends")
+}
+
+func TestTranslateApisixRouteV2WithRouteLabels(t *testing.T) {
+	tr, processCh := mockTranslator(t)
+	<-processCh
+	<-processCh
+	tr.RouteLabelKeys = []string{"team", "empty"}
+	tr.RouteLabelPrefix = "obs/"
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+			Labels: map[string]string{
+				"team":       "infra",
+				"empty":      "",
+				"app":        "httpbin",
+				"obs/env":    strings.Repeat("x", 70),
+				"managed-by": "someone",
+			},
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{
+							"/*",
+						},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.IntOrString{
+								IntVal: 80,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tctx, err := tr.TranslateRouteV2(ar)
+	assert.Nil(t, err)
+	assert.Len(t, tctx.Routes, 1)
+	assert.Equal(t, map[string]string{
+		"managed-by":       "apisix-ingress-controller",
+		"source-kind":      "ApisixRoute",
+		"source-namespace": "test",
+		"source-name":      "ar",
+		"team":             "infra",
+		"obs/env":          strings.Repeat("x", 64),
+	}, tctx.Routes[0].Labels)
 }
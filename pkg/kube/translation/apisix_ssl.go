@@ -15,13 +15,17 @@
 package translation
 
 import (
+	"crypto/x509"
+	"encoding/pem"
 	"errors"
 
+	"go.uber.org/zap"
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/log"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
@@ -32,6 +36,12 @@ var (
 	ErrEmptyCert = errors.New("missing cert field")
 	// ErrEmptyPrivKey means the key field in Kubernetes Secret is not found.
 	ErrEmptyPrivKey = errors.New("missing key field")
+	// ErrEmptySNIs means neither the hosts nor the certificate SANs are
+	// available as SNIs.
+	ErrEmptySNIs = errors.New("no SNI found in hosts or certificate SANs")
+	// ErrNoPEMCertificate means the cert field doesn't contain a PEM
+	// encoded certificate.
+	ErrNoPEMCertificate = errors.New("no PEM encoded certificate found")
 )
 
 func (t *translator) TranslateSSLV2Beta3(tls *configv2beta3.ApisixTls) (*apisixv1.Ssl, error) {
@@ -49,14 +59,12 @@ func (t *translator) TranslateSSLV2Beta3(tls *configv2beta3.ApisixTls) (*apisixv
 		snis = append(snis, string(host))
 	}
 	ssl := &apisixv1.Ssl{
-		ID:     id.GenID(tls.Namespace + "_" + tls.Name),
+		ID:     id.GenID(id.SSL, tls.Namespace+"_"+tls.Name),
 		Snis:   snis,
 		Cert:   string(cert),
 		Key:    string(key),
 		Status: 1,
-		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
-		},
+		Labels: sourceLabels("ApisixTls", tls),
 	}
 	if tls.Spec.Client != nil {
 		caSecret, err := t.SecretLister.Secrets(tls.Spec.Client.CASecret.Namespace).Get(tls.Spec.Client.CASecret.Name)
@@ -90,15 +98,37 @@ func (t *translator) TranslateSSLV2(tls *configv2.ApisixTls) (*apisixv1.Ssl, err
 	for _, host := range tls.Spec.Hosts {
 		snis = append(snis, string(host))
 	}
+	if tls.Spec.AutoSNI {
+		sans, err := certificateSANs(cert)
+		if err != nil {
+			log.Warnw("failed to parse certificate SANs, only hosts are used as SNIs",
+				zap.Error(err),
+				zap.String("ApisixTls", tls.Namespace+"/"+tls.Name),
+			)
+		}
+		for _, san := range sans {
+			found := false
+			for _, sni := range snis {
+				if sni == san {
+					found = true
+					break
+				}
+			}
+			if !found {
+				snis = append(snis, san)
+			}
+		}
+	}
+	if len(snis) == 0 {
+		return nil, ErrEmptySNIs
+	}
 	ssl := &apisixv1.Ssl{
-		ID:     id.GenID(tls.Namespace + "_" + tls.Name),
+		ID:     id.GenID(id.SSL, tls.Namespace+"_"+tls.Name),
 		Snis:   snis,
 		Cert:   string(cert),
 		Key:    string(key),
 		Status: 1,
-		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
-		},
+		Labels: sourceLabels("ApisixTls", tls),
 	}
 	if tls.Spec.Client != nil {
 		caSecret, err := t.SecretLister.Secrets(tls.Spec.Client.CASecret.Namespace).Get(tls.Spec.Client.CASecret.Name)
@@ -159,3 +189,17 @@ func (t *translator) extractKubeSecretKeyPair(s *v1.Secret, hasPrivateKey bool)
 	}
 	return
 }
+
+// certificateSANs returns the DNS names in the SAN list of the first
+// certificate in the PEM encoded cert.
+func certificateSANs(cert []byte) ([]string, error) {
+	block, _ := pem.Decode(cert)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, ErrNoPEMCertificate
+	}
+	crt, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	return crt.DNSNames, nil
+}
@@ -0,0 +1,103 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+)
+
+func genTestCert(t *testing.T, dnsNames ...string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		DNSNames:     dnsNames,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestTranslateSSLV2AutoSNI(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "wildcard",
+			Namespace: "default",
+		},
+		Data: map[string][]byte{
+			"cert": genTestCert(t, "*.example.com", "example.com", "api.example.org"),
+			"key":  []byte("key"),
+		},
+	}
+	client := fake.NewSimpleClientset()
+	informer := informers.NewSharedInformerFactory(client, 0).Core().V1().Secrets()
+	assert.Nil(t, informer.Informer().GetIndexer().Add(secret))
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			SecretLister: informer.Lister(),
+		},
+	}
+
+	tls := &configv2.ApisixTls{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "tls",
+			Namespace: "default",
+		},
+		Spec: &configv2.ApisixTlsSpec{
+			Hosts: []configv2.HostType{"example.com"},
+			Secret: configv2.ApisixSecret{
+				Name:      "wildcard",
+				Namespace: "default",
+			},
+		},
+	}
+	ssl, err := tr.TranslateSSLV2(tls)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com"}, ssl.Snis)
+
+	tls.Spec.AutoSNI = true
+	ssl, err = tr.TranslateSSLV2(tls)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com", "*.example.com", "api.example.org"}, ssl.Snis)
+
+	// Parse errors fall back to the hosts.
+	secret.Data["cert"] = []byte("not a certificate")
+	ssl, err = tr.TranslateSSLV2(tls)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"example.com"}, ssl.Snis)
+
+	tls.Spec.Hosts = nil
+	_, err = tr.TranslateSSLV2(tls)
+	assert.Equal(t, ErrEmptySNIs, err)
+}
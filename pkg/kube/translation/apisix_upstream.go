@@ -16,6 +16,9 @@ package translation
 
 import (
 	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
@@ -70,6 +73,55 @@ func (t *translator) translateUpstreamRetriesAndTimeout(retries *int, timeout *c
 	return nil
 }
 
+func (t *translator) translateUpstreamRetryTimeout(retryTimeout *metav1.Duration, ups *apisixv1.Upstream) error {
+	if retryTimeout == nil {
+		return nil
+	}
+	// APISIX accepts retry_timeout in seconds and treats 0 as no limit,
+	// so a sub-second value would silently disable the limit.
+	if retryTimeout.Duration < 0 || (retryTimeout.Duration > 0 && retryTimeout.Duration < time.Second) {
+		return &translateError{
+			field:  "retryTimeout",
+			reason: "invalid value",
+		}
+	}
+	seconds := int(retryTimeout.Seconds())
+	ups.RetryTimeout = &seconds
+	return nil
+}
+
+func (t *translator) translateUpstreamKeepalivePool(kp *configv2beta3.UpstreamKeepalivePool, ups *apisixv1.Upstream) error {
+	if kp == nil {
+		return nil
+	}
+	if kp.Size < 0 {
+		return &translateError{
+			field:  "keepalivePool.size",
+			reason: "invalid value",
+		}
+	}
+	if kp.Requests < 0 {
+		return &translateError{
+			field:  "keepalivePool.requests",
+			reason: "invalid value",
+		}
+	}
+	// APISIX accepts idle_timeout in seconds, a sub-second value can't be
+	// represented.
+	if kp.IdleTimeout.Duration < 0 || (kp.IdleTimeout.Duration > 0 && kp.IdleTimeout.Duration < time.Second) {
+		return &translateError{
+			field:  "keepalivePool.idleTimeout",
+			reason: "invalid value",
+		}
+	}
+	ups.KeepalivePool = &apisixv1.UpstreamKeepalivePool{
+		Size:        kp.Size,
+		IdleTimeout: int(kp.IdleTimeout.Seconds()),
+		Requests:    kp.Requests,
+	}
+	return nil
+}
+
 func (t *translator) translateUpstreamScheme(scheme string, ups *apisixv1.Upstream) error {
 	if scheme == "" {
 		ups.Scheme = apisixv1.SchemeHTTP
@@ -84,6 +136,30 @@ func (t *translator) translateUpstreamScheme(scheme string, ups *apisixv1.Upstre
 	}
 }
 
+func (t *translator) translateUpstreamPassHost(passHost, upstreamHost string, ups *apisixv1.Upstream) error {
+	switch passHost {
+	case "", apisixv1.PassHostPass, apisixv1.PassHostNode:
+		if upstreamHost != "" {
+			return &translateError{
+				field:  "upstreamHost",
+				reason: "only allowed when passHost is rewrite",
+			}
+		}
+	case apisixv1.PassHostRewrite:
+		if upstreamHost == "" {
+			return &translateError{
+				field:  "upstreamHost",
+				reason: "required when passHost is rewrite",
+			}
+		}
+	default:
+		return &translateError{field: "passHost", reason: "invalid value"}
+	}
+	ups.PassHost = passHost
+	ups.UpstreamHost = upstreamHost
+	return nil
+}
+
 func (t *translator) translateUpstreamLoadBalancer(lb *configv2beta3.LoadBalancer, ups *apisixv1.Upstream) error {
 	if lb == nil || lb.Type == "" {
 		ups.Type = apisixv1.LbRoundRobin
@@ -152,6 +228,14 @@ func (t translator) translateClientTLS(config *configv2beta3.ApisixSecret, ups *
 	if config == nil {
 		return nil
 	}
+	// The client certificate is only used in the TLS handshake, which
+	// doesn't happen with the plain text schemes.
+	if ups.Scheme != apisixv1.SchemeHTTPS && ups.Scheme != apisixv1.SchemeGRPCS {
+		return &translateError{
+			field:  "tlsSecret",
+			reason: "only allowed with https or grpcs scheme",
+		}
+	}
 	s, err := t.SecretLister.Secrets(config.Namespace).Get(config.Name)
 	if err != nil {
 		return &translateError{
@@ -173,6 +257,27 @@ func (t translator) translateClientTLS(config *configv2beta3.ApisixSecret, ups *
 	return nil
 }
 
+func (t *translator) translateUpstreamTLS(config *configv2beta3.ApisixUpstreamTLS, ups *apisixv1.Upstream) error {
+	if config == nil {
+		return nil
+	}
+	if ups.Scheme != apisixv1.SchemeHTTPS && ups.Scheme != apisixv1.SchemeGRPCS {
+		return &translateError{
+			field:  "tls",
+			reason: "only allowed with https or grpcs scheme",
+		}
+	}
+	verify := true
+	if config.Verify != nil {
+		verify = *config.Verify
+	}
+	if ups.TLS == nil {
+		ups.TLS = &apisixv1.ClientTLS{}
+	}
+	ups.TLS.Verify = &verify
+	return nil
+}
+
 func (t *translator) translateUpstreamActiveHealthCheck(config *configv2beta3.ActiveHealthCheck) (*apisixv1.UpstreamActiveHealthCheck, error) {
 	var active apisixv1.UpstreamActiveHealthCheck
 	switch config.Type {
@@ -187,6 +292,15 @@ func (t *translator) translateUpstreamActiveHealthCheck(config *configv2beta3.Ac
 		}
 	}
 
+	if active.Type == apisixv1.HealthCheckTCP {
+		if field := activeHealthCheckHTTPField(config); field != "" {
+			return nil, &translateError{
+				field:  "healthCheck.active." + field,
+				reason: "not allowed when type is tcp",
+			}
+		}
+	}
+
 	active.Timeout = int(config.Timeout.Seconds())
 	if config.Port < 0 || config.Port > 65535 {
 		return nil, &translateError{
@@ -208,7 +322,7 @@ func (t *translator) translateUpstreamActiveHealthCheck(config *configv2beta3.Ac
 	active.HTTPPath = config.HTTPPath
 	active.HTTPRequestHeaders = config.RequestHeaders
 
-	if config.StrictTLS == nil || *config.StrictTLS {
+	if active.Type != apisixv1.HealthCheckTCP && (config.StrictTLS == nil || *config.StrictTLS) {
 		active.HTTPSVerifyCert = true
 	}
 
@@ -288,6 +402,14 @@ func (t *translator) translateUpstreamPassiveHealthCheck(config *configv2beta3.P
 			reason: "invalid value",
 		}
 	}
+	if passive.Type == apisixv1.HealthCheckTCP {
+		if field := passiveHealthCheckHTTPField(config); field != "" {
+			return nil, &translateError{
+				field:  "healthCheck.passive." + field,
+				reason: "not allowed when type is tcp",
+			}
+		}
+	}
 	if config.Healthy != nil {
 		// zero means use the default value.
 		if config.Healthy.Successes < 0 || config.Healthy.Successes > apisixv1.HealthCheckMaxConsecutiveNumber {
@@ -334,3 +456,39 @@ func (t *translator) translateUpstreamPassiveHealthCheck(config *configv2beta3.P
 	}
 	return &passive, nil
 }
+
+// activeHealthCheckHTTPField returns the first HTTP specific field set in the
+// active health check, or empty if there is none.
+func activeHealthCheckHTTPField(config *configv2beta3.ActiveHealthCheck) string {
+	switch {
+	case config.Host != "":
+		return "host"
+	case config.HTTPPath != "":
+		return "httpPath"
+	case config.StrictTLS != nil:
+		return "strictTLS"
+	case len(config.RequestHeaders) > 0:
+		return "requestHeaders"
+	case config.Healthy != nil && config.Healthy.HTTPCodes != nil:
+		return "healthy.httpCodes"
+	case config.Unhealthy != nil && config.Unhealthy.HTTPCodes != nil:
+		return "unhealthy.httpCodes"
+	case config.Unhealthy != nil && config.Unhealthy.HTTPFailures != 0:
+		return "unhealthy.httpFailures"
+	}
+	return ""
+}
+
+// passiveHealthCheckHTTPField returns the first HTTP specific field set in
+// the passive health check, or empty if there is none.
+func passiveHealthCheckHTTPField(config *configv2beta3.PassiveHealthCheck) string {
+	switch {
+	case config.Healthy != nil && config.Healthy.HTTPCodes != nil:
+		return "healthy.httpCodes"
+	case config.Unhealthy != nil && config.Unhealthy.HTTPCodes != nil:
+		return "unhealthy.httpCodes"
+	case config.Unhealthy != nil && config.Unhealthy.HTTPFailures != 0:
+		return "unhealthy.httpFailures"
+	}
+	return ""
+}
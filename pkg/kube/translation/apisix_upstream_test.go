@@ -15,6 +15,7 @@
 package translation
 
 import (
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -94,6 +95,96 @@ func TestTranslateUpstreamHealthCheck(t *testing.T) {
 	})
 }
 
+func TestTranslateUpstreamTCPHealthCheck(t *testing.T) {
+	tr := &translator{}
+	hc := &configv2beta3.HealthCheck{
+		Active: &configv2beta3.ActiveHealthCheck{
+			Type:    apisixv1.HealthCheckTCP,
+			Timeout: 3 * time.Second,
+			Port:    6379,
+			Healthy: &configv2beta3.ActiveHealthCheckHealthy{
+				PassiveHealthCheckHealthy: configv2beta3.PassiveHealthCheckHealthy{
+					Successes: 2,
+				},
+				Interval: metav1.Duration{Duration: 2 * time.Second},
+			},
+			Unhealthy: &configv2beta3.ActiveHealthCheckUnhealthy{
+				PassiveHealthCheckUnhealthy: configv2beta3.PassiveHealthCheckUnhealthy{
+					TCPFailures: 3,
+					Timeouts:    3,
+				},
+				Interval: metav1.Duration{Duration: time.Second},
+			},
+		},
+		Passive: &configv2beta3.PassiveHealthCheck{
+			Type: apisixv1.HealthCheckTCP,
+			Unhealthy: &configv2beta3.PassiveHealthCheckUnhealthy{
+				TCPFailures: 2,
+			},
+		},
+	}
+
+	var ups apisixv1.Upstream
+	err := tr.translateUpstreamHealthCheck(hc, &ups)
+	assert.Nil(t, err, "translating upstream health check")
+	assert.Equal(t, &apisixv1.UpstreamActiveHealthCheck{
+		Type:    apisixv1.HealthCheckTCP,
+		Timeout: 3,
+		Port:    6379,
+		Healthy: apisixv1.UpstreamActiveHealthCheckHealthy{
+			Interval: 2,
+			UpstreamPassiveHealthCheckHealthy: apisixv1.UpstreamPassiveHealthCheckHealthy{
+				Successes: 2,
+			},
+		},
+		Unhealthy: apisixv1.UpstreamActiveHealthCheckUnhealthy{
+			Interval: 1,
+			UpstreamPassiveHealthCheckUnhealthy: apisixv1.UpstreamPassiveHealthCheckUnhealthy{
+				TCPFailures: 3,
+				Timeouts:    3,
+			},
+		},
+	}, ups.Checks.Active)
+	assert.Equal(t, &apisixv1.UpstreamPassiveHealthCheck{
+		Type: apisixv1.HealthCheckTCP,
+		Unhealthy: apisixv1.UpstreamPassiveHealthCheckUnhealthy{
+			TCPFailures: 2,
+		},
+	}, ups.Checks.Passive)
+
+	// HTTP specific fields are rejected.
+	hc.Active.HTTPPath = "/healthz"
+	err = tr.translateUpstreamHealthCheck(hc, &ups)
+	assert.Equal(t, &translateError{
+		field:  "healthCheck.active.httpPath",
+		reason: "not allowed when type is tcp",
+	}, err)
+
+	hc.Active.HTTPPath = ""
+	hc.Active.RequestHeaders = []string{"User-Agent: curl"}
+	err = tr.translateUpstreamHealthCheck(hc, &ups)
+	assert.Equal(t, &translateError{
+		field:  "healthCheck.active.requestHeaders",
+		reason: "not allowed when type is tcp",
+	}, err)
+
+	hc.Active.RequestHeaders = nil
+	hc.Active.Unhealthy.HTTPFailures = 2
+	err = tr.translateUpstreamHealthCheck(hc, &ups)
+	assert.Equal(t, &translateError{
+		field:  "healthCheck.active.unhealthy.httpFailures",
+		reason: "not allowed when type is tcp",
+	}, err)
+
+	hc.Active.Unhealthy.HTTPFailures = 0
+	hc.Passive.Unhealthy.HTTPCodes = []int{502}
+	err = tr.translateUpstreamHealthCheck(hc, &ups)
+	assert.Equal(t, &translateError{
+		field:  "healthCheck.passive.unhealthy.httpCodes",
+		reason: "not allowed when type is tcp",
+	}, err)
+}
+
 func TestTranslateUpstreamPassiveHealthCheckUnusually(t *testing.T) {
 	tr := &translator{}
 
@@ -409,3 +500,172 @@ func TestUpstreamRetriesAndTimeout(t *testing.T) {
 		Read:    15,
 	}, ups.Timeout)
 }
+
+func TestUpstreamRetryTimeout(t *testing.T) {
+	tr := &translator{}
+	var ups apisixv1.Upstream
+	err := tr.translateUpstreamRetryTimeout(nil, &ups)
+	assert.Nil(t, err)
+	assert.Nil(t, ups.RetryTimeout)
+
+	err = tr.translateUpstreamRetryTimeout(&metav1.Duration{Duration: -time.Second}, &ups)
+	assert.Equal(t, &translateError{
+		field:  "retryTimeout",
+		reason: "invalid value",
+	}, err)
+	err = tr.translateUpstreamRetryTimeout(&metav1.Duration{Duration: 500 * time.Millisecond}, &ups)
+	assert.Equal(t, &translateError{
+		field:  "retryTimeout",
+		reason: "invalid value",
+	}, err)
+
+	err = tr.translateUpstreamRetryTimeout(&metav1.Duration{Duration: 0}, &ups)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, *ups.RetryTimeout)
+
+	err = tr.translateUpstreamRetryTimeout(&metav1.Duration{Duration: 15 * time.Second}, &ups)
+	assert.Nil(t, err)
+	assert.Equal(t, 15, *ups.RetryTimeout)
+}
+
+func TestUpstreamKeepalivePool(t *testing.T) {
+	tr := &translator{}
+	ups, err := tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, ups.KeepalivePool)
+	data, err := json.Marshal(ups)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), "keepalive_pool")
+
+	ups, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		KeepalivePool: &configv2beta3.UpstreamKeepalivePool{
+			Size:        64,
+			IdleTimeout: metav1.Duration{Duration: time.Minute},
+			Requests:    1000,
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.UpstreamKeepalivePool{
+		Size:        64,
+		IdleTimeout: 60,
+		Requests:    1000,
+	}, ups.KeepalivePool)
+
+	// The items not set are left to the APISIX defaults.
+	ups, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		KeepalivePool: &configv2beta3.UpstreamKeepalivePool{Size: 16},
+	})
+	assert.Nil(t, err)
+	data, err = json.Marshal(ups.KeepalivePool)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"size":16}`, string(data))
+
+	_, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		KeepalivePool: &configv2beta3.UpstreamKeepalivePool{Size: -1},
+	})
+	assert.Equal(t, &translateError{field: "keepalivePool.size", reason: "invalid value"}, err)
+	_, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		KeepalivePool: &configv2beta3.UpstreamKeepalivePool{Requests: -1},
+	})
+	assert.Equal(t, &translateError{field: "keepalivePool.requests", reason: "invalid value"}, err)
+	_, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		KeepalivePool: &configv2beta3.UpstreamKeepalivePool{IdleTimeout: metav1.Duration{Duration: 500 * time.Millisecond}},
+	})
+	assert.Equal(t, &translateError{field: "keepalivePool.idleTimeout", reason: "invalid value"}, err)
+}
+
+func TestUpstreamZeroRetriesIsEmitted(t *testing.T) {
+	tr := &translator{}
+	retries := 0
+	ups, err := tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		Retries: &retries,
+	})
+	assert.Nil(t, err)
+	data, err := json.Marshal(ups)
+	assert.Nil(t, err)
+	assert.Contains(t, string(data), `"retries":0`)
+}
+
+func TestUpstreamClientTLSScheme(t *testing.T) {
+	tr := &translator{}
+	ups := &apisixv1.Upstream{Scheme: apisixv1.SchemeHTTP}
+	err := tr.translateClientTLS(&configv2beta3.ApisixSecret{Name: "client", Namespace: "default"}, ups)
+	assert.Equal(t, &translateError{
+		field:  "tlsSecret",
+		reason: "only allowed with https or grpcs scheme",
+	}, err)
+	assert.Nil(t, ups.TLS)
+
+	ups.Scheme = apisixv1.SchemeGRPC
+	err = tr.translateClientTLS(&configv2beta3.ApisixSecret{Name: "client", Namespace: "default"}, ups)
+	assert.Equal(t, &translateError{
+		field:  "tlsSecret",
+		reason: "only allowed with https or grpcs scheme",
+	}, err)
+}
+
+func TestUpstreamPassHost(t *testing.T) {
+	tr := &translator{}
+	var ups apisixv1.Upstream
+	err := tr.translateUpstreamPassHost("", "", &ups)
+	assert.Nil(t, err)
+	assert.Equal(t, "", ups.PassHost)
+
+	err = tr.translateUpstreamPassHost(apisixv1.PassHostRewrite, "httpbin.org", &ups)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.PassHostRewrite, ups.PassHost)
+	assert.Equal(t, "httpbin.org", ups.UpstreamHost)
+
+	err = tr.translateUpstreamPassHost(apisixv1.PassHostRewrite, "", &ups)
+	assert.Equal(t, &translateError{
+		field:  "upstreamHost",
+		reason: "required when passHost is rewrite",
+	}, err)
+
+	err = tr.translateUpstreamPassHost(apisixv1.PassHostNode, "httpbin.org", &ups)
+	assert.Equal(t, &translateError{
+		field:  "upstreamHost",
+		reason: "only allowed when passHost is rewrite",
+	}, err)
+	err = tr.translateUpstreamPassHost("", "httpbin.org", &ups)
+	assert.Equal(t, &translateError{
+		field:  "upstreamHost",
+		reason: "only allowed when passHost is rewrite",
+	}, err)
+
+	err = tr.translateUpstreamPassHost("host", "", &ups)
+	assert.Equal(t, &translateError{
+		field:  "passHost",
+		reason: "invalid value",
+	}, err)
+}
+
+func TestTranslateUpstreamTLS(t *testing.T) {
+	tr := &translator{}
+
+	ups, err := tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		Scheme: apisixv1.SchemeHTTPS,
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, ups.TLS)
+
+	ups, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		Scheme: apisixv1.SchemeHTTPS,
+		TLS:    &configv2beta3.ApisixUpstreamTLS{},
+	})
+	assert.Nil(t, err)
+	assert.True(t, *ups.TLS.Verify, "verify is on by default")
+
+	verify := false
+	ups, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		Scheme: apisixv1.SchemeGRPCS,
+		TLS:    &configv2beta3.ApisixUpstreamTLS{Verify: &verify},
+	})
+	assert.Nil(t, err)
+	assert.False(t, *ups.TLS.Verify)
+
+	_, err = tr.TranslateUpstreamConfig(&configv2beta3.ApisixUpstreamConfig{
+		TLS: &configv2beta3.ApisixUpstreamTLS{Verify: &verify},
+	})
+	assert.Equal(t, &translateError{field: "tls", reason: "only allowed with https or grpcs scheme"}, err)
+}
@@ -57,6 +57,20 @@ func (tc *TranslateContext) CheckUpstreamExist(name string) (ok bool) {
 	return
 }
 
+// getUpstream returns the upstream with the name, nil will be given if it's
+// not found.
+func (tc *TranslateContext) getUpstream(name string) *apisix.Upstream {
+	if !tc.CheckUpstreamExist(name) {
+		return nil
+	}
+	for _, u := range tc.Upstreams {
+		if u.Name == name {
+			return u
+		}
+	}
+	return nil
+}
+
 func (tc *TranslateContext) AddPluginConfig(pc *apisix.PluginConfig) {
 	tc.PluginConfigs = append(tc.PluginConfigs, pc)
 }
@@ -15,27 +15,140 @@
 package translation
 
 import (
+	"fmt"
+	"net/url"
+	"strings"
+
 	"github.com/apache/apisix-ingress-controller/pkg/id"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
-type prometheusPluginConfig struct{}
+type prometheusPluginConfig struct {
+	PreferName bool `json:"prefer_name,omitempty"`
+}
 
 type skywalkingPluginConfig struct {
 	SampleRatio float64 `json:"sample_ratio,omitempty"`
 }
 
+const (
+	_tracingPluginZipkin        = "zipkin"
+	_tracingPluginOpentelemetry = "opentelemetry"
+	// _tracingClusterPlaceholder is replaced with the cluster name in the
+	// tracing service name.
+	_tracingClusterPlaceholder = "${cluster}"
+)
+
+type zipkinPluginConfig struct {
+	Endpoint    string             `json:"endpoint"`
+	SampleRatio float64            `json:"sample_ratio"`
+	ServiceName string             `json:"service_name,omitempty"`
+	Meta        *tracingPluginMeta `json:"_meta,omitempty"`
+}
+
+type opentelemetryPluginConfig struct {
+	Sampler opentelemetrySampler `json:"sampler"`
+	Meta    *tracingPluginMeta   `json:"_meta,omitempty"`
+}
+
+type opentelemetrySampler struct {
+	Name    string                       `json:"name"`
+	Options *opentelemetrySamplerOptions `json:"options,omitempty"`
+}
+
+type opentelemetrySamplerOptions struct {
+	Fraction float64 `json:"fraction"`
+}
+
+// tracingPluginMeta is the _meta field of the tracing plugins, the filter
+// skips the plugin on the requests which don't match it.
+type tracingPluginMeta struct {
+	Filter []interface{} `json:"filter,omitempty"`
+}
+
+// translateTracingPlugin translates the tracing config to the name and
+// config of the tracing plugin.
+func translateTracingPlugin(cluster string, cfg *configv2.ApisixClusterTracingConfig) (string, interface{}, error) {
+	ratio := cfg.SampleRatio
+	if ratio == 0 {
+		ratio = 1
+	}
+	if ratio < 0 || ratio > 1 {
+		return "", nil, &translateError{field: "tracing.sampleRatio", reason: "should be in (0, 1]"}
+	}
+	switch cfg.Plugin {
+	case _tracingPluginZipkin:
+		u, err := url.Parse(cfg.Endpoint)
+		if err != nil {
+			return "", nil, &translateError{field: "tracing.endpoint", reason: err.Error()}
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", nil, &translateError{field: "tracing.endpoint", reason: "should be an absolute http or https URL"}
+		}
+		return _tracingPluginZipkin, &zipkinPluginConfig{
+			Endpoint:    cfg.Endpoint,
+			SampleRatio: ratio,
+			ServiceName: strings.ReplaceAll(cfg.ServiceName, _tracingClusterPlaceholder, cluster),
+		}, nil
+	case _tracingPluginOpentelemetry:
+		if cfg.Endpoint != "" || cfg.ServiceName != "" {
+			return "", nil, &translateError{
+				field:  "tracing",
+				reason: "endpoint and serviceName of the opentelemetry plugin should be set in the plugin_attr of APISIX",
+			}
+		}
+		sampler := opentelemetrySampler{Name: "always_on"}
+		if ratio < 1 {
+			sampler = opentelemetrySampler{
+				Name:    "trace_id_ratio",
+				Options: &opentelemetrySamplerOptions{Fraction: ratio},
+			}
+		}
+		return _tracingPluginOpentelemetry, &opentelemetryPluginConfig{Sampler: sampler}, nil
+	default:
+		return "", nil, &translateError{
+			field:  "tracing.plugin",
+			reason: fmt.Sprintf("unknown tracing plugin %s", cfg.Plugin),
+		}
+	}
+}
+
+// ExcludeRoutesFromTracing makes the tracing plugin in the global rule
+// skip the given routes, through the _meta.filter of the plugin.
+func ExcludeRoutesFromTracing(gr *apisixv1.GlobalRule, routeIDs []string) {
+	if len(routeIDs) == 0 {
+		return
+	}
+	ids := make([]interface{}, 0, len(routeIDs))
+	for _, id := range routeIDs {
+		ids = append(ids, id)
+	}
+	meta := &tracingPluginMeta{
+		Filter: []interface{}{
+			[]interface{}{"route_id", "!", "in", ids},
+		},
+	}
+	if cfg, ok := gr.Plugins[_tracingPluginZipkin].(*zipkinPluginConfig); ok {
+		cfg.Meta = meta
+	}
+	if cfg, ok := gr.Plugins[_tracingPluginOpentelemetry].(*opentelemetryPluginConfig); ok {
+		cfg.Meta = meta
+	}
+}
+
 func (t *translator) TranslateClusterConfigV2beta3(acc *configv2beta3.ApisixClusterConfig) (*apisixv1.GlobalRule, error) {
 	globalRule := &apisixv1.GlobalRule{
-		ID:      id.GenID(acc.Name),
+		ID:      id.GenID(id.GlobalRule, acc.Name),
 		Plugins: make(apisixv1.Plugins),
 	}
 
 	if acc.Spec.Monitoring != nil {
 		if acc.Spec.Monitoring.Prometheus.Enable {
-			globalRule.Plugins["prometheus"] = &prometheusPluginConfig{}
+			globalRule.Plugins["prometheus"] = &prometheusPluginConfig{
+				PreferName: acc.Spec.Monitoring.Prometheus.PreferName,
+			}
 		}
 		if acc.Spec.Monitoring.Skywalking.Enable {
 			globalRule.Plugins["skywalking"] = &skywalkingPluginConfig{
@@ -49,19 +162,28 @@ func (t *translator) TranslateClusterConfigV2beta3(acc *configv2beta3.ApisixClus
 
 func (t *translator) TranslateClusterConfigV2(acc *configv2.ApisixClusterConfig) (*apisixv1.GlobalRule, error) {
 	globalRule := &apisixv1.GlobalRule{
-		ID:      id.GenID(acc.Name),
+		ID:      id.GenID(id.GlobalRule, acc.Name),
 		Plugins: make(apisixv1.Plugins),
 	}
 
 	if acc.Spec.Monitoring != nil {
 		if acc.Spec.Monitoring.Prometheus.Enable {
-			globalRule.Plugins["prometheus"] = &prometheusPluginConfig{}
+			globalRule.Plugins["prometheus"] = &prometheusPluginConfig{
+				PreferName: acc.Spec.Monitoring.Prometheus.PreferName,
+			}
 		}
 		if acc.Spec.Monitoring.Skywalking.Enable {
 			globalRule.Plugins["skywalking"] = &skywalkingPluginConfig{
 				SampleRatio: acc.Spec.Monitoring.Skywalking.SampleRatio,
 			}
 		}
+		if acc.Spec.Monitoring.Tracing != nil {
+			name, cfg, err := translateTracingPlugin(acc.Name, acc.Spec.Monitoring.Tracing)
+			if err != nil {
+				return nil, err
+			}
+			globalRule.Plugins[name] = cfg
+		}
 	}
 
 	return globalRule, nil
@@ -21,6 +21,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 )
 
@@ -46,8 +47,109 @@ func TestTranslateClusterConfig(t *testing.T) {
 	}
 	gr, err := tr.TranslateClusterConfigV2beta3(acc)
 	assert.Nil(t, err, "translating ApisixClusterConfig")
-	assert.Equal(t, gr.ID, id.GenID("qa-apisix"), "checking global_rule id")
+	assert.Equal(t, gr.ID, id.GenID(id.GlobalRule, "qa-apisix"), "checking global_rule id")
 	assert.Len(t, gr.Plugins, 2)
 	assert.Equal(t, gr.Plugins["prometheus"], &prometheusPluginConfig{})
 	assert.Equal(t, gr.Plugins["skywalking"], &skywalkingPluginConfig{SampleRatio: 0.5})
 }
+
+func TestTranslateClusterConfigV2Prometheus(t *testing.T) {
+	tr := &translator{}
+
+	acc := &configv2.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "qa-apisix",
+		},
+		Spec: configv2.ApisixClusterConfigSpec{
+			Monitoring: &configv2.ApisixClusterMonitoringConfig{
+				Prometheus: configv2.ApisixClusterPrometheusConfig{
+					Enable:     true,
+					PreferName: true,
+				},
+			},
+		},
+	}
+	gr, err := tr.TranslateClusterConfigV2(acc)
+	assert.Nil(t, err)
+	assert.Len(t, gr.Plugins, 1)
+	assert.Equal(t, &prometheusPluginConfig{PreferName: true}, gr.Plugins["prometheus"])
+
+	acc.Spec.Monitoring.Prometheus.Enable = false
+	gr, err = tr.TranslateClusterConfigV2(acc)
+	assert.Nil(t, err)
+	assert.Len(t, gr.Plugins, 0)
+}
+
+func TestTranslateClusterConfigV2Tracing(t *testing.T) {
+	tr := &translator{}
+
+	acc := &configv2.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "qa-apisix",
+		},
+		Spec: configv2.ApisixClusterConfigSpec{
+			Monitoring: &configv2.ApisixClusterMonitoringConfig{
+				Tracing: &configv2.ApisixClusterTracingConfig{
+					Plugin:      "zipkin",
+					Endpoint:    "http://zipkin:9411/api/v2/spans",
+					ServiceName: "apisix-${cluster}",
+				},
+			},
+		},
+	}
+	gr, err := tr.TranslateClusterConfigV2(acc)
+	assert.Nil(t, err)
+	assert.Equal(t, &zipkinPluginConfig{
+		Endpoint:    "http://zipkin:9411/api/v2/spans",
+		SampleRatio: 1,
+		ServiceName: "apisix-qa-apisix",
+	}, gr.Plugins["zipkin"])
+
+	ExcludeRoutesFromTracing(gr, []string{"1", "2"})
+	assert.Equal(t, &tracingPluginMeta{
+		Filter: []interface{}{
+			[]interface{}{"route_id", "!", "in", []interface{}{"1", "2"}},
+		},
+	}, gr.Plugins["zipkin"].(*zipkinPluginConfig).Meta)
+
+	acc.Spec.Monitoring.Tracing = &configv2.ApisixClusterTracingConfig{
+		Plugin:      "opentelemetry",
+		SampleRatio: 0.25,
+	}
+	gr, err = tr.TranslateClusterConfigV2(acc)
+	assert.Nil(t, err)
+	assert.Equal(t, &opentelemetryPluginConfig{
+		Sampler: opentelemetrySampler{
+			Name:    "trace_id_ratio",
+			Options: &opentelemetrySamplerOptions{Fraction: 0.25},
+		},
+	}, gr.Plugins["opentelemetry"])
+
+	acc.Spec.Monitoring.Tracing = &configv2.ApisixClusterTracingConfig{
+		Plugin:   "zipkin",
+		Endpoint: "zipkin:9411",
+	}
+	_, err = tr.TranslateClusterConfigV2(acc)
+	assert.Equal(t, "tracing.endpoint: should be an absolute http or https URL", err.Error())
+
+	acc.Spec.Monitoring.Tracing = &configv2.ApisixClusterTracingConfig{
+		Plugin:      "zipkin",
+		Endpoint:    "http://zipkin:9411/api/v2/spans",
+		SampleRatio: 1.5,
+	}
+	_, err = tr.TranslateClusterConfigV2(acc)
+	assert.Equal(t, "tracing.sampleRatio: should be in (0, 1]", err.Error())
+
+	acc.Spec.Monitoring.Tracing = &configv2.ApisixClusterTracingConfig{
+		Plugin:   "opentelemetry",
+		Endpoint: "http://otel:4318",
+	}
+	_, err = tr.TranslateClusterConfigV2(acc)
+	assert.Equal(t, "tracing: endpoint and serviceName of the opentelemetry plugin should be set in the plugin_attr of APISIX", err.Error())
+
+	acc.Spec.Monitoring.Tracing = &configv2.ApisixClusterTracingConfig{
+		Plugin: "jaeger",
+	}
+	_, err = tr.TranslateClusterConfigV2(acc)
+	assert.Equal(t, "tracing.plugin: unknown tracing plugin jaeger", err.Error())
+}
@@ -17,6 +17,7 @@ package translation
 import (
 	"bytes"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"go.uber.org/zap"
@@ -37,13 +38,43 @@ import (
 
 const (
 	_regexPriority = 100
+	// _defaultBackendPriority makes the catch-all route of the Ingress
+	// default backend the last one to match.
+	_defaultBackendPriority = -100
 )
 
+// validateIngressPathRegex checks the ImplementationSpecific path, which is
+// interpreted as a regular expression matched against the request URI.
+func validateIngressPathRegex(path string) error {
+	if _, err := regexp.Compile(path); err != nil {
+		return &translateError{
+			field:  "path",
+			reason: fmt.Sprintf("invalid regular expression %s: %s", path, err),
+		}
+	}
+	return nil
+}
+
 func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bool) (*TranslateContext, error) {
 	ctx := DefaultEmptyTranslateContext()
 	plugins := t.translateAnnotations(ing.Annotations)
+	if err := t.translateAuthConsumers(ing.Namespace, ing.Annotations, plugins, !skipVerify); err != nil {
+		log.Errorw("failed to translate ingress auth consumers",
+			zap.Error(err),
+			zap.Any("ingress", ing),
+		)
+		return nil, err
+	}
 	annoExtractor := annotations.NewExtractor(ing.Annotations)
-	useRegex := annoExtractor.GetBoolAnnotation(annotations.AnnotationsPrefix + "use-regex")
+	pluginConfigName := annoExtractor.GetStringAnnotation(annotations.AnnotationsPluginConfigName)
+	var pluginConfigID string
+	if pluginConfigName != "" {
+		var err error
+		pluginConfigID, err = t.translatePluginConfigID(ing.Namespace, pluginConfigName, !skipVerify)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// add https
 	for _, tls := range ing.Spec.TLS {
 		apisixTls := kubev2.ApisixTls{
@@ -72,10 +103,26 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bo
 			)
 			return nil, err
 		}
+		ssl.ID = id.GenID(id.SSL, ComposeIngressSSLName(ing.Namespace, ing.Name))
 		ctx.AddSSL(ssl)
 	}
 	for _, rule := range ing.Spec.Rules {
-		for _, pathRule := range rule.HTTP.Paths {
+		var paths []networkingv1.HTTPIngressPath
+		if rule.HTTP != nil {
+			paths = rule.HTTP.Paths
+		} else if rule.Host != "" && ing.Spec.DefaultBackend != nil {
+			// A host-only rule sends all the requests of the host to the
+			// default backend, so a host-scoped catch-all route is created.
+			pathType := networkingv1.PathTypePrefix
+			paths = []networkingv1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &pathType,
+					Backend:  *ing.Spec.DefaultBackend,
+				},
+			}
+		}
+		for _, pathRule := range paths {
 			var (
 				ups          *apisixv1.Upstream
 				pluginConfig *apisixv1.PluginConfig
@@ -116,7 +163,14 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bo
 						prefix += "/*"
 					}
 					uris = append(uris, prefix)
-				} else if *pathRule.PathType == networkingv1.PathTypeImplementationSpecific && useRegex {
+				} else if *pathRule.PathType == networkingv1.PathTypeImplementationSpecific {
+					if err := validateIngressPathRegex(pathRule.Path); err != nil && !skipVerify {
+						log.Errorw("failed to translate ingress path",
+							zap.Error(err),
+							zap.Any("ingress", ing),
+						)
+						return nil, err
+					}
 					nginxVars = append(nginxVars, kubev2.ApisixRouteHTTPMatchExpr{
 						Subject: kubev2.ApisixRouteHTTPMatchExprSubject{
 							Scope: apisixconst.ScopePath,
@@ -129,7 +183,7 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bo
 			}
 			route := apisixv1.NewDefaultRoute()
 			route.Name = composeIngressRouteName(ing.Namespace, ing.Name, rule.Host, pathRule.Path)
-			route.ID = id.GenID(route.Name)
+			route.ID = id.GenID(id.Route, route.Name)
 			route.Host = rule.Host
 			route.Uris = uris
 			if len(nginxVars) > 0 {
@@ -143,13 +197,18 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bo
 			if len(plugins) > 0 {
 				route.Plugins = *(plugins.DeepCopy())
 
-				pluginConfig = apisixv1.NewDefaultPluginConfig()
-				pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.Service.Name)
-				pluginConfig.ID = id.GenID(route.Name)
-				pluginConfig.Plugins = *(plugins.DeepCopy())
-				ctx.AddPluginConfig(pluginConfig)
+				if pluginConfigName == "" {
+					pluginConfig = apisixv1.NewDefaultPluginConfig()
+					pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.Service.Name)
+					pluginConfig.ID = id.GenID(id.PluginConfig, route.Name)
+					pluginConfig.Plugins = *(plugins.DeepCopy())
+					ctx.AddPluginConfig(pluginConfig)
 
-				route.PluginConfigId = pluginConfig.ID
+					route.PluginConfigId = pluginConfig.ID
+				}
+			}
+			if pluginConfigID != "" {
+				route.PluginConfigId = pluginConfigID
 			}
 			if ups != nil {
 				route.UpstreamId = ups.ID
@@ -157,14 +216,48 @@ func (t *translator) translateIngressV1(ing *networkingv1.Ingress, skipVerify bo
 			ctx.AddRoute(route)
 		}
 	}
+	if backend := ing.Spec.DefaultBackend; backend != nil && backend.Service != nil {
+		var (
+			ups *apisixv1.Upstream
+			err error
+		)
+		if skipVerify {
+			ups = t.translateDefaultUpstreamFromIngressV1(ing.Namespace, backend.Service)
+		} else {
+			ups, err = t.translateUpstreamFromIngressV1(ing.Namespace, backend.Service)
+			if err != nil {
+				log.Errorw("failed to translate ingress default backend to upstream",
+					zap.Error(err),
+					zap.Any("ingress", ing),
+				)
+				return nil, err
+			}
+		}
+		addIngressDefaultBackendRoute(ctx, ing.Namespace, ing.Name, backend.Service.Name, ups, plugins, pluginConfigName, pluginConfigID)
+	}
 	return ctx, nil
 }
 
 func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, skipVerify bool) (*TranslateContext, error) {
 	ctx := DefaultEmptyTranslateContext()
 	plugins := t.translateAnnotations(ing.Annotations)
+	if err := t.translateAuthConsumers(ing.Namespace, ing.Annotations, plugins, !skipVerify); err != nil {
+		log.Errorw("failed to translate ingress auth consumers",
+			zap.Error(err),
+			zap.Any("ingress", ing),
+		)
+		return nil, err
+	}
 	annoExtractor := annotations.NewExtractor(ing.Annotations)
-	useRegex := annoExtractor.GetBoolAnnotation(annotations.AnnotationsPrefix + "use-regex")
+	pluginConfigName := annoExtractor.GetStringAnnotation(annotations.AnnotationsPluginConfigName)
+	var pluginConfigID string
+	if pluginConfigName != "" {
+		var err error
+		pluginConfigID, err = t.translatePluginConfigID(ing.Namespace, pluginConfigName, !skipVerify)
+		if err != nil {
+			return nil, err
+		}
+	}
 	// add https
 	for _, tls := range ing.Spec.TLS {
 		apisixTls := kubev2beta3.ApisixTls{
@@ -193,10 +286,26 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, ski
 			)
 			return nil, err
 		}
+		ssl.ID = id.GenID(id.SSL, ComposeIngressSSLName(ing.Namespace, ing.Name))
 		ctx.AddSSL(ssl)
 	}
 	for _, rule := range ing.Spec.Rules {
-		for _, pathRule := range rule.HTTP.Paths {
+		var paths []networkingv1beta1.HTTPIngressPath
+		if rule.HTTP != nil {
+			paths = rule.HTTP.Paths
+		} else if rule.Host != "" && ing.Spec.Backend != nil {
+			// A host-only rule sends all the requests of the host to the
+			// default backend, so a host-scoped catch-all route is created.
+			pathType := networkingv1beta1.PathTypePrefix
+			paths = []networkingv1beta1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &pathType,
+					Backend:  *ing.Spec.Backend,
+				},
+			}
+		}
+		for _, pathRule := range paths {
 			var (
 				ups          *apisixv1.Upstream
 				pluginConfig *apisixv1.PluginConfig
@@ -237,7 +346,14 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, ski
 						prefix += "/*"
 					}
 					uris = append(uris, prefix)
-				} else if *pathRule.PathType == networkingv1beta1.PathTypeImplementationSpecific && useRegex {
+				} else if *pathRule.PathType == networkingv1beta1.PathTypeImplementationSpecific {
+					if err := validateIngressPathRegex(pathRule.Path); err != nil && !skipVerify {
+						log.Errorw("failed to translate ingress path",
+							zap.Error(err),
+							zap.Any("ingress", ing),
+						)
+						return nil, err
+					}
 					nginxVars = append(nginxVars, kubev2.ApisixRouteHTTPMatchExpr{
 						Subject: kubev2.ApisixRouteHTTPMatchExprSubject{
 							Scope: apisixconst.ScopePath,
@@ -250,7 +366,7 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, ski
 			}
 			route := apisixv1.NewDefaultRoute()
 			route.Name = composeIngressRouteName(ing.Namespace, ing.Name, rule.Host, pathRule.Path)
-			route.ID = id.GenID(route.Name)
+			route.ID = id.GenID(id.Route, route.Name)
 			route.Host = rule.Host
 			route.Uris = uris
 			if len(nginxVars) > 0 {
@@ -264,13 +380,18 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, ski
 			if len(plugins) > 0 {
 				route.Plugins = *(plugins.DeepCopy())
 
-				pluginConfig = apisixv1.NewDefaultPluginConfig()
-				pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.ServiceName)
-				pluginConfig.ID = id.GenID(route.Name)
-				pluginConfig.Plugins = *(plugins.DeepCopy())
-				ctx.AddPluginConfig(pluginConfig)
+				if pluginConfigName == "" {
+					pluginConfig = apisixv1.NewDefaultPluginConfig()
+					pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.ServiceName)
+					pluginConfig.ID = id.GenID(id.PluginConfig, route.Name)
+					pluginConfig.Plugins = *(plugins.DeepCopy())
+					ctx.AddPluginConfig(pluginConfig)
 
-				route.PluginConfigId = pluginConfig.ID
+					route.PluginConfigId = pluginConfig.ID
+				}
+			}
+			if pluginConfigID != "" {
+				route.PluginConfigId = pluginConfigID
 			}
 			if ups != nil {
 				route.UpstreamId = ups.ID
@@ -278,6 +399,25 @@ func (t *translator) translateIngressV1beta1(ing *networkingv1beta1.Ingress, ski
 			ctx.AddRoute(route)
 		}
 	}
+	if backend := ing.Spec.Backend; backend != nil && backend.ServiceName != "" {
+		var (
+			ups *apisixv1.Upstream
+			err error
+		)
+		if skipVerify {
+			ups = t.translateDefaultUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+		} else {
+			ups, err = t.translateUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+			if err != nil {
+				log.Errorw("failed to translate ingress default backend to upstream",
+					zap.Error(err),
+					zap.Any("ingress", ing),
+				)
+				return nil, err
+			}
+		}
+		addIngressDefaultBackendRoute(ctx, ing.Namespace, ing.Name, backend.ServiceName, ups, plugins, pluginConfigName, pluginConfigID)
+	}
 	return ctx, nil
 }
 
@@ -301,7 +441,9 @@ func (t *translator) translateDefaultUpstreamFromIngressV1(namespace string, bac
 	}
 	ups := apisixv1.NewDefaultUpstream()
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, backend.Name, "", portNumber)
-	ups.ID = id.GenID(ups.Name)
+	// The config is not translated, so the ID is generated from the name
+	// like translateUpstreamNotStrictly.
+	ups.ID = id.GenID(id.Upstream, ups.Name)
 	return ups
 }
 func (t *translator) translateUpstreamFromIngressV1(namespace string, backend *networkingv1.IngressServiceBackend) (*apisixv1.Upstream, error) {
@@ -331,18 +473,48 @@ func (t *translator) translateUpstreamFromIngressV1(namespace string, backend *n
 		return nil, err
 	}
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, backend.Name, "", svcPort)
-	ups.ID = id.GenID(ups.Name)
+	ups.ID = apisixv1.ComposeUpstreamID(ups)
 	return ups, nil
 }
 
 func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.Ingress, skipVerify bool) (*TranslateContext, error) {
 	ctx := DefaultEmptyTranslateContext()
 	plugins := t.translateAnnotations(ing.Annotations)
+	if err := t.translateAuthConsumers(ing.Namespace, ing.Annotations, plugins, !skipVerify); err != nil {
+		log.Errorw("failed to translate ingress auth consumers",
+			zap.Error(err),
+			zap.Any("ingress", ing),
+		)
+		return nil, err
+	}
 	annoExtractor := annotations.NewExtractor(ing.Annotations)
-	useRegex := annoExtractor.GetBoolAnnotation(annotations.AnnotationsPrefix + "use-regex")
+	pluginConfigName := annoExtractor.GetStringAnnotation(annotations.AnnotationsPluginConfigName)
+	var pluginConfigID string
+	if pluginConfigName != "" {
+		var err error
+		pluginConfigID, err = t.translatePluginConfigID(ing.Namespace, pluginConfigName, !skipVerify)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	for _, rule := range ing.Spec.Rules {
-		for _, pathRule := range rule.HTTP.Paths {
+		var paths []extensionsv1beta1.HTTPIngressPath
+		if rule.HTTP != nil {
+			paths = rule.HTTP.Paths
+		} else if rule.Host != "" && ing.Spec.Backend != nil {
+			// A host-only rule sends all the requests of the host to the
+			// default backend, so a host-scoped catch-all route is created.
+			pathType := extensionsv1beta1.PathTypePrefix
+			paths = []extensionsv1beta1.HTTPIngressPath{
+				{
+					Path:     "/",
+					PathType: &pathType,
+					Backend:  *ing.Spec.Backend,
+				},
+			}
+		}
+		for _, pathRule := range paths {
 			var (
 				ups          *apisixv1.Upstream
 				pluginConfig *apisixv1.PluginConfig
@@ -384,7 +556,14 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 						prefix += "/*"
 					}
 					uris = append(uris, prefix)
-				} else if *pathRule.PathType == extensionsv1beta1.PathTypeImplementationSpecific && useRegex {
+				} else if *pathRule.PathType == extensionsv1beta1.PathTypeImplementationSpecific {
+					if err := validateIngressPathRegex(pathRule.Path); err != nil && !skipVerify {
+						log.Errorw("failed to translate ingress path",
+							zap.Error(err),
+							zap.Any("ingress", ing),
+						)
+						return nil, err
+					}
 					nginxVars = append(nginxVars, kubev2.ApisixRouteHTTPMatchExpr{
 						Subject: kubev2.ApisixRouteHTTPMatchExprSubject{
 							Scope: apisixconst.ScopePath,
@@ -397,7 +576,7 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 			}
 			route := apisixv1.NewDefaultRoute()
 			route.Name = composeIngressRouteName(ing.Namespace, ing.Name, rule.Host, pathRule.Path)
-			route.ID = id.GenID(route.Name)
+			route.ID = id.GenID(id.Route, route.Name)
 			route.Host = rule.Host
 			route.Uris = uris
 			if len(nginxVars) > 0 {
@@ -411,13 +590,18 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 			if len(plugins) > 0 {
 				route.Plugins = *(plugins.DeepCopy())
 
-				pluginConfig = apisixv1.NewDefaultPluginConfig()
-				pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.ServiceName)
-				pluginConfig.ID = id.GenID(route.Name)
-				pluginConfig.Plugins = *(plugins.DeepCopy())
-				ctx.AddPluginConfig(pluginConfig)
+				if pluginConfigName == "" {
+					pluginConfig = apisixv1.NewDefaultPluginConfig()
+					pluginConfig.Name = composeIngressPluginName(ing.Namespace, pathRule.Backend.ServiceName)
+					pluginConfig.ID = id.GenID(id.PluginConfig, route.Name)
+					pluginConfig.Plugins = *(plugins.DeepCopy())
+					ctx.AddPluginConfig(pluginConfig)
 
-				route.PluginConfigId = pluginConfig.ID
+					route.PluginConfigId = pluginConfig.ID
+				}
+			}
+			if pluginConfigID != "" {
+				route.PluginConfigId = pluginConfigID
 			}
 			if ups != nil {
 				route.UpstreamId = ups.ID
@@ -425,6 +609,25 @@ func (t *translator) translateIngressExtensionsV1beta1(ing *extensionsv1beta1.In
 			ctx.AddRoute(route)
 		}
 	}
+	if backend := ing.Spec.Backend; backend != nil && backend.ServiceName != "" {
+		var (
+			ups *apisixv1.Upstream
+			err error
+		)
+		if skipVerify {
+			ups = t.translateDefaultUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+		} else {
+			ups, err = t.translateUpstreamFromIngressV1beta1(ing.Namespace, backend.ServiceName, backend.ServicePort)
+			if err != nil {
+				log.Errorw("failed to translate ingress default backend to upstream",
+					zap.Error(err),
+					zap.Any("ingress", ing),
+				)
+				return nil, err
+			}
+		}
+		addIngressDefaultBackendRoute(ctx, ing.Namespace, ing.Name, backend.ServiceName, ups, plugins, pluginConfigName, pluginConfigID)
+	}
 	return ctx, nil
 }
 
@@ -447,7 +650,9 @@ func (t *translator) translateDefaultUpstreamFromIngressV1beta1(namespace string
 	}
 	ups := apisixv1.NewDefaultUpstream()
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, svcName, "", portNumber)
-	ups.ID = id.GenID(ups.Name)
+	// The config is not translated, so the ID is generated from the name
+	// like translateUpstreamNotStrictly.
+	ups.ID = id.GenID(id.Upstream, ups.Name)
 	return ups
 }
 
@@ -478,7 +683,7 @@ func (t *translator) translateUpstreamFromIngressV1beta1(namespace string, svcNa
 		return nil, err
 	}
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, svcName, "", portNumber)
-	ups.ID = id.GenID(ups.Name)
+	ups.ID = apisixv1.ComposeUpstreamID(ups)
 	return ups, nil
 }
 
@@ -489,8 +694,10 @@ func (t *translator) translateUpstreamFromIngressV1beta1(namespace string, svcNa
 // ref: https://github.com/apache/apisix-ingress-controller/issues/781
 // We will construct the following structure for easy reading and debugging.
 // ing_namespace_ingressName_id
+// The short CRC32 form is kept for the id part, so that the route names are
+// unchanged.
 func composeIngressRouteName(namespace, name, host, path string) string {
-	pID := id.GenID(host + path)
+	pID := id.GenLegacyID(host + path)
 	p := make([]byte, 0, len(namespace)+len(name)+len("ing")+len(pID)+3)
 	buf := bytes.NewBuffer(p)
 
@@ -505,6 +712,49 @@ func composeIngressRouteName(namespace, name, host, path string) string {
 	return buf.String()
 }
 
+func composeIngressDefaultBackendRouteName(namespace, name string) string {
+	return "ing_" + namespace + "_" + name + "_default-backend"
+}
+
+// addIngressDefaultBackendRoute adds a catch-all route of the Ingress default
+// backend. The route has no host and the lowest priority, so that it only
+// matches the requests which match no other route, including the ones of
+// the other Ingresses and ApisixRoutes.
+func addIngressDefaultBackendRoute(ctx *TranslateContext, namespace, name, svcName string, ups *apisixv1.Upstream,
+	plugins apisixv1.Plugins, pluginConfigName, pluginConfigID string) {
+	ctx.AddUpstream(ups)
+	route := apisixv1.NewDefaultRoute()
+	route.Name = composeIngressDefaultBackendRouteName(namespace, name)
+	route.ID = id.GenID(id.Route, route.Name)
+	route.Uris = []string{"/*"}
+	route.Priority = _defaultBackendPriority
+	route.UpstreamId = ups.ID
+	if len(plugins) > 0 {
+		route.Plugins = *(plugins.DeepCopy())
+
+		if pluginConfigName == "" {
+			pluginConfig := apisixv1.NewDefaultPluginConfig()
+			pluginConfig.Name = composeIngressPluginName(namespace, svcName)
+			pluginConfig.ID = id.GenID(id.PluginConfig, route.Name)
+			pluginConfig.Plugins = *(plugins.DeepCopy())
+			ctx.AddPluginConfig(pluginConfig)
+
+			route.PluginConfigId = pluginConfig.ID
+		}
+	}
+	if pluginConfigID != "" {
+		route.PluginConfigId = pluginConfigID
+	}
+	ctx.AddRoute(route)
+}
+
+// ComposeIngressSSLName composes the name used to generate the ID of the SSL
+// object translated from the Ingress TLS, the "ing" prefix avoids colliding
+// with the SSL object of an ApisixTls named "<ingressName>-tls".
+func ComposeIngressSSLName(namespace, name string) string {
+	return "ing_" + namespace + "_" + name + "-tls"
+}
+
 func composeIngressPluginName(svc, name string) string {
 	p := make([]byte, 0, len(svc)+len(name)+len("ingress")+2)
 	buf := bytes.NewBuffer(p)
@@ -17,6 +17,7 @@ package translation
 import (
 	"context"
 	"path"
+	"regexp"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +31,7 @@ import (
 	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/tools/cache"
 
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
@@ -131,6 +133,48 @@ func TestTranslateIngressV1NoBackend(t *testing.T) {
 	assert.Equal(t, []string{"/foo", "/foo/*"}, ctx.Routes[0].Uris)
 }
 
+func TestTranslateIngressV1WithPluginConfigName(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			Annotations: map[string]string{
+				annotations.AnnotationsPluginConfigName: "echo-and-cors-apc",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "apisix.apache.org",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     "/foo",
+									PathType: &prefix,
+								},
+								{
+									Path:     "/bar",
+									PathType: &prefix,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	tr := &translator{}
+	ctx, err := tr.translateIngressV1(ing, false)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 2)
+	assert.Len(t, ctx.PluginConfigs, 0)
+	expectedId := id.GenID(id.PluginConfig, v1.ComposePluginConfigName("default", "echo-and-cors-apc"))
+	assert.Equal(t, expectedId, ctx.Routes[0].PluginConfigId)
+	assert.Equal(t, expectedId, ctx.Routes[1].PluginConfigId)
+}
+
 func TestTranslateIngressV1BackendWithInvalidService(t *testing.T) {
 	prefix := networkingv1.PathTypePrefix
 	// no backend.
@@ -303,6 +347,114 @@ func TestTranslateIngressV1WithRegex(t *testing.T) {
 	assert.Equal(t, expectedVars, ctx.Routes[0].Vars)
 }
 
+func TestTranslateIngressV1ImplementationSpecificPath(t *testing.T) {
+	prefix := networkingv1.PathTypeImplementationSpecific
+	regexPath := "/api/v[0-9]+/users$"
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "apisix.apache.org",
+					IngressRuleValue: networkingv1.IngressRuleValue{
+						HTTP: &networkingv1.HTTPIngressRuleValue{
+							Paths: []networkingv1.HTTPIngressPath{
+								{
+									Path:     regexPath,
+									PathType: &prefix,
+									Backend: networkingv1.IngressBackend{
+										Service: &networkingv1.IngressServiceBackend{
+											Name: "test-service",
+											Port: networkingv1.ServiceBackendPort{
+												Name: "port1",
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+	svcLister := informersFactory.Core().V1().Services().Lister()
+	epLister, epInformer := kube.NewEndpointListerAndInformer(informersFactory, false)
+	apisixClient := fakeapisix.NewSimpleClientset()
+	apisixInformersFactory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	processCh := make(chan struct{})
+	svcInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			processCh <- struct{}{}
+		},
+	})
+	epInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			processCh <- struct{}{}
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	go epInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced)
+
+	_, err := client.CoreV1().Services("default").Create(context.Background(), _testSvc, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	_, err = client.CoreV1().Endpoints("default").Create(context.Background(), _testEp, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			ServiceLister:        svcLister,
+			EndpointLister:       epLister,
+			ApisixUpstreamLister: apisixInformersFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+		},
+	}
+
+	<-processCh
+	<-processCh
+	ctx, err := tr.translateIngressV1(ing, false)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 1)
+	routeVars, err := tr.translateRouteMatchExprs([]configv2.ApisixRouteHTTPMatchExpr{{
+		Subject: configv2.ApisixRouteHTTPMatchExprSubject{
+			Scope: apisixconst.ScopePath,
+		},
+		Op:    apisixconst.OpRegexMatch,
+		Value: &regexPath,
+	}})
+	assert.Nil(t, err)
+
+	var expectedVars v1.Vars = routeVars
+
+	assert.Equal(t, []string{"/*"}, ctx.Routes[0].Uris)
+	assert.Equal(t, expectedVars, ctx.Routes[0].Vars)
+	assert.Equal(t, _regexPriority, ctx.Routes[0].Priority)
+
+	re := regexp.MustCompile(regexPath)
+	assert.True(t, re.MatchString("/api/v1/users"))
+	assert.False(t, re.MatchString("/api/vx/users"))
+	assert.False(t, re.MatchString("/api/v1/users/1"))
+
+	ing.Spec.Rules[0].HTTP.Paths[0].Path = "/api/(v1"
+	_, err = tr.translateIngressV1(ing, false)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid regular expression /api/(v1")
+
+	// The invalid path is ignored when the Ingress is deleted.
+	ctx, err = tr.translateIngressV1(ing, true)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 1)
+}
+
 func TestTranslateIngressV1(t *testing.T) {
 	prefix := networkingv1.PathTypePrefix
 	// no backend.
@@ -428,6 +580,95 @@ func TestTranslateIngressV1(t *testing.T) {
 	assert.Len(t, ctx.PluginConfigs[1].Plugins, 2)
 }
 
+
+func TestTranslateIngressV1HostOnlyRule(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+		},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: "test-service",
+					Port: networkingv1.ServiceBackendPort{
+						Name: "port1",
+					},
+				},
+			},
+			Rules: []networkingv1.IngressRule{
+				{
+					Host: "apisix.apache.org",
+				},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset()
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+	svcLister := informersFactory.Core().V1().Services().Lister()
+	epLister, epInformer := kube.NewEndpointListerAndInformer(informersFactory, false)
+	apisixClient := fakeapisix.NewSimpleClientset()
+	apisixInformersFactory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	processCh := make(chan struct{})
+	svcInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			processCh <- struct{}{}
+		},
+	})
+	epInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			processCh <- struct{}{}
+		},
+	})
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	go epInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced)
+
+	_, err := client.CoreV1().Services("default").Create(context.Background(), _testSvc, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	_, err = client.CoreV1().Endpoints("default").Create(context.Background(), _testEp, metav1.CreateOptions{})
+	assert.Nil(t, err)
+
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			ServiceLister:        svcLister,
+			EndpointLister:       epLister,
+			ApisixUpstreamLister: apisixInformersFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+		},
+	}
+
+	<-processCh
+	<-processCh
+	ctx, err := tr.translateIngressV1(ing, false)
+	assert.Nil(t, err)
+	// The host-only rule and the default backend.
+	assert.Len(t, ctx.Routes, 2)
+	assert.Len(t, ctx.Upstreams, 1)
+
+	assert.Equal(t, "apisix.apache.org", ctx.Routes[0].Host)
+	assert.Equal(t, []string{"/", "/*"}, ctx.Routes[0].Uris)
+	assert.Equal(t, ctx.Upstreams[0].ID, ctx.Routes[0].UpstreamId)
+	assert.Len(t, ctx.Upstreams[0].Nodes, 2)
+	assert.Equal(t, 9080, ctx.Upstreams[0].Nodes[0].Port)
+
+	assert.Equal(t, "", ctx.Routes[1].Host)
+	assert.Equal(t, []string{"/*"}, ctx.Routes[1].Uris)
+	assert.Equal(t, _defaultBackendPriority, ctx.Routes[1].Priority)
+	assert.Less(t, ctx.Routes[1].Priority, ctx.Routes[0].Priority)
+	assert.Equal(t, ctx.Upstreams[0].ID, ctx.Routes[1].UpstreamId)
+	assert.Equal(t, id.GenID(id.Route, "ing_default_test_default-backend"), ctx.Routes[1].ID)
+
+	// Without a default backend, a host-only rule has nothing to route to.
+	ing.Spec.DefaultBackend = nil
+	ctx, err = tr.translateIngressV1(ing, false)
+	assert.Nil(t, err)
+	assert.Len(t, ctx.Routes, 0)
+	assert.Len(t, ctx.Upstreams, 0)
+}
 func TestTranslateIngressV1beta1NoBackend(t *testing.T) {
 	prefix := networkingv1beta1.PathTypePrefix
 	// no backend.
@@ -0,0 +1,102 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"strings"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/apache/apisix-ingress-controller/pkg/ingress/utils"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// The labels identifying the Kubernetes object which an APISIX object is
+// translated from.
+const (
+	LabelManagedBy       = apisixv1.ManagedByLabel
+	LabelSourceKind      = "source-kind"
+	LabelSourceNamespace = "source-namespace"
+	LabelSourceName      = "source-name"
+	LabelSourceUID       = "source-uid"
+)
+
+// sourceLabels returns the labels identifying the Kubernetes object of kind,
+// the values are sanitized and the empty ones are skipped.
+func sourceLabels(kind string, obj metav1.Object) map[string]string {
+	labels := map[string]string{
+		LabelManagedBy: apisixv1.ManagedBy(),
+	}
+	for k, v := range map[string]string{
+		LabelSourceKind:      kind,
+		LabelSourceNamespace: obj.GetNamespace(),
+		LabelSourceName:      obj.GetName(),
+		LabelSourceUID:       string(obj.GetUID()),
+	} {
+		if v = sanitizeLabelValue(v); v != "" {
+			labels[k] = v
+		}
+	}
+	return labels
+}
+
+// sanitizeLabelValue makes v acceptable by APISIX as a label value, which
+// can't contain whitespaces and is limited in length.
+func sanitizeLabelValue(v string) string {
+	v = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return '_'
+		}
+		return r
+	}, v)
+	return utils.TruncateString(v, _maxLabelValueLength)
+}
+
+// SetSourceLabels attaches the labels of the Kubernetes object of kind to
+// all APISIX objects in the context, the existing ones (e.g. the upstreams
+// labeled with their Services) are kept.
+func SetSourceLabels(ctx *TranslateContext, kind string, obj metav1.Object) {
+	labels := sourceLabels(kind, obj)
+	for _, r := range ctx.Routes {
+		r.Labels = mergeLabels(r.Labels, labels)
+	}
+	for _, sr := range ctx.StreamRoutes {
+		sr.Labels = mergeLabels(sr.Labels, labels)
+	}
+	for _, u := range ctx.Upstreams {
+		if _, ok := u.Labels[LabelSourceKind]; !ok {
+			u.Labels = mergeLabels(u.Labels, labels)
+		}
+	}
+	for _, ssl := range ctx.SSL {
+		ssl.Labels = mergeLabels(ssl.Labels, labels)
+	}
+	for _, pc := range ctx.PluginConfigs {
+		pc.Labels = mergeLabels(pc.Labels, labels)
+	}
+}
+
+// setUpstreamSourceLabels labels the upstream with the Service it's
+// translated from.
+func (t *translator) setUpstreamSourceLabels(ups *apisixv1.Upstream, namespace, name string) {
+	var obj metav1.Object = &metav1.ObjectMeta{Namespace: namespace, Name: name}
+	if t.ServiceLister != nil {
+		if svc, err := t.ServiceLister.Services(namespace).Get(name); err == nil {
+			obj = svc
+		}
+	}
+	ups.Labels = mergeLabels(ups.Labels, sourceLabels("Service", obj))
+}
@@ -0,0 +1,82 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+func TestSourceLabels(t *testing.T) {
+	obj := &metav1.ObjectMeta{
+		Namespace: "test",
+		Name:      strings.Repeat("x", 70),
+		UID:       "8b1e5a6e-3c1f-4f0e-9d2a-6f3c0c7d9e11",
+	}
+	assert.Equal(t, map[string]string{
+		"managed-by":       "apisix-ingress-controller",
+		"source-kind":      "ApisixRoute",
+		"source-namespace": "test",
+		"source-name":      strings.Repeat("x", 64),
+		"source-uid":       "8b1e5a6e-3c1f-4f0e-9d2a-6f3c0c7d9e11",
+	}, sourceLabels("ApisixRoute", obj))
+
+	// Empty values are skipped.
+	assert.Equal(t, map[string]string{
+		"managed-by":  "apisix-ingress-controller",
+		"source-kind": "Service",
+		"source-name": "svc",
+	}, sourceLabels("Service", &metav1.ObjectMeta{Name: "svc"}))
+
+	assert.Equal(t, "a_b_c", sanitizeLabelValue("a b\tc"))
+}
+
+func TestSetSourceLabels(t *testing.T) {
+	ctx := DefaultEmptyTranslateContext()
+	route := apisixv1.NewDefaultRoute()
+	route.ID = "1"
+	route.Labels["team"] = "infra"
+	ctx.AddRoute(route)
+	sr := &apisixv1.StreamRoute{ID: "2"}
+	ctx.AddStreamRoute(sr)
+	svcUps := apisixv1.NewDefaultUpstream()
+	svcUps.Name = "test_svc_80"
+	svcUps.Labels = mergeLabels(svcUps.Labels, sourceLabels("Service", &metav1.ObjectMeta{Namespace: "test", Name: "svc"}))
+	ctx.AddUpstream(svcUps)
+	ups := apisixv1.NewDefaultUpstream()
+	ups.Name = "test_ar_external"
+	ctx.AddUpstream(ups)
+
+	SetSourceLabels(ctx, "ApisixRoute", &metav1.ObjectMeta{Namespace: "test", Name: "ar", UID: "uid"})
+
+	expected := map[string]string{
+		"managed-by":       "apisix-ingress-controller",
+		"source-kind":      "ApisixRoute",
+		"source-namespace": "test",
+		"source-name":      "ar",
+		"source-uid":       "uid",
+	}
+	assert.Equal(t, expected, sr.Labels)
+	assert.Equal(t, expected, ups.Labels)
+	expected["team"] = "infra"
+	assert.Equal(t, expected, route.Labels)
+	assert.Equal(t, "Service", svcUps.Labels["source-kind"])
+	assert.Equal(t, "svc", svcUps.Labels["source-name"])
+}
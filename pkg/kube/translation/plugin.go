@@ -15,8 +15,20 @@
 package translation
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
@@ -38,6 +50,22 @@ var (
 	_hmacAuthMaxReqBodyDefaultValue          = int64(524288)
 )
 
+// _nginxVarRegex matches the valid names of the nginx variables, which are
+// used in the match conditions of APISIX.
+var _nginxVarRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+
+var _validHTTPMethods = map[string]struct{}{
+	"CONNECT": {},
+	"DELETE":  {},
+	"GET":     {},
+	"HEAD":    {},
+	"OPTIONS": {},
+	"PATCH":   {},
+	"POST":    {},
+	"PUT":     {},
+	"TRACE":   {},
+}
+
 func (t *translator) translateTrafficSplitPlugin(ctx *TranslateContext, ns string, defaultBackendWeight int,
 	backends []configv2.ApisixRouteHTTPBackend) (*apisixv1.TrafficSplitConfig, error) {
 	var (
@@ -80,6 +108,919 @@ func (t *translator) translateTrafficSplitPlugin(ctx *TranslateContext, ns strin
 	return tsCfg, nil
 }
 
+// translateSubsetTrafficSplitPlugin splits the traffic across the subsets of the
+// Service which have weights in the ApisixUpstream, the whole Service (the default
+// upstream of the route) gets no traffic. A nil config is returned if there are no
+// weighted subsets.
+func (t *translator) translateSubsetTrafficSplitPlugin(ctx *TranslateContext, ns, svcName, svcResolveGranularity,
+	svcClusterIP string, svcPort int32) (*apisixv1.TrafficSplitConfig, error) {
+	au, err := t.ApisixUpstreamLister.ApisixUpstreams(ns).Get(svcName)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, &translateError{
+			field:  "ApisixUpstream",
+			reason: err.Error(),
+		}
+	}
+	if au.Spec == nil {
+		return nil, nil
+	}
+
+	var wups []apisixv1.TrafficSplitConfigRuleWeightedUpstream
+	for i, ss := range au.Spec.Subsets {
+		if ss.Weight == nil {
+			continue
+		}
+		if *ss.Weight < 0 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("subsets[%d].weight", i),
+				reason: "should not be negative",
+			}
+		}
+		ups, err := t.translateUpstream(ns, svcName, ss.Name, svcResolveGranularity, svcClusterIP, svcPort)
+		if err != nil {
+			return nil, err
+		}
+		ctx.AddUpstream(ups)
+
+		wups = append(wups, apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+			UpstreamID: ups.ID,
+			Weight:     *ss.Weight,
+		})
+	}
+	if len(wups) == 0 {
+		return nil, nil
+	}
+
+	wups = append(wups, apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+		Weight: 0,
+	})
+	return &apisixv1.TrafficSplitConfig{
+		Rules: []apisixv1.TrafficSplitConfigRule{
+			{
+				WeightedUpstreams: wups,
+			},
+		},
+	}, nil
+}
+
+// translateMethodTrafficSplitRules translates the method backends of a route
+// rule to the traffic-split plugin rules, each of them matches the request
+// methods and proxies the requests to the upstream of the backend.
+func (t *translator) translateMethodTrafficSplitRules(ctx *TranslateContext, ns string, routeMethods []string,
+	mbs []configv2.ApisixRouteHTTPMethodBackend) ([]apisixv1.TrafficSplitConfigRule, error) {
+	seen := make(map[string]struct{})
+	rules := make([]apisixv1.TrafficSplitConfigRule, 0, len(mbs))
+	for i, mb := range mbs {
+		if len(mb.Methods) == 0 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("methodBackends[%d].methods", i),
+				reason: "empty",
+			}
+		}
+		for _, method := range mb.Methods {
+			if _, ok := _validHTTPMethods[method]; !ok {
+				return nil, &translateError{
+					field:  fmt.Sprintf("methodBackends[%d].methods", i),
+					reason: fmt.Sprintf("invalid method %s", method),
+				}
+			}
+			if len(routeMethods) > 0 && !containsString(routeMethods, method) {
+				return nil, &translateError{
+					field:  fmt.Sprintf("methodBackends[%d].methods", i),
+					reason: fmt.Sprintf("method %s is not matched by the route", method),
+				}
+			}
+			if _, ok := seen[method]; ok {
+				return nil, &translateError{
+					field:  fmt.Sprintf("methodBackends[%d].methods", i),
+					reason: fmt.Sprintf("duplicated method %s", method),
+				}
+			}
+			seen[method] = struct{}{}
+		}
+
+		backend := mb.Backend
+		svcClusterIP, svcPort, err := t.getServiceClusterIPAndPort(&backend, ns)
+		if err != nil {
+			return nil, err
+		}
+		ups, err := t.translateUpstream(ns, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
+		if err != nil {
+			return nil, err
+		}
+		ctx.AddUpstream(ups)
+
+		rules = append(rules, apisixv1.TrafficSplitConfigRule{
+			Match: []apisixv1.TrafficSplitConfigRuleMatch{
+				{
+					Vars: apisixv1.Vars{
+						{
+							{StrVal: "request_method"},
+							{StrVal: "in"},
+							{SliceVal: mb.Methods},
+						},
+					},
+				},
+			},
+			WeightedUpstreams: []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+				{
+					UpstreamID: ups.ID,
+					Weight:     _defaultWeight,
+				},
+			},
+		})
+	}
+	return rules, nil
+}
+
+// translateCanaryTrafficSplitRules translates the canary rules of a route rule
+// to the traffic-split plugin rules, the stable backend is the default upstream
+// of the route.
+func (t *translator) translateCanaryTrafficSplitRules(ctx *TranslateContext, ns string,
+	canary *configv2.ApisixRouteHTTPCanary) ([]apisixv1.TrafficSplitConfigRule, error) {
+	rules := make([]apisixv1.TrafficSplitConfigRule, 0, len(canary.Rules))
+	for i, cr := range canary.Rules {
+		field := fmt.Sprintf("canary.rules[%d]", i)
+		if len(cr.Backends) == 0 {
+			return nil, &translateError{
+				field:  field + ".backends",
+				reason: "empty",
+			}
+		}
+		if cr.StableWeight < 0 {
+			return nil, &translateError{
+				field:  field + ".stableWeight",
+				reason: "should not be negative",
+			}
+		}
+
+		var rule apisixv1.TrafficSplitConfigRule
+		if len(cr.Exprs) > 0 {
+			vars, err := t.translateRouteMatchExprs(cr.Exprs)
+			if err != nil {
+				return nil, &translateError{
+					field:  field + ".exprs",
+					reason: err.Error(),
+				}
+			}
+			for j, v := range vars {
+				if !_nginxVarRegex.MatchString(v[0].StrVal) {
+					return nil, &translateError{
+						field:  fmt.Sprintf("%s.exprs[%d].subject", field, j),
+						reason: fmt.Sprintf("invalid APISIX variable %s", v[0].StrVal),
+					}
+				}
+			}
+			rule.Match = []apisixv1.TrafficSplitConfigRuleMatch{
+				{
+					Vars: vars,
+				},
+			}
+		}
+
+		total := cr.StableWeight
+		for j, backend := range cr.Backends {
+			weight := _defaultWeight
+			if backend.Weight != nil {
+				weight = *backend.Weight
+			}
+			if weight < 0 {
+				return nil, &translateError{
+					field:  fmt.Sprintf("%s.backends[%d].weight", field, j),
+					reason: "should not be negative",
+				}
+			}
+			total += weight
+
+			svcClusterIP, svcPort, err := t.getServiceClusterIPAndPort(&backend, ns)
+			if err != nil {
+				return nil, err
+			}
+			ups, err := t.translateUpstream(ns, backend.ServiceName, backend.Subset, backend.ResolveGranularity, svcClusterIP, svcPort)
+			if err != nil {
+				return nil, err
+			}
+			ctx.AddUpstream(ups)
+
+			rule.WeightedUpstreams = append(rule.WeightedUpstreams, apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+				UpstreamID: ups.ID,
+				Weight:     weight,
+			})
+		}
+		if total == 0 {
+			return nil, &translateError{
+				field:  field,
+				reason: "all weights are zero",
+			}
+		}
+		// The stable backend, i.e. the default upstream of the route.
+		rule.WeightedUpstreams = append(rule.WeightedUpstreams, apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+			Weight: cr.StableWeight,
+		})
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// translatePluginMeta validates the _meta field in the plugin config and returns
+// a copy of the config with the priority override and the filter set, the
+// returned config is nil if there is neither of them.
+func (t *translator) translatePluginMeta(name string, cfg map[string]interface{}, priority *int64, filter []configv2.ApisixRouteHTTPMatchExpr) (map[string]interface{}, error) {
+	var meta map[string]interface{}
+	if raw, ok := cfg["_meta"]; ok {
+		meta, ok = raw.(map[string]interface{})
+		if !ok {
+			return nil, &translateError{field: name + "._meta", reason: "should be an object"}
+		}
+		if p, ok := meta["priority"]; ok && !isInteger(p) {
+			return nil, &translateError{field: name + "._meta.priority", reason: "should be an integer"}
+		}
+		if _, ok := meta["filter"]; ok && len(filter) > 0 {
+			return nil, &translateError{field: name + ".filter", reason: "can't be used together with _meta.filter in config"}
+		}
+	}
+	var vars apisixv1.Vars
+	if len(filter) > 0 {
+		var err error
+		vars, err = t.translateRouteMatchExprs(filter)
+		if err != nil {
+			return nil, &translateError{field: name + ".filter", reason: err.Error()}
+		}
+	}
+	if priority == nil && vars == nil {
+		return nil, nil
+	}
+
+	out := make(map[string]interface{}, len(cfg)+1)
+	for k, v := range cfg {
+		out[k] = v
+	}
+	newMeta := make(map[string]interface{}, len(meta)+1)
+	for k, v := range meta {
+		newMeta[k] = v
+	}
+	if priority != nil {
+		newMeta["priority"] = *priority
+	}
+	if vars != nil {
+		newMeta["filter"] = vars
+	}
+	out["_meta"] = newMeta
+	return out, nil
+}
+
+func isInteger(v interface{}) bool {
+	switch n := v.(type) {
+	case int, int32, int64:
+		return true
+	case float64:
+		return n == math.Trunc(n)
+	default:
+		return false
+	}
+}
+
+func (t *translator) translateFileLoggerPlugin(cfg *configv2.ApisixRouteHTTPFileLogger) (*apisixv1.FileLoggerConfig, error) {
+	if cfg.Path == "" {
+		return nil, &translateError{field: "fileLogger.path", reason: "empty path"}
+	}
+	if !strings.HasPrefix(cfg.Path, "/") || strings.HasSuffix(cfg.Path, "/") {
+		return nil, &translateError{field: "fileLogger.path", reason: "should be an absolute file path"}
+	}
+	if path.Clean(cfg.Path) != cfg.Path {
+		return nil, &translateError{field: "fileLogger.path", reason: "should be a clean path"}
+	}
+	for k := range cfg.LogFormat {
+		if k == "" {
+			return nil, &translateError{field: "fileLogger.logFormat", reason: "empty field name"}
+		}
+	}
+	return &apisixv1.FileLoggerConfig{
+		Path:      cfg.Path,
+		LogFormat: cfg.LogFormat,
+	}, nil
+}
+
+// _defaultPluginsFromKey is the ConfigMap key of plugins if it's not
+// specified in pluginsFrom.
+const _defaultPluginsFromKey = "plugins.json"
+
+// translatePluginsFrom loads the plugins from the ConfigMap referenced by
+// pluginsFrom, the value should be a JSON object mapping plugin names to
+// their configurations.
+func (t *translator) translatePluginsFrom(ns string, ref *configv2.ApisixRouteHTTPPluginsFrom) (apisixv1.Plugins, error) {
+	if ref.ConfigMapName == "" {
+		return nil, &translateError{field: "pluginsFrom.configMapName", reason: "empty ConfigMap name"}
+	}
+	key := ref.Key
+	if key == "" {
+		key = _defaultPluginsFromKey
+	}
+	cm, err := t.ConfigMapLister.ConfigMaps(ns).Get(ref.ConfigMapName)
+	if err != nil {
+		return nil, &translateError{field: "pluginsFrom", reason: err.Error()}
+	}
+	data, ok := cm.Data[key]
+	if !ok {
+		return nil, &translateError{
+			field:  "pluginsFrom.key",
+			reason: fmt.Sprintf("key %s not found in ConfigMap %s", key, ref.ConfigMapName),
+		}
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &raw); err != nil {
+		return nil, &translateError{
+			field:  "pluginsFrom",
+			reason: fmt.Sprintf("invalid plugins JSON in ConfigMap %s: %s", ref.ConfigMapName, err),
+		}
+	}
+	plugins := make(apisixv1.Plugins, len(raw))
+	for name, value := range raw {
+		var cfg map[string]interface{}
+		if err := json.Unmarshal(value, &cfg); err != nil || cfg == nil {
+			return nil, &translateError{
+				field:  "pluginsFrom",
+				reason: fmt.Sprintf("config of plugin %s in ConfigMap %s should be an object", name, ref.ConfigMapName),
+			}
+		}
+		plugins[name] = cfg
+	}
+	return plugins, nil
+}
+
+// ValidateIPRestriction checks the ipRestriction of an ApisixRoute rule,
+// the addresses should be IPs or CIDRs, and only one of allowlist and
+// denylist can be specified as APISIX does.
+func ValidateIPRestriction(cfg *configv2.ApisixRouteHTTPIPRestriction) error {
+	if len(cfg.Allowlist) > 0 && len(cfg.Denylist) > 0 {
+		return &translateError{field: "ipRestriction", reason: "allowlist and denylist cannot be used together"}
+	}
+	if len(cfg.Allowlist) == 0 && len(cfg.Denylist) == 0 {
+		return &translateError{field: "ipRestriction", reason: "either allowlist or denylist is required"}
+	}
+	if err := validateRemoteAddrs(cfg.Allowlist); err != nil {
+		return &translateError{field: "ipRestriction.allowlist", reason: err.Error()}
+	}
+	if err := validateRemoteAddrs(cfg.Denylist); err != nil {
+		return &translateError{field: "ipRestriction.denylist", reason: err.Error()}
+	}
+	return nil
+}
+
+func (t *translator) translateIPRestrictionPlugin(cfg *configv2.ApisixRouteHTTPIPRestriction) (*apisixv1.IPRestrictConfig, error) {
+	if err := ValidateIPRestriction(cfg); err != nil {
+		return nil, err
+	}
+	return &apisixv1.IPRestrictConfig{
+		Allowlist: cfg.Allowlist,
+		Blocklist: cfg.Denylist,
+	}, nil
+}
+
+// ValidateForwardAuth checks the forwardAuth of an ApisixRoute rule, the
+// uri should be an absolute http or https URL.
+func ValidateForwardAuth(cfg *configv2.ApisixRouteHTTPForwardAuth) error {
+	if cfg.URI == "" {
+		return &translateError{field: "forwardAuth.uri", reason: "empty uri"}
+	}
+	u, err := url.Parse(cfg.URI)
+	if err != nil {
+		return &translateError{field: "forwardAuth.uri", reason: err.Error()}
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &translateError{field: "forwardAuth.uri", reason: "should be an http or https URL"}
+	}
+	return nil
+}
+
+const (
+	_corsDefaultMaxAge = 5
+	// _corsCredentialMethods and _corsCredentialHeaders are the defaults
+	// when credentials are allowed, since the wildcard doesn't work then.
+	_corsCredentialMethods = "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"
+	_corsCredentialHeaders = "Accept,Accept-Language,Content-Language,Content-Type,Authorization"
+)
+
+// ValidateCors checks the cors of an ApisixRoute rule, the wildcards
+// cannot be used when the credentials are allowed, otherwise any site
+// could send credentialed requests.
+func ValidateCors(cfg *configv2.ApisixRouteHTTPCors) error {
+	if cfg.MaxAge != nil && *cfg.MaxAge < -1 {
+		return &translateError{field: "cors.maxAge", reason: "should not be less than -1"}
+	}
+	if !cfg.AllowCredentials {
+		return nil
+	}
+	if len(cfg.AllowOrigins) == 0 {
+		return &translateError{field: "cors.allowOrigins", reason: "required when allowCredentials is true"}
+	}
+	fields := []struct {
+		name   string
+		values []string
+	}{
+		{"cors.allowOrigins", cfg.AllowOrigins},
+		{"cors.allowMethods", cfg.AllowMethods},
+		{"cors.allowHeaders", cfg.AllowHeaders},
+		{"cors.exposeHeaders", cfg.ExposeHeaders},
+	}
+	for _, field := range fields {
+		for _, v := range field.values {
+			if strings.Contains(v, "*") {
+				return &translateError{field: field.name, reason: "wildcard cannot be used when allowCredentials is true"}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *translator) translateCorsPlugin(cfg *configv2.ApisixRouteHTTPCors) (*apisixv1.CorsConfig, error) {
+	if err := ValidateCors(cfg); err != nil {
+		return nil, err
+	}
+	maxAge := _corsDefaultMaxAge
+	if cfg.MaxAge != nil {
+		maxAge = *cfg.MaxAge
+	}
+	conf := &apisixv1.CorsConfig{
+		AllowOrigins:    "*",
+		AllowMethods:    "*",
+		AllowHeaders:    "*",
+		ExposeHeaders:   strings.Join(cfg.ExposeHeaders, ","),
+		MaxAge:          &maxAge,
+		AllowCredential: cfg.AllowCredentials,
+	}
+	if cfg.AllowCredentials {
+		conf.AllowMethods = _corsCredentialMethods
+		conf.AllowHeaders = _corsCredentialHeaders
+	}
+	if len(cfg.AllowOrigins) > 0 {
+		conf.AllowOrigins = strings.Join(cfg.AllowOrigins, ",")
+	}
+	if len(cfg.AllowMethods) > 0 {
+		conf.AllowMethods = strings.Join(cfg.AllowMethods, ",")
+	}
+	if len(cfg.AllowHeaders) > 0 {
+		conf.AllowHeaders = strings.Join(cfg.AllowHeaders, ",")
+	}
+	return conf, nil
+}
+
+// _headerNameRegex matches the valid HTTP header names (tokens).
+var _headerNameRegex = regexp.MustCompile("^[!#$%&'*+\\-.^_`|~0-9A-Za-z]+$")
+
+// ValidateResponseRewrite checks the responseRewrite of an ApisixRoute rule.
+func ValidateResponseRewrite(cfg *configv2.ApisixRouteHTTPResponseRewrite) error {
+	if cfg.StatusCode != 0 && (cfg.StatusCode < 200 || cfg.StatusCode > 598) {
+		return &translateError{field: "responseRewrite.statusCode", reason: "should be in [200, 598]"}
+	}
+	if cfg.Body != "" && cfg.BodyBase64 != "" {
+		return &translateError{field: "responseRewrite.bodyBase64", reason: "can't be used together with body"}
+	}
+	if cfg.BodyBase64 != "" {
+		if _, err := base64.StdEncoding.DecodeString(cfg.BodyBase64); err != nil {
+			return &translateError{field: "responseRewrite.bodyBase64", reason: "invalid base64 content"}
+		}
+	}
+	if cfg.Headers == nil {
+		return nil
+	}
+	seen := make(map[string]string)
+	check := func(field, name string) error {
+		if !_headerNameRegex.MatchString(name) {
+			return &translateError{field: field, reason: fmt.Sprintf("invalid header name %q", name)}
+		}
+		key := strings.ToLower(name)
+		if prev, ok := seen[key]; ok && prev != field {
+			return &translateError{field: field, reason: fmt.Sprintf("header %s is also in %s", name, prev)}
+		}
+		seen[key] = field
+		return nil
+	}
+	for name := range cfg.Headers.Set {
+		if err := check("responseRewrite.headers.set", name); err != nil {
+			return err
+		}
+	}
+	for name := range cfg.Headers.Add {
+		if err := check("responseRewrite.headers.add", name); err != nil {
+			return err
+		}
+	}
+	for _, name := range cfg.Headers.Remove {
+		if err := check("responseRewrite.headers.remove", name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *translator) translateResponseRewritePlugin(cfg *configv2.ApisixRouteHTTPResponseRewrite) (*apisixv1.ResponseRewriteConfig, error) {
+	if err := ValidateResponseRewrite(cfg); err != nil {
+		return nil, err
+	}
+	conf := &apisixv1.ResponseRewriteConfig{
+		StatusCode: cfg.StatusCode,
+		Body:       cfg.Body,
+	}
+	if cfg.BodyBase64 != "" {
+		conf.Body = cfg.BodyBase64
+		conf.BodyBase64 = true
+	}
+	if cfg.Headers != nil {
+		headers := &apisixv1.ResponseRewriteHeaders{
+			Set:    cfg.Headers.Set,
+			Remove: cfg.Headers.Remove,
+		}
+		for name, value := range cfg.Headers.Add {
+			headers.Add = append(headers.Add, name+": "+value)
+		}
+		// Keep the order stable to not update the route needlessly.
+		sort.Strings(headers.Add)
+		conf.Headers = headers
+	}
+	return conf, nil
+}
+
+const (
+	_requestIDDefaultHeader    = "X-Request-Id"
+	_requestIDDefaultAlgorithm = "uuid"
+)
+
+// _requestIDAlgorithms are the algorithms supported by the request-id
+// plugin.
+var _requestIDAlgorithms = map[string]struct{}{
+	"uuid":      {},
+	"snowflake": {},
+	"range_id":  {},
+}
+
+// ValidateRequestID checks the requestId of an ApisixRoute rule.
+func ValidateRequestID(cfg *configv2.ApisixRouteHTTPRequestID) error {
+	if cfg.HeaderName != "" && !_headerNameRegex.MatchString(cfg.HeaderName) {
+		return &translateError{field: "requestId.headerName", reason: fmt.Sprintf("invalid header name %q", cfg.HeaderName)}
+	}
+	if cfg.Algorithm != "" {
+		if _, ok := _requestIDAlgorithms[cfg.Algorithm]; !ok {
+			return &translateError{field: "requestId.algorithm", reason: fmt.Sprintf("unsupported algorithm %s", cfg.Algorithm)}
+		}
+	}
+	return nil
+}
+
+func (t *translator) translateRequestIDPlugin(cfg *configv2.ApisixRouteHTTPRequestID) (*apisixv1.RequestIDConfig, error) {
+	if err := ValidateRequestID(cfg); err != nil {
+		return nil, err
+	}
+	conf := &apisixv1.RequestIDConfig{
+		HeaderName:        _requestIDDefaultHeader,
+		IncludeInResponse: true,
+		Algorithm:         _requestIDDefaultAlgorithm,
+	}
+	if cfg.HeaderName != "" {
+		conf.HeaderName = cfg.HeaderName
+	}
+	if cfg.Algorithm != "" {
+		conf.Algorithm = cfg.Algorithm
+	}
+	if cfg.IncludeInResponse != nil {
+		conf.IncludeInResponse = *cfg.IncludeInResponse
+	}
+	return conf, nil
+}
+
+const _serverlessDefaultPhase = "access"
+
+// _serverlessPhases are the phases supported by the serverless plugins.
+var _serverlessPhases = map[string]struct{}{
+	"rewrite":       {},
+	"access":        {},
+	"header_filter": {},
+	"body_filter":   {},
+	"log":           {},
+	"before_proxy":  {},
+}
+
+// ValidateServerless checks the serverless of an ApisixRoute rule, whether
+// it's allowed is decided by the controller configuration.
+func ValidateServerless(cfg *configv2.ApisixRouteHTTPServerless) error {
+	if cfg.Pre == nil && cfg.Post == nil {
+		return &translateError{field: "serverless", reason: "either pre or post should be set"}
+	}
+	if err := validateServerlessFunctions("serverless.pre", cfg.Pre); err != nil {
+		return err
+	}
+	return validateServerlessFunctions("serverless.post", cfg.Post)
+}
+
+func validateServerlessFunctions(field string, fns *configv2.ApisixRouteHTTPServerlessFunctions) error {
+	if fns == nil {
+		return nil
+	}
+	if fns.Phase != "" {
+		if _, ok := _serverlessPhases[fns.Phase]; !ok {
+			return &translateError{field: field + ".phase", reason: fmt.Sprintf("unsupported phase %s", fns.Phase)}
+		}
+	}
+	if len(fns.Functions) == 0 {
+		return &translateError{field: field + ".functions", reason: "empty"}
+	}
+	for _, fn := range fns.Functions {
+		if strings.TrimSpace(fn) == "" {
+			return &translateError{field: field + ".functions", reason: "empty function"}
+		}
+	}
+	return nil
+}
+
+// _serverlessPlugins are the plugins running inline Lua functions.
+var _serverlessPlugins = map[string]struct{}{
+	"serverless-pre-function":  {},
+	"serverless-post-function": {},
+}
+
+// IsServerlessPlugin reports whether the plugin runs inline Lua functions.
+func IsServerlessPlugin(name string) bool {
+	_, ok := _serverlessPlugins[name]
+	return ok
+}
+
+// checkServerlessPlugins rejects the serverless plugins in the plugin map
+// unless inline Lua functions are allowed by the controller.
+func (t *translator) checkServerlessPlugins(field string, plugins apisixv1.Plugins) error {
+	for name := range plugins {
+		if IsServerlessPlugin(name) && !t.AllowServerlessFunctions {
+			return &translateError{
+				field:  field,
+				reason: fmt.Sprintf("plugin %s is not allowed since inline Lua functions are disabled by the controller", name),
+			}
+		}
+	}
+	return nil
+}
+
+// translateServerlessPlugins assembles the serverless-pre-function and
+// serverless-post-function plugins, keyed by the plugin name.
+func (t *translator) translateServerlessPlugins(cfg *configv2.ApisixRouteHTTPServerless) (map[string]*apisixv1.ServerlessConfig, error) {
+	if !t.AllowServerlessFunctions {
+		return nil, &translateError{field: "serverless", reason: "inline Lua functions are not allowed by the controller"}
+	}
+	if err := ValidateServerless(cfg); err != nil {
+		return nil, err
+	}
+	plugins := make(map[string]*apisixv1.ServerlessConfig)
+	for name, fns := range map[string]*configv2.ApisixRouteHTTPServerlessFunctions{
+		"serverless-pre-function":  cfg.Pre,
+		"serverless-post-function": cfg.Post,
+	} {
+		if fns == nil {
+			continue
+		}
+		phase := fns.Phase
+		if phase == "" {
+			phase = _serverlessDefaultPhase
+		}
+		plugins[name] = &apisixv1.ServerlessConfig{
+			Phase:     phase,
+			Functions: fns.Functions,
+		}
+	}
+	return plugins, nil
+}
+
+func (t *translator) translateForwardAuthPlugin(cfg *configv2.ApisixRouteHTTPForwardAuth) (*apisixv1.ForwardAuthConfig, error) {
+	if err := ValidateForwardAuth(cfg); err != nil {
+		return nil, err
+	}
+	sslVerify := true
+	if cfg.SSLVerify != nil {
+		sslVerify = *cfg.SSLVerify
+	}
+	return &apisixv1.ForwardAuthConfig{
+		URI:             cfg.URI,
+		SSLVerify:       sslVerify,
+		RequestHeaders:  cfg.RequestHeaders,
+		UpstreamHeaders: cfg.UpstreamHeaders,
+		ClientHeaders:   cfg.ClientHeaders,
+	}, nil
+}
+
+const (
+	_limitCountPolicyRedis        = "redis"
+	_limitCountPolicyRedisCluster = "redis-cluster"
+	// _redisPasswordKey is the key of the password in the Secret
+	// referenced by passwordSecretRef.
+	_redisPasswordKey = "password"
+)
+
+// translateLimitCountPlugin assembles the limit-count plugin, the counter
+// store is taken from the ApisixRateLimitPolicy referenced by policyRef,
+// the counters are local to each APISIX node if it's not specified.
+func (t *translator) translateLimitCountPlugin(ns string, cfg *configv2.ApisixRouteHTTPLimitCount) (*apisixv1.LimitCountConfig, error) {
+	if cfg.Count <= 0 {
+		return nil, &translateError{field: "limitCount.count", reason: "should be greater than 0"}
+	}
+	if cfg.TimeWindow <= 0 {
+		return nil, &translateError{field: "limitCount.timeWindow", reason: "should be greater than 0"}
+	}
+	lc := &apisixv1.LimitCountConfig{
+		Count:        cfg.Count,
+		TimeWindow:   cfg.TimeWindow,
+		Key:          cfg.Key,
+		RejectedCode: cfg.RejectedCode,
+	}
+	if cfg.PolicyRef == "" {
+		return lc, nil
+	}
+	if t.ApisixRateLimitPolicyLister == nil {
+		return nil, &translateError{field: "limitCount.policyRef", reason: "ApisixRateLimitPolicy is not watched"}
+	}
+	policy, err := t.ApisixRateLimitPolicyLister.ApisixRateLimitPolicies(ns).Get(cfg.PolicyRef)
+	if err != nil {
+		return nil, &translateError{field: "limitCount.policyRef", reason: err.Error()}
+	}
+	spec := &policy.Spec
+	switch spec.Policy {
+	case "", _limitCountPolicyRedis:
+		if spec.Redis == nil || spec.Redis.Host == "" {
+			return nil, &translateError{
+				field:  "limitCount.policyRef",
+				reason: fmt.Sprintf("ApisixRateLimitPolicy %s has no redis host", cfg.PolicyRef),
+			}
+		}
+		password, err := t.loadRedisPassword(ns, spec.Redis.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		lc.Policy = _limitCountPolicyRedis
+		lc.RedisHost = spec.Redis.Host
+		lc.RedisPort = spec.Redis.Port
+		lc.RedisDatabase = spec.Redis.Database
+		lc.RedisTimeout = spec.Redis.Timeout
+		lc.RedisPassword = password
+	case _limitCountPolicyRedisCluster:
+		if spec.RedisCluster == nil || len(spec.RedisCluster.Nodes) == 0 || spec.RedisCluster.Name == "" {
+			return nil, &translateError{
+				field:  "limitCount.policyRef",
+				reason: fmt.Sprintf("ApisixRateLimitPolicy %s needs the nodes and name of the redis cluster", cfg.PolicyRef),
+			}
+		}
+		password, err := t.loadRedisPassword(ns, spec.RedisCluster.PasswordSecretRef)
+		if err != nil {
+			return nil, err
+		}
+		lc.Policy = _limitCountPolicyRedisCluster
+		lc.RedisClusterNode = spec.RedisCluster.Nodes
+		lc.RedisClusterName = spec.RedisCluster.Name
+		lc.RedisTimeout = spec.RedisCluster.Timeout
+		lc.RedisPassword = password
+	default:
+		return nil, &translateError{
+			field:  "limitCount.policyRef",
+			reason: fmt.Sprintf("unknown policy %s in ApisixRateLimitPolicy %s", spec.Policy, cfg.PolicyRef),
+		}
+	}
+	return lc, nil
+}
+
+// _bodyTransformerInputFormats are the body formats supported by the
+// body-transformer plugin.
+var _bodyTransformerInputFormats = map[string]struct{}{
+	"xml":       {},
+	"json":      {},
+	"encoded":   {},
+	"args":      {},
+	"plain":     {},
+	"multipart": {},
+}
+
+// translateBodyTransformerPlugin loads the templates of bodyTransformer
+// from the ConfigMap, so that they needn't be escaped in the ApisixRoute.
+func (t *translator) translateBodyTransformerPlugin(ns string, cfg *configv2.ApisixRouteHTTPBodyTransformer) (*apisixv1.BodyTransformerConfig, error) {
+	if cfg.ConfigMapName == "" {
+		return nil, &translateError{field: "bodyTransformer.configMapName", reason: "empty ConfigMap name"}
+	}
+	if cfg.Request == nil && cfg.Response == nil {
+		return nil, &translateError{field: "bodyTransformer", reason: "at least one of request and response should be specified"}
+	}
+	cm, err := t.ConfigMapLister.ConfigMaps(ns).Get(cfg.ConfigMapName)
+	if err != nil {
+		return nil, &translateError{field: "bodyTransformer.configMapName", reason: err.Error()}
+	}
+	load := func(field string, tmpl *configv2.ApisixRouteHTTPBodyTransformerTemplate) (*apisixv1.BodyTransformerTemplate, error) {
+		if tmpl == nil {
+			return nil, nil
+		}
+		if tmpl.InputFormat != "" {
+			if _, ok := _bodyTransformerInputFormats[tmpl.InputFormat]; !ok {
+				return nil, &translateError{field: field + ".inputFormat", reason: "unknown input format " + tmpl.InputFormat}
+			}
+		}
+		if tmpl.TemplateKey == "" {
+			return nil, &translateError{field: field + ".templateKey", reason: "empty template key"}
+		}
+		data := cm.Data[tmpl.TemplateKey]
+		if strings.TrimSpace(data) == "" {
+			return nil, &translateError{
+				field:  field + ".templateKey",
+				reason: fmt.Sprintf("template %s not found in ConfigMap %s", tmpl.TemplateKey, cfg.ConfigMapName),
+			}
+		}
+		return &apisixv1.BodyTransformerTemplate{
+			InputFormat: tmpl.InputFormat,
+			Template:    data,
+		}, nil
+	}
+	var bt apisixv1.BodyTransformerConfig
+	if bt.Request, err = load("bodyTransformer.request", cfg.Request); err != nil {
+		return nil, err
+	}
+	if bt.Response, err = load("bodyTransformer.response", cfg.Response); err != nil {
+		return nil, err
+	}
+	return &bt, nil
+}
+
+// loadRedisPassword reads the redis password from the Secret, an empty
+// password is returned if no Secret is referenced.
+func (t *translator) loadRedisPassword(ns string, ref *corev1.LocalObjectReference) (string, error) {
+	if ref == nil || ref.Name == "" {
+		return "", nil
+	}
+	sec, err := t.SecretLister.Secrets(ns).Get(ref.Name)
+	if err != nil {
+		return "", &translateError{field: "limitCount.policyRef", reason: err.Error()}
+	}
+	password, ok := sec.Data[_redisPasswordKey]
+	if !ok {
+		return "", &translateError{
+			field:  "limitCount.policyRef",
+			reason: fmt.Sprintf("key %s not found in Secret %s", _redisPasswordKey, ref.Name),
+		}
+	}
+	return string(password), nil
+}
+
+func (t *translator) translateRewritePlugin(cfg *configv2.ApisixRouteHTTPRewrite) (*apisixv1.RewriteConfig, error) {
+	if cfg.Regex == "" {
+		return nil, &translateError{field: "rewrite.regex", reason: "empty regex"}
+	}
+	re, err := regexp.Compile(cfg.Regex)
+	if err != nil {
+		return nil, &translateError{field: "rewrite.regex", reason: err.Error()}
+	}
+	if err := validateRewriteReplacement(cfg.Replacement, re.NumSubexp()); err != nil {
+		return nil, err
+	}
+	return &apisixv1.RewriteConfig{
+		RewriteTargetRegex: []string{cfg.Regex, cfg.Replacement},
+	}, nil
+}
+
+// validateRewriteReplacement checks the capture group references ($N or
+// ${N}) in the replacement, the groups should exist in the regex.
+func validateRewriteReplacement(replacement string, groups int) error {
+	for i := 0; i < len(replacement); i++ {
+		if replacement[i] != '$' {
+			continue
+		}
+		if i+1 < len(replacement) && replacement[i+1] == '$' {
+			i++
+			continue
+		}
+		ref := replacement[i+1:]
+		braced := strings.HasPrefix(ref, "{")
+		if braced {
+			end := strings.IndexByte(ref, '}')
+			if end < 0 {
+				return &translateError{field: "rewrite.replacement", reason: "unclosed group reference"}
+			}
+			ref = ref[1:end]
+			i += end + 1
+		} else {
+			end := 0
+			for end < len(ref) && ref[end] >= '0' && ref[end] <= '9' {
+				end++
+			}
+			ref = ref[:end]
+			i += end
+		}
+		n, err := strconv.Atoi(ref)
+		if err != nil || strings.TrimLeft(ref, "0123456789") != "" {
+			return &translateError{field: "rewrite.replacement", reason: "invalid group reference, should be $N or ${N}"}
+		}
+		if n > groups {
+			return &translateError{
+				field:  "rewrite.replacement",
+				reason: fmt.Sprintf("group %d does not exist in the regex", n),
+			}
+		}
+	}
+	return nil
+}
+
 func (t *translator) translateConsumerKeyAuthPluginV2beta3(consumerNamespace string, cfg *configv2beta3.ApisixConsumerKeyAuth) (*apisixv1.KeyAuthConsumerConfig, error) {
 	if cfg.Value != nil {
 		return &apisixv1.KeyAuthConsumerConfig{Key: cfg.Value.Key}, nil
@@ -0,0 +1,79 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+var _pluginNameRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+func (t *translator) TranslatePluginMetadataV2(acc *configv2.ApisixClusterConfig) ([]*apisixv1.PluginMetadata, error) {
+	names := make([]string, 0, len(acc.Spec.PluginMetadata))
+	for name := range acc.Spec.PluginMetadata {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pms := make([]*apisixv1.PluginMetadata, 0, len(names))
+	for _, name := range names {
+		field := "pluginMetadata." + name
+		if !_pluginNameRegex.MatchString(name) {
+			return nil, &translateError{field: field, reason: "invalid plugin name"}
+		}
+		metadata := acc.Spec.PluginMetadata[name]
+		if metadata == nil {
+			return nil, &translateError{field: field, reason: "should be a JSON object"}
+		}
+		// Make sure the metadata can be encoded, it's sent to APISIX as is.
+		if _, err := json.Marshal(metadata); err != nil {
+			return nil, &translateError{field: field, reason: err.Error()}
+		}
+		pms = append(pms, &apisixv1.PluginMetadata{
+			Name:     name,
+			Metadata: metadata,
+		})
+	}
+	return pms, nil
+}
+
+// RemovedPluginMetadata returns the plugin_metadata in prev but not in curr,
+// they should be deleted from the APISIX cluster.
+func RemovedPluginMetadata(prev, curr *configv2.ApisixClusterConfig) []*apisixv1.PluginMetadata {
+	if prev == nil {
+		return nil
+	}
+	var names []string
+	for name := range prev.Spec.PluginMetadata {
+		if curr != nil {
+			if _, ok := curr.Spec.PluginMetadata[name]; ok {
+				continue
+			}
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pms := make([]*apisixv1.PluginMetadata, 0, len(names))
+	for _, name := range names {
+		pms = append(pms, &apisixv1.PluginMetadata{Name: name})
+	}
+	return pms
+}
@@ -0,0 +1,96 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
+)
+
+func TestTranslatePluginMetadataV2(t *testing.T) {
+	tr := &translator{}
+
+	acc := &configv2.ApisixClusterConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "qa-apisix",
+		},
+		Spec: configv2.ApisixClusterConfigSpec{
+			PluginMetadata: map[string]configv2.Config{
+				"http-logger": {
+					"log_format": map[string]interface{}{
+						"host": "$host",
+					},
+				},
+				"error-log-logger": {
+					"host": "127.0.0.1",
+				},
+			},
+		},
+	}
+	pms, err := tr.TranslatePluginMetadataV2(acc)
+	assert.Nil(t, err)
+	assert.Len(t, pms, 2)
+	assert.Equal(t, "error-log-logger", pms[0].Name)
+	assert.Equal(t, map[string]interface{}{"host": "127.0.0.1"}, pms[0].Metadata)
+	assert.Equal(t, "http-logger", pms[1].Name)
+
+	acc.Spec.PluginMetadata = nil
+	pms, err = tr.TranslatePluginMetadataV2(acc)
+	assert.Nil(t, err)
+	assert.Len(t, pms, 0)
+
+	acc.Spec.PluginMetadata = map[string]configv2.Config{"http-logger": nil}
+	_, err = tr.TranslatePluginMetadataV2(acc)
+	assert.Equal(t, &translateError{field: "pluginMetadata.http-logger", reason: "should be a JSON object"}, err)
+
+	acc.Spec.PluginMetadata = map[string]configv2.Config{"Http Logger": {}}
+	_, err = tr.TranslatePluginMetadataV2(acc)
+	assert.Equal(t, &translateError{field: "pluginMetadata.Http Logger", reason: "invalid plugin name"}, err)
+
+	acc.Spec.PluginMetadata = map[string]configv2.Config{"http-logger": {"bad": func() {}}}
+	_, err = tr.TranslatePluginMetadataV2(acc)
+	assert.NotNil(t, err)
+}
+
+func TestRemovedPluginMetadata(t *testing.T) {
+	prev := &configv2.ApisixClusterConfig{
+		Spec: configv2.ApisixClusterConfigSpec{
+			PluginMetadata: map[string]configv2.Config{
+				"http-logger":        {},
+				"error-log-logger":   {},
+				"ext-plugin-pre-req": {},
+			},
+		},
+	}
+	curr := &configv2.ApisixClusterConfig{
+		Spec: configv2.ApisixClusterConfigSpec{
+			PluginMetadata: map[string]configv2.Config{
+				"http-logger": {},
+			},
+		},
+	}
+
+	pms := RemovedPluginMetadata(prev, curr)
+	assert.Len(t, pms, 2)
+	assert.Equal(t, "error-log-logger", pms[0].Name)
+	assert.Equal(t, "ext-plugin-pre-req", pms[1].Name)
+
+	assert.Len(t, RemovedPluginMetadata(prev, nil), 3)
+	assert.Len(t, RemovedPluginMetadata(nil, curr), 0)
+}
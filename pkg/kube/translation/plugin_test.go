@@ -16,7 +16,9 @@ package translation
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
@@ -24,14 +26,16 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes/fake"
+	listerscorev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 
-	"github.com/apache/apisix-ingress-controller/pkg/id"
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
 	apisixfake "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
 	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 func TestTranslateTrafficSplitPlugin(t *testing.T) {
@@ -197,9 +201,9 @@ func TestTranslateTrafficSplitPlugin(t *testing.T) {
 
 	assert.Len(t, cfg.Rules, 1)
 	assert.Len(t, cfg.Rules[0].WeightedUpstreams, 3)
-	assert.Equal(t, id.GenID("test_svc-1_80"), cfg.Rules[0].WeightedUpstreams[0].UpstreamID)
+	assert.Equal(t, ctx.Upstreams[0].ID, cfg.Rules[0].WeightedUpstreams[0].UpstreamID)
 	assert.Equal(t, 10, cfg.Rules[0].WeightedUpstreams[0].Weight)
-	assert.Equal(t, id.GenID("test_svc-1_443"), cfg.Rules[0].WeightedUpstreams[1].UpstreamID)
+	assert.Equal(t, ctx.Upstreams[1].ID, cfg.Rules[0].WeightedUpstreams[1].UpstreamID)
 	assert.Equal(t, 20, cfg.Rules[0].WeightedUpstreams[1].Weight)
 	assert.Equal(t, "", cfg.Rules[0].WeightedUpstreams[2].UpstreamID)
 	assert.Equal(t, 30, cfg.Rules[0].WeightedUpstreams[2].Weight)
@@ -361,9 +365,9 @@ func TestTranslateTrafficSplitPluginWithSameUpstreams(t *testing.T) {
 
 	assert.Len(t, cfg.Rules, 1)
 	assert.Len(t, cfg.Rules[0].WeightedUpstreams, 3)
-	assert.Equal(t, id.GenID("test_svc-1_80"), cfg.Rules[0].WeightedUpstreams[0].UpstreamID)
+	assert.Equal(t, ctx.Upstreams[0].ID, cfg.Rules[0].WeightedUpstreams[0].UpstreamID)
 	assert.Equal(t, 10, cfg.Rules[0].WeightedUpstreams[0].Weight)
-	assert.Equal(t, id.GenID("test_svc-1_80"), cfg.Rules[0].WeightedUpstreams[1].UpstreamID)
+	assert.Equal(t, ctx.Upstreams[0].ID, cfg.Rules[0].WeightedUpstreams[1].UpstreamID)
 	assert.Equal(t, 20, cfg.Rules[0].WeightedUpstreams[1].Weight)
 	assert.Equal(t, "", cfg.Rules[0].WeightedUpstreams[2].UpstreamID)
 	assert.Equal(t, 30, cfg.Rules[0].WeightedUpstreams[2].Weight)
@@ -1022,3 +1026,645 @@ func TestTranslateConsumerHMACAuthPluginWithSecretRef(t *testing.T) {
 	close(processCh)
 	close(stopCh)
 }
+
+func TestTranslateSubsetTrafficSplitPlugin(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "port1",
+					Port: 80,
+					TargetPort: intstr.IntOrString{
+						Type:   intstr.Int,
+						IntVal: 9080,
+					},
+				},
+			},
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Ports: []corev1.EndpointPort{
+					{
+						Name: "port1",
+						Port: 9080,
+					},
+				},
+				Addresses: []corev1.EndpointAddress{
+					{IP: "192.168.1.1"},
+					{IP: "192.168.1.2"},
+				},
+			},
+		},
+	}
+	pod1 := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "test",
+			Labels:    map[string]string{"version": "v1"},
+		},
+		Status: corev1.PodStatus{PodIP: "192.168.1.1"},
+	}
+	pod2 := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod2",
+			Namespace: "test",
+			Labels:    map[string]string{"version": "v2"},
+		},
+		Status: corev1.PodStatus{PodIP: "192.168.1.2"},
+	}
+	weight90 := 90
+	weight10 := 10
+	au := &configv2beta3.ApisixUpstream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Spec: &configv2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: configv2beta3.ApisixUpstreamConfig{
+				Subsets: []configv2beta3.ApisixUpstreamSubset{
+					{
+						Name:   "v1",
+						Labels: map[string]string{"version": "v1"},
+						Weight: &weight90,
+					},
+					{
+						Name:   "v2",
+						Labels: map[string]string{"version": "v2"},
+						Weight: &weight10,
+					},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(svc, endpoints, pod1, pod2)
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+	podInformer := informersFactory.Core().V1().Pods().Informer()
+	epLister, epInformer := kube.NewEndpointListerAndInformer(informersFactory, false)
+	apisixClient := apisixfake.NewSimpleClientset(au)
+	apisixInformersFactory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	auInformer := apisixInformersFactory.Apisix().V2beta3().ApisixUpstreams().Informer()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	go podInformer.Run(stopCh)
+	go epInformer.Run(stopCh)
+	go auInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced, podInformer.HasSynced, epInformer.HasSynced, auInformer.HasSynced)
+
+	podCache := types.NewPodCache()
+	assert.Nil(t, podCache.Add(pod1))
+	assert.Nil(t, podCache.Add(pod2))
+
+	tr := &translator{&TranslatorOptions{
+		ServiceLister:        informersFactory.Core().V1().Services().Lister(),
+		EndpointLister:       epLister,
+		PodLister:            informersFactory.Core().V1().Pods().Lister(),
+		PodCache:             podCache,
+		ApisixUpstreamLister: apisixInformersFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+	}}
+
+	ar := &configv2.ApisixRoute{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ar",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRouteSpec{
+			HTTP: []configv2.ApisixRouteHTTP{
+				{
+					Name: "rule1",
+					Match: configv2.ApisixRouteHTTPMatch{
+						Paths: []string{"/*"},
+					},
+					Backends: []configv2.ApisixRouteHTTPBackend{
+						{
+							ServiceName: "svc",
+							ServicePort: intstr.FromInt(80),
+						},
+					},
+				},
+			},
+		},
+	}
+	tctx, err := tr.TranslateRouteV2(ar)
+	assert.Nil(t, err)
+	assert.Len(t, tctx.Routes, 1)
+	assert.Len(t, tctx.Upstreams, 3)
+
+	assert.Equal(t, "test_svc_v1_80", tctx.Upstreams[0].Name)
+	assert.Len(t, tctx.Upstreams[0].Nodes, 1)
+	assert.Equal(t, "192.168.1.1", tctx.Upstreams[0].Nodes[0].Host)
+	assert.Equal(t, "test_svc_v2_80", tctx.Upstreams[1].Name)
+	assert.Len(t, tctx.Upstreams[1].Nodes, 1)
+	assert.Equal(t, "192.168.1.2", tctx.Upstreams[1].Nodes[0].Host)
+	assert.Equal(t, "test_svc_80", tctx.Upstreams[2].Name)
+	assert.Len(t, tctx.Upstreams[2].Nodes, 2)
+
+	route := tctx.Routes[0]
+	assert.Equal(t, tctx.Upstreams[2].ID, route.UpstreamId)
+	cfg, ok := route.Plugins["traffic-split"].(*apisixv1.TrafficSplitConfig)
+	assert.True(t, ok)
+	assert.Len(t, cfg.Rules, 1)
+	assert.Equal(t, []apisixv1.TrafficSplitConfigRuleWeightedUpstream{
+		{UpstreamID: tctx.Upstreams[0].ID, Weight: 90},
+		{UpstreamID: tctx.Upstreams[1].ID, Weight: 10},
+		{Weight: 0},
+	}, cfg.Rules[0].WeightedUpstreams)
+}
+
+func TestTranslateIPRestrictionPlugin(t *testing.T) {
+	tr := &translator{}
+	cfg, err := tr.translateIPRestrictionPlugin(&configv2.ApisixRouteHTTPIPRestriction{
+		Allowlist: []string{"10.0.0.0/8", "192.168.1.1"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.IPRestrictConfig{Allowlist: []string{"10.0.0.0/8", "192.168.1.1"}}, cfg)
+
+	cfg, err = tr.translateIPRestrictionPlugin(&configv2.ApisixRouteHTTPIPRestriction{
+		Denylist: []string{"127.0.0.1/32"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.IPRestrictConfig{Blocklist: []string{"127.0.0.1/32"}}, cfg)
+
+	_, err = tr.translateIPRestrictionPlugin(&configv2.ApisixRouteHTTPIPRestriction{
+		Allowlist: []string{"10.0.0.0/8"},
+		Denylist:  []string{"10.1.0.0/16"},
+	})
+	assert.Equal(t, "ipRestriction: allowlist and denylist cannot be used together", err.Error())
+
+	_, err = tr.translateIPRestrictionPlugin(&configv2.ApisixRouteHTTPIPRestriction{})
+	assert.Equal(t, "ipRestriction: either allowlist or denylist is required", err.Error())
+
+	_, err = tr.translateIPRestrictionPlugin(&configv2.ApisixRouteHTTPIPRestriction{
+		Denylist: []string{"10.0.0.0/33"},
+	})
+	assert.Equal(t, "ipRestriction.denylist: address is neither IP or CIDR", err.Error())
+}
+
+func TestTranslateLimitCountPlugin(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	secretInformer := informersFactory.Core().V1().Secrets().Informer()
+	secretLister := informersFactory.Core().V1().Secrets().Lister()
+	apisixClient := apisixfake.NewSimpleClientset()
+	apisixInformersFactory := apisixinformers.NewSharedInformerFactory(apisixClient, 0)
+	policyInformer := apisixInformersFactory.Apisix().V2().ApisixRateLimitPolicies().Informer()
+	policyLister := apisixInformersFactory.Apisix().V2().ApisixRateLimitPolicies().Lister()
+
+	tr := &translator{
+		&TranslatorOptions{
+			SecretLister:                secretLister,
+			ApisixRateLimitPolicyLister: policyLister,
+		},
+	}
+
+	sec := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis-password",
+			Namespace: "test",
+		},
+		Data: map[string][]byte{
+			"password": []byte("foo"),
+		},
+	}
+	redis := &configv2.ApisixRateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRateLimitPolicySpec{
+			Redis: &configv2.ApisixRateLimitPolicyRedis{
+				Host:              "redis.test.svc",
+				Port:              6380,
+				PasswordSecretRef: &corev1.LocalObjectReference{Name: "redis-password"},
+			},
+		},
+	}
+	redisCluster := &configv2.ApisixRateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "redis-cluster",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRateLimitPolicySpec{
+			Policy: "redis-cluster",
+			RedisCluster: &configv2.ApisixRateLimitPolicyRedisCluster{
+				Nodes: []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+				Name:  "shared",
+			},
+		},
+	}
+	badSecret := &configv2.ApisixRateLimitPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bad-secret",
+			Namespace: "test",
+		},
+		Spec: configv2.ApisixRateLimitPolicySpec{
+			Redis: &configv2.ApisixRateLimitPolicyRedis{
+				Host:              "redis.test.svc",
+				PasswordSecretRef: &corev1.LocalObjectReference{Name: "not-exist"},
+			},
+		},
+	}
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go secretInformer.Run(stopCh)
+	go policyInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, secretInformer.HasSynced, policyInformer.HasSynced)
+
+	_, err := client.CoreV1().Secrets("test").Create(context.Background(), sec, metav1.CreateOptions{})
+	assert.Nil(t, err)
+	for _, p := range []*configv2.ApisixRateLimitPolicy{redis, redisCluster, badSecret} {
+		_, err = apisixClient.ApisixV2().ApisixRateLimitPolicies("test").Create(context.Background(), p, metav1.CreateOptions{})
+		assert.Nil(t, err)
+	}
+	assert.Eventually(t, func() bool {
+		return len(secretInformer.GetStore().List()) == 1 && len(policyInformer.GetStore().List()) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cfg, err := tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		Count:      10,
+		TimeWindow: 60,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.LimitCountConfig{Count: 10, TimeWindow: 60}, cfg)
+
+	cfg, err = tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		Count:        10,
+		TimeWindow:   60,
+		Key:          "http_x_user",
+		RejectedCode: 429,
+		PolicyRef:    "redis",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.LimitCountConfig{
+		Count:         10,
+		TimeWindow:    60,
+		Key:           "http_x_user",
+		RejectedCode:  429,
+		Policy:        "redis",
+		RedisHost:     "redis.test.svc",
+		RedisPort:     6380,
+		RedisPassword: "foo",
+	}, cfg)
+
+	cfg, err = tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		Count:      10,
+		TimeWindow: 60,
+		PolicyRef:  "redis-cluster",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.LimitCountConfig{
+		Count:            10,
+		TimeWindow:       60,
+		Policy:           "redis-cluster",
+		RedisClusterName: "shared",
+		RedisClusterNode: []string{"10.0.0.1:6379", "10.0.0.2:6379"},
+	}, cfg)
+
+	_, err = tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		TimeWindow: 60,
+	})
+	assert.Equal(t, "limitCount.count: should be greater than 0", err.Error())
+
+	_, err = tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		Count:      10,
+		TimeWindow: 60,
+		PolicyRef:  "not-exist",
+	})
+	assert.Equal(t, "limitCount.policyRef: apisixratelimitpolicy.apisix.apache.org \"not-exist\" not found", err.Error())
+
+	_, err = tr.translateLimitCountPlugin("test", &configv2.ApisixRouteHTTPLimitCount{
+		Count:      10,
+		TimeWindow: 60,
+		PolicyRef:  "bad-secret",
+	})
+	assert.Equal(t, "limitCount.policyRef: secret \"not-exist\" not found", err.Error())
+}
+
+func TestTranslateBodyTransformerPlugin(t *testing.T) {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "templates",
+			Namespace: "test",
+		},
+		Data: map[string]string{
+			"request.tmpl":  `{"name":"{{name}}"}`,
+			"response.tmpl": `{"data":{*_escape_json(_body)*}}`,
+		},
+	}
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	assert.Nil(t, indexer.Add(cm))
+	tr := &translator{
+		&TranslatorOptions{
+			ConfigMapLister: listerscorev1.NewConfigMapLister(indexer),
+		},
+	}
+
+	cfg, err := tr.translateBodyTransformerPlugin("test", &configv2.ApisixRouteHTTPBodyTransformer{
+		ConfigMapName: "templates",
+		Request: &configv2.ApisixRouteHTTPBodyTransformerTemplate{
+			TemplateKey: "request.tmpl",
+		},
+		Response: &configv2.ApisixRouteHTTPBodyTransformerTemplate{
+			TemplateKey: "response.tmpl",
+			InputFormat: "json",
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.BodyTransformerConfig{
+		Request: &apisixv1.BodyTransformerTemplate{
+			Template: `{"name":"{{name}}"}`,
+		},
+		Response: &apisixv1.BodyTransformerTemplate{
+			InputFormat: "json",
+			Template:    `{"data":{*_escape_json(_body)*}}`,
+		},
+	}, cfg)
+
+	_, err = tr.translateBodyTransformerPlugin("test", &configv2.ApisixRouteHTTPBodyTransformer{
+		ConfigMapName: "templates",
+	})
+	assert.Equal(t, "bodyTransformer: at least one of request and response should be specified", err.Error())
+
+	_, err = tr.translateBodyTransformerPlugin("test", &configv2.ApisixRouteHTTPBodyTransformer{
+		ConfigMapName: "not-exist",
+		Response: &configv2.ApisixRouteHTTPBodyTransformerTemplate{
+			TemplateKey: "response.tmpl",
+		},
+	})
+	assert.Contains(t, err.Error(), "bodyTransformer.configMapName: ")
+
+	_, err = tr.translateBodyTransformerPlugin("test", &configv2.ApisixRouteHTTPBodyTransformer{
+		ConfigMapName: "templates",
+		Response: &configv2.ApisixRouteHTTPBodyTransformerTemplate{
+			TemplateKey: "not-exist.tmpl",
+		},
+	})
+	assert.Equal(t, "bodyTransformer.response.templateKey: template not-exist.tmpl not found in ConfigMap templates", err.Error())
+
+	_, err = tr.translateBodyTransformerPlugin("test", &configv2.ApisixRouteHTTPBodyTransformer{
+		ConfigMapName: "templates",
+		Request: &configv2.ApisixRouteHTTPBodyTransformerTemplate{
+			TemplateKey: "request.tmpl",
+			InputFormat: "yaml",
+		},
+	})
+	assert.Equal(t, "bodyTransformer.request.inputFormat: unknown input format yaml", err.Error())
+}
+
+func TestTranslateForwardAuthPlugin(t *testing.T) {
+	tr := &translator{}
+	cfg, err := tr.translateForwardAuthPlugin(&configv2.ApisixRouteHTTPForwardAuth{
+		URI:             "http://auth.default.svc/verify",
+		RequestHeaders:  []string{"Authorization"},
+		UpstreamHeaders: []string{"X-User-ID"},
+		ClientHeaders:   []string{"Location"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.ForwardAuthConfig{
+		URI:             "http://auth.default.svc/verify",
+		SSLVerify:       true,
+		RequestHeaders:  []string{"Authorization"},
+		UpstreamHeaders: []string{"X-User-ID"},
+		ClientHeaders:   []string{"Location"},
+	}, cfg)
+
+	sslVerify := false
+	cfg, err = tr.translateForwardAuthPlugin(&configv2.ApisixRouteHTTPForwardAuth{
+		URI:       "https://auth.example.com",
+		SSLVerify: &sslVerify,
+	})
+	assert.Nil(t, err)
+	assert.False(t, cfg.SSLVerify)
+
+	_, err = tr.translateForwardAuthPlugin(&configv2.ApisixRouteHTTPForwardAuth{})
+	assert.Equal(t, "forwardAuth.uri: empty uri", err.Error())
+
+	_, err = tr.translateForwardAuthPlugin(&configv2.ApisixRouteHTTPForwardAuth{URI: "/verify"})
+	assert.Equal(t, "forwardAuth.uri: should be an http or https URL", err.Error())
+
+	_, err = tr.translateForwardAuthPlugin(&configv2.ApisixRouteHTTPForwardAuth{URI: "grpc://auth:50051"})
+	assert.Equal(t, "forwardAuth.uri: should be an http or https URL", err.Error())
+}
+
+func TestTranslateCorsPlugin(t *testing.T) {
+	tr := &translator{}
+	maxAge := 5
+	cfg, err := tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.CorsConfig{
+		AllowOrigins: "*",
+		AllowMethods: "*",
+		AllowHeaders: "*",
+		MaxAge:       &maxAge,
+	}, cfg)
+
+	maxAge = 3600
+	cfg, err = tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{
+		AllowOrigins:     []string{"https://a.example.com", "https://b.example.com"},
+		AllowHeaders:     []string{"X-Request-ID"},
+		ExposeHeaders:    []string{"X-Trace-ID"},
+		MaxAge:           &maxAge,
+		AllowCredentials: true,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.CorsConfig{
+		AllowOrigins:    "https://a.example.com,https://b.example.com",
+		AllowMethods:    _corsCredentialMethods,
+		AllowHeaders:    "X-Request-ID",
+		ExposeHeaders:   "X-Trace-ID",
+		MaxAge:          &maxAge,
+		AllowCredential: true,
+	}, cfg)
+
+	_, err = tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{AllowCredentials: true})
+	assert.Equal(t, "cors.allowOrigins: required when allowCredentials is true", err.Error())
+
+	_, err = tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{
+		AllowOrigins:     []string{"*"},
+		AllowCredentials: true,
+	})
+	assert.Equal(t, "cors.allowOrigins: wildcard cannot be used when allowCredentials is true", err.Error())
+
+	_, err = tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{
+		AllowOrigins:     []string{"https://a.example.com"},
+		AllowHeaders:     []string{"*"},
+		AllowCredentials: true,
+	})
+	assert.Equal(t, "cors.allowHeaders: wildcard cannot be used when allowCredentials is true", err.Error())
+
+	maxAge = -2
+	_, err = tr.translateCorsPlugin(&configv2.ApisixRouteHTTPCors{MaxAge: &maxAge})
+	assert.Equal(t, "cors.maxAge: should not be less than -1", err.Error())
+}
+
+func TestTranslateResponseRewritePlugin(t *testing.T) {
+	tr := &translator{}
+	cfg, err := tr.translateResponseRewritePlugin(&configv2.ApisixRouteHTTPResponseRewrite{
+		StatusCode: 503,
+		Headers: &configv2.ApisixRouteHTTPResponseRewriteHeaders{
+			Set:    map[string]string{"X-Server": "apisix"},
+			Remove: []string{"X-Powered-By"},
+		},
+		BodyBase64: "SGVsbG8=",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.ResponseRewriteConfig{
+		StatusCode: 503,
+		Body:       "SGVsbG8=",
+		BodyBase64: true,
+		Headers: &apisixv1.ResponseRewriteHeaders{
+			Set:    map[string]string{"X-Server": "apisix"},
+			Remove: []string{"X-Powered-By"},
+		},
+	}, cfg)
+	// Encoded in the legacy form without the added headers.
+	data, err := json.Marshal(cfg)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"status_code":503,"body":"SGVsbG8=","body_base64":true,"headers":{"X-Server":"apisix","X-Powered-By":""}}`, string(data))
+
+	cfg, err = tr.translateResponseRewritePlugin(&configv2.ApisixRouteHTTPResponseRewrite{
+		Headers: &configv2.ApisixRouteHTTPResponseRewriteHeaders{
+			Add: map[string]string{"Set-Cookie": "a=b", "Cache-Control": "no-cache"},
+		},
+	})
+	assert.Nil(t, err)
+	data, err = json.Marshal(cfg)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"headers":{"add":["Cache-Control: no-cache","Set-Cookie: a=b"]}}`, string(data))
+
+	for _, c := range []struct {
+		cfg *configv2.ApisixRouteHTTPResponseRewrite
+		err error
+	}{
+		{
+			&configv2.ApisixRouteHTTPResponseRewrite{StatusCode: 600},
+			&translateError{field: "responseRewrite.statusCode", reason: "should be in [200, 598]"},
+		},
+		{
+			&configv2.ApisixRouteHTTPResponseRewrite{Body: "a", BodyBase64: "YQ=="},
+			&translateError{field: "responseRewrite.bodyBase64", reason: "can't be used together with body"},
+		},
+		{
+			&configv2.ApisixRouteHTTPResponseRewrite{BodyBase64: "!!"},
+			&translateError{field: "responseRewrite.bodyBase64", reason: "invalid base64 content"},
+		},
+		{
+			&configv2.ApisixRouteHTTPResponseRewrite{Headers: &configv2.ApisixRouteHTTPResponseRewriteHeaders{
+				Set: map[string]string{"X Server": "apisix"},
+			}},
+			&translateError{field: "responseRewrite.headers.set", reason: `invalid header name "X Server"`},
+		},
+		{
+			&configv2.ApisixRouteHTTPResponseRewrite{Headers: &configv2.ApisixRouteHTTPResponseRewriteHeaders{
+				Set:    map[string]string{"X-Server": "apisix"},
+				Remove: []string{"x-server"},
+			}},
+			&translateError{field: "responseRewrite.headers.remove", reason: "header x-server is also in responseRewrite.headers.set"},
+		},
+	} {
+		assert.Equal(t, c.err, ValidateResponseRewrite(c.cfg))
+	}
+}
+
+func TestTranslateRequestIDPlugin(t *testing.T) {
+	tr := &translator{}
+	cfg, err := tr.translateRequestIDPlugin(&configv2.ApisixRouteHTTPRequestID{})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.RequestIDConfig{
+		HeaderName:        "X-Request-Id",
+		IncludeInResponse: true,
+		Algorithm:         "uuid",
+	}, cfg)
+
+	includeInResponse := false
+	cfg, err = tr.translateRequestIDPlugin(&configv2.ApisixRouteHTTPRequestID{
+		HeaderName:        "X-Trace-Id",
+		Algorithm:         "snowflake",
+		IncludeInResponse: &includeInResponse,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, &apisixv1.RequestIDConfig{
+		HeaderName: "X-Trace-Id",
+		Algorithm:  "snowflake",
+	}, cfg)
+
+	_, err = tr.translateRequestIDPlugin(&configv2.ApisixRouteHTTPRequestID{Algorithm: "nanoid"})
+	assert.Equal(t, &translateError{field: "requestId.algorithm", reason: "unsupported algorithm nanoid"}, err)
+	_, err = tr.translateRequestIDPlugin(&configv2.ApisixRouteHTTPRequestID{HeaderName: "X Trace"})
+	assert.Equal(t, &translateError{field: "requestId.headerName", reason: `invalid header name "X Trace"`}, err)
+}
+
+func TestTranslateServerlessPlugins(t *testing.T) {
+	cfg := &configv2.ApisixRouteHTTPServerless{
+		Pre: &configv2.ApisixRouteHTTPServerlessFunctions{
+			Functions: []string{"return function() ngx.log(ngx.ERR, 'pre') end"},
+		},
+		Post: &configv2.ApisixRouteHTTPServerlessFunctions{
+			Phase:     "log",
+			Functions: []string{"return function() ngx.log(ngx.ERR, 'post') end"},
+		},
+	}
+
+	tr := &translator{TranslatorOptions: &TranslatorOptions{}}
+	_, err := tr.translateServerlessPlugins(cfg)
+	assert.Equal(t, &translateError{field: "serverless", reason: "inline Lua functions are not allowed by the controller"}, err)
+
+	tr.AllowServerlessFunctions = true
+	plugins, err := tr.translateServerlessPlugins(cfg)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*apisixv1.ServerlessConfig{
+		"serverless-pre-function": {
+			Phase:     "access",
+			Functions: []string{"return function() ngx.log(ngx.ERR, 'pre') end"},
+		},
+		"serverless-post-function": {
+			Phase:     "log",
+			Functions: []string{"return function() ngx.log(ngx.ERR, 'post') end"},
+		},
+	}, plugins)
+
+	cases := []struct {
+		cfg *configv2.ApisixRouteHTTPServerless
+		err error
+	}{
+		{
+			cfg: &configv2.ApisixRouteHTTPServerless{},
+			err: &translateError{field: "serverless", reason: "either pre or post should be set"},
+		},
+		{
+			cfg: &configv2.ApisixRouteHTTPServerless{
+				Pre: &configv2.ApisixRouteHTTPServerlessFunctions{Phase: "balancer", Functions: []string{"return function() end"}},
+			},
+			err: &translateError{field: "serverless.pre.phase", reason: "unsupported phase balancer"},
+		},
+		{
+			cfg: &configv2.ApisixRouteHTTPServerless{
+				Post: &configv2.ApisixRouteHTTPServerlessFunctions{},
+			},
+			err: &translateError{field: "serverless.post.functions", reason: "empty"},
+		},
+		{
+			cfg: &configv2.ApisixRouteHTTPServerless{
+				Post: &configv2.ApisixRouteHTTPServerlessFunctions{Functions: []string{" "}},
+			},
+			err: &translateError{field: "serverless.post.functions", reason: "empty function"},
+		},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.err, ValidateServerless(c.cfg))
+	}
+}
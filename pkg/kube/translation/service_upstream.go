@@ -0,0 +1,104 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package translation
+
+import (
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+
+	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
+)
+
+// ServiceUpstreamAnnotationsChanged checks whether the honored upstream
+// annotations are changed between the two versions of the Service.
+func ServiceUpstreamAnnotationsChanged(keys []string, prev, curr *corev1.Service) bool {
+	for _, key := range keys {
+		if prev.Annotations[key] != curr.Annotations[key] {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *translator) TranslateServiceUpstreamConfig(svc *corev1.Service) (*apisixv1.Upstream, error) {
+	var (
+		cfg     configv2beta3.ApisixUpstreamConfig
+		timeout configv2beta3.UpstreamTimeout
+		err     error
+	)
+	for _, key := range t.ServiceUpstreamAnnotations {
+		value, ok := svc.Annotations[key]
+		if !ok {
+			continue
+		}
+		switch key {
+		case annotations.AnnotationsUpstreamScheme:
+			cfg.Scheme = value
+		case annotations.AnnotationsUpstreamLoadBalancer:
+			cfg.LoadBalancer = &configv2beta3.LoadBalancer{Type: value}
+		case annotations.AnnotationsUpstreamRetries:
+			var retries int
+			retries, err = strconv.Atoi(value)
+			cfg.Retries = &retries
+		case annotations.AnnotationsUpstreamConnectTimeout:
+			timeout.Connect.Duration, err = time.ParseDuration(value)
+			cfg.Timeout = &timeout
+		case annotations.AnnotationsUpstreamReadTimeout:
+			timeout.Read.Duration, err = time.ParseDuration(value)
+			cfg.Timeout = &timeout
+		case annotations.AnnotationsUpstreamSendTimeout:
+			timeout.Send.Duration, err = time.ParseDuration(value)
+			cfg.Timeout = &timeout
+		}
+		if err != nil {
+			return nil, &translateError{
+				field:  "annotations[" + key + "]",
+				reason: err.Error(),
+			}
+		}
+	}
+	ups, err := t.TranslateUpstreamConfig(&cfg)
+	if err != nil {
+		return nil, &translateError{
+			field:  "service upstream annotations",
+			reason: err.Error(),
+		}
+	}
+	return ups, nil
+}
+
+// translateServiceUpstream returns the upstream of the Service without
+// ApisixUpstream, it's configured by the Service annotations if enabled.
+func (t *translator) translateServiceUpstream(namespace, name string) (*apisixv1.Upstream, error) {
+	if len(t.ServiceUpstreamAnnotations) == 0 {
+		return apisixv1.NewDefaultUpstream(), nil
+	}
+	svc, err := t.ServiceLister.Services(namespace).Get(name)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return apisixv1.NewDefaultUpstream(), nil
+		}
+		return nil, &translateError{
+			field:  "service",
+			reason: err.Error(),
+		}
+	}
+	return t.TranslateServiceUpstreamConfig(svc)
+}
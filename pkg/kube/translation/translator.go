@@ -16,16 +16,21 @@ package translation
 
 import (
 	"fmt"
+	"net"
 
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
 	listerscorev1 "k8s.io/client-go/listers/core/v1"
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	listersv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2"
 	listersv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/listers/config/v2beta3"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
 	"github.com/apache/apisix-ingress-controller/pkg/types"
@@ -48,12 +53,26 @@ func (te *translateError) Error() string {
 // Translator translates Apisix* CRD resources to the description in APISIX.
 type Translator interface {
 	// TranslateUpstreamNodes translate Endpoints resources to APISIX Upstream nodes
-	// according to the give port. Extra labels can be passed to filter the ultimate
-	// upstream nodes.
-	TranslateUpstreamNodes(kube.Endpoint, int32, types.Labels) (apisixv1.UpstreamNodes, error)
+	// according to the give port. A pod label selector can be passed to filter the
+	// ultimate upstream nodes.
+	TranslateUpstreamNodes(kube.Endpoint, int32, labels.Selector) (apisixv1.UpstreamNodes, error)
+	// TranslateServiceRefsNodes merges the nodes of the Services referenced
+	// by an ApisixUpstream (in the given namespace) according to the given
+	// port, a pod label selector can be passed to filter the nodes.
+	TranslateServiceRefsNodes(string, []configv2beta3.ApisixUpstreamServiceRef, int32, labels.Selector) (apisixv1.UpstreamNodes, error)
+	// TranslateExternalNodes translates the static nodes of an ApisixUpstream
+	// according to the given port, which is used by the nodes without port.
+	TranslateExternalNodes([]configv2beta3.ApisixUpstreamExternalNode, int32) (apisixv1.UpstreamNodes, error)
 	// TranslateUpstreamConfig translates ApisixUpstreamConfig (part of ApisixUpstream)
 	// to APISIX Upstream, it doesn't fill the the Upstream metadata and nodes.
 	TranslateUpstreamConfig(*configv2beta3.ApisixUpstreamConfig) (*apisixv1.Upstream, error)
+	// TranslateUpstreamDiscovery makes the Upstream resolve the nodes with the
+	// service discovery of APISIX, the Upstream nodes are cleared.
+	TranslateUpstreamDiscovery(*configv2beta3.ApisixUpstreamDiscovery, *apisixv1.Upstream) error
+	// TranslateServiceUpstreamConfig translates the upstream annotations of the
+	// Service to APISIX Upstream, only the ones enabled in ServiceUpstreamAnnotations
+	// are honored. It doesn't fill the Upstream metadata and nodes.
+	TranslateServiceUpstreamConfig(*corev1.Service) (*apisixv1.Upstream, error)
 	// TranslateUpstream composes an upstream according to the
 	// given namespace, name (searching Service/Endpoints) and port (filtering Endpoints).
 	// The returned Upstream doesn't have metadata info.
@@ -95,6 +114,9 @@ type Translator interface {
 	// TranslateClusterConfigV2 translates the configv2.ApisixClusterConfig object into the APISIX
 	// Global Rule resource.
 	TranslateClusterConfigV2(*configv2.ApisixClusterConfig) (*apisixv1.GlobalRule, error)
+	// TranslatePluginMetadataV2 translates the plugin metadata of the configv2.ApisixClusterConfig
+	// object into the APISIX PluginMetadata resources.
+	TranslatePluginMetadataV2(*configv2.ApisixClusterConfig) ([]*apisixv1.PluginMetadata, error)
 	// TranslateApisixConsumer translates the configv2beta3.APisixConsumer object into the APISIX Consumer
 	// resource.
 	TranslateApisixConsumerV2beta3(*configv2beta3.ApisixConsumer) (*apisixv1.Consumer, error)
@@ -127,7 +149,39 @@ type TranslatorOptions struct {
 	ServiceLister        listerscorev1.ServiceLister
 	ApisixUpstreamLister listersv2beta3.ApisixUpstreamLister
 	SecretLister         listerscorev1.SecretLister
-	UseEndpointSlices    bool
+	ConfigMapLister      listerscorev1.ConfigMapLister
+	// ApisixRateLimitPolicyLister is used to resolve the policyRef of
+	// limitCount, it's nil if ApisixRoute v2 is not watched.
+	ApisixRateLimitPolicyLister listersv2.ApisixRateLimitPolicyLister
+	// ApisixPluginConfigLister is used to merge the route plugins with the
+	// ones of the referenced ApisixPluginConfig, nil means no merge.
+	ApisixPluginConfigLister  kube.ApisixPluginConfigLister
+	ApisixPluginConfigVersion string
+	// ApisixConsumerLister is used to check the ApisixConsumers referenced
+	// by the auth-consumers annotation of Ingress.
+	ApisixConsumerLister  kube.ApisixConsumerLister
+	ApisixConsumerVersion string
+	UseEndpointSlices     bool
+	// AllowCrossNamespacePluginConfig allows routes to reference
+	// ApisixPluginConfigs in other namespaces.
+	AllowCrossNamespacePluginConfig bool
+	// AllowServerlessFunctions allows the serverless field of ApisixRoute
+	// and the serverless plugins.
+	AllowServerlessFunctions bool
+	// RouteLabelKeys are the keys of the ApisixRoute labels copied to
+	// the APISIX routes.
+	RouteLabelKeys []string
+	// RouteLabelPrefix selects the ApisixRoute labels copied to the
+	// APISIX routes by the key prefix, empty means none.
+	RouteLabelPrefix string
+	// ServiceUpstreamAnnotations are the Service annotations which
+	// configure the upstreams of the Services without ApisixUpstream.
+	ServiceUpstreamAnnotations []string
+	// ClusterDomain is the DNS domain of the cluster, it's used to compose
+	// the DNS names of the Services.
+	ClusterDomain string
+	// DiscoveryTypes are the service discovery types enabled in APISIX.
+	DiscoveryTypes []string
 }
 
 type translator struct {
@@ -149,19 +203,32 @@ func (t *translator) TranslateUpstreamConfig(au *configv2beta3.ApisixUpstreamCon
 	if err := t.translateUpstreamLoadBalancer(au.LoadBalancer, ups); err != nil {
 		return nil, err
 	}
+	if err := t.translateUpstreamPassHost(au.PassHost, au.UpstreamHost, ups); err != nil {
+		return nil, err
+	}
 	if err := t.translateUpstreamHealthCheck(au.HealthCheck, ups); err != nil {
 		return nil, err
 	}
 	if err := t.translateUpstreamRetriesAndTimeout(au.Retries, au.Timeout, ups); err != nil {
 		return nil, err
 	}
+	if err := t.translateUpstreamRetryTimeout(au.RetryTimeout, ups); err != nil {
+		return nil, err
+	}
+	if err := t.translateUpstreamKeepalivePool(au.KeepalivePool, ups); err != nil {
+		return nil, err
+	}
 	if err := t.translateClientTLS(au.TLSSecret, ups); err != nil {
 		return nil, err
 	}
+	if err := t.translateUpstreamTLS(au.TLS, ups); err != nil {
+		return nil, err
+	}
 	return ups, nil
 }
 
-func (t *translator) TranslateUpstream(namespace, name, subset string, port int32) (*apisixv1.Upstream, error) {
+// getEndpoint fetches the Endpoints or EndpointSlices of the Service.
+func (t *translator) getEndpoint(namespace, name string) (kube.Endpoint, error) {
 	var (
 		endpoint kube.Endpoint
 		err      error
@@ -177,6 +244,19 @@ func (t *translator) TranslateUpstream(namespace, name, subset string, port int3
 			reason: err.Error(),
 		}
 	}
+	return endpoint, nil
+}
+
+func (t *translator) TranslateUpstream(namespace, name, subset string, port int32) (*apisixv1.Upstream, error) {
+	ups, err := t.translateUpstreamByService(namespace, name, subset, port)
+	if err != nil {
+		return nil, err
+	}
+	t.setUpstreamSourceLabels(ups, namespace, name)
+	return ups, nil
+}
+
+func (t *translator) translateUpstreamByService(namespace, name, subset string, port int32) (*apisixv1.Upstream, error) {
 	au, err := t.ApisixUpstreamLister.ApisixUpstreams(namespace).Get(name)
 	ups := apisixv1.NewDefaultUpstream()
 	if err != nil {
@@ -194,21 +274,45 @@ func (t *translator) TranslateUpstream(namespace, name, subset string, port int3
 			}
 		}
 	}
-	var labels types.Labels
+	var selector labels.Selector
 	if subset != "" {
-		for _, ss := range au.Spec.Subsets {
-			if ss.Name == subset {
-				labels = ss.Labels
+		for i := range au.Spec.Subsets {
+			if au.Spec.Subsets[i].Name == subset {
+				selector, err = SubsetSelector(&au.Spec.Subsets[i])
+				if err != nil {
+					return nil, err
+				}
 				break
 			}
 		}
 	}
+	if au != nil && au.Spec != nil && au.Spec.Discovery != nil {
+		return t.translateDiscoveryUpstream(au, subset, port)
+	}
+	if au != nil && au.Spec != nil && len(au.Spec.ExternalNodes) > 0 {
+		return t.translateExternalNodesUpstream(au, subset, port)
+	}
 	// Filter nodes by subset.
-	nodes, err := t.TranslateUpstreamNodes(endpoint, port, labels)
+	var nodes apisixv1.UpstreamNodes
+	if au != nil && au.Spec != nil && len(au.Spec.Services) > 0 {
+		nodes, err = t.TranslateServiceRefsNodes(namespace, au.Spec.Services, port, selector)
+	} else {
+		var endpoint kube.Endpoint
+		endpoint, err = t.getEndpoint(namespace, name)
+		if err != nil {
+			return nil, err
+		}
+		nodes, err = t.TranslateUpstreamNodes(endpoint, port, selector)
+	}
 	if err != nil {
 		return nil, err
 	}
 	if au == nil || au.Spec == nil {
+		// ApisixUpstream takes precedence over the Service annotations.
+		ups, err = t.translateServiceUpstream(namespace, name)
+		if err != nil {
+			return nil, err
+		}
 		ups.Nodes = nodes
 		return ups, nil
 	}
@@ -228,7 +332,136 @@ func (t *translator) TranslateUpstream(namespace, name, subset string, port int3
 	return ups, nil
 }
 
-func (t *translator) TranslateUpstreamNodes(endpoint kube.Endpoint, port int32, labels types.Labels) (apisixv1.UpstreamNodes, error) {
+// translateDiscoveryUpstream translates the ApisixUpstream with service
+// discovery, the endpoints of the Service are not used at all.
+func (t *translator) translateDiscoveryUpstream(au *configv2beta3.ApisixUpstream, subset string, port int32) (*apisixv1.Upstream, error) {
+	if subset != "" {
+		return nil, &translateError{
+			field:  "discovery",
+			reason: "can't be used together with subsets",
+		}
+	}
+	if len(au.Spec.Services) > 0 {
+		return nil, &translateError{
+			field:  "discovery",
+			reason: "can't be used together with services",
+		}
+	}
+	if len(au.Spec.ExternalNodes) > 0 {
+		return nil, &translateError{
+			field:  "discovery",
+			reason: "can't be used together with externalNodes",
+		}
+	}
+	upsCfg := &au.Spec.ApisixUpstreamConfig
+	for _, pls := range au.Spec.PortLevelSettings {
+		if pls.Port == port {
+			upsCfg = &pls.ApisixUpstreamConfig
+			break
+		}
+	}
+	ups, err := t.TranslateUpstreamConfig(upsCfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.TranslateUpstreamDiscovery(au.Spec.Discovery, ups); err != nil {
+		return nil, err
+	}
+	return ups, nil
+}
+
+// translateExternalNodesUpstream translates the ApisixUpstream with static
+// nodes, neither the Service nor its endpoints are used.
+func (t *translator) translateExternalNodesUpstream(au *configv2beta3.ApisixUpstream, subset string, port int32) (*apisixv1.Upstream, error) {
+	if subset != "" {
+		return nil, &translateError{
+			field:  "externalNodes",
+			reason: "can't be used together with subsets",
+		}
+	}
+	if len(au.Spec.Services) > 0 {
+		return nil, &translateError{
+			field:  "externalNodes",
+			reason: "can't be used together with services",
+		}
+	}
+	upsCfg := &au.Spec.ApisixUpstreamConfig
+	for _, pls := range au.Spec.PortLevelSettings {
+		if pls.Port == port {
+			upsCfg = &pls.ApisixUpstreamConfig
+			break
+		}
+	}
+	ups, err := t.TranslateUpstreamConfig(upsCfg)
+	if err != nil {
+		return nil, err
+	}
+	ups.Nodes, err = t.TranslateExternalNodes(au.Spec.ExternalNodes, port)
+	if err != nil {
+		return nil, err
+	}
+	return ups, nil
+}
+
+func (t *translator) TranslateExternalNodes(externalNodes []configv2beta3.ApisixUpstreamExternalNode, port int32) (apisixv1.UpstreamNodes, error) {
+	nodes := make(apisixv1.UpstreamNodes, 0, len(externalNodes))
+	for i, node := range externalNodes {
+		if net.ParseIP(node.Host) == nil && len(k8svalidation.IsDNS1123Subdomain(node.Host)) > 0 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("externalNodes[%d].host", i),
+				reason: fmt.Sprintf("%q is neither an IP address nor a domain name", node.Host),
+			}
+		}
+		if node.Port < 0 || node.Port > 65535 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("externalNodes[%d].port", i),
+				reason: "should be in the range [1, 65535]",
+			}
+		}
+		if node.Weight != nil && *node.Weight < 0 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("externalNodes[%d].weight", i),
+				reason: "should not be negative",
+			}
+		}
+		nodePort := node.Port
+		if nodePort == 0 {
+			nodePort = port
+		}
+		weight := _defaultWeight
+		if node.Weight != nil {
+			weight = *node.Weight
+		}
+		nodes = append(nodes, apisixv1.UpstreamNode{
+			Host:   node.Host,
+			Port:   int(nodePort),
+			Weight: weight,
+		})
+	}
+	return nodes, nil
+}
+
+func (t *translator) TranslateUpstreamDiscovery(discovery *configv2beta3.ApisixUpstreamDiscovery, ups *apisixv1.Upstream) error {
+	if !containsString(t.DiscoveryTypes, discovery.Type) {
+		return &translateError{
+			field:  "discovery.type",
+			reason: fmt.Sprintf("%s is not enabled in APISIX", discovery.Type),
+		}
+	}
+	if discovery.ServiceName == "" {
+		return &translateError{
+			field:  "discovery.serviceName",
+			reason: "empty",
+		}
+	}
+	ups.Nodes = nil
+	ups.DiscoveryType = discovery.Type
+	ups.ServiceName = discovery.ServiceName
+	ups.DiscoveryArgs = discovery.Args
+	return nil
+}
+
+func (t *translator) TranslateUpstreamNodes(endpoint kube.Endpoint, port int32, selector labels.Selector) (apisixv1.UpstreamNodes, error) {
 	namespace, err := endpoint.Namespace()
 	if err != nil {
 		log.Errorw("failed to get endpoint namespace",
@@ -263,6 +496,9 @@ func (t *translator) TranslateUpstreamNodes(endpoint kube.Endpoint, port int32,
 	// not a nil slice.
 	nodes := make(apisixv1.UpstreamNodes, 0)
 	for _, hostport := range endpoint.Endpoints(svcPort) {
+		if selector != nil && !t.matchPodLabels(namespace, hostport, selector) {
+			continue
+		}
 		nodes = append(nodes, apisixv1.UpstreamNode{
 			Host: hostport.Host,
 			Port: hostport.Port,
@@ -270,11 +506,50 @@ func (t *translator) TranslateUpstreamNodes(endpoint kube.Endpoint, port int32,
 			Weight: _defaultWeight,
 		})
 	}
-	if labels != nil {
-		nodes = t.filterNodesByLabels(nodes, labels, namespace)
-		return nodes, nil
+	// Overlapping endpoints might have the same address, and the order is
+	// not stable across the syncs.
+	return nodes.Normalize(), nil
+}
+
+func (t *translator) TranslateServiceRefsNodes(namespace string, refs []configv2beta3.ApisixUpstreamServiceRef, port int32, selector labels.Selector) (apisixv1.UpstreamNodes, error) {
+	nodes := make(apisixv1.UpstreamNodes, 0)
+	for i, ref := range refs {
+		if ref.Name == "" {
+			return nil, &translateError{
+				field:  fmt.Sprintf("services[%d].name", i),
+				reason: "empty Service name",
+			}
+		}
+		if ref.Weight != nil && *ref.Weight < 0 {
+			return nil, &translateError{
+				field:  fmt.Sprintf("services[%d].weight", i),
+				reason: "should not be negative",
+			}
+		}
+		ns := ref.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		refPort := ref.Port
+		if refPort == 0 {
+			refPort = port
+		}
+		endpoint, err := t.getEndpoint(ns, ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		refNodes, err := t.TranslateUpstreamNodes(endpoint, refPort, selector)
+		if err != nil {
+			return nil, err
+		}
+		for _, node := range refNodes {
+			if ref.Weight != nil {
+				node.Weight = *ref.Weight
+			}
+			nodes = append(nodes, node)
+		}
 	}
-	return nodes, nil
+	return nodes.Normalize(), nil
 }
 
 func (t *translator) TranslateIngress(ing kube.Ingress, args ...bool) (*TranslateContext, error) {
@@ -282,14 +557,27 @@ func (t *translator) TranslateIngress(ing kube.Ingress, args ...bool) (*Translat
 	if len(args) != 0 {
 		skipVerify = args[0]
 	}
+	var (
+		ctx *TranslateContext
+		obj metav1.Object
+		err error
+	)
 	switch ing.GroupVersion() {
 	case kube.IngressV1:
-		return t.translateIngressV1(ing.V1(), skipVerify)
+		obj = ing.V1()
+		ctx, err = t.translateIngressV1(ing.V1(), skipVerify)
 	case kube.IngressV1beta1:
-		return t.translateIngressV1beta1(ing.V1beta1(), skipVerify)
+		obj = ing.V1beta1()
+		ctx, err = t.translateIngressV1beta1(ing.V1beta1(), skipVerify)
 	case kube.IngressExtensionsV1beta1:
-		return t.translateIngressExtensionsV1beta1(ing.ExtensionsV1beta1(), skipVerify)
+		obj = ing.ExtensionsV1beta1()
+		ctx, err = t.translateIngressExtensionsV1beta1(ing.ExtensionsV1beta1(), skipVerify)
 	default:
 		return nil, fmt.Errorf("translator: source group version not supported: %s", ing.GroupVersion())
 	}
+	if err != nil {
+		return nil, err
+	}
+	SetSourceLabels(ctx, "Ingress", obj)
+	return ctx, nil
 }
@@ -16,6 +16,7 @@ package translation
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -28,7 +29,12 @@ import (
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/apache/apisix-ingress-controller/pkg/kube"
+	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	fakeapisix "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/clientset/versioned/fake"
+	apisixinformers "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/client/informers/externalversions"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
+	"github.com/apache/apisix-ingress-controller/pkg/types"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
@@ -98,6 +104,46 @@ func TestTranslateUpstreamConfig(t *testing.T) {
 	})
 }
 
+func TestTranslateServiceUpstreamConfig(t *testing.T) {
+	tr := &translator{
+		TranslatorOptions: &TranslatorOptions{
+			ServiceUpstreamAnnotations: []string{
+				annotations.AnnotationsUpstreamScheme,
+				annotations.AnnotationsUpstreamRetries,
+				annotations.AnnotationsUpstreamConnectTimeout,
+			},
+		},
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+			Annotations: map[string]string{
+				annotations.AnnotationsUpstreamScheme:         apisixv1.SchemeHTTPS,
+				annotations.AnnotationsUpstreamRetries:        "3",
+				annotations.AnnotationsUpstreamConnectTimeout: "5s",
+				// Not honored.
+				annotations.AnnotationsUpstreamLoadBalancer: apisixv1.LbLeastConn,
+			},
+		},
+	}
+	ups, err := tr.TranslateServiceUpstreamConfig(svc)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.SchemeHTTPS, ups.Scheme)
+	assert.Equal(t, apisixv1.LbRoundRobin, ups.Type)
+	assert.Equal(t, 3, *ups.Retries)
+	assert.Equal(t, 5, ups.Timeout.Connect)
+
+	svc.Annotations[annotations.AnnotationsUpstreamRetries] = "three"
+	_, err = tr.TranslateServiceUpstreamConfig(svc)
+	assert.Contains(t, err.Error(), "annotations[k8s.apisix.apache.org/upstream-retries]")
+
+	svc.Annotations[annotations.AnnotationsUpstreamRetries] = "3"
+	svc.Annotations[annotations.AnnotationsUpstreamScheme] = "dns"
+	_, err = tr.TranslateServiceUpstreamConfig(svc)
+	assert.Contains(t, err.Error(), "service upstream annotations")
+}
+
 func TestTranslateUpstreamNodes(t *testing.T) {
 	svc := &corev1.Service{
 		TypeMeta: metav1.TypeMeta{},
@@ -343,3 +389,421 @@ func TestTranslateUpstreamNodesWithEndpointSlices(t *testing.T) {
 		},
 	}, nodes)
 }
+
+func TestTranslateServiceRefsNodes(t *testing.T) {
+	newService := func(ns, name string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Spec: corev1.ServiceSpec{
+				Ports: []corev1.ServicePort{
+					{
+						Name: "http",
+						Port: 80,
+						TargetPort: intstr.IntOrString{
+							Type:   intstr.Int,
+							IntVal: 8080,
+						},
+					},
+				},
+			},
+		}
+	}
+	newEndpoints := func(ns, name string, ips ...string) *corev1.Endpoints {
+		var addrs []corev1.EndpointAddress
+		for _, ip := range ips {
+			addrs = append(addrs, corev1.EndpointAddress{IP: ip})
+		}
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: ns,
+			},
+			Subsets: []corev1.EndpointSubset{
+				{
+					Ports:     []corev1.EndpointPort{{Name: "http", Port: 8080}},
+					Addresses: addrs,
+				},
+			},
+		}
+	}
+
+	client := fake.NewSimpleClientset(
+		newService("active", "svc"),
+		newEndpoints("active", "svc", "10.0.0.1", "10.0.0.2"),
+		newService("passive", "svc"),
+		newEndpoints("passive", "svc", "10.0.1.1"),
+	)
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+	svcLister := informersFactory.Core().V1().Services().Lister()
+	epLister, epInformer := kube.NewEndpointListerAndInformer(informersFactory, false)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	go epInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced, epInformer.HasSynced)
+
+	tr := &translator{&TranslatorOptions{
+		ServiceLister:  svcLister,
+		EndpointLister: epLister,
+	}}
+
+	passiveWeight := 0
+	nodes, err := tr.TranslateServiceRefsNodes("active", []configv2beta3.ApisixUpstreamServiceRef{
+		{Name: "svc"},
+		{Namespace: "passive", Name: "svc", Port: 80, Weight: &passiveWeight},
+	}, 80, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.0.1", Port: 8080, Weight: 100},
+		{Host: "10.0.0.2", Port: 8080, Weight: 100},
+		{Host: "10.0.1.1", Port: 8080, Weight: 0},
+	}, nodes)
+
+	_, err = tr.TranslateServiceRefsNodes("active", []configv2beta3.ApisixUpstreamServiceRef{
+		{Name: "svc"},
+		{Namespace: "passive"},
+	}, 80, nil)
+	assert.Equal(t, "services[1].name: empty Service name", err.Error())
+
+	_, err = tr.TranslateServiceRefsNodes("active", []configv2beta3.ApisixUpstreamServiceRef{
+		{Namespace: "passive", Name: "not-exist"},
+	}, 80, nil)
+	assert.Contains(t, err.Error(), "endpoints: ")
+}
+
+func TestTranslateUpstreamNodesWithSubsetSelector(t *testing.T) {
+	newPod := func(name, ip, version string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test",
+				Labels: map[string]string{
+					"app":     "svc",
+					"version": version,
+				},
+			},
+			Status: corev1.PodStatus{
+				PodIP: ip,
+			},
+		}
+	}
+	pods := []*corev1.Pod{
+		newPod("svc-v1", "10.0.0.1", "v1"),
+		newPod("svc-v2", "10.0.0.2", "v2"),
+		newPod("svc-v3", "10.0.0.3", "v3"),
+	}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Spec: corev1.ServiceSpec{
+			Ports: []corev1.ServicePort{
+				{
+					Name: "http",
+					Port: 80,
+				},
+			},
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}},
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.1", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "svc-v1"}},
+					{IP: "10.0.0.2", TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "svc-v2"}},
+					// Without the target reference, the pod is found by IP.
+					{IP: "10.0.0.3"},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(svc, pods[0], pods[1], pods[2])
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+	podInformer := informersFactory.Core().V1().Pods().Informer()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	go podInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced, podInformer.HasSynced)
+
+	podCache := types.NewPodCache()
+	for _, pod := range pods {
+		assert.Nil(t, podCache.Add(pod))
+	}
+	tr := &translator{&TranslatorOptions{
+		ServiceLister: informersFactory.Core().V1().Services().Lister(),
+		PodLister:     informersFactory.Core().V1().Pods().Lister(),
+		PodCache:      podCache,
+	}}
+
+	selector, err := SubsetSelector(&configv2beta3.ApisixUpstreamSubset{
+		Name: "new",
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      "version",
+					Operator: metav1.LabelSelectorOpIn,
+					Values:   []string{"v2", "v3"},
+				},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	nodes, err := tr.TranslateUpstreamNodes(kube.NewEndpoint(endpoints), 80, selector)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.0.2", Port: 8080, Weight: 100},
+		{Host: "10.0.0.3", Port: 8080, Weight: 100},
+	}, nodes)
+
+	// Labels and selector are ANDed.
+	selector, err = SubsetSelector(&configv2beta3.ApisixUpstreamSubset{
+		Name:   "v3",
+		Labels: map[string]string{"version": "v3"},
+		Selector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{"app": "svc"},
+		},
+	})
+	assert.Nil(t, err)
+	nodes, err = tr.TranslateUpstreamNodes(kube.NewEndpoint(endpoints), 80, selector)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.0.3", Port: 8080, Weight: 100},
+	}, nodes)
+
+	selector, err = SubsetSelector(&configv2beta3.ApisixUpstreamSubset{Name: "all"})
+	assert.Nil(t, err)
+	assert.Nil(t, selector)
+
+	_, err = SubsetSelector(&configv2beta3.ApisixUpstreamSubset{
+		Name: "bad",
+		Selector: &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "version", Operator: "Like"},
+			},
+		},
+	})
+	assert.Contains(t, err.Error(), "subsets.selector: ")
+}
+
+func TestTranslateHeadlessService(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+				{
+					Name:       "grpc",
+					Port:       50051,
+					TargetPort: intstr.FromString("grpc"),
+				},
+			},
+		},
+	}
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "svc",
+			Namespace: "test",
+		},
+		Subsets: []corev1.EndpointSubset{
+			{
+				Ports: []corev1.EndpointPort{{Name: "http", Port: 8080}},
+				Addresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.1"},
+					{IP: "10.0.0.2"},
+				},
+				NotReadyAddresses: []corev1.EndpointAddress{
+					{IP: "10.0.0.3"},
+				},
+			},
+		},
+	}
+
+	client := fake.NewSimpleClientset(svc)
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	svcInformer := informersFactory.Core().V1().Services().Informer()
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go svcInformer.Run(stopCh)
+	cache.WaitForCacheSync(stopCh, svcInformer.HasSynced)
+
+	tr := &translator{&TranslatorOptions{
+		ServiceLister: informersFactory.Core().V1().Services().Lister(),
+		ClusterDomain: "cluster.local",
+	}}
+
+	// One node per ready pod.
+	nodes, err := tr.TranslateUpstreamNodes(kube.NewEndpoint(endpoints), 80, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.0.1", Port: 8080, Weight: 100},
+		{Host: "10.0.0.2", Port: 8080, Weight: 100},
+	}, nodes)
+
+	_, _, err = tr.getServiceClusterIPAndPort(&configv2.ApisixRouteHTTPBackend{
+		ServiceName:        "svc",
+		ServicePort:        intstr.FromInt(80),
+		ResolveGranularity: "service",
+	}, "test")
+	assert.Equal(t, "conflict headless service and backend resolve granularity", err.Error())
+
+	ups := apisixv1.NewDefaultUpstream()
+	assert.Nil(t, tr.translateUpstreamDNSDiscovery(ups, "test", "svc", 80))
+	assert.Equal(t, apisixv1.DiscoveryTypeDNS, ups.DiscoveryType)
+	assert.Equal(t, "svc.test.svc.cluster.local:8080", ups.ServiceName)
+	data, err := json.Marshal(ups)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(data), `"nodes"`)
+	assert.Contains(t, string(data), `"service_name":"svc.test.svc.cluster.local:8080"`)
+
+	assert.Equal(t, &translateError{
+		field:  "resolveGranularity",
+		reason: "dns requires the numeric target port for headless service",
+	}, tr.translateUpstreamDNSDiscovery(apisixv1.NewDefaultUpstream(), "test", "svc", 50051))
+}
+
+func TestTranslateUpstreamDiscovery(t *testing.T) {
+	tr := &translator{&TranslatorOptions{
+		DiscoveryTypes: []string{"dns", "nacos"},
+	}}
+	au := &configv2beta3.ApisixUpstream{
+		Spec: &configv2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: configv2beta3.ApisixUpstreamConfig{
+				Scheme: apisixv1.SchemeHTTPS,
+			},
+			Discovery: &configv2beta3.ApisixUpstreamDiscovery{
+				Type:        "nacos",
+				ServiceName: "APISIX-NACOS",
+				Args: map[string]string{
+					"namespace_id": "test_ns",
+				},
+			},
+		},
+	}
+	ups, err := tr.translateDiscoveryUpstream(au, "", 80)
+	assert.Nil(t, err)
+	assert.Nil(t, ups.Nodes)
+	assert.Equal(t, apisixv1.SchemeHTTPS, ups.Scheme)
+	assert.Equal(t, "nacos", ups.DiscoveryType)
+	assert.Equal(t, "APISIX-NACOS", ups.ServiceName)
+	assert.Equal(t, map[string]string{"namespace_id": "test_ns"}, ups.DiscoveryArgs)
+
+	_, err = tr.translateDiscoveryUpstream(au, "v1", 80)
+	assert.Equal(t, &translateError{
+		field:  "discovery",
+		reason: "can't be used together with subsets",
+	}, err)
+
+	au.Spec.Discovery.Type = "consul"
+	_, err = tr.translateDiscoveryUpstream(au, "", 80)
+	assert.Equal(t, &translateError{
+		field:  "discovery.type",
+		reason: "consul is not enabled in APISIX",
+	}, err)
+}
+
+func TestTranslateUpstreamExternalNodes(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	informersFactory := informers.NewSharedInformerFactory(client, 0)
+	apisixFactory := apisixinformers.NewSharedInformerFactory(fakeapisix.NewSimpleClientset(), 0)
+	weight := 10
+	au := &configv2beta3.ApisixUpstream{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "db-proxy",
+			Namespace: "test",
+		},
+		Spec: &configv2beta3.ApisixUpstreamSpec{
+			ApisixUpstreamConfig: configv2beta3.ApisixUpstreamConfig{
+				Scheme: apisixv1.SchemeHTTPS,
+			},
+			ExternalNodes: []configv2beta3.ApisixUpstreamExternalNode{
+				{Host: "10.0.0.10"},
+				{Host: "proxy.example.com", Port: 8443, Weight: &weight},
+			},
+		},
+	}
+	assert.Nil(t, apisixFactory.Apisix().V2beta3().ApisixUpstreams().Informer().GetIndexer().Add(au))
+
+	epLister, _ := kube.NewEndpointListerAndInformer(informersFactory, false)
+	tr := &translator{&TranslatorOptions{
+		ServiceLister:        informersFactory.Core().V1().Services().Lister(),
+		EndpointLister:       epLister,
+		ApisixUpstreamLister: apisixFactory.Apisix().V2beta3().ApisixUpstreams().Lister(),
+	}}
+
+	// Neither the Service nor the endpoints exist.
+	ups, err := tr.TranslateUpstream("test", "db-proxy", "", 443)
+	assert.Nil(t, err)
+	assert.Equal(t, apisixv1.SchemeHTTPS, ups.Scheme)
+	assert.Equal(t, apisixv1.UpstreamNodes{
+		{Host: "10.0.0.10", Port: 443, Weight: 100},
+		{Host: "proxy.example.com", Port: 8443, Weight: 10},
+	}, ups.Nodes)
+
+	ip, port, err := tr.getServiceClusterIPAndPort(&configv2.ApisixRouteHTTPBackend{
+		ServiceName: "db-proxy",
+		ServicePort: intstr.FromInt(443),
+	}, "test")
+	assert.Nil(t, err)
+	assert.Equal(t, "", ip)
+	assert.Equal(t, int32(443), port)
+
+	_, _, err = tr.getServiceClusterIPAndPort(&configv2.ApisixRouteHTTPBackend{
+		ServiceName: "db-proxy",
+		ServicePort: intstr.FromString("https"),
+	}, "test")
+	assert.Equal(t, "service port https should be a number to use the external nodes", err.Error())
+
+	_, _, err = tr.getServiceClusterIPAndPort(&configv2.ApisixRouteHTTPBackend{
+		ServiceName:        "db-proxy",
+		ServicePort:        intstr.FromInt(443),
+		ResolveGranularity: "service",
+	}, "test")
+	assert.Equal(t, "resolve granularity service can't be used with the external nodes", err.Error())
+
+	_, err = tr.TranslateUpstream("test", "db-proxy", "v1", 443)
+	assert.Equal(t, &translateError{
+		field:  "externalNodes",
+		reason: "can't be used together with subsets",
+	}, err)
+
+	_, err = tr.TranslateExternalNodes([]configv2beta3.ApisixUpstreamExternalNode{{Host: "bad host"}}, 80)
+	assert.Equal(t, &translateError{
+		field:  "externalNodes[0].host",
+		reason: `"bad host" is neither an IP address nor a domain name`,
+	}, err)
+	_, err = tr.TranslateExternalNodes([]configv2beta3.ApisixUpstreamExternalNode{{Host: "10.0.0.10", Port: 70000}}, 80)
+	assert.Equal(t, &translateError{
+		field:  "externalNodes[0].port",
+		reason: "should be in the range [1, 65535]",
+	}, err)
+	weight = -1
+	_, err = tr.TranslateExternalNodes(au.Spec.ExternalNodes, 80)
+	assert.Equal(t, &translateError{
+		field:  "externalNodes[1].weight",
+		reason: "should not be negative",
+	}, err)
+}
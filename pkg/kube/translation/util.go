@@ -16,31 +16,76 @@ package translation
 
 import (
 	"errors"
+	"fmt"
 	"net"
+	"regexp"
+	"strings"
 
 	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/apache/apisix-ingress-controller/pkg/id"
+	"github.com/apache/apisix-ingress-controller/pkg/kube"
 	configv2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2"
 	configv2beta2 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta2"
 	configv2beta3 "github.com/apache/apisix-ingress-controller/pkg/kube/apisix/apis/config/v2beta3"
+	"github.com/apache/apisix-ingress-controller/pkg/kube/translation/annotations"
 	"github.com/apache/apisix-ingress-controller/pkg/log"
-	"github.com/apache/apisix-ingress-controller/pkg/types"
 	apisixv1 "github.com/apache/apisix-ingress-controller/pkg/types/apisix/v1"
 )
 
 var (
 	_errInvalidAddress = errors.New("address is neither IP or CIDR")
+	_errInvalidPath    = errors.New("path should start with \"/\" and the wildcard \"*\" can only be the last character or start the last segment")
+
+	// _hostRegex is the same as the pattern of hosts in ApisixRoute CRD.
+	_hostRegex = regexp.MustCompile(`^\*?[0-9a-zA-Z-._]+$`)
 )
 
+// isHeadlessService reports whether the Service has no cluster IP, so that it
+// can only be resolved to the pod IPs.
+func isHeadlessService(svc *corev1.Service) bool {
+	return svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone
+}
+
+// hasExternalNodes reports whether the ApisixUpstream with the name has
+// static nodes, the backends referencing it don't require the Service.
+func (t *translator) hasExternalNodes(ns, name string) bool {
+	if t.ApisixUpstreamLister == nil {
+		return false
+	}
+	au, err := t.ApisixUpstreamLister.ApisixUpstreams(ns).Get(name)
+	return err == nil && au.Spec != nil && len(au.Spec.ExternalNodes) > 0
+}
+
+// externalNodesPort returns the port of the backend referencing the static
+// nodes, it must be a number since there is no Service to look up the port
+// name.
+func externalNodesPort(port intstr.IntOrString, resolveGranularity string) (int32, error) {
+	if resolveGranularity != "" && resolveGranularity != "endpoint" {
+		return 0, fmt.Errorf("resolve granularity %s can't be used with the external nodes", resolveGranularity)
+	}
+	if port.Type != intstr.Int {
+		return 0, fmt.Errorf("service port %s should be a number to use the external nodes", port.StrVal)
+	}
+	return port.IntVal, nil
+}
+
 func (t *translator) getServiceClusterIPAndPort(backend *configv2.ApisixRouteHTTPBackend, ns string) (string, int32, error) {
 	svc, err := t.ServiceLister.Services(ns).Get(backend.ServiceName)
+	if k8serrors.IsNotFound(err) && t.hasExternalNodes(ns, backend.ServiceName) {
+		port, err := externalNodesPort(backend.ServicePort, backend.ResolveGranularity)
+		return "", port, err
+	}
 	if err != nil {
 		return "", 0, err
 	}
 	svcPort := int32(-1)
-	if backend.ResolveGranularity == "service" && svc.Spec.ClusterIP == "" {
+	if backend.ResolveGranularity == "service" && isHeadlessService(svc) {
 		log.Errorw("ApisixRoute refers to a headless service but want to use the service level resolve granularity",
 			zap.Any("namespace", ns),
 			zap.Any("service", svc),
@@ -76,11 +121,15 @@ loop:
 // getStreamServiceClusterIPAndPortV2beta2 is for v2beta2 streamRoute
 func (t *translator) getStreamServiceClusterIPAndPortV2beta2(backend configv2beta2.ApisixRouteStreamBackend, ns string) (string, int32, error) {
 	svc, err := t.ServiceLister.Services(ns).Get(backend.ServiceName)
+	if k8serrors.IsNotFound(err) && t.hasExternalNodes(ns, backend.ServiceName) {
+		port, err := externalNodesPort(backend.ServicePort, backend.ResolveGranularity)
+		return "", port, err
+	}
 	if err != nil {
 		return "", 0, err
 	}
 	svcPort := int32(-1)
-	if backend.ResolveGranularity == "service" && svc.Spec.ClusterIP == "" {
+	if backend.ResolveGranularity == "service" && isHeadlessService(svc) {
 		log.Errorw("ApisixRoute refers to a headless service but want to use the service level resolve granularity",
 			zap.String("ApisixRoute namespace", ns),
 			zap.Any("service", svc),
@@ -116,11 +165,15 @@ loop:
 // getStreamServiceClusterIPAndPortV2beta3 is for v2beta3 streamRoute
 func (t *translator) getStreamServiceClusterIPAndPortV2beta3(backend configv2beta3.ApisixRouteStreamBackend, ns string) (string, int32, error) {
 	svc, err := t.ServiceLister.Services(ns).Get(backend.ServiceName)
+	if k8serrors.IsNotFound(err) && t.hasExternalNodes(ns, backend.ServiceName) {
+		port, err := externalNodesPort(backend.ServicePort, backend.ResolveGranularity)
+		return "", port, err
+	}
 	if err != nil {
 		return "", 0, err
 	}
 	svcPort := int32(-1)
-	if backend.ResolveGranularity == "service" && svc.Spec.ClusterIP == "" {
+	if backend.ResolveGranularity == "service" && isHeadlessService(svc) {
 		log.Errorw("ApisixRoute refers to a headless service but want to use the service level resolve granularity",
 			zap.String("ApisixRoute namespace", ns),
 			zap.Any("service", svc),
@@ -156,11 +209,15 @@ loop:
 // getStreamServiceClusterIPAndPortV2 is for v2 streamRoute
 func (t *translator) getStreamServiceClusterIPAndPortV2(backend configv2.ApisixRouteStreamBackend, ns string) (string, int32, error) {
 	svc, err := t.ServiceLister.Services(ns).Get(backend.ServiceName)
+	if k8serrors.IsNotFound(err) && t.hasExternalNodes(ns, backend.ServiceName) {
+		port, err := externalNodesPort(backend.ServicePort, backend.ResolveGranularity)
+		return "", port, err
+	}
 	if err != nil {
 		return "", 0, err
 	}
 	svcPort := int32(-1)
-	if backend.ResolveGranularity == "service" && svc.Spec.ClusterIP == "" {
+	if backend.ResolveGranularity == "service" && isHeadlessService(svc) {
 		log.Errorw("ApisixRoute refers to a headless service but want to use the service level resolve granularity",
 			zap.String("ApisixRoute namespace", ns),
 			zap.Any("service", svc),
@@ -194,10 +251,12 @@ loop:
 }
 
 // translateUpstreamNotStrictly translates Upstream nodes with a loose way, only generate ID and Name for delete Event.
+// The ID is generated from the name only since the config is unknown, the
+// upstreams are deleted by the name then.
 func (t *translator) translateUpstreamNotStrictly(namespace, svcName, subset string, svcPort int32) (*apisixv1.Upstream, error) {
 	ups := &apisixv1.Upstream{}
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, svcName, subset, svcPort)
-	ups.ID = id.GenID(ups.Name)
+	ups.ID = id.GenID(id.Upstream, ups.Name)
 	return ups, nil
 }
 
@@ -206,7 +265,8 @@ func (t *translator) translateUpstream(namespace, svcName, subset, svcResolveGra
 	if err != nil {
 		return nil, err
 	}
-	if svcResolveGranularity == "service" {
+	switch svcResolveGranularity {
+	case "service":
 		ups.Nodes = apisixv1.UpstreamNodes{
 			{
 				Host:   svcClusterIP,
@@ -214,40 +274,125 @@ func (t *translator) translateUpstream(namespace, svcName, subset, svcResolveGra
 				Weight: _defaultWeight,
 			},
 		}
+	case "dns":
+		if subset != "" {
+			return nil, &translateError{
+				field:  "resolveGranularity",
+				reason: "dns can't be used together with subset",
+			}
+		}
+		if err := t.translateUpstreamDNSDiscovery(ups, namespace, svcName, svcPort); err != nil {
+			return nil, err
+		}
 	}
 	ups.Name = apisixv1.ComposeUpstreamName(namespace, svcName, subset, svcPort)
-	ups.ID = id.GenID(ups.Name)
+	ups.ID = apisixv1.ComposeUpstreamID(ups)
 	return ups, nil
 }
 
-func (t *translator) filterNodesByLabels(nodes apisixv1.UpstreamNodes, labels types.Labels, namespace string) apisixv1.UpstreamNodes {
-	if labels == nil {
-		return nodes
+// translateUpstreamDNSDiscovery makes APISIX resolve the upstream nodes by
+// the DNS name of the Service instead of the endpoints. The name of a
+// headless Service resolves to the pod IPs, so the target port is used.
+func (t *translator) translateUpstreamDNSDiscovery(ups *apisixv1.Upstream, namespace, svcName string, svcPort int32) error {
+	svc, err := t.ServiceLister.Services(namespace).Get(svcName)
+	if err != nil {
+		return err
+	}
+	port := svcPort
+	if isHeadlessService(svc) {
+		for _, p := range svc.Spec.Ports {
+			if p.Port != svcPort {
+				continue
+			}
+			if p.TargetPort.Type == intstr.String {
+				return &translateError{
+					field:  "resolveGranularity",
+					reason: "dns requires the numeric target port for headless service",
+				}
+			}
+			if p.TargetPort.IntVal != 0 {
+				port = p.TargetPort.IntVal
+			}
+			break
+		}
 	}
+	ups.Nodes = nil
+	ups.DiscoveryType = apisixv1.DiscoveryTypeDNS
+	ups.ServiceName = fmt.Sprintf("%s.%s.svc.%s:%d", svcName, namespace, t.ClusterDomain, port)
+	return nil
+}
 
-	filteredNodes := make(apisixv1.UpstreamNodes, 0)
-	for _, node := range nodes {
-		podName, err := t.PodCache.GetNameByIP(node.Host)
-		if err != nil {
-			log.Errorw("failed to find pod name by ip, ignore it",
-				zap.Error(err),
-				zap.String("pod_ip", node.Host),
-			)
-			continue
+// SubsetSelector returns the pod label selector of the ApisixUpstream
+// subset, the Labels and Selector are ANDed. A nil selector is returned if
+// the subset selects all the pods.
+func SubsetSelector(subset *configv2beta3.ApisixUpstreamSubset) (labels.Selector, error) {
+	if len(subset.Labels) == 0 && subset.Selector == nil {
+		return nil, nil
+	}
+	ls := &metav1.LabelSelector{}
+	if subset.Selector != nil {
+		ls = subset.Selector.DeepCopy()
+	}
+	if len(subset.Labels) > 0 && ls.MatchLabels == nil {
+		ls.MatchLabels = make(map[string]string, len(subset.Labels))
+	}
+	for k, v := range subset.Labels {
+		ls.MatchLabels[k] = v
+	}
+	selector, err := metav1.LabelSelectorAsSelector(ls)
+	if err != nil {
+		return nil, &translateError{
+			field:  "subsets.selector",
+			reason: err.Error(),
 		}
-		pod, err := t.PodLister.Pods(namespace).Get(podName)
+	}
+	return selector, nil
+}
+
+// matchPodLabels reports whether the target pod of the endpoint matches
+// the selector, the pod is found by the target reference of the endpoint,
+// or by its IP if the reference is missing.
+func (t *translator) matchPodLabels(namespace string, hp kube.HostPort, selector labels.Selector) bool {
+	podName := hp.PodName
+	if podName == "" {
+		name, err := t.PodCache.GetNameByIP(hp.Host)
 		if err != nil {
-			log.Errorw("failed to find pod, ignore it",
+			log.Errorw("failed to find pod name by ip, ignore it",
 				zap.Error(err),
-				zap.String("pod_name", podName),
+				zap.String("pod_ip", hp.Host),
 			)
-			continue
+			return false
 		}
-		if labels.IsSubsetOf(pod.Labels) {
-			filteredNodes = append(filteredNodes, node)
+		podName = name
+	}
+	pod, err := t.PodLister.Pods(namespace).Get(podName)
+	if err != nil {
+		log.Errorw("failed to find pod, ignore it",
+			zap.Error(err),
+			zap.String("pod_name", podName),
+		)
+		return false
+	}
+	return selector.Matches(labels.Set(pod.Labels))
+}
+
+// translateServiceHosts returns the hosts declared in the route hosts annotation
+// of the Service, a nil slice will be given if the annotation is missing.
+func (t *translator) translateServiceHosts(ns, svcName string) ([]string, error) {
+	svc, err := t.ServiceLister.Services(ns).Get(svcName)
+	if err != nil {
+		return nil, err
+	}
+	extractor := annotations.NewExtractor(svc.Annotations)
+	var hosts []string
+	for _, host := range extractor.GetStringsAnnotation(annotations.AnnotationsRouteHosts) {
+		host = strings.TrimSpace(host)
+		if !_hostRegex.MatchString(host) {
+			return nil, fmt.Errorf("invalid host %q in annotation %s", host, annotations.AnnotationsRouteHosts)
 		}
+		hosts = append(hosts, host)
 	}
-	return filteredNodes
+	return hosts, nil
 }
 
 func validateRemoteAddrs(remoteAddrs []string) error {
@@ -261,3 +406,45 @@ func validateRemoteAddrs(remoteAddrs []string) error {
 	}
 	return nil
 }
+
+// translateRoutePaths checks all the paths in a route rule, so that each path can be
+// used as an APISIX route uri, an exact match ("/foo") or a prefix match ("/foo/*").
+// Duplicated paths are dropped and the original order is kept.
+func translateRoutePaths(paths []string) ([]string, error) {
+	uris := make([]string, 0, len(paths))
+	seen := make(map[string]struct{}, len(paths))
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, _errInvalidPath
+		}
+		if !validRouteWildcard(path) {
+			return nil, _errInvalidPath
+		}
+		if _, ok := seen[path]; ok {
+			continue
+		}
+		seen[path] = struct{}{}
+		uris = append(uris, path)
+	}
+	return uris, nil
+}
+
+// validRouteWildcard checks the wildcard of the path the way APISIX does, a
+// "*" either ends the path (prefix match), or starts the last segment as a
+// named wildcard, e.g. "/foo/*action".
+func validRouteWildcard(path string) bool {
+	idx := strings.Index(path, "*")
+	if idx < 0 || idx == len(path)-1 {
+		return true
+	}
+	return path[idx-1] == '/' && !strings.ContainsAny(path[idx+1:], "/*")
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -37,3 +37,25 @@ func TestValidateRemoteAddrs(t *testing.T) {
 	}
 	assert.Nil(t, validateRemoteAddrs(addrs))
 }
+
+func TestTranslateRoutePaths(t *testing.T) {
+	uris, err := translateRoutePaths([]string{"/foo", "/foo/*", "/foo"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/foo", "/foo/*"}, uris)
+
+	_, err = translateRoutePaths([]string{"/foo", "foo/*"})
+	assert.Equal(t, _errInvalidPath, err)
+
+	_, err = translateRoutePaths([]string{"/foo/*/bar"})
+	assert.Equal(t, _errInvalidPath, err)
+
+	// Named wildcards capture the rest of the path.
+	uris, err = translateRoutePaths([]string{"/foo/*action", "/:id/*rest", "/foo*"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"/foo/*action", "/:id/*rest", "/foo*"}, uris)
+
+	for _, path := range []string{"/foo*bar", "/*action/bar", "/foo/*a*", "/fo*o/"} {
+		_, err = translateRoutePaths([]string{path})
+		assert.Equal(t, _errInvalidPath, err, path)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"runtime"
 	"time"
 
+	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -40,7 +41,7 @@ var (
 type Logger struct {
 	writer io.Writer
 	core   zapcore.Core
-	level  zapcore.Level
+	level  zap.AtomicLevel
 }
 
 func (logger *Logger) write(level zapcore.Level, message string, fields []zapcore.Field) {
@@ -51,7 +52,9 @@ func (logger *Logger) write(level zapcore.Level, message string, fields []zapcor
 		Caller:  zapcore.NewEntryCaller(runtime.Caller(3)),
 	}
 
-	_ = logger.core.Write(e, fields)
+	if ce := logger.core.Check(e, nil); ce != nil {
+		ce.Write(fields...)
+	}
 }
 
 // Sync flushes all buffered logs to the their destination.
@@ -74,7 +77,7 @@ func (logger *Logger) Close() (err error) {
 
 // Debug uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Debug(args ...interface{}) {
-	if logger.level <= zapcore.DebugLevel {
+	if logger.level.Enabled(zapcore.DebugLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.DebugLevel, msg, nil)
 	}
@@ -82,7 +85,7 @@ func (logger *Logger) Debug(args ...interface{}) {
 
 // Debugf uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Debugf(template string, args ...interface{}) {
-	if logger.level <= zapcore.DebugLevel {
+	if logger.level.Enabled(zapcore.DebugLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.DebugLevel, msg, nil)
 	}
@@ -90,14 +93,14 @@ func (logger *Logger) Debugf(template string, args ...interface{}) {
 
 // Debugw logs a message with some additional context.
 func (logger *Logger) Debugw(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.DebugLevel {
+	if logger.level.Enabled(zapcore.DebugLevel) {
 		logger.write(zapcore.DebugLevel, message, fields)
 	}
 }
 
 // Info uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Info(args ...interface{}) {
-	if logger.level <= zapcore.InfoLevel {
+	if logger.level.Enabled(zapcore.InfoLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.InfoLevel, msg, nil)
 	}
@@ -105,7 +108,7 @@ func (logger *Logger) Info(args ...interface{}) {
 
 // Infof uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Infof(template string, args ...interface{}) {
-	if logger.level <= zapcore.InfoLevel {
+	if logger.level.Enabled(zapcore.InfoLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.InfoLevel, msg, nil)
 	}
@@ -113,14 +116,14 @@ func (logger *Logger) Infof(template string, args ...interface{}) {
 
 // Infow logs a message with some additional context.
 func (logger *Logger) Infow(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.InfoLevel {
+	if logger.level.Enabled(zapcore.InfoLevel) {
 		logger.write(zapcore.InfoLevel, message, fields)
 	}
 }
 
 // Warn uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Warn(args ...interface{}) {
-	if logger.level <= zapcore.WarnLevel {
+	if logger.level.Enabled(zapcore.WarnLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.WarnLevel, msg, nil)
 	}
@@ -128,7 +131,7 @@ func (logger *Logger) Warn(args ...interface{}) {
 
 // Warnf uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Warnf(template string, args ...interface{}) {
-	if logger.level <= zapcore.WarnLevel {
+	if logger.level.Enabled(zapcore.WarnLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.WarnLevel, msg, nil)
 	}
@@ -136,14 +139,14 @@ func (logger *Logger) Warnf(template string, args ...interface{}) {
 
 // Warnw logs a message with some additional context.
 func (logger *Logger) Warnw(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.WarnLevel {
+	if logger.level.Enabled(zapcore.WarnLevel) {
 		logger.write(zapcore.WarnLevel, message, fields)
 	}
 }
 
 // Error uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Error(args ...interface{}) {
-	if logger.level <= zapcore.ErrorLevel {
+	if logger.level.Enabled(zapcore.ErrorLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.ErrorLevel, msg, nil)
 	}
@@ -151,7 +154,7 @@ func (logger *Logger) Error(args ...interface{}) {
 
 // Errorf uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Errorf(template string, args ...interface{}) {
-	if logger.level <= zapcore.ErrorLevel {
+	if logger.level.Enabled(zapcore.ErrorLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.ErrorLevel, msg, nil)
 	}
@@ -159,14 +162,14 @@ func (logger *Logger) Errorf(template string, args ...interface{}) {
 
 // Errorw logs a message with some additional context.
 func (logger *Logger) Errorw(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.ErrorLevel {
+	if logger.level.Enabled(zapcore.ErrorLevel) {
 		logger.write(zapcore.ErrorLevel, message, fields)
 	}
 }
 
 // Panic uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Panic(args ...interface{}) {
-	if logger.level <= zapcore.PanicLevel {
+	if logger.level.Enabled(zapcore.PanicLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.PanicLevel, msg, nil)
 	}
@@ -174,7 +177,7 @@ func (logger *Logger) Panic(args ...interface{}) {
 
 // Panicf uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Panicf(template string, args ...interface{}) {
-	if logger.level <= zapcore.PanicLevel {
+	if logger.level.Enabled(zapcore.PanicLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.PanicLevel, msg, nil)
 	}
@@ -182,14 +185,14 @@ func (logger *Logger) Panicf(template string, args ...interface{}) {
 
 // Panicw logs a message with some additional context.
 func (logger *Logger) Panicw(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.PanicLevel {
+	if logger.level.Enabled(zapcore.PanicLevel) {
 		logger.write(zapcore.PanicLevel, message, fields)
 	}
 }
 
 // Fatal uses the fmt.Sprint to construct and log a message.
 func (logger *Logger) Fatal(args ...interface{}) {
-	if logger.level <= zapcore.FatalLevel {
+	if logger.level.Enabled(zapcore.FatalLevel) {
 		msg := fmt.Sprint(args...)
 		logger.write(zapcore.FatalLevel, msg, nil)
 	}
@@ -197,7 +200,7 @@ func (logger *Logger) Fatal(args ...interface{}) {
 
 // Fatalf uses the fmt.Sprintf to log a templated message.
 func (logger *Logger) Fatalf(template string, args ...interface{}) {
-	if logger.level <= zapcore.FatalLevel {
+	if logger.level.Enabled(zapcore.FatalLevel) {
 		msg := fmt.Sprintf(template, args...)
 		logger.write(zapcore.FatalLevel, msg, nil)
 	}
@@ -205,7 +208,7 @@ func (logger *Logger) Fatalf(template string, args ...interface{}) {
 
 // Fatalw logs a message with some additional context.
 func (logger *Logger) Fatalw(message string, fields ...zapcore.Field) {
-	if logger.level <= zapcore.FatalLevel {
+	if logger.level.Enabled(zapcore.FatalLevel) {
 		logger.write(zapcore.FatalLevel, message, fields)
 	}
 }
@@ -231,7 +234,7 @@ func NewLogger(opts ...Option) (*Logger, error) {
 	}
 
 	logger := &Logger{
-		level: level,
+		level: zap.NewAtomicLevelAt(level),
 	}
 
 	if o.writeSyncer != nil {
@@ -280,6 +283,31 @@ func NewLogger(opts ...Option) (*Logger, error) {
 		})
 	}
 	logger.writer = writer
-	logger.core = zapcore.NewCore(enc, writer, level)
+	if o.samplingInitial > 0 {
+		// Only the debug and info logs are sampled, so that warnings and
+		// errors are never dropped.
+		low := zapcore.NewCore(enc.Clone(), writer, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l < zapcore.WarnLevel && logger.level.Enabled(l)
+		}))
+		high := zapcore.NewCore(enc, writer, zap.LevelEnablerFunc(func(l zapcore.Level) bool {
+			return l >= zapcore.WarnLevel && logger.level.Enabled(l)
+		}))
+		logger.core = zapcore.NewTee(
+			zapcore.NewSamplerWithOptions(low, time.Second, o.samplingInitial, o.samplingThereafter),
+			high,
+		)
+	} else {
+		logger.core = zapcore.NewCore(enc, writer, logger.level)
+	}
 	return logger, nil
 }
+
+// SetLevel changes the log level at runtime.
+func (logger *Logger) SetLevel(level string) error {
+	l, ok := levelMap[level]
+	if !ok {
+		return fmt.Errorf("unknown log level %s", level)
+	}
+	logger.level.SetLevel(l)
+	return nil
+}
@@ -114,3 +114,50 @@ func TestLogLevel(t *testing.T) {
 	p := fws.bytes()
 	assert.Len(t, p, 0, "saw a message which should be dropped")
 }
+
+func TestLoggerSetLevel(t *testing.T) {
+	fws := &fakeWriteSyncer{}
+	logger, err := NewLogger(WithLogLevel("error"), WithWriteSyncer(fws))
+	assert.Nil(t, err, "failed to new logger: ", err)
+	defer logger.Close()
+
+	assert.Nil(t, logger.SetLevel("debug"))
+	logger.Debug("this message should be written")
+	assert.Nil(t, logger.Sync(), "failed to sync logger")
+	fields := unmarshalLogMessage(t, fws.bytes())
+	assert.Equal(t, "debug", fields.Level)
+
+	assert.Nil(t, logger.SetLevel("warn"))
+	logger.Info("this message should be dropped")
+	assert.Len(t, fws.bytes(), 0, "saw a message which should be dropped")
+
+	assert.NotNil(t, logger.SetLevel("verbose"))
+}
+
+func TestLoggerSampling(t *testing.T) {
+	fws := &fakeWriteSyncer{}
+	logger, err := NewLogger(WithLogLevel("debug"), WithWriteSyncer(fws), WithSampling(2, 5))
+	assert.Nil(t, err, "failed to new logger: ", err)
+	defer logger.Close()
+
+	for i := 0; i < 12; i++ {
+		logger.Debug("hot path")
+	}
+	for i := 0; i < 12; i++ {
+		logger.Warn("warning")
+	}
+	assert.Nil(t, logger.Sync(), "failed to sync logger")
+
+	debugs, warns := 0, 0
+	for _, line := range bytes.Split(bytes.TrimSpace(fws.bytes()), []byte("\n")) {
+		switch unmarshalLogMessage(t, line).Level {
+		case "debug":
+			debugs++
+		case "warn":
+			warns++
+		}
+	}
+	// The first 2 debug entries, then the 7th and 12th ones.
+	assert.Equal(t, 4, debugs)
+	assert.Equal(t, 12, warns)
+}
@@ -35,6 +35,10 @@ type options struct {
 	writeSyncer zapcore.WriteSyncer
 	outputFile  string
 	logLevel    string
+	// samplingInitial and samplingThereafter are the log sampling
+	// thresholds, sampling is disabled if samplingInitial is zero.
+	samplingInitial    int
+	samplingThereafter int
 }
 
 // WithLogLevel sets the log level.
@@ -65,3 +69,16 @@ func WithWriteSyncer(ws zapcore.WriteSyncer) Option {
 		},
 	}
 }
+
+// WithSampling samples the debug and info logs, the first initial entries
+// with the same level and message in each second are logged, then every
+// thereafter-th entry is logged. Warnings and errors are never sampled,
+// zero initial disables the sampling.
+func WithSampling(initial, thereafter int) Option {
+	return &funcOption{
+		do: func(o *options) {
+			o.samplingInitial = initial
+			o.samplingThereafter = thereafter
+		},
+	}
+}
@@ -17,6 +17,7 @@ package metrics
 import (
 	"os"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -30,6 +31,10 @@ const (
 type Collector interface {
 	// ResetLeader changes the role of ingress apisix instance (leader, follower).
 	ResetLeader(bool)
+	// IsLeader returns whether the ingress apisix instance is the leader, which
+	// is the only one reconciling resources. Sync metrics are only recorded by
+	// the leader.
+	IsLeader() bool
 	// RecordAPISIXCode records a status code returned by APISIX with the resource
 	// type label.
 	RecordAPISIXCode(int, string)
@@ -38,11 +43,15 @@ type Collector interface {
 	RecordAPISIXLatency(time.Duration, string)
 	// IncrAPISIXRequest increases the number of requests to apisix.
 	IncrAPISIXRequest(string)
+	// IncrSkippedWrites increases the number of writes to apisix skipped
+	// since the payload is unchanged, with the resource type label.
+	IncrSkippedWrites(string)
 	// IncrCheckClusterHealth increases the number of cluster health check operations
 	// with the cluster name label.
 	IncrCheckClusterHealth(string)
 	// IncrSyncOperation increases the number of sync operations with the resource
-	// type label.
+	// type label, the time of the last successful sync of the resource type is
+	// also recorded.
 	IncrSyncOperation(string, string)
 	// IncrCacheSyncOperation increases the number of cache sync operations with the
 	// resource type label.
@@ -50,18 +59,45 @@ type Collector interface {
 	// IncrEvents increases the number of events handled by controllers with the
 	// operation label.
 	IncrEvents(string, string)
+	// IncrRouteLimitRejection increases the number of syncs rejected due to
+	// the route count limit.
+	IncrRouteLimitRejection()
+	// IncrResyncResources increases the number of resources handled by the
+	// periodic resync with the resource type and result (skipped, pushed) labels.
+	IncrResyncResources(string, string, int)
+	// SetStaleWorkers sets the number of stuck workers with the controller
+	// label.
+	SetStaleWorkers(string, int)
+	// SetManagedResources sets the number of resources managed by the
+	// controller with the resource type and cluster name labels.
+	SetManagedResources(string, string, int)
+	// DeleteManagedResources deletes the number of resources managed by the
+	// controller with the resource type and cluster name labels.
+	DeleteManagedResources(string, string)
+	// SetLeaderResync sets whether the resync triggered by the leader
+	// acquisition is in progress.
+	SetLeaderResync(bool)
 }
 
 // collector contains necessary messages to collect Prometheus metrics.
 type collector struct {
+	// leader is 1 if the instance is the leader, it's accessed atomically.
+	leader             int32
 	isLeader           prometheus.Gauge
 	apisixLatency      *prometheus.SummaryVec
 	apisixRequests     *prometheus.CounterVec
+	skippedWrites      *prometheus.CounterVec
 	apisixCodes        *prometheus.GaugeVec
 	checkClusterHealth *prometheus.CounterVec
 	syncOperation      *prometheus.CounterVec
+	lastSyncSuccess    *prometheus.GaugeVec
 	cacheSyncOperation *prometheus.CounterVec
 	controllerEvents   *prometheus.CounterVec
+	routeLimitRejected prometheus.Counter
+	resyncResources    *prometheus.CounterVec
+	staleWorkers       *prometheus.GaugeVec
+	managedResources   *prometheus.GaugeVec
+	leaderResync       prometheus.Gauge
 }
 
 // NewPrometheusCollector creates the Prometheus metrics collector.
@@ -114,6 +150,15 @@ func NewPrometheusCollector() Collector {
 			},
 			[]string{"resource"},
 		),
+		skippedWrites: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "apisix_skipped_writes_total",
+				Help:        "Number of writes to APISIX skipped since the payload is unchanged",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource"},
+		),
 		checkClusterHealth: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace:   _namespace,
@@ -132,6 +177,15 @@ func NewPrometheusCollector() Collector {
 			},
 			[]string{"resource", "result"},
 		),
+		lastSyncSuccess: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "last_sync_success_timestamp_seconds",
+				Help:        "Unix timestamp of the last successful sync operation",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource"},
+		),
 		cacheSyncOperation: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace:   _namespace,
@@ -150,6 +204,49 @@ func NewPrometheusCollector() Collector {
 			},
 			[]string{"operation", "resource"},
 		),
+		routeLimitRejected: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "route_limit_rejected_total",
+				Help:        "Number of syncs rejected due to the route count limit",
+				ConstLabels: constLabels,
+			},
+		),
+		resyncResources: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   _namespace,
+				Name:        "resync_resources_total",
+				Help:        "Number of resources skipped or pushed by the periodic resync",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource", "result"},
+		),
+		staleWorkers: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "stale_workers",
+				Help:        "Number of controller workers stuck in handling an event",
+				ConstLabels: constLabels,
+			},
+			[]string{"controller"},
+		),
+		managedResources: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "managed_resources",
+				Help:        "Number of APISIX resources managed by the controller",
+				ConstLabels: constLabels,
+			},
+			[]string{"resource", "cluster"},
+		),
+		leaderResync: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Namespace:   _namespace,
+				Name:        "leader_resync_in_progress",
+				Help:        "Whether the resync triggered by the leader acquisition is in progress",
+				ConstLabels: constLabels,
+			},
+		),
 	}
 
 	// Since we use the DefaultRegisterer, in test cases, the metrics
@@ -158,20 +255,34 @@ func NewPrometheusCollector() Collector {
 	prometheus.Unregister(collector.apisixCodes)
 	prometheus.Unregister(collector.apisixLatency)
 	prometheus.Unregister(collector.apisixRequests)
+	prometheus.Unregister(collector.skippedWrites)
 	prometheus.Unregister(collector.checkClusterHealth)
 	prometheus.Unregister(collector.syncOperation)
+	prometheus.Unregister(collector.lastSyncSuccess)
 	prometheus.Unregister(collector.cacheSyncOperation)
 	prometheus.Unregister(collector.controllerEvents)
+	prometheus.Unregister(collector.routeLimitRejected)
+	prometheus.Unregister(collector.resyncResources)
+	prometheus.Unregister(collector.staleWorkers)
+	prometheus.Unregister(collector.managedResources)
+	prometheus.Unregister(collector.leaderResync)
 
 	prometheus.MustRegister(
 		collector.isLeader,
 		collector.apisixCodes,
 		collector.apisixLatency,
 		collector.apisixRequests,
+		collector.skippedWrites,
 		collector.checkClusterHealth,
 		collector.syncOperation,
+		collector.lastSyncSuccess,
 		collector.cacheSyncOperation,
 		collector.controllerEvents,
+		collector.routeLimitRejected,
+		collector.resyncResources,
+		collector.staleWorkers,
+		collector.managedResources,
+		collector.leaderResync,
 	)
 
 	return collector
@@ -180,12 +291,19 @@ func NewPrometheusCollector() Collector {
 // ResetLeader resets the leader role.
 func (c *collector) ResetLeader(leader bool) {
 	if leader {
+		atomic.StoreInt32(&c.leader, 1)
 		c.isLeader.Set(1)
 	} else {
+		atomic.StoreInt32(&c.leader, 0)
 		c.isLeader.Set(0)
 	}
 }
 
+// IsLeader returns whether the instance is the leader.
+func (c *collector) IsLeader() bool {
+	return atomic.LoadInt32(&c.leader) == 1
+}
+
 // RecordAPISIXCode records the status code (returned by APISIX)
 // for the specific resource (e.g. Route, Upstream and etc).
 func (c *collector) RecordAPISIXCode(code int, resource string) {
@@ -207,6 +325,12 @@ func (c *collector) IncrAPISIXRequest(resource string) {
 	c.apisixRequests.WithLabelValues(resource).Inc()
 }
 
+// IncrSkippedWrites increases the number of writes to APISIX skipped
+// since the payload is unchanged.
+func (c *collector) IncrSkippedWrites(resource string) {
+	c.skippedWrites.WithLabelValues(resource).Inc()
+}
+
 // IncrCheckClusterHealth increases the number of cluster health check
 // operations.
 func (c *collector) IncrCheckClusterHealth(name string) {
@@ -214,17 +338,27 @@ func (c *collector) IncrCheckClusterHealth(name string) {
 }
 
 // IncrSyncOperation increases the number of sync operations for specific
-// resource.
+// resource. For the successful ones, the time is recorded so that a stuck
+// controller, which is not reconciling at all, can be alerted.
 func (c *collector) IncrSyncOperation(resource, result string) {
+	if !c.IsLeader() {
+		return
+	}
 	c.syncOperation.With(prometheus.Labels{
 		"resource": resource,
 		"result":   result,
 	}).Inc()
+	if result == "success" {
+		c.lastSyncSuccess.WithLabelValues(resource).SetToCurrentTime()
+	}
 }
 
 // IncrCacheSyncOperation increases the number of cache sync operations for
 // cluster.
 func (c *collector) IncrCacheSyncOperation(result string) {
+	if !c.IsLeader() {
+		return
+	}
 	c.cacheSyncOperation.WithLabelValues(result).Inc()
 }
 
@@ -237,16 +371,75 @@ func (c *collector) IncrEvents(resource, operation string) {
 	}).Inc()
 }
 
+// IncrRouteLimitRejection increases the number of syncs rejected
+// since the route count limit is reached.
+func (c *collector) IncrRouteLimitRejection() {
+	c.routeLimitRejected.Inc()
+}
+
+// IncrResyncResources increases the number of resources skipped or
+// pushed by the periodic resync.
+func (c *collector) IncrResyncResources(resource, result string, n int) {
+	if !c.IsLeader() {
+		return
+	}
+	c.resyncResources.With(prometheus.Labels{
+		"resource": resource,
+		"result":   result,
+	}).Add(float64(n))
+}
+
+// SetStaleWorkers sets the number of workers of the controller which
+// are stuck in handling an event.
+func (c *collector) SetStaleWorkers(controller string, n int) {
+	c.staleWorkers.WithLabelValues(controller).Set(float64(n))
+}
+
+// SetManagedResources sets the number of resources of the type in the
+// APISIX cluster which are managed by the controller.
+func (c *collector) SetManagedResources(resource, cluster string, n int) {
+	c.managedResources.With(prometheus.Labels{
+		"resource": resource,
+		"cluster":  cluster,
+	}).Set(float64(n))
+}
+
+// DeleteManagedResources deletes the number of resources of the type in the
+// APISIX cluster, so that a dropped cluster isn't reported anymore.
+func (c *collector) DeleteManagedResources(resource, cluster string) {
+	c.managedResources.Delete(prometheus.Labels{
+		"resource": resource,
+		"cluster":  cluster,
+	})
+}
+
+// SetLeaderResync sets whether the resync triggered by the leader
+// acquisition is in progress.
+func (c *collector) SetLeaderResync(inProgress bool) {
+	if inProgress {
+		c.leaderResync.Set(1)
+	} else {
+		c.leaderResync.Set(0)
+	}
+}
+
 // Collect collects the prometheus.Collect.
 func (c *collector) Collect(ch chan<- prometheus.Metric) {
 	c.isLeader.Collect(ch)
 	c.apisixLatency.Collect(ch)
 	c.apisixRequests.Collect(ch)
+	c.skippedWrites.Collect(ch)
 	c.apisixCodes.Collect(ch)
 	c.checkClusterHealth.Collect(ch)
 	c.syncOperation.Collect(ch)
+	c.lastSyncSuccess.Collect(ch)
 	c.cacheSyncOperation.Collect(ch)
 	c.controllerEvents.Collect(ch)
+	c.routeLimitRejected.Collect(ch)
+	c.resyncResources.Collect(ch)
+	c.staleWorkers.Collect(ch)
+	c.managedResources.Collect(ch)
+	c.leaderResync.Collect(ch)
 }
 
 // Describe describes the prometheus.Describe.
@@ -254,9 +447,16 @@ func (c *collector) Describe(ch chan<- *prometheus.Desc) {
 	c.isLeader.Describe(ch)
 	c.apisixLatency.Describe(ch)
 	c.apisixRequests.Describe(ch)
+	c.skippedWrites.Describe(ch)
 	c.apisixCodes.Describe(ch)
 	c.checkClusterHealth.Describe(ch)
 	c.syncOperation.Describe(ch)
+	c.lastSyncSuccess.Describe(ch)
 	c.cacheSyncOperation.Describe(ch)
 	c.controllerEvents.Describe(ch)
+	c.routeLimitRejected.Describe(ch)
+	c.resyncResources.Describe(ch)
+	c.staleWorkers.Describe(ch)
+	c.managedResources.Describe(ch)
+	c.leaderResync.Describe(ch)
 }
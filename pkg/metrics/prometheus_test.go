@@ -162,6 +162,21 @@ func syncOperationTestHandler(t *testing.T, metrics []*io_prometheus_client.Metr
 	}
 }
 
+func lastSyncSuccessTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily, start time.Time) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_last_sync_success_timestamp_seconds", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "GAUGE")
+		m := metric.GetMetric()
+		// The failed sync operation of schema is not recorded.
+		assert.Len(t, m, 1)
+
+		assert.GreaterOrEqual(t, *m[0].Gauge.Value, float64(start.Unix()))
+		assert.Equal(t, *m[0].Label[2].Name, "resource")
+		assert.Equal(t, *m[0].Label[2].Value, "endpoint")
+	}
+}
+
 func cacheSncOperationTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
 	return func(t *testing.T) {
 		metric := findMetric("apisix_ingress_controller_cache_sync_total", metrics)
@@ -200,7 +215,108 @@ func controllerEventsTestHandler(t *testing.T, metrics []*io_prometheus_client.M
 	}
 }
 
+func routeLimitRejectedTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_route_limit_rejected_total", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, *m[0].Counter.Value, float64(2))
+		assert.Equal(t, *m[0].Label[0].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[0].Value, "default")
+		assert.Equal(t, *m[0].Label[1].Name, "controller_pod")
+		assert.Equal(t, *m[0].Label[1].Value, "")
+	}
+}
+
+func resyncResourcesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_resync_resources_total", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, *m[0].Counter.Value, float64(1))
+		assert.Equal(t, *m[0].Label[2].Name, "resource")
+		assert.Equal(t, *m[0].Label[2].Value, "route")
+		assert.Equal(t, *m[0].Label[3].Name, "result")
+		assert.Equal(t, *m[0].Label[3].Value, "pushed")
+
+		assert.Equal(t, *m[1].Counter.Value, float64(5))
+		assert.Equal(t, *m[1].Label[2].Name, "resource")
+		assert.Equal(t, *m[1].Label[2].Value, "route")
+		assert.Equal(t, *m[1].Label[3].Name, "result")
+		assert.Equal(t, *m[1].Label[3].Value, "skipped")
+	}
+}
+
+func skippedWritesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_apisix_skipped_writes_total", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "COUNTER")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, *m[0].Counter.Value, float64(2))
+		assert.Equal(t, *m[0].Label[2].Name, "resource")
+		assert.Equal(t, *m[0].Label[2].Value, "route")
+	}
+}
+
+func staleWorkersTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_stale_workers", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "GAUGE")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, *m[0].Gauge.Value, float64(1))
+		assert.Equal(t, *m[0].Label[0].Name, "controller")
+		assert.Equal(t, *m[0].Label[0].Value, "ApisixRoute")
+		assert.Equal(t, *m[0].Label[1].Name, "controller_namespace")
+		assert.Equal(t, *m[0].Label[1].Value, "default")
+	}
+}
+
+func leaderResyncTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_leader_resync_in_progress", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "GAUGE")
+		m := metric.GetMetric()
+		assert.Len(t, m, 1)
+
+		assert.Equal(t, *m[0].Gauge.Value, float64(1))
+	}
+}
+
+func managedResourcesTestHandler(t *testing.T, metrics []*io_prometheus_client.MetricFamily) func(t *testing.T) {
+	return func(t *testing.T) {
+		metric := findMetric("apisix_ingress_controller_managed_resources", metrics)
+		assert.NotNil(t, metric)
+		assert.Equal(t, metric.Type.String(), "GAUGE")
+		m := metric.GetMetric()
+		assert.Len(t, m, 2)
+
+		assert.Equal(t, *m[0].Gauge.Value, float64(3))
+		assert.Equal(t, *m[0].Label[0].Name, "cluster")
+		assert.Equal(t, *m[0].Label[0].Value, "default")
+		assert.Equal(t, *m[0].Label[3].Name, "resource")
+		assert.Equal(t, *m[0].Label[3].Value, "route")
+
+		assert.Equal(t, *m[1].Gauge.Value, float64(1))
+		assert.Equal(t, *m[1].Label[0].Value, "default")
+		assert.Equal(t, *m[1].Label[3].Value, "upstream")
+	}
+}
+
 func TestPrometheusCollector(t *testing.T) {
+	start := time.Now()
 	c := NewPrometheusCollector()
 	c.ResetLeader(true)
 	c.RecordAPISIXCode(404, "route")
@@ -214,6 +330,21 @@ func TestPrometheusCollector(t *testing.T) {
 	c.IncrSyncOperation("endpoint", "success")
 	c.IncrCacheSyncOperation("failure")
 	c.IncrEvents("pod", "add")
+	c.IncrRouteLimitRejection()
+	c.IncrRouteLimitRejection()
+	c.IncrResyncResources("route", "skipped", 3)
+	c.IncrResyncResources("route", "skipped", 2)
+	c.IncrResyncResources("route", "pushed", 1)
+	c.IncrSkippedWrites("route")
+	c.IncrSkippedWrites("route")
+	c.SetStaleWorkers("ApisixRoute", 2)
+	c.SetStaleWorkers("ApisixRoute", 1)
+	c.SetManagedResources("route", "default", 4)
+	c.SetManagedResources("route", "default", 3)
+	c.SetManagedResources("upstream", "default", 1)
+	c.SetManagedResources("ssl", "edge", 2)
+	c.DeleteManagedResources("ssl", "edge")
+	c.SetLeaderResync(true)
 
 	metrics, err := prometheus.DefaultGatherer.Gather()
 	assert.Nil(t, err)
@@ -224,8 +355,45 @@ func TestPrometheusCollector(t *testing.T) {
 	t.Run("apisix_requests", apisixRequestTestHandler(t, metrics))
 	t.Run("check_cluster_health_total", checkClusterHealthTestHandler(t, metrics))
 	t.Run("sync_operation_total", syncOperationTestHandler(t, metrics))
+	t.Run("last_sync_success_timestamp_seconds", lastSyncSuccessTestHandler(t, metrics, start))
 	t.Run("cache_sync_total", cacheSncOperationTestHandler(t, metrics))
 	t.Run("events_total", controllerEventsTestHandler(t, metrics))
+	t.Run("route_limit_rejected_total", routeLimitRejectedTestHandler(t, metrics))
+	t.Run("resync_resources_total", resyncResourcesTestHandler(t, metrics))
+	t.Run("apisix_skipped_writes_total", skippedWritesTestHandler(t, metrics))
+	t.Run("stale_workers", staleWorkersTestHandler(t, metrics))
+	t.Run("managed_resources", managedResourcesTestHandler(t, metrics))
+	t.Run("leader_resync_in_progress", leaderResyncTestHandler(t, metrics))
+}
+
+func TestPrometheusCollectorFollower(t *testing.T) {
+	c := NewPrometheusCollector()
+	c.ResetLeader(false)
+	assert.False(t, c.IsLeader())
+	c.IncrSyncOperation("route", "success")
+	c.IncrCacheSyncOperation("success")
+	c.IncrResyncResources("route", "pushed", 1)
+
+	metrics, err := prometheus.DefaultGatherer.Gather()
+	assert.Nil(t, err)
+
+	// Sync metrics are not recorded by followers.
+	assert.Nil(t, findMetric("apisix_ingress_controller_sync_operation_total", metrics))
+	assert.Nil(t, findMetric("apisix_ingress_controller_last_sync_success_timestamp_seconds", metrics))
+	assert.Nil(t, findMetric("apisix_ingress_controller_cache_sync_total", metrics))
+	assert.Nil(t, findMetric("apisix_ingress_controller_resync_resources_total", metrics))
+
+	metric := findMetric("apisix_ingress_controller_is_leader", metrics)
+	assert.NotNil(t, metric)
+	assert.Equal(t, float64(0), *metric.GetMetric()[0].Gauge.Value)
+
+	c.ResetLeader(true)
+	assert.True(t, c.IsLeader())
+	c.IncrSyncOperation("route", "success")
+
+	metrics, err = prometheus.DefaultGatherer.Gather()
+	assert.Nil(t, err)
+	assert.NotNil(t, findMetric("apisix_ingress_controller_sync_operation_total", metrics))
 }
 
 func findMetric(name string, metrics []*io_prometheus_client.MetricFamily) *io_prometheus_client.MetricFamily {
@@ -14,6 +14,8 @@
 // limitations under the License.
 package v1
 
+import "encoding/json"
+
 // TrafficSplitConfig is the config of traffic-split plugin.
 // +k8s:deepcopy-gen=true
 type TrafficSplitConfig struct {
@@ -23,9 +25,17 @@ type TrafficSplitConfig struct {
 // TrafficSplitConfigRule is the rule config in traffic-split plugin config.
 // +k8s:deepcopy-gen=true
 type TrafficSplitConfigRule struct {
+	Match             []TrafficSplitConfigRuleMatch            `json:"match,omitempty"`
 	WeightedUpstreams []TrafficSplitConfigRuleWeightedUpstream `json:"weighted_upstreams"`
 }
 
+// TrafficSplitConfigRuleMatch is the match condition of the traffic split
+// plugin rule, the rule is applied only if the condition is met.
+// +k8s:deepcopy-gen=true
+type TrafficSplitConfigRuleMatch struct {
+	Vars Vars `json:"vars"`
+}
+
 // TrafficSplitConfigRuleWeightedUpstream is the weighted upstream config in
 // the traffic split plugin rule.
 // +k8s:deepcopy-gen=true
@@ -34,6 +44,78 @@ type TrafficSplitConfigRuleWeightedUpstream struct {
 	Weight     int    `json:"weight"`
 }
 
+// TrafficSplitUpstreamIDs returns the IDs of the upstreams referenced in the
+// traffic-split plugin of the route, without duplicates. The plugin config is
+// a *TrafficSplitConfig when it's translated, and a generic map when it's
+// read from APISIX or deep copied.
+func TrafficSplitUpstreamIDs(r *Route) []string {
+	plugin, ok := r.Plugins["traffic-split"]
+	if !ok || plugin == nil {
+		return nil
+	}
+	data, err := json.Marshal(plugin)
+	if err != nil {
+		return nil
+	}
+	var cfg TrafficSplitConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+	var (
+		ids  []string
+		seen = make(map[string]struct{})
+	)
+	for _, rule := range cfg.Rules {
+		for _, wup := range rule.WeightedUpstreams {
+			if wup.UpstreamID == "" {
+				continue
+			}
+			if _, ok := seen[wup.UpstreamID]; ok {
+				continue
+			}
+			seen[wup.UpstreamID] = struct{}{}
+			ids = append(ids, wup.UpstreamID)
+		}
+	}
+	return ids
+}
+
+// ReplaceRouteUpstreamID replaces the upstream ID old with new in the route,
+// including the references in the traffic-split plugin. It reports whether
+// the route is changed.
+func ReplaceRouteUpstreamID(r *Route, old, new string) bool {
+	changed := false
+	if r.UpstreamId == old {
+		r.UpstreamId = new
+		changed = true
+	}
+	plugin, ok := r.Plugins["traffic-split"]
+	if !ok || plugin == nil {
+		return changed
+	}
+	data, err := json.Marshal(plugin)
+	if err != nil {
+		return changed
+	}
+	var cfg TrafficSplitConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return changed
+	}
+	replaced := false
+	for i := range cfg.Rules {
+		for j := range cfg.Rules[i].WeightedUpstreams {
+			if cfg.Rules[i].WeightedUpstreams[j].UpstreamID == old {
+				cfg.Rules[i].WeightedUpstreams[j].UpstreamID = new
+				replaced = true
+			}
+		}
+	}
+	if replaced {
+		r.Plugins["traffic-split"] = &cfg
+	}
+	return changed || replaced
+}
+
 // IPRestrictConfig is the rule config for ip-restriction plugin.
 // +k8s:deepcopy-gen=true
 type IPRestrictConfig struct {
@@ -41,12 +123,53 @@ type IPRestrictConfig struct {
 	Blocklist []string `json:"blacklist,omitempty"`
 }
 
+// FileLoggerConfig is the rule config for file-logger plugin.
+// +k8s:deepcopy-gen=true
+type FileLoggerConfig struct {
+	Path      string            `json:"path"`
+	LogFormat map[string]string `json:"log_format,omitempty"`
+}
+
+// LimitCountConfig is the rule config for limit-count plugin.
+// +k8s:deepcopy-gen=true
+type LimitCountConfig struct {
+	Count            int      `json:"count"`
+	TimeWindow       int      `json:"time_window"`
+	Key              string   `json:"key,omitempty"`
+	RejectedCode     int      `json:"rejected_code,omitempty"`
+	Policy           string   `json:"policy,omitempty"`
+	RedisHost        string   `json:"redis_host,omitempty"`
+	RedisPort        int      `json:"redis_port,omitempty"`
+	RedisPassword    string   `json:"redis_password,omitempty"`
+	RedisDatabase    int      `json:"redis_database,omitempty"`
+	RedisTimeout     int      `json:"redis_timeout,omitempty"`
+	RedisClusterName string   `json:"redis_cluster_name,omitempty"`
+	RedisClusterNode []string `json:"redis_cluster_nodes,omitempty"`
+}
+
+// BodyTransformerConfig is the rule config for body-transformer plugin.
+// +k8s:deepcopy-gen=true
+type BodyTransformerConfig struct {
+	Request  *BodyTransformerTemplate `json:"request,omitempty"`
+	Response *BodyTransformerTemplate `json:"response,omitempty"`
+}
+
+// BodyTransformerTemplate is the template of body-transformer plugin.
+// +k8s:deepcopy-gen=true
+type BodyTransformerTemplate struct {
+	InputFormat string `json:"input_format,omitempty"`
+	Template    string `json:"template"`
+}
+
 // CorsConfig is the rule config for cors plugin.
 // +k8s:deepcopy-gen=true
 type CorsConfig struct {
-	AllowOrigins string `json:"allow_origins,omitempty"`
-	AllowMethods string `json:"allow_methods,omitempty"`
-	AllowHeaders string `json:"allow_headers,omitempty"`
+	AllowOrigins    string `json:"allow_origins,omitempty"`
+	AllowMethods    string `json:"allow_methods,omitempty"`
+	AllowHeaders    string `json:"allow_headers,omitempty"`
+	ExposeHeaders   string `json:"expose_headers,omitempty"`
+	MaxAge          *int   `json:"max_age,omitempty"`
+	AllowCredential bool   `json:"allow_credential,omitempty"`
 }
 
 // CSRfConfig is the rule config for csrf plugin.
@@ -125,6 +248,57 @@ type RewriteConfig struct {
 	RewriteTargetRegex []string `json:"regex_uri,omitempty"`
 }
 
+// ResponseRewriteConfig is the rule config for response-rewrite plugin.
+// +k8s:deepcopy-gen=true
+type ResponseRewriteConfig struct {
+	StatusCode int                     `json:"status_code,omitempty"`
+	Body       string                  `json:"body,omitempty"`
+	BodyBase64 bool                    `json:"body_base64,omitempty"`
+	Headers    *ResponseRewriteHeaders `json:"headers,omitempty"`
+}
+
+// ResponseRewriteHeaders is the headers config of response-rewrite plugin.
+// +k8s:deepcopy-gen=true
+type ResponseRewriteHeaders struct {
+	Add    []string          `json:"add,omitempty"`
+	Set    map[string]string `json:"set,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface. The headers are encoded
+// in the legacy form (a map, where the removed headers have empty values)
+// unless there are added headers, so that APISIX before 3.0 accepts them.
+func (h ResponseRewriteHeaders) MarshalJSON() ([]byte, error) {
+	if len(h.Add) > 0 {
+		type headers ResponseRewriteHeaders
+		return json.Marshal(headers(h))
+	}
+	legacy := make(map[string]string, len(h.Set)+len(h.Remove))
+	for k, v := range h.Set {
+		legacy[k] = v
+	}
+	for _, k := range h.Remove {
+		legacy[k] = ""
+	}
+	return json.Marshal(legacy)
+}
+
+// RequestIDConfig is the rule config for request-id plugin.
+// +k8s:deepcopy-gen=true
+type RequestIDConfig struct {
+	HeaderName        string `json:"header_name"`
+	IncludeInResponse bool   `json:"include_in_response"`
+	Algorithm         string `json:"algorithm"`
+}
+
+// ServerlessConfig is the rule config for serverless-pre-function and
+// serverless-post-function plugins.
+// +k8s:deepcopy-gen=true
+type ServerlessConfig struct {
+	Phase     string   `json:"phase"`
+	Functions []string `json:"functions"`
+}
+
 // RedirectConfig is the rule config for redirect plugin.
 // +k8s:deepcopy-gen=true
 type RedirectConfig struct {
@@ -152,3 +326,9 @@ type BasicAuthConfig struct {
 // +k8s:deepcopy-gen=true
 type KeyAuthConfig struct {
 }
+
+// ConsumerRestrictionConfig is the rule config for consumer-restriction plugin.
+// +k8s:deepcopy-gen=true
+type ConsumerRestrictionConfig struct {
+	Whitelist []string `json:"whitelist,omitempty"`
+}
@@ -18,9 +18,12 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/apache/apisix-ingress-controller/pkg/id"
 )
 
 const (
@@ -55,6 +58,17 @@ const (
 	// SchemeGRPCS represents the GRPCS protocol.
 	SchemeGRPCS = "grpcs"
 
+	// DiscoveryTypeDNS resolves the upstream nodes with the DNS service
+	// discovery of APISIX.
+	DiscoveryTypeDNS = "dns"
+
+	// PassHostPass passes the client request Host to the upstream.
+	PassHostPass = "pass"
+	// PassHostNode passes the upstream node address as the Host.
+	PassHostNode = "node"
+	// PassHostRewrite passes the configured upstream_host as the Host.
+	PassHostRewrite = "rewrite"
+
 	// HealthCheckHTTP represents the HTTP kind health check.
 	HealthCheckHTTP = "http"
 	// HealthCheckHTTPS represents the HTTPS kind health check.
@@ -181,21 +195,53 @@ func (p *Plugins) DeepCopy() *Plugins {
 type Upstream struct {
 	Metadata `json:",inline" yaml:",inline"`
 
-	Type    string               `json:"type,omitempty" yaml:"type,omitempty"`
-	HashOn  string               `json:"hash_on,omitempty" yaml:"hash_on,omitempty"`
-	Key     string               `json:"key,omitempty" yaml:"key,omitempty"`
-	Checks  *UpstreamHealthCheck `json:"checks,omitempty" yaml:"checks,omitempty"`
-	Nodes   UpstreamNodes        `json:"nodes" yaml:"nodes"`
-	Scheme  string               `json:"scheme,omitempty" yaml:"scheme,omitempty"`
-	Retries *int                 `json:"retries,omitempty" yaml:"retries,omitempty"`
-	Timeout *UpstreamTimeout     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
-	TLS     *ClientTLS           `json:"tls,omitempty" yaml:"tls,omitempty"`
+	Type         string               `json:"type,omitempty" yaml:"type,omitempty"`
+	HashOn       string               `json:"hash_on,omitempty" yaml:"hash_on,omitempty"`
+	Key          string               `json:"key,omitempty" yaml:"key,omitempty"`
+	Checks       *UpstreamHealthCheck `json:"checks,omitempty" yaml:"checks,omitempty"`
+	Nodes        UpstreamNodes        `json:"nodes" yaml:"nodes"`
+	Scheme       string               `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	PassHost     string               `json:"pass_host,omitempty" yaml:"pass_host,omitempty"`
+	UpstreamHost string               `json:"upstream_host,omitempty" yaml:"upstream_host,omitempty"`
+	Retries      *int                 `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryTimeout *int                 `json:"retry_timeout,omitempty" yaml:"retry_timeout,omitempty"`
+	Timeout      *UpstreamTimeout     `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	TLS          *ClientTLS           `json:"tls,omitempty" yaml:"tls,omitempty"`
+	// KeepalivePool is the keepalive connection pool to the upstream,
+	// the APISIX default is used if it's nil.
+	KeepalivePool *UpstreamKeepalivePool `json:"keepalive_pool,omitempty" yaml:"keepalive_pool,omitempty"`
+	// DiscoveryType is the service discovery which resolves the nodes by
+	// the ServiceName, the Nodes are ignored if it's set.
+	DiscoveryType string `json:"discovery_type,omitempty" yaml:"discovery_type,omitempty"`
+	// ServiceName is the name of the service in the service discovery.
+	ServiceName string `json:"service_name,omitempty" yaml:"service_name,omitempty"`
+	// DiscoveryArgs are the arguments of the service discovery.
+	DiscoveryArgs map[string]string `json:"discovery_args,omitempty" yaml:"discovery_args,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface. APISIX doesn't accept
+// the nodes together with the service discovery, so they are omitted if the
+// discovery type is set.
+func (up Upstream) MarshalJSON() ([]byte, error) {
+	type upstream Upstream
+	if up.DiscoveryType == "" {
+		return json.Marshal((*upstream)(&up))
+	}
+	return json.Marshal(&struct {
+		*upstream
+		Nodes UpstreamNodes `json:"nodes,omitempty"`
+	}{
+		upstream: (*upstream)(&up),
+	})
 }
 
 // ClientTLS is tls cert and key use in mTLS
+// +k8s:deepcopy-gen=true
 type ClientTLS struct {
 	Cert string `json:"client_cert,omitempty" yaml:"client_cert,omitempty"`
 	Key  string `json:"client_key,omitempty" yaml:"client_key,omitempty"`
+	// Verify turns on the verification of the upstream server certificate.
+	Verify *bool `json:"verify,omitempty" yaml:"verify,omitempty"`
 }
 
 // UpstreamTimeout represents the timeout settings on Upstream.
@@ -208,6 +254,17 @@ type UpstreamTimeout struct {
 	Read int `json:"read" yaml:"read"`
 }
 
+// UpstreamKeepalivePool represents the keepalive connection pool settings on
+// Upstream, zero values are omitted so the APISIX defaults are used.
+type UpstreamKeepalivePool struct {
+	// Size is the maximum number of idle connections kept in the pool.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+	// IdleTimeout is the idle timeout of the connections in seconds.
+	IdleTimeout int `json:"idle_timeout,omitempty" yaml:"idle_timeout,omitempty"`
+	// Requests is the maximum number of requests served by a connection.
+	Requests int `json:"requests,omitempty" yaml:"requests,omitempty"`
+}
+
 // UpstreamNodes is the upstream node list.
 type UpstreamNodes []UpstreamNode
 
@@ -231,6 +288,28 @@ func (n *UpstreamNodes) UnmarshalJSON(p []byte) error {
 	return nil
 }
 
+// Normalize sorts the nodes by host and port and removes the duplicated
+// ones (with the same host and port, the first one wins), so the same set
+// of nodes is always encoded in the same way.
+func (n UpstreamNodes) Normalize() UpstreamNodes {
+	nodes := make(UpstreamNodes, len(n))
+	copy(nodes, n)
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Host != nodes[j].Host {
+			return nodes[i].Host < nodes[j].Host
+		}
+		return nodes[i].Port < nodes[j].Port
+	})
+	deduped := nodes[:0]
+	for i, node := range nodes {
+		if i > 0 && node.Host == nodes[i-1].Host && node.Port == nodes[i-1].Port {
+			continue
+		}
+		deduped = append(deduped, node)
+	}
+	return deduped
+}
+
 // UpstreamNode is the node in upstream
 // +k8s:deepcopy-gen=true
 type UpstreamNode struct {
@@ -346,6 +425,16 @@ type GlobalRule struct {
 	Plugins Plugins `json:"plugins,omitempty" yaml:"plugins,omitempty"`
 }
 
+// PluginMetadata represents the plugin_metadata object in APISIX, which is
+// shared by all the instances of the plugin.
+type PluginMetadata struct {
+	// Name is the plugin name, it's the ID of the object.
+	Name string `json:"-" yaml:"-"`
+	// Metadata is the content of the object, it's validated by the
+	// metadata schema of the plugin.
+	Metadata map[string]interface{} `json:"-" yaml:"-"`
+}
+
 // Consumer represents the consumer object in APISIX.
 // +k8s:deepcopy-gen=true
 type Consumer struct {
@@ -369,6 +458,21 @@ type UpstreamServiceRelation struct {
 	UpstreamName string `json:"upstream_name,omitempty" yaml:"upstream_name,omitempty"`
 }
 
+// ManagedByLabel is the label marking the APISIX objects created by
+// apisix-ingress-controller, only the objects with the value returned by
+// ManagedBy are garbage collected.
+const ManagedByLabel = "managed-by"
+
+// ManagedBy returns the value of the managed-by label, the ID prefix (see
+// id.SetPrefix) is appended so that the controllers with different prefixes
+// ignore the objects of each other.
+func ManagedBy() string {
+	if prefix := id.Prefix(); prefix != "" {
+		return "apisix-ingress-controller." + prefix
+	}
+	return "apisix-ingress-controller"
+}
+
 // NewDefaultUpstream returns an empty Upstream with default values.
 func NewDefaultUpstream() *Upstream {
 	return &Upstream{
@@ -379,7 +483,7 @@ func NewDefaultUpstream() *Upstream {
 		Metadata: Metadata{
 			Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 			Labels: map[string]string{
-				"managed-by": "apisix-ingress-controller",
+				ManagedByLabel: ManagedBy(),
 			},
 		},
 	}
@@ -391,7 +495,7 @@ func NewDefaultRoute() *Route {
 		Metadata: Metadata{
 			Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 			Labels: map[string]string{
-				"managed-by": "apisix-ingress-controller",
+				ManagedByLabel: ManagedBy(),
 			},
 		},
 	}
@@ -402,7 +506,7 @@ func NewDefaultStreamRoute() *StreamRoute {
 	return &StreamRoute{
 		Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
+			ManagedByLabel: ManagedBy(),
 		},
 	}
 }
@@ -412,7 +516,7 @@ func NewDefaultConsumer() *Consumer {
 	return &Consumer{
 		Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 		Labels: map[string]string{
-			"managed-by": "apisix-ingress-controller",
+			ManagedByLabel: ManagedBy(),
 		},
 	}
 }
@@ -423,7 +527,7 @@ func NewDefaultPluginConfig() *PluginConfig {
 		Metadata: Metadata{
 			Desc: "Created by apisix-ingress-controller, DO NOT modify it manually",
 			Labels: map[string]string{
-				"managed-by": "apisix-ingress-controller",
+				ManagedByLabel: ManagedBy(),
 			},
 		},
 		Plugins: make(Plugins),
@@ -457,6 +561,24 @@ func ComposeUpstreamName(namespace, name, subset string, port int32) string {
 	return buf.String()
 }
 
+// ComposeUpstreamID generates the upstream ID from the content hash of its
+// config, i.e. the whole upstream except the ID, the nodes and the labels, so
+// that the identical upstreams of different routes collapse to one object,
+// while the ones with any different config have their own. The nodes are
+// updated in place and don't change the ID.
+func ComposeUpstreamID(ups *Upstream) string {
+	if ups.Name == "" {
+		return ""
+	}
+	config := *ups
+	config.ID = ""
+	config.Labels = nil
+	config.Nodes = nil
+	// The fields are plain data, marshaling never fails.
+	data, _ := json.Marshal(&config)
+	return id.GenID(id.Upstream, string(data))
+}
+
 // ComposeRouteName uses namespace, name and rule name to compose
 // the route name.
 func ComposeRouteName(namespace, name string, rule string) string {
@@ -498,6 +620,12 @@ func ComposeConsumerName(namespace, name string) string {
 	p := make([]byte, 0, len(namespace)+len(name)+1)
 	buf := bytes.NewBuffer(p)
 
+	// The username is the ID of consumer, so it's prefixed like the
+	// other IDs.
+	if prefix := id.Prefix(); prefix != "" {
+		buf.WriteString(prefix)
+		buf.WriteString("_")
+	}
 	// TODO If APISIX modifies the consumer name schema, we can drop this.
 	buf.WriteString(strings.Replace(namespace, "-", "_", -1))
 	buf.WriteString("_")
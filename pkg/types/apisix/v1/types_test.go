@@ -0,0 +1,154 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamNodesNormalize(t *testing.T) {
+	nodes := UpstreamNodes{
+		{Host: "10.0.0.2", Port: 80, Weight: 100},
+		{Host: "10.0.0.1", Port: 8080, Weight: 100},
+		{Host: "10.0.0.1", Port: 80, Weight: 50},
+		{Host: "10.0.0.2", Port: 80, Weight: 10},
+	}
+	assert.Equal(t, UpstreamNodes{
+		{Host: "10.0.0.1", Port: 80, Weight: 50},
+		{Host: "10.0.0.1", Port: 8080, Weight: 100},
+		{Host: "10.0.0.2", Port: 80, Weight: 100},
+	}, nodes.Normalize())
+	// The original nodes are untouched.
+	assert.Equal(t, "10.0.0.2", nodes[0].Host)
+	assert.Len(t, nodes, 4)
+
+	assert.Equal(t, UpstreamNodes{}, UpstreamNodes{}.Normalize())
+}
+
+func TestTrafficSplitUpstreamIDs(t *testing.T) {
+	cfg := &TrafficSplitConfig{
+		Rules: []TrafficSplitConfigRule{
+			{
+				WeightedUpstreams: []TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: "1", Weight: 10},
+					{UpstreamID: "2", Weight: 5},
+					// The default upstream of the route.
+					{Weight: 5},
+				},
+			},
+			{
+				WeightedUpstreams: []TrafficSplitConfigRuleWeightedUpstream{
+					{UpstreamID: "2", Weight: 10},
+				},
+			},
+		},
+	}
+	r := &Route{Plugins: Plugins{"traffic-split": cfg}}
+	assert.Equal(t, []string{"1", "2"}, TrafficSplitUpstreamIDs(r))
+
+	// The plugin config is a generic map after deep copying.
+	assert.Equal(t, []string{"1", "2"}, TrafficSplitUpstreamIDs(r.DeepCopy()))
+
+	assert.Nil(t, TrafficSplitUpstreamIDs(&Route{}))
+	r = &Route{Plugins: Plugins{"traffic-split": "invalid"}}
+	assert.Nil(t, TrafficSplitUpstreamIDs(r))
+}
+
+func TestComposeUpstreamID(t *testing.T) {
+	newUpstream := func() *Upstream {
+		ups := NewDefaultUpstream()
+		ups.Name = "default_httpbin_80"
+		ups.Nodes = UpstreamNodes{{Host: "10.0.0.1", Port: 80, Weight: 100}}
+		return ups
+	}
+	ups := newUpstream()
+	upsID := ComposeUpstreamID(ups)
+	assert.NotEmpty(t, upsID)
+	assert.Equal(t, upsID, ComposeUpstreamID(newUpstream()))
+
+	// The nodes are updated in place.
+	ups.Nodes = UpstreamNodes{{Host: "10.0.0.2", Port: 80, Weight: 100}}
+	assert.Equal(t, upsID, ComposeUpstreamID(ups))
+
+	ups = newUpstream()
+	ups.Name = "default_httpbin_443"
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.Scheme = SchemeHTTPS
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.Type = LbConsistentHash
+	ups.HashOn = HashOnHeader
+	ups.Key = "X-User"
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.Checks = &UpstreamHealthCheck{
+		Active: &UpstreamActiveHealthCheck{Type: "http"},
+	}
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.Timeout = &UpstreamTimeout{Connect: 5, Send: 10, Read: 10}
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.TLS = &ClientTLS{}
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.PassHost = PassHostRewrite
+	ups.UpstreamHost = "httpbin.org"
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+	ups = newUpstream()
+	ups.DiscoveryType = "dns"
+	ups.ServiceName = "httpbin.default.svc.cluster.local:80"
+	assert.NotEqual(t, upsID, ComposeUpstreamID(ups))
+
+	// The labels don't change the ID either.
+	ups = newUpstream()
+	ups.Labels = map[string]string{"meta_port": "80"}
+	assert.Equal(t, upsID, ComposeUpstreamID(ups))
+
+	assert.Equal(t, "", ComposeUpstreamID(&Upstream{}))
+}
+
+func TestReplaceRouteUpstreamID(t *testing.T) {
+	r := &Route{
+		UpstreamId: "1",
+		Plugins: Plugins{
+			"traffic-split": &TrafficSplitConfig{
+				Rules: []TrafficSplitConfigRule{
+					{
+						WeightedUpstreams: []TrafficSplitConfigRuleWeightedUpstream{
+							{UpstreamID: "2", Weight: 10},
+							{Weight: 5},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.True(t, ReplaceRouteUpstreamID(r, "1", "3"))
+	assert.Equal(t, "3", r.UpstreamId)
+	assert.Equal(t, []string{"2"}, TrafficSplitUpstreamIDs(r))
+
+	// The plugin config is a generic map after deep copying.
+	r = r.DeepCopy()
+	assert.True(t, ReplaceRouteUpstreamID(r, "2", "4"))
+	assert.Equal(t, "3", r.UpstreamId)
+	assert.Equal(t, []string{"4"}, TrafficSplitUpstreamIDs(r))
+
+	assert.False(t, ReplaceRouteUpstreamID(r, "5", "6"))
+	assert.False(t, ReplaceRouteUpstreamID(&Route{}, "1", "2"))
+}
@@ -68,6 +68,48 @@ func (in *BasicAuthRouteConfig) DeepCopy() *BasicAuthRouteConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyTransformerConfig) DeepCopyInto(out *BodyTransformerConfig) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(BodyTransformerTemplate)
+		**out = **in
+	}
+	if in.Response != nil {
+		in, out := &in.Response, &out.Response
+		*out = new(BodyTransformerTemplate)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyTransformerConfig.
+func (in *BodyTransformerConfig) DeepCopy() *BodyTransformerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyTransformerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BodyTransformerTemplate) DeepCopyInto(out *BodyTransformerTemplate) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BodyTransformerTemplate.
+func (in *BodyTransformerTemplate) DeepCopy() *BodyTransformerTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(BodyTransformerTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CSRFConfig) DeepCopyInto(out *CSRFConfig) {
 	*out = *in
@@ -84,6 +126,27 @@ func (in *CSRFConfig) DeepCopy() *CSRFConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientTLS) DeepCopyInto(out *ClientTLS) {
+	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientTLS.
+func (in *ClientTLS) DeepCopy() *ClientTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Consumer) DeepCopyInto(out *Consumer) {
 	*out = *in
@@ -108,9 +171,35 @@ func (in *Consumer) DeepCopy() *Consumer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConsumerRestrictionConfig) DeepCopyInto(out *ConsumerRestrictionConfig) {
+	*out = *in
+	if in.Whitelist != nil {
+		in, out := &in.Whitelist, &out.Whitelist
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConsumerRestrictionConfig.
+func (in *ConsumerRestrictionConfig) DeepCopy() *ConsumerRestrictionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ConsumerRestrictionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CorsConfig) DeepCopyInto(out *CorsConfig) {
 	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(int)
+		**out = **in
+	}
 	return
 }
 
@@ -124,6 +213,29 @@ func (in *CorsConfig) DeepCopy() *CorsConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileLoggerConfig) DeepCopyInto(out *FileLoggerConfig) {
+	*out = *in
+	if in.LogFormat != nil {
+		in, out := &in.LogFormat, &out.LogFormat
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileLoggerConfig.
+func (in *FileLoggerConfig) DeepCopy() *FileLoggerConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(FileLoggerConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ForwardAuthConfig) DeepCopyInto(out *ForwardAuthConfig) {
 	*out = *in
@@ -267,6 +379,27 @@ func (in *KeyAuthConsumerConfig) DeepCopy() *KeyAuthConsumerConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LimitCountConfig) DeepCopyInto(out *LimitCountConfig) {
+	*out = *in
+	if in.RedisClusterNode != nil {
+		in, out := &in.RedisClusterNode, &out.RedisClusterNode
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LimitCountConfig.
+func (in *LimitCountConfig) DeepCopy() *LimitCountConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(LimitCountConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
@@ -340,6 +473,76 @@ func (in *RedirectConfig) DeepCopy() *RedirectConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequestIDConfig) DeepCopyInto(out *RequestIDConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequestIDConfig.
+func (in *RequestIDConfig) DeepCopy() *RequestIDConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RequestIDConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseRewriteConfig) DeepCopyInto(out *ResponseRewriteConfig) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = new(ResponseRewriteHeaders)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseRewriteConfig.
+func (in *ResponseRewriteConfig) DeepCopy() *ResponseRewriteConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseRewriteConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResponseRewriteHeaders) DeepCopyInto(out *ResponseRewriteHeaders) {
+	*out = *in
+	if in.Add != nil {
+		in, out := &in.Add, &out.Add
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResponseRewriteHeaders.
+func (in *ResponseRewriteHeaders) DeepCopy() *ResponseRewriteHeaders {
+	if in == nil {
+		return nil
+	}
+	out := new(ResponseRewriteHeaders)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RewriteConfig) DeepCopyInto(out *RewriteConfig) {
 	*out = *in
@@ -417,6 +620,27 @@ func (in *Route) DeepCopy() *Route {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerlessConfig) DeepCopyInto(out *ServerlessConfig) {
+	*out = *in
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerlessConfig.
+func (in *ServerlessConfig) DeepCopy() *ServerlessConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServerlessConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Ssl) DeepCopyInto(out *Ssl) {
 	*out = *in
@@ -525,6 +749,13 @@ func (in *TrafficSplitConfig) DeepCopy() *TrafficSplitConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficSplitConfigRule) DeepCopyInto(out *TrafficSplitConfigRule) {
 	*out = *in
+	if in.Match != nil {
+		in, out := &in.Match, &out.Match
+		*out = make([]TrafficSplitConfigRuleMatch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.WeightedUpstreams != nil {
 		in, out := &in.WeightedUpstreams, &out.WeightedUpstreams
 		*out = make([]TrafficSplitConfigRuleWeightedUpstream, len(*in))
@@ -543,6 +774,35 @@ func (in *TrafficSplitConfigRule) DeepCopy() *TrafficSplitConfigRule {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficSplitConfigRuleMatch) DeepCopyInto(out *TrafficSplitConfigRuleMatch) {
+	*out = *in
+	if in.Vars != nil {
+		in, out := &in.Vars, &out.Vars
+		*out = make(Vars, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = make([]StringOrSlice, len(*in))
+				for i := range *in {
+					(*in)[i].DeepCopyInto(&(*out)[i])
+				}
+			}
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficSplitConfigRuleMatch.
+func (in *TrafficSplitConfigRuleMatch) DeepCopy() *TrafficSplitConfigRuleMatch {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficSplitConfigRuleMatch)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficSplitConfigRuleWeightedUpstream) DeepCopyInto(out *TrafficSplitConfigRuleWeightedUpstream) {
 	*out = *in
@@ -578,6 +838,11 @@ func (in *Upstream) DeepCopyInto(out *Upstream) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.RetryTimeout != nil {
+		in, out := &in.RetryTimeout, &out.RetryTimeout
+		*out = new(int)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(UpstreamTimeout)
@@ -586,8 +851,20 @@ func (in *Upstream) DeepCopyInto(out *Upstream) {
 	if in.TLS != nil {
 		in, out := &in.TLS, &out.TLS
 		*out = new(ClientTLS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KeepalivePool != nil {
+		in, out := &in.KeepalivePool, &out.KeepalivePool
+		*out = new(UpstreamKeepalivePool)
 		**out = **in
 	}
+	if in.DiscoveryArgs != nil {
+		in, out := &in.DiscoveryArgs, &out.DiscoveryArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	return
 }
 
@@ -51,4 +51,7 @@ type Event struct {
 	// Tombstone is the final state before object was delete,
 	// it's useful for DELETE event.
 	Tombstone interface{}
+	// Snapshot is the state of the APISIX clusters fetched by the periodic resync,
+	// it's only set in the events generated by the periodic resync.
+	Snapshot interface{}
 }
@@ -169,6 +169,7 @@ rules:
       - apisixconsumers/status
       - apisixpluginconfigs
       - apisixpluginconfigs/status
+      - apisixratelimitpolicies
     verbs:
       - '*'
   - apiGroups:
@@ -190,12 +191,23 @@ rules:
     resources:
     - httproutes
     - tlsroutes
+    - backendtlspolicies
     - gateways
     - gatewayclasses
     verbs:
     - get
     - list
     - watch
+  - apiGroups:
+    - gateway.networking.k8s.io
+    resources:
+    - httproutes/status
+    - tlsroutes/status
+    - gateways/status
+    - gatewayclasses/status
+    verbs:
+    - get
+    - update
 `
 	_clusterRoleBinding = `
 apiVersion: rbac.authorization.k8s.io/v1
@@ -388,6 +400,21 @@ webhooks:
         resources: ["apisixupstreams"]
     timeoutSeconds: 30
     failurePolicy: Fail
+  - name: apisixpluginconfig-validator-webhook.apisix.apache.org
+    clientConfig:
+      service:
+        name: webhook
+        namespace: %s
+        port: 8443
+        path: "/validation/apisixpluginconfigs"
+      caBundle: %s
+    rules:
+      - operations: [ "CREATE", "UPDATE" ]
+        apiGroups: ["apisix.apache.org"]
+        apiVersions: ["*"]
+        resources: ["apisixpluginconfigs"]
+    timeoutSeconds: 30
+    failurePolicy: Fail
 `
 	_webhookCertSecret = "webhook-certs"
 	_volumeMounts      = `volumeMounts:
@@ -443,7 +470,7 @@ func (s *Scaffold) newIngressAPISIXController() error {
 		assert.True(s.t, ok, "get cert.pem from the secret")
 		caBundle := base64.StdEncoding.EncodeToString(cert)
 
-		webhookReg := fmt.Sprintf(_ingressAPISIXAdmissionWebhook, s.namespace, caBundle, s.namespace, caBundle, s.namespace, caBundle, s.namespace, caBundle)
+		webhookReg := fmt.Sprintf(_ingressAPISIXAdmissionWebhook, s.namespace, caBundle, s.namespace, caBundle, s.namespace, caBundle, s.namespace, caBundle, s.namespace, caBundle)
 		ginkgo.GinkgoT().Log(webhookReg)
 		err = k8s.KubectlApplyFromStringE(s.t, s.kubectlOptions, webhookReg)
 		assert.Nil(s.t, err, "create webhook registration")
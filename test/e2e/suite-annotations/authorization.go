@@ -169,6 +169,90 @@ spec:
 				Status(http.StatusOK)
 		})
 
+		ginkgo.It("restrict keyAuth to consumers in ingress networking/v1", func() {
+			err := s.ApisixConsumerKeyAuthCreated("foo", "bar")
+			assert.Nil(ginkgo.GinkgoT(), err, "creating keyAuth ApisixConsumer")
+			err = s.ApisixConsumerKeyAuthCreated("foo2", "bar2")
+			assert.Nil(ginkgo.GinkgoT(), err, "creating keyAuth ApisixConsumer")
+
+			// Wait until the ApisixConsumer create event was delivered.
+			time.Sleep(6 * time.Second)
+
+			backendSvc, backendPort := s.DefaultHTTPBackend()
+			ing := fmt.Sprintf(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: apisix
+    k8s.apisix.apache.org/auth-type: "keyAuth"
+    k8s.apisix.apache.org/auth-consumers: "foo"
+  name: ingress-v1
+spec:
+  rules:
+  - host: httpbin.org
+    http:
+      paths:
+      - path: /ip
+        pathType: Exact
+        backend:
+          service:
+            name: %s
+            port:
+              number: %d
+`, backendSvc, backendPort[0])
+			err = s.CreateResourceFromString(ing)
+			assert.Nil(ginkgo.GinkgoT(), err, "creating ingress")
+			time.Sleep(5 * time.Second)
+
+			_ = s.NewAPISIXClient().GET("/ip").
+				WithHeader("Host", "httpbin.org").
+				WithHeader("apikey", "bar").
+				Expect().
+				Status(http.StatusOK)
+			msg403 := s.NewAPISIXClient().GET("/ip").
+				WithHeader("Host", "httpbin.org").
+				WithHeader("apikey", "bar2").
+				Expect().
+				Status(http.StatusForbidden).
+				Body().
+				Raw()
+			assert.Contains(ginkgo.GinkgoT(), msg403, "The consumer_name is forbidden")
+		})
+
+		ginkgo.It("reject ingress with unknown auth consumers", func() {
+			backendSvc, backendPort := s.DefaultHTTPBackend()
+			ing := fmt.Sprintf(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: apisix
+    k8s.apisix.apache.org/auth-type: "keyAuth"
+    k8s.apisix.apache.org/auth-consumers: "nobody"
+  name: ingress-v1
+spec:
+  rules:
+  - host: httpbin.org
+    http:
+      paths:
+      - path: /ip
+        pathType: Exact
+        backend:
+          service:
+            name: %s
+            port:
+              number: %d
+`, backendSvc, backendPort[0])
+			err := s.CreateResourceFromString(ing)
+			assert.Nil(ginkgo.GinkgoT(), err, "creating ingress")
+			time.Sleep(5 * time.Second)
+
+			routes, err := s.ListApisixRoutes()
+			assert.Nil(ginkgo.GinkgoT(), err)
+			assert.Len(ginkgo.GinkgoT(), routes, 0)
+		})
+
 		ginkgo.It("enable basicAuth in ingress networking/v1", func() {
 			err := s.ApisixConsumerBasicAuthCreated("jack1", "jack1-username", "jack1-password")
 			assert.Nil(ginkgo.GinkgoT(), err, "creating keyAuth ApisixConsumer")
@@ -0,0 +1,99 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package features
+
+import (
+	"fmt"
+	"net/http"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/test/e2e/scaffold"
+)
+
+var _ = ginkgo.Describe("suite-features: forwardAuth", func() {
+	opts := &scaffold.Options{
+		Name:                  "default",
+		Kubeconfig:            scaffold.GetKubeconfig(),
+		APISIXConfigPath:      "testdata/apisix-gw-config.yaml",
+		IngressAPISIXReplicas: 1,
+		HTTPBinServicePort:    80,
+		APISIXRouteVersion:    "apisix.apache.org/v2",
+	}
+	s := scaffold.NewScaffold(opts)
+	ginkgo.JustBeforeEach(func() {
+		// create an external auth service, it allows the requests with
+		// the "Authorization: 123" header and rejects the others.
+		json := `{
+			"uri":"/auth",
+			"plugins":{
+				"serverless-pre-function":{
+					"phase":"rewrite",
+					"functions":[
+						"return function (conf, ctx)\n    local core = require(\"apisix.core\");\n    local authorization = core.request.header(ctx, \"Authorization\");\n    if authorization == \"123\" then\n        core.response.set_header(\"X-User-ID\", \"i-am-user\");\n        core.response.exit(200);\n    else core.response.set_header(\"Location\", \"http://example.com/auth\");\n        core.response.exit(403);\n    end\nend"
+					]
+				}
+			}
+		}`
+		assert.Nil(ginkgo.GinkgoT(), s.CreateApisixRouteByApisixAdmin("auth", []byte(json)), "create forward-auth serverless route")
+	})
+
+	ginkgo.JustAfterEach(func() {
+		assert.Nil(ginkgo.GinkgoT(), s.DeleteApisixRouteByApisixAdmin("auth"), "clean up forward-auth serverless route")
+	})
+
+	ginkgo.It("the auth service decides whether the request is proxied", func() {
+		backendSvc, backendPorts := s.DefaultHTTPBackend()
+		ar := fmt.Sprintf(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+  name: httpbin-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - httpbin.org
+      paths:
+      - /headers
+    backends:
+    - serviceName: %s
+      servicePort: %d
+    forwardAuth:
+      uri: http://127.0.0.1:9080/auth
+      requestHeaders:
+      - Authorization
+      upstreamHeaders:
+      - X-User-ID
+      clientHeaders:
+      - Location
+`, backendSvc, backendPorts[0])
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(ar))
+		err := s.EnsureNumApisixRoutesCreated(2)
+		assert.Nil(ginkgo.GinkgoT(), err, "checking number of routes")
+
+		resp := s.NewAPISIXClient().GET("/headers").WithHeader("Host", "httpbin.org").WithHeader("Authorization", "123").Expect()
+		resp.Status(http.StatusOK)
+		resp.Body().Contains("i-am-user")
+
+		resp = s.NewAPISIXClient().GET("/headers").WithHeader("Host", "httpbin.org").Expect()
+		resp.Status(http.StatusForbidden)
+		resp.Headers().ContainsMap(map[string]interface{}{
+			"Location": []string{"http://example.com/auth"},
+		})
+	})
+})
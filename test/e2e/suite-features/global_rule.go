@@ -68,7 +68,7 @@ spec:
 			grs, err := s.ListApisixGlobalRules()
 			assert.Nil(ginkgo.GinkgoT(), err, "listing global_rules")
 			assert.Len(ginkgo.GinkgoT(), grs, 1)
-			assert.Equal(ginkgo.GinkgoT(), grs[0].ID, id.GenID("default"))
+			assert.Equal(ginkgo.GinkgoT(), grs[0].ID, id.GenID(id.GlobalRule, "default"))
 			assert.Len(ginkgo.GinkgoT(), grs[0].Plugins, 1)
 			_, ok := grs[0].Plugins["prometheus"]
 			assert.Equal(ginkgo.GinkgoT(), ok, true)
@@ -0,0 +1,112 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package features
+
+import (
+	"io/ioutil"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/test/e2e/scaffold"
+	"github.com/apache/apisix-ingress-controller/test/e2e/testbackend/client"
+)
+
+var _ = ginkgo.Describe("suite-features: grpc match", func() {
+	opts := &scaffold.Options{
+		Name:                  "default",
+		Kubeconfig:            scaffold.GetKubeconfig(),
+		APISIXConfigPath:      "testdata/apisix-gw-config.yaml",
+		IngressAPISIXReplicas: 1,
+		HTTPBinServicePort:    80,
+		APISIXRouteVersion:    "apisix.apache.org/v2",
+	}
+	s := scaffold.NewScaffold(opts)
+
+	ginkgo.It("match grpc methods", func() {
+		f, err := ioutil.ReadFile("testbackend/tls/server.pem")
+		assert.NoError(ginkgo.GinkgoT(), err, "read server cert")
+		serverCert := string(f)
+		f, err = ioutil.ReadFile("testbackend/tls/server.key")
+		assert.NoError(ginkgo.GinkgoT(), err, "read server key")
+		serverKey := string(f)
+		assert.NoError(ginkgo.GinkgoT(), s.NewSecret("grpc-secret", serverCert, serverKey), "create server cert secret")
+
+		assert.NoError(ginkgo.GinkgoT(), s.CreateResourceFromString(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixUpstream
+metadata:
+  name: test-backend-service-e2e-test
+spec:
+  scheme: grpcs
+`))
+		assert.NoError(ginkgo.GinkgoT(), s.CreateResourceFromString(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+ name: grpc-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - e2e.apisix.local
+      grpc:
+        service: helloworld.Greeter
+        methods:
+        - SayHello
+    backends:
+    -  serviceName: test-backend-service-e2e-test
+       servicePort: 50052
+`))
+		assert.NoError(ginkgo.GinkgoT(), s.NewApisixTls("grpc-secret", "e2e.apisix.local", "grpc-secret"))
+		assert.NoError(ginkgo.GinkgoT(), s.EnsureNumApisixRoutesCreated(1))
+
+		routes, err := s.ListApisixRoutes()
+		assert.Nil(ginkgo.GinkgoT(), err)
+		assert.Len(ginkgo.GinkgoT(), routes, 1)
+		assert.Equal(ginkgo.GinkgoT(), []string{"/helloworld.Greeter/SayHello"}, routes[0].Uris)
+		assert.Equal(ginkgo.GinkgoT(), []string{"POST"}, routes[0].Methods)
+
+		ca, err := ioutil.ReadFile("testbackend/tls/ca.pem")
+		assert.NoError(ginkgo.GinkgoT(), err, "read ca cert")
+		assert.NoError(ginkgo.GinkgoT(), client.RequestHello(s.GetAPISIXHTTPSEndpoint(), ca), "request apisix using grpc protocol")
+	})
+
+	ginkgo.It("reject non-grpc upstream", func() {
+		assert.NoError(ginkgo.GinkgoT(), s.CreateResourceFromString(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+ name: grpc-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - e2e.apisix.local
+      grpc:
+        service: helloworld.Greeter
+    backends:
+    -  serviceName: test-backend-service-e2e-test
+       servicePort: 80
+`))
+		time.Sleep(6 * time.Second)
+		routes, err := s.ListApisixRoutes()
+		assert.Nil(ginkgo.GinkgoT(), err)
+		assert.Len(ginkgo.GinkgoT(), routes, 0)
+	})
+})
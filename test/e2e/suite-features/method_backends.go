@@ -0,0 +1,79 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package features
+
+import (
+	"fmt"
+	"net/http"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/test/e2e/scaffold"
+)
+
+var _ = ginkgo.Describe("suite-features: method backends", func() {
+	opts := &scaffold.Options{
+		Name:                  "default",
+		Kubeconfig:            scaffold.GetKubeconfig(),
+		APISIXConfigPath:      "testdata/apisix-gw-config.yaml",
+		IngressAPISIXReplicas: 1,
+		HTTPBinServicePort:    80,
+		APISIXRouteVersion:    "apisix.apache.org/v2",
+	}
+	s := scaffold.NewScaffold(opts)
+	ginkgo.It("GET and POST reach different backends", func() {
+		backendSvc, backendPorts := s.DefaultHTTPBackend()
+		adminSvc, adminPort := s.ApisixAdminServiceAndPort()
+		ar := fmt.Sprintf(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+ name: httpbin-route
+spec:
+ http:
+ - name: rule1
+   match:
+     hosts:
+     - httpbin.org
+     paths:
+       - /get
+   backends:
+   - serviceName: %s
+     servicePort: %d
+   methodBackends:
+   - methods:
+     - POST
+     - PUT
+     backend:
+       serviceName: %s
+       servicePort: %d
+`, backendSvc, backendPorts[0], adminSvc, adminPort)
+
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(ar))
+
+		err := s.EnsureNumApisixUpstreamsCreated(2)
+		assert.Nil(ginkgo.GinkgoT(), err, "Checking number of upstreams")
+		err = s.EnsureNumApisixRoutesCreated(1)
+		assert.Nil(ginkgo.GinkgoT(), err, "Checking number of routes")
+
+		// GET requests are sent to httpbin, 200 will be given.
+		s.NewAPISIXClient().GET("/get").WithHeader("Host", "httpbin.org").Expect().
+			Status(http.StatusOK).Body().Contains("origin")
+		// POST requests are sent to http-admin, 404 will be given.
+		s.NewAPISIXClient().POST("/get").WithHeader("Host", "httpbin.org").Expect().
+			Status(http.StatusNotFound)
+	})
+})
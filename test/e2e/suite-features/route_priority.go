@@ -0,0 +1,111 @@
+// Licensed to the Apache Software Foundation (ASF) under one or more
+// contributor license agreements.  See the NOTICE file distributed with
+// this work for additional information regarding copyright ownership.
+// The ASF licenses this file to You under the Apache License, Version 2.0
+// (the "License"); you may not use this file except in compliance with
+// the License.  You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+package features
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	ginkgo "github.com/onsi/ginkgo/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/apache/apisix-ingress-controller/test/e2e/scaffold"
+)
+
+var _ = ginkgo.Describe("suite-features: route priority", func() {
+	opts := &scaffold.Options{
+		Name:                  "default",
+		Kubeconfig:            scaffold.GetKubeconfig(),
+		APISIXConfigPath:      "testdata/apisix-gw-config.yaml",
+		IngressAPISIXReplicas: 1,
+		HTTPBinServicePort:    80,
+		APISIXRouteVersion:    "apisix.apache.org/v2",
+	}
+	s := scaffold.NewScaffold(opts)
+	ginkgo.It("overlapping routes are resolved by priority", func() {
+		backendSvc, backendPorts := s.DefaultHTTPBackend()
+		stable := fmt.Sprintf(`
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+ name: httpbin-stable
+spec:
+ http:
+ - name: rule1
+   priority: 1
+   match:
+     hosts:
+     - httpbin.org
+     paths:
+       - /ip
+   backends:
+   - serviceName: %s
+     servicePort: %d
+`, backendSvc, backendPorts[0])
+		maintenanceTemplate := `
+apiVersion: apisix.apache.org/v2
+kind: ApisixRoute
+metadata:
+ name: httpbin-maintenance
+spec:
+ http:
+ - name: rule1
+   priority: %d
+   match:
+     hosts:
+     - httpbin.org
+     paths:
+       - /ip
+   backends:
+   - serviceName: %s
+     servicePort: %d
+   plugins:
+   - name: fault-injection
+     enable: true
+     config:
+       abort:
+         http_status: 503
+         body: "under maintenance"
+`
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(stable))
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(fmt.Sprintf(maintenanceTemplate, 2, backendSvc, backendPorts[0])))
+		err := s.EnsureNumApisixRoutesCreated(2)
+		assert.Nil(ginkgo.GinkgoT(), err, "Checking number of routes")
+
+		// The maintenance route has the higher priority.
+		for i := 0; i < 5; i++ {
+			s.NewAPISIXClient().GET("/ip").WithHeader("Host", "httpbin.org").Expect().
+				Status(http.StatusServiceUnavailable).
+				Body().Contains("under maintenance")
+		}
+
+		// Lower the priority to the default one, the stable route wins now.
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(fmt.Sprintf(maintenanceTemplate, 0, backendSvc, backendPorts[0])))
+		time.Sleep(6 * time.Second)
+		routes, err := s.ListApisixRoutes()
+		assert.Nil(ginkgo.GinkgoT(), err)
+		for _, r := range routes {
+			if r.Name == s.Namespace()+"_httpbin-maintenance_rule1" {
+				assert.Equal(ginkgo.GinkgoT(), 0, r.Priority)
+			}
+		}
+		for i := 0; i < 5; i++ {
+			s.NewAPISIXClient().GET("/ip").WithHeader("Host", "httpbin.org").Expect().
+				Status(http.StatusOK).
+				Body().Contains("origin")
+		}
+	})
+})
@@ -17,6 +17,7 @@ package features
 
 import (
 	"io/ioutil"
+	"net/http"
 	"time"
 
 	ginkgo "github.com/onsi/ginkgo/v2"
@@ -122,6 +123,52 @@ spec:
 		// assert.Equal(ginkgo.GinkgoT(), resp.Message, "Alex")
 	})
 
+	ginkgo.It("https", func() {
+		assert.NoError(ginkgo.GinkgoT(), s.CreateResourceFromString(`
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixUpstream
+metadata:
+  name: test-backend-service-e2e-test
+spec:
+  portLevelSettings:
+    - port: 443
+      scheme: https
+`))
+
+		assert.NoError(ginkgo.GinkgoT(), s.CreateResourceFromString(`
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixRoute
+metadata:
+ name: https-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - upstream-is-https.httpbin.local
+      paths:
+      - /hello
+    backends:
+    -  serviceName: test-backend-service-e2e-test
+       servicePort: 443
+    plugins:
+    - name: proxy-rewrite
+      enable: true
+      config:
+        host: e2e.apisix.local
+`))
+
+		time.Sleep(2 * time.Second)
+		ups, err := s.ListApisixUpstreams()
+		assert.Nil(ginkgo.GinkgoT(), err)
+		assert.Len(ginkgo.GinkgoT(), ups, 1)
+		assert.Equal(ginkgo.GinkgoT(), ups[0].Scheme, "https")
+
+		// TLS is terminated at the backend.
+		s.NewAPISIXClient().GET("/hello").WithHeader("Host", "upstream-is-https.httpbin.local").
+			Expect().Status(http.StatusOK)
+	})
+
 	ginkgo.It("grpcs", func() {
 		grpcSecret := `grpc-secret`
 		f, err := ioutil.ReadFile("testbackend/tls/server.pem")
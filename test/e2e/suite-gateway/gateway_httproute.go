@@ -146,4 +146,79 @@ spec:
 			Expect().
 			Status(http.StatusNotFound)
 	})
+
+	ginkgo.It("HTTPRoute attached to the Gateway by parentRefs", func() {
+		backendSvc, backendPorts := s.DefaultHTTPBackend()
+		gateway := fmt.Sprintf(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: GatewayClass
+metadata:
+  name: apisix-%s
+spec:
+  controllerName: apisix.apache.org/gateway-controller
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: Gateway
+metadata:
+  name: apisix-gateway
+spec:
+  gatewayClassName: apisix-%s
+  listeners:
+  - name: http
+    protocol: HTTP
+    port: 80
+`, s.Namespace(), s.Namespace())
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(gateway), "creating Gateway")
+		time.Sleep(time.Second * 15)
+
+		route := fmt.Sprintf(`
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: attached-http-route
+spec:
+  parentRefs:
+  - name: apisix-gateway
+  hostnames: ["httpbin.org"]
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /ip
+    backendRefs:
+    - name: %s
+      port: %d
+---
+apiVersion: gateway.networking.k8s.io/v1alpha2
+kind: HTTPRoute
+metadata:
+  name: detached-http-route
+spec:
+  parentRefs:
+  - name: unknown-gateway
+  hostnames: ["httpbin.org"]
+  rules:
+  - matches:
+    - path:
+        type: PathPrefix
+        value: /get
+    backendRefs:
+    - name: %s
+      port: %d
+`, backendSvc, backendPorts[0], backendSvc, backendPorts[0])
+
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(route), "creating HTTPRoute")
+		time.Sleep(time.Second * 6)
+		// Only the route attached to the Gateway is created.
+		assert.Nil(ginkgo.GinkgoT(), s.EnsureNumApisixRoutesCreated(1), "Checking number of routes")
+
+		_ = s.NewAPISIXClient().GET("/ip").
+			WithHeader("Host", "httpbin.org").
+			Expect().
+			Status(http.StatusOK)
+		_ = s.NewAPISIXClient().GET("/get").
+			WithHeader("Host", "httpbin.org").
+			Expect().
+			Status(http.StatusNotFound)
+	})
 })
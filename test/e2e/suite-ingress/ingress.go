@@ -197,7 +197,7 @@ spec:
 		apisixSsls, err := s.ListApisixSsl()
 		assert.Nil(ginkgo.GinkgoT(), err, "list SSLs error")
 		assert.Len(ginkgo.GinkgoT(), apisixSsls, 1, "SSL number should be 1")
-		assert.Equal(ginkgo.GinkgoT(), id.GenID(s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
+		assert.Equal(ginkgo.GinkgoT(), id.GenID(id.SSL, "ing_"+s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
 		assert.Equal(ginkgo.GinkgoT(), apisixSsls[0].Snis, []string{host}, "SSL configuration")
 
 		caCertPool := x509.NewCertPool()
@@ -251,7 +251,7 @@ spec:
 		apisixSsls, err := s.ListApisixSsl()
 		assert.Nil(ginkgo.GinkgoT(), err, "list SSLs error")
 		assert.Len(ginkgo.GinkgoT(), apisixSsls, 1, "SSL number should be 1")
-		assert.Equal(ginkgo.GinkgoT(), id.GenID(s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
+		assert.Equal(ginkgo.GinkgoT(), id.GenID(id.SSL, "ing_"+s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
 		assert.Equal(ginkgo.GinkgoT(), apisixSsls[0].Snis, []string{host}, "SSL configuration")
 
 		caCertPool := x509.NewCertPool()
@@ -305,7 +305,7 @@ spec:
 		apisixSsls, err := s.ListApisixSsl()
 		assert.Nil(ginkgo.GinkgoT(), err, "list SSLs error")
 		assert.Len(ginkgo.GinkgoT(), apisixSsls, 1, "SSL number should be 1")
-		assert.Equal(ginkgo.GinkgoT(), id.GenID(s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
+		assert.Equal(ginkgo.GinkgoT(), id.GenID(id.SSL, "ing_"+s.Namespace()+"_httpbin-ingress-https-tls"), apisixSsls[0].ID, "SSL name")
 		assert.Equal(ginkgo.GinkgoT(), apisixSsls[0].Snis, []string{host}, "SSL configuration")
 
 		caCertPool := x509.NewCertPool()
@@ -419,6 +419,108 @@ spec:
 		// Mismatched host
 		_ = s.NewAPISIXClient().GET("/anything/aaa/ok").WithHeader("Host", "a.httpbin.org").Expect().Status(http.StatusNotFound)
 	})
+
+	ginkgo.It("ImplementationSpecific path regex match without use-regex", func() {
+		backendSvc, backendPort := s.DefaultHTTPBackend()
+		ing := fmt.Sprintf(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: apisix
+  name: ingress-v1
+spec:
+  rules:
+  - host: httpbin.org
+    http:
+      paths:
+      - path: /status/2[0-9]{2}$
+        pathType: ImplementationSpecific
+        backend:
+          service:
+            name: %s
+            port:
+              number: %d
+`, backendSvc, backendPort[0])
+		err := s.CreateResourceFromString(ing)
+		assert.Nil(ginkgo.GinkgoT(), err, "creating ingress")
+		time.Sleep(5 * time.Second)
+
+		_ = s.NewAPISIXClient().GET("/status/200").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusOK)
+		_ = s.NewAPISIXClient().GET("/status/201").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusCreated)
+		_ = s.NewAPISIXClient().GET("/status/500").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusNotFound).Body().Contains("404 Route Not Found")
+		_ = s.NewAPISIXClient().GET("/status/2000").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusNotFound).Body().Contains("404 Route Not Found")
+	})
+
+	ginkgo.It("default backend catches the unmatched requests", func() {
+		backendSvc, backendPort := s.DefaultHTTPBackend()
+		ing := fmt.Sprintf(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: apisix
+  name: ingress-v1
+spec:
+  defaultBackend:
+    service:
+      name: %s
+      port:
+        number: %d
+  rules:
+  - host: httpbin.org
+    http:
+      paths:
+      - path: /ip
+        pathType: Exact
+        backend:
+          service:
+            name: %s
+            port:
+              number: %d
+`, backendSvc, backendPort[0], backendSvc, backendPort[0])
+		err := s.CreateResourceFromString(ing)
+		assert.Nil(ginkgo.GinkgoT(), err, "creating ingress")
+		time.Sleep(5 * time.Second)
+
+		routes, err := s.ListApisixRoutes()
+		assert.Nil(ginkgo.GinkgoT(), err)
+		assert.Len(ginkgo.GinkgoT(), routes, 2)
+
+		_ = s.NewAPISIXClient().GET("/ip").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusOK).Body().Contains("origin")
+		// Unmatched path and host.
+		_ = s.NewAPISIXClient().GET("/status/201").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusCreated)
+		_ = s.NewAPISIXClient().GET("/status/201").WithHeader("Host", "a.httpbin.org").Expect().Status(http.StatusCreated)
+
+		// A more specific route of another Ingress takes precedence.
+		ing2 := fmt.Sprintf(`
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  annotations:
+    kubernetes.io/ingress.class: apisix
+    k8s.apisix.apache.org/rewrite-target: /status/202
+  name: ingress-v1-2
+spec:
+  rules:
+  - host: a.httpbin.org
+    http:
+      paths:
+      - path: /status
+        pathType: Prefix
+        backend:
+          service:
+            name: %s
+            port:
+              number: %d
+`, backendSvc, backendPort[0])
+		err = s.CreateResourceFromString(ing2)
+		assert.Nil(ginkgo.GinkgoT(), err, "creating ingress")
+		time.Sleep(5 * time.Second)
+
+		_ = s.NewAPISIXClient().GET("/status/201").WithHeader("Host", "a.httpbin.org").Expect().Status(http.StatusAccepted)
+		_ = s.NewAPISIXClient().GET("/status/201").WithHeader("Host", "httpbin.org").Expect().Status(http.StatusCreated)
+	})
 })
 
 var _ = ginkgo.Describe("suite-ingress: support ingress.networking/v1beta1", func() {
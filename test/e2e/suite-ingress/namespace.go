@@ -199,5 +199,37 @@ spec:
 			assert.Nil(ginkgo.GinkgoT(), err, "unmarshalling header")
 			assert.NotEqual(ginkgo.GinkgoT(), headers{}, headerResponse)
 		})
+
+		ginkgo.It("same-named resources in different namespaces coexist", func() {
+			backendSvc, backendSvcPort := s.DefaultHTTPBackend()
+			routeTemplate := `
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixRoute
+metadata:
+  name: httpbin-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - %s
+      paths:
+      - /ip
+    backends:
+    - serviceName: %s
+      servicePort: %d
+`
+			assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(fmt.Sprintf(routeTemplate, "httpbin.com", backendSvc, backendSvcPort[0])), "creating first ApisixRoute")
+			assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromStringWithNamespace(fmt.Sprintf(routeTemplate, "second.httpbin.com", backendSvc, backendSvcPort[0]), namespace), "creating second ApisixRoute")
+			assert.Nil(ginkgo.GinkgoT(), s.EnsureNumApisixRoutesCreated(2), "checking number of routes")
+
+			routes, err := s.ListApisixRoutes()
+			assert.Nil(ginkgo.GinkgoT(), err)
+			assert.Len(ginkgo.GinkgoT(), routes, 2)
+			assert.NotEqual(ginkgo.GinkgoT(), routes[0].ID, routes[1].ID)
+
+			_ = s.NewAPISIXClient().GET("/ip").WithHeader("Host", "httpbin.com").Expect().Status(http.StatusOK)
+			_ = s.NewAPISIXClient().GET("/ip").WithHeader("Host", "second.httpbin.com").Expect().Status(http.StatusOK)
+		})
 	})
 })
@@ -106,7 +106,7 @@ spec:
 		assert.Len(ginkgo.GinkgoT(), consumers, 1)
 
 		for _, route := range routes {
-			_ = s.CreateApisixRouteByApisixAdmin(id.GenID(route.Name), []byte(`
+			_ = s.CreateApisixRouteByApisixAdmin(id.GenID(id.Route, route.Name), []byte(`
 {
 	"methods": ["GET"],
 	"uri": "/anything",
@@ -179,7 +179,7 @@ spec:
 		assert.Len(ginkgo.GinkgoT(), consumers, 1)
 
 		for _, route := range routes {
-			_ = s.DeleteApisixRouteByApisixAdmin(id.GenID(route.Name))
+			_ = s.DeleteApisixRouteByApisixAdmin(id.GenID(id.Route, route.Name))
 		}
 
 		for _, consumer := range consumers {
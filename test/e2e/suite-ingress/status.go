@@ -69,6 +69,44 @@ spec:
 		assert.True(ginkgo.GinkgoT(), hasType, "Status is recorded")
 		hasMsg := strings.Contains(output, "message: Sync Successfully")
 		assert.True(ginkgo.GinkgoT(), hasMsg, "Status is recorded")
+		hasSynced := strings.Contains(output, "type: Synced")
+		assert.True(ginkgo.GinkgoT(), hasSynced, "Synced condition is recorded")
+		hasGeneration := strings.Contains(output, "observedGeneration: 1")
+		assert.True(ginkgo.GinkgoT(), hasGeneration, "Observed generation is recorded")
+	})
+
+	ginkgo.It("check the APISIX error is recorded", func() {
+		backendSvc, backendSvcPort := s.DefaultHTTPBackend()
+		apisixRoute := fmt.Sprintf(`
+apiVersion: apisix.apache.org/v2beta3
+kind: ApisixRoute
+metadata:
+  name: httpbin-route
+spec:
+  http:
+  - name: rule1
+    match:
+      hosts:
+      - httpbin.com
+      paths:
+      - /ip
+    backends:
+    - serviceName: %s
+      servicePort: %d
+    plugins:
+    - name: limit-count
+      enable: true
+      config:
+        time_window: 60
+`, backendSvc, backendSvcPort[0])
+		assert.Nil(ginkgo.GinkgoT(), s.CreateResourceFromString(apisixRoute))
+
+		err := s.EnsureNumApisixRoutesCreated(0)
+		assert.Nil(ginkgo.GinkgoT(), err, "Checking number of routes")
+		time.Sleep(6 * time.Second)
+		output, err := s.GetOutputFromString("ar", "httpbin-route", "-o", "yaml")
+		assert.Nil(ginkgo.GinkgoT(), err, "Get output of ApisixRoute resource")
+		assert.Contains(ginkgo.GinkgoT(), output, "failed to check the configuration of plugin limit-count", "APISIX error is recorded")
 	})
 })
 